@@ -0,0 +1,375 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/database"
+	mhttp "github.com/etix/mirrorbits/http"
+	"github.com/etix/mirrorbits/mirrors"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+)
+
+// replayedRequest is a request read from a list of paths or a download log
+type replayedRequest struct {
+	Path   string
+	IP     string
+	Mirror string
+}
+
+// benchResult is the outcome of a single request of the benchmark
+type benchResult struct {
+	latency time.Duration
+	status  int
+	mirror  string
+	err     error
+}
+
+func (c *cli) CmdBench(args ...string) error {
+	cmd := SubCmd("bench", "[OPTIONS]", "Replay requests against the server and report the latency and the\ndistribution among the mirrors.\n\nThe paths are read from the given file (or the standard input), one per\nline, or from a download log in which case the address of the clients\nis replayed too.")
+	requests := cmd.Int("requests", 1000, "Number of requests to send")
+	concurrency := cmd.Int("concurrency", 10, "Number of concurrent requests")
+	paths := cmd.String("paths", "-", "File containing the paths or the download log to replay")
+	target := cmd.String("url", "", "Base URL of the server (default based on ListenAddress)")
+	local := cmd.Bool("local", false, "Run the selection in-process instead of sending HTTP requests")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 || *requests <= 0 || *concurrency <= 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	list, err := readReplayedRequests(*paths)
+	if err != nil {
+		return errors.Wrap(err, "bench error")
+	}
+	if len(list) == 0 {
+		return newError(ExitInvalid, "No request to replay")
+	}
+
+	var run func(replayedRequest) benchResult
+	if *local {
+		simulator, err := newLocalSimulator()
+		if err != nil {
+			return errors.Wrap(err, "bench error")
+		}
+		run = func(r replayedRequest) benchResult {
+			start := time.Now()
+			mlist, _, err := simulator.Select(r.Path, r.IP)
+			res := benchResult{latency: time.Since(start), status: http.StatusFound, err: err}
+			if err != nil {
+				res.status = http.StatusInternalServerError
+			} else if len(mlist) == 0 {
+				res.status = http.StatusNotFound
+			} else {
+				res.mirror = mlist[0].Name
+			}
+			return res
+		}
+	} else {
+		base, err := benchURL(*target)
+		if err != nil {
+			return err
+		}
+		run = c.httpBenchRunner(base, *concurrency)
+	}
+
+	results := make([]benchResult, *requests)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				results[n] = run(list[n%len(list)])
+			}
+		}()
+	}
+	for n := 0; n < *requests; n++ {
+		jobs <- n
+	}
+	close(jobs)
+	wg.Wait()
+
+	printBenchReport(results, time.Since(start))
+	return nil
+}
+
+// httpBenchRunner returns a function sending the requests to the server at
+// the given URL
+func (c *cli) httpBenchRunner(base *url.URL, concurrency int) func(replayedRequest) benchResult {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: concurrency,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	// The mirror list is used to identify the mirror of the redirections
+	var mlist []mirrorURL
+	if rpcClient, err := c.GetRPC(); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+		defer cancel()
+		if list, err := rpcClient.List(ctx, &empty.Empty{}); err == nil {
+			for _, m := range list.Mirrors {
+				mlist = append(mlist, mirrorURL{m.Name, m.HttpURL})
+			}
+		}
+	}
+
+	return func(r replayedRequest) benchResult {
+		u := *base
+		u.Path = strings.TrimSuffix(u.Path, "/") + r.Path
+		req, err := http.NewRequest("GET", u.String(), nil)
+		if err != nil {
+			return benchResult{err: err}
+		}
+		if r.IP != "" {
+			req.Header.Set("X-Forwarded-For", r.IP)
+		}
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			return benchResult{latency: time.Since(start), err: err}
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+
+		res := benchResult{latency: time.Since(start), status: resp.StatusCode}
+		if location := resp.Header.Get("Location"); location != "" {
+			res.mirror = mirrorFromURL(location, mlist)
+		}
+		return res
+	}
+}
+
+// benchURL returns the base URL of the server
+func benchURL(target string) (*url.URL, error) {
+	if target == "" {
+		address := ":8080"
+		if LoadConfig() == nil {
+			address = GetConfig().ListenAddress
+		}
+		if strings.HasPrefix(address, "unix:") {
+			return nil, newError(ExitInvalid, "The server listens on a unix socket, use -url")
+		}
+		if strings.HasPrefix(address, ":") {
+			address = "localhost" + address
+		}
+		target = "http://" + address
+	}
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, newError(ExitInvalid, "Invalid URL: %s", target)
+	}
+	return u, nil
+}
+
+// newLocalSimulator connects to the database of the configuration to run
+// the mirror selection in-process
+func newLocalSimulator() (*mhttp.Simulator, error) {
+	if err := LoadConfig(); err != nil {
+		return nil, err
+	}
+	r := database.NewRedis()
+	r.ConnectPubsub()
+	cache := mirrors.NewCache(r)
+	if cache == nil {
+		return nil, newError(ExitDatabaseUnreachable, "Unable to connect to the database")
+	}
+	return mhttp.NewSimulator(cache)
+}
+
+type mirrorURL struct {
+	name string
+	url  string
+}
+
+// mirrorFromURL returns the name of the mirror serving the given URL, or its
+// host if the mirror is unknown
+func mirrorFromURL(location string, list []mirrorURL) string {
+	name, length := "", 0
+	for _, m := range list {
+		if m.url != "" && strings.HasPrefix(location, m.url) && len(m.url) > length {
+			name, length = m.name, len(m.url)
+		}
+	}
+	if name != "" {
+		return name
+	}
+	if u, err := url.Parse(location); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return location
+}
+
+// readReplayedRequests reads the requests from a list of paths or from a
+// download log, the standard input being used for -
+func readReplayedRequests(file string) ([]replayedRequest, error) {
+	var in io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var list []replayedRequest
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if r, ok := parseReplayedRequest(line); ok {
+			list = append(list, r)
+		}
+	}
+	return list, scanner.Err()
+}
+
+// parseReplayedRequest parses a path or a line of the download log such as
+// 2019/01/02 15:04:05.000000 REDIRECT 302 "/file" ip:192.0.2.1 mirror:m1 ...
+func parseReplayedRequest(line string) (replayedRequest, bool) {
+	var r replayedRequest
+
+	start := strings.Index(line, "\"")
+	if start < 0 {
+		r.Path = strings.Fields(line)[0]
+		if !strings.HasPrefix(r.Path, "/") {
+			r.Path = "/" + r.Path
+		}
+		return r, true
+	}
+
+	end := strings.Index(line[start+1:], "\"")
+	if end < 0 {
+		return r, false
+	}
+	r.Path = line[start+1 : start+1+end]
+	for _, field := range strings.Fields(line[start+end+2:]) {
+		if strings.HasPrefix(field, "ip:") {
+			r.IP = strings.TrimPrefix(field, "ip:")
+		} else if strings.HasPrefix(field, "mirror:") {
+			r.Mirror = strings.TrimPrefix(field, "mirror:")
+		}
+	}
+	return r, r.Path != ""
+}
+
+// percentile returns the given percentile of the sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted))*p+0.5) - 1
+	if i < 0 {
+		i = 0
+	} else if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+func printBenchReport(results []benchResult, elapsed time.Duration) {
+	var latencies []time.Duration
+	statuses := make(map[int]int)
+	distribution := make(map[string]int)
+	failures := 0
+	redirected := 0
+
+	for _, r := range results {
+		if r.err != nil {
+			failures++
+			continue
+		}
+		latencies = append(latencies, r.latency)
+		statuses[r.status]++
+		if r.mirror != "" {
+			distribution[r.mirror]++
+			redirected++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	fmt.Printf(" %-14s %d (%d failed)\n", "Requests:", len(results), failures)
+	fmt.Printf(" %-14s %s (%.1f req/s)\n", "Duration:", elapsed.Truncate(time.Millisecond), float64(len(results))/elapsed.Seconds())
+	fmt.Printf(" %-14s p50 %s, p90 %s, p99 %s, max %s\n", "Latency:",
+		percentile(latencies, 0.5), percentile(latencies, 0.9), percentile(latencies, 0.99), percentile(latencies, 1))
+
+	var codes []int
+	for code := range statuses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	var s []string
+	for _, code := range codes {
+		s = append(s, fmt.Sprintf("%d: %d", code, statuses[code]))
+	}
+	fmt.Printf(" %-14s %s\n", "Status codes:", strings.Join(s, ", "))
+
+	if failures > 0 {
+		for _, r := range results {
+			if r.err != nil {
+				fmt.Printf(" %-14s %s\n", "First error:", r.err)
+				break
+			}
+		}
+	}
+
+	printDistribution("Mirror distribution:", distribution, redirected)
+}
+
+// printDistribution prints the number of requests per mirror, sorted by
+// decreasing count
+func printDistribution(title string, distribution map[string]int, total int) {
+	if total == 0 {
+		return
+	}
+
+	var names []string
+	for name := range distribution {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if distribution[names[i]] == distribution[names[j]] {
+			return names[i] < names[j]
+		}
+		return distribution[names[i]] > distribution[names[j]]
+	})
+
+	fmt.Printf("\n%s\n", title)
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, " %s\t%d\t%.1f%%\n", name, distribution[name], float64(distribution[name])*100/float64(total))
+	}
+	w.Flush()
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReplayedRequest(t *testing.T) {
+	tests := []struct {
+		line string
+		req  replayedRequest
+		ok   bool
+	}{
+		{"/test/file.tgz", replayedRequest{Path: "/test/file.tgz"}, true},
+		{"test/file.tgz 42", replayedRequest{Path: "/test/file.tgz"}, true},
+		{`2019/01/02 15:04:05.000000 REDIRECT 302 "/test/file.tgz" ip:192.0.2.1 mirror:m1 asn:0 distance:0.00km countries:FR`,
+			replayedRequest{Path: "/test/file.tgz", IP: "192.0.2.1", Mirror: "m1"}, true},
+		{`2019/01/02 15:04:05.000000 REDIRECT 404 "/test/file.tgz" ip:192.0.2.1`,
+			replayedRequest{Path: "/test/file.tgz", IP: "192.0.2.1"}, true},
+		{`REDIRECT 302 "/test/file.tgz`, replayedRequest{}, false},
+	}
+
+	for i, test := range tests {
+		req, ok := parseReplayedRequest(test.line)
+		if ok != test.ok || (ok && req != test.req) {
+			t.Fatalf("test %d: expected %+v (%t), got %+v (%t)", i, test.req, test.ok, req, ok)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i))
+	}
+
+	if p := percentile(sorted, 0.5); p != 50 {
+		t.Fatalf("Expected p50 to be 50, got %d", p)
+	}
+	if p := percentile(sorted, 0.99); p != 99 {
+		t.Fatalf("Expected p99 to be 99, got %d", p)
+	}
+	if p := percentile(sorted, 1); p != 100 {
+		t.Fatalf("Expected the max to be 100, got %d", p)
+	}
+	if p := percentile(nil, 0.5); p != 0 {
+		t.Fatalf("Expected 0 without values, got %d", p)
+	}
+}
+
+func TestMirrorFromURL(t *testing.T) {
+	list := []mirrorURL{
+		{"m1", "http://mirror.example.org/"},
+		{"m2", "http://mirror.example.org/project/"},
+	}
+
+	if m := mirrorFromURL("http://mirror.example.org/project/file.tgz", list); m != "m2" {
+		t.Fatalf("Expected the longest match m2, got %s", m)
+	}
+	if m := mirrorFromURL("http://mirror.example.org/other/file.tgz", list); m != "m1" {
+		t.Fatalf("Expected m1, got %s", m)
+	}
+	if m := mirrorFromURL("https://unknown.example.org/file.tgz", list); m != "unknown.example.org" {
+		t.Fatalf("Expected the host of an unknown mirror, got %s", m)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"os/exec"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"text/tabwriter"
@@ -28,7 +29,6 @@ import (
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/howeyc/gopass"
-	"github.com/op/go-logging"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -39,11 +39,51 @@ import (
 const (
 	commentSeparator  = "##### Comments go below this line #####"
 	defaultRPCTimeout = time.Second * 10
+	rsyncRPCTimeout   = time.Minute
 )
 
-var (
-	log = logging.MustGetLogger("main")
-)
+// commands lists the CLI commands along with their description
+var commands = [][]string{
+	{"add", "Add a new mirror"},
+	{"apikey", "Manage the API key of a mirror"},
+	{"bench", "Benchmark the server by replaying requests"},
+	{"check-update", "Check if a new release is available"},
+	{"clone", "Add a mirror using the configuration of another"},
+	{"completion", "Generate the shell completion scripts"},
+	{"coverage", "Show the countries without nearby mirror"},
+	{"dbinfo", "Show the memory usage of the database"},
+	{"disable", "Disable a mirror"},
+	{"edit", "Edit a mirror"},
+	{"enable", "Enable a mirror"},
+	{"export", "Export the mirror database"},
+	{"gc", "Remove the orphaned keys from the database"},
+	{"import", "Import the mirrors of MirrorBrain or MirrorManager"},
+	{"list", "List all mirrors"},
+	{"logs", "Print logs of a mirror"},
+	{"pause", "Pause the background scans and health checks"},
+	{"pending", "Review the mirrors submitted for registration"},
+	{"propagation", "Show the propagation of files to the mirrors"},
+	{"refresh", "Refresh the local repository"},
+	{"reload", "Reload configuration"},
+	{"remove", "Remove a mirror"},
+	{"reopen-logs", "Re-open the log files"},
+	{"rename", "Rename a mirror"},
+	{"resume", "Resume the background scans and health checks"},
+	{"scan", "(Re-)Scan a mirror"},
+	{"scan-log", "Print the scan history of a mirror"},
+	{"shell", "Start an interactive shell"},
+	{"show", "Print a mirror configuration"},
+	{"sign", "Generate a signed URL for a restricted path"},
+	{"simulate", "Simulate the mirror selection over a download log"},
+	{"stats", "Show download stats"},
+	{"status", "Show the health of the server"},
+	{"stop", "Stop the server gracefully"},
+	{"trace", "Show the mirror selection made for a request"},
+	{"upgrade", "Seamless binary upgrade"},
+	{"verify", "Verify the contact of a mirror or the local files"},
+	{"version", "Print version information"},
+	{"weights", "Show the share of each mirror for a file and a location"},
+}
 
 type cli struct {
 	sync.Mutex
@@ -53,6 +93,10 @@ type cli struct {
 
 // ParseCommands parses the command line and call the appropriate functions
 func ParseCommands(args ...string) error {
+	if err := loadRPCSettings(); err != nil {
+		return err
+	}
+
 	c := &cli{
 		creds: &loginCreds{
 			Password: core.RPCPassword,
@@ -89,32 +133,17 @@ func ParseCommands(args ...string) error {
 }
 
 func (c *cli) getMethod(name string) (reflect.Method, bool) {
+	name = strings.Replace(name, "-", "", -1)
 	methodName := "Cmd" + strings.ToUpper(name[:1]) + strings.ToLower(name[1:])
 	return reflect.TypeOf(c).MethodByName(methodName)
 }
 
 func (c *cli) CmdHelp() error {
 	help := fmt.Sprintf("Usage: mirrorbits [OPTIONS] COMMAND [arg...]\n\nA smart download redirector.\n\n")
-	help += fmt.Sprintf("Server commands:\n    %-10.10s%s\n\n", "daemon", "Start the server")
+	help += fmt.Sprintf("Server commands:\n    %-14.14s%s\n\n", "daemon", "Start the server")
 	help += fmt.Sprintf("CLI commands:\n")
-	for _, command := range [][]string{
-		{"add", "Add a new mirror"},
-		{"disable", "Disable a mirror"},
-		{"edit", "Edit a mirror"},
-		{"enable", "Enable a mirror"},
-		{"export", "Export the mirror database"},
-		{"list", "List all mirrors"},
-		{"logs", "Print logs of a mirror"},
-		{"refresh", "Refresh the local repository"},
-		{"reload", "Reload configuration"},
-		{"remove", "Remove a mirror"},
-		{"scan", "(Re-)Scan a mirror"},
-		{"show", "Print a mirror configuration"},
-		{"stats", "Show download stats"},
-		{"upgrade", "Seamless binary upgrade"},
-		{"version", "Print version information"},
-	} {
-		help += fmt.Sprintf("    %-10.10s%s\n", command[0], command[1])
+	for _, command := range commands {
+		help += fmt.Sprintf("    %-14.14s%s\n", command[0], command[1])
 	}
 	fmt.Fprintf(os.Stderr, "%s\n", help)
 	return nil
@@ -142,11 +171,14 @@ func (c *cli) CmdList(args ...string) error {
 	rsync := cmd.Bool("rsync", false, "Print rsync addresses")
 	ftp := cmd.Bool("ftp", false, "Print FTP addresses")
 	location := cmd.Bool("location", false, "Print the country and continent code")
+	ipv6 := cmd.Bool("ipv6", false, "Print the IP families the mirror is reachable with")
 	state := cmd.Bool("state", true, "Print the state of the mirror")
 	score := cmd.Bool("score", false, "Print the score of the mirror")
 	disabled := cmd.Bool("disabled", false, "List disabled mirrors only")
 	enabled := cmd.Bool("enabled", false, "List enabled mirrors only")
 	down := cmd.Bool("down", false, "List only mirrors currently down")
+	unverified := cmd.Bool("unverified", false, "List only mirrors whose contact is not verified")
+	httpErrors := cmd.Bool("errors", false, "Print the HTTP errors recently returned by the mirror (see HTTPErrorsWindow)")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
@@ -156,16 +188,48 @@ func (c *cli) CmdList(args ...string) error {
 		return nil
 	}
 
-	client := c.GetRPC()
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
 	defer cancel()
 	list, err := client.List(ctx, &empty.Empty{})
 	if err != nil {
-		log.Fatal("list error:", err)
+		return errors.Wrap(err, "list error")
 	}
 
 	sort.Sort(ByDate(list.Mirrors))
 
+	contacts := make(map[int32]*rpc.ContactStatus)
+	if *unverified == true {
+		reply, err := client.GetContactStatuses(ctx, &empty.Empty{})
+		if err != nil {
+			return errors.Wrap(err, "list error")
+		}
+		for _, s := range reply.Statuses {
+			contacts[s.ID] = s
+		}
+	}
+
+	errorCounts := make(map[int32]mirrors.HTTPErrors)
+	if *httpErrors == true {
+		reply, err := client.GetHTTPErrors(ctx, &empty.Empty{})
+		if err != nil {
+			return errors.Wrap(err, "list error")
+		}
+		for _, m := range reply.Mirrors {
+			counts := make(mirrors.HTTPErrors)
+			for _, e := range m.Errors {
+				if counts[e.Source] == nil {
+					counts[e.Source] = make(map[int]int64)
+				}
+				counts[e.Source][int(e.Code)] = e.Count
+			}
+			errorCounts[m.ID] = counts
+		}
+	}
+
 	w := new(tabwriter.Writer)
 	w.Init(os.Stdout, 0, 8, 0, '\t', 0)
 	fmt.Fprint(w, "Identifier ")
@@ -184,9 +248,18 @@ func (c *cli) CmdList(args ...string) error {
 	if *location == true {
 		fmt.Fprint(w, "\tLOCATION ")
 	}
+	if *ipv6 == true {
+		fmt.Fprint(w, "\tIP ")
+	}
 	if *state == true {
 		fmt.Fprint(w, "\tSTATE\tSINCE")
 	}
+	if *unverified == true {
+		fmt.Fprint(w, "\tCONTACT\tSTATUS")
+	}
+	if *httpErrors == true {
+		fmt.Fprint(w, "\tERRORS")
+	}
 	fmt.Fprint(w, "\n")
 
 	for _, mirror := range list.Mirrors {
@@ -205,9 +278,14 @@ func (c *cli) CmdList(args ...string) error {
 				continue
 			}
 		}
+		if *unverified == true {
+			if s, ok := contacts[mirror.ID]; ok && s.Status == mirrors.ContactVerified {
+				continue
+			}
+		}
 		stateSince, err := ptypes.Timestamp(mirror.StateSince)
 		if err != nil {
-			log.Fatal("list error:", err)
+			return errors.Wrap(err, "list error")
 		}
 		fmt.Fprintf(w, "%s ", mirror.Name)
 		if *score == true {
@@ -223,12 +301,26 @@ func (c *cli) CmdList(args ...string) error {
 			fmt.Fprintf(w, "\t%s ", mirror.FtpURL)
 		}
 		if *location == true {
-			countries := strings.Split(mirror.CountryCodes, " ")
 			countryCode := "/"
-			if len(countries) >= 1 {
-				countryCode = countries[0]
+			if len(mirror.CountryCodes) >= 1 {
+				countryCode = mirror.CountryCodes[0]
 			}
 			fmt.Fprintf(w, "\t%s (%s) ", countryCode, mirror.ContinentCode)
+			if mirror.LocationWarning != "" {
+				fmt.Fprint(w, "[relocated] ")
+			}
+		}
+		if *ipv6 == true {
+			switch {
+			case mirror.IPv4 && mirror.IPv6:
+				fmt.Fprint(w, "\tv4+v6 ")
+			case mirror.IPv6:
+				fmt.Fprint(w, "\tv6 ")
+			case mirror.IPv4:
+				fmt.Fprint(w, "\tv4 ")
+			default:
+				fmt.Fprint(w, "\tunknown ")
+			}
 		}
 		if *state == true {
 			if mirror.Enabled == false {
@@ -240,6 +332,20 @@ func (c *cli) CmdList(args ...string) error {
 			}
 			fmt.Fprintf(w, " \t(%s)", stateSince.Format(time.RFC1123))
 		}
+		if *unverified == true {
+			status := mirrors.ContactUnverified
+			if s, ok := contacts[mirror.ID]; ok {
+				status = s.Status
+			}
+			fmt.Fprintf(w, "\t%s \t%s", mirror.AdminEmail, status)
+		}
+		if *httpErrors == true {
+			if e := errorCounts[mirror.ID]; e.Total() > 0 {
+				fmt.Fprintf(w, "\t%d (%s)", e.Total(), e)
+			} else {
+				fmt.Fprint(w, "\t0")
+			}
+		}
 		fmt.Fprint(w, "\n")
 	}
 
@@ -249,9 +355,17 @@ func (c *cli) CmdList(args ...string) error {
 }
 
 func (c *cli) CmdAdd(args ...string) error {
+	_, err := c.addMirror(args...)
+	return err
+}
+
+// addMirror adds the mirror described by the arguments of the add command and
+// returns false if it wasn't added
+func (c *cli) addMirror(args ...string) (bool, error) {
 	cmd := SubCmd("add", "[OPTIONS] IDENTIFIER", "Add a new mirror")
 	http := cmd.String("http", "", "HTTP base URL")
 	rsync := cmd.String("rsync", "", "RSYNC base URL (for scanning only)")
+	rsyncModule := cmd.String("rsync-module", "", "RSYNC module to use when the RSYNC URL only contains a host")
 	ftp := cmd.String("ftp", "", "FTP base URL (for scanning only)")
 	sponsorName := cmd.String("sponsor-name", "", "Name of the sponsor")
 	sponsorURL := cmd.String("sponsor-url", "", "URL of the sponsor")
@@ -261,36 +375,78 @@ func (c *cli) CmdAdd(args ...string) error {
 	customData := cmd.String("custom-data", "", "Associated data to return when the mirror is selected (i.e. json document)")
 	continentOnly := cmd.Bool("continent-only", false, "The mirror should only handle its continent")
 	countryOnly := cmd.Bool("country-only", false, "The mirror should only handle its country")
+	regionOnly := cmd.Bool("region-only", false, "The mirror should only handle its region (see Regions)")
 	asOnly := cmd.Bool("as-only", false, "The mirror should only handle clients in the same AS number")
+	cdn := cmd.Bool("cdn", false, "The mirror is geo-distributed (CDN) and has no fixed location")
+	channels := cmd.String("channels", "", "Channels carried by the mirror, separated by spaces or commas (default: all files)")
 	score := cmd.Int("score", 0, "Weight to give to the mirror during selection")
+	latitude := cmd.Float64("latitude", 0, "Latitude of the mirror (overrides the GeoIP database)")
+	longitude := cmd.Float64("longitude", 0, "Longitude of the mirror (overrides the GeoIP database)")
+	country := cmd.String("country", "", "Country codes of the mirror, separated by spaces or commas (overrides the GeoIP database)")
+	continent := cmd.String("continent", "", "Continent code of the mirror (default: derived from its country)")
+	region := cmd.String("region", "", "ISO 3166-2 region code (e.g. US-CA) or group of Regions of the mirror (overrides the GeoIP database)")
+	endpoints := cmd.String("endpoints", "", "Additional HTTP base URLs of a multi-homed mirror, separated by spaces or commas")
 	comment := cmd.String("comment", "", "Comment")
 
 	if err := cmd.Parse(args); err != nil {
-		return nil
+		return false, nil
 	}
 	if cmd.NArg() < 1 {
 		cmd.Usage()
-		return nil
+		return false, nil
+	}
+
+	// The location given explicitly is never overwritten by the GeoIP database
+	set := make(map[string]bool)
+	cmd.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	manualLocation := set["latitude"] || set["longitude"] || set["country"] || set["continent"] || set["region"]
+	if set["latitude"] != set["longitude"] {
+		return false, newError(ExitInvalid, "Both -latitude and -longitude are required")
+	}
+	if *latitude < -90 || *latitude > 90 || *longitude < -180 || *longitude > 180 {
+		return false, newError(ExitInvalid, "Invalid coordinates")
+	}
+	countries, err := mirrors.ParseCountryList(*country)
+	if err != nil {
+		return false, newError(ExitInvalid, "%s", err)
 	}
 
 	if strings.Contains(cmd.Arg(0), " ") {
-		fmt.Fprintf(os.Stderr, "The identifier cannot contain a space\n")
-		os.Exit(-1)
+		return false, newError(ExitInvalid, "The identifier cannot contain a space")
 	}
 
 	if *http == "" {
-		fmt.Fprintf(os.Stderr, "You *must* pass at least an HTTP URL\n")
-		os.Exit(-1)
+		return false, newError(ExitInvalid, "You *must* pass at least an HTTP URL")
 	}
 
 	if !strings.HasPrefix(*http, "http://") && !strings.HasPrefix(*http, "https://") {
 		*http = "http://" + *http
 	}
 
-	_, err := url.Parse(*http)
+	_, err = url.Parse(*http)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Can't parse url\n")
-		os.Exit(-1)
+		return false, newError(ExitInvalid, "Can't parse url")
+	}
+
+	if *rsync != "" {
+		if !strings.HasPrefix(*rsync, "rsync://") {
+			*rsync = "rsync://" + *rsync
+		}
+
+		u, err := url.Parse(*rsync)
+		if err != nil {
+			return false, newError(ExitInvalid, "Can't parse rsync url")
+		}
+
+		// Only a host was given, find the module holding the repository
+		if u.Path == "" || u.Path == "/" {
+			*rsync, err = c.selectRsyncModule(u, *rsyncModule)
+			if err != nil {
+				return false, err
+			}
+		}
 	}
 
 	mirror := &mirrors.Mirror{
@@ -306,26 +462,50 @@ func (c *cli) CmdAdd(args ...string) error {
 		CustomData:     *customData,
 		ContinentOnly:  *continentOnly,
 		CountryOnly:    *countryOnly,
+		RegionOnly:     *regionOnly,
 		ASOnly:         *asOnly,
+		CDN:            *cdn,
+		Channels:       mirrors.ParseChannelList(*channels),
 		Score:          *score,
+		Latitude:       float32(*latitude),
+		Longitude:      float32(*longitude),
+		ContinentCode:  *continent,
+		CountryCodes:   countries,
+		RegionCode:     *region,
+		ManualLocation: manualLocation,
+		Endpoints:      mirrors.ParseEndpoints(*endpoints),
 		Comment:        *comment,
 	}
 
-	client := c.GetRPC()
+	client, err := c.GetRPC()
+	if err != nil {
+		return false, err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
 	defer cancel()
 	m, err := rpc.MirrorToRPC(mirror)
 	if err != nil {
-		log.Fatal("edit error:", err)
+		return false, errors.Wrap(err, "edit error")
 	}
 	reply, err := client.AddMirror(ctx, m)
 	if err != nil {
-		if err.Error() == rpc.ErrNameAlreadyTaken.Error() {
-			log.Fatalf("Mirror %s already exists!\n", mirror.Name)
+		if status.Convert(err).Message() == rpc.ErrNameAlreadyTaken.Error() {
+			return false, newError(ExitConflict, "Mirror %s already exists!", mirror.Name)
 		}
-		log.Fatal("edit error:", err)
+		return false, errors.Wrap(err, "edit error")
 	}
 
+	printAddMirrorReply(reply)
+
+	fmt.Printf("Mirror '%s' added successfully\n", mirror.Name)
+	fmt.Printf("Enable this mirror using\n  $ mirrorbits enable %s\n", mirror.Name)
+
+	return true, nil
+}
+
+// printAddMirrorReply prints the warnings and the location returned when
+// adding a mirror
+func printAddMirrorReply(reply *rpc.AddMirrorReply) {
 	for i := 0; i < len(reply.Warnings); i++ {
 		fmt.Println(reply.Warnings[i])
 		if i == len(reply.Warnings)-1 {
@@ -342,358 +522,1515 @@ func (c *cli) CmdAdd(args ...string) error {
 		fmt.Printf("ASN:       %s\n", reply.ASN)
 		fmt.Println("")
 	}
-
-	fmt.Printf("Mirror '%s' added successfully\n", mirror.Name)
-	fmt.Printf("Enable this mirror using\n  $ mirrorbits enable %s\n", mirror.Name)
-
-	return nil
 }
 
-func (c *cli) CmdRemove(args ...string) error {
-	cmd := SubCmd("remove", "IDENTIFIER", "Remove an existing mirror")
-	force := cmd.Bool("f", false, "Never prompt for confirmation")
+func (c *cli) CmdClone(args ...string) error {
+	cmd := SubCmd("clone", "[OPTIONS] SOURCE IDENTIFIER", "Add a new mirror using the configuration of an existing one")
+	http := cmd.String("http", "", "HTTP base URL of the new mirror")
+	rsync := cmd.String("rsync", "", "RSYNC base URL of the new mirror (for scanning only)")
+	ftp := cmd.String("ftp", "", "FTP base URL of the new mirror (for scanning only)")
+	noScan := cmd.Bool("no-scan", false, "Don't scan the new mirror")
+	enable := cmd.Bool("enable", false, "Enable the new mirror automatically if the scan is successful")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
 	}
-	if cmd.NArg() != 1 {
+	if cmd.NArg() != 2 {
 		cmd.Usage()
 		return nil
 	}
 
-	id, name := c.matchMirror(cmd.Arg(0))
+	name := cmd.Arg(1)
+	if strings.Contains(name, " ") {
+		return newError(ExitInvalid, "The identifier cannot contain a space")
+	}
 
-	if *force == false {
-		fmt.Printf("Removing %s, are you sure? [y/N]", name)
-		reader := bufio.NewReader(os.Stdin)
-		s, _ := reader.ReadString('\n')
-		switch s[0] {
-		case 'y', 'Y':
-			break
-		default:
-			return nil
-		}
+	id, _, err := c.matchMirror(cmd.Arg(0))
+	if err != nil {
+		return err
 	}
 
-	client := c.GetRPC()
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
 	defer cancel()
-	_, err := client.RemoveMirror(ctx, &rpc.MirrorIDRequest{
+	rpcm, err := client.MirrorInfo(ctx, &rpc.MirrorIDRequest{
 		ID: int32(id),
 	})
 	if err != nil {
-		log.Fatal("remove error:", err)
+		return errors.Wrap(err, "clone error")
+	}
+	src, err := rpc.MirrorFromRPC(rpcm)
+	if err != nil {
+		return errors.Wrap(err, "clone error")
 	}
 
-	fmt.Printf("Mirror '%s' removed successfully\n", name)
-	return nil
-}
-
-func (c *cli) CmdScan(args ...string) error {
-	cmd := SubCmd("scan", "[IDENTIFIER]", "(Re-)Scan a mirror")
-	enable := cmd.Bool("enable", false, "Enable the mirror automatically if the scan is successful")
-	all := cmd.Bool("all", false, "Scan all mirrors at once")
-	ftp := cmd.Bool("ftp", false, "Force a scan using FTP")
-	rsync := cmd.Bool("rsync", false, "Force a scan using rsync")
-	timeout := cmd.Uint("timeout", 0, "Timeout in seconds")
+	// Only the configuration is copied, the state and the file index of
+	// the source are left behind
+	mirror := &mirrors.Mirror{
+		Name:                 name,
+		HttpURL:              src.HttpURL,
+		RsyncURL:             src.RsyncURL,
+		FtpURL:               src.FtpURL,
+		SponsorName:          src.SponsorName,
+		SponsorURL:           src.SponsorURL,
+		SponsorLogoURL:       src.SponsorLogoURL,
+		AdminName:            src.AdminName,
+		AdminEmail:           src.AdminEmail,
+		CustomData:           src.CustomData,
+		ContinentOnly:        src.ContinentOnly,
+		CountryOnly:          src.CountryOnly,
+		RegionOnly:           src.RegionOnly,
+		ASOnly:               src.ASOnly,
+		Score:                src.Score,
+		Latitude:             src.Latitude,
+		Longitude:            src.Longitude,
+		ContinentCode:        src.ContinentCode,
+		CountryCodes:         src.CountryCodes,
+		ExcludedCountryCodes: src.ExcludedCountryCodes,
+		RegionCode:           src.RegionCode,
+		Asnum:                src.Asnum,
+		ManualLocation:       src.ManualLocation,
+		Comment:              src.Comment,
+		AllowRedirects:       src.AllowRedirects,
+		CDN:                  src.CDN,
+		Channels:             src.Channels,
+		PathRewrites:         src.PathRewrites,
+		Endpoints:            src.Endpoints,
+		Schedule:             src.Schedule,
+		Probe:                src.Probe,
+		Proxy:                src.Proxy,
+	}
+
+	if *http != "" {
+		if !strings.HasPrefix(*http, "http://") && !strings.HasPrefix(*http, "https://") {
+			*http = "http://" + *http
+		}
+		mirror.HttpURL = *http
+	}
+	if *rsync != "" {
+		if !strings.HasPrefix(*rsync, "rsync://") {
+			*rsync = "rsync://" + *rsync
+		}
+		mirror.RsyncURL = *rsync
+	}
+	if *ftp != "" {
+		mirror.FtpURL = *ftp
+	}
 
-	if err := cmd.Parse(args); err != nil {
-		return nil
+	m, err := rpc.MirrorToRPC(mirror)
+	if err != nil {
+		return errors.Wrap(err, "clone error")
 	}
-	if !*all && cmd.NArg() != 1 || *all && cmd.NArg() != 0 {
-		cmd.Usage()
-		return nil
+	reply, err := client.AddMirror(ctx, m)
+	if err != nil {
+		if status.Convert(err).Message() == rpc.ErrNameAlreadyTaken.Error() {
+			return newError(ExitConflict, "Mirror %s already exists!", mirror.Name)
+		}
+		return errors.Wrap(err, "clone error")
 	}
 
-	client := c.GetRPC()
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	printAddMirrorReply(reply)
 
-	list := make(map[int]string)
+	fmt.Printf("Mirror '%s' cloned into '%s' successfully\n", src.Name, mirror.Name)
 
-	// Get the list of mirrors to scan
-	if *all == true {
-		reply, err := client.MatchMirror(ctx, &rpc.MatchRequest{
-			Pattern: "", // Match all of them
-		})
-		if err != nil {
-			return errors.New("Cannot fetch the list of mirrors")
-		}
+	if *noScan {
+		fmt.Printf("Enable this mirror using\n  $ mirrorbits enable %s\n", mirror.Name)
+		return nil
+	}
 
-		for _, m := range reply.Mirrors {
-			list[int(m.ID)] = m.Name
-		}
-	} else {
-		// Single mirror
-		id, name := c.matchMirror(cmd.Arg(0))
-		list[id] = name
+	scanArgs := []string{mirror.Name}
+	if *enable {
+		scanArgs = append([]string{"-enable"}, scanArgs...)
 	}
+	return c.CmdScan(scanArgs...)
+}
 
-	// Set the method of the scan (if not default)
-	var method rpc.ScanMirrorRequest_Method
-	if *ftp == false && *rsync == false {
-		method = rpc.ScanMirrorRequest_ALL
-	} else if *rsync == true {
-		method = rpc.ScanMirrorRequest_RSYNC
-	} else if *ftp == true {
-		method = rpc.ScanMirrorRequest_FTP
+// selectRsyncModule queries the rsync daemon of the given host for its modules
+// and returns the URL of the one holding the repository
+func (c *cli) selectRsyncModule(u *url.URL, module string) (string, error) {
+	client, err := c.GetRPC()
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), rsyncRPCTimeout)
+	defer cancel()
+	reply, err := client.ListRsyncModules(ctx, &rpc.RsyncModulesRequest{
+		URL: u.String(),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "rsync error")
 	}
 
-	for id, name := range list {
+	if len(reply.Modules) == 0 {
+		return "", newError(ExitNotFound, "No rsync module exported by %s", u.Host)
+	}
 
-		if *timeout > 0 {
-			ctx, cancel = context.WithTimeout(context.Background(), time.Duration(*timeout)*time.Second)
-			defer cancel()
-		}
+	var selected *rpc.RsyncModule
 
-		fmt.Printf("Scanning %s... ", name)
+	if module != "" {
+		for _, m := range reply.Modules {
+			if m.Name == module {
+				selected = m
+				break
+			}
+		}
+		if selected == nil {
+			return "", newError(ExitNotFound, "No rsync module named %s on %s", module, u.Host)
+		}
+		if !selected.ContainsRepository {
+			return "", newError(ExitInvalid, "The rsync module %s does not contain the repository root", module)
+		}
+	} else {
+		var candidate int
+		var candidates int
 
-		reply, err := client.ScanMirror(ctx, &rpc.ScanMirrorRequest{
-			ID:         int32(id),
-			AutoEnable: *enable,
-			Protocol:   method,
-		})
-		if err != nil {
-			s := status.Convert(err)
-			if s.Code() == codes.FailedPrecondition || len(list) == 1 {
-				return errors.New("\nscan error: " + grpc.ErrorDesc(err))
+		fmt.Printf("Modules available on %s:\n", u.Host)
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+		for i, m := range reply.Modules {
+			var found string
+			if m.ContainsRepository {
+				found = "(repository found)"
+				candidate = i + 1
+				candidates++
 			}
-			fmt.Println("scan error:", grpc.ErrorDesc(err))
-			continue
+			fmt.Fprintf(w, "  %d)\t%s\t%s\t%s\n", i+1, m.Name, m.Comment, found)
+		}
+		w.Flush()
+
+		if candidates == 1 {
+			fmt.Printf("Select a module [%d]: ", candidate)
 		} else {
-			fmt.Printf("%d files indexed, %d known and %d removed\n", reply.FilesIndexed, reply.KnownIndexed, reply.Removed)
-			if reply.GetTZOffsetMs() != 0 {
-				fmt.Printf("  ∟ Timezone offset detected and corrected: %d milliseconds\n", reply.TZOffsetMs)
+			candidate = 0
+			fmt.Printf("Select a module [1-%d]: ", len(reply.Modules))
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		s, _ := reader.ReadString('\n')
+		s = strings.TrimSpace(s)
+		if s != "" {
+			candidate, err = strconv.Atoi(s)
+			if err != nil {
+				candidate = 0
 			}
-			if reply.Enabled {
-				fmt.Println("  ∟ Enabled")
+		}
+		if candidate < 1 || candidate > len(reply.Modules) {
+			return "", newError(ExitInvalid, "Invalid selection")
+		}
+		selected = reply.Modules[candidate-1]
+
+		if !selected.ContainsRepository {
+			fmt.Printf("The module %s does not seem to contain the repository root, use it anyway? [y/N]", selected.Name)
+			s, _ := reader.ReadString('\n')
+			if len(s) == 0 || (s[0] != 'y' && s[0] != 'Y') {
+				return "", newError(ExitFailure, "Aborted")
 			}
 		}
 	}
 
-	return nil
+	u.Path = "/" + selected.Name + "/"
+	return u.String(), nil
 }
 
-func (c *cli) CmdRefresh(args ...string) error {
-	cmd := SubCmd("refresh", "", "Scan the local repository")
-	rehash := cmd.Bool("rehash", false, "Force a rehash of the files")
+func (c *cli) CmdApikey(args ...string) error {
+	cmd := SubCmd("apikey", "[OPTIONS] IDENTIFIER", "Generate a new API key for a mirror, replacing the previous one.\n\nThe key allows the admins of the mirror to trigger a scan, to put the\nmirror in maintenance or to update its sponsor through the HTTP API.")
+	revoke := cmd.Bool("revoke", false, "Revoke the API key")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
 	}
-	if cmd.NArg() != 0 {
+	if cmd.NArg() != 1 {
 		cmd.Usage()
 		return nil
 	}
 
-	fmt.Print("Refreshing the local repository... ")
+	id, name, err := c.matchMirror(cmd.Arg(0))
+	if err != nil {
+		return err
+	}
 
-	client := c.GetRPC()
-	ctx, cancel := context.WithCancel(context.Background())
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
 	defer cancel()
-	_, err := client.RefreshRepository(ctx, &rpc.RefreshRepositoryRequest{
-		Rehash: *rehash,
+
+	if *revoke {
+		_, err := client.RevokeAPIKey(ctx, &rpc.MirrorIDRequest{
+			ID: int32(id),
+		})
+		if err != nil {
+			return errors.Wrap(err, "apikey error")
+		}
+		fmt.Printf("API key of '%s' revoked\n", name)
+		return nil
+	}
+
+	reply, err := client.GenerateAPIKey(ctx, &rpc.MirrorIDRequest{
+		ID: int32(id),
 	})
 	if err != nil {
-		fmt.Println("")
-		log.Fatal(err)
+		return errors.Wrap(err, "apikey error")
 	}
 
-	fmt.Println("done")
-
+	fmt.Printf("API key of '%s': %s\n", name, reply.Key)
 	return nil
 }
 
-func (c *cli) matchMirror(pattern string) (id int, name string) {
-	if len(pattern) == 0 {
-		return -1, ""
+func (c *cli) CmdPending(args ...string) error {
+	cmd := SubCmd("pending", "list | approve ID [ADD OPTIONS] | reject ID", "Review the mirrors submitted for registration.\n\nApproving a mirror adds it like the add command would, the options\nof the add command can be given to complete the registration.")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
 	}
 
-	client := c.GetRPC()
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
 	defer cancel()
-	reply, err := client.MatchMirror(ctx, &rpc.MatchRequest{
-		Pattern: pattern,
-	})
+	reply, err := client.ListPendingMirrors(ctx, &empty.Empty{})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "mirror matching: %s\n", err)
-		os.Exit(1)
+		return errors.Wrap(err, "pending error")
 	}
 
-	switch len(reply.Mirrors) {
-	case 0:
-		fmt.Fprintf(os.Stderr, "No match for '%s'\n", pattern)
-		os.Exit(1)
-	case 1:
-		id, name, err := GetSingle(reply.Mirrors)
-		if err != nil {
-			log.Fatal("unexpected error:", err)
+	if cmd.Arg(0) == "list" {
+		if len(reply.Mirrors) == 0 {
+			fmt.Println("No pending mirror")
+			return nil
 		}
-		return id, name
-	default:
-		fmt.Fprintln(os.Stderr, "Multiple match:")
-		for _, mirror := range reply.Mirrors {
-			fmt.Fprintf(os.Stderr, "  %s\n", mirror.Name)
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+		fmt.Fprint(w, "ID\tIdentifier\tHTTP\tAdmin\tSubmitted\n")
+		for _, p := range reply.Mirrors {
+			submitted, _ := ptypes.Timestamp(p.Submitted)
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", p.ID, p.Name, p.HttpURL, p.AdminEmail, submitted.Local().Format("2006-01-02 15:04:05 MST"))
 		}
-		os.Exit(1)
-	}
-	return
-}
-
-func GetSingle(list []*rpc.MirrorID) (int, string, error) {
-	if len(list) == 0 {
-		return -1, "", errors.New("list is empty")
-	} else if len(list) > 1 {
-		return -1, "", errors.New("too many results")
-	}
-	return int(list[0].ID), list[0].Name, nil
-}
-
-func (c *cli) CmdEdit(args ...string) error {
-	cmd := SubCmd("edit", "[IDENTIFIER]", "Edit a mirror")
-
-	if err := cmd.Parse(args); err != nil {
+		w.Flush()
 		return nil
 	}
-	if cmd.NArg() != 1 {
+
+	if cmd.Arg(0) != "approve" && cmd.Arg(0) != "reject" || cmd.NArg() < 2 {
 		cmd.Usage()
 		return nil
 	}
 
-	// Find the editor to use
-	editor := os.Getenv("EDITOR")
+	id, err := strconv.Atoi(cmd.Arg(1))
+	if err != nil {
+		return newError(ExitInvalid, "Invalid pending mirror ID")
+	}
 
-	if editor == "" {
-		log.Fatal("Environment variable $EDITOR not set")
+	var pending *rpc.PendingMirror
+	for _, p := range reply.Mirrors {
+		if int(p.ID) == id {
+			pending = p
+			break
+		}
 	}
+	if pending == nil {
+		return newError(ExitNotFound, "No pending mirror with ID %d", id)
+	}
+
+	if cmd.Arg(0) == "approve" {
+		addArgs := []string{"-http", pending.HttpURL}
+		for _, o := range []struct{ flag, value string }{
+			{"-rsync", pending.RsyncURL},
+			{"-ftp", pending.FtpURL},
+			{"-sponsor-name", pending.SponsorName},
+			{"-sponsor-url", pending.SponsorURL},
+			{"-admin-name", pending.AdminName},
+			{"-admin-email", pending.AdminEmail},
+			{"-comment", pending.Comment},
+		} {
+			if o.value != "" {
+				addArgs = append(addArgs, o.flag, o.value)
+			}
+		}
+		// The options given by the operator take precedence
+		addArgs = append(addArgs, cmd.Args()[2:]...)
+		addArgs = append(addArgs, pending.Name)
 
-	id, _ := c.matchMirror(cmd.Arg(0))
+		added, err := c.addMirror(addArgs...)
+		if err != nil || !added {
+			return err
+		}
+	}
 
-	client := c.GetRPC()
-	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	ctx, cancel = context.WithTimeout(context.Background(), defaultRPCTimeout)
 	defer cancel()
-	rpcm, err := client.MirrorInfo(ctx, &rpc.MirrorIDRequest{
+	_, err = client.RemovePendingMirror(ctx, &rpc.PendingMirrorRequest{
 		ID: int32(id),
 	})
 	if err != nil {
-		log.Fatal("edit error:", err)
+		return errors.Wrap(err, "pending error")
 	}
-	mirror, err := rpc.MirrorFromRPC(rpcm)
-	if err != nil {
-		log.Fatal("edit error:", err)
+
+	if cmd.Arg(0) == "reject" {
+		fmt.Printf("Pending mirror '%s' rejected\n", pending.Name)
+	}
+	return nil
+}
+
+func (c *cli) CmdRemove(args ...string) error {
+	cmd := SubCmd("remove", "IDENTIFIER", "Remove an existing mirror")
+	force := cmd.Bool("f", false, "Never prompt for confirmation")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	id, name, err := c.matchMirror(cmd.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *force == false {
+		fmt.Printf("Removing %s, are you sure? [y/N]", name)
+		reader := bufio.NewReader(os.Stdin)
+		s, _ := reader.ReadString('\n')
+		switch s[0] {
+		case 'y', 'Y':
+			break
+		default:
+			return nil
+		}
+	}
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	_, err = client.RemoveMirror(ctx, &rpc.MirrorIDRequest{
+		ID: int32(id),
+	})
+	if err != nil {
+		return errors.Wrap(err, "remove error")
+	}
+
+	fmt.Printf("Mirror '%s' removed successfully\n", name)
+	return nil
+}
+
+func (c *cli) CmdRename(args ...string) error {
+	cmd := SubCmd("rename", "IDENTIFIER NEWNAME", "Rename an existing mirror")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 2 {
+		cmd.Usage()
+		return nil
+	}
+
+	id, name, err := c.matchMirror(cmd.Arg(0))
+	if err != nil {
+		return err
+	}
+	newName := cmd.Arg(1)
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	_, err = client.RenameMirror(ctx, &rpc.RenameMirrorRequest{
+		ID:   int32(id),
+		Name: newName,
+	})
+	if err != nil {
+		if status.Convert(err).Message() == rpc.ErrNameAlreadyTaken.Error() {
+			return newError(ExitConflict, "Mirror %s already exists!", newName)
+		}
+		return errors.Wrap(err, "rename error")
+	}
+
+	fmt.Printf("Mirror '%s' renamed to '%s'\n", name, newName)
+	return nil
+}
+
+func (c *cli) CmdScan(args ...string) error {
+	cmd := SubCmd("scan", "[IDENTIFIER|-all [PATTERN]]", "(Re-)Scan a mirror")
+	enable := cmd.Bool("enable", false, "Enable the mirror automatically if the scan is successful")
+	all := cmd.Bool("all", false, "Scan all mirrors at once, or all the mirrors matching the pattern")
+	ftp := cmd.Bool("ftp", false, "Force a scan using FTP")
+	rsync := cmd.Bool("rsync", false, "Force a scan using rsync")
+	force := cmd.Bool("force", false, "Accept the result even if the mirror lost too many files")
+	timeout := cmd.Uint("timeout", 0, "Timeout in seconds")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if !*all && cmd.NArg() != 1 || *all && cmd.NArg() > 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var list []*rpc.MirrorID
+
+	// Get the list of mirrors to scan
+	if *all == true {
+		// Without a pattern, match all of them
+		list, err = c.matchMirrors(cmd.Arg(0))
+		if err != nil {
+			return err
+		}
+	} else {
+		// Single mirror
+		id, name, err := c.matchMirror(cmd.Arg(0))
+		if err != nil {
+			return err
+		}
+		list = append(list, &rpc.MirrorID{ID: int32(id), Name: name})
+	}
+
+	// Set the method of the scan (if not default)
+	var method rpc.ScanMirrorRequest_Method
+	if *ftp == false && *rsync == false {
+		method = rpc.ScanMirrorRequest_ALL
+	} else if *rsync == true {
+		method = rpc.ScanMirrorRequest_RSYNC
+	} else if *ftp == true {
+		method = rpc.ScanMirrorRequest_FTP
+	}
+
+	for _, m := range list {
+		id, name := m.ID, m.Name
+
+		if *timeout > 0 {
+			ctx, cancel = context.WithTimeout(context.Background(), time.Duration(*timeout)*time.Second)
+			defer cancel()
+		}
+
+		fmt.Printf("Scanning %s... ", name)
+
+		reply, err := client.ScanMirror(ctx, &rpc.ScanMirrorRequest{
+			ID:         id,
+			AutoEnable: *enable,
+			Protocol:   method,
+			Force:      *force,
+		})
+		if err != nil {
+			s := status.Convert(err)
+			if s.Code() == codes.FailedPrecondition || len(list) == 1 {
+				return errors.New("\nscan error: " + grpc.ErrorDesc(err))
+			}
+			fmt.Println("scan error:", grpc.ErrorDesc(err))
+			continue
+		} else {
+			fmt.Printf("%d files indexed, %d known and %d removed\n", reply.FilesIndexed, reply.KnownIndexed, reply.Removed)
+			if reply.GetTZOffsetMs() != 0 {
+				fmt.Printf("  ∟ Timezone offset detected and corrected: %d milliseconds\n", reply.TZOffsetMs)
+			}
+			if reply.Enabled {
+				fmt.Println("  ∟ Enabled")
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *cli) CmdRefresh(args ...string) error {
+	cmd := SubCmd("refresh", "", "Scan the local repository")
+	rehash := cmd.Bool("rehash", false, "Force a rehash of the files")
+	push := cmd.Bool("push", false, "Notify the mirrors having a push hook once done")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	fmt.Print("Refreshing the local repository... ")
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err = client.RefreshRepository(ctx, &rpc.RefreshRepositoryRequest{
+		Rehash: *rehash,
+		Push:   *push,
+	})
+	if err != nil {
+		fmt.Println("")
+		return err
+	}
+
+	fmt.Println("done")
+
+	return nil
+}
+
+func (c *cli) CmdPause(args ...string) error {
+	cmd := SubCmd("pause", "[scanning|monitoring|all]", "Pause the background scans of the mirrors and of the local repository\n(scanning) and the health checks of the mirrors (monitoring) on all\nthe instances, e.g. during a maintenance of the database. The scans and\nrefreshes requested by hand are still done.")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() > 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	_, err = client.Pause(ctx, &rpc.PauseRequest{
+		Activity: cmd.Arg(0),
+	})
+	if err != nil {
+		return errors.Wrap(err, "pause error")
+	}
+	return nil
+}
+
+func (c *cli) CmdResume(args ...string) error {
+	cmd := SubCmd("resume", "[scanning|monitoring|all]", "Resume the background activities paused with the pause command")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() > 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	_, err = client.Resume(ctx, &rpc.PauseRequest{
+		Activity: cmd.Arg(0),
+	})
+	if err != nil {
+		return errors.Wrap(err, "resume error")
+	}
+	return nil
+}
+
+func (c *cli) CmdGc(args ...string) error {
+	cmd := SubCmd("gc", "[OPTIONS]", "Remove the keys left in the database by the removed mirrors and files,\nthe interrupted scans, and the files no longer in the repository still\ncounted as handled by the mirrors.")
+	dryRun := cmd.Bool("dry-run", false, "Only report the orphaned keys")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	reply, err := client.CollectGarbage(ctx, &rpc.GCRequest{
+		DryRun: *dryRun,
+	})
+	if err != nil {
+		return errors.Wrap(err, "gc error")
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 0, '\t', 0)
+	fmt.Fprintf(w, "Keys of the removed mirrors:\t%d\n", reply.MirrorKeys)
+	fmt.Fprintf(w, "File information of the removed mirrors:\t%d\n", reply.FileInfos)
+	fmt.Fprintf(w, "Mirror sets of the removed files:\t%d\n", reply.FileMirrors)
+	fmt.Fprintf(w, "Removed mirrors serving a file:\t%d\n", reply.FileMirrorEntries)
+	fmt.Fprintf(w, "Temporary sets of interrupted scans:\t%d\n", reply.TmpKeys)
+	fmt.Fprintf(w, "Removed files still handled by a mirror:\t%d\n", reply.HandledFiles)
+	w.Flush()
+
+	total := reply.MirrorKeys + reply.FileInfos + reply.FileMirrors + reply.FileMirrorEntries + reply.TmpKeys + reply.HandledFiles
+	if *dryRun {
+		fmt.Printf("\n%d orphaned entry(ies) found (dry run, nothing removed)\n", total)
+	} else {
+		fmt.Printf("\n%d orphaned entry(ies) removed\n", total)
+	}
+	return nil
+}
+
+func (c *cli) CmdDbinfo(args ...string) error {
+	cmd := SubCmd("dbinfo", "[OPTIONS]", "Show the number of keys and the approximate memory usage of each family\nof keys of the database, estimated from a sample of their keys (Redis 4.0\nor later).")
+	samples := cmd.Int("samples", 100, "Number of keys sampled per family")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	reply, err := client.DBInfo(ctx, &rpc.DBInfoRequest{
+		Samples: int32(*samples),
+	})
+	if err != nil {
+		return errors.Wrap(err, "dbinfo error")
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 0, '\t', 0)
+	fmt.Fprintln(w, "Family \tKEYS \tMEMORY \tSAMPLED ")
+	for _, f := range reply.Families {
+		if f.Keys == 0 {
+			continue
+		}
+		memory := "n/a"
+		if f.Sampled > 0 {
+			memory = "~" + utils.ReadableSize(f.Memory)
+		}
+		fmt.Fprintf(w, "%s \t%d \t%s \t%d \n", f.Name, f.Keys, memory, f.Sampled)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d key%s, %s used by Redis\n", reply.Keys, utils.Plural(int(reply.Keys)), utils.ReadableSize(reply.UsedMemory))
+	return nil
+}
+
+func (c *cli) matchMirror(pattern string) (id int, name string, err error) {
+	if len(pattern) == 0 {
+		return -1, "", nil
+	}
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return -1, "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	reply, err := client.MatchMirror(ctx, &rpc.MatchRequest{
+		Pattern: pattern,
+	})
+	if err != nil {
+		return -1, "", errors.Wrap(err, "mirror matching")
+	}
+
+	switch len(reply.Mirrors) {
+	case 0:
+		return -1, "", newError(ExitNotFound, "No match for '%s'", pattern)
+	case 1:
+		id, name, err := GetSingle(reply.Mirrors)
+		if err != nil {
+			return -1, "", errors.Wrap(err, "unexpected error")
+		}
+		return id, name, nil
+	default:
+		names := make([]string, 0, len(reply.Mirrors))
+		for _, mirror := range reply.Mirrors {
+			names = append(names, mirror.Name)
+		}
+		sort.Strings(names)
+		return -1, "", newError(ExitAmbiguous, "Multiple match:\n  %s", strings.Join(names, "\n  "))
+	}
+}
+
+// matchMirrors returns all the mirrors matching the given pattern, sorted by
+// name. An empty pattern matches all the mirrors.
+func (c *cli) matchMirrors(pattern string) ([]*rpc.MirrorID, error) {
+	client, err := c.GetRPC()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	reply, err := client.MatchMirror(ctx, &rpc.MatchRequest{
+		Pattern: pattern,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "mirror matching")
+	}
+
+	if len(reply.Mirrors) == 0 {
+		return nil, newError(ExitNotFound, "No match for '%s'", pattern)
+	}
+
+	sort.Slice(reply.Mirrors, func(i, j int) bool {
+		return reply.Mirrors[i].Name < reply.Mirrors[j].Name
+	})
+	return reply.Mirrors, nil
+}
+
+func GetSingle(list []*rpc.MirrorID) (int, string, error) {
+	if len(list) == 0 {
+		return -1, "", errors.New("list is empty")
+	} else if len(list) > 1 {
+		return -1, "", errors.New("too many results")
+	}
+	return int(list[0].ID), list[0].Name, nil
+}
+
+func (c *cli) CmdEdit(args ...string) error {
+	cmd := SubCmd("edit", "[IDENTIFIER] [-set FIELD=VALUE]...", "Edit a mirror")
+	var fields fieldAssignments
+	cmd.Var(&fields, "set", "Set a field without opening an editor (can be repeated)")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	// Allow the options to be given after the identifier
+	identifier := cmd.Arg(0)
+	if err := cmd.Parse(cmd.Args()[1:]); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	// Find the editor to use
+	editor := os.Getenv("EDITOR")
+
+	if editor == "" && len(fields) == 0 {
+		return newError(ExitInvalid, "Environment variable $EDITOR not set")
+	}
+
+	id, _, err := c.matchMirror(identifier)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	rpcm, err := client.MirrorInfo(ctx, &rpc.MirrorIDRequest{
+		ID: int32(id),
+	})
+	if err != nil {
+		return errors.Wrap(err, "edit error")
+	}
+	mirror, err := rpc.MirrorFromRPC(rpcm)
+	if err != nil {
+		return errors.Wrap(err, "edit error")
+	}
+
+	if len(fields) > 0 {
+		return c.editFields(mirror, fields)
+	}
+
+	// Generate a yaml configuration string from the struct
+	out, err := yaml.Marshal(mirror)
+
+	// Open a temporary file
+	f, err := ioutil.TempFile(os.TempDir(), "edit")
+	if err != nil {
+		return errors.Wrap(err, "Cannot create temporary file")
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("# You can now edit this mirror configuration.\n" +
+		"# Just save and quit when you're done.\n\n")
+	f.WriteString(string(out))
+	f.WriteString(fmt.Sprintf("\n%s\n\n%s\n", commentSeparator, mirror.Comment))
+	f.Close()
+
+	// Checksum the original file
+	chk, _ := filesystem.Sha256sum(f.Name())
+
+reopen:
+	// Launch the editor with the filename as first parameter
+	exe := exec.Command(editor, f.Name())
+	exe.Stdin = os.Stdin
+	exe.Stdout = os.Stdout
+	exe.Stderr = os.Stderr
+
+	err = exe.Run()
+	if err != nil {
+		return err
+	}
+
+	// Read the file back
+	out, err = ioutil.ReadFile(f.Name())
+	if err != nil {
+		return errors.Wrapf(err, "Cannot read file %s", f.Name())
+	}
+
+	// Checksum the file back and compare
+	chk2, _ := filesystem.Sha256sum(f.Name())
+	if bytes.Compare(chk, chk2) == 0 {
+		fmt.Println("Aborted - settings are unmodified, so there is nothing to change.")
+		return nil
+	}
+
+	var comment string
+	yamlstr := string(out)
+
+	commentIndex := strings.Index(yamlstr, commentSeparator)
+	if commentIndex > 0 {
+		comment = strings.TrimSpace(yamlstr[commentIndex+len(commentSeparator):])
+		yamlstr = yamlstr[:commentIndex]
+	}
+
+	reopen := func(err error) bool {
+	eagain:
+		fmt.Printf("%s\nRetry? [Y/n]", err.Error())
+		reader := bufio.NewReader(os.Stdin)
+		s, _ := reader.ReadString('\n')
+		switch s[0] {
+		case 'y', 'Y', 10:
+			return true
+		case 'n', 'N':
+			fmt.Println("Aborted")
+			return false
+		default:
+			goto eagain
+		}
+	}
+
+	// Removing the location warning from the file acknowledges it
+	mirror.LocationWarning = ""
+
+	// Fill the struct from the yaml
+	err = yaml.Unmarshal([]byte(yamlstr), &mirror)
+	if err != nil {
+		switch reopen(err) {
+		case true:
+			goto reopen
+		case false:
+			return nil
+		}
+	}
+
+	mirror.Comment = comment
+
+	ctx, cancel = context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	m, err := rpc.MirrorToRPC(mirror)
+	if err != nil {
+		return errors.Wrap(err, "edit error")
+	}
+	reply, err := client.UpdateMirror(ctx, m)
+	if err != nil {
+		if err.Error() == rpc.ErrNameAlreadyTaken.Error() {
+			switch reopen(errors.New("Name already taken")) {
+			case true:
+				goto reopen
+			case false:
+				return nil
+			}
+		}
+		return errors.Wrap(err, "edit error")
+	}
+
+	for _, warning := range reply.Warnings {
+		fmt.Println(warning)
+	}
+	if len(reply.Diff) > 0 {
+		fmt.Println(reply.Diff)
+	}
+
+	fmt.Printf("Mirror '%s' edited successfully\n", mirror.Name)
+
+	return nil
+}
+
+// fieldAssignments is a list of FIELD=VALUE given on the command line
+type fieldAssignments []string
+
+func (f *fieldAssignments) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *fieldAssignments) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected FIELD=VALUE, got %s", value)
+	}
+	*f = append(*f, value)
+	return nil
+}
+
+// editFields updates the given fields of the mirror without any interaction.
+// Nothing is done if the mirror already matches the given values.
+func (c *cli) editFields(mirror *mirrors.Mirror, fields fieldAssignments) error {
+	before, _ := yaml.Marshal(mirror)
+	comment := mirror.Comment
+
+	if err := applyFields(mirror, fields); err != nil {
+		return errors.Wrap(err, "edit error")
+	}
+
+	after, _ := yaml.Marshal(mirror)
+	if bytes.Equal(before, after) && comment == mirror.Comment {
+		fmt.Printf("Mirror '%s' is unchanged\n", mirror.Name)
+		return nil
+	}
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	m, err := rpc.MirrorToRPC(mirror)
+	if err != nil {
+		return errors.Wrap(err, "edit error")
+	}
+	reply, err := client.UpdateMirror(ctx, m)
+	if err != nil {
+		return errors.Wrap(err, "edit error")
+	}
+
+	for _, warning := range reply.Warnings {
+		fmt.Println(warning)
+	}
+	if len(reply.Diff) > 0 {
+		fmt.Println(reply.Diff)
+	}
+
+	fmt.Printf("Mirror '%s' edited successfully\n", mirror.Name)
+
+	return nil
+}
+
+// applyFields sets the given fields, named after the keys of the yaml
+// configuration of the mirror (case insensitive), to their new value
+func applyFields(mirror *mirrors.Mirror, fields fieldAssignments) error {
+	keys := make(map[string]string)
+	t := reflect.TypeOf(*mirror)
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if tag != "" && tag != "-" {
+			keys[strings.ToLower(tag)] = tag
+		}
+	}
+
+	for _, field := range fields {
+		kv := strings.SplitN(field, "=", 2)
+		name, value := strings.TrimSpace(kv[0]), kv[1]
+
+		if strings.EqualFold(name, "comment") {
+			mirror.Comment = value
+			continue
+		}
+
+		key, ok := keys[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("unknown field %s", name)
+		}
+
+		// Let yaml guess the type of the value and fall back to a string
+		var parsed interface{}
+		if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+			parsed = value
+		} else if _, isMap := parsed.(map[interface{}]interface{}); isMap {
+			parsed = value
+		}
+
+		doc, err := yaml.Marshal(map[string]interface{}{key: parsed})
+		if err != nil {
+			return err
+		}
+		if err := yaml.UnmarshalStrict(doc, mirror); err != nil {
+			return fmt.Errorf("invalid value for %s: %s", key, value)
+		}
+	}
+	return nil
+}
+
+func (c *cli) CmdShow(args ...string) error {
+	cmd := SubCmd("show", "[IDENTIFIER]", "Print a mirror configuration")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	id, _, err := c.matchMirror(cmd.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	rpcm, err := client.MirrorInfo(ctx, &rpc.MirrorIDRequest{
+		ID: int32(id),
+	})
+	if err != nil {
+		return errors.Wrap(err, "edit error")
+	}
+	mirror, err := rpc.MirrorFromRPC(rpcm)
+	if err != nil {
+		return errors.Wrap(err, "edit error")
+	}
+
+	// The credentials of the probe and of the proxy are only revealed by
+	// 'edit'
+	if mirror.Probe.Password != "" {
+		mirror.Probe.Password = "********"
+	}
+	if u, err := url.Parse(mirror.Proxy); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), "********")
+			mirror.Proxy = u.String()
+		}
+	}
+
+	// Generate a yaml configuration string from the struct
+	out, err := yaml.Marshal(mirror)
+	if err != nil {
+		return errors.Wrap(err, "show error")
+	}
+
+	fmt.Printf("%s\nComment:\n%s\n", out, mirror.Comment)
+	if mirror.MovedTo != "" {
+		fmt.Printf("\nThe HTTP URL permanently redirects to %s\n", mirror.MovedTo)
+	}
+	for _, u := range mirror.EndpointsDown {
+		fmt.Printf("\nThe endpoint %s is down\n", u)
+	}
+	if !mirror.Schedule.IsZero() {
+		fmt.Printf("\nThe mirror currently accepts %d%% of its traffic\n", mirror.Schedule.Share(time.Now()))
+	}
+	return nil
+}
+
+func (c *cli) CmdExport(args ...string) error {
+	cmd := SubCmd("export", "[format]", "Export the mirror database.\n\nAvailable formats: mirmon, mirrorbrain (the SQL statements filling the\nserver table of a MirrorBrain database) and json (a stable schema for the\npublic lists of mirrors: generated, then for each of the mirrors id, name,\nurls by protocol, country, countries, continent, sponsor, state (up, down\nor disabled), lag (seconds since the last successful scan) and score)")
+	rsync := cmd.Bool("rsync", true, "Export rsync URLs")
+	http := cmd.Bool("http", true, "Export http URLs")
+	ftp := cmd.Bool("ftp", true, "Export ftp URLs")
+	disabled := cmd.Bool("disabled", true, "Export disabled mirrors")
+	signKey := cmd.String("sign", "", "Clearsign the export with the given GPG key")
+	gpgHomedir := cmd.String("gpg-homedir", "", "GnuPG home directory holding the signing key")
+	outputFile := cmd.String("o", "", "Write the export to the given file, replaced atomically")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	format := cmd.Arg(0)
+	if format != "mirmon" && format != "mirrorbrain" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Unsupported format\n")
+		cmd.Usage()
+		return nil
+	}
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	list, err := client.List(ctx, &empty.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "export error")
+	}
+
+	var buf bytes.Buffer
+	urls := exportURLs{http: *http, rsync: *rsync, ftp: *ftp}
+	switch format {
+	case "mirrorbrain":
+		writeMirrorBrain(&buf, list.Mirrors, urls, *disabled)
+	case "json":
+		if err = writeJSON(&buf, list.Mirrors, urls, *disabled, time.Now()); err != nil {
+			return errors.Wrap(err, "export error")
+		}
+	default:
+		writeMirmon(&buf, list.Mirrors, urls, *disabled)
+	}
+
+	output := buf.Bytes()
+	if *signKey != "" {
+		signer := utils.GPGSigner{
+			Homedir: *gpgHomedir,
+			Key:     *signKey,
+		}
+		output, err = signer.ClearSign(output)
+		if err != nil {
+			return errors.Wrap(err, "export error")
+		}
+	}
+
+	if *outputFile != "" {
+		// The readers of the file never see a partial export
+		tmp := *outputFile + ".new"
+		if err = ioutil.WriteFile(tmp, output, 0644); err == nil {
+			if err = os.Rename(tmp, *outputFile); err != nil {
+				os.Remove(tmp)
+			}
+		}
+		if err != nil {
+			return errors.Wrap(err, "export error")
+		}
+		return nil
+	}
+
+	os.Stdout.Write(output)
+	return nil
+}
+
+func (c *cli) CmdEnable(args ...string) error {
+	cmd := SubCmd("enable", "[-all] [IDENTIFIER|PATTERN]", "Enable a mirror")
+	all := cmd.Bool("all", false, "Enable all the mirrors matching the pattern")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	if *all == true {
+		list, err := c.matchMirrors(cmd.Arg(0))
+		if err != nil {
+			return err
+		}
+		for _, m := range list {
+			if err := c.setStatus(int(m.ID), m.Name, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	id, name, err := c.matchMirror(cmd.Arg(0))
+	if err != nil {
+		return err
+	}
+	return c.setStatus(id, name, true)
+}
+
+func (c *cli) CmdDisable(args ...string) error {
+	cmd := SubCmd("disable", "[-all] [IDENTIFIER|PATTERN]", "Disable a mirror")
+	all := cmd.Bool("all", false, "Disable all the mirrors matching the pattern")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	if *all == true {
+		list, err := c.matchMirrors(cmd.Arg(0))
+		if err != nil {
+			return err
+		}
+		for _, m := range list {
+			if err := c.setStatus(int(m.ID), m.Name, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	id, name, err := c.matchMirror(cmd.Arg(0))
+	if err != nil {
+		return err
+	}
+	return c.setStatus(id, name, false)
+}
+
+func (c *cli) setStatus(id int, name string, enabled bool) error {
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	_, err = client.ChangeStatus(ctx, &rpc.ChangeStatusRequest{
+		ID:      int32(id),
+		Enabled: enabled,
+	})
+	if err != nil {
+		if enabled {
+			return errors.Wrapf(err, "Couldn't enable mirror '%s'", name)
+		}
+		return errors.Wrapf(err, "Couldn't disable mirror '%s'", name)
+	}
+
+	if enabled {
+		fmt.Printf("Mirror '%s' enabled successfully\n", name)
+	} else {
+		fmt.Printf("Mirror '%s' disabled successfully\n", name)
 	}
+	return nil
+}
 
-	// Generate a yaml configuration string from the struct
-	out, err := yaml.Marshal(mirror)
+func (c *cli) CmdStats(args ...string) error {
+	cmd := SubCmd("stats", "[OPTIONS] [mirror|file] [IDENTIFIER|PATTERN]", "Show download stats for a particular mirror or a file pattern")
+	dateStart := cmd.String("start-date", "", "Starting date (format YYYY-MM-DD)")
+	dateEnd := cmd.String("end-date", "", "Ending date (format YYYY-MM-DD)")
+	human := cmd.Bool("h", true, "Human readable version")
 
-	// Open a temporary file
-	f, err := ioutil.TempFile(os.TempDir(), "edit")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 2 || (cmd.Arg(0) != "mirror" && cmd.Arg(0) != "file") {
+		cmd.Usage()
+		return nil
+	}
+
+	start, err := time.Parse("2006-1-2", *dateStart)
 	if err != nil {
-		log.Fatal("Cannot create temporary file:", err)
+		start = time.Now()
 	}
-	defer os.Remove(f.Name())
-	f.WriteString("# You can now edit this mirror configuration.\n" +
-		"# Just save and quit when you're done.\n\n")
-	f.WriteString(string(out))
-	f.WriteString(fmt.Sprintf("\n%s\n\n%s\n", commentSeparator, mirror.Comment))
-	f.Close()
+	startproto, _ := ptypes.TimestampProto(start)
 
-	// Checksum the original file
-	chk, _ := filesystem.Sha256sum(f.Name())
+	end, err := time.Parse("2006-1-2", *dateEnd)
+	if err != nil {
+		end = time.Now()
+	}
+	endproto, _ := ptypes.TimestampProto(end)
 
-reopen:
-	// Launch the editor with the filename as first parameter
-	exe := exec.Command(editor, f.Name())
-	exe.Stdin = os.Stdin
-	exe.Stdout = os.Stdout
-	exe.Stderr = os.Stderr
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
 
-	err = exe.Run()
+	if cmd.Arg(0) == "file" {
+		// File stats
+
+		reply, err := client.StatsFile(ctx, &rpc.StatsFileRequest{
+			Pattern:   cmd.Arg(1),
+			DateStart: startproto,
+			DateEnd:   endproto,
+		})
+		if err != nil {
+			return errors.Wrap(err, "file stats error")
+		}
+
+		// Format the results
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 0, '\t', 0)
+
+		// Sort keys and count requests
+		var keys []string
+		var requests int64
+		for k, req := range reply.Files {
+			requests += req
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s:\t%d\n", k, reply.Files[k])
+		}
+
+		if len(keys) > 0 {
+			// Add a line separator
+			fmt.Fprintf(w, "\t\n")
+		}
+
+		fmt.Fprintf(w, "Total download requests: \t%d\n", requests)
+		w.Flush()
+	} else if cmd.Arg(0) == "mirror" {
+		// Mirror stats
+
+		id, name, err := c.matchMirror(cmd.Arg(1))
+		if err != nil {
+			return err
+		}
+
+		reply, err := client.StatsMirror(ctx, &rpc.StatsMirrorRequest{
+			ID:        int32(id),
+			DateStart: startproto,
+			DateEnd:   endproto,
+		})
+		if err != nil {
+			return errors.Wrap(err, "mirror stats error")
+		}
+
+		// Format the results
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 0, '\t', 0)
+
+		fmt.Fprintf(w, "Identifier:\t%s\n", name)
+		if !reply.Mirror.Enabled {
+			fmt.Fprintf(w, "Status:\tdisabled\n")
+		} else if reply.Mirror.Up {
+			fmt.Fprintf(w, "Status:\tup\n")
+		} else {
+			fmt.Fprintf(w, "Status:\tdown\n")
+		}
+		fmt.Fprintf(w, "Download requests:\t%d\n", reply.Requests)
+		fmt.Fprint(w, "Bytes transferred:\t")
+		if *human {
+			fmt.Fprintln(w, utils.ReadableSize(reply.Bytes))
+		} else {
+			fmt.Fprintln(w, reply.Bytes)
+		}
+		w.Flush()
+	}
+
+	return nil
+}
+
+func (c *cli) CmdLogs(args ...string) error {
+	cmd := SubCmd("logs", "[IDENTIFIER]", "Print logs of a mirror")
+	maxResults := cmd.Uint("l", 500, "Maximum number of logs to return")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	id, name, err := c.matchMirror(cmd.Arg(0))
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	// Read the file back
-	out, err = ioutil.ReadFile(f.Name())
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	resp, err := client.GetMirrorLogs(ctx, &rpc.GetMirrorLogsRequest{
+		ID:         int32(id),
+		MaxResults: int32(*maxResults),
+	})
 	if err != nil {
-		log.Fatal("Cannot read file", f.Name())
+		return errors.Wrap(err, "logs error")
 	}
 
-	// Checksum the file back and compare
-	chk2, _ := filesystem.Sha256sum(f.Name())
-	if bytes.Compare(chk, chk2) == 0 {
-		fmt.Println("Aborted - settings are unmodified, so there is nothing to change.")
+	if len(resp.Line) == 0 {
+		fmt.Printf("No logs for %s\n", name)
 		return nil
 	}
 
-	var comment string
-	yamlstr := string(out)
+	fmt.Printf("Printing logs for %s:\n", name)
 
-	commentIndex := strings.Index(yamlstr, commentSeparator)
-	if commentIndex > 0 {
-		comment = strings.TrimSpace(yamlstr[commentIndex+len(commentSeparator):])
-		yamlstr = yamlstr[:commentIndex]
+	for _, l := range resp.Line {
+		fmt.Println(l)
 	}
 
-	reopen := func(err error) bool {
-	eagain:
-		fmt.Printf("%s\nRetry? [Y/n]", err.Error())
-		reader := bufio.NewReader(os.Stdin)
-		s, _ := reader.ReadString('\n')
-		switch s[0] {
-		case 'y', 'Y', 10:
-			return true
-		case 'n', 'N':
-			fmt.Println("Aborted")
-			return false
-		default:
-			goto eagain
-		}
+	return nil
+}
+
+func (c *cli) CmdScanlog(args ...string) error {
+	cmd := SubCmd("scan-log", "[IDENTIFIER]", "Print the scan history of a mirror")
+	maxResults := cmd.Uint("l", 20, "Maximum number of scans to return")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		return nil
 	}
 
-	// Fill the struct from the yaml
-	err = yaml.Unmarshal([]byte(yamlstr), &mirror)
+	id, name, err := c.matchMirror(cmd.Arg(0))
 	if err != nil {
-		switch reopen(err) {
-		case true:
-			goto reopen
-		case false:
-			return nil
-		}
+		return err
 	}
 
-	mirror.Comment = comment
-
-	ctx, cancel = context.WithTimeout(context.Background(), defaultRPCTimeout)
-	defer cancel()
-	m, err := rpc.MirrorToRPC(mirror)
+	client, err := c.GetRPC()
 	if err != nil {
-		log.Fatal("edit error:", err)
+		return err
 	}
-	reply, err := client.UpdateMirror(ctx, m)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	resp, err := client.GetScanSummaries(ctx, &rpc.GetScanSummariesRequest{
+		ID:         int32(id),
+		MaxResults: int32(*maxResults),
+	})
 	if err != nil {
-		if err.Error() == rpc.ErrNameAlreadyTaken.Error() {
-			switch reopen(errors.New("Name already taken")) {
-			case true:
-				goto reopen
-			case false:
-				return nil
-			}
-		}
-		log.Fatal("edit error:", err)
+		return errors.Wrap(err, "scan-log error")
 	}
 
-	if len(reply.Diff) > 0 {
-		fmt.Println(reply.Diff)
+	if len(resp.Summaries) == 0 {
+		fmt.Printf("No scan history for %s\n", name)
+		return nil
 	}
 
-	fmt.Printf("Mirror '%s' edited successfully\n", mirror.Name)
+	fmt.Printf("Scan history for %s:\n", name)
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprint(w, "Date\tMethod\tDuration\tFiles\tKnown\tAdded\tRemoved\tError\n")
+	for _, s := range resp.Summaries {
+		date, _ := ptypes.Timestamp(s.Timestamp)
+		duration := time.Duration(s.DurationMs) * time.Millisecond
+		method := s.Method
+		if s.Quarantined {
+			method += " (quarantined)"
+		} else if s.Accepted {
+			method += " (accepted)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\n",
+			date.Local().Format("2006-01-02 15:04:05 MST"),
+			method,
+			duration.Round(time.Second),
+			s.FilesIndexed,
+			s.KnownIndexed,
+			s.Added,
+			s.Removed,
+			s.Error)
+	}
+	w.Flush()
 
 	return nil
 }
 
-func (c *cli) CmdShow(args ...string) error {
-	cmd := SubCmd("show", "[IDENTIFIER]", "Print a mirror configuration")
+func (c *cli) CmdPropagation(args ...string) error {
+	cmd := SubCmd("propagation", "[OPTIONS] PATH_PREFIX", "Show the propagation of the files under the given path to the mirrors")
+	verbose := cmd.Bool("v", false, "Print the name of the mirrors")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
@@ -703,109 +2040,198 @@ func (c *cli) CmdShow(args ...string) error {
 		return nil
 	}
 
-	id, _ := c.matchMirror(cmd.Arg(0))
-
-	client := c.GetRPC()
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
 	defer cancel()
-	rpcm, err := client.MirrorInfo(ctx, &rpc.MirrorIDRequest{
-		ID: int32(id),
+	reply, err := client.GetPropagation(ctx, &rpc.PropagationRequest{
+		Prefix: cmd.Arg(0),
 	})
 	if err != nil {
-		log.Fatal("edit error:", err)
+		return errors.Wrap(err, "propagation error")
 	}
-	mirror, err := rpc.MirrorFromRPC(rpcm)
+
+	if len(reply.Files) == 0 {
+		fmt.Printf("No file found under %s\n", cmd.Arg(0))
+		return nil
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprint(w, "File\tMirrors\tPropagation\tETA\n")
+	for _, f := range reply.Files {
+		eta := "-"
+		if f.ETA != nil {
+			t, _ := ptypes.Timestamp(f.ETA)
+			eta = t.Local().Format("2006-01-02 15:04:05 MST")
+		}
+		fmt.Fprintf(w, "%s\t%d/%d\t%.1f%%\t%s\n", f.Path, len(f.Mirrors), len(f.Mirrors)+len(f.Missing), f.Percentage, eta)
+		if *verbose {
+			if len(f.Mirrors) > 0 {
+				fmt.Fprintf(w, "  ∟ Serving: %s\n", strings.Join(f.Mirrors, ", "))
+			}
+			if len(f.Missing) > 0 {
+				fmt.Fprintf(w, "  ∟ Missing: %s\n", strings.Join(f.Missing, ", "))
+			}
+		}
+	}
+	w.Flush()
+
+	return nil
+}
+
+func (c *cli) CmdSign(args ...string) error {
+	cmd := SubCmd("sign", "[OPTIONS] PATH", "Generate a signed URL for a restricted path")
+	validity := cmd.Duration("validity", 24*time.Hour, "Validity of the URL")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	reply, err := client.SignURL(ctx, &rpc.SignURLRequest{
+		Path:     cmd.Arg(0),
+		Validity: int64(validity.Seconds()),
+	})
 	if err != nil {
-		log.Fatal("edit error:", err)
+		return errors.Wrap(err, "sign error")
 	}
 
-	// Generate a yaml configuration string from the struct
-	out, err := yaml.Marshal(mirror)
-	if err != nil {
-		log.Fatal("show error:", err)
+	u := url.URL{
+		Path:     reply.Path,
+		RawQuery: fmt.Sprintf("expires=%d&signature=%s", reply.Expires, reply.Signature),
 	}
+	fmt.Println(u.String())
 
-	fmt.Printf("%s\nComment:\n%s\n", out, mirror.Comment)
 	return nil
 }
 
-func (c *cli) CmdExport(args ...string) error {
-	cmd := SubCmd("export", "[format]", "Export the mirror database.\n\nAvailable formats: mirmon")
-	rsync := cmd.Bool("rsync", true, "Export rsync URLs")
-	http := cmd.Bool("http", true, "Export http URLs")
-	ftp := cmd.Bool("ftp", true, "Export ftp URLs")
-	disabled := cmd.Bool("disabled", true, "Export disabled mirrors")
+func (c *cli) CmdStatus(args ...string) error {
+	cmd := SubCmd("status", "", "Print a summary of the health of the server")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
 	}
-	if cmd.NArg() != 1 {
+	if cmd.NArg() != 0 {
 		cmd.Usage()
 		return nil
 	}
 
-	if cmd.Arg(0) != "mirmon" {
-		fmt.Fprintf(os.Stderr, "Unsupported format\n")
-		cmd.Usage()
-		return nil
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
 	}
-
-	client := c.GetRPC()
 	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
 	defer cancel()
-	list, err := client.List(ctx, &empty.Empty{})
+	reply, err := client.Status(ctx, &empty.Empty{})
 	if err != nil {
-		log.Fatal("export error:", err)
+		return errors.Wrap(err, "status error")
 	}
 
-	w := new(tabwriter.Writer)
-	w.Init(os.Stdout, 0, 8, 0, '\t', 0)
+	started, _ := ptypes.Timestamp(reply.Started)
 
-	for _, m := range list.Mirrors {
-		if *disabled == false {
-			if m.Enabled == false {
-				continue
-			}
-		}
-		ccodes := strings.Fields(m.CountryCodes)
+	fmt.Printf(" %-17s %s\n", "Version:", reply.Version)
+	fmt.Printf(" %-17s %s (since %s)\n", "Uptime:", time.Since(started).Truncate(time.Second), started.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf(" %-17s %s\n", "Config file:", configFileName(reply.ConfigFile))
+	fmt.Printf(" %-17s %s (http), %s (rpc)\n", "Listening on:", reply.ListenAddress, reply.RPCListenAddress)
 
-		urls := make([]string, 0, 3)
-		if *rsync == true && m.RsyncURL != "" {
-			urls = append(urls, m.RsyncURL)
+	if !reply.DatabaseReachable {
+		fmt.Printf(" %-17s unreachable (%s)\n", "Database:", reply.DatabaseError)
+	} else {
+		if reply.Degraded {
+			fmt.Printf(" %-17s not writable, degraded mode (%d pending update%s)\n", "Database:",
+				reply.PendingWrites, utils.Plural(int(reply.PendingWrites)))
+		} else {
+			fmt.Printf(" %-17s ok\n", "Database:")
 		}
-		if *http == true && m.HttpURL != "" {
-			urls = append(urls, m.HttpURL)
+		fmt.Printf(" %-17s %d up, %d down, %d disabled\n", "Mirrors:", reply.MirrorsUp, reply.MirrorsDown, reply.MirrorsDisabled)
+		if reply.LastRepositoryScan != nil {
+			lastScan, _ := ptypes.Timestamp(reply.LastRepositoryScan)
+			fmt.Printf(" %-17s scanned %s\n", "Repository:", utils.FuzzyTimeStr(time.Since(lastScan)))
+		} else {
+			fmt.Printf(" %-17s never scanned\n", "Repository:")
 		}
-		if *ftp == true && m.FtpURL != "" {
-			urls = append(urls, m.FtpURL)
+		if reply.ScanningPaused != nil {
+			since, _ := ptypes.Timestamp(reply.ScanningPaused)
+			fmt.Printf(" %-17s paused since %s\n", "Scanning:", since.Local().Format("2006-01-02 15:04:05"))
 		}
-
-		for _, u := range urls {
-			fmt.Fprintf(w, "%s\t%s\t%s\n", ccodes[0], u, m.AdminEmail)
+		if reply.MonitoringPaused != nil {
+			since, _ := ptypes.Timestamp(reply.MonitoringPaused)
+			fmt.Printf(" %-17s paused since %s\n", "Monitoring:", since.Local().Format("2006-01-02 15:04:05"))
 		}
 	}
 
-	w.Flush()
-	return nil
-}
+	if reply.Monitor {
+		fmt.Printf(" %-17s %d health check%s, %d scan%s in progress, %d scan%s waiting\n", "Monitor:",
+			reply.HealthChecks, utils.Plural(int(reply.HealthChecks)),
+			reply.Scans, utils.Plural(int(reply.Scans)),
+			reply.PendingScans, utils.Plural(int(reply.PendingScans)))
+	} else {
+		fmt.Printf(" %-17s disabled\n", "Monitor:")
+	}
 
-func (c *cli) CmdEnable(args ...string) error {
-	cmd := SubCmd("enable", "[IDENTIFIER]", "Enable a mirror")
+	label := "Workers:"
+	for _, p := range reply.WorkerPools {
+		line := fmt.Sprintf("%s: %d/%d busy", p.Name, p.Busy, p.Workers)
+		if p.QueueSize > 0 {
+			line += fmt.Sprintf(", %d/%d queued", p.Queued, p.QueueSize)
+		} else {
+			line += fmt.Sprintf(", %d queued", p.Queued)
+		}
+		// All the workers are busy with tasks waiting, or the queue is
+		// almost full
+		if (p.Busy >= p.Workers && p.Queued > 0) || (p.QueueSize > 0 && p.Queued*10 >= p.QueueSize*9) {
+			line += " (falling behind)"
+		}
+		fmt.Printf(" %-17s %s\n", label, line)
+		label = ""
+	}
 
-	if err := cmd.Parse(args); err != nil {
-		return nil
+	label = "Scan queue:"
+	for _, q := range reply.ScanQueue {
+		details := q.Source
+		if q.Force {
+			details += ", forced"
+		}
+		var line string
+		if q.Started != nil {
+			started, _ := ptypes.Timestamp(q.Started)
+			line = fmt.Sprintf("%s scanning for %s (%s)", q.MirrorName, time.Since(started).Truncate(time.Second), details)
+		} else {
+			queued, _ := ptypes.Timestamp(q.Queued)
+			line = fmt.Sprintf("%s waiting for %s (%s)", q.MirrorName, time.Since(queued).Truncate(time.Second), details)
+		}
+		fmt.Printf(" %-17s %s\n", label, line)
+		label = ""
 	}
-	if cmd.NArg() != 1 {
-		cmd.Usage()
-		return nil
+
+	label = "Quarantined:"
+	for _, q := range reply.Quarantined {
+		since, _ := ptypes.Timestamp(q.Since)
+		fmt.Printf(" %-17s %s since %s: %d files found instead of %d (use 'scan -force %s' to accept)\n",
+			label, q.MirrorName, since.Local().Format("2006-01-02 15:04:05 MST"), q.Files, q.Previous, q.MirrorName)
+		label = ""
 	}
 
-	c.changeStatus(cmd.Arg(0), true)
+	if !reply.DatabaseReachable {
+		return newError(ExitDatabaseUnreachable, "The server cannot reach the database")
+	}
 	return nil
 }
 
-func (c *cli) CmdDisable(args ...string) error {
-	cmd := SubCmd("disable", "[IDENTIFIER]", "Disable a mirror")
+func (c *cli) CmdTrace(args ...string) error {
+	cmd := SubCmd("trace", "REQUESTID", "Show the mirror selection made for a request.\n\nThe request ID is returned in the X-Request-ID header and written in\nthe download logs. The traces are kept RequestTraceRetention minutes.")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
@@ -815,210 +2241,277 @@ func (c *cli) CmdDisable(args ...string) error {
 		return nil
 	}
 
-	c.changeStatus(cmd.Arg(0), false)
-	return nil
-}
-
-func (c *cli) changeStatus(pattern string, enabled bool) {
-	id, name := c.matchMirror(pattern)
-
-	client := c.GetRPC()
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
 	defer cancel()
-	_, err := client.ChangeStatus(ctx, &rpc.ChangeStatusRequest{
-		ID:      int32(id),
-		Enabled: enabled,
+	reply, err := client.GetRequestTrace(ctx, &rpc.RequestTraceRequest{
+		RequestID: cmd.Arg(0),
 	})
 	if err != nil {
-		if enabled {
-			log.Fatalf("Couldn't enable mirror '%s': %s\n", name, err)
-		} else {
-			log.Fatalf("Couldn't disable mirror '%s': %s\n", name, err)
-		}
+		return errors.Wrap(err, "trace error")
 	}
 
-	if enabled {
-		fmt.Printf("Mirror '%s' enabled successfully\n", name)
-	} else {
-		fmt.Printf("Mirror '%s' disabled successfully\n", name)
+	t, _ := ptypes.Timestamp(reply.Time)
+
+	fmt.Printf(" %-11s %s\n", "Request:", reply.RequestID)
+	fmt.Printf(" %-11s %s\n", "Date:", t.Local().Format("2006-01-02 15:04:05 MST"))
+	fmt.Printf(" %-11s %s\n", "File:", reply.Path)
+	fmt.Printf(" %-11s %s (country: %s, asn: %d)\n", "Client:", reply.IP, reply.Country, reply.ASNum)
+	fmt.Printf(" %-11s %s %d\n", "Response:", reply.Output, reply.Status)
+	if reply.Error != "" {
+		fmt.Printf(" %-11s %s\n", "Error:", reply.Error)
 	}
-	return
+	if reply.Fallback {
+		fmt.Printf(" %-11s yes\n", "Fallback:")
+	}
+
+	fmt.Printf("\nSelected mirrors:\n")
+	if len(reply.Mirrors) == 0 {
+		fmt.Printf(" none\n")
+	}
+	for i, m := range reply.Mirrors {
+		fmt.Printf(" %2d. %s\n", i+1, m)
+	}
+
+	if len(reply.Excluded) > 0 {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 0, '\t', 0)
+		fmt.Printf("\nExcluded mirrors:\n")
+		for _, e := range reply.Excluded {
+			fmt.Fprintf(w, " %s\t%s\n", e.Name, e.Reason)
+		}
+		w.Flush()
+	}
+
+	return nil
 }
 
-func (c *cli) CmdStats(args ...string) error {
-	cmd := SubCmd("stats", "[OPTIONS] [mirror|file] [IDENTIFIER|PATTERN]", "Show download stats for a particular mirror or a file pattern")
-	dateStart := cmd.String("start-date", "", "Starting date (format YYYY-MM-DD)")
-	dateEnd := cmd.String("end-date", "", "Ending date (format YYYY-MM-DD)")
-	human := cmd.Bool("h", true, "Human readable version")
+func (c *cli) CmdCoverage(args ...string) error {
+	cmd := SubCmd("coverage", "[OPTIONS]", "Show the countries with traffic but without nearby mirror.\n\nThe report is computed periodically from the redirections (see\nCoverageReport).")
+	all := cmd.Bool("all", false, "Show all the countries with traffic")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
 	}
-	if cmd.NArg() != 2 || (cmd.Arg(0) != "mirror" && cmd.Arg(0) != "file") {
+	if cmd.NArg() != 0 {
 		cmd.Usage()
 		return nil
 	}
 
-	start, err := time.Parse("2006-1-2", *dateStart)
-	if err != nil {
-		start = time.Now()
-	}
-	startproto, _ := ptypes.TimestampProto(start)
-
-	end, err := time.Parse("2006-1-2", *dateEnd)
+	client, err := c.GetRPC()
 	if err != nil {
-		end = time.Now()
+		return err
 	}
-	endproto, _ := ptypes.TimestampProto(end)
-
-	client := c.GetRPC()
 	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
 	defer cancel()
+	reply, err := client.GetCoverage(ctx, &empty.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "coverage error")
+	}
 
-	if cmd.Arg(0) == "file" {
-		// File stats
+	if reply.Generated == nil {
+		fmt.Println("The coverage report has not been computed yet")
+		return nil
+	}
+	generated, _ := ptypes.Timestamp(reply.Generated)
+	fmt.Printf("Coverage over the last %d day%s (computed on %s)\n\n", reply.Days, utils.Plural(int(reply.Days)),
+		generated.Local().Format("2006-01-02 15:04:05"))
 
-		reply, err := client.StatsFile(ctx, &rpc.StatsFileRequest{
-			Pattern:   cmd.Arg(1),
-			DateStart: startproto,
-			DateEnd:   endproto,
-		})
-		if err != nil {
-			log.Fatal("file stats error:", err)
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 0, '\t', 0)
+	fmt.Fprintln(w, "Country \tREQUESTS \tAVG DISTANCE \tMIRRORS \tGAP ")
+	gaps := 0
+	for _, country := range reply.Countries {
+		if country.Gap {
+			gaps++
+		} else if !*all {
+			continue
 		}
-
-		// Format the results
-		w := new(tabwriter.Writer)
-		w.Init(os.Stdout, 0, 8, 0, '\t', 0)
-
-		// Sort keys and count requests
-		var keys []string
-		var requests int64
-		for k, req := range reply.Files {
-			requests += req
-			keys = append(keys, k)
+		gap := ""
+		if country.Gap {
+			gap = "yes"
 		}
-		sort.Strings(keys)
+		fmt.Fprintf(w, "%s \t%d \t%.0f km \t%d \t%s\n", country.CountryCode, country.Requests,
+			country.AverageDistance, country.LocalMirrors, gap)
+	}
+	w.Flush()
 
-		for _, k := range keys {
-			fmt.Fprintf(w, "%s:\t%d\n", k, reply.Files[k])
-		}
+	fmt.Printf("\n%d coverage gap%s\n", gaps, utils.Plural(gaps))
+	return nil
+}
 
-		if len(keys) > 0 {
-			// Add a line separator
-			fmt.Fprintf(w, "\t\n")
-		}
+func (c *cli) CmdReload(args ...string) error {
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	_, err = client.Reload(ctx, &empty.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "upgrade error")
+	}
 
-		fmt.Fprintf(w, "Total download requests: \t%d\n", requests)
-		w.Flush()
-	} else if cmd.Arg(0) == "mirror" {
-		// Mirror stats
+	return nil
+}
 
-		id, name := c.matchMirror(cmd.Arg(1))
+func (c *cli) CmdReopenlogs(args ...string) error {
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	_, err = client.ReopenLogs(ctx, &empty.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "reopen-logs error")
+	}
 
-		reply, err := client.StatsMirror(ctx, &rpc.StatsMirrorRequest{
-			ID:        int32(id),
-			DateStart: startproto,
-			DateEnd:   endproto,
-		})
-		if err != nil {
-			log.Fatal("mirror stats error:", err)
-		}
+	return nil
+}
 
-		// Format the results
-		w := new(tabwriter.Writer)
-		w.Init(os.Stdout, 0, 8, 0, '\t', 0)
+func (c *cli) CmdStop(args ...string) error {
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	_, err = client.Stop(ctx, &empty.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "stop error")
+	}
 
-		fmt.Fprintf(w, "Identifier:\t%s\n", name)
-		if !reply.Mirror.Enabled {
-			fmt.Fprintf(w, "Status:\tdisabled\n")
-		} else if reply.Mirror.Up {
-			fmt.Fprintf(w, "Status:\tup\n")
-		} else {
-			fmt.Fprintf(w, "Status:\tdown\n")
-		}
-		fmt.Fprintf(w, "Download requests:\t%d\n", reply.Requests)
-		fmt.Fprint(w, "Bytes transferred:\t")
-		if *human {
-			fmt.Fprintln(w, utils.ReadableSize(reply.Bytes))
-		} else {
-			fmt.Fprintln(w, reply.Bytes)
-		}
-		w.Flush()
+	return nil
+}
+
+func (c *cli) CmdUpgrade(args ...string) error {
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	_, err = client.Upgrade(ctx, &empty.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "upgrade error")
 	}
 
 	return nil
 }
 
-func (c *cli) CmdLogs(args ...string) error {
-	cmd := SubCmd("logs", "[IDENTIFIER]", "Print logs of a mirror")
-	maxResults := cmd.Uint("l", 500, "Maximum number of logs to return")
+func (c *cli) CmdVerify(args ...string) error {
+	cmd := SubCmd("verify", "[OPTIONS] IDENTIFIER | -path PREFIX", "Email a verification link to the contact of a mirror, or re-hash the files\nof the local repository starting with PREFIX and report those whose content\nno longer matches their hashes")
+	bounced := cmd.Bool("bounced", false, "Mark the contact as bounced instead")
+	prefix := cmd.String("path", "", "Verify the local files starting with this prefix")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil
 	}
+	if *prefix != "" {
+		if cmd.NArg() != 0 || *bounced {
+			cmd.Usage()
+			return nil
+		}
+		return c.verifyRepository(*prefix)
+	}
 	if cmd.NArg() != 1 {
 		cmd.Usage()
 		return nil
 	}
 
-	id, name := c.matchMirror(cmd.Arg(0))
+	id, name, err := c.matchMirror(cmd.Arg(0))
+	if err != nil {
+		return err
+	}
 
-	client := c.GetRPC()
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
 	defer cancel()
-	resp, err := client.GetMirrorLogs(ctx, &rpc.GetMirrorLogsRequest{
-		ID:         int32(id),
-		MaxResults: int32(*maxResults),
+	_, err = client.VerifyContact(ctx, &rpc.VerifyContactRequest{
+		ID:      int32(id),
+		Bounced: *bounced,
 	})
 	if err != nil {
-		log.Fatal("logs error:", err)
-	}
-
-	if len(resp.Line) == 0 {
-		fmt.Printf("No logs for %s\n", name)
-		return nil
+		return errors.Wrap(err, "verify error")
 	}
 
-	fmt.Printf("Printing logs for %s:\n", name)
-
-	for _, l := range resp.Line {
-		fmt.Println(l)
+	if *bounced {
+		fmt.Printf("Contact of '%s' marked as bounced\n", name)
+	} else {
+		fmt.Printf("Verification email sent to the contact of '%s'\n", name)
 	}
-
 	return nil
 }
 
-func (c *cli) CmdReload(args ...string) error {
-	client := c.GetRPC()
-	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
-	defer cancel()
-	_, err := client.Reload(ctx, &empty.Empty{})
-	if err != nil {
-		log.Fatal("upgrade error:", err)
+// verifyRepository re-hashes the local files starting with prefix
+func (c *cli) verifyRepository(prefix string) error {
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
 	}
 
-	return nil
-}
+	fmt.Print("Verifying the local repository... ")
 
-func (c *cli) CmdUpgrade(args ...string) error {
-	client := c.GetRPC()
-	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	_, err := client.Upgrade(ctx, &empty.Empty{})
+	reply, err := client.VerifyRepository(ctx, &rpc.VerifyRepositoryRequest{
+		Prefix: prefix,
+	})
 	if err != nil {
-		log.Fatal("upgrade error:", err)
+		fmt.Println("")
+		return errors.Wrap(err, "verify error")
 	}
 
+	fmt.Println("done")
+	fmt.Printf(" %-17s %d\n", "Files verified:", reply.Checked)
+	fmt.Printf(" %-17s %d\n", "Mismatches:", len(reply.Mismatches))
+	for _, f := range reply.Mismatches {
+		fmt.Printf("  %s\n", f)
+	}
+	if len(reply.Mismatches) > 0 {
+		return newError(ExitFailure, "%d corrupted file%s found", len(reply.Mismatches), utils.Plural(len(reply.Mismatches)))
+	}
 	return nil
 }
 
 func (c *cli) CmdVersion(args ...string) error {
+	cmd := SubCmd("version", "[-v]", "Print version information")
+	verbose := cmd.Bool("v", false, "Print the configuration files in use")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
 	fmt.Printf("Client:\n")
 	core.PrintVersion(core.GetVersionInfo())
+	if *verbose {
+		fmt.Printf(" %-17s %s\n", "Config file:", configFileName(core.ConfigFile))
+	}
 	fmt.Println()
 
-	client := c.GetRPC()
+	client, err := c.GetRPC()
+	if ExitCode(err) == ExitServerUnreachable {
+		// The version of the client is still useful without a server
+		fmt.Printf("Server:\n")
+		fmt.Printf(" %-17s not running or unreachable\n", "Status:")
+		return nil
+	} else if err != nil {
+		return err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
 	defer cancel()
 	reply, err := client.GetVersion(ctx, &empty.Empty{})
@@ -1032,11 +2525,42 @@ func (c *cli) CmdVersion(args ...string) error {
 		core.PrintVersion(core.VersionInfo{
 			Version:    reply.Version,
 			Build:      reply.Build,
+			Commit:     reply.Commit,
+			BuildDate:  reply.BuildDate,
 			GoVersion:  reply.GoVersion,
 			OS:         reply.OS,
 			Arch:       reply.Arch,
 			GoMaxProcs: int(reply.GoMaxProcs),
 		})
+		if *verbose {
+			fmt.Printf(" %-17s %s\n", "Config file:", configFileName(reply.ConfigFile))
+		}
+		if reply.DatabaseError != "" {
+			fmt.Printf(" %-17s unreachable (%s)\n", "Redis:", reply.DatabaseError)
+		} else {
+			fmt.Printf(" %-17s %s\n", "Redis:", reply.RedisVersion)
+			schema := fmt.Sprintf("%d", reply.DBVersion)
+			if reply.DBVersion != core.DBVersion {
+				schema += fmt.Sprintf(" (this client expects %d)", core.DBVersion)
+			}
+			fmt.Printf(" %-17s %s\n", "Database schema:", schema)
+		}
+		if len(reply.GeoIPDatabases) == 0 {
+			fmt.Printf(" %-17s none loaded\n", "GeoIP:")
+		}
+		label := "GeoIP:"
+		for _, db := range reply.GeoIPDatabases {
+			built, _ := ptypes.Timestamp(db.BuildDate)
+			fmt.Printf(" %-17s %s (built on %s)\n", label, db.Filename, built.UTC().Format("2006-01-02"))
+			label = ""
+		}
 	}
 	return nil
 }
+
+func configFileName(path string) string {
+	if path == "" {
+		return "none"
+	}
+	return path
+}
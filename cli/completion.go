@@ -0,0 +1,176 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/etix/mirrorbits/rpc"
+)
+
+var (
+	// mirrorCommands are the commands taking a mirror identifier
+	mirrorCommands = []string{"apikey", "clone", "disable", "edit", "enable", "logs",
+		"remove", "rename", "scan", "scan-log", "show", "verify"}
+	// fileCommands are the commands taking a path of the repository
+	fileCommands = []string{"propagation", "sign"}
+)
+
+const bashCompletion = `# bash completion for mirrorbits
+#
+# Load it with:
+#   source <(mirrorbits completion bash)
+
+_mirrorbits() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
+    local opts=() cmd="" i
+
+    # Keep the connection options to call back into mirrorbits
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${COMP_WORDS[i]}" in
+            -h|-p|-P) opts+=("${COMP_WORDS[i]}" "${COMP_WORDS[i+1]}"); ((i++)) ;;
+            -cpuprofile) ((i++)) ;;
+            -*) ;;
+            *) cmd="${COMP_WORDS[i]}"; break ;;
+        esac
+    done
+
+    COMPREPLY=()
+    if [ -z "$cmd" ]; then
+        COMPREPLY=($(compgen -W "%s" -- "$cur"))
+        return
+    fi
+
+    case "$cur" in
+        -*) return ;;
+    esac
+
+    case "$cmd" in
+        %s)
+            COMPREPLY=($(compgen -W "$(mirrorbits "${opts[@]}" completion -list mirrors 2>/dev/null)" -- "$cur"))
+            ;;
+        %s)
+            mapfile -t COMPREPLY < <(mirrorbits "${opts[@]}" completion -list files "$cur" 2>/dev/null)
+            compopt -o nospace
+            ;;
+        stats)
+            case "$prev" in
+                mirror)
+                    COMPREPLY=($(compgen -W "$(mirrorbits "${opts[@]}" completion -list mirrors 2>/dev/null)" -- "$cur"))
+                    ;;
+                file)
+                    mapfile -t COMPREPLY < <(mirrorbits "${opts[@]}" completion -list files "$cur" 2>/dev/null)
+                    compopt -o nospace
+                    ;;
+                stats)
+                    COMPREPLY=($(compgen -W "mirror file" -- "$cur"))
+                    ;;
+            esac
+            ;;
+    esac
+}
+
+complete -F _mirrorbits mirrorbits
+`
+
+const zshCompletion = `#compdef mirrorbits
+#
+# zsh completion for mirrorbits
+#
+# Load it with:
+#   source <(mirrorbits completion zsh)
+
+autoload -U +X bashcompinit && bashcompinit
+`
+
+const fishCompletion = `# fish completion for mirrorbits
+#
+# Load it with:
+#   mirrorbits completion fish | source
+
+complete -c mirrorbits -f
+%s
+complete -c mirrorbits -n '__fish_seen_subcommand_from %s' -a '(mirrorbits completion -list mirrors 2>/dev/null)'
+complete -c mirrorbits -n '__fish_seen_subcommand_from %s' -a '(mirrorbits completion -list files (commandline -ct) 2>/dev/null)'
+complete -c mirrorbits -n '__fish_seen_subcommand_from stats; and not __fish_seen_subcommand_from mirror file' -a 'mirror file'
+complete -c mirrorbits -n '__fish_seen_subcommand_from stats; and __fish_seen_subcommand_from mirror' -a '(mirrorbits completion -list mirrors 2>/dev/null)'
+complete -c mirrorbits -n '__fish_seen_subcommand_from stats; and __fish_seen_subcommand_from file' -a '(mirrorbits completion -list files (commandline -ct) 2>/dev/null)'
+`
+
+func (c *cli) CmdCompletion(args ...string) error {
+	cmd := SubCmd("completion", "[bash|zsh|fish]", "Generate the completion script of the given shell.\n\nThe scripts call back into mirrorbits to complete the mirror\nidentifiers and the paths of the repository.")
+	list := cmd.String("list", "", "Print the candidates of the given kind (mirrors or files) instead")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+
+	switch *list {
+	case "":
+	case "mirrors":
+		for _, name := range c.mirrorNames() {
+			fmt.Println(name)
+		}
+		return nil
+	case "files":
+		client, err := c.GetRPC()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+		defer cancel()
+		reply, err := client.ListFiles(ctx, &rpc.ListFilesRequest{
+			Prefix: cmd.Arg(0),
+		})
+		if err != nil {
+			return err
+		}
+		for _, file := range reply.Files {
+			fmt.Println(file)
+		}
+		return nil
+	default:
+		cmd.Usage()
+		return nil
+	}
+
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	var names []string
+	for _, command := range commands {
+		names = append(names, command[0])
+	}
+
+	bash := fmt.Sprintf(bashCompletion,
+		strings.Join(append(names, "daemon", "help"), " "),
+		strings.Join(mirrorCommands, "|"),
+		strings.Join(fileCommands, "|"))
+
+	switch cmd.Arg(0) {
+	case "bash":
+		fmt.Print(bash)
+	case "zsh":
+		fmt.Print(zshCompletion + "\n" + bash)
+	case "fish":
+		var subcommands string
+		for _, command := range commands {
+			subcommands += fmt.Sprintf("complete -c mirrorbits -n '__fish_use_subcommand' -a %s -d '%s'\n",
+				command[0], strings.Replace(command[1], "'", `\'`, -1))
+		}
+		subcommands += "complete -c mirrorbits -n '__fish_use_subcommand' -a daemon -d 'Start the server'"
+		fmt.Printf(fishCompletion, subcommands,
+			strings.Join(mirrorCommands, " "),
+			strings.Join(fileCommands, " "))
+	default:
+		return fmt.Errorf("unsupported shell: %s", cmd.Arg(0))
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/etix/mirrorbits/core"
+	"github.com/etix/mirrorbits/rpc"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Exit codes of the CLI
+const (
+	ExitSuccess             = 0
+	ExitFailure             = 1 // Unexpected error
+	ExitNotFound            = 3 // No mirror matches the given identifier
+	ExitAmbiguous           = 4 // Several mirrors match the given identifier
+	ExitInvalid             = 5 // The validation of the arguments failed
+	ExitConflict            = 6 // The name is already taken by another mirror
+	ExitUnauthorized        = 7 // The server refused the password
+	ExitServerUnreachable   = 8 // The server cannot be contacted
+	ExitDatabaseUnreachable = 9 // The server cannot reach the database
+)
+
+var exitReasons = map[int]string{
+	ExitFailure:             "failure",
+	ExitNotFound:            "not_found",
+	ExitAmbiguous:           "ambiguous",
+	ExitInvalid:             "invalid",
+	ExitConflict:            "conflict",
+	ExitUnauthorized:        "unauthorized",
+	ExitServerUnreachable:   "server_unreachable",
+	ExitDatabaseUnreachable: "database_unreachable",
+}
+
+// Error is an error of the CLI carrying its exit code
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// newError returns an error exiting the CLI with the given code
+func newError(code int, format string, args ...interface{}) error {
+	return &Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// ExitCode returns the exit code matching the given error
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+
+	cause := errors.Cause(err)
+	if e, ok := cause.(*Error); ok {
+		return e.Code
+	}
+
+	if s, ok := status.FromError(cause); ok {
+		if s.Message() == rpc.ErrNameAlreadyTaken.Error() {
+			return ExitConflict
+		}
+		switch s.Code() {
+		case codes.NotFound:
+			return ExitNotFound
+		case codes.InvalidArgument, codes.FailedPrecondition:
+			return ExitInvalid
+		case codes.AlreadyExists:
+			return ExitConflict
+		case codes.Unauthenticated, codes.PermissionDenied:
+			return ExitUnauthorized
+		case codes.Unavailable:
+			return ExitDatabaseUnreachable
+		}
+	}
+
+	return ExitFailure
+}
+
+// HandleError prints the error according to the selected output mode and
+// returns the exit code to use
+func HandleError(err error) int {
+	code := ExitCode(err)
+	if err == nil || core.QuietErrors {
+		return code
+	}
+
+	if core.JSONErrors {
+		out, _ := json.Marshal(struct {
+			Error  string `json:"error"`
+			Code   int    `json:"code"`
+			Reason string `json:"reason"`
+		}{
+			Error:  err.Error(),
+			Code:   code,
+			Reason: exitReasons[code],
+		})
+		fmt.Fprintf(os.Stderr, "%s\n", out)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+	}
+
+	return code
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/etix/mirrorbits/rpc"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{nil, ExitSuccess},
+		{errors.New("failure"), ExitFailure},
+		{newError(ExitAmbiguous, "Multiple match"), ExitAmbiguous},
+		{errors.Wrap(newError(ExitNotFound, "No match"), "scan error"), ExitNotFound},
+		{status.Error(codes.NotFound, "mirror not found"), ExitNotFound},
+		{errors.Wrap(status.Error(codes.InvalidArgument, "invalid country"), "edit error"), ExitInvalid},
+		{status.Error(codes.Unknown, rpc.ErrNameAlreadyTaken.Error()), ExitConflict},
+		{status.Error(codes.Unavailable, "redis endpoint unreachable"), ExitDatabaseUnreachable},
+		{status.Error(codes.Unauthenticated, "access denied"), ExitUnauthorized},
+	}
+
+	for i, test := range tests {
+		if code := ExitCode(test.err); code != test.code {
+			t.Fatalf("test %d: expected exit code %d, got %d", i, test.code, code)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/etix/mirrorbits/mirrors"
+	"github.com/etix/mirrorbits/rpc"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// exportURLs selects the URLs of the mirrors to export
+type exportURLs struct {
+	http, rsync, ftp bool
+}
+
+// writeMirmon writes the given mirrors in the format of the list of mirrors
+// of mirmon
+func writeMirmon(out io.Writer, list []*rpc.Mirror, urls exportURLs, disabled bool) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 8, 0, '\t', 0)
+
+	for _, m := range list {
+		if !disabled && !m.Enabled {
+			continue
+		}
+		country := mirrors.CountryList(m.CountryCodes).Primary()
+
+		selected := make([]string, 0, 3)
+		if urls.rsync && m.RsyncURL != "" {
+			selected = append(selected, m.RsyncURL)
+		}
+		if urls.http && m.HttpURL != "" {
+			selected = append(selected, m.HttpURL)
+		}
+		if urls.ftp && m.FtpURL != "" {
+			selected = append(selected, m.FtpURL)
+		}
+
+		for _, u := range selected {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", country, u, m.AdminEmail)
+		}
+	}
+
+	w.Flush()
+}
+
+// writeMirrorBrain writes the SQL statements filling the server table of a
+// MirrorBrain database with the given mirrors. The score of MirrorBrain
+// being a weight of 100 by default, the score of the mirrors is added to it.
+func writeMirrorBrain(w io.Writer, list []*rpc.Mirror, urls exportURLs, disabled bool) {
+	quote := func(s string) string {
+		return "'" + strings.Replace(s, "'", "''", -1) + "'"
+	}
+	url := func(u string, selected bool) string {
+		if !selected {
+			u = ""
+		}
+		return quote(u)
+	}
+
+	fmt.Fprintln(w, "BEGIN;")
+	for _, m := range list {
+		if !disabled && !m.Enabled {
+			continue
+		}
+
+		countries := mirrors.CountryList(m.CountryCodes)
+		var others []string
+		if len(countries) > 1 {
+			for _, c := range countries[1:] {
+				others = append(others, strings.ToLower(c))
+			}
+		}
+		score := 100 + int(m.Score)
+		if score < 1 {
+			score = 1
+		}
+		lastScan := "NULL"
+		if t, err := ptypes.Timestamp(m.LastSuccessfulSync); err == nil && !t.IsZero() && t.Unix() > 0 {
+			lastScan = quote(t.UTC().Format("2006-01-02 15:04:05+00"))
+		}
+		lat, lng := "NULL", "NULL"
+		if m.Latitude != 0 || m.Longitude != 0 {
+			lat = fmt.Sprintf("%.3f", m.Latitude)
+			lng = fmt.Sprintf("%.3f", m.Longitude)
+		}
+
+		fmt.Fprintf(w, "INSERT INTO server (identifier, baseurl, baseurl_ftp, baseurl_rsync, enabled, status_baseurl, "+
+			"region, country, asn, prefix, score, scan_fpm, last_scan, comment, operator_name, operator_url, public_notes, "+
+			"admin, admin_email, lat, lng, country_only, region_only, as_only, prefix_only, other_countries, file_maxsize) "+
+			"VALUES (%s, %s, %s, %s, %t, %t, %s, %s, %d, '', %d, 0, %s, %s, %s, %s, '', %s, %s, %s, %s, %t, %t, %t, false, %s, 0);\n",
+			quote(m.Name), url(m.HttpURL, urls.http), url(m.FtpURL, urls.ftp), url(m.RsyncURL, urls.rsync),
+			m.Enabled, m.Up, quote(strings.ToLower(m.ContinentCode)), quote(strings.ToLower(countries.Primary())),
+			m.Asnum, score, lastScan, quote(m.Comment), quote(m.SponsorName), quote(m.SponsorURL),
+			quote(m.AdminName), quote(m.AdminEmail), lat, lng, m.CountryOnly, m.ContinentOnly, m.ASOnly,
+			quote(strings.Join(others, ",")))
+	}
+	fmt.Fprintln(w, "COMMIT;")
+}
+
+// jsonExport is the schema of 'export json', kept stable for the generators
+// of public lists of mirrors
+type jsonExport struct {
+	// Generated is the time of the export
+	Generated time.Time    `json:"generated"`
+	Mirrors   []jsonMirror `json:"mirrors"`
+}
+
+type jsonMirror struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+	// URLs of the mirror by protocol (http, rsync, ftp), the missing ones
+	// being omitted
+	URLs map[string]string `json:"urls"`
+	// Country is the ISO 3166-1 code of the primary country of the mirror,
+	// Countries all the countries it serves, the primary one first
+	Country   string       `json:"country"`
+	Countries []string     `json:"countries"`
+	Continent string       `json:"continent"`
+	Sponsor   *jsonSponsor `json:"sponsor,omitempty"`
+	// State is either up, down or disabled
+	State string `json:"state"`
+	// Lag is the number of seconds since the last successful scan of the
+	// mirror, null if it has never been scanned
+	Lag   *int64 `json:"lag"`
+	Score int32  `json:"score"`
+}
+
+type jsonSponsor struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+	Logo string `json:"logo,omitempty"`
+}
+
+// writeJSON writes the given mirrors in json (see jsonExport)
+func writeJSON(w io.Writer, list []*rpc.Mirror, urls exportURLs, disabled bool, now time.Time) error {
+	export := jsonExport{
+		Generated: now.UTC(),
+		Mirrors:   []jsonMirror{},
+	}
+	for _, m := range list {
+		if !disabled && !m.Enabled {
+			continue
+		}
+		countries := mirrors.CountryList(m.CountryCodes)
+		jm := jsonMirror{
+			ID:        m.ID,
+			Name:      m.Name,
+			URLs:      make(map[string]string),
+			Country:   countries.Primary(),
+			Countries: append([]string{}, countries...),
+			Continent: m.ContinentCode,
+			State:     "down",
+			Score:     m.Score,
+		}
+		if urls.http && m.HttpURL != "" {
+			jm.URLs["http"] = m.HttpURL
+		}
+		if urls.rsync && m.RsyncURL != "" {
+			jm.URLs["rsync"] = m.RsyncURL
+		}
+		if urls.ftp && m.FtpURL != "" {
+			jm.URLs["ftp"] = m.FtpURL
+		}
+		if m.SponsorName != "" {
+			jm.Sponsor = &jsonSponsor{
+				Name: m.SponsorName,
+				URL:  m.SponsorURL,
+				Logo: m.SponsorLogoURL,
+			}
+		}
+		if !m.Enabled {
+			jm.State = "disabled"
+		} else if m.Up {
+			jm.State = "up"
+		}
+		if t, err := ptypes.Timestamp(m.LastSuccessfulSync); err == nil && t.Unix() > 0 {
+			lag := int64(now.Sub(t) / time.Second)
+			jm.Lag = &lag
+		}
+		export.Mirrors = append(export.Mirrors, jm)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(export)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etix/mirrorbits/rpc"
+	"github.com/golang/protobuf/ptypes"
+)
+
+func TestWriteMirrorBrain(t *testing.T) {
+	list := []*rpc.Mirror{
+		{
+			Name:          "m1",
+			HttpURL:       "http://m1.mirror/repo/",
+			RsyncURL:      "rsync://m1.mirror/repo/",
+			Enabled:       true,
+			Up:            true,
+			ContinentCode: "EU",
+			CountryCodes:  []string{"FR", "BE"},
+			Score:         -20,
+			Comment:       "Sponsor's mirror",
+			Latitude:      48.85,
+			Longitude:     2.35,
+		},
+		{
+			Name:    "m2",
+			HttpURL: "http://m2.mirror/",
+		},
+	}
+
+	var buf bytes.Buffer
+	writeMirrorBrain(&buf, list, exportURLs{http: true}, false)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[0] != "BEGIN;" || lines[2] != "COMMIT;" {
+		t.Fatalf("Expected a single mirror in a transaction, got:\n%s", buf.String())
+	}
+	values := lines[1][strings.Index(lines[1], "VALUES"):]
+	expected := "VALUES ('m1', 'http://m1.mirror/repo/', '', '', true, true, 'eu', 'fr', 0, '', 80, 0, NULL, " +
+		"'Sponsor''s mirror', '', '', '', '', '', 48.850, 2.350, false, false, false, false, 'be', 0);"
+	if values != expected {
+		t.Fatalf("Unexpected values:\n%s\nexpected:\n%s", values, expected)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	now := time.Date(2019, 3, 15, 12, 0, 0, 0, time.UTC)
+	synced, _ := ptypes.TimestampProto(now.Add(-time.Hour))
+	list := []*rpc.Mirror{
+		{
+			ID:                 1,
+			Name:               "m1",
+			HttpURL:            "http://m1.mirror/repo/",
+			RsyncURL:           "rsync://m1.mirror/repo/",
+			Enabled:            true,
+			Up:                 true,
+			ContinentCode:      "EU",
+			CountryCodes:       []string{"FR", "BE"},
+			SponsorName:        "Sponsor",
+			LastSuccessfulSync: synced,
+		},
+		{
+			ID:      2,
+			Name:    "m2",
+			HttpURL: "http://m2.mirror/",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, list, exportURLs{http: true, rsync: true}, true, now); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	var export jsonExport
+	if err := json.Unmarshal(buf.Bytes(), &export); err != nil {
+		t.Fatalf("Invalid json: %s", err)
+	}
+	if !export.Generated.Equal(now) || len(export.Mirrors) != 2 {
+		t.Fatalf("Unexpected export %+v", export)
+	}
+	m1, m2 := export.Mirrors[0], export.Mirrors[1]
+	if m1.Country != "FR" || len(m1.Countries) != 2 || m1.URLs["rsync"] != "rsync://m1.mirror/repo/" ||
+		m1.State != "up" || m1.Lag == nil || *m1.Lag != 3600 || m1.Sponsor == nil || m1.Sponsor.Name != "Sponsor" {
+		t.Fatalf("Unexpected mirror %+v", m1)
+	}
+	if m2.State != "disabled" || m2.Lag != nil || m2.Sponsor != nil {
+		t.Fatalf("Unexpected mirror %+v", m2)
+	}
+}
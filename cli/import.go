@@ -0,0 +1,240 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/etix/mirrorbits/mirrors"
+	"github.com/etix/mirrorbits/rpc"
+	"github.com/etix/mirrorbits/utils"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/status"
+)
+
+func (c *cli) CmdImport(args ...string) error {
+	cmd := SubCmd("import", "-from FORMAT FILE", "Import the mirrors of another mirror registry, the mirrors already known by\n"+
+		"name being skipped.\n\nAvailable formats:\n"+
+		"  mirrorbrain    the server table of MirrorBrain in CSV, e.g. dumped with\n"+
+		"                 psql -c \"\\copy server TO 'server.csv' CSV HEADER\"\n"+
+		"  mirrormanager  a json list of the hosts of MirrorManager with their name,\n"+
+		"                 country, asn, comment, admin_active, user_active, private,\n"+
+		"                 admin_name, admin_email and urls (list of their base URLs)")
+	from := cmd.String("from", "", "Format of the file: mirrorbrain or mirrormanager")
+	dryRun := cmd.Bool("dry-run", false, "Show the mirrors to import without importing them")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 1 || (*from != "mirrorbrain" && *from != "mirrormanager") {
+		cmd.Usage()
+		return nil
+	}
+
+	f, err := os.Open(cmd.Arg(0))
+	if err != nil {
+		return newError(ExitInvalid, "%s", err)
+	}
+	defer f.Close()
+
+	var list []*mirrors.Mirror
+	if *from == "mirrorbrain" {
+		list, err = parseMirrorBrain(f)
+	} else {
+		list, err = parseMirrorManager(f)
+	}
+	if err != nil {
+		return newError(ExitInvalid, "%s: %s", cmd.Arg(0), err)
+	}
+
+	if *dryRun {
+		for _, m := range list {
+			state := "disabled"
+			if m.Enabled {
+				state = "enabled"
+			}
+			fmt.Printf("%s\t%s\t%s\t%s\n", m.Name, m.CountryCodes.Primary(), m.HttpURL, state)
+		}
+		fmt.Printf("%d mirror%s to import\n", len(list), utils.Plural(len(list)))
+		return nil
+	}
+
+	client, err := c.GetRPC()
+	if err != nil {
+		return err
+	}
+
+	added, skipped := 0, 0
+	for _, m := range list {
+		in, err := rpc.MirrorToRPC(m)
+		if err != nil {
+			return errors.Wrap(err, "import error")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+		reply, err := client.AddMirror(ctx, in)
+		cancel()
+		if err != nil {
+			if status.Convert(err).Message() == rpc.ErrNameAlreadyTaken.Error() {
+				fmt.Printf("Mirror %s already exists, skipped\n", m.Name)
+				skipped++
+				continue
+			}
+			return errors.Wrapf(err, "import error on %s", m.Name)
+		}
+		for _, w := range reply.Warnings {
+			fmt.Printf("Mirror %s: %s\n", m.Name, w)
+		}
+		added++
+	}
+
+	fmt.Printf("%d mirror%s imported, %d skipped\n", added, utils.Plural(added), skipped)
+	return nil
+}
+
+// importName turns the name of a mirror of another registry into an
+// identifier
+func importName(name string) string {
+	return strings.Join(strings.Fields(name), "-")
+}
+
+// parseMirrorBrain returns the mirrors of a CSV dump of the server table of
+// MirrorBrain, with its header. The score of MirrorBrain being a weight of
+// 100 by default, 100 is subtracted from it (see 'export mirrorbrain').
+func parseMirrorBrain(r io.Reader) ([]*mirrors.Mirror, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"identifier", "baseurl"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %s", name)
+		}
+	}
+
+	var list []*mirrors.Mirror
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		get := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		isTrue := func(name string) bool {
+			v := strings.ToLower(get(name))
+			return v == "t" || v == "true" || v == "1"
+		}
+
+		m := &mirrors.Mirror{
+			Name:          importName(get("identifier")),
+			HttpURL:       get("baseurl"),
+			FtpURL:        get("baseurl_ftp"),
+			RsyncURL:      get("baseurl_rsync"),
+			Enabled:       isTrue("enabled"),
+			ContinentCode: strings.ToUpper(get("region")),
+			SponsorName:   get("operator_name"),
+			SponsorURL:    get("operator_url"),
+			AdminName:     get("admin"),
+			AdminEmail:    get("admin_email"),
+			Comment:       get("comment"),
+			CountryOnly:   isTrue("country_only"),
+			ContinentOnly: isTrue("region_only"),
+			ASOnly:        isTrue("as_only"),
+		}
+		if m.Name == "" || m.HttpURL == "" {
+			return nil, fmt.Errorf("line %d: the identifier and the baseurl are required", line)
+		}
+		countries := get("country")
+		if others := get("other_countries"); others != "" {
+			countries += "," + others
+		}
+		if m.CountryCodes, err = mirrors.ParseCountryList(countries); err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		if asn, err := strconv.ParseUint(get("asn"), 10, 32); err == nil {
+			m.Asnum = uint(asn)
+		}
+		if score, err := strconv.Atoi(get("score")); err == nil {
+			m.Score = score - 100
+		}
+		m.ManualLocation = len(m.CountryCodes) > 0 || m.ContinentCode != ""
+		list = append(list, m)
+	}
+	return list, nil
+}
+
+// mirrorManagerHost is a host of MirrorManager along with its URLs and its
+// administrator
+type mirrorManagerHost struct {
+	Name        string   `json:"name"`
+	Country     string   `json:"country"`
+	ASN         uint     `json:"asn"`
+	Comment     string   `json:"comment"`
+	AdminActive bool     `json:"admin_active"`
+	UserActive  bool     `json:"user_active"`
+	Private     bool     `json:"private"`
+	AdminName   string   `json:"admin_name"`
+	AdminEmail  string   `json:"admin_email"`
+	URLs        []string `json:"urls"`
+}
+
+// parseMirrorManager returns the mirrors of a json list of the hosts of
+// MirrorManager. The private hosts and the ones deactivated are imported
+// disabled.
+func parseMirrorManager(r io.Reader) ([]*mirrors.Mirror, error) {
+	var hosts []mirrorManagerHost
+	if err := json.NewDecoder(r).Decode(&hosts); err != nil {
+		return nil, err
+	}
+
+	var list []*mirrors.Mirror
+	for i, h := range hosts {
+		m := &mirrors.Mirror{
+			Name:       importName(h.Name),
+			Asnum:      h.ASN,
+			Comment:    h.Comment,
+			AdminName:  h.AdminName,
+			AdminEmail: h.AdminEmail,
+			Enabled:    h.AdminActive && h.UserActive && !h.Private,
+		}
+		for _, u := range h.URLs {
+			switch {
+			case (strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://")) && (m.HttpURL == "" || strings.HasPrefix(u, "https://")):
+				// HTTPS is preferred
+				m.HttpURL = u
+			case strings.HasPrefix(u, "rsync://") && m.RsyncURL == "":
+				m.RsyncURL = u
+			case strings.HasPrefix(u, "ftp://") && m.FtpURL == "":
+				m.FtpURL = u
+			}
+		}
+		if m.Name == "" || m.HttpURL == "" {
+			return nil, fmt.Errorf("host #%d: the name and an HTTP URL are required", i+1)
+		}
+		var err error
+		if m.CountryCodes, err = mirrors.ParseCountryList(h.Country); err != nil {
+			return nil, fmt.Errorf("host %s: %s", h.Name, err)
+		}
+		m.ManualLocation = len(m.CountryCodes) > 0
+		list = append(list, m)
+	}
+	return list, nil
+}
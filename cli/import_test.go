@@ -0,0 +1,63 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMirrorBrain(t *testing.T) {
+	dump := `id,identifier,baseurl,baseurl_ftp,baseurl_rsync,enabled,region,country,asn,score,admin_email,other_countries,country_only
+1,mirror one,http://m1.mirror/repo/,,rsync://m1.mirror/repo/,t,eu,fr,3215,80,admin@m1.mirror,"be,lu",f
+2,m2,http://m2.mirror/,,,f,na,us,,100,,,t
+`
+	list, err := parseMirrorBrain(strings.NewReader(dump))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 mirrors, got %d", len(list))
+	}
+	m1, m2 := list[0], list[1]
+	if m1.Name != "mirror-one" || m1.HttpURL != "http://m1.mirror/repo/" || m1.RsyncURL != "rsync://m1.mirror/repo/" ||
+		!m1.Enabled || m1.ContinentCode != "EU" || strings.Join(m1.CountryCodes, ",") != "FR,BE,LU" ||
+		m1.Asnum != 3215 || m1.Score != -20 || m1.AdminEmail != "admin@m1.mirror" || m1.CountryOnly {
+		t.Fatalf("Unexpected mirror %+v", m1)
+	}
+	if m2.Enabled || m2.Score != 0 || !m2.CountryOnly || !m2.ManualLocation {
+		t.Fatalf("Unexpected mirror %+v", m2)
+	}
+
+	if _, err := parseMirrorBrain(strings.NewReader("id,identifier\n1,m1\n")); err == nil {
+		t.Fatalf("The baseurl column is required")
+	}
+}
+
+func TestParseMirrorManager(t *testing.T) {
+	hosts := `[
+	{"name": "m1", "country": "de", "admin_active": true, "user_active": true,
+	 "urls": ["rsync://m1.mirror/fedora/", "http://m1.mirror/fedora/", "https://m1.mirror/fedora/"]},
+	{"name": "m2", "country": "jp", "admin_active": true, "user_active": true, "private": true,
+	 "urls": ["http://m2.mirror/"]}
+]`
+	list, err := parseMirrorManager(strings.NewReader(hosts))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 mirrors, got %d", len(list))
+	}
+	if m := list[0]; m.HttpURL != "https://m1.mirror/fedora/" || m.RsyncURL != "rsync://m1.mirror/fedora/" ||
+		!m.Enabled || m.CountryCodes.Primary() != "DE" {
+		t.Fatalf("Unexpected mirror %+v", m)
+	}
+	if list[1].Enabled {
+		t.Fatalf("The private hosts must be disabled")
+	}
+
+	if _, err := parseMirrorManager(strings.NewReader(`[{"name": "m3", "urls": ["rsync://m3.mirror/"]}]`)); err == nil {
+		t.Fatalf("An HTTP URL is required")
+	}
+}
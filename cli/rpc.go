@@ -5,10 +5,11 @@ package cli
 
 import (
 	"context"
-	"fmt"
-	"os"
+	"flag"
+	"net"
 	"strconv"
 
+	. "github.com/etix/mirrorbits/config"
 	"github.com/etix/mirrorbits/core"
 	"github.com/etix/mirrorbits/rpc"
 	"github.com/golang/protobuf/ptypes/empty"
@@ -17,7 +18,7 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-func (c *cli) GetRPC() rpc.CLIClient {
+func (c *cli) GetRPC() (rpc.CLIClient, error) {
 	c.Lock()
 	defer c.Unlock()
 
@@ -28,24 +29,56 @@ func (c *cli) GetRPC() rpc.CLIClient {
 			grpc.FailOnNonTempDialError(true),
 			grpc.WithPerRPCCredentials(c.creds))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "rpc: %s\n", err)
-			os.Exit(1)
+			return nil, newError(ExitServerUnreachable, "rpc: %s", err)
 		}
-		c.rpcconn = conn
-		client := rpc.NewCLIClient(c.rpcconn)
+		client := rpc.NewCLIClient(conn)
 		_, err = client.Ping(context.Background(), &empty.Empty{})
 		s := status.Convert(err)
 		if s.Code() == codes.Unauthenticated {
+			conn.Close()
 			if len(c.creds.Password) == 0 {
-				fmt.Fprintf(os.Stderr, "Please set the server password with the -P option.\n")
-			} else {
-				fmt.Fprintf(os.Stderr, "Password refused\n")
+				return nil, newError(ExitUnauthorized, "Please set the server password with the -P option.")
 			}
-			os.Exit(1)
+			return nil, newError(ExitUnauthorized, "Password refused")
+		}
+		c.rpcconn = conn
+	}
+
+	return rpc.NewCLIClient(c.rpcconn), nil
+}
+
+// loadRPCSettings takes the address and the password of the server from the
+// configuration file, if any, unless they are given on the command line
+func loadRPCSettings() error {
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	if FindConfigFile() == "" {
+		return nil
+	}
+	if err := LoadConfig(); err != nil {
+		if explicit["config"] {
+			return err
 		}
+		return nil
+	}
+
+	host, port, err := net.SplitHostPort(GetConfig().RPCListenAddress)
+	if err == nil {
+		if ip := net.ParseIP(host); !explicit["h"] && host != "" && (ip == nil || !ip.IsUnspecified()) {
+			core.RPCHost = host
+		}
+		if p, err := strconv.ParseUint(port, 10, 16); err == nil && !explicit["p"] {
+			core.RPCPort = uint(p)
+		}
+	}
+	if !explicit["P"] && !core.RPCAskPass {
+		core.RPCPassword = GetConfig().RPCPassword
 	}
 
-	return rpc.NewCLIClient(c.rpcconn)
+	return nil
 }
 
 type loginCreds struct {
@@ -0,0 +1,194 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/etix/mirrorbits/rpc"
+	"github.com/etix/mirrorbits/utils"
+	"github.com/golang/protobuf/ptypes/empty"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	shellPrompt = "mirrorbits> "
+)
+
+func (c *cli) CmdShell(args ...string) error {
+	cmd := SubCmd("shell", "", "Start an interactive shell")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return fmt.Errorf("shell: the standard input is not a terminal")
+	}
+
+	// Connect right away, the connection is then reused by all the commands
+	if _, err := c.GetRPC(); err != nil {
+		return err
+	}
+
+	term := terminal.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, shellPrompt)
+	term.AutoCompleteCallback = c.shellComplete
+	if width, height, err := terminal.GetSize(fd); err == nil {
+		term.SetSize(width, height)
+	}
+
+	fmt.Println("Type 'help' for the list of commands, 'exit' to quit.")
+
+	for {
+		state, err := terminal.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		line, err := term.ReadLine()
+		terminal.Restore(fd, state)
+		if err == io.EOF {
+			fmt.Println("")
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+
+		switch args[0] {
+		case "exit", "quit":
+			return nil
+		case "help":
+			c.CmdHelp()
+			continue
+		case "shell", "daemon":
+			fmt.Fprintf(os.Stderr, "Command not available in the shell: %s\n", args[0])
+			continue
+		}
+
+		method, exists := c.getMethod(args[0])
+		if !exists {
+			fmt.Fprintf(os.Stderr, "Command not found: %s\n", args[0])
+			continue
+		}
+		ret := method.Func.CallSlice([]reflect.Value{
+			reflect.ValueOf(c),
+			reflect.ValueOf(args[1:]),
+		})[0].Interface()
+		if ret != nil {
+			HandleError(ret.(error))
+		}
+	}
+}
+
+// shellComplete completes the command names and the mirror identifiers
+// when the tab key is pressed
+func (c *cli) shellComplete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	head := line[:pos]
+	start := strings.LastIndexAny(head, " \t") + 1
+	word := head[start:]
+
+	var candidates []string
+	if fields := strings.Fields(head[:start]); len(fields) == 0 {
+		for _, command := range commands {
+			candidates = append(candidates, command[0])
+		}
+		candidates = append(candidates, "exit", "help")
+	} else if utils.IsInSlice(fields[0], fileCommands) {
+		candidates = c.fileNames(word)
+	} else if !strings.HasPrefix(word, "-") {
+		candidates = c.mirrorNames()
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+
+	completion := commonPrefix(matches)
+	if len(matches) == 1 && !strings.HasSuffix(completion, "/") {
+		completion += " "
+	}
+	if completion == word {
+		return "", 0, false
+	}
+
+	return head[:start] + completion + line[pos:], start + len(completion), true
+}
+
+// mirrorNames returns the sorted identifiers of all the mirrors
+func (c *cli) mirrorNames() []string {
+	client, err := c.GetRPC()
+	if err != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	list, err := client.List(ctx, &empty.Empty{})
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(list.Mirrors))
+	for _, mirror := range list.Mirrors {
+		names = append(names, mirror.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fileNames returns the files and directories of the repository starting
+// with the given prefix
+func (c *cli) fileNames(prefix string) []string {
+	client, err := c.GetRPC()
+	if err != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout)
+	defer cancel()
+	reply, err := client.ListFiles(ctx, &rpc.ListFilesRequest{
+		Prefix: prefix,
+	})
+	if err != nil {
+		return nil
+	}
+	return reply.Files
+}
+
+// commonPrefix returns the longest prefix shared by all the given strings
+func commonPrefix(list []string) string {
+	prefix := list[0]
+	for _, s := range list[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+func (c *cli) CmdSimulate(args ...string) error {
+	cmd := SubCmd("simulate", "[OPTIONS]", "Run the mirror selection over the requests of a download log with the\ncurrent mirrors and weights, and compare the resulting distribution to\nthe one of the log.")
+	logFile := cmd.String("log", "-", "Download log to replay (- for the standard input)")
+	max := cmd.Int("max", 0, "Maximum number of requests to simulate (0 for all)")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 || *max < 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	list, err := readReplayedRequests(*logFile)
+	if err != nil {
+		return errors.Wrap(err, "simulate error")
+	}
+	if *max > 0 && len(list) > *max {
+		list = list[:*max]
+	}
+	if len(list) == 0 {
+		return newError(ExitInvalid, "No request to simulate")
+	}
+
+	simulator, err := newLocalSimulator()
+	if err != nil {
+		return errors.Wrap(err, "simulate error")
+	}
+
+	historical := make(map[string]int)
+	simulated := make(map[string]int)
+	var historicalTotal, simulatedTotal, unserved, failures int
+
+	for _, r := range list {
+		if r.Mirror != "" {
+			historical[r.Mirror]++
+			historicalTotal++
+		}
+		mlist, _, err := simulator.Select(r.Path, r.IP)
+		if err != nil {
+			failures++
+			continue
+		}
+		if len(mlist) == 0 {
+			unserved++
+			continue
+		}
+		simulated[mlist[0].Name]++
+		simulatedTotal++
+	}
+
+	fmt.Printf(" %-12s %d\n", "Requests:", len(list))
+	fmt.Printf(" %-12s %d\n", "Redirected:", simulatedTotal)
+	fmt.Printf(" %-12s %d\n", "No mirror:", unserved)
+	fmt.Printf(" %-12s %d\n", "Errors:", failures)
+
+	names := make(map[string]bool)
+	for name := range historical {
+		names[name] = true
+	}
+	for name := range simulated {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if simulated[sorted[i]] == simulated[sorted[j]] {
+			return sorted[i] < sorted[j]
+		}
+		return simulated[sorted[i]] > simulated[sorted[j]]
+	})
+
+	share := func(count, total int) float64 {
+		if total == 0 {
+			return 0
+		}
+		return float64(count) * 100 / float64(total)
+	}
+
+	fmt.Println("")
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprint(w, " Mirror\tLog\t\tSimulated\t\tDelta\n")
+	for _, name := range sorted {
+		before := share(historical[name], historicalTotal)
+		after := share(simulated[name], simulatedTotal)
+		fmt.Fprintf(w, " %s\t%d\t%.1f%%\t%d\t%.1f%%\t%+.1f%%\n", name,
+			historical[name], before, simulated[name], after, after-before)
+	}
+	w.Flush()
+
+	return nil
+}
@@ -0,0 +1,321 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etix/mirrorbits/core"
+	"github.com/etix/mirrorbits/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultReleaseURL = "https://api.github.com/repos/etix/mirrorbits/releases/latest"
+	// Maximum size of a downloaded release archive
+	maxReleaseSize = 256 << 20
+)
+
+var (
+	// The commits made since the tag in the output of git describe
+	describeSuffix = regexp.MustCompile(`^([0-9]+)-g[0-9a-f]+`)
+)
+
+// release is the description of a release as returned by the GitHub API
+type release struct {
+	TagName string         `json:"tag_name"`
+	HTMLURL string         `json:"html_url"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// asset returns the URL of the asset of the given name
+func (r *release) asset(name string) (string, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL, true
+		}
+	}
+	return "", false
+}
+
+func (c *cli) CmdCheckupdate(args ...string) error {
+	cmd := SubCmd("check-update", "[OPTIONS]", "Check if a new release is available.\n\nThe release archive can be downloaded and verified against its SHA-256\nchecksum and its GPG signature made by one of the given signers, the\nbinary being written next to the current one (with the .new extension) or replacing it with -install.\nRun 'upgrade' afterwards to switch the server to the new binary.")
+	releaseURL := cmd.String("url", defaultReleaseURL, "URL of the description of the latest release")
+	download := cmd.Bool("download", false, "Download and verify the new release")
+	install := cmd.Bool("install", false, "Replace the current binary with the verified one (implies -download)")
+	gpgBinary := cmd.String("gpg", "gpg", "Path of the gpg binary")
+	gpgHomedir := cmd.String("gpg-homedir", "", "GnuPG home directory holding the keys of the release signers")
+	gpgKeyring := cmd.String("keyring", "", "Keyring holding the keys of the release signers (instead of the one of the home directory)")
+	signer := cmd.String("signer", "", "Fingerprints of the keys trusted to sign the releases (comma-separated)")
+	force := cmd.Bool("f", false, "Download the release even if it is not newer")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	client := &http.Client{
+		Timeout: 5 * time.Minute,
+	}
+
+	var rel release
+	body, err := httpGet(client, *releaseURL, 1<<20)
+	if err != nil {
+		return errors.Wrap(err, "check-update error")
+	}
+	if err := json.Unmarshal(body, &rel); err != nil || rel.TagName == "" {
+		return newError(ExitFailure, "check-update error: invalid release description")
+	}
+
+	current := core.VERSION
+	fmt.Printf(" %-17s %s\n", "Current version:", versionOrUnknown(current))
+	fmt.Printf(" %-17s %s\n", "Latest release:", rel.TagName)
+
+	if current != "" && compareVersions(current, rel.TagName) >= 0 {
+		fmt.Println("Mirrorbits is up to date.")
+		if !*force {
+			return nil
+		}
+	} else {
+		fmt.Println("A new release is available.")
+		if rel.HTMLURL != "" {
+			fmt.Printf(" %-17s %s\n", "Release notes:", rel.HTMLURL)
+		}
+	}
+
+	if !*download && !*install {
+		return nil
+	}
+
+	signers, err := parseSigners(*signer)
+	if err != nil {
+		return newError(ExitFailure, "%s", err)
+	}
+
+	// Never install a binary that cannot be verified
+	archive := fmt.Sprintf("mirrorbits-%s.tar.gz", rel.TagName)
+	archiveURL, ok1 := rel.asset(archive)
+	checksumURL, ok2 := rel.asset(archive + ".sha256")
+	signatureURL, ok3 := rel.asset(archive + ".asc")
+	if !ok1 || !ok2 || !ok3 {
+		return newError(ExitFailure, "The release lacks %s or its checksum (.sha256) or its signature (.asc)", archive)
+	}
+
+	fmt.Printf("Downloading %s...\n", archive)
+	data, err := httpGet(client, archiveURL, maxReleaseSize)
+	if err != nil {
+		return errors.Wrap(err, "download error")
+	}
+	checksum, err := httpGet(client, checksumURL, 4096)
+	if err != nil {
+		return errors.Wrap(err, "download error")
+	}
+	signature, err := httpGet(client, signatureURL, 64<<10)
+	if err != nil {
+		return errors.Wrap(err, "download error")
+	}
+
+	if err := verifyChecksum(data, checksum); err != nil {
+		return newError(ExitFailure, "%s: %s", archive, err)
+	}
+	if err := utils.GPGVerify(*gpgBinary, *gpgHomedir, *gpgKeyring, signers, data, signature); err != nil {
+		return newError(ExitFailure, "%s: invalid signature: %s", archive, err)
+	}
+	fmt.Println("Checksum and signature verified.")
+
+	binary, err := extractBinary(data)
+	if err != nil {
+		return errors.Wrap(err, archive)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	executable, err = filepath.EvalSymlinks(executable)
+	if err != nil {
+		return err
+	}
+
+	// Write the new binary next to the current one so the rename is atomic
+	target := executable + ".new"
+	if err := ioutil.WriteFile(target, binary, 0755); err != nil {
+		return errors.Wrap(err, "unable to write the new binary")
+	}
+	if !*install {
+		fmt.Printf("The new binary has been written to %s.\n", target)
+		fmt.Printf("Replace %s with it and run 'mirrorbits upgrade' to switch the server to the new release.\n", executable)
+		return nil
+	}
+	if err := os.Rename(target, executable); err != nil {
+		os.Remove(target)
+		return errors.Wrap(err, "unable to install the new binary")
+	}
+	fmt.Printf("%s has been replaced, run 'mirrorbits upgrade' to switch the server to the new release.\n", executable)
+	return nil
+}
+
+// parseSigners returns the fingerprints of the given comma-separated list,
+// the short key ids being rejected as they are easily forged
+func parseSigners(list string) ([]string, error) {
+	var signers []string
+	for _, s := range strings.Split(list, ",") {
+		fpr := strings.ToUpper(strings.Replace(strings.TrimSpace(s), " ", "", -1))
+		fpr = strings.TrimPrefix(fpr, "0X")
+		if fpr == "" {
+			continue
+		}
+		if len(fpr) != 40 && len(fpr) != 64 || strings.Trim(fpr, "0123456789ABCDEF") != "" {
+			return nil, fmt.Errorf("invalid signer %q: the full fingerprint of the key is required", s)
+		}
+		signers = append(signers, fpr)
+	}
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("the fingerprint of the release signer is required to verify the release (-signer)")
+	}
+	return signers, nil
+}
+
+// httpGet returns the body of the given URL, up to limit bytes
+func httpGet(client *http.Client, url string, limit int64) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%s: response too large", url)
+	}
+	return body, nil
+}
+
+// verifyChecksum compares the SHA-256 of data with the checksum file, in the
+// format of sha256sum
+func verifyChecksum(data, checksumFile []byte) error {
+	fields := strings.Fields(string(checksumFile))
+	if len(fields) == 0 {
+		return errors.New("empty checksum file")
+	}
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+		return errors.New("checksum mismatch")
+	}
+	return nil
+}
+
+// extractBinary returns the mirrorbits binary of a release archive
+func extractBinary(archive []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.New("no mirrorbits binary in the archive")
+		} else if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg && hdr.Name == "mirrorbits/mirrorbits" {
+			return ioutil.ReadAll(tr)
+		}
+	}
+}
+
+func versionOrUnknown(version string) string {
+	if version == "" {
+		return "unknown (development build)"
+	}
+	return version
+}
+
+// parseVersion splits a version as given by git describe (e.g.
+// v0.5.1-12-g0123abc-dirty) into its numbers, the number of commits made
+// since the tag and the pre-release suffix if any (e.g. rc1 for v0.6-rc1)
+func parseVersion(version string) (numbers []int, ahead int, prerelease string) {
+	version = strings.TrimPrefix(version, "v")
+	var suffix string
+	if i := strings.IndexByte(version, '-'); i >= 0 {
+		version, suffix = version[:i], version[i+1:]
+	}
+	for _, n := range strings.Split(version, ".") {
+		v, _ := strconv.Atoi(n)
+		numbers = append(numbers, v)
+	}
+	if suffix != "" {
+		if m := describeSuffix.FindStringSubmatch(suffix); m != nil {
+			ahead, _ = strconv.Atoi(m[1])
+		} else if suffix != "dirty" {
+			prerelease = suffix
+		}
+	}
+	return
+}
+
+// compareVersions returns -1, 0 or 1 whether a is older, the same or newer
+// than b
+func compareVersions(a, b string) int {
+	na, aheadA, preA := parseVersion(a)
+	nb, aheadB, preB := parseVersion(b)
+	for i := 0; i < len(na) || i < len(nb); i++ {
+		var va, vb int
+		if i < len(na) {
+			va = na[i]
+		}
+		if i < len(nb) {
+			vb = nb[i]
+		}
+		if va != vb {
+			if va < vb {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case preA != preB:
+		// A pre-release comes before the release
+		if preA != "" && (preB == "" || preA < preB) {
+			return -1
+		}
+		return 1
+	case aheadA != aheadB:
+		if aheadA < aheadB {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
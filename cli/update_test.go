@@ -0,0 +1,95 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"v0.5.1", "v0.5.1", 0},
+		{"v0.5.1", "v0.6", -1},
+		{"v0.6", "v0.5.1", 1},
+		{"v0.5", "v0.5.0", 0},
+		{"v0.10", "v0.9", 1},
+		// Built from commits made after the release
+		{"v0.5.1-12-g0123abc", "v0.5.1", 1},
+		{"v0.5.1-12-g0123abc-dirty", "v0.6", -1},
+		{"v0.5.1-dirty", "v0.5.1", 0},
+		// Pre-releases come before the release
+		{"v0.6-rc1", "v0.6", -1},
+		{"v0.6", "v0.6-rc1", 1},
+		{"v0.6-rc1", "v0.6-rc2", -1},
+		{"v0.6-rc1", "v0.5.1", 1},
+	}
+
+	for _, test := range tests {
+		if r := compareVersions(test.a, test.b); r != test.expected {
+			t.Errorf("compareVersions(%s, %s) = %d, expected %d", test.a, test.b, r, test.expected)
+		}
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("release")
+	sum := sha256.Sum256(data)
+
+	if err := verifyChecksum(data, []byte(hex.EncodeToString(sum[:])+"  mirrorbits-v0.6.tar.gz\n")); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := verifyChecksum([]byte("tampered"), []byte(hex.EncodeToString(sum[:]))); err == nil {
+		t.Fatalf("Expected a checksum mismatch")
+	}
+	if err := verifyChecksum(data, nil); err == nil {
+		t.Fatalf("Expected an error with an empty checksum file")
+	}
+}
+
+func TestExtractBinary(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range []struct{ name, content string }{
+		{"mirrorbits/mirrorbits.conf", "conf"},
+		{"mirrorbits/mirrorbits", "binary"},
+	} {
+		tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0755, Size: int64(len(f.content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(f.content))
+	}
+	tw.Close()
+	gz.Close()
+
+	binary, err := extractBinary(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if string(binary) != "binary" {
+		t.Fatalf("Expected the binary, got %q", binary)
+	}
+}
+
+func TestParseSigners(t *testing.T) {
+	signers, err := parseSigners("0x0123 4567 89ab cdef 0123  4567 89AB CDEF 0123 4567, 89ABCDEF0123456789ABCDEF0123456789ABCDEF")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(signers) != 2 || signers[0] != "0123456789ABCDEF0123456789ABCDEF01234567" {
+		t.Fatalf("Unexpected signers %v", signers)
+	}
+
+	for _, list := range []string{"", " , ", "89ABCDEF", "0123456789ABCDEF0123456789ABCDEF0123456Z"} {
+		if _, err := parseSigners(list); err == nil {
+			t.Errorf("parseSigners(%q) is supposed to fail", list)
+		}
+	}
+}
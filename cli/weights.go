@@ -0,0 +1,161 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/etix/mirrorbits/hooks"
+	"github.com/etix/mirrorbits/network"
+	"github.com/etix/mirrorbits/utils"
+	"github.com/pkg/errors"
+)
+
+func (c *cli) CmdWeights(args ...string) error {
+	cmd := SubCmd("weights", "[OPTIONS] FILE", "Show the share of the requests for a file each mirror would receive from a\nclient of the given location, with the current mirrors and settings.\n\nThe client is located by its IP address (-ip) or by its country, placed\nat the average location of the mirrors of this country unless -location\nis given. The score of a mirror can be changed for the preview only with\n-mirror NAME -set score=N, to try a tuning before applying it with 'edit'.")
+	ip := cmd.String("ip", "", "IP address of the client")
+	country := cmd.String("country", "", "Country code of the client")
+	location := cmd.String("location", "", "Coordinates of the client (LAT,LON) along with -country")
+	asnum := cmd.Uint("asn", 0, "AS number of the client along with -country")
+	mirror := cmd.String("mirror", "", "Name of the mirror changed by -set")
+	var fields fieldAssignments
+	cmd.Var(&fields, "set", "Preview the mirror with score=N")
+
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 1 || (*ip == "") == (*country == "") || (len(fields) > 0) != (*mirror != "") {
+		cmd.Usage()
+		return nil
+	}
+
+	score, overridden := 0, false
+	for _, field := range fields {
+		kv := strings.SplitN(field, "=", 2)
+		if !strings.EqualFold(strings.TrimSpace(kv[0]), "score") {
+			return newError(ExitInvalid, "Only the score can be previewed, got %s", kv[0])
+		}
+		v, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return newError(ExitInvalid, "Invalid score %s", kv[1])
+		}
+		score, overridden = v, true
+	}
+
+	simulator, err := newLocalSimulator()
+	if err != nil {
+		return errors.Wrap(err, "weights error")
+	}
+
+	file := cmd.Arg(0)
+
+	var clientInfo network.GeoIPRecord
+	if *ip != "" {
+		clientInfo = simulator.Locate(*ip)
+		if !clientInfo.IsValid() {
+			return newError(ExitInvalid, "Unable to locate %s", *ip)
+		}
+	} else {
+		code := strings.ToUpper(*country)
+		if *location != "" {
+			clientInfo, err = parseLocation(code, *location)
+			if err != nil {
+				return newError(ExitInvalid, "%s", err)
+			}
+		} else {
+			clientInfo, err = simulator.CountryLocation(file, code)
+			if err != nil {
+				return newError(ExitInvalid, "%s: %s", code, err)
+			}
+		}
+		clientInfo.ASNum = *asnum
+	}
+
+	// The score is changed in this process only, as a selection hook would
+	found := false
+	if overridden {
+		hooks.Register(func(req *hooks.Request, candidates []hooks.Candidate) {
+			for i := range candidates {
+				if candidates[i].Name == *mirror {
+					candidates[i].Score = score
+					found = true
+				}
+			}
+		})
+	}
+
+	selected, excluded, err := simulator.Weights(file, clientInfo)
+	if err != nil {
+		return errors.Wrap(err, "weights error")
+	}
+	if overridden && !found {
+		fmt.Fprintf(os.Stderr, "Warning: the mirror %s is not eligible for this file\n", *mirror)
+	}
+
+	fmt.Printf(" %-10s %s\n", "File:", file)
+	fmt.Printf(" %-10s %s (%s) %.2f,%.2f", "Client:", clientInfo.CountryCode, clientInfo.ContinentCode,
+		clientInfo.Latitude, clientInfo.Longitude)
+	if clientInfo.ASNum > 0 {
+		fmt.Printf(" AS%d", clientInfo.ASNum)
+	}
+	fmt.Println("")
+
+	fmt.Printf("\nEligible mirrors:\n")
+	if len(selected) == 0 {
+		fmt.Printf(" none\n")
+	}
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 2, ' ', 0)
+	if len(selected) > 0 {
+		fmt.Fprint(w, " Mirror\tScore\tDistance\tShare\n")
+	}
+	for _, m := range selected {
+		mark := ""
+		if overridden && m.Name == *mirror {
+			mark = " (preview)"
+		}
+		distance := fmt.Sprintf("%.0f km", m.Distance)
+		if m.CDN {
+			distance = "CDN"
+		}
+		fmt.Fprintf(w, " %s\t%d%s\t%s\t%.1f%%\n", m.Name, m.Score, mark, distance, m.Weight)
+	}
+	w.Flush()
+
+	if len(excluded) > 0 {
+		fmt.Printf("\nExcluded mirrors:\n")
+		w.Init(os.Stdout, 0, 8, 0, '\t', 0)
+		for _, m := range excluded {
+			fmt.Fprintf(w, " %s\t%s\n", m.Name, m.ExcludeReason)
+		}
+		w.Flush()
+	}
+
+	return nil
+}
+
+// parseLocation returns a client of the given country located at the given
+// coordinates (LAT,LON)
+func parseLocation(countryCode, location string) (network.GeoIPRecord, error) {
+	clientInfo := network.GeoIPRecord{
+		CountryCode:   countryCode,
+		ContinentCode: utils.CountryContinent(countryCode),
+	}
+	coords := strings.Split(location, ",")
+	if len(coords) != 2 {
+		return clientInfo, fmt.Errorf("invalid location %s, expected LAT,LON", location)
+	}
+	latitude, err1 := strconv.ParseFloat(strings.TrimSpace(coords[0]), 32)
+	longitude, err2 := strconv.ParseFloat(strings.TrimSpace(coords[1]), 32)
+	if err1 != nil || err2 != nil || latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return clientInfo, fmt.Errorf("invalid location %s, expected LAT,LON", location)
+	}
+	clientInfo.Latitude = float32(latitude)
+	clientInfo.Longitude = float32(longitude)
+	return clientInfo, nil
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package cli
+
+import (
+	"testing"
+)
+
+func TestParseLocation(t *testing.T) {
+	clientInfo, err := parseLocation("FR", "48.85, 2.35")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if clientInfo.CountryCode != "FR" || clientInfo.ContinentCode != "EU" || clientInfo.Latitude != 48.85 || clientInfo.Longitude != 2.35 {
+		t.Fatalf("Unexpected location %+v", clientInfo)
+	}
+
+	for _, location := range []string{"48.85", "48.85,2.35,1", "north,2.35", "91,0", "0,181"} {
+		if _, err := parseLocation("FR", location); err == nil {
+			t.Fatalf("Expected an error for %s", location)
+		}
+	}
+}
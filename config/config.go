@@ -6,9 +6,16 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"net"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/etix/mirrorbits/core"
 	"github.com/op/go-logging"
@@ -20,6 +27,23 @@ var (
 	TEMPLATES_PATH = ""
 )
 
+const (
+	// SymlinkFollow indexes the symlinks as regular files
+	SymlinkFollow = "follow"
+	// SymlinkAlias serves the symlinks as an alias of their target
+	SymlinkAlias = "alias"
+	// SymlinkIgnore ignores the symlinks
+	SymlinkIgnore = "ignore"
+)
+
+// LogModules are the subsystems whose logging level can be set individually
+var LogModules = []string{"main", "http", "scan", "monitor", "redis"}
+
+// SyslogFacilities are the names of the syslog facilities indexed by their code
+var SyslogFacilities = []string{"kern", "user", "mail", "daemon", "auth", "syslog",
+	"lpr", "news", "uucp", "cron", "authpriv", "ftp", "ntp", "audit", "alert", "clock",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7"}
+
 var (
 	log         = logging.MustGetLogger("main")
 	config      *Configuration
@@ -40,53 +64,205 @@ func defaultConfig() Configuration {
 		RedisAddress:           "127.0.0.1:6379",
 		RedisPassword:          "",
 		RedisDB:                0,
+		RedisTimeout:           300,
 		LogDir:                 "",
+		PidFile:                "",
 		TraceFileLocation:      "",
 		GeoipDatabasePath:      "/usr/share/GeoIP/",
 		ConcurrentSync:         5,
+		ConcurrentChecks:       10,
+		StatsQueueSize:         1000,
 		ScanInterval:           30,
+		ScanTimeout:            0,
 		CheckInterval:          1,
 		RepositoryScanInterval: 5,
+		DNSRefreshInterval:     1440,
+		RelocationThreshold:    500,
 		MaxLinkHeaders:         10,
 		FixTimezoneOffsets:     false,
+		SymlinkPolicy:          SymlinkAlias,
 		Hashes: hashing{
 			SHA1:   false,
 			SHA256: true,
 			MD5:    false,
 		},
-		DisallowRedirects:       false,
+		DisallowRedirects: false,
+		DirectoryListingTools: listingTools{
+			UserAgents: []string{"curl/", "wget/", "wget2/", "aria2/", "libfetch/", "fetch libfetch", "python-requests/", "python-urllib/", "go-http-client/", "libwww-perl/"},
+		},
 		WeightDistributionRange: 1.5,
+		CDNWeight:               25,
+		SelectionRandomness:     100,
+		SyncMarkers:             []string{".~tmp~", ".in-progress", "*.part", ".*.??????"},
 		DisableOnMissingFile:    false,
+		MinimumMirrors:          0,
+		MinimumPropagation:      0,
+		ScanQuarantineThreshold: 0,
 		RPCListenAddress:        "localhost:3390",
 		RPCPassword:             "",
+		HealthPath:              "/health",
+		ReadinessPath:           "/ready",
+		Logging: runtimeLogging{
+			Output:    "stderr",
+			Format:    "text",
+			Level:     "info",
+			Downloads: "file",
+			Syslog: syslogLogging{
+				Facility: "daemon",
+				Tag:      "mirrorbits",
+			},
+		},
+		Tracing: tracing{
+			ServiceName: "mirrorbits",
+			SampleRatio: 1,
+		},
+		HTTPErrorsWindow: 24,
+		FirstByteProbe: firstByteProbe{
+			Timeout:   1000,
+			CacheTTL:  30,
+			MaxProbes: 3,
+		},
+		FileIndex: fileIndex{
+			PageSize: 10000,
+			CacheTTL: 300,
+		},
+		IntegrityCheck: integrityCheck{
+			Files: 1000,
+		},
+		CoverageReport: coverageReport{
+			Interval:    360,
+			Days:        30,
+			MinRequests: 100,
+			MaxDistance: 1000,
+		},
+		Telemetry: telemetry{
+			Interval: 24,
+		},
+		Regions: regions{
+			Countries: []string{"US", "RU", "BR", "CN"},
+		},
+		HotFiles: hotFiles{
+			MinRequests: 100,
+			Interval:    60,
+		},
 	}
 }
 
 // Configuration contains all the option available in the yaml file
 type Configuration struct {
-	Repository              string     `yaml:"Repository"`
-	Templates               string     `yaml:"Templates"`
-	LocalJSPath             string     `yaml:"LocalJSPath"`
-	OutputMode              string     `yaml:"OutputMode"`
-	ListenAddress           string     `yaml:"ListenAddress"`
-	Gzip                    bool       `yaml:"Gzip"`
-	RedisAddress            string     `yaml:"RedisAddress"`
-	RedisPassword           string     `yaml:"RedisPassword"`
-	RedisDB                 int        `yaml:"RedisDB"`
-	LogDir                  string     `yaml:"LogDir"`
-	TraceFileLocation       string     `yaml:"TraceFileLocation"`
-	GeoipDatabasePath       string     `yaml:"GeoipDatabasePath"`
-	ConcurrentSync          int        `yaml:"ConcurrentSync"`
-	ScanInterval            int        `yaml:"ScanInterval"`
-	CheckInterval           int        `yaml:"CheckInterval"`
-	RepositoryScanInterval  int        `yaml:"RepositoryScanInterval"`
-	MaxLinkHeaders          int        `yaml:"MaxLinkHeaders"`
-	FixTimezoneOffsets      bool       `yaml:"FixTimezoneOffsets"`
-	Hashes                  hashing    `yaml:"Hashes"`
-	DisallowRedirects       bool       `yaml:"DisallowRedirects"`
-	WeightDistributionRange float32    `yaml:"WeightDistributionRange"`
-	DisableOnMissingFile    bool       `yaml:"DisableOnMissingFile"`
-	Fallbacks               []fallback `yaml:"Fallbacks"`
+	Repository              string       `yaml:"Repository"`
+	Templates               string       `yaml:"Templates"`
+	LocalJSPath             string       `yaml:"LocalJSPath"`
+	OutputMode              string       `yaml:"OutputMode"`
+	ListenAddress           string       `yaml:"ListenAddress"`
+	Gzip                    bool         `yaml:"Gzip"`
+	RedisAddress            string       `yaml:"RedisAddress"`
+	RedisPassword           string       `yaml:"RedisPassword"`
+	RedisDB                 int          `yaml:"RedisDB"`
+	RedisTimeout            int          `yaml:"RedisTimeout"`
+	LogDir                  string       `yaml:"LogDir"`
+	PidFile                 string       `yaml:"PidFile"`
+	TraceFileLocation       string       `yaml:"TraceFileLocation"`
+	GeoipDatabasePath       string       `yaml:"GeoipDatabasePath"`
+	ConcurrentSync          int          `yaml:"ConcurrentSync"`
+	ConcurrentChecks        int          `yaml:"ConcurrentChecks"`
+	StatsQueueSize          int          `yaml:"StatsQueueSize"`
+	ScanInterval            int          `yaml:"ScanInterval"`
+	ScanTimeout             int          `yaml:"ScanTimeout"`
+	CheckInterval           int          `yaml:"CheckInterval"`
+	RepositoryScanInterval  int          `yaml:"RepositoryScanInterval"`
+	DNSRefreshInterval      int          `yaml:"DNSRefreshInterval"`
+	RelocationThreshold     int          `yaml:"RelocationThreshold"`
+	MaxLinkHeaders          int          `yaml:"MaxLinkHeaders"`
+	MaxCandidateMirrors     int          `yaml:"MaxCandidateMirrors"`
+	FixTimezoneOffsets      bool         `yaml:"FixTimezoneOffsets"`
+	ScanQuarantineThreshold int          `yaml:"ScanQuarantineThreshold"`
+	SymlinkPolicy           string       `yaml:"SymlinkPolicy"`
+	SyncMarkers             []string     `yaml:"SyncMarkers"`
+	OutboundProxy           string       `yaml:"OutboundProxy"`
+	Hashes                  hashing      `yaml:"Hashes"`
+	DisallowRedirects       bool         `yaml:"DisallowRedirects"`
+	WeightDistributionRange float32      `yaml:"WeightDistributionRange"`
+	CDNWeight               int          `yaml:"CDNWeight"`
+	MetroAreaRadius         int          `yaml:"MetroAreaRadius"`
+	DisableOnMissingFile    bool         `yaml:"DisableOnMissingFile"`
+	DirectoryListing        bool         `yaml:"DirectoryListing"`
+	DirectoryListingTools   listingTools `yaml:"DirectoryListingTools"`
+	TolerantLookups         bool         `yaml:"TolerantLookups"`
+	MirrorRegistration      bool         `yaml:"MirrorRegistration"`
+	MinimumMirrors          int          `yaml:"MinimumMirrors"`
+	MinimumPropagation      int          `yaml:"MinimumPropagation"`
+	Fallbacks               []fallback   `yaml:"Fallbacks"`
+
+	RenameRedirects []renameRedirect `yaml:"RenameRedirects"`
+	Tombstones      []tombstone      `yaml:"Tombstones"`
+	PathAliases     []pathAlias      `yaml:"PathAliases"`
+	Embargoes       []embargo        `yaml:"Embargoes"`
+	GeoRestrictions []geoRestriction `yaml:"GeoRestrictions"`
+	SignedURLs      []signedURL      `yaml:"SignedURLs"`
+	ResponseHeaders []responseHeader `yaml:"ResponseHeaders"`
+
+	MimeTypes          map[string]string    `yaml:"MimeTypes"`
+	ContentDisposition []contentDisposition `yaml:"ContentDisposition"`
+	ChecksumFiles      []string             `yaml:"ChecksumFiles"`
+	GPGSigning         gpgSigning           `yaml:"GPGSigning"`
+
+	RobotsTxt       string           `yaml:"RobotsTxt"`
+	CrawlerPolicies []crawlerPolicy  `yaml:"CrawlerPolicies"`
+	RefererPolicies []refererPolicy  `yaml:"RefererPolicies"`
+	UserAgentRoutes []userAgentRoute `yaml:"UserAgentRoutes"`
+
+	Logging runtimeLogging `yaml:"Logging"`
+
+	HealthPath    string `yaml:"HealthPath"`
+	ReadinessPath string `yaml:"ReadinessPath"`
+
+	DebugListenAddress string `yaml:"DebugListenAddress"`
+	DebugPassword      string `yaml:"DebugPassword"`
+
+	RequestTraceRetention int           `yaml:"RequestTraceRetention"`
+	Tracing               tracing       `yaml:"Tracing"`
+	ClientPrivacy         clientPrivacy `yaml:"ClientPrivacy"`
+
+	SelectionSeed       int64 `yaml:"SelectionSeed"`
+	SelectionRandomness int   `yaml:"SelectionRandomness"`
+	SelectionTopK       int   `yaml:"SelectionTopK"`
+
+	MaxDistance map[string]int `yaml:"MaxDistance"`
+
+	SelectionHooks []string `yaml:"SelectionHooks"`
+
+	Channels map[string][]string `yaml:"Channels"`
+
+	FirstByteProbe firstByteProbe `yaml:"FirstByteProbe"`
+
+	HTTPErrorsWindow int    `yaml:"HTTPErrorsWindow"`
+	ErrorReportPath  string `yaml:"ErrorReportPath"`
+
+	MovedMirrorUpdateThreshold int `yaml:"MovedMirrorUpdateThreshold"`
+
+	FileIndex fileIndex `yaml:"FileIndex"`
+
+	SyncManifest syncManifest `yaml:"SyncManifest"`
+
+	ObjectStorage objectStorage `yaml:"ObjectStorage"`
+
+	IntegrityCheck integrityCheck `yaml:"IntegrityCheck"`
+
+	CoverageReport coverageReport `yaml:"CoverageReport"`
+
+	Telemetry telemetry `yaml:"Telemetry"`
+
+	Mirmon mirmon `yaml:"Mirmon"`
+
+	Regions regions `yaml:"Regions"`
+
+	HotFiles hotFiles `yaml:"HotFiles"`
+
+	LandingPageLanguages []string `yaml:"LandingPageLanguages"`
+
+	ContactVerification contactVerification `yaml:"ContactVerification"`
+	PushMirroring       pushMirroring       `yaml:"PushMirroring"`
 
 	RedisSentinelMasterName string      `yaml:"RedisSentinelMasterName"`
 	RedisSentinels          []sentinels `yaml:"RedisSentinels"`
@@ -101,6 +277,211 @@ type fallback struct {
 	ContinentCode string `yaml:"ContinentCode"`
 }
 
+type listingTools struct {
+	Format     string   `yaml:"Format"`
+	UserAgents []string `yaml:"UserAgents"`
+}
+
+type renameRedirect struct {
+	Prefix      string `yaml:"Prefix"`
+	GracePeriod int    `yaml:"GracePeriod"`
+}
+
+type tombstone struct {
+	Prefix  string `yaml:"Prefix"`
+	Period  int    `yaml:"Period"`
+	Message string `yaml:"Message"`
+}
+
+type pathAlias struct {
+	From     string `yaml:"From"`
+	To       string `yaml:"To"`
+	Regexp   bool   `yaml:"Regexp"`
+	Redirect bool   `yaml:"Redirect"`
+
+	re *regexp.Regexp
+}
+
+type geoRestriction struct {
+	Prefix    string   `yaml:"Prefix"`
+	Countries []string `yaml:"Countries"`
+	ASNums    []uint   `yaml:"ASNums"`
+	Status    int      `yaml:"Status"`
+}
+
+type embargo struct {
+	Prefix string `yaml:"Prefix"`
+	Until  string `yaml:"Until"`
+	Status int    `yaml:"Status"`
+
+	until time.Time
+}
+
+type signedURL struct {
+	Prefix string `yaml:"Prefix"`
+	Secret string `yaml:"Secret"`
+}
+
+type responseHeader struct {
+	Prefix  string            `yaml:"Prefix"`
+	Headers map[string]string `yaml:"Headers"`
+}
+
+type contentDisposition struct {
+	Pattern     string `yaml:"Pattern"`
+	Disposition string `yaml:"Disposition"`
+}
+
+type crawlerPolicy struct {
+	UserAgent string `yaml:"UserAgent"`
+	Block     bool   `yaml:"Block"`
+	RateLimit int    `yaml:"RateLimit"`
+	NoStats   bool   `yaml:"NoStats"`
+}
+
+type refererPolicy struct {
+	Prefix         string   `yaml:"Prefix"`
+	Allow          []string `yaml:"Allow"`
+	BlockNoReferer bool     `yaml:"BlockNoReferer"`
+	Action         string   `yaml:"Action"`
+	RedirectURL    string   `yaml:"RedirectURL"`
+}
+
+type userAgentRoute struct {
+	Prefix     string `yaml:"Prefix"`
+	UserAgent  string `yaml:"UserAgent"`
+	OutputMode string `yaml:"OutputMode"`
+}
+
+type gpgSigning struct {
+	Key     string `yaml:"Key"`
+	Homedir string `yaml:"Homedir"`
+	Binary  string `yaml:"Binary"`
+}
+
+type contactVerification struct {
+	SMTPServer   string `yaml:"SMTPServer"`
+	SMTPUsername string `yaml:"SMTPUsername"`
+	SMTPPassword string `yaml:"SMTPPassword"`
+	From         string `yaml:"From"`
+	URL          string `yaml:"URL"`
+	Validity     int    `yaml:"Validity"`
+}
+
+type pushMirroring struct {
+	ScanDelay int        `yaml:"ScanDelay"`
+	Hooks     []pushHook `yaml:"Hooks"`
+}
+
+type pushHook struct {
+	Mirror  string `yaml:"Mirror"`
+	URL     string `yaml:"URL"`
+	SSH     string `yaml:"SSH"`
+	SSHKey  string `yaml:"SSHKey"`
+	Command string `yaml:"Command"`
+}
+
+type runtimeLogging struct {
+	Output    string            `yaml:"Output"`
+	File      string            `yaml:"File"`
+	Format    string            `yaml:"Format"`
+	Level     string            `yaml:"Level"`
+	Levels    map[string]string `yaml:"Levels"`
+	Downloads string            `yaml:"Downloads"`
+	Syslog    syslogLogging     `yaml:"Syslog"`
+}
+
+type syslogLogging struct {
+	Address  string `yaml:"Address"`
+	Facility string `yaml:"Facility"`
+	Tag      string `yaml:"Tag"`
+}
+
+type tracing struct {
+	Endpoint    string            `yaml:"Endpoint"`
+	ServiceName string            `yaml:"ServiceName"`
+	SampleRatio float64           `yaml:"SampleRatio"`
+	Headers     map[string]string `yaml:"Headers"`
+}
+
+type clientPrivacy struct {
+	IPv4Prefix   int `yaml:"IPv4Prefix"`
+	IPv6Prefix   int `yaml:"IPv6Prefix"`
+	HashRotation int `yaml:"HashRotation"`
+}
+
+type firstByteProbe struct {
+	Prefixes  []string `yaml:"Prefixes"`
+	Timeout   int      `yaml:"Timeout"`
+	CacheTTL  int      `yaml:"CacheTTL"`
+	MaxProbes int      `yaml:"MaxProbes"`
+}
+
+type fileIndex struct {
+	Sitemap  bool   `yaml:"Sitemap"`
+	Path     string `yaml:"Path"`
+	PageSize int    `yaml:"PageSize"`
+	CacheTTL int    `yaml:"CacheTTL"`
+}
+
+type syncManifest struct {
+	Path        string `yaml:"Path"`
+	ScanMirrors bool   `yaml:"ScanMirrors"`
+}
+
+type objectStorage struct {
+	Endpoint  string `yaml:"Endpoint"`
+	Region    string `yaml:"Region"`
+	Bucket    string `yaml:"Bucket"`
+	Prefix    string `yaml:"Prefix"`
+	AccessKey string `yaml:"AccessKey"`
+	SecretKey string `yaml:"SecretKey"`
+	PathStyle bool   `yaml:"PathStyle"`
+}
+
+// Enabled returns true if the repository is stored in a bucket instead of
+// the local filesystem
+func (o objectStorage) Enabled() bool {
+	return o.Bucket != ""
+}
+
+type integrityCheck struct {
+	Interval int `yaml:"Interval"`
+	Files    int `yaml:"Files"`
+}
+
+type telemetry struct {
+	Endpoint string `yaml:"Endpoint"`
+	Interval int    `yaml:"Interval"`
+}
+
+type mirmon struct {
+	Interval   int    `yaml:"Interval"`
+	StateFile  string `yaml:"StateFile"`
+	ListFile   string `yaml:"ListFile"`
+	HTMLReport string `yaml:"HTMLReport"`
+}
+
+type coverageReport struct {
+	Interval    int `yaml:"Interval"`
+	Days        int `yaml:"Days"`
+	MinRequests int `yaml:"MinRequests"`
+	MaxDistance int `yaml:"MaxDistance"`
+}
+
+type regions struct {
+	Countries []string            `yaml:"Countries"`
+	Groups    map[string][]string `yaml:"Groups"`
+
+	group map[string]string
+}
+
+type hotFiles struct {
+	Count       int `yaml:"Count"`
+	MinRequests int `yaml:"MinRequests"`
+	Interval    int `yaml:"Interval"`
+}
+
 type sentinels struct {
 	Host string `yaml:"Host"`
 }
@@ -112,28 +493,49 @@ type hashing struct {
 }
 
 // LoadConfig loads the configuration file if it has not yet been loaded
-func LoadConfig() {
+func LoadConfig() error {
 	if config != nil {
-		return
+		return nil
 	}
-	err := ReloadConfig()
-	if err != nil {
-		log.Fatal(err)
+	return ReloadConfig()
+}
+
+// ConfigPaths returns the locations where the configuration file is looked
+// for when none is given with -config, by order of preference
+func ConfigPaths() []string {
+	var paths []string
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		paths = append(paths, filepath.Join(dir, "mirrorbits", "mirrorbits.conf"))
+	} else if home := os.Getenv("HOME"); home != "" {
+		paths = append(paths, filepath.Join(home, ".config", "mirrorbits", "mirrorbits.conf"))
 	}
+	return append(paths, "/etc/mirrorbits.conf")
+}
+
+// FindConfigFile returns the path of the configuration file given with
+// -config or the first one found in the standard locations
+func FindConfigFile() string {
+	if core.ConfigFile != "" {
+		return core.ConfigFile
+	}
+	for _, p := range ConfigPaths() {
+		if fileExists(p) {
+			return p
+		}
+	}
+	return ""
 }
 
 // ReloadConfig reloads the configuration file and update it globally
 func ReloadConfig() error {
+	core.ConfigFile = FindConfigFile()
 	if core.ConfigFile == "" {
-		if fileExists("/etc/mirrorbits.conf") {
-			core.ConfigFile = "/etc/mirrorbits.conf"
-		}
+		return fmt.Errorf("configuration could not be found in %s, use -config <path>", strings.Join(ConfigPaths(), ", "))
 	}
 
 	content, err := ioutil.ReadFile(core.ConfigFile)
 	if err != nil {
-		fmt.Println("Configuration could not be found.\n\tUse -config <path>")
-		os.Exit(1)
+		return fmt.Errorf("configuration could not be read, use -config <path>: %s", err)
 	}
 
 	if os.Getenv("DEBUG") != "" {
@@ -152,19 +554,438 @@ func ReloadConfig() error {
 	if c.WeightDistributionRange <= 0 {
 		return fmt.Errorf("WeightDistributionRange must be > 0")
 	}
-	if !isInSlice(c.OutputMode, []string{"auto", "json", "redirect"}) {
-		return fmt.Errorf("Config: outputMode can only be set to 'auto', 'json' or 'redirect'")
+	if c.CDNWeight < 0 || c.CDNWeight > 99 {
+		return fmt.Errorf("CDNWeight must be between 0 and 99")
 	}
-	if c.Repository == "" {
+	if c.MetroAreaRadius < 0 {
+		return fmt.Errorf("MetroAreaRadius must be >= 0")
+	}
+	maxDistance := make(map[string]int, len(c.MaxDistance))
+	for continent, km := range c.MaxDistance {
+		continent = strings.ToUpper(continent)
+		if continent != "*" && !isInSlice(continent, []string{"AF", "AN", "AS", "EU", "NA", "OC", "SA"}) {
+			return fmt.Errorf("MaxDistance: invalid continent code %s", continent)
+		}
+		if km < 0 {
+			return fmt.Errorf("MaxDistance: the distance of %s must be >= 0", continent)
+		}
+		maxDistance[continent] = km
+	}
+	c.MaxDistance = maxDistance
+	for _, marker := range c.SyncMarkers {
+		if _, err := path.Match(marker, ""); err != nil || strings.Contains(marker, "/") {
+			return fmt.Errorf("SyncMarkers: invalid pattern %s", marker)
+		}
+	}
+	if _, err := ParseProxy(c.OutboundProxy); err != nil {
+		return fmt.Errorf("OutboundProxy: %s", err)
+	}
+	if c.SelectionRandomness < 0 || c.SelectionRandomness > 100 {
+		return fmt.Errorf("SelectionRandomness must be between 0 and 100")
+	}
+	if c.SelectionTopK < 0 {
+		return fmt.Errorf("SelectionTopK must be >= 0")
+	}
+	for name, prefixes := range c.Channels {
+		if name == "" || name != strings.ToLower(name) || strings.ContainsAny(name, " ,") {
+			return fmt.Errorf("Channels: invalid channel name '%s' (lower case without space or comma)", name)
+		}
+		for _, p := range prefixes {
+			if !strings.HasPrefix(p, "/") {
+				return fmt.Errorf("Channels: the path %s of the channel %s must start with /", p, name)
+			}
+		}
+	}
+	if !isInSlice(c.OutputMode, []string{"auto", "json", "redirect", "landing"}) {
+		return fmt.Errorf("Config: outputMode can only be set to 'auto', 'json', 'redirect' or 'landing'")
+	}
+	if !isInSlice(c.SymlinkPolicy, []string{SymlinkFollow, SymlinkAlias, SymlinkIgnore}) {
+		return fmt.Errorf("Config: SymlinkPolicy can only be set to 'follow', 'alias' or 'ignore'")
+	}
+	if c.Repository == "" && !c.ObjectStorage.Enabled() {
 		return fmt.Errorf("Path to local repository not configured (see mirrorbits.conf)")
 	}
-	c.Repository, err = filepath.Abs(c.Repository)
-	if err != nil {
-		return fmt.Errorf("Invalid local repository path: %s", err)
+	if c.Repository != "" {
+		c.Repository, err = filepath.Abs(c.Repository)
+		if err != nil {
+			return fmt.Errorf("Invalid local repository path: %s", err)
+		}
+	}
+	if c.ObjectStorage.Enabled() {
+		if c.ObjectStorage.Region == "" {
+			c.ObjectStorage.Region = "us-east-1"
+		}
+		if c.ObjectStorage.Endpoint == "" {
+			c.ObjectStorage.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", c.ObjectStorage.Region)
+		}
+		if u, err := url.Parse(c.ObjectStorage.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("ObjectStorage: invalid endpoint %s", c.ObjectStorage.Endpoint)
+		}
+		if (c.ObjectStorage.AccessKey == "") != (c.ObjectStorage.SecretKey == "") {
+			return fmt.Errorf("ObjectStorage: both AccessKey and SecretKey are required")
+		}
+		c.ObjectStorage.Prefix = strings.TrimPrefix(c.ObjectStorage.Prefix, "/")
+		if c.ObjectStorage.Prefix != "" && !strings.HasSuffix(c.ObjectStorage.Prefix, "/") {
+			c.ObjectStorage.Prefix += "/"
+		}
 	}
 	if c.RepositoryScanInterval < 0 {
 		c.RepositoryScanInterval = 0
 	}
+	if c.DNSRefreshInterval < 0 {
+		c.DNSRefreshInterval = 0
+	}
+	if c.RelocationThreshold < 0 {
+		return fmt.Errorf("RelocationThreshold must be >= 0")
+	}
+	for _, r := range c.RenameRedirects {
+		if !strings.HasPrefix(r.Prefix, "/") {
+			return fmt.Errorf("RenameRedirects: prefix %s must start with a /", r.Prefix)
+		}
+		if r.GracePeriod < 0 {
+			return fmt.Errorf("RenameRedirects: grace period of %s must be >= 0", r.Prefix)
+		}
+	}
+	for _, t := range c.Tombstones {
+		if !strings.HasPrefix(t.Prefix, "/") {
+			return fmt.Errorf("Tombstones: prefix %s must start with a /", t.Prefix)
+		}
+		if t.Period < 0 {
+			return fmt.Errorf("Tombstones: period of %s must be >= 0", t.Prefix)
+		}
+	}
+	for i := range c.PathAliases {
+		if err := c.PathAliases[i].compile(); err != nil {
+			return fmt.Errorf("PathAliases: %s", err)
+		}
+	}
+	if c.MinimumMirrors < 0 {
+		c.MinimumMirrors = 0
+	}
+	if c.MinimumPropagation < 0 || c.MinimumPropagation > 100 {
+		return fmt.Errorf("MinimumPropagation must be a percentage between 0 and 100")
+	}
+	for i := range c.Embargoes {
+		e := &c.Embargoes[i]
+		if !strings.HasPrefix(e.Prefix, "/") {
+			return fmt.Errorf("Embargoes: prefix %s must start with a /", e.Prefix)
+		}
+		e.until, err = time.Parse(time.RFC3339, e.Until)
+		if err != nil {
+			return fmt.Errorf("Embargoes: invalid date for %s: %s", e.Prefix, err)
+		}
+		if e.Status == 0 {
+			e.Status = 404
+		} else if e.Status != 403 && e.Status != 404 {
+			return fmt.Errorf("Embargoes: status of %s can only be 403 or 404", e.Prefix)
+		}
+	}
+	for i := range c.GeoRestrictions {
+		g := &c.GeoRestrictions[i]
+		if !strings.HasPrefix(g.Prefix, "/") {
+			return fmt.Errorf("GeoRestrictions: prefix %s must start with a /", g.Prefix)
+		}
+		if len(g.Countries) == 0 && len(g.ASNums) == 0 {
+			return fmt.Errorf("GeoRestrictions: no country nor AS number given for %s", g.Prefix)
+		}
+		for j, code := range g.Countries {
+			if len(code) != 2 {
+				return fmt.Errorf("GeoRestrictions: invalid country code %s for %s", code, g.Prefix)
+			}
+			g.Countries[j] = strings.ToUpper(code)
+		}
+		if g.Status == 0 {
+			g.Status = 451
+		} else if g.Status != 403 && g.Status != 451 {
+			return fmt.Errorf("GeoRestrictions: status of %s can only be 403 or 451", g.Prefix)
+		}
+	}
+	for _, u := range c.SignedURLs {
+		if !strings.HasPrefix(u.Prefix, "/") {
+			return fmt.Errorf("SignedURLs: prefix %s must start with a /", u.Prefix)
+		}
+		if len(u.Secret) < 16 {
+			return fmt.Errorf("SignedURLs: secret of %s must be at least 16 characters long", u.Prefix)
+		}
+	}
+	for _, h := range c.ResponseHeaders {
+		if !strings.HasPrefix(h.Prefix, "/") {
+			return fmt.Errorf("ResponseHeaders: prefix %s must start with a /", h.Prefix)
+		}
+		for name := range h.Headers {
+			if name == "" || strings.ContainsAny(name, " :\t\r\n") {
+				return fmt.Errorf("ResponseHeaders: invalid header name %q for %s", name, h.Prefix)
+			}
+		}
+	}
+	mimeTypes := make(map[string]string, len(c.MimeTypes))
+	for ext, typ := range c.MimeTypes {
+		if !strings.HasPrefix(ext, ".") {
+			return fmt.Errorf("MimeTypes: extension %s must start with a dot", ext)
+		}
+		mimeTypes[strings.ToLower(ext)] = typ
+	}
+	c.MimeTypes = mimeTypes
+	for i := range c.ContentDisposition {
+		d := &c.ContentDisposition[i]
+		if _, err := path.Match(d.Pattern, ""); err != nil {
+			return fmt.Errorf("ContentDisposition: invalid pattern %s: %s", d.Pattern, err)
+		}
+		d.Disposition = strings.ToLower(d.Disposition)
+		if d.Disposition != "attachment" && d.Disposition != "inline" {
+			return fmt.Errorf("ContentDisposition: disposition of %s must be attachment or inline", d.Pattern)
+		}
+	}
+	for i := range c.CrawlerPolicies {
+		p := &c.CrawlerPolicies[i]
+		if p.UserAgent == "" {
+			return fmt.Errorf("CrawlerPolicies: the user agent can't be empty")
+		}
+		p.UserAgent = strings.ToLower(p.UserAgent)
+		if p.RateLimit < 0 {
+			return fmt.Errorf("CrawlerPolicies: rate limit of %s must be >= 0", p.UserAgent)
+		}
+	}
+	if !isInSlice(c.DirectoryListingTools.Format, []string{"", "text", "json"}) {
+		return fmt.Errorf("DirectoryListingTools: invalid format %s", c.DirectoryListingTools.Format)
+	}
+	for i := range c.DirectoryListingTools.UserAgents {
+		c.DirectoryListingTools.UserAgents[i] = strings.ToLower(c.DirectoryListingTools.UserAgents[i])
+	}
+	for i := range c.RefererPolicies {
+		p := &c.RefererPolicies[i]
+		if !strings.HasPrefix(p.Prefix, "/") {
+			return fmt.Errorf("RefererPolicies: prefix %s must start with a /", p.Prefix)
+		}
+		for j := range p.Allow {
+			p.Allow[j] = strings.ToLower(p.Allow[j])
+		}
+		if !isInSlice(p.Action, []string{"block", "landing", "redirect"}) {
+			return fmt.Errorf("RefererPolicies: invalid action %s for %s", p.Action, p.Prefix)
+		}
+		if p.Action == "redirect" && p.RedirectURL == "" {
+			return fmt.Errorf("RefererPolicies: the redirect action of %s requires a RedirectURL", p.Prefix)
+		}
+	}
+	for i := range c.UserAgentRoutes {
+		u := &c.UserAgentRoutes[i]
+		if u.Prefix == "" {
+			u.Prefix = "/"
+		}
+		if !strings.HasPrefix(u.Prefix, "/") {
+			return fmt.Errorf("UserAgentRoutes: prefix %s must start with a /", u.Prefix)
+		}
+		u.UserAgent = strings.ToLower(u.UserAgent)
+		if !isInSlice(u.OutputMode, []string{"auto", "json", "redirect", "landing", "mirrorlist"}) {
+			return fmt.Errorf("UserAgentRoutes: invalid output mode %s for %s", u.OutputMode, u.UserAgent)
+		}
+	}
+	if !isInSlice(c.Logging.Output, []string{"stderr", "file", "syslog", "journald"}) {
+		return fmt.Errorf("Logging: output must be stderr, file, syslog or journald")
+	}
+	if c.Logging.Output == "file" && c.Logging.File == "" {
+		return fmt.Errorf("Logging: a file is required with the file output")
+	}
+	if !isInSlice(c.Logging.Format, []string{"text", "json"}) {
+		return fmt.Errorf("Logging: format must be text or json")
+	}
+	if _, err := logging.LogLevel(c.Logging.Level); err != nil {
+		return fmt.Errorf("Logging: invalid level %s", c.Logging.Level)
+	}
+	if !isInSlice(c.Logging.Downloads, []string{"file", "syslog", "journald"}) {
+		return fmt.Errorf("Logging: downloads must be file, syslog or journald")
+	}
+	if a := c.Logging.Syslog.Address; a != "" {
+		u, err := url.Parse(a)
+		if err != nil || !isInSlice(u.Scheme, []string{"udp", "tcp", "unix"}) {
+			return fmt.Errorf("Logging: syslog address must be udp://host:port, tcp://host:port or unix:///path")
+		}
+	}
+	if !isInSlice(c.Logging.Syslog.Facility, SyslogFacilities) {
+		return fmt.Errorf("Logging: unknown syslog facility %s", c.Logging.Syslog.Facility)
+	}
+	if c.Logging.Syslog.Tag == "" {
+		c.Logging.Syslog.Tag = "mirrorbits"
+	}
+	for module, level := range c.Logging.Levels {
+		if !isInSlice(module, LogModules) {
+			return fmt.Errorf("Logging: unknown subsystem %s, must be one of %s", module, strings.Join(LogModules, ", "))
+		}
+		if _, err := logging.LogLevel(level); err != nil {
+			return fmt.Errorf("Logging: invalid level %s for %s", level, module)
+		}
+	}
+	if c.RequestTraceRetention < 0 {
+		return fmt.Errorf("RequestTraceRetention must be >= 0")
+	}
+	if c.ClientPrivacy.IPv4Prefix < 0 || c.ClientPrivacy.IPv4Prefix > 32 {
+		return fmt.Errorf("ClientPrivacy: IPv4Prefix must be between 0 and 32")
+	}
+	if c.ClientPrivacy.IPv6Prefix < 0 || c.ClientPrivacy.IPv6Prefix > 128 {
+		return fmt.Errorf("ClientPrivacy: IPv6Prefix must be between 0 and 128")
+	}
+	if c.ClientPrivacy.HashRotation < 0 {
+		return fmt.Errorf("ClientPrivacy: HashRotation must be >= 0")
+	}
+	if e := c.Tracing.Endpoint; e != "" && !strings.HasPrefix(e, "http://") && !strings.HasPrefix(e, "https://") {
+		return fmt.Errorf("Tracing: endpoint must be an http or https URL")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("Tracing: sample ratio must be between 0 and 1")
+	}
+	if c.HealthPath != "" && !strings.HasPrefix(c.HealthPath, "/") {
+		return fmt.Errorf("HealthPath: %s must start with a /", c.HealthPath)
+	}
+	if c.ReadinessPath != "" && !strings.HasPrefix(c.ReadinessPath, "/") {
+		return fmt.Errorf("ReadinessPath: %s must start with a /", c.ReadinessPath)
+	}
+	for _, p := range c.FirstByteProbe.Prefixes {
+		if !strings.HasPrefix(p, "/") {
+			return fmt.Errorf("FirstByteProbe: prefix %s must start with a /", p)
+		}
+	}
+	if c.FirstByteProbe.Timeout <= 0 {
+		return fmt.Errorf("FirstByteProbe: Timeout must be > 0")
+	}
+	if c.FirstByteProbe.CacheTTL < 0 {
+		return fmt.Errorf("FirstByteProbe: CacheTTL must be >= 0")
+	}
+	if c.FirstByteProbe.MaxProbes <= 0 {
+		return fmt.Errorf("FirstByteProbe: MaxProbes must be > 0")
+	}
+	if c.ConcurrentChecks <= 0 {
+		return fmt.Errorf("ConcurrentChecks must be > 0")
+	}
+	if c.StatsQueueSize <= 0 {
+		return fmt.Errorf("StatsQueueSize must be > 0")
+	}
+	if c.ScanTimeout < 0 {
+		return fmt.Errorf("ScanTimeout must be >= 0")
+	}
+	if c.RedisTimeout <= 10 {
+		// The pubsub connection is checked every 10 seconds
+		return fmt.Errorf("RedisTimeout must be > 10")
+	}
+	if c.MovedMirrorUpdateThreshold < 0 {
+		return fmt.Errorf("MovedMirrorUpdateThreshold must be >= 0")
+	}
+	if c.HTTPErrorsWindow <= 0 {
+		return fmt.Errorf("HTTPErrorsWindow must be > 0")
+	}
+	if c.ErrorReportPath != "" && !strings.HasPrefix(c.ErrorReportPath, "/") {
+		return fmt.Errorf("ErrorReportPath: %s must start with a /", c.ErrorReportPath)
+	}
+	if c.FileIndex.Path != "" && !strings.HasPrefix(c.FileIndex.Path, "/") {
+		return fmt.Errorf("FileIndex: Path %s must start with a /", c.FileIndex.Path)
+	}
+	if c.FileIndex.PageSize <= 0 || c.FileIndex.PageSize > 50000 {
+		return fmt.Errorf("FileIndex: PageSize must be between 1 and 50000")
+	}
+	if c.FileIndex.CacheTTL < 0 {
+		return fmt.Errorf("FileIndex: CacheTTL must be >= 0")
+	}
+	if c.SyncManifest.Path != "" && !strings.HasPrefix(c.SyncManifest.Path, "/") {
+		return fmt.Errorf("SyncManifest: Path %s must start with a /", c.SyncManifest.Path)
+	}
+	if c.SyncManifest.ScanMirrors && c.SyncManifest.Path == "" {
+		return fmt.Errorf("SyncManifest: ScanMirrors requires a Path")
+	}
+	if c.IntegrityCheck.Interval < 0 {
+		c.IntegrityCheck.Interval = 0
+	}
+	if c.IntegrityCheck.Files <= 0 {
+		return fmt.Errorf("IntegrityCheck: Files must be > 0")
+	}
+	if c.Telemetry.Endpoint != "" {
+		if u, err := url.Parse(c.Telemetry.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("Telemetry: invalid endpoint %s", c.Telemetry.Endpoint)
+		}
+	}
+	if c.Telemetry.Interval <= 0 {
+		return fmt.Errorf("Telemetry: Interval must be > 0")
+	}
+	if c.Mirmon.Interval < 0 {
+		c.Mirmon.Interval = 0
+	}
+	if c.Mirmon.Interval > 0 && c.Mirmon.StateFile == "" {
+		return fmt.Errorf("Mirmon: StateFile is required")
+	}
+	if c.CoverageReport.Interval < 0 {
+		c.CoverageReport.Interval = 0
+	}
+	if c.CoverageReport.Days <= 0 {
+		return fmt.Errorf("CoverageReport: Days must be > 0")
+	}
+	if c.CoverageReport.MinRequests < 0 || c.CoverageReport.MaxDistance < 0 {
+		return fmt.Errorf("CoverageReport: MinRequests and MaxDistance must be >= 0")
+	}
+	if c.HotFiles.Count < 0 || c.HotFiles.MinRequests < 0 {
+		return fmt.Errorf("HotFiles: Count and MinRequests must be >= 0")
+	}
+	if c.HotFiles.Interval <= 0 {
+		return fmt.Errorf("HotFiles: Interval must be > 0")
+	}
+	for i, country := range c.Regions.Countries {
+		if len(country) != 2 {
+			return fmt.Errorf("Regions: invalid country code %s", country)
+		}
+		c.Regions.Countries[i] = strings.ToUpper(country)
+	}
+	c.Regions.group = make(map[string]string)
+	for name, subdivisions := range c.Regions.Groups {
+		for _, s := range subdivisions {
+			s = strings.ToUpper(s)
+			if !strings.Contains(s, "-") {
+				return fmt.Errorf("Regions: %s of %s is not an ISO 3166-2 subdivision code", s, name)
+			}
+			if g, ok := c.Regions.group[s]; ok {
+				return fmt.Errorf("Regions: %s is part of both %s and %s", s, g, name)
+			}
+			c.Regions.group[s] = strings.ToUpper(name)
+		}
+	}
+	for i, lang := range c.LandingPageLanguages {
+		lang = strings.ToLower(lang)
+		if lang == "" || strings.ContainsAny(lang, "/\\. ") {
+			return fmt.Errorf("LandingPageLanguages: invalid language %q", lang)
+		}
+		c.LandingPageLanguages[i] = lang
+	}
+	for i, ext := range c.ChecksumFiles {
+		ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+		if !isInSlice(ext, []string{"md5", "sha1", "sha256"}) {
+			return fmt.Errorf("ChecksumFiles: unsupported hash %s (only md5, sha1 and sha256 are computed)", ext)
+		}
+		c.ChecksumFiles[i] = ext
+	}
+	if v := &c.ContactVerification; v.SMTPServer != "" {
+		if v.From == "" || v.URL == "" {
+			return fmt.Errorf("ContactVerification: From and URL are required")
+		}
+		if !strings.HasSuffix(v.URL, "/") {
+			v.URL += "/"
+		}
+		if v.Validity <= 0 {
+			v.Validity = 7
+		}
+	}
+	if c.PushMirroring.ScanDelay < 0 {
+		return fmt.Errorf("PushMirroring: ScanDelay must be >= 0")
+	}
+	for _, h := range c.PushMirroring.Hooks {
+		if h.Mirror == "" {
+			return fmt.Errorf("PushMirroring: a hook must be associated to a mirror")
+		}
+		if (h.URL == "") == (h.SSH == "") {
+			return fmt.Errorf("PushMirroring: the hook of %s requires either an URL or an SSH destination", h.Mirror)
+		}
+		if h.URL != "" && !strings.HasPrefix(h.URL, "http://") && !strings.HasPrefix(h.URL, "https://") {
+			return fmt.Errorf("PushMirroring: invalid URL for %s", h.Mirror)
+		}
+	}
+	if c.ScanQuarantineThreshold < 0 || c.ScanQuarantineThreshold > 100 {
+		return fmt.Errorf("ScanQuarantineThreshold must be a percentage between 0 and 100")
+	}
 
 	if config != nil &&
 		(c.RedisAddress != config.RedisAddress ||
@@ -185,6 +1006,340 @@ func ReloadConfig() error {
 	return nil
 }
 
+// RenameGracePeriod returns for how long a renamed file within the given path
+// should be redirected to its new location, the longest matching prefix wins
+func (c *Configuration) RenameGracePeriod(path string) time.Duration {
+	var prefix string
+	var days int
+	for _, r := range c.RenameRedirects {
+		if strings.HasPrefix(path, r.Prefix) && len(r.Prefix) > len(prefix) {
+			prefix = r.Prefix
+			days = r.GracePeriod
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Tombstone returns for how long a file removed from the given path should
+// be answered with 410 Gone and the message to show, the longest matching
+// prefix wins
+func (c *Configuration) Tombstone(path string) (time.Duration, string) {
+	var prefix, message string
+	var days int
+	for _, t := range c.Tombstones {
+		if strings.HasPrefix(path, t.Prefix) && len(t.Prefix) > len(prefix) {
+			prefix = t.Prefix
+			days = t.Period
+			message = t.Message
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour, message
+}
+
+// compile validates the rule and compiles its regular expression, which
+// must match the whole path
+func (a *pathAlias) compile() (err error) {
+	if a.Regexp {
+		a.re, err = regexp.Compile("^(?:" + a.From + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid regular expression %s: %s", a.From, err)
+		}
+	} else if !strings.HasPrefix(a.From, "/") {
+		return fmt.Errorf("path %s must start with a /", a.From)
+	}
+	if !strings.HasPrefix(a.To, "/") {
+		return fmt.Errorf("target %s of %s must start with a /", a.To, a.From)
+	}
+	return nil
+}
+
+// PathAlias returns the path mapped to the given legacy path by the first
+// matching rule of PathAliases (or an empty string) and whether the client
+// must be redirected to it. A path ending with a slash maps the whole
+// directory and a regular expression must match the whole path, so a path
+// is rewritten once at most. The mapped path is cleaned and an empty string
+// is returned if it doesn't start with a slash.
+func (c *Configuration) PathAlias(p string) (string, bool) {
+	for _, a := range c.PathAliases {
+		var target string
+		if a.re != nil {
+			m := a.re.FindStringSubmatchIndex(p)
+			if m == nil {
+				continue
+			}
+			target = string(a.re.ExpandString(nil, a.To, p, m))
+		} else if p == a.From || (strings.HasSuffix(a.From, "/") && strings.HasPrefix(p, a.From)) {
+			target = a.To + p[len(a.From):]
+		} else {
+			continue
+		}
+		dir := strings.HasSuffix(target, "/")
+		target = path.Clean(target)
+		if !strings.HasPrefix(target, "/") {
+			return "", false
+		}
+		if dir && target != "/" {
+			target += "/"
+		}
+		return target, a.Redirect
+	}
+	return "", false
+}
+
+// Region returns the region routed separately of the given ISO 3166-2
+// subdivision (e.g. US-CA), being either the group of Regions it belongs to or
+// the subdivision itself, or an empty string if the regions of its country
+// are not routed separately. A name of group is returned as is.
+func (c *Configuration) Region(code string) string {
+	code = strings.ToUpper(code)
+	if g, ok := c.Regions.group[code]; ok {
+		return g
+	}
+	for name := range c.Regions.Groups {
+		if strings.ToUpper(name) == code {
+			return code
+		}
+	}
+	i := strings.Index(code, "-")
+	if i <= 0 || !isInSlice(code[:i], c.Regions.Countries) {
+		return ""
+	}
+	return code
+}
+
+// DistanceLimit returns the distance in km beyond which the mirrors are
+// excluded for the clients of the given continent, or 0 for no limit
+func (c *Configuration) DistanceLimit(continent string) int {
+	if km, ok := c.MaxDistance[strings.ToUpper(continent)]; ok {
+		return km
+	}
+	return c.MaxDistance["*"]
+}
+
+// IsProbedPath returns true if the mirror must be probed before redirecting
+// to the given path (see FirstByteProbe)
+func (c *Configuration) IsProbedPath(path string) bool {
+	for _, p := range c.FirstByteProbe.Prefixes {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// EmbargoStatus returns the HTTP status code to use if the given path is
+// currently under embargo or 0 otherwise
+func (c *Configuration) EmbargoStatus(path string) int {
+	now := time.Now()
+	for _, e := range c.Embargoes {
+		if strings.HasPrefix(path, e.Prefix) && now.Before(e.until) {
+			return e.Status
+		}
+	}
+	return 0
+}
+
+// GeoRestrictionStatus returns the HTTP status to answer to a client of the
+// given country and AS number requesting the given path, or 0 if the client
+// is not subject to any of the GeoRestrictions
+func (c *Configuration) GeoRestrictionStatus(path, countryCode string, asnum uint) int {
+	for _, g := range c.GeoRestrictions {
+		if !strings.HasPrefix(path, g.Prefix) {
+			continue
+		}
+		if countryCode != "" && isInSlice(countryCode, g.Countries) {
+			return g.Status
+		}
+		for _, n := range g.ASNums {
+			if asnum != 0 && n == asnum {
+				return g.Status
+			}
+		}
+	}
+	return 0
+}
+
+// SigningSecret returns the secret used to sign the URLs of the given path or
+// an empty string if the path is not restricted, the longest matching prefix
+// wins
+func (c *Configuration) SigningSecret(path string) string {
+	var prefix, secret string
+	for _, u := range c.SignedURLs {
+		if strings.HasPrefix(path, u.Prefix) && len(u.Prefix) > len(prefix) {
+			prefix = u.Prefix
+			secret = u.Secret
+		}
+	}
+	return secret
+}
+
+// PathResponseHeaders returns the extra headers to send for the given path.
+// The headers of the longest matching prefixes take precedence and an empty
+// value removes the header set by a shorter prefix.
+func (c *Configuration) PathResponseHeaders(path string) map[string]string {
+	matching := make([]responseHeader, 0, len(c.ResponseHeaders))
+	for _, h := range c.ResponseHeaders {
+		if strings.HasPrefix(path, h.Prefix) {
+			matching = append(matching, h)
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		return len(matching[i].Prefix) < len(matching[j].Prefix)
+	})
+
+	headers := make(map[string]string)
+	for _, h := range matching {
+		for name, value := range h.Headers {
+			headers[name] = value
+		}
+	}
+	for name, value := range headers {
+		if value == "" {
+			delete(headers, name)
+		}
+	}
+	return headers
+}
+
+// MimeType returns the content type configured for the extension of the given
+// file or an empty string
+func (c *Configuration) MimeType(name string) string {
+	return c.MimeTypes[strings.ToLower(path.Ext(name))]
+}
+
+// ContentDispositionType returns the disposition (attachment or inline) of the
+// first pattern matching either the full path or the name of the given file
+func (c *Configuration) ContentDispositionType(filePath string) string {
+	for _, d := range c.ContentDisposition {
+		if ok, _ := path.Match(d.Pattern, filePath); ok {
+			return d.Disposition
+		}
+		if ok, _ := path.Match(d.Pattern, path.Base(filePath)); ok {
+			return d.Disposition
+		}
+	}
+	return ""
+}
+
+// CrawlerPolicy returns the first policy whose user agent is contained in the
+// given user agent or nil if none matches
+func (c *Configuration) CrawlerPolicy(userAgent string) *crawlerPolicy {
+	userAgent = strings.ToLower(userAgent)
+	for i := range c.CrawlerPolicies {
+		if strings.Contains(userAgent, c.CrawlerPolicies[i].UserAgent) {
+			return &c.CrawlerPolicies[i]
+		}
+	}
+	return nil
+}
+
+// RefererPolicy returns the policy to apply to a request of the given path
+// linked from another site (see RefererPolicies), or nil if the referer is
+// allowed. The links from the redirector itself (host) are always allowed
+// and the longest matching prefix wins.
+func (c *Configuration) RefererPolicy(path, referer, host string) *refererPolicy {
+	var policy *refererPolicy
+	for i, p := range c.RefererPolicies {
+		if strings.HasPrefix(path, p.Prefix) && (policy == nil || len(p.Prefix) > len(policy.Prefix)) {
+			policy = &c.RefererPolicies[i]
+		}
+	}
+	if policy == nil {
+		return nil
+	}
+	if referer == "" {
+		if policy.BlockNoReferer {
+			return policy
+		}
+		return nil
+	}
+	u, err := url.Parse(referer)
+	if err != nil {
+		return policy
+	}
+	refererHost := strings.ToLower(u.Hostname())
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if refererHost == strings.ToLower(host) {
+		return nil
+	}
+	for _, allowed := range policy.Allow {
+		if refererHost == allowed || (strings.HasPrefix(allowed, "*.") && strings.HasSuffix(refererHost, allowed[1:])) {
+			return nil
+		}
+	}
+	return policy
+}
+
+// ListingToolFormat returns the format of the directory listings requested
+// by the given user agent if it is a command-line tool (see
+// DirectoryListingTools), or an empty string
+func (c *Configuration) ListingToolFormat(userAgent string) string {
+	if c.DirectoryListingTools.Format == "" {
+		return ""
+	}
+	userAgent = strings.ToLower(userAgent)
+	for _, tool := range c.DirectoryListingTools.UserAgents {
+		if strings.Contains(userAgent, tool) {
+			return c.DirectoryListingTools.Format
+		}
+	}
+	return ""
+}
+
+// UserAgentOutputMode returns the output mode of the first route matching both
+// the given path and user agent or the default output mode
+func (c *Configuration) UserAgentOutputMode(path, userAgent string) string {
+	userAgent = strings.ToLower(userAgent)
+	for _, u := range c.UserAgentRoutes {
+		if strings.HasPrefix(path, u.Prefix) && strings.Contains(userAgent, u.UserAgent) {
+			return u.OutputMode
+		}
+	}
+	return c.OutputMode
+}
+
+// UsesOutputMode returns true if the given output mode is either the default
+// one or used by any of the user agent routes or of the referer policies
+func (c *Configuration) UsesOutputMode(mode string) bool {
+	if c.OutputMode == mode {
+		return true
+	}
+	for _, u := range c.UserAgentRoutes {
+		if u.OutputMode == mode {
+			return true
+		}
+	}
+	// The hotlinked files can be answered with their landing page
+	for _, p := range c.RefererPolicies {
+		if mode == "landing" && p.Action == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseProxy returns the URL of the given outbound proxy (http://,
+// https:// or socks5://), nil meaning a direct connection ("" or "direct")
+func ParseProxy(raw string) (*url.URL, error) {
+	if raw == "" || raw == "direct" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		// The error would reveal the credentials of the proxy
+		return nil, fmt.Errorf("invalid proxy URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+		return nil, fmt.Errorf("unsupported proxy scheme %s, expected http, https or socks5", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy: missing host")
+	}
+	return u, nil
+}
+
 // GetConfig returns a pointer to a configuration object
 // FIXME reading from the pointer could cause a race!
 func GetConfig() *Configuration {
@@ -13,4 +13,12 @@ const (
 	ContextMirrorID
 	// ContextMirrorName is the key for the variable: MirrorName
 	ContextMirrorName
+	// ContextNetwork is the key for the network to dial: tcp, tcp4 or tcp6
+	ContextNetwork
+	// ContextPermanentRedirect is the key for the *string receiving the
+	// target of a permanent redirect of the request
+	ContextPermanentRedirect
+	// ContextProxy is the key for the *url.URL of the outbound proxy, nil
+	// for a direct connection
+	ContextProxy
 )
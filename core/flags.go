@@ -5,13 +5,13 @@ package core
 
 import (
 	"flag"
-	"os"
 )
 
 var (
 	Daemon      bool
 	Debug       bool
 	Monitor     bool
+	Bootstrap   string
 	ConfigFile  string
 	CpuProfile  string
 	PidFile     string
@@ -20,16 +20,21 @@ var (
 	RPCHost     string
 	RPCPassword string
 	RPCAskPass  bool
+	QuietErrors bool
+	JSONErrors  bool
 	NArg        int
 )
 
 func Parseflags() {
 	flag.BoolVar(&Debug, "debug", false, "Debug mode")
 	flag.StringVar(&CpuProfile, "cpuprofile", "", "write cpu profile to file")
+	flag.StringVar(&ConfigFile, "config", "", "Path to the config file")
 	flag.UintVar(&RPCPort, "p", 3390, "Server port")
 	flag.StringVar(&RPCHost, "h", "localhost", "Server host")
 	flag.StringVar(&RPCPassword, "P", "", "Server password")
 	flag.BoolVar(&RPCAskPass, "a", false, "Ask for server password")
+	flag.BoolVar(&QuietErrors, "q", false, "Don't print the errors, only set the exit code")
+	flag.BoolVar(&JSONErrors, "json", false, "Print the errors in json")
 	flag.Parse()
 	NArg = flag.NArg()
 
@@ -38,11 +43,12 @@ func Parseflags() {
 	daemon.StringVar(&CpuProfile, "cpuprofile", "", "write cpu profile to file")
 	daemon.StringVar(&ConfigFile, "config", "", "Path to the config file")
 	daemon.BoolVar(&Monitor, "monitor", true, "Enable the background mirrors monitor")
+	daemon.StringVar(&Bootstrap, "bootstrap", "", "Seed file of the mirrors to load into an empty database")
 	daemon.StringVar(&PidFile, "p", "", "Path to pid file")
 	daemon.StringVar(&RunLog, "log", "", "File to output logs (default: stderr)")
 
-	if len(os.Args) > 1 && os.Args[1] == "daemon" {
+	if flag.Arg(0) == "daemon" {
 		Daemon = true
-		daemon.Parse(os.Args[2:])
+		daemon.Parse(flag.Args()[1:])
 	}
 }
@@ -10,6 +10,16 @@ const (
 	RSYNC ScannerType = iota
 	// FTP represents an ftp scanner
 	FTP
+	// MANIFEST represents a scanner fetching the sync manifest of the mirror
+	MANIFEST
+)
+
+// Sources of the requests of scans of the mirrors
+const (
+	ScanSourceScheduler = "scheduler"
+	ScanSourceCLI       = "cli"
+	ScanSourceAPI       = "api"
+	ScanSourcePush      = "push"
 )
 
 // Precision is used to compute the precision of the mod time (millisecond, second)
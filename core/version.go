@@ -9,15 +9,19 @@ import (
 )
 
 var (
-	VERSION = ""
-	BUILD   = ""
-	DEV     = ""
+	VERSION    = ""
+	BUILD      = ""
+	DEV        = ""
+	COMMIT     = ""
+	BUILD_DATE = ""
 )
 
 // VersionInfo is a struct containing version related informations
 type VersionInfo struct {
 	Version    string
 	Build      string
+	Commit     string
+	BuildDate  string
 	GoVersion  string
 	OS         string
 	Arch       string
@@ -29,6 +33,8 @@ func GetVersionInfo() VersionInfo {
 	return VersionInfo{
 		Version:    VERSION,
 		Build:      BUILD + DEV,
+		Commit:     COMMIT,
+		BuildDate:  BUILD_DATE,
 		GoVersion:  runtime.Version(),
 		OS:         runtime.GOOS,
 		Arch:       runtime.GOARCH,
@@ -40,6 +46,12 @@ func GetVersionInfo() VersionInfo {
 func PrintVersion(info VersionInfo) {
 	fmt.Printf(" %-17s %s\n", "Version:", info.Version)
 	fmt.Printf(" %-17s %s\n", "Build:", info.Build)
+	if info.Commit != "" {
+		fmt.Printf(" %-17s %s\n", "Commit:", info.Commit)
+	}
+	if info.BuildDate != "" {
+		fmt.Printf(" %-17s %s\n", "Build date:", info.BuildDate)
+	}
 	fmt.Printf(" %-17s %s\n", "GoVersion:", info.GoVersion)
 	fmt.Printf(" %-17s %s\n", "Operating System:", info.OS)
 	fmt.Printf(" %-17s %s\n", "Architecture:", info.Arch)
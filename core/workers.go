@@ -0,0 +1,59 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package core
+
+import (
+	"sort"
+	"sync"
+)
+
+// WorkerPool reports the activity of a pool of background workers
+type WorkerPool struct {
+	// Name under which the pool is registered
+	Name string
+	// Number of workers of the pool
+	Workers int
+	// Number of workers currently busy
+	Busy int
+	// Number of tasks waiting for a worker
+	Queued int
+	// Maximum number of tasks waiting for a worker, 0 if unbounded
+	QueueSize int
+}
+
+var workerPools = struct {
+	sync.Mutex
+	pools map[string]func() WorkerPool
+}{
+	pools: make(map[string]func() WorkerPool),
+}
+
+// RegisterWorkerPool registers the function reporting the activity of the
+// given pool, replacing the previous one registered under the same name
+func RegisterWorkerPool(name string, status func() WorkerPool) {
+	workerPools.Lock()
+	defer workerPools.Unlock()
+	workerPools.pools[name] = status
+}
+
+// WorkerPools returns the activity of the registered pools sorted by name
+func WorkerPools() []WorkerPool {
+	workerPools.Lock()
+	funcs := make(map[string]func() WorkerPool, len(workerPools.pools))
+	for name, f := range workerPools.pools {
+		funcs[name] = f
+	}
+	workerPools.Unlock()
+
+	pools := make([]WorkerPool, 0, len(funcs))
+	for name, f := range funcs {
+		pool := f()
+		pool.Name = name
+		pools = append(pools, pool)
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].Name < pools[j].Name
+	})
+	return pools
+}
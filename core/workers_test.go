@@ -0,0 +1,35 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package core
+
+import (
+	"testing"
+)
+
+func TestWorkerPools(t *testing.T) {
+	RegisterWorkerPool("scans", func() WorkerPool {
+		return WorkerPool{Workers: 5, Busy: 1}
+	})
+	RegisterWorkerPool("health checks", func() WorkerPool {
+		return WorkerPool{Workers: 10, Queued: 2, QueueSize: 50}
+	})
+	// Replaces the previous pool of the same name
+	RegisterWorkerPool("scans", func() WorkerPool {
+		return WorkerPool{Workers: 5, Busy: 2}
+	})
+
+	pools := WorkerPools()
+	if len(pools) != 2 {
+		t.Fatalf("Expected 2 pools, got %d", len(pools))
+	}
+	if pools[0].Name != "health checks" || pools[1].Name != "scans" {
+		t.Fatalf("Pools not sorted by name: %+v", pools)
+	}
+	if pools[0].Queued != 2 || pools[0].QueueSize != 50 {
+		t.Fatalf("Unexpected health check pool: %+v", pools[0])
+	}
+	if pools[1].Busy != 2 {
+		t.Fatalf("Expected the last registered scan pool, got %+v", pools[1])
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/mirrors"
+)
+
+const (
+	// Number of probes kept in the history of each mirror
+	mirmonHistory = 14
+)
+
+var mirmonReport = template.Must(template.New("mirmon").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Mirrors status</title></head>
+<body>
+<h1>Mirrors status</h1>
+<p>Generated on {{.Generated.Format "2006-01-02 15:04:05 MST"}}</p>
+<table>
+<tr><th>Country</th><th>Mirror</th><th>Age</th><th>Status</th><th>History</th></tr>
+{{range .Mirrors}}<tr><td>{{.Country}}</td><td><a href="{{.URL}}">{{.URL}}</a></td><td>{{.Age}}</td><td>{{.Status}}</td><td>{{.History}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// mirmonState is the state of a mirror, a line of the mirmon state file
+type mirmonState struct {
+	url         string
+	age         string
+	status      string
+	lastSuccess string
+	probes      string
+	states      string
+	lastProbe   string
+}
+
+func (s mirmonState) String() string {
+	return strings.Join([]string{s.url, s.age, s.status, s.lastSuccess, s.probes, s.states, s.lastProbe}, " ")
+}
+
+// readMirmonStates parses a mirmon state file
+func readMirmonStates(r io.Reader) map[string]mirmonState {
+	states := make(map[string]mirmonState)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		f := strings.Fields(scanner.Text())
+		if len(f) != 7 {
+			continue
+		}
+		states[f[0]] = mirmonState{f[0], f[1], f[2], f[3], f[4], f[5], f[6]}
+	}
+	return states
+}
+
+// updateMirmonState records the state of the mirror at the given time in
+// its previous state
+func updateMirmonState(s mirmonState, m mirrors.Mirror, now time.Time) mirmonState {
+	probe := strconv.FormatInt(now.Unix(), 10)
+
+	s.url = m.HttpURL
+	s.age = "undef"
+	if !m.LastModTime.IsZero() {
+		s.age = strconv.FormatInt(m.LastModTime.Unix(), 10)
+	}
+	if s.lastSuccess == "" {
+		s.lastSuccess = "undef"
+	}
+	state := "f"
+	if m.Up {
+		s.status = "ok"
+		s.lastSuccess = probe
+		state = "s"
+	} else {
+		reason := m.ExcludeReason
+		if reason == "" {
+			reason = "fail"
+		}
+		s.status = strings.Join(strings.Fields(reason), "_")
+	}
+
+	var probes []string
+	if s.probes != "" {
+		probes = strings.Split(s.probes, "-")
+	}
+	probes = append(probes, probe)
+	if len(probes) > mirmonHistory {
+		probes = probes[len(probes)-mirmonHistory:]
+	}
+	s.probes = strings.Join(probes, "-")
+	s.states += state
+	if len(s.states) > mirmonHistory {
+		s.states = s.states[len(s.states)-mirmonHistory:]
+	}
+	s.lastProbe = probe
+	return s
+}
+
+// writeMirmon writes the state of the enabled mirrors in the format of
+// mirmon (see Mirmon)
+func (m *monitor) writeMirmon() {
+	conf := GetConfig().Mirmon
+	now := time.Now()
+
+	m.mapLock.Lock()
+	list := make(mirrors.Mirrors, 0, len(m.mirrors))
+	for _, mir := range m.mirrors {
+		if mir.Enabled && mir.HttpURL != "" {
+			list = append(list, mir.Mirror)
+		}
+	}
+	m.mapLock.Unlock()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].HttpURL < list[j].HttpURL
+	})
+
+	// The history of the probes is carried over from the previous file
+	previous := make(map[string]mirmonState)
+	if f, err := os.Open(conf.StateFile); err == nil {
+		previous = readMirmonStates(f)
+		f.Close()
+	}
+
+	type reportLine struct {
+		Country string
+		URL     string
+		Age     string
+		Status  string
+		History string
+	}
+	var state, mirrorList bytes.Buffer
+	var lines []reportLine
+	for _, mir := range list {
+		s := updateMirmonState(previous[mir.HttpURL], mir, now)
+		fmt.Fprintln(&state, s)
+		fmt.Fprintf(&mirrorList, "%s\t%s\t%s\n", mir.CountryCodes.Primary(), mir.HttpURL, mir.AdminEmail)
+
+		age := "unknown"
+		if !mir.LastModTime.IsZero() {
+			age = now.Sub(mir.LastModTime.Time).Truncate(time.Minute).String()
+		}
+		lines = append(lines, reportLine{
+			Country: mir.CountryCodes.Primary(),
+			URL:     mir.HttpURL,
+			Age:     age,
+			Status:  s.status,
+			History: s.states,
+		})
+	}
+
+	if err := writeFileAtomic(conf.StateFile, state.Bytes()); err != nil {
+		log.Errorf("Unable to write the mirmon state file: %s", err)
+	}
+	if conf.ListFile != "" {
+		if err := writeFileAtomic(conf.ListFile, mirrorList.Bytes()); err != nil {
+			log.Errorf("Unable to write the mirmon list of mirrors: %s", err)
+		}
+	}
+	if conf.HTMLReport != "" {
+		var report bytes.Buffer
+		err := mirmonReport.Execute(&report, struct {
+			Generated time.Time
+			Mirrors   []reportLine
+		}{now, lines})
+		if err == nil {
+			err = writeFileAtomic(conf.HTMLReport, report.Bytes())
+		}
+		if err != nil {
+			log.Errorf("Unable to write the mirmon report: %s", err)
+		}
+	}
+}
+
+// writeFileAtomic replaces the given file by a new one holding data, the
+// readers never seeing a partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".new"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package daemon
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etix/mirrorbits/mirrors"
+)
+
+func TestMirmonState(t *testing.T) {
+	m := mirrors.Mirror{
+		HttpURL: "http://m1.mirror/repo/",
+		Up:      true,
+	}
+	m.LastModTime = m.LastModTime.FromTime(time.Unix(1500000000, 0))
+
+	s := updateMirmonState(mirmonState{}, m, time.Unix(1500000600, 0))
+	if s.String() != "http://m1.mirror/repo/ 1500000000 ok 1500000600 1500000600 s 1500000600" {
+		t.Fatalf("Unexpected state %q", s)
+	}
+
+	// The history is carried over
+	states := readMirmonStates(strings.NewReader(s.String() + "\ninvalid line\n"))
+	if len(states) != 1 {
+		t.Fatalf("Expected a single state, got %d", len(states))
+	}
+	m.Up = false
+	m.ExcludeReason = "Connection refused"
+	s = updateMirmonState(states[m.HttpURL], m, time.Unix(1500001200, 0))
+	if s.String() != "http://m1.mirror/repo/ 1500000000 Connection_refused 1500000600 1500000600-1500001200 sf 1500001200" {
+		t.Fatalf("Unexpected state %q", s)
+	}
+
+	for i := 0; i < 2*mirmonHistory; i++ {
+		s = updateMirmonState(s, m, time.Unix(1500001200, 0))
+	}
+	if len(s.states) != mirmonHistory || len(strings.Split(s.probes, "-")) != mirmonHistory {
+		t.Fatalf("The history must be limited to %d probes", mirmonHistory)
+	}
+}
@@ -6,12 +6,17 @@ package daemon
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"math/rand"
 	"net"
 	"net/http"
+	"net/url"
+	"path"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	. "github.com/etix/mirrorbits/config"
@@ -26,14 +31,14 @@ import (
 )
 
 var (
-	healthCheckThreads  = 10
 	userAgent           = "Mirrorbits/" + core.VERSION + " PING CHECK"
 	clientTimeout       = time.Duration(20 * time.Second)
 	clientDeadline      = time.Duration(40 * time.Second)
+	sniffLength         = int64(512)
 	errRedirect         = errors.New("Redirect not allowed")
 	errMirrorNotScanned = errors.New("Mirror has not yet been scanned")
 
-	log = logging.MustGetLogger("main")
+	log = logging.MustGetLogger("monitor")
 )
 
 type monitor struct {
@@ -44,12 +49,25 @@ type monitor struct {
 	httpClient      http.Client
 	httpTransport   http.Transport
 	healthCheckChan chan int
-	syncChan        chan int
+	scans           *scan.Queue
 	stop            chan struct{}
+	ctx             context.Context
+	cancel          context.CancelFunc
 	configNotifier  chan bool
 	wg              sync.WaitGroup
 	formatLongestID int
 
+	// Size of the pools of workers and number of workers busy, updated
+	// atomically
+	checkWorkers int32
+	busyChecks   int32
+	// Set while the integrity of the repository is being checked
+	verifying int32
+
+	// Background activities paused by the operators, only accessed by the
+	// main loop
+	paused map[string]bool
+
 	cluster *cluster
 	trace   *scan.Trace
 }
@@ -57,7 +75,6 @@ type monitor struct {
 type mirror struct {
 	mirrors.Mirror
 	checking  bool
-	scanning  bool
 	lastCheck time.Time
 }
 
@@ -69,10 +86,6 @@ func (m *mirror) NeedSync() bool {
 	return time.Since(m.LastSync.Time) > time.Duration(GetConfig().ScanInterval)*time.Minute
 }
 
-func (m *mirror) IsScanning() bool {
-	return m.scanning
-}
-
 func (m *mirror) IsChecking() bool {
 	return m.checking
 }
@@ -84,9 +97,11 @@ func NewMonitor(r *database.Redis, c *mirrors.Cache) *monitor {
 	m.cache = c
 	m.cluster = NewCluster(r)
 	m.mirrors = make(map[int]*mirror)
-	m.healthCheckChan = make(chan int, healthCheckThreads*5)
-	m.syncChan = make(chan int)
+	m.paused = make(map[string]bool)
+	m.healthCheckChan = make(chan int, GetConfig().ConcurrentChecks*5)
+	m.scans = scan.NewQueue(m.runScan)
 	m.stop = make(chan struct{})
+	m.ctx, m.cancel = context.WithCancel(context.Background())
 	m.configNotifier = make(chan bool, 1)
 	m.trace = scan.NewTraceHandler(m.redis, m.stop)
 
@@ -97,9 +112,15 @@ func NewMonitor(r *database.Redis, c *mirrors.Cache) *monitor {
 	m.httpTransport = http.Transport{
 		DisableKeepAlives:   true,
 		MaxIdleConnsPerHost: 0,
-		Dial: func(network, addr string) (net.Conn, error) {
+		Proxy:               mirrors.ProxyFromContext,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			// Restrict the connection to a single IP family if requested
+			if n, ok := ctx.Value(core.ContextNetwork).(string); ok && n != "" {
+				network = n
+			}
 			deadline := time.Now().Add(clientDeadline)
-			c, err := net.DialTimeout(network, addr, clientTimeout)
+			dialer := net.Dialer{Timeout: clientTimeout}
+			c, err := dialer.DialContext(ctx, network, addr)
 			if err != nil {
 				return nil, err
 			}
@@ -122,6 +143,8 @@ func (m *monitor) Stop() {
 	default:
 		m.cluster.Stop()
 		close(m.stop)
+		// Abort the scans in progress
+		m.cancel()
 	}
 }
 
@@ -131,6 +154,15 @@ func (m *monitor) Wait() {
 
 // Return an error if the endpoint is an unauthorized redirect
 func checkRedirect(req *http.Request, via []*http.Request) error {
+	// Remember where the mirror permanently moved to
+	if len(via) == 1 && req.Response != nil {
+		if code := req.Response.StatusCode; code == http.StatusMovedPermanently || code == http.StatusPermanentRedirect {
+			if location, ok := req.Context().Value(core.ContextPermanentRedirect).(*string); ok {
+				*location = req.URL.String()
+			}
+		}
+	}
+
 	redirects := req.Context().Value(core.ContextAllowRedirects).(mirrors.Redirects)
 
 	if redirects.Allowed() {
@@ -165,6 +197,12 @@ func (m *monitor) MonitorLoop() {
 		break
 	}
 
+	// Follow the background activities paused by the operators
+	pauseEvent := make(chan string, 10)
+	m.redis.Pubsub.SubscribeEvent(database.PAUSE_UPDATE, pauseEvent)
+	m.redis.Pubsub.SubscribeEvent(database.PUBSUB_RECONNECTED, pauseEvent)
+	m.refreshPaused()
+
 	// Scan the local repository
 	m.retry(func(i uint) error {
 		err := m.scanRepository()
@@ -204,20 +242,35 @@ func (m *monitor) MonitorLoop() {
 	m.cluster.Start()
 
 	// Start the health check routines
-	for i := 0; i < healthCheckThreads; i++ {
+	atomic.StoreInt32(&m.checkWorkers, int32(GetConfig().ConcurrentChecks))
+	for i := 0; i < GetConfig().ConcurrentChecks; i++ {
 		m.wg.Add(1)
 		go m.healthCheckLoop()
 	}
 
 	// Start the mirror sync routines
-	for i := 0; i < GetConfig().ConcurrentSync; i++ {
-		m.wg.Add(1)
-		go m.syncLoop()
-	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.scans.Run(m.ctx, GetConfig().ConcurrentSync)
+	}()
+
+	core.RegisterWorkerPool("health checks", m.checkPool)
+	core.RegisterWorkerPool("scans", m.scans.Pool)
 
 	// Setup recurrent tasks
 	var repositoryScanTicker <-chan time.Time
 	repositoryScanInterval := -1
+	var dnsRefreshTicker <-chan time.Time
+	dnsRefreshInterval := -1
+	var coverageTicker <-chan time.Time
+	coverageInterval := -1
+	var integrityTicker <-chan time.Time
+	integrityInterval := -1
+	var telemetryTicker <-chan time.Time
+	telemetryInterval := -1
+	var mirmonTicker <-chan time.Time
+	mirmonInterval := -1
 	mirrorCheckTicker := time.NewTicker(1 * time.Second)
 
 	// Disable the mirror check while stopping to avoid spurious events
@@ -243,6 +296,8 @@ func (m *monitor) MonitorLoop() {
 			if err == nil {
 				m.syncMirrorList(id)
 			}
+		case <-pauseEvent:
+			m.refreshPaused()
 		case <-m.configNotifier:
 			if repositoryScanInterval != GetConfig().RepositoryScanInterval {
 				repositoryScanInterval = GetConfig().RepositoryScanInterval
@@ -253,8 +308,74 @@ func (m *monitor) MonitorLoop() {
 					repositoryScanTicker = time.Tick(time.Duration(repositoryScanInterval) * time.Minute)
 				}
 			}
+			if dnsRefreshInterval != GetConfig().DNSRefreshInterval {
+				dnsRefreshInterval = GetConfig().DNSRefreshInterval
+
+				if dnsRefreshInterval == 0 {
+					dnsRefreshTicker = nil
+				} else {
+					dnsRefreshTicker = time.Tick(time.Duration(dnsRefreshInterval) * time.Minute)
+				}
+			}
+			if coverageInterval != GetConfig().CoverageReport.Interval {
+				coverageInterval = GetConfig().CoverageReport.Interval
+
+				if coverageInterval == 0 {
+					coverageTicker = nil
+				} else {
+					coverageTicker = time.Tick(time.Duration(coverageInterval) * time.Minute)
+				}
+			}
+			if integrityInterval != GetConfig().IntegrityCheck.Interval {
+				integrityInterval = GetConfig().IntegrityCheck.Interval
+
+				if integrityInterval == 0 {
+					integrityTicker = nil
+				} else {
+					integrityTicker = time.Tick(time.Duration(integrityInterval) * time.Minute)
+				}
+			}
+			if mirmonInterval != GetConfig().Mirmon.Interval {
+				mirmonInterval = GetConfig().Mirmon.Interval
+
+				if mirmonInterval == 0 {
+					mirmonTicker = nil
+				} else {
+					mirmonTicker = time.Tick(time.Duration(mirmonInterval) * time.Minute)
+				}
+			}
+			interval := 0
+			if GetConfig().Telemetry.Endpoint != "" {
+				interval = GetConfig().Telemetry.Interval
+			}
+			if telemetryInterval != interval {
+				telemetryInterval = interval
+
+				if telemetryInterval == 0 {
+					telemetryTicker = nil
+				} else {
+					telemetryTicker = time.Tick(time.Duration(telemetryInterval) * time.Hour)
+					go m.sendTelemetry()
+				}
+			}
 		case <-repositoryScanTicker:
 			m.scanRepository()
+		case <-dnsRefreshTicker:
+			if m.paused[database.PauseMonitoring] {
+				continue
+			}
+			go m.resolveMirrors()
+		case <-coverageTicker:
+			go m.computeCoverage()
+		case <-integrityTicker:
+			if m.paused[database.PauseScanning] {
+				continue
+			}
+			go m.verifyRepository()
+		case <-telemetryTicker:
+			go m.sendTelemetry()
+		case <-mirmonTicker:
+			go m.writeMirmon()
 		case <-mirrorCheckTicker.C:
 			if m.redis.Failure() {
 				continue
@@ -265,19 +386,19 @@ func (m *monitor) MonitorLoop() {
 					// Ignore disabled mirrors
 					continue
 				}
-				if v.NeedHealthCheck() && !v.IsChecking() && m.cluster.IsHandled(id) {
+				if v.NeedHealthCheck() && !v.IsChecking() && m.cluster.IsHandled(id) && !m.paused[database.PauseMonitoring] {
 					select {
 					case m.healthCheckChan <- id:
 						m.mirrors[id].checking = true
 					default:
 					}
 				}
-				if v.NeedSync() && !v.IsScanning() && m.cluster.IsHandled(id) {
-					select {
-					case m.syncChan <- id:
-						m.mirrors[id].scanning = true
-					default:
+				if v.NeedSync() && !m.scans.Has(id) && m.cluster.IsHandled(id) && !m.paused[database.PauseScanning] {
+					source := v.ScanRequestedBy
+					if source == "" {
+						source = core.ScanSourceScheduler
 					}
+					m.scans.Add(id, v.Name, source, nil, false)
 				}
 			}
 			m.mapLock.Unlock()
@@ -285,6 +406,36 @@ func (m *monitor) MonitorLoop() {
 	}
 }
 
+// QueueStatus returns the number of health checks queued or in progress, the
+// number of scans in progress and the number of scans waiting for a worker
+func (m *monitor) QueueStatus() (checks, scans, pendingScans int) {
+	m.mapLock.Lock()
+	for _, v := range m.mirrors {
+		if v.IsChecking() {
+			checks++
+		}
+	}
+	m.mapLock.Unlock()
+
+	pool := m.scans.Pool()
+	return checks, pool.Busy, pool.Queued
+}
+
+// ScanQueue returns the queue of the scans of the mirrors
+func (m *monitor) ScanQueue() *scan.Queue {
+	return m.scans
+}
+
+// checkPool reports the activity of the health check workers
+func (m *monitor) checkPool() core.WorkerPool {
+	return core.WorkerPool{
+		Workers:   int(atomic.LoadInt32(&m.checkWorkers)),
+		Busy:      int(atomic.LoadInt32(&m.busyChecks)),
+		Queued:    len(m.healthCheckChan),
+		QueueSize: cap(m.healthCheckChan),
+	}
+}
+
 // Returns a list of all mirrors ID
 func (m *monitor) mirrorsID() ([]int, error) {
 	var ids []int
@@ -311,6 +462,10 @@ func (m *monitor) syncMirrorList(mirrorsIDs ...int) error {
 			delete(m.mirrors, id)
 			m.mapLock.Unlock()
 			m.cluster.RemoveMirrorID(id)
+			m.scans.Remove(id)
+			if scan.Cancel(id) {
+				log.Noticef("Scan of the removed mirror #%d aborted", id)
+			}
 			continue
 		}
 
@@ -367,7 +522,9 @@ func (m *monitor) healthCheckLoop() {
 			mirror = *mptr
 			m.mapLock.Unlock()
 
+			atomic.AddInt32(&m.busyChecks, 1)
 			err := m.healthCheck(mirror.Mirror)
+			atomic.AddInt32(&m.busyChecks, -1)
 
 			if err == errMirrorNotScanned {
 				// Not removing the 'checking' lock is intended here so the mirror won't
@@ -387,89 +544,71 @@ func (m *monitor) healthCheckLoop() {
 	}
 }
 
-// Main sync loop
-// TODO merge with the monitorLoop?
-func (m *monitor) syncLoop() {
-	defer m.wg.Done()
-	for {
-		select {
-		case <-m.stop:
-			return
-		case id := <-m.syncChan:
+// runScan runs a scan of the queue, along with the fetch of the trace file
+// of the mirror
+func (m *monitor) runScan(ctx context.Context, s *scan.QueuedScan) (*scan.ScanResult, error) {
+	var mir mirrors.Mirror
+	m.mapLock.Lock()
+	if mirrorPtr, ok := m.mirrors[s.MirrorID]; ok {
+		mir = mirrorPtr.Mirror
+	}
+	m.mapLock.Unlock()
+
+	var err error
+	if mir.ID == 0 {
+		// Mirror not yet known by the monitor, e.g. added right before
+		// being scanned from the CLI
+		if mir, err = m.cache.GetMirror(s.MirrorID); err != nil {
+			return nil, err
+		}
+	}
 
-			var mir mirror
-			var mirrorPtr *mirror
-			var ok bool
+	conn := m.redis.Get()
+	scanning, err := scan.IsScanning(conn, s.MirrorID)
+	if err == nil && !scanning && mir.ScanRequestedBy != "" {
+		// The request is handled
+		_, err = conn.Do("HDEL", fmt.Sprintf("MIRROR_%d", s.MirrorID), "scanRequestedBy")
+	}
+	conn.Close()
+	if err != nil {
+		if !database.RedisIsLoading(err) {
+			log.Warningf("syncloop: %s", err.Error())
+		}
+		return nil, err
+	} else if scanning {
+		log.Debugf("[%s] scan already in progress on another node", mir.Name)
+		return nil, scan.ErrScanInProgress
+	}
 
-			m.mapLock.Lock()
-			if mirrorPtr, ok = m.mirrors[id]; !ok {
-				m.mapLock.Unlock()
-				continue
-			}
-			mir = *mirrorPtr
-			m.mapLock.Unlock()
+	log.Debugf("Scanning %s (requested by %s)", mir.Name, s.Source)
 
-			conn := m.redis.Get()
-			scanning, err := scan.IsScanning(conn, id)
-			if err != nil {
-				conn.Close()
-				if !database.RedisIsLoading(err) {
-					log.Warningf("syncloop: %s", err.Error())
-				}
-				goto end
-			} else if scanning {
-				log.Debugf("[%s] scan already in progress on another node", mir.Name)
-				conn.Close()
-				goto end
-			}
-			conn.Close()
-
-			log.Debugf("Scanning %s", mir.Name)
-
-			// Start fetching the latest trace
-			go func() {
-				err := m.trace.GetLastUpdate(mir.Mirror)
-				if err != nil && err != scan.ErrNoTrace {
-					if numError, ok := err.(*strconv.NumError); ok {
-						if numError.Err == strconv.ErrSyntax {
-							log.Warningf("[%s] parsing trace file failed: %s is not a valid timestamp", mir.Name, strconv.Quote(numError.Num))
-							return
-						}
-					} else {
-						log.Warningf("[%s] fetching trace file failed: %s", mir.Name, err)
-					}
+	// Start fetching the latest trace
+	go func() {
+		err := m.trace.GetLastUpdate(mir)
+		if err != nil && err != scan.ErrNoTrace {
+			if numError, ok := err.(*strconv.NumError); ok {
+				if numError.Err == strconv.ErrSyntax {
+					log.Warningf("[%s] parsing trace file failed: %s is not a valid timestamp", mir.Name, strconv.Quote(numError.Num))
+					return
 				}
-			}()
-
-			err = scan.ErrNoSyncMethod
-
-			// First try to scan with rsync
-			if mir.RsyncURL != "" {
-				_, err = scan.Scan(core.RSYNC, m.redis, m.cache, mir.RsyncURL, id, m.stop)
-			}
-			// If it failed or rsync wasn't supported
-			// fallback to FTP
-			if err != nil && err != scan.ErrScanAborted && mir.FtpURL != "" {
-				_, err = scan.Scan(core.FTP, m.redis, m.cache, mir.FtpURL, id, m.stop)
+			} else {
+				log.Warningf("[%s] fetching trace file failed: %s", mir.Name, err)
 			}
+		}
+	}()
 
-			if err == scan.ErrScanInProgress {
-				log.Warningf("%-30.30s Scan already in progress", mir.Name)
-				goto end
-			}
+	// Try the sync manifest, then rsync and fallback to FTP
+	res, err := scan.ScanMirror(ctx, m.redis, m.cache, s)
 
-			if err == nil && mir.Enabled == true && mir.Up == false {
-				m.healthCheckChan <- id
-			}
+	if err == scan.ErrScanInProgress {
+		log.Warningf("%-30.30s Scan already in progress", mir.Name)
+		return nil, err
+	}
 
-		end:
-			m.mapLock.Lock()
-			if mirrorPtr, ok = m.mirrors[id]; ok {
-				mirrorPtr.scanning = false
-			}
-			m.mapLock.Unlock()
-		}
+	if err == nil && mir.Enabled == true && mir.Up == false {
+		m.healthCheckChan <- s.MirrorID
 	}
+	return res, err
 }
 
 // Do an actual health check against a given mirror
@@ -488,43 +627,82 @@ func (m *monitor) healthCheck(mirror mirrors.Mirror) error {
 		return err
 	}
 
-	// Prepare the HTTP request
-	req, err := http.NewRequest("HEAD", strings.TrimRight(mirror.HttpURL, "/")+file, nil)
-	req.Header.Set("User-Agent", userAgent)
-	req.Close = true
+	// Check both families separately on dual-stack mirrors so a mirror broken
+	// over a single family can still serve the clients of the other one
+	ipv4, ipv6, err := lookupIPFamilies(mirror.HttpURL)
+	if err != nil {
+		log.Debugf(format+"Unable to resolve the IP families: %s", mirror.Name, err)
+	}
+	// The family cannot be chosen through a proxy
+	proxy, _ := mirror.ProxyURL()
+	dualStack := ipv4 && ipv6 && proxy == nil
+
+	var probes []healthProbe
+	if dualStack {
+		probes = []healthProbe{{network: "tcp4"}, {network: "tcp6"}}
+	} else {
+		probes = []healthProbe{{network: "tcp"}}
+	}
 
-	ctx, cancel := context.WithTimeout(req.Context(), clientDeadline)
-	ctx = context.WithValue(ctx, core.ContextMirrorID, mirror.ID)
-	ctx = context.WithValue(ctx, core.ContextMirrorName, mirror.Name)
-	ctx = context.WithValue(ctx, core.ContextAllowRedirects, mirror.AllowRedirects)
-	req = req.WithContext(ctx)
-	defer cancel()
+	for i := range probes {
+		m.probe(mirror, file, &probes[i])
+		if utils.IsStopped(m.stop) {
+			return nil
+		}
+	}
 
-	go func() {
-		select {
-		case <-m.stop:
-			log.Debugf("Aborting health-check for %s", mirror.HttpURL)
-			cancel()
-		case <-ctx.Done():
+	// Use the result of the first family answering properly, if any
+	result := probes[0]
+	for _, p := range probes {
+		if p.err == nil && mirror.Probe.Expects(p.statusCode) {
+			result = p
+			break
 		}
-	}()
+	}
 
-	var contentLength string
-	var statusCode int
-	elapsed, err := m.httpDo(ctx, req, func(resp *http.Response, err error) error {
-		if err != nil {
-			return err
+	if dualStack {
+		up4 := probes[0].err == nil && mirror.Probe.Expects(probes[0].statusCode)
+		up6 := probes[1].err == nil && mirror.Probe.Expects(probes[1].statusCode)
+		if up4 != up6 {
+			failed := probes[0]
+			if up4 {
+				failed = probes[1]
+			}
+			reason := fmt.Sprintf("status code %d", failed.statusCode)
+			if failed.err != nil {
+				reason = failed.err.Error()
+			}
+			log.Warningf(format+"Down over %s: %s", mirror.Name, familyName(failed.network), reason)
 		}
-		defer resp.Body.Close()
-		statusCode = resp.StatusCode
-		contentLength = resp.Header.Get("Content-Length")
-		return nil
-	})
+		if up4 || up6 {
+			ipv4, ipv6 = up4, up6
+		}
+	}
+	if ipv4 || ipv6 {
+		if err := mirrors.SetMirrorIPFamilies(m.redis, mirror.ID, ipv4, ipv6); err != nil {
+			log.Errorf(format+"Unable to record the IP families: %s", mirror.Name, err)
+		}
+	}
 
-	if utils.IsStopped(m.stop) {
-		return nil
+	m.checkMoved(mirror, file, result, format)
+
+	// A multi-homed mirror stays up as long as one of its endpoints answers
+	if len(mirror.Endpoints) > 0 || len(mirror.EndpointsDown) > 0 {
+		primaryUp := result.err == nil && mirror.Probe.Expects(result.statusCode)
+		if m.checkEndpoints(mirror, file, primaryUp, format) && !primaryUp {
+			if err := mirrors.MarkMirrorUp(m.redis, mirror.ID); err != nil {
+				log.Errorf(format+"Unable to mark mirror as up: %s", mirror.Name, err)
+			}
+			log.Warningf(format+"Up through its other endpoints only", mirror.Name)
+			return nil
+		}
 	}
 
+	elapsed := result.elapsed
+	statusCode := result.statusCode
+	contentLength := result.contentLength
+	err = result.err
+
 	if err != nil {
 		if opErr, ok := err.(*net.OpError); ok {
 			log.Debugf("Op: %s | Net: %s | Addr: %s | Err: %s | Temporary: %t", opErr.Op, opErr.Net, opErr.Addr, opErr.Error(), opErr.Temporary())
@@ -538,19 +716,49 @@ func (m *monitor) healthCheck(mirror mirrors.Mirror) error {
 		return err
 	}
 
-	switch statusCode {
-	case 200:
+	if statusCode >= 400 && !mirror.Probe.Expects(statusCode) {
+		window := time.Duration(GetConfig().HTTPErrorsWindow) * time.Hour
+		if err := mirrors.RecordHTTPError(m.redis, mirror.ID, mirrors.ErrorSourceMonitor, statusCode, window); err != nil {
+			log.Errorf(format+"Unable to record the error: %s", mirror.Name, err)
+		}
+	}
+
+	switch {
+	case mirror.Probe.Expects(statusCode):
+		// Some web servers answer a custom error page with a 200 status
+		// instead of a 404 for the missing files
+		if statusCode == 200 && suspectErrorPage(file, size, result) {
+			sniff := healthProbe{network: result.network, sniff: true}
+			m.probe(mirror, file, &sniff)
+			if utils.IsStopped(m.stop) {
+				return nil
+			}
+			if sniff.err == nil && isHTMLPage(file, sniff.head) {
+				err = mirrors.MarkMirrorDown(m.redis, mirror.ID, fmt.Sprintf("HTML page served instead of %s (error 200)", file))
+				if err != nil {
+					log.Errorf(format+"Unable to mark mirror as down: %s", mirror.Name, err)
+				}
+				if GetConfig().DisableOnMissingFile {
+					err = mirrors.DisableMirror(m.redis, mirror.ID)
+					if err != nil {
+						log.Errorf(format+"Unable to disable mirror: %s", mirror.Name, err)
+					}
+				}
+				log.Errorf(format+"Error: HTML page served instead of %s (error 200)", mirror.Name, file)
+				return nil
+			}
+		}
 		err = mirrors.MarkMirrorUp(m.redis, mirror.ID)
 		if err != nil {
 			log.Errorf(format+"Unable to mark mirror as up: %s", mirror.Name, err)
 		}
 		rsize, err := strconv.ParseInt(contentLength, 10, 64)
-		if err == nil && rsize != size {
+		if statusCode == 200 && err == nil && rsize != size {
 			log.Warningf(format+"File size mismatch! [%s] (%dms)", mirror.Name, file, elapsed/time.Millisecond)
 		} else {
 			log.Noticef(format+"Up! (%dms)", mirror.Name, elapsed/time.Millisecond)
 		}
-	case 404:
+	case statusCode == 404:
 		err = mirrors.MarkMirrorDown(m.redis, mirror.ID, fmt.Sprintf("File not found %s (error 404)", file))
 		if err != nil {
 			log.Errorf(format+"Unable to mark mirror as down: %s", mirror.Name, err)
@@ -572,6 +780,228 @@ func (m *monitor) healthCheck(mirror mirrors.Mirror) error {
 	return nil
 }
 
+// checkMoved records the permanent redirection of the HTTP URL of the
+// mirror, updating it after MovedMirrorUpdateThreshold consecutive checks
+func (m *monitor) checkMoved(mirror mirrors.Mirror, file string, result healthProbe, format string) {
+	if result.movedTo == "" {
+		if mirror.MovedTo != "" && result.err == nil {
+			if err := mirrors.ClearMirrorMove(m.redis, mirror.ID); err != nil {
+				log.Errorf(format+"Unable to clear the redirection: %s", mirror.Name, err)
+			}
+		}
+		return
+	}
+
+	// The new base URL is only known if the path of the file is kept
+	file = "/" + strings.TrimPrefix(mirror.PathRewrites.ToMirror(file), "/")
+	if !strings.HasSuffix(result.movedTo, file) {
+		log.Warningf(format+"Permanent redirect to %s", mirror.Name, result.movedTo)
+		return
+	}
+	url := strings.TrimSuffix(result.movedTo, file) + "/"
+
+	count, err := mirrors.RecordMirrorMove(m.redis, mirror.ID, url)
+	if err != nil {
+		log.Errorf(format+"Unable to record the redirection: %s", mirror.Name, err)
+		return
+	}
+
+	threshold := GetConfig().MovedMirrorUpdateThreshold
+	if threshold > 0 && count >= threshold {
+		if err := mirrors.MoveMirror(m.redis, mirror.ID, url, count); err != nil {
+			log.Errorf(format+"Unable to update the HTTP URL: %s", mirror.Name, err)
+			return
+		}
+		log.Noticef(format+"HTTP URL updated to %s", mirror.Name, url)
+		return
+	}
+	log.Warningf(format+"HTTP URL permanently redirects to %s (%d times in a row)", mirror.Name, url, count)
+}
+
+// checkEndpoints checks the additional endpoints of a multi-homed mirror and
+// records the ones that are down. It returns true if any of them is up.
+func (m *monitor) checkEndpoints(mirror mirrors.Mirror, file string, primaryUp bool, format string) bool {
+	var down mirrors.URLList
+	if !primaryUp {
+		down = append(down, mirror.HttpURL)
+	}
+
+	anyUp := false
+	for _, e := range mirror.Endpoints {
+		endpoint := mirror
+		endpoint.HttpURL = e.HttpURL
+		p := healthProbe{network: "tcp"}
+		m.probe(endpoint, file, &p)
+		if utils.IsStopped(m.stop) {
+			return false
+		}
+		if p.err == nil && mirror.Probe.Expects(p.statusCode) {
+			anyUp = true
+			if mirror.EndpointsDown.Contains(e.HttpURL) {
+				log.Noticef(format+"Endpoint %s is up", mirror.Name, e.HttpURL)
+			}
+			continue
+		}
+		down = append(down, e.HttpURL)
+		if !mirror.EndpointsDown.Contains(e.HttpURL) {
+			reason := fmt.Sprintf("status code %d", p.statusCode)
+			if p.err != nil {
+				reason = p.err.Error()
+			}
+			log.Warningf(format+"Endpoint %s is down: %s", mirror.Name, e.HttpURL, reason)
+		}
+	}
+
+	if down.RedisArg() != mirror.EndpointsDown.RedisArg() {
+		if err := mirrors.SetEndpointsDown(m.redis, mirror.ID, down); err != nil {
+			log.Errorf(format+"Unable to record the state of the endpoints: %s", mirror.Name, err)
+		}
+	}
+	return anyUp
+}
+
+// healthProbe is the result of a health check over a given network
+type healthProbe struct {
+	network       string
+	statusCode    int
+	contentLength string
+	contentType   string
+	elapsed       time.Duration
+	err           error
+	movedTo       string
+	// sniff requests the first bytes of the file in head instead of
+	// its headers only
+	sniff bool
+	head  []byte
+}
+
+// probe requests the given file from the mirror over the network of the probe
+func (m *monitor) probe(mirror mirrors.Mirror, file string, p *healthProbe) {
+	// Prepare the HTTP request
+	proxy, err := mirror.ProxyURL()
+	if err != nil {
+		p.err = err
+		return
+	}
+
+	method := "HEAD"
+	if p.sniff {
+		method = "GET"
+	}
+	req, err := http.NewRequest(method, mirror.FileURL(file), nil)
+	if err != nil {
+		p.err = err
+		return
+	}
+	req.Header.Set("User-Agent", userAgent)
+	mirror.Probe.Apply(req)
+	if p.sniff {
+		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", sniffLength-1))
+	}
+	req.Close = true
+
+	ctx, cancel := context.WithTimeout(req.Context(), clientDeadline)
+	ctx = context.WithValue(ctx, core.ContextMirrorID, mirror.ID)
+	ctx = context.WithValue(ctx, core.ContextMirrorName, mirror.Name)
+	ctx = context.WithValue(ctx, core.ContextAllowRedirects, mirror.AllowRedirects)
+	ctx = context.WithValue(ctx, core.ContextNetwork, p.network)
+	ctx = context.WithValue(ctx, core.ContextPermanentRedirect, &p.movedTo)
+	ctx = context.WithValue(ctx, core.ContextProxy, proxy)
+	req = req.WithContext(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-m.stop:
+			log.Debugf("Aborting health-check for %s", mirror.HttpURL)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	p.elapsed, p.err = m.httpDo(ctx, req, func(resp *http.Response, err error) error {
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		p.statusCode = resp.StatusCode
+		p.contentLength = resp.Header.Get("Content-Length")
+		p.contentType = resp.Header.Get("Content-Type")
+		if p.sniff {
+			p.head, err = ioutil.ReadAll(io.LimitReader(resp.Body, sniffLength))
+		}
+		return err
+	})
+}
+
+// suspectErrorPage returns true if the answer of the mirror to the HEAD
+// request of the file may be an error page, its type being HTML or its
+// length differing from the one of the file
+func suspectErrorPage(file string, size int64, p healthProbe) bool {
+	if isHTMLFile(file) {
+		return false
+	}
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(p.contentType)), "text/html") {
+		return true
+	}
+	rsize, err := strconv.ParseInt(p.contentLength, 10, 64)
+	return err == nil && rsize != size
+}
+
+// isHTMLPage returns true if the first bytes served by the mirror for the
+// file are an HTML page while the file itself is not one
+func isHTMLPage(file string, head []byte) bool {
+	if isHTMLFile(file) || len(head) == 0 {
+		return false
+	}
+	return strings.HasPrefix(http.DetectContentType(head), "text/html")
+}
+
+// isHTMLFile returns true if the file is expected to be an HTML page
+func isHTMLFile(file string) bool {
+	switch strings.ToLower(path.Ext(file)) {
+	case ".html", ".htm", ".xhtml", ".shtml":
+		return true
+	}
+	return false
+}
+
+// familyName returns the name of the IP family of the given network
+func familyName(network string) string {
+	switch network {
+	case "tcp4":
+		return "IPv4"
+	case "tcp6":
+		return "IPv6"
+	}
+	return "IP"
+}
+
+// lookupIPFamilies returns whether the host of the given URL has IPv4 and
+// IPv6 addresses
+func lookupIPFamilies(rawurl string) (ipv4, ipv6 bool, err error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return false, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clientTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return false, false, err
+	}
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			ipv4 = true
+		} else {
+			ipv6 = true
+		}
+	}
+	return ipv4, ipv6, nil
+}
+
 func (m *monitor) httpDo(ctx context.Context, req *http.Request, f func(*http.Response, error) error) (time.Duration, error) {
 	var elapsed time.Duration
 	c := make(chan error, 1)
@@ -613,9 +1043,72 @@ func (m *monitor) getRandomFile(id int) (file string, size int64, err error) {
 	return
 }
 
+// computeCoverage updates the report of the coverage of the mirrors
+func (m *monitor) computeCoverage() {
+	c := GetConfig().CoverageReport
+	report, err := mirrors.ComputeCoverage(m.redis, c.Days, int64(c.MinRequests), float32(c.MaxDistance))
+	if err != nil {
+		log.Errorf("Unable to compute the coverage report: %s", err)
+		return
+	}
+	if err := mirrors.SaveCoverage(m.redis, report); err != nil {
+		log.Errorf("Unable to save the coverage report: %s", err)
+		return
+	}
+	if gaps := report.Gaps(); len(gaps) > 0 {
+		log.Noticef("Coverage report: %d coverage gap%s found", len(gaps), utils.Plural(len(gaps)))
+	}
+}
+
+// verifyRepository re-hashes the next files of the local repository
+func (m *monitor) verifyRepository() {
+	if !atomic.CompareAndSwapInt32(&m.verifying, 0, 1) {
+		// The previous run is still in progress
+		return
+	}
+	defer atomic.StoreInt32(&m.verifying, 0)
+
+	res, err := scan.VerifySource(m.ctx, m.redis, "", GetConfig().IntegrityCheck.Files)
+	if err != nil {
+		log.Errorf("Unable to verify the local repository: %s", err)
+		return
+	}
+	if len(res.Mismatches) > 0 {
+		log.Errorf("Integrity check: %d file%s out of %d no longer matching their hashes", len(res.Mismatches), utils.Plural(len(res.Mismatches)), res.Checked)
+	}
+}
+
+// refreshPaused updates the background activities paused by the operators
+func (m *monitor) refreshPaused() {
+	paused, err := m.redis.GetPaused()
+	if err != nil {
+		log.Errorf("Unable to fetch the paused activities: %s", err)
+		return
+	}
+	for _, activity := range database.PauseActivities {
+		_, p := paused[activity]
+		if p && !m.paused[activity] {
+			log.Noticef("Background %s paused", activity)
+			if activity == database.PauseScanning {
+				// The scans requested from the CLI are not background ones
+				if n := m.scans.RemoveSource(core.ScanSourceScheduler, core.ScanSourceAPI, core.ScanSourcePush); n > 0 {
+					log.Noticef("Dropped %d pending scan(s)", n)
+				}
+			}
+		} else if !p && m.paused[activity] {
+			log.Noticef("Background %s resumed", activity)
+		}
+		m.paused[activity] = p
+	}
+}
+
 // Trigger a sync of the local repository
 func (m *monitor) scanRepository() error {
-	err := scan.ScanSource(m.redis, false, m.stop)
+	if m.paused[database.PauseScanning] {
+		log.Info("Skipping the scan of the local repository: the scanning is paused")
+		return nil
+	}
+	err := scan.ScanSource(m.ctx, m.redis, false)
 	if err != nil {
 		log.Errorf("Scanning source failed: %s", err.Error())
 	}
@@ -0,0 +1,52 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package daemon
+
+import (
+	"testing"
+)
+
+func TestSuspectErrorPage(t *testing.T) {
+	tests := []struct {
+		file     string
+		size     int64
+		probe    healthProbe
+		expected bool
+	}{
+		{"/dist/file.tar.gz", 1024, healthProbe{contentLength: "1024", contentType: "application/gzip"}, false},
+		{"/dist/file.tar.gz", 1024, healthProbe{contentType: "application/gzip"}, false},
+		{"/dist/file.tar.gz", 1024, healthProbe{contentLength: "312", contentType: "application/gzip"}, true},
+		{"/dist/file.tar.gz", 1024, healthProbe{contentLength: "1024", contentType: "text/html; charset=UTF-8"}, true},
+		{"/dist/file.tar.gz", 1024, healthProbe{contentType: "TEXT/HTML"}, true},
+		{"/index.html", 1024, healthProbe{contentLength: "312", contentType: "text/html"}, false},
+		{"/doc/page.HTM", 1024, healthProbe{contentLength: "312", contentType: "text/html"}, false},
+	}
+
+	for i, test := range tests {
+		if r := suspectErrorPage(test.file, test.size, test.probe); r != test.expected {
+			t.Fatalf("Test %d: expected %t, got %t", i, test.expected, r)
+		}
+	}
+}
+
+func TestIsHTMLPage(t *testing.T) {
+	tests := []struct {
+		file     string
+		head     string
+		expected bool
+	}{
+		{"/dist/file.tar.gz", "<!DOCTYPE html><html><body>Not found</body></html>", true},
+		{"/dist/file.iso", "\n  <html>\n<head><title>404</title></head>", true},
+		{"/dist/file.tar.gz", "\x1f\x8b\x08\x00\x00\x00\x00\x00", false},
+		{"/dist/file.txt", "Release notes", false},
+		{"/dist/file.tar.gz", "", false},
+		{"/index.html", "<!DOCTYPE html><html></html>", false},
+	}
+
+	for i, test := range tests {
+		if r := isHTMLPage(test.file, []byte(test.head)); r != test.expected {
+			t.Fatalf("Test %d: expected %t, got %t", i, test.expected, r)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package daemon
+
+import (
+	"net"
+	"net/url"
+
+	"github.com/etix/mirrorbits/mirrors"
+	"github.com/etix/mirrorbits/network"
+	"github.com/etix/mirrorbits/utils"
+)
+
+// resolveMirrors looks up the hostname of the mirrors handled by this node
+// again and updates their location when their address changed
+func (m *monitor) resolveMirrors() {
+	m.mapLock.Lock()
+	list := make([]mirrors.Mirror, 0, len(m.mirrors))
+	for id, v := range m.mirrors {
+		if m.cluster.IsHandled(id) {
+			list = append(list, v.Mirror)
+		}
+	}
+	m.mapLock.Unlock()
+
+	if len(list) == 0 {
+		return
+	}
+
+	geo := network.NewGeoIP()
+	if err := geo.LoadGeoIP(); err != nil {
+		log.Warningf("DNS refresh: unable to load the GeoIP databases: %s", err)
+	}
+
+	for _, mirror := range list {
+		if utils.IsStopped(m.stop) {
+			return
+		}
+
+		u, err := url.Parse(mirror.HttpURL)
+		if err != nil {
+			continue
+		}
+
+		addrs, err := net.LookupIP(u.Hostname())
+		if err != nil || len(addrs) == 0 {
+			log.Warningf("DNS refresh: unable to resolve %s: %s", mirror.Name, err)
+			continue
+		}
+
+		// Hostnames with several addresses might return them in any order
+		known := false
+		for _, addr := range addrs {
+			if addr.String() == mirror.IPAddress {
+				known = true
+				break
+			}
+		}
+		if known {
+			continue
+		}
+		ip := addrs[0].String()
+
+		// The location of mirrors added before the address was recorded
+		// might have been set manually, keep it. CDN mirrors have no fixed
+		// location at all.
+		var geoRec network.GeoIPRecord
+		if mirror.IPAddress != "" && !mirror.CDN {
+			geoRec = geo.GetRecord(ip)
+		}
+
+		distance, err := mirrors.UpdateMirrorAddress(m.redis, &mirror, ip, geoRec)
+		if err != nil {
+			log.Errorf("DNS refresh: unable to update the address of %s: %s", mirror.Name, err)
+			continue
+		}
+
+		if mirror.IPAddress == "" {
+			log.Infof("DNS refresh: %s resolved to %s", mirror.Name, ip)
+		} else {
+			log.Noticef("DNS refresh: %s moved from %s to %s (%d km)", mirror.Name, mirror.IPAddress, ip, int(distance))
+		}
+	}
+}
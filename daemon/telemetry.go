@@ -0,0 +1,102 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/core"
+	"github.com/gomodule/redigo/redis"
+)
+
+// telemetryReport holds the anonymous numbers sent upstream (see Telemetry)
+type telemetryReport struct {
+	Version string `json:"version"`
+	Mirrors int    `json:"mirrors"`
+	Files   int    `json:"files"`
+	// Redirections of the previous day
+	Redirects int64 `json:"redirects"`
+}
+
+// collectTelemetry returns the report of the given day
+func collectTelemetry(conn redis.Conn, now time.Time) (*telemetryReport, error) {
+	report := &telemetryReport{
+		Version: core.VERSION,
+	}
+
+	var err error
+	if report.Mirrors, err = redis.Int(conn.Do("HLEN", "MIRRORS")); err != nil {
+		return nil, err
+	}
+	if report.Files, err = redis.Int(conn.Do("SCARD", "FILES")); err != nil {
+		return nil, err
+	}
+	key := "STATS_MIRROR_" + now.AddDate(0, 0, -1).Format("2006_01_02")
+	counts, err := redis.Int64s(conn.Do("HVALS", key))
+	if err != nil && err != redis.ErrNil {
+		return nil, err
+	}
+	for _, c := range counts {
+		report.Redirects += c
+	}
+	return report, nil
+}
+
+// sendTelemetry posts the report to the configured endpoint, unless another
+// instance of the cluster already did it during the current interval
+func (m *monitor) sendTelemetry() {
+	conf := GetConfig().Telemetry
+	if conf.Endpoint == "" {
+		return
+	}
+
+	conn := m.redis.Get()
+	defer conn.Close()
+
+	interval := time.Duration(conf.Interval) * time.Hour
+	if _, err := redis.String(conn.Do("SET", "TELEMETRY_SENT", time.Now().Unix(), "EX", int(interval.Seconds())-60, "NX")); err != nil {
+		// Already sent or the database is unavailable
+		return
+	}
+
+	report, err := collectTelemetry(conn, time.Now())
+	if err != nil {
+		log.Warningf("Unable to collect the telemetry: %s", err)
+		return
+	}
+	body, err := json.Marshal(report)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequest("POST", conf.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		log.Warningf("Unable to send the telemetry: %s", err)
+		return
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Mirrorbits/"+core.VERSION+" TELEMETRY")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			err = fmt.Errorf("%s", resp.Status)
+		}
+	}
+	if err != nil {
+		log.Warningf("Unable to send the telemetry: %s", err)
+		return
+	}
+	log.Infof("Telemetry sent to %s: %s", conf.Endpoint, body)
+}
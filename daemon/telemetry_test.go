@@ -0,0 +1,33 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/etix/mirrorbits/core"
+	. "github.com/etix/mirrorbits/testing"
+)
+
+func TestCollectTelemetry(t *testing.T) {
+	mock, r := PrepareRedisTest()
+	conn := r.Get()
+	defer conn.Close()
+
+	mock.Command("HLEN", "MIRRORS").Expect(int64(3))
+	mock.Command("SCARD", "FILES").Expect(int64(1500))
+	mock.Command("HVALS", "STATS_MIRROR_2019_03_14").Expect([]interface{}{
+		[]byte("120"),
+		[]byte("80"),
+	})
+
+	report, err := collectTelemetry(conn, time.Date(2019, 3, 15, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if report.Version != core.VERSION || report.Mirrors != 3 || report.Files != 1500 || report.Redirects != 200 {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+}
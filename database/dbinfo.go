@@ -0,0 +1,122 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package database
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Families of keys of the dataset, a key belonging to the first family
+// having one of its prefixes
+var keyFamilies = []struct {
+	name     string
+	prefixes []string
+}{
+	{"Mirrors", []string{"MIRROR_", "MIRRORS", "MIRRORLOGS_", "SCANSUMMARIES_", "CONTACT_"}},
+	{"Files", []string{"FILE_", "FILES", "FILEALIAS_", "FILETOMBSTONE_"}},
+	{"File infos", []string{"FILEINFOS_", "FILEINFO_"}},
+	{"File-mirror sets", []string{"FILEMIRRORS_"}},
+	{"Mirror file lists", []string{"MIRRORFILES_", "MIRRORFILESTMP_", "MIRRORFILESQUARANTINE_", "HANDLEDFILES_"}},
+	{"Stats", []string{"STATS_"}},
+}
+
+// KeyFamily is the number of keys of a family and their approximate memory
+// usage, extrapolated from the given number of sampled keys
+type KeyFamily struct {
+	Name    string
+	Keys    int64
+	Sampled int64
+	Memory  int64
+}
+
+// MemoryReport is the memory usage of the dataset per family of keys
+type MemoryReport struct {
+	Families   []KeyFamily
+	Keys       int64
+	UsedMemory int64
+}
+
+func keyFamily(key string) int {
+	for i, f := range keyFamilies {
+		for _, prefix := range f.prefixes {
+			if strings.HasPrefix(key, prefix) {
+				return i
+			}
+		}
+	}
+	return len(keyFamilies)
+}
+
+// MemoryReport counts the keys of each family and estimates their memory
+// usage from the first samples keys of each family. The estimations are
+// left empty if the server doesn't support the MEMORY command (< 4.0).
+func (r *Redis) MemoryReport(samples int) (*MemoryReport, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	report := &MemoryReport{}
+	for _, f := range keyFamilies {
+		report.Families = append(report.Families, KeyFamily{Name: f.name})
+	}
+	report.Families = append(report.Families, KeyFamily{Name: "Other"})
+
+	sampled := make([]int64, len(report.Families))
+	memoryUsage := true
+
+	cursor := "0"
+	for {
+		values, err := redis.Values(conn.Do("SCAN", cursor, "COUNT", 1000))
+		if err != nil {
+			return nil, err
+		}
+		var keys []string
+		if _, err = redis.Scan(values, &cursor, &keys); err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			i := keyFamily(key)
+			f := &report.Families[i]
+			f.Keys++
+			report.Keys++
+			if !memoryUsage || f.Sampled >= int64(samples) {
+				continue
+			}
+			usage, err := redis.Int64(conn.Do("MEMORY", "USAGE", key))
+			if err == redis.ErrNil {
+				// The key expired in the meantime
+				continue
+			} else if err != nil {
+				log.Warningf("Unable to estimate the memory usage: %s", err)
+				memoryUsage = false
+				continue
+			}
+			f.Sampled++
+			sampled[i] += usage
+		}
+		if cursor == "0" {
+			break
+		}
+	}
+
+	for i := range report.Families {
+		f := &report.Families[i]
+		if !memoryUsage {
+			f.Sampled = 0
+		}
+		if f.Sampled > 0 {
+			f.Memory = sampled[i] / f.Sampled * f.Keys
+		}
+	}
+
+	info, err := parseInfo(conn.Do("INFO", "memory"))
+	if err != nil {
+		return nil, err
+	}
+	report.UsedMemory, _ = strconv.ParseInt(info["used_memory"], 10, 64)
+
+	return report, nil
+}
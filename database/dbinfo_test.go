@@ -0,0 +1,59 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package database
+
+import (
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+)
+
+type mockPool struct {
+	conn *redigomock.Conn
+}
+
+func (p *mockPool) Get() redis.Conn { return p.conn }
+func (p *mockPool) Close() error    { return nil }
+
+func TestMemoryReport(t *testing.T) {
+	mock := redigomock.NewConn()
+	r := NewRedisCustomPool(&mockPool{conn: mock})
+
+	mock.Command("SCAN", "0", "COUNT", 1000).Expect([]interface{}{
+		[]byte("17"),
+		[]interface{}{[]byte("MIRROR_1"), []byte("FILEINFO_1_/a.tgz"), []byte("FILEINFO_1_/b.tgz")},
+	})
+	mock.Command("SCAN", "17", "COUNT", 1000).Expect([]interface{}{
+		[]byte("0"),
+		[]interface{}{[]byte("FILEINFO_2_/a.tgz"), []byte("FILEMIRRORS_/a.tgz"), []byte("SOMETHING")},
+	})
+	mock.Command("MEMORY", "USAGE", "MIRROR_1").Expect(int64(500))
+	mock.Command("MEMORY", "USAGE", "FILEINFO_1_/a.tgz").Expect(int64(100))
+	mock.Command("MEMORY", "USAGE", "FILEINFO_1_/b.tgz").Expect(int64(120))
+	mock.Command("MEMORY", "USAGE", "FILEMIRRORS_/a.tgz").Expect(int64(80))
+	mock.Command("MEMORY", "USAGE", "SOMETHING").Expect(int64(60))
+	mock.Command("INFO", "memory").Expect("# Memory\r\nused_memory:1048576\r\nused_memory_human:1.00M\r\n")
+
+	report, err := r.MemoryReport(2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if report.Keys != 6 || report.UsedMemory != 1048576 {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+
+	expected := map[string]KeyFamily{
+		"Mirrors":          {Name: "Mirrors", Keys: 1, Sampled: 1, Memory: 500},
+		"File infos":       {Name: "File infos", Keys: 3, Sampled: 2, Memory: 330},
+		"File-mirror sets": {Name: "File-mirror sets", Keys: 1, Sampled: 1, Memory: 80},
+		"Stats":            {Name: "Stats"},
+		"Other":            {Name: "Other", Keys: 1, Sampled: 1, Memory: 60},
+	}
+	for _, f := range report.Families {
+		if e, ok := expected[f.Name]; ok && e != f {
+			t.Fatalf("Expected %+v, got %+v", e, f)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package database
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// Maximum number of updates kept in memory while the database is not
+	// writable, the updates of new keys being dropped past this limit
+	maxDeferredWrites = 10000
+)
+
+var (
+	// ErrDeferred is returned when an update is kept in memory until the
+	// database is writable again
+	ErrDeferred = errors.New("database not writable, update deferred")
+	// ErrDeferredDropped is returned when an update is dropped because too
+	// many updates are already waiting for the database
+	ErrDeferredDropped = errors.New("database not writable, too many pending updates, update dropped")
+)
+
+// deferredWrite is an update kept in memory while the database is not
+// writable
+type deferredWrite struct {
+	key string
+	fn  func(redis.Conn) error
+}
+
+// IsWriteError returns true if the error means that the database cannot
+// currently accept writes (read-only replica, failed persistence, out of
+// memory or connection failure)
+func IsWriteError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+	msg := err.Error()
+	for _, prefix := range []string{"READONLY", "MISCONF", "OOM", "NOREPLICAS", "EXECABORT"} {
+		if strings.HasPrefix(msg, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Degraded returns true if the database is not writable, the updates being
+// kept in memory until it recovers
+func (r *Redis) Degraded() bool {
+	r.failureState.RLock()
+	defer r.failureState.RUnlock()
+	return r.degraded
+}
+
+// SetDegraded switches to the degraded mode after the given write error
+func (r *Redis) SetDegraded(err error) {
+	r.failureState.Lock()
+	defer r.failureState.Unlock()
+	if !r.degraded {
+		log.Warningf("Database not writable, switching to degraded mode: %s", err)
+	}
+	r.degraded = true
+}
+
+// Defer keeps the given update in memory until the database is writable
+// again. Only the last update of a given key is kept, an update replacing
+// the previous one being applied last. It returns ErrDeferred, or
+// ErrDeferredDropped if the update of a new key is dropped because the
+// buffer is full.
+func (r *Redis) Defer(key string, fn func(redis.Conn) error) error {
+	r.deferredLock.Lock()
+	defer r.deferredLock.Unlock()
+	if r.deferredKeys == nil {
+		r.deferredKeys = make(map[string]int)
+	}
+	if i, ok := r.deferredKeys[key]; ok {
+		// Leave a hole in place of the previous update
+		r.deferred[i-r.deferredBase].fn = nil
+	} else if len(r.deferredKeys) >= maxDeferredWrites {
+		if r.deferredDropped == 0 {
+			log.Warningf("Too many updates waiting for the database, dropping the updates of new keys")
+		}
+		r.deferredDropped++
+		return ErrDeferredDropped
+	}
+	if len(r.deferred) >= 2*maxDeferredWrites {
+		r.compactDeferred()
+	}
+	r.deferredKeys[key] = r.deferredBase + len(r.deferred)
+	r.deferred = append(r.deferred, deferredWrite{key: key, fn: fn})
+	return ErrDeferred
+}
+
+// compactDeferred removes the holes left by the replaced updates, the
+// positions kept in deferredKeys being counted from deferredBase
+func (r *Redis) compactDeferred() {
+	deferred := make([]deferredWrite, 0, len(r.deferredKeys))
+	for _, w := range r.deferred {
+		if w.fn != nil {
+			r.deferredKeys[w.key] = len(deferred)
+			deferred = append(deferred, w)
+		}
+	}
+	r.deferred = deferred
+	r.deferredBase = 0
+}
+
+// PendingWrites returns the number of updates waiting for the database to be
+// writable again
+func (r *Redis) PendingWrites() int {
+	r.deferredLock.Lock()
+	defer r.deferredLock.Unlock()
+	return len(r.deferredKeys)
+}
+
+// flushDeferred leaves the degraded mode once the database accepts writes
+// again and applies the updates kept in memory
+func (r *Redis) flushDeferred() {
+	conn := r.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("SET", "WRITE_PROBE", time.Now().Unix(), "EX", 60); err != nil {
+		return
+	}
+
+	r.deferredLock.Lock()
+	defer r.deferredLock.Unlock()
+	flushed := 0
+	for len(r.deferred) > 0 {
+		w := r.deferred[0]
+		if w.fn != nil {
+			if err := w.fn(conn); err != nil {
+				if IsWriteError(err) {
+					// Still not writable, try again later
+					return
+				}
+				log.Errorf("Unable to apply the update of %s: %s", w.key, err)
+			} else {
+				flushed++
+			}
+			delete(r.deferredKeys, w.key)
+		}
+		r.deferred = r.deferred[1:]
+		r.deferredBase++
+	}
+
+	r.failureState.Lock()
+	r.degraded = false
+	r.failureState.Unlock()
+	log.Noticef("Database writable again, leaving degraded mode (pending updates applied: %d, dropped: %d)", flushed, r.deferredDropped)
+	r.deferredDropped = 0
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package database
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+func TestIsWriteError(t *testing.T) {
+	writeErrors := []error{
+		redis.Error("READONLY You can't write against a read only replica."),
+		redis.Error("MISCONF Redis is configured to save RDB snapshots, but it is currently not able to persist on disk."),
+		redis.Error("OOM command not allowed when used memory > 'maxmemory'."),
+		&net.OpError{Op: "dial", Err: errors.New("connection refused")},
+	}
+	for _, err := range writeErrors {
+		if !IsWriteError(err) {
+			t.Fatalf("Expected %q to be a write error", err)
+		}
+	}
+
+	for _, err := range []error{nil, redis.ErrNil, redis.Error("WRONGTYPE Operation against a key holding the wrong kind of value")} {
+		if IsWriteError(err) {
+			t.Fatalf("Unexpected write error %q", err)
+		}
+	}
+}
+
+func TestRedis_Defer(t *testing.T) {
+	r := &Redis{}
+	var applied []string
+	update := func(key string) func(redis.Conn) error {
+		return func(redis.Conn) error {
+			applied = append(applied, key)
+			return nil
+		}
+	}
+
+	for _, key := range []string{"a", "b", "a"} {
+		if err := r.Defer(key, update(key)); err != ErrDeferred {
+			t.Fatalf("Expected ErrDeferred, got %v", err)
+		}
+	}
+
+	if r.PendingWrites() != 2 {
+		t.Fatalf("Expected 2 pending writes, got %d", r.PendingWrites())
+	}
+	for _, w := range r.deferred {
+		if w.fn != nil {
+			w.fn(nil)
+		}
+	}
+	if len(applied) != 2 || applied[0] != "b" || applied[1] != "a" {
+		t.Fatalf("Expected the last update of a key to be applied last, got %v", applied)
+	}
+
+	r.SetDegraded(errors.New("READONLY"))
+	if !r.Degraded() {
+		t.Fatalf("Expected the degraded mode")
+	}
+}
+
+func TestRedis_Defer_limit(t *testing.T) {
+	r := &Redis{}
+	noop := func(redis.Conn) error { return nil }
+
+	for i := 0; i < maxDeferredWrites; i++ {
+		if err := r.Defer(strconv.Itoa(i), noop); err != ErrDeferred {
+			t.Fatalf("Expected ErrDeferred, got %v", err)
+		}
+	}
+
+	// The updates of new keys are dropped
+	if err := r.Defer("new", noop); err != ErrDeferredDropped {
+		t.Fatalf("Expected ErrDeferredDropped, got %v", err)
+	}
+	if r.PendingWrites() != maxDeferredWrites || r.deferredDropped != 1 {
+		t.Fatalf("Expected %d pending writes and 1 dropped, got %d and %d", maxDeferredWrites, r.PendingWrites(), r.deferredDropped)
+	}
+
+	// The known keys can still be updated, the buffer being compacted
+	for n := 0; n < 3; n++ {
+		for i := 0; i < maxDeferredWrites; i++ {
+			if err := r.Defer(strconv.Itoa(i), noop); err != ErrDeferred {
+				t.Fatalf("Expected ErrDeferred, got %v", err)
+			}
+		}
+	}
+	if r.PendingWrites() != maxDeferredWrites || len(r.deferred) > 2*maxDeferredWrites {
+		t.Fatalf("Expected the buffer to be compacted, got %d updates for %d keys", len(r.deferred), r.PendingWrites())
+	}
+	for key, i := range r.deferredKeys {
+		if w := r.deferred[i-r.deferredBase]; w.key != key || w.fn == nil {
+			t.Fatalf("Unexpected update at the position of %s: %+v", key, w)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package database
+
+import (
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Background activities of the monitor that can be paused
+const (
+	PauseScanning   = "scanning"
+	PauseMonitoring = "monitoring"
+)
+
+// PauseActivities is the list of the background activities that can be paused
+var PauseActivities = []string{PauseScanning, PauseMonitoring}
+
+// SetPaused pauses or resumes the given background activity on all the
+// instances sharing the database
+func (r *Redis) SetPaused(activity string, paused bool) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	var err error
+	if paused {
+		_, err = conn.Do("HSETNX", "PAUSED", activity, time.Now().Unix())
+	} else {
+		_, err = conn.Do("HDEL", "PAUSED", activity)
+	}
+	if err == nil {
+		Publish(conn, PAUSE_UPDATE, activity)
+	}
+	return err
+}
+
+// GetPaused returns the background activities currently paused along with
+// the date they were paused
+func (r *Redis) GetPaused() (map[string]time.Time, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	values, err := redis.Int64Map(conn.Do("HGETALL", "PAUSED"))
+	if err != nil {
+		return nil, err
+	}
+	paused := make(map[string]time.Time, len(values))
+	for activity, since := range values {
+		paused[activity] = time.Unix(since, 0)
+	}
+	return paused, nil
+}
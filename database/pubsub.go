@@ -12,7 +12,7 @@ import (
 )
 
 var (
-	log = logging.MustGetLogger("main")
+	log = logging.MustGetLogger("redis")
 )
 
 type pubsubEvent string
@@ -22,6 +22,7 @@ const (
 	FILE_UPDATE        pubsubEvent = "_mirrorbits_file_update"
 	MIRROR_UPDATE      pubsubEvent = "_mirrorbits_mirror_update"
 	MIRROR_FILE_UPDATE pubsubEvent = "_mirrorbits_mirror_file_update"
+	PAUSE_UPDATE       pubsubEvent = "_mirrorbits_pause_update"
 
 	PUBSUB_RECONNECTED pubsubEvent = "_mirrorbits_pubsub_reconnected"
 )
@@ -35,6 +36,12 @@ type Pubsub struct {
 	extSubscribersLock sync.RWMutex
 	stop               chan bool
 	wg                 sync.WaitGroup
+
+	// Sequence number of the last message of the log known to be received
+	// and the one to use as the next checkpoint
+	checkpoint    int64
+	hasCheckpoint bool
+	nextSeq       int64
 }
 
 // NewPubsub returns a new instance of the publish/subscribe handler
@@ -106,14 +113,18 @@ connect:
 		psc.Subscribe(FILE_UPDATE)
 		psc.Subscribe(MIRROR_UPDATE)
 		psc.Subscribe(MIRROR_FILE_UPDATE)
+		psc.Subscribe(PAUSE_UPDATE)
 
 		if disconnected == true {
-			// This is a way to keep the cache active while disconnected
-			// from redis but still clear the cache (possibly outdated)
-			// after a successful reconnection.
 			disconnected = false
-			p.handleMessage(string(PUBSUB_RECONNECTED), nil)
+			p.resync()
+		} else {
+			p.resetCheckpoint()
 		}
+
+		done := make(chan struct{})
+		go p.pingCheckpoint(&psc, done)
+
 		for {
 			switch v := psc.Receive().(type) {
 			case redis.Message:
@@ -121,7 +132,10 @@ connect:
 				p.handleMessage(v.Channel, v.Data)
 			case redis.Subscription:
 				log.Debugf("Redis subscription on channel %s: %s (%d)", v.Channel, v.Kind, v.Count)
+			case redis.Pong:
+				p.advanceCheckpoint()
 			case error:
+				close(done)
 				select {
 				case <-p.stop:
 					return
@@ -138,6 +152,94 @@ connect:
 	}
 }
 
+// resync replays the messages missed while disconnected. This is a way to
+// keep the cache active while disconnected from redis but still invalidate
+// the (possibly outdated) entries after a successful reconnection. The whole
+// cache is cleared when the missed messages are no longer in the log.
+func (p *Pubsub) resync() {
+	conn := p.r.Get()
+	defer conn.Close()
+
+	head, err := logSequence(conn)
+	if err == nil && p.hasCheckpoint {
+		var entries []logEntry
+		var complete bool
+		entries, complete, err = readLog(conn, p.checkpoint, head)
+		if err == nil && complete {
+			log.Noticef("Pubsub reconnected, replaying %d missed message(s)", len(entries))
+			for _, e := range entries {
+				p.dispatchMessage(e.channel, []byte(e.message))
+			}
+			p.checkpoint, p.nextSeq = head, head
+			return
+		}
+	}
+	if err != nil {
+		log.Warningf("Pubsub reconnected, unable to replay the missed messages: %s", err)
+	}
+	p.handleMessage(string(PUBSUB_RECONNECTED), nil)
+	p.checkpoint, p.nextSeq = head, head
+	p.hasCheckpoint = err == nil
+}
+
+// resetCheckpoint starts tracking the log from its current end
+func (p *Pubsub) resetCheckpoint() {
+	conn := p.r.Get()
+	defer conn.Close()
+
+	head, err := logSequence(conn)
+	p.checkpoint, p.nextSeq = head, head
+	p.hasCheckpoint = err == nil
+}
+
+// advanceCheckpoint is called each time the pubsub connection is proven
+// alive. The messages logged before the previous proof have been delivered
+// by then, as they are published right after being logged.
+func (p *Pubsub) advanceCheckpoint() {
+	conn := p.r.Get()
+	defer conn.Close()
+
+	head, err := logSequence(conn)
+	if err != nil {
+		return
+	}
+	p.checkpoint, p.nextSeq = p.nextSeq, head
+}
+
+// pingCheckpoint pings the pubsub connection periodically until done is
+// closed, each reply advancing the checkpoint
+func (p *Pubsub) pingCheckpoint(psc *redis.PubSubConn, done chan struct{}) {
+	ticker := time.NewTicker(pubsubCheckpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.connlock.Lock()
+			psc.Ping("")
+			p.connlock.Unlock()
+		}
+	}
+}
+
+// dispatchMessage notifies subscribers of a replayed message, waiting for
+// them to be available as those would otherwise be lost for good
+func (p *Pubsub) dispatchMessage(channel string, data []byte) {
+	p.extSubscribersLock.RLock()
+	defer p.extSubscribersLock.RUnlock()
+
+	for _, listener := range p.extSubscribers[channel] {
+		select {
+		case listener <- string(data):
+		case <-p.stop:
+			return
+		}
+	}
+}
+
 // Notify subscribers of the new message
 func (p *Pubsub) handleMessage(channel string, data []byte) {
 	p.extSubscribersLock.RLock()
@@ -156,12 +258,23 @@ func (p *Pubsub) handleMessage(channel string, data []byte) {
 
 // Publish a message on the pubsub server
 func Publish(r redis.Conn, event pubsubEvent, message string) error {
+	// The message is logged first so that an instance subscribing
+	// concurrently either receives it or finds it in the log
+	if err := logEvent(r, event, message); err != nil {
+		log.Warningf("Unable to log the message for replay: %s", err)
+	}
 	_, err := r.Do("PUBLISH", string(event), message)
+	if err != nil {
+		log.Warningf("Unable to publish on %s: %s", event, err)
+	}
 	return err
 }
 
 // SendPublish add the message to a transaction
 func SendPublish(r redis.Conn, event pubsubEvent, message string) error {
+	if err := sendLogEvent(r, event, message); err != nil {
+		log.Warningf("Unable to log the message for replay: %s", err)
+	}
 	err := r.Send("PUBLISH", string(event), message)
 	return err
 }
@@ -19,7 +19,6 @@ import (
 
 const (
 	redisConnectionTimeout = 200 * time.Millisecond
-	redisReadWriteTimeout  = 300 * time.Second
 )
 
 var (
@@ -39,7 +38,13 @@ type Redis struct {
 	pool            redisPool
 	Pubsub          *Pubsub
 	failure         bool
+	degraded        bool
 	failureState    sync.RWMutex
+	deferred        []deferredWrite
+	deferredKeys    map[string]int
+	deferredBase    int
+	deferredDropped int
+	deferredLock    sync.Mutex
 	knownMaster     string
 	knownMasterLock sync.Mutex
 	stop            chan bool
@@ -162,6 +167,18 @@ func (r *Redis) checkVersion(conn redis.Conn) error {
 	return err
 }
 
+// ServerVersion returns the version of the redis server
+func (r *Redis) ServerVersion() (string, error) {
+	conn := r.UnblockedGet()
+	defer conn.Close()
+
+	info, err := parseInfo(conn.Do("INFO", "server"))
+	if err != nil {
+		return "", err
+	}
+	return info["redis_version"], nil
+}
+
 // Connect initiates a new connection to the redis server
 func (r *Redis) Connect() (redis.Conn, error) {
 	sentinels := GetConfig().RedisSentinels
@@ -285,10 +302,11 @@ single:
 }
 
 func (r *Redis) connectTo(address string) (redis.Conn, error) {
+	timeout := time.Duration(GetConfig().RedisTimeout) * time.Second
 	return redis.Dial("tcp", address,
 		redis.DialConnectTimeout(redisConnectionTimeout),
-		redis.DialReadTimeout(redisReadWriteTimeout),
-		redis.DialWriteTimeout(redisReadWriteTimeout))
+		redis.DialReadTimeout(timeout),
+		redis.DialWriteTimeout(timeout))
 }
 
 func (r *Redis) askRole(c redis.Conn) (string, error) {
@@ -352,6 +370,9 @@ func (r *Redis) connRecover() {
 		case <-r.stop:
 			return
 		case <-ticker.C:
+			if r.Degraded() && !r.Failure() {
+				r.flushDeferred()
+			}
 			if r.Failure() {
 				if conn := r.Get(); conn != nil {
 					// A successful Get() request will automatically unlock
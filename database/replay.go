@@ -0,0 +1,98 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package database
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// The invalidation messages are also appended to a sequence-numbered log
+// kept in the database, letting an instance that lost its subscription
+// replay the messages it missed instead of dropping all its caches.
+const (
+	pubsubSeqKey = "PUBSUB_SEQ"
+	pubsubLogKey = "PUBSUB_LOG"
+
+	// Number of messages kept in the log
+	pubsubLogSize = 10000
+
+	// Interval between two proofs that the pubsub connection is alive
+	pubsubCheckpointInterval = 10 * time.Second
+)
+
+var pubsubLogScript = redis.NewScript(2, `
+local seq = redis.call('INCR', KEYS[1])
+redis.call('ZADD', KEYS[2], seq, seq .. ' ' .. ARGV[1] .. ' ' .. ARGV[2])
+redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -tonumber(ARGV[3]) - 1)
+return seq
+`)
+
+// replayable returns true if the event has to be kept in the log, the
+// cluster announcements being repeated periodically anyway
+func replayable(event pubsubEvent) bool {
+	return event != CLUSTER
+}
+
+// logEvent appends the message to the log
+func logEvent(r redis.Conn, event pubsubEvent, message string) error {
+	if !replayable(event) {
+		return nil
+	}
+	_, err := pubsubLogScript.Do(r, pubsubSeqKey, pubsubLogKey, string(event), message, pubsubLogSize)
+	return err
+}
+
+// sendLogEvent adds the append of the message to the log to a transaction
+func sendLogEvent(r redis.Conn, event pubsubEvent, message string) error {
+	if !replayable(event) {
+		return nil
+	}
+	return pubsubLogScript.Send(r, pubsubSeqKey, pubsubLogKey, string(event), message, pubsubLogSize)
+}
+
+// logSequence returns the sequence number of the last message of the log
+func logSequence(r redis.Conn) (int64, error) {
+	seq, err := redis.Int64(r.Do("GET", pubsubSeqKey))
+	if err == redis.ErrNil {
+		return 0, nil
+	}
+	return seq, err
+}
+
+type logEntry struct {
+	channel string
+	message string
+}
+
+// readLog returns the messages logged after the sequence number from up to
+// the sequence number to. The returned boolean is false when some of them
+// are no longer in the log.
+func readLog(r redis.Conn, from, to int64) ([]logEntry, bool, error) {
+	if to < from {
+		// The log has been reset
+		return nil, false, nil
+	}
+	if to == from {
+		return nil, true, nil
+	}
+	members, err := redis.Strings(r.Do("ZRANGEBYSCORE", pubsubLogKey, from+1, to))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(members)) != to-from {
+		return nil, false, nil
+	}
+	entries := make([]logEntry, 0, len(members))
+	for _, m := range members {
+		s := strings.SplitN(m, " ", 3)
+		if len(s) != 3 {
+			return nil, false, nil
+		}
+		entries = append(entries, logEntry{channel: s[1], message: s[2]})
+	}
+	return entries, true, nil
+}
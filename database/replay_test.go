@@ -0,0 +1,53 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafaeljusto/redigomock"
+)
+
+func TestReadLog(t *testing.T) {
+	conn := redigomock.NewConn()
+
+	conn.Command("ZRANGEBYSCORE", pubsubLogKey, int64(4), int64(6)).Expect([]interface{}{
+		[]byte("4 _mirrorbits_mirror_update 1"),
+		[]byte("5 _mirrorbits_file_update /file with spaces.tgz"),
+		[]byte("6 _mirrorbits_mirror_file_update 2 /file.tgz"),
+	})
+
+	entries, complete, err := readLog(conn, 3, 6)
+	if err != nil || !complete {
+		t.Fatalf("Expected a complete replay, got %v (%v)", complete, err)
+	}
+	expected := []logEntry{
+		{channel: string(MIRROR_UPDATE), message: "1"},
+		{channel: string(FILE_UPDATE), message: "/file with spaces.tgz"},
+		{channel: string(MIRROR_FILE_UPDATE), message: "2 /file.tgz"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, entries)
+	}
+
+	// The oldest message is no longer in the log
+	conn.Command("ZRANGEBYSCORE", pubsubLogKey, int64(2), int64(6)).Expect([]interface{}{
+		[]byte("5 _mirrorbits_file_update /file.tgz"),
+		[]byte("6 _mirrorbits_mirror_update 1"),
+	})
+	if _, complete, _ = readLog(conn, 1, 6); complete {
+		t.Fatalf("The replay is not supposed to be complete")
+	}
+
+	// Nothing missed
+	if entries, complete, _ = readLog(conn, 6, 6); !complete || len(entries) > 0 {
+		t.Fatalf("Expected an empty replay")
+	}
+
+	// The log has been reset (i.e. database flushed)
+	if _, complete, _ = readLog(conn, 6, 2); complete {
+		t.Fatalf("The replay is not supposed to be complete")
+	}
+}
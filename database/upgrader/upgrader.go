@@ -6,6 +6,8 @@ package upgrader
 import (
 	"github.com/etix/mirrorbits/database/interfaces"
 	v1 "github.com/etix/mirrorbits/database/v1"
+	v2 "github.com/etix/mirrorbits/database/v2"
+	v3 "github.com/etix/mirrorbits/database/v3"
 )
 
 // Upgrader is an interface to implement a database upgrade strategy
@@ -18,6 +20,10 @@ func GetUpgrader(redis interfaces.Redis, version int) Upgrader {
 	switch version {
 	case 1:
 		return v1.NewUpgraderV1(redis)
+	case 2:
+		return v2.NewUpgraderV2(redis)
+	case 3:
+		return v3.NewUpgraderV3(redis)
 	}
 	return nil
 }
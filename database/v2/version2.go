@@ -0,0 +1,98 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/etix/mirrorbits/core"
+	"github.com/etix/mirrorbits/database/interfaces"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+)
+
+// NewUpgraderV2 upgrades the database from version 1 to 2
+func NewUpgraderV2(redis interfaces.Redis) *Version2 {
+	return &Version2{
+		Redis: redis,
+	}
+}
+
+type Version2 struct {
+	Redis interfaces.Redis
+}
+
+// Upgrade converts the country codes of the mirrors, stored as space
+// separated strings, to json arrays
+func (v *Version2) Upgrade() error {
+	conn := v.Redis.UnblockedGet()
+	defer conn.Close()
+
+	ids, err := redis.Ints(conn.Do("HKEYS", "MIRRORS"))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	updates := make(map[string][]interface{})
+
+	for _, id := range ids {
+		key := fmt.Sprintf("MIRROR_%d", id)
+
+		values, err := redis.Strings(conn.Do("HMGET", key, "countryCodes", "excludedCountryCodes"))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		var args []interface{}
+		for i, field := range []string{"countryCodes", "excludedCountryCodes"} {
+			list, err := convertCountryCodes(values[i])
+			if err != nil {
+				return errors.Wrapf(err, "mirror %d", id)
+			}
+			args = append(args, field, list)
+		}
+		updates[key] = args
+	}
+
+	// Start a transaction to atomically and irrevocably set the new version
+	conn.Send("MULTI")
+
+	for key, args := range updates {
+		conn.Send("HMSET", append([]interface{}{key}, args...)...)
+	}
+
+	conn.Send("SET", core.DBVersionKey, 2)
+
+	// Finalize the transaction
+	_, err = conn.Do("EXEC")
+	return err
+}
+
+// convertCountryCodes converts a space separated list of country codes to a
+// json array without duplicates. Unknown codes are kept as is.
+func convertCountryCodes(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") {
+		// Already converted
+		return value, nil
+	}
+
+	list := []string{}
+	seen := make(map[string]bool)
+	for _, c := range strings.Fields(strings.Replace(value, ",", " ", -1)) {
+		c = strings.ToUpper(c)
+		if !seen[c] {
+			seen[c] = true
+			list = append(list, c)
+		}
+	}
+
+	b, err := json.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package v3
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etix/mirrorbits/core"
+	"github.com/etix/mirrorbits/database/interfaces"
+	"github.com/etix/mirrorbits/filesystem"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+)
+
+// NewUpgraderV3 upgrades the database from version 2 to 3
+func NewUpgraderV3(redis interfaces.Redis) *Version3 {
+	return &Version3{
+		Redis: redis,
+	}
+}
+
+type Version3 struct {
+	Redis interfaces.Redis
+}
+
+// Upgrade packs the FILEINFO_<id>_<path> hashes, one per file found on a
+// mirror, into a single FILEINFOS_<id> hash per mirror. The new hashes are
+// filled before switching to the new version, the previous keys being
+// removed afterwards.
+func (v *Version3) Upgrade() error {
+	conn := v.Redis.UnblockedGet()
+	defer conn.Close()
+
+	// Erase previous work keys (previous failed upgrade?)
+	err := v.forEachKey(conn, "FILEINFOS_*", func(keys []string) error {
+		_, err := conn.Do("DEL", toArgs(keys)...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	err = v.forEachKey(conn, "FILEINFO_*", func(keys []string) error {
+		for _, key := range keys {
+			conn.Send("HMGET", key, "size", "modTime")
+		}
+		if err := conn.Flush(); err != nil {
+			return errors.WithStack(err)
+		}
+		packed := make([][]interface{}, len(keys))
+		for i, key := range keys {
+			values, err := redis.Strings(conn.Receive())
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			id, path, ok := parseKey(key)
+			if !ok {
+				continue
+			}
+			f := filesystem.FileInfo{}
+			f.Size, _ = strconv.ParseInt(values[0], 10, 64)
+			f.ModTime, _ = time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", values[1])
+			packed[i] = []interface{}{fmt.Sprintf("FILEINFOS_%d", id), path, f.Pack()}
+		}
+		for _, args := range packed {
+			if args != nil {
+				conn.Send("HSET", args...)
+				count++
+			}
+		}
+		_, err := conn.Do("")
+		return errors.WithStack(err)
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err = conn.Do("SET", core.DBVersionKey, 3); err != nil {
+		return err
+	}
+
+	// <-- At this point the previous keys are no longer used.
+
+	err = v.forEachKey(conn, "FILEINFO_*", func(keys []string) error {
+		_, err := conn.Do("DEL", toArgs(keys)...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// forEachKey calls fn for each batch of keys matching the given pattern
+func (v *Version3) forEachKey(conn redis.Conn, pattern string, fn func(keys []string) error) error {
+	cursor := "0"
+	for {
+		values, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", pattern, "COUNT", 1000))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		var keys []string
+		if _, err = redis.Scan(values, &cursor, &keys); err != nil {
+			return errors.WithStack(err)
+		}
+		if len(keys) > 0 {
+			if err = fn(keys); err != nil {
+				return err
+			}
+		}
+		if cursor == "0" {
+			return nil
+		}
+	}
+}
+
+// parseKey returns the mirror id and the path of a FILEINFO_<id>_<path> key
+func parseKey(key string) (id int, path string, ok bool) {
+	s := strings.SplitN(strings.TrimPrefix(key, "FILEINFO_"), "_", 2)
+	if len(s) != 2 {
+		return 0, "", false
+	}
+	id, err := strconv.Atoi(s[0])
+	if err != nil {
+		return 0, "", false
+	}
+	return id, s[1], true
+}
+
+func toArgs(keys []string) []interface{} {
+	args := make([]interface{}, len(keys))
+	for i, k := range keys {
+		args[i] = k
+	}
+	return args
+}
@@ -4,9 +4,17 @@
 package filesystem
 
 import (
+	"encoding/binary"
+	"errors"
 	"time"
 )
 
+// ErrInvalidPackedInfo is returned when unpacking a corrupted file information
+var ErrInvalidPackedInfo = errors.New("invalid packed file information")
+
+// packVersion is the first byte of the packed file information
+const packVersion = 1
+
 // FileInfo is a struct embedding details about a file served by
 // the redirector.
 type FileInfo struct {
@@ -24,3 +32,56 @@ func NewFileInfo(path string) FileInfo {
 		Path: path,
 	}
 }
+
+// Pack returns the size and modification time of the file as a compact
+// binary value, used to store the files found on the mirrors as the fields
+// of a single hash per mirror. The hashes are left out, the scanners never
+// retrieving them.
+func (f FileInfo) Pack() []byte {
+	b := make([]byte, 1, 1+3*binary.MaxVarintLen64)
+	b[0] = packVersion
+	b = appendVarint(b, f.Size)
+	if !f.ModTime.IsZero() {
+		b = appendVarint(b, f.ModTime.Unix())
+		b = appendVarint(b, int64(f.ModTime.Nanosecond()))
+	}
+	return b
+}
+
+// UnpackFileInfo returns the file information packed with Pack
+func UnpackFileInfo(path string, b []byte) (FileInfo, error) {
+	f := NewFileInfo(path)
+	if len(b) == 0 {
+		return f, nil
+	}
+	if b[0] != packVersion {
+		return f, ErrInvalidPackedInfo
+	}
+	b = b[1:]
+
+	var values [3]int64
+	var i int
+	for i = 0; i < len(values) && len(b) > 0; i++ {
+		v, n := binary.Varint(b)
+		if n <= 0 {
+			return f, ErrInvalidPackedInfo
+		}
+		values[i] = v
+		b = b[n:]
+	}
+	if i == 2 {
+		return f, ErrInvalidPackedInfo
+	}
+
+	f.Size = values[0]
+	if i == 3 {
+		f.ModTime = time.Unix(values[1], values[2]).UTC()
+	}
+	return f, nil
+}
+
+func appendVarint(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
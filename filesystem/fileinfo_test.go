@@ -0,0 +1,42 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package filesystem
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFileInfo_Pack(t *testing.T) {
+	modTime := time.Date(2019, 3, 14, 15, 9, 26, 535897932, time.FixedZone("CET", 3600))
+
+	for _, f := range []FileInfo{
+		{Path: "/a.tgz", Size: 44000, ModTime: modTime},
+		{Path: "/b.tgz", Size: 1 << 40},
+		{Path: "/c.tgz"},
+	} {
+		packed := f.Pack()
+		unpacked, err := UnpackFileInfo(f.Path, packed)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if unpacked.Path != f.Path || unpacked.Size != f.Size || !unpacked.ModTime.Equal(f.ModTime) {
+			t.Fatalf("Expected %+v, got %+v", f, unpacked)
+		}
+	}
+
+	if len((FileInfo{Size: 44000, ModTime: modTime}).Pack()) > 16 {
+		t.Fatalf("The packed file information is supposed to be compact")
+	}
+
+	if f, err := UnpackFileInfo("/d.tgz", nil); err != nil || f.Size != 0 || !f.ModTime.IsZero() {
+		t.Fatalf("Expected an empty file information, got %+v (%v)", f, err)
+	}
+
+	for _, b := range [][]byte{{2, 0}, {packVersion, 0x80}, {packVersion, 2, 2}} {
+		if _, err := UnpackFileInfo("/e.tgz", b); err != ErrInvalidPackedInfo {
+			t.Fatalf("Expected an error for %v", b)
+		}
+	}
+}
@@ -5,8 +5,11 @@ package filesystem
 
 import (
 	"errors"
+	"os"
 	"path/filepath"
 	"strings"
+
+	. "github.com/etix/mirrorbits/config"
 )
 
 var (
@@ -14,8 +17,9 @@ var (
 	ErrOutsideRepo = errors.New("target file outside repository")
 )
 
-// EvaluateFilePath sanitize and validate the file against the local repository
-func EvaluateFilePath(repository, urlpath string) (string, error) {
+// EvaluateFilePath sanitize and validate the file against the local repository.
+// Symlinks are resolved according to the given policy (see SymlinkPolicy).
+func EvaluateFilePath(repository, urlpath, symlinkPolicy string) (string, error) {
 	fpath := repository + urlpath
 
 	// Get the absolute file path
@@ -35,6 +39,9 @@ func EvaluateFilePath(repository, urlpath string) (string, error) {
 		return "", err
 	}
 	if targetPath != fpath {
+		if symlinkPolicy == SymlinkIgnore {
+			return "", os.ErrNotExist
+		}
 		targetPath, err = filepath.Abs(targetPath)
 		if err != nil {
 			return "", err
@@ -42,6 +49,10 @@ func EvaluateFilePath(repository, urlpath string) (string, error) {
 		if !IsInRepository(repository, targetPath) {
 			return "", ErrOutsideRepo
 		}
+		if symlinkPolicy == SymlinkFollow {
+			// The symlink is indexed as a regular file
+			return fpath[len(repository):], nil
+		}
 		return targetPath[len(repository):], nil
 	}
 	return fpath[len(repository):], nil
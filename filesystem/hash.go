@@ -24,8 +24,11 @@ func HashFile(path string) (hashes FileInfo, err error) {
 	}
 	defer f.Close()
 
-	reader := bufio.NewReader(f)
+	return HashReader(bufio.NewReader(f))
+}
 
+// HashReader generates a human readable hash of the given content
+func HashReader(reader io.Reader) (hashes FileInfo, err error) {
 	var writers []io.Writer
 
 	if GetConfig().Hashes.SHA1 {
@@ -0,0 +1,42 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package filesystem
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Manifest is the mirror sync manifest, the list of the files of the
+// repository published for the mirrors (see SyncManifest)
+type Manifest struct {
+	// Generated is the time of the last scan of the repository
+	Generated time.Time
+	Files     []FileInfo
+}
+
+// ReadManifest decodes a manifest and checks its paths
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	for _, f := range m.Files {
+		if !strings.HasPrefix(f.Path, "/") || strings.Contains(f.Path, "/../") || strings.HasSuffix(f.Path, "/..") {
+			return nil, errors.New("invalid path in the manifest: " + f.Path)
+		}
+	}
+	return &m, nil
+}
+
+// Matches returns false if a hash known on both sides differs
+func (f FileInfo) Matches(o FileInfo) bool {
+	differ := func(a, b string) bool {
+		return a != "" && b != "" && !strings.EqualFold(a, b)
+	}
+	return !differ(f.Sha256, o.Sha256) && !differ(f.Sha1, o.Sha1) && !differ(f.Md5, o.Md5)
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package filesystem
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadManifest(t *testing.T) {
+	m, err := ReadManifest(strings.NewReader(`{"Generated":"2019-03-14T15:09:26Z","Files":[{"Path":"/a.tgz","Size":44000,"ModTime":"2019-03-14T15:09:26.535897932Z","Sha256":"0123"}]}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if m.Generated.IsZero() || len(m.Files) != 1 || m.Files[0].Path != "/a.tgz" || m.Files[0].Size != 44000 {
+		t.Fatalf("Unexpected manifest %+v", m)
+	}
+
+	for _, doc := range []string{`{"Files":[{"Path":"a.tgz"}]}`, `{"Files":[{"Path":"/a/../../b"}]}`, `[`} {
+		if _, err := ReadManifest(strings.NewReader(doc)); err == nil {
+			t.Fatalf("Expected an error for %s", doc)
+		}
+	}
+}
+
+func TestFileInfo_Matches(t *testing.T) {
+	local := FileInfo{Sha256: "abcd", Md5: "01"}
+	if !local.Matches(FileInfo{Sha256: "ABCD"}) {
+		t.Fatalf("The hashes are supposed to match")
+	}
+	if !local.Matches(FileInfo{Sha1: "ef"}) {
+		t.Fatalf("The hashes unknown on one side are supposed to be ignored")
+	}
+	if local.Matches(FileInfo{Sha256: "abcd", Md5: "02"}) {
+		t.Fatalf("The hashes are not supposed to match")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+// Package hooks allows site-specific policies to adjust the mirror
+// selection without forking mirrorbits.
+//
+// A hook is either registered by a program embedding mirrorbits with
+// Register, or loaded from a Go plugin (see SelectionHooks in the
+// configuration) exporting a function named SelectionHook:
+//
+//	package main
+//
+//	import "github.com/etix/mirrorbits/hooks"
+//
+//	func SelectionHook(req *hooks.Request, candidates []hooks.Candidate) {
+//		for i := range candidates {
+//			if req.CountryCode == "FR" && candidates[i].ASNum == 64496 {
+//				candidates[i].Score += 50
+//			}
+//		}
+//	}
+//
+// The plugin must be built with 'go build -buildmode=plugin' against the
+// same version of mirrorbits.
+package hooks
+
+import (
+	"fmt"
+	"plugin"
+	"sync"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/op/go-logging"
+)
+
+const (
+	// SymbolName is the name of the function looked up in the plugins
+	SymbolName = "SelectionHook"
+)
+
+var (
+	log = logging.MustGetLogger("main")
+
+	hooksLock  sync.RWMutex
+	registered []Hook
+	loaded     []Hook
+)
+
+// Request describes the request being served
+type Request struct {
+	Path          string
+	IP            string
+	CountryCode   string
+	ContinentCode string
+	ASNum         uint
+	ASName        string
+	Latitude      float32
+	Longitude     float32
+}
+
+// Candidate is a mirror eligible for the request. A hook can change its
+// Score (a percentage added to the weight of the mirror, as with 'edit')
+// for this request only, or veto it.
+type Candidate struct {
+	ID            int
+	Name          string
+	HttpURL       string
+	CountryCodes  []string
+	ContinentCode string
+	ASNum         uint
+	Distance      float32
+	CDN           bool
+	Score         int
+	Veto          bool
+	VetoReason    string
+}
+
+// Hook adjusts the candidates of a request
+type Hook func(req *Request, candidates []Candidate)
+
+// Register adds a hook called for each request, before the hooks loaded
+// from the plugins
+func Register(h Hook) {
+	hooksLock.Lock()
+	defer hooksLock.Unlock()
+	registered = append(registered, h)
+}
+
+// Reload loads the plugins given by SelectionHooks. A plugin cannot be
+// unloaded but a plugin removed from the configuration is no longer called.
+func Reload() {
+	var list []Hook
+	for _, path := range GetConfig().SelectionHooks {
+		h, err := open(path)
+		if err != nil {
+			log.Errorf("Unable to load the selection hook %s: %s", path, err)
+			continue
+		}
+		list = append(list, h)
+	}
+
+	hooksLock.Lock()
+	defer hooksLock.Unlock()
+	loaded = list
+}
+
+func open(path string) (Hook, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup(SymbolName)
+	if err != nil {
+		return nil, err
+	}
+	switch f := sym.(type) {
+	case func(*Request, []Candidate):
+		return f, nil
+	case *Hook:
+		return *f, nil
+	}
+	return nil, fmt.Errorf("%s has the wrong type %T", SymbolName, sym)
+}
+
+// Enabled returns true if at least one hook is registered or loaded
+func Enabled() bool {
+	hooksLock.RLock()
+	defer hooksLock.RUnlock()
+	return len(registered)+len(loaded) > 0
+}
+
+// Run calls the hooks in order. A hook panicking is logged and the
+// changes it made are kept.
+func Run(req *Request, candidates []Candidate) {
+	hooksLock.RLock()
+	list := append(append([]Hook(nil), registered...), loaded...)
+	hooksLock.RUnlock()
+
+	for _, h := range list {
+		run(h, req, candidates)
+	}
+}
+
+func run(h Hook, req *Request, candidates []Candidate) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("Selection hook panicked: %v", r)
+		}
+	}()
+	h(req, candidates)
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package hooks
+
+import (
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	defer func() { registered = nil }()
+
+	Register(func(req *Request, candidates []Candidate) {
+		for i := range candidates {
+			if candidates[i].CountryCodes[0] == req.CountryCode {
+				candidates[i].Score += 50
+			}
+		}
+	})
+	Register(func(req *Request, candidates []Candidate) {
+		panic("broken hook")
+	})
+	Register(func(req *Request, candidates []Candidate) {
+		candidates[1].Veto = true
+	})
+
+	if !Enabled() {
+		t.Fatalf("Hooks should be enabled")
+	}
+
+	candidates := []Candidate{
+		{ID: 1, CountryCodes: []string{"FR"}, Score: 10},
+		{ID: 2, CountryCodes: []string{"DE"}},
+	}
+	Run(&Request{CountryCode: "FR"}, candidates)
+
+	if candidates[0].Score != 60 {
+		t.Fatalf("Score should be 60, got %d", candidates[0].Score)
+	}
+	if candidates[0].Veto || !candidates[1].Veto {
+		t.Fatalf("Only the second candidate should be vetoed")
+	}
+}
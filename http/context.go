@@ -4,6 +4,7 @@
 package http
 
 import (
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -21,6 +22,11 @@ const (
 	FILESTATS
 	MIRRORSTATS
 	CHECKSUM
+	PROPAGATION
+	DIRECTORY
+	REGISTER
+	VERIFYCONTACT
+	MIRRORAPI
 
 	UNDEFINED SecureOption = iota
 	WITHTLS
@@ -38,13 +44,18 @@ type Context struct {
 	isMirrorStats bool
 	isFileStats   bool
 	isChecksum    bool
+	isPropagation bool
+	isDirectory   bool
+	isRegister    bool
 	isPretty      bool
 	secureOption  SecureOption
+	remoteIP      net.IP
+	requestID     string
 }
 
 // NewContext returns a new instance of Context
 func NewContext(w http.ResponseWriter, r *http.Request, t Templates) *Context {
-	c := &Context{r: r, w: w, t: t, v: r.URL.Query()}
+	c := &Context{r: r, w: w, t: t, v: r.URL.Query(), requestID: requestID(r)}
 
 	if c.paramBool("mirrorlist") {
 		c.typ = MIRRORLIST
@@ -58,6 +69,19 @@ func NewContext(w http.ResponseWriter, r *http.Request, t Templates) *Context {
 	} else if c.paramBool("md5") || c.paramBool("sha1") || c.paramBool("sha256") {
 		c.typ = CHECKSUM
 		c.isChecksum = true
+	} else if c.paramBool("propagation") {
+		c.typ = PROPAGATION
+		c.isPropagation = true
+	} else if c.paramBool("mirrorapi") {
+		c.typ = MIRRORAPI
+	} else if c.paramBool("verifycontact") {
+		c.typ = VERIFYCONTACT
+	} else if c.paramBool("register") {
+		c.typ = REGISTER
+		c.isRegister = true
+	} else if strings.HasSuffix(r.URL.Path, "/") {
+		c.typ = DIRECTORY
+		c.isDirectory = true
 	} else {
 		c.typ = STANDARD
 	}
@@ -94,6 +118,11 @@ func (c *Context) ResponseWriter() http.ResponseWriter {
 	return c.w
 }
 
+// RequestID returns the identifier of the current request
+func (c *Context) RequestID() string {
+	return c.requestID
+}
+
 // Templates returns the instance of precompiled templates
 func (c *Context) Templates() Templates {
 	return c.t
@@ -124,6 +153,21 @@ func (c *Context) IsChecksum() bool {
 	return c.isChecksum
 }
 
+// IsPropagation returns true if the propagation report has been requested
+func (c *Context) IsPropagation() bool {
+	return c.isPropagation
+}
+
+// IsDirectory returns true if a directory has been requested
+func (c *Context) IsDirectory() bool {
+	return c.isDirectory
+}
+
+// IsRegister returns true if a mirror registration has been submitted
+func (c *Context) IsRegister() bool {
+	return c.isRegister
+}
+
 // IsPretty returns true if the pretty json has been requested
 func (c *Context) IsPretty() bool {
 	return c.isPretty
@@ -139,6 +183,21 @@ func (c *Context) SecureOption() SecureOption {
 	return c.secureOption
 }
 
+// SetRemoteIP sets the address of the client
+func (c *Context) SetRemoteIP(ip string) {
+	c.remoteIP = net.ParseIP(ip)
+}
+
+// IsIPv6 returns true if the client is connected over IPv6
+func (c *Context) IsIPv6() bool {
+	return c.remoteIP != nil && c.remoteIP.To4() == nil
+}
+
+// IsIPv4 returns true if the client is connected over IPv4
+func (c *Context) IsIPv4() bool {
+	return c.remoteIP != nil && c.remoteIP.To4() != nil
+}
+
 func (c *Context) paramBool(key string) bool {
 	_, ok := c.v[key]
 	return ok
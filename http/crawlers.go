@@ -0,0 +1,33 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"sync"
+	"time"
+)
+
+// crawlerLimiter counts the requests of each crawler policy within a fixed
+// window of one minute
+type crawlerLimiter struct {
+	sync.Mutex
+	window   time.Time
+	counters map[string]int
+}
+
+// allow returns true if the crawlers matching the given key didn't make more
+// than limit requests during the current minute
+func (l *crawlerLimiter) allow(key string, limit int, now time.Time) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	window := now.Truncate(time.Minute)
+	if !window.Equal(l.window) || l.counters == nil {
+		l.window = window
+		l.counters = make(map[string]int)
+	}
+
+	l.counters[key]++
+	return l.counters[key] <= limit
+}
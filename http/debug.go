@@ -0,0 +1,120 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/core"
+)
+
+var (
+	// ErrDebugPasswordRequired is returned when the debug listener would be
+	// reachable from the network without a password
+	ErrDebugPasswordRequired = errors.New("DebugPassword is required unless DebugListenAddress is a loopback address")
+
+	// counters are the statistics of the redirector exported on /debug/vars
+	counters = expvar.NewMap("mirrorbits")
+)
+
+func init() {
+	expvar.Publish("goroutines", expvar.Func(func() interface{} {
+		return runtime.NumGoroutine()
+	}))
+	expvar.Publish("workers", expvar.Func(func() interface{} {
+		return core.WorkerPools()
+	}))
+}
+
+// StartDebugServer starts the listener exposing the profiling endpoints,
+// the expvar counters and the goroutine dumps on DebugListenAddress. It
+// refuses to start without a DebugPassword unless the listener is only
+// reachable from the local host.
+func StartDebugServer() error {
+	address := GetConfig().DebugListenAddress
+	if GetConfig().DebugPassword == "" && !isLoopbackAddress(address) {
+		return ErrDebugPasswordRequired
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/goroutines", goroutinesHandler)
+
+	server := &http.Server{
+		Handler:        debugAuth(mux),
+		ReadTimeout:    10 * time.Second,
+		MaxHeaderBytes: 1 << 20,
+	}
+
+	log.Infof("Debug service listening on %s", GetConfig().DebugListenAddress)
+
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Errorf("Debug service stopped: %s", err)
+		}
+	}()
+	return nil
+}
+
+// isLoopbackAddress returns true if the given listen address only binds the
+// loopback interface
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// debugAuth requires the DebugPassword (with any user name) using the
+// basic authentication if a password is configured
+func debugAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if password := GetConfig().DebugPassword; password != "" {
+			_, given, ok := r.BasicAuth()
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(password)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="mirrorbits"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// goroutinesHandler dumps the stack of all the goroutines in the response
+// and in the logs
+func goroutinesHandler(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	rpprof.Lookup("goroutine").WriteTo(&buf, 2)
+
+	log.Noticef("Goroutine dump requested by %s:\n%s", r.RemoteAddr, buf.String())
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"testing"
+
+	. "github.com/etix/mirrorbits/config"
+)
+
+func TestIsLoopbackAddress(t *testing.T) {
+	tests := map[string]bool{
+		"localhost:6060": true,
+		"127.0.0.1:6060": true,
+		"[::1]:6060":     true,
+		":6060":          false,
+		"0.0.0.0:6060":   false,
+		"[::]:6060":      false,
+		"10.0.0.1:6060":  false,
+		"example.org:80": false,
+		"localhost":      false,
+	}
+	for address, expected := range tests {
+		if isLoopbackAddress(address) != expected {
+			t.Errorf("isLoopbackAddress(%q) is supposed to be %t", address, expected)
+		}
+	}
+}
+
+func TestStartDebugServer_password(t *testing.T) {
+	c := &Configuration{}
+	c.DebugListenAddress = "0.0.0.0:0"
+	SetConfiguration(c)
+
+	if err := StartDebugServer(); err != ErrDebugPasswordRequired {
+		t.Fatalf("Expected ErrDebugPasswordRequired, got %v", err)
+	}
+}
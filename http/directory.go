@@ -0,0 +1,140 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+)
+
+// DirectoryEntry is a file or a sub-directory of a directory listing
+type DirectoryEntry struct {
+	Name    string
+	IsDir   bool
+	Size    int64     `json:",omitempty"`
+	ModTime time.Time `json:",omitempty"`
+}
+
+// DirectoryPage is the data passed to the directory listing template
+type DirectoryPage struct {
+	Path        string
+	Parent      string
+	Entries     []DirectoryEntry
+	LocalJSPath string
+}
+
+func (h *HTTP) directoryHandler(w http.ResponseWriter, r *http.Request, ctx *Context) {
+	dir := path.Clean(r.URL.Path)
+	if dir != "/" {
+		dir += "/"
+	}
+
+	content, found, err := h.cache.GetDirectory(dir)
+	if err != nil {
+		http.Error(w, "Cannot list the directory", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		if h.tolerantLookupRedirect(w, r) {
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	page := DirectoryPage{
+		Path:        dir,
+		Entries:     make([]DirectoryEntry, 0, len(content.Dirs)+len(content.Files)),
+		LocalJSPath: GetConfig().LocalJSPath,
+	}
+	if dir != "/" {
+		page.Parent = path.Dir(path.Dir(dir)) + "/"
+		if page.Parent == "//" {
+			page.Parent = "/"
+		}
+	}
+
+	for _, d := range content.Dirs {
+		// Hide the content under embargo
+		if GetConfig().EmbargoStatus(dir+d+"/") != 0 {
+			continue
+		}
+		page.Entries = append(page.Entries, DirectoryEntry{
+			Name:  d,
+			IsDir: true,
+		})
+	}
+	for _, n := range content.Files {
+		if GetConfig().EmbargoStatus(dir+n) != 0 {
+			continue
+		}
+		fileInfo, err := h.cache.GetFileInfo(dir + n)
+		if err != nil {
+			http.Error(w, "Cannot list the directory", http.StatusInternalServerError)
+			return
+		}
+		page.Entries = append(page.Entries, DirectoryEntry{
+			Name:    n,
+			Size:    fileInfo.Size,
+			ModTime: fileInfo.ModTime,
+		})
+	}
+
+	toolFormat := GetConfig().ListingToolFormat(r.UserAgent())
+	asJSON := GetConfig().OutputMode == "json" || toolFormat == "json" ||
+		(GetConfig().OutputMode == "auto" && strings.Contains(r.Header.Get("Accept"), "application/json"))
+
+	if toolFormat == "text" && !asJSON {
+		// One entry per line for the command-line tools
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, e := range page.Entries {
+			if e.IsDir {
+				fmt.Fprintf(w, "%s/\n", e.Name)
+			} else {
+				fmt.Fprintf(w, "%s\t%d\t%s\n", e.Name, e.Size, e.ModTime.UTC().Format(time.RFC3339))
+			}
+		}
+		return
+	}
+
+	if asJSON {
+		var output []byte
+		if ctx.IsPretty() {
+			output, err = json.MarshalIndent(page.Entries, "", "    ")
+		} else {
+			output, err = json.Marshal(page.Entries)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(output)
+		return
+	}
+
+	if ctx.Templates().directory == nil {
+		http.Error(w, ErrTemplatesNotFound.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Render the page into a buffer to be able to report errors
+	var buf bytes.Buffer
+	err = ctx.Templates().directory.ExecuteTemplate(&buf, "base", page)
+	if err != nil {
+		log.Errorf("HTTP error: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
@@ -0,0 +1,327 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/filesystem"
+	"github.com/etix/mirrorbits/utils"
+	"github.com/gomodule/redigo/redis"
+)
+
+// fileIndexCache holds the sorted list of the public files of the repository
+// used by the sitemap and the file index (see FileIndex)
+type fileIndexCache struct {
+	sync.Mutex
+	files   []string
+	expires time.Time
+}
+
+// FileIndexEntry is a file of the file index
+type FileIndexEntry struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Sha1    string `json:",omitempty"`
+	Sha256  string `json:",omitempty"`
+	Md5     string `json:",omitempty"`
+}
+
+// FileIndexPage is a page of the file index
+type FileIndexPage struct {
+	Page  int
+	Pages int
+	Files []FileIndexEntry
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	XMLNS    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+const sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// list returns the sorted list of the files of the repository, without the
+// files under embargo or restricted to the signed URLs
+func (f *fileIndexCache) list(r redis.Conn) ([]string, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	now := time.Now()
+	if f.files != nil && now.Before(f.expires) {
+		return f.files, nil
+	}
+
+	files, err := redis.Strings(r.Do("SMEMBERS", "FILES"))
+	if err != nil {
+		return nil, err
+	}
+	list := files[:0]
+	for _, file := range files {
+		if GetConfig().EmbargoStatus(file) != 0 || GetConfig().SigningSecret(file) != "" {
+			continue
+		}
+		list = append(list, file)
+	}
+	sort.Strings(list)
+
+	f.files = list
+	f.expires = now.Add(time.Duration(GetConfig().FileIndex.CacheTTL) * time.Second)
+	return list, nil
+}
+
+// paginate returns the files of the given page (starting at 1) and the
+// number of pages
+func paginate(files []string, page, size int) ([]string, int) {
+	pages := (len(files) + size - 1) / size
+	if pages == 0 {
+		pages = 1
+	}
+	if page < 1 || page > pages {
+		return nil, pages
+	}
+	end := page * size
+	if end > len(files) {
+		end = len(files)
+	}
+	return files[(page-1)*size : end], pages
+}
+
+// requestedPage returns the page requested with ?page=N, 1 by default, or
+// 0 if invalid
+func requestedPage(r *http.Request) int {
+	p := r.URL.Query().Get("page")
+	if p == "" {
+		return 1
+	}
+	page, err := strconv.Atoi(p)
+	if err != nil || page < 1 {
+		return 0
+	}
+	return page
+}
+
+// baseURL returns the scheme and the host used by the client
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || strings.ToLower(r.Header.Get("X-Forwarded-Proto")) == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// fileIndexFiles returns the files of the requested page, the page and the
+// number of pages, or false if an error was returned to the client
+func (h *HTTP) fileIndexFiles(w http.ResponseWriter, r *http.Request) ([]string, int, int, bool) {
+	conn := h.redis.Get()
+	files, err := h.fileIndex.list(conn)
+	conn.Close()
+	if err != nil {
+		http.Error(w, "Cannot fetch the list of files", http.StatusInternalServerError)
+		return nil, 0, 0, false
+	}
+
+	page := requestedPage(r)
+	files, pages := paginate(files, page, GetConfig().FileIndex.PageSize)
+	if page == 0 || page > pages {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return nil, 0, 0, false
+	}
+	return files, page, pages, true
+}
+
+func (h *HTTP) sitemapHandler(w http.ResponseWriter, r *http.Request) {
+	files, _, pages, ok := h.fileIndexFiles(w, r)
+	if !ok {
+		return
+	}
+
+	var doc interface{}
+	if pages > 1 && r.URL.Query().Get("page") == "" {
+		// Too many files for a single sitemap
+		index := sitemapIndex{XMLNS: sitemapNS}
+		for i := 1; i <= pages; i++ {
+			index.Sitemaps = append(index.Sitemaps, sitemapURL{
+				Loc: fmt.Sprintf("%s%s?page=%d", baseURL(r), utils.EscapePath(r.URL.Path), i),
+			})
+		}
+		doc = index
+	} else {
+		set := sitemapURLSet{XMLNS: sitemapNS}
+		for _, file := range files {
+			fileInfo, err := h.cache.GetFileInfo(file)
+			if err != nil {
+				http.Error(w, "Cannot fetch the file details", http.StatusInternalServerError)
+				return
+			}
+			u := sitemapURL{Loc: baseURL(r) + utils.EscapePath(file)}
+			if !fileInfo.ModTime.IsZero() {
+				u.LastMod = fileInfo.ModTime.UTC().Format("2006-01-02")
+			}
+			set.URLs = append(set.URLs, u)
+		}
+		doc = set
+	}
+
+	output, err := xml.Marshal(doc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	w.Write(output)
+}
+
+func (h *HTTP) fileIndexHandler(w http.ResponseWriter, r *http.Request, ctx *Context) {
+	files, page, pages, ok := h.fileIndexFiles(w, r)
+	if !ok {
+		return
+	}
+
+	result := FileIndexPage{
+		Page:  page,
+		Pages: pages,
+		Files: make([]FileIndexEntry, 0, len(files)),
+	}
+	for _, file := range files {
+		fileInfo, err := h.cache.GetFileInfo(file)
+		if err != nil {
+			http.Error(w, "Cannot fetch the file details", http.StatusInternalServerError)
+			return
+		}
+		result.Files = append(result.Files, FileIndexEntry{
+			Path:    file,
+			Size:    fileInfo.Size,
+			ModTime: fileInfo.ModTime,
+			Sha1:    fileInfo.Sha1,
+			Sha256:  fileInfo.Sha256,
+			Md5:     fileInfo.Md5,
+		})
+	}
+
+	if page < pages {
+		w.Header().Set("Link", fmt.Sprintf("<%s?page=%d>; rel=\"next\"", utils.EscapePath(r.URL.Path), page+1))
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		var output []byte
+		var err error
+		if ctx.IsPretty() {
+			output, err = json.MarshalIndent(result, "", "    ")
+		} else {
+			output, err = json.Marshal(result)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(output)
+		return
+	}
+
+	// One file per line: path, size, modification time and hashes
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, f := range result.Files {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n", f.Path, f.Size, f.ModTime.UTC().Format(time.RFC3339),
+			dashIfEmpty(f.Sha256), dashIfEmpty(f.Sha1), dashIfEmpty(f.Md5))
+	}
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// manifestCache holds the last mirror sync manifest (see SyncManifest),
+// refreshed along with the list of the files
+type manifestCache struct {
+	sync.Mutex
+	data      []byte
+	generated time.Time
+	expires   time.Time
+}
+
+// syncManifest returns the encoded mirror sync manifest and the time of the
+// last scan of the repository
+func (h *HTTP) syncManifest() ([]byte, time.Time, error) {
+	h.manifest.Lock()
+	defer h.manifest.Unlock()
+
+	now := time.Now()
+	if h.manifest.data != nil && now.Before(h.manifest.expires) {
+		return h.manifest.data, h.manifest.generated, nil
+	}
+
+	conn := h.redis.Get()
+	defer conn.Close()
+
+	files, err := h.fileIndex.list(conn)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	manifest := filesystem.Manifest{
+		Files: make([]filesystem.FileInfo, 0, len(files)),
+	}
+	if lastScan, err := redis.Int64(conn.Do("GET", "LAST_SOURCE_SCAN")); err == nil {
+		manifest.Generated = time.Unix(lastScan, 0).UTC()
+	}
+	for _, file := range files {
+		fileInfo, err := h.cache.GetFileInfo(file)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		fileInfo.Path = file
+		manifest.Files = append(manifest.Files, fileInfo)
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	h.manifest.data = data
+	h.manifest.generated = manifest.Generated
+	h.manifest.expires = now.Add(time.Duration(GetConfig().FileIndex.CacheTTL) * time.Second)
+	return data, manifest.Generated, nil
+}
+
+func (h *HTTP) syncManifestHandler(w http.ResponseWriter, r *http.Request) {
+	data, generated, err := h.syncManifest()
+	if err != nil {
+		http.Error(w, "Cannot build the manifest", http.StatusInternalServerError)
+		return
+	}
+	// Let the clients skip the download until the next scan of the repository
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("ETag", fmt.Sprintf("\"%x-%x\"", generated.Unix(), len(data)))
+	http.ServeContent(w, r, "", generated, bytes.NewReader(data))
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	files := []string{"/a", "/b", "/c", "/d", "/e"}
+
+	page, pages := paginate(files, 1, 2)
+	if pages != 3 || !reflect.DeepEqual(page, []string{"/a", "/b"}) {
+		t.Fatalf("Unexpected first page %v of %d", page, pages)
+	}
+	page, pages = paginate(files, 3, 2)
+	if pages != 3 || !reflect.DeepEqual(page, []string{"/e"}) {
+		t.Fatalf("Unexpected last page %v of %d", page, pages)
+	}
+	if page, _ = paginate(files, 4, 2); page != nil {
+		t.Fatalf("A page past the end should be empty")
+	}
+	if page, pages = paginate(nil, 1, 2); pages != 1 || len(page) != 0 {
+		t.Fatalf("An empty index should have a single empty page")
+	}
+}
+
+func TestRequestedPage(t *testing.T) {
+	pages := map[string]int{
+		"/files":         1,
+		"/files?page=3":  3,
+		"/files?page=0":  0,
+		"/files?page=-1": 0,
+		"/files?page=a":  0,
+	}
+	for target, expected := range pages {
+		if page := requestedPage(httptest.NewRequest("GET", target, nil)); page != expected {
+			t.Fatalf("Expected page %d for %s, got %d", expected, target, page)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// healthHandler answers the liveness checks, it succeeds as long as the
+// process is able to serve requests
+func (h *HTTP) healthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprintln(w, "OK")
+}
+
+// readinessHandler answers the readiness checks, it only succeeds when the
+// database is reachable, the repository has been indexed and at least one
+// mirror is able to receive the clients
+func (h *HTTP) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	ready, report := h.readiness()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	for _, line := range report {
+		fmt.Fprintln(w, line)
+	}
+}
+
+func (h *HTTP) readiness() (bool, []string) {
+	conn, err := h.redis.Connect()
+	if err == nil {
+		defer conn.Close()
+		_, err = conn.Do("PING")
+	}
+	if err != nil {
+		return false, []string{"database: " + err.Error()}
+	}
+	report := []string{"database: ok"}
+	if h.redis.Degraded() {
+		// Still able to serve the redirections
+		report = []string{"database: not writable (degraded mode)"}
+	}
+
+	indexed, err := redis.Bool(conn.Do("EXISTS", "FILES"))
+	if err != nil {
+		return false, append(report, "repository: "+err.Error())
+	}
+	if !indexed {
+		return false, append(report, "repository: not indexed")
+	}
+	report = append(report, "repository: ok")
+
+	mirrorsIDs, err := h.redis.GetListOfMirrors()
+	if err != nil {
+		return false, append(report, "mirrors: "+err.Error())
+	}
+	up := 0
+	for id := range mirrorsIDs {
+		mirror, err := h.cache.GetMirror(id)
+		if err != nil {
+			return false, append(report, "mirrors: "+err.Error())
+		}
+		if mirror.Enabled && !mirror.Maintenance && mirror.Up {
+			up++
+		}
+	}
+	report = append(report, fmt.Sprintf("mirrors: %d up", up))
+
+	return up > 0, report
+}
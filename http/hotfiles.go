@@ -0,0 +1,75 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+)
+
+const (
+	// Maximum number of files counted during an interval
+	hotFilesMaxTracked = 100000
+)
+
+// hotFileDetector counts the requests per file to find the most requested
+// files of the last interval (see HotFiles)
+type hotFileDetector struct {
+	sync.Mutex
+	counts  map[string]int
+	hot     map[string]bool
+	rotated time.Time
+}
+
+// hit records a request for the given file and returns true if the file
+// was one of the most requested files of the previous interval
+func (d *hotFileDetector) hit(path string) bool {
+	config := GetConfig().HotFiles
+	if config.Count <= 0 {
+		return false
+	}
+	now := time.Now()
+
+	d.Lock()
+	defer d.Unlock()
+
+	if d.counts == nil {
+		d.counts = make(map[string]int)
+		d.rotated = now
+	}
+	if now.Sub(d.rotated) >= time.Duration(config.Interval)*time.Second {
+		d.rotate(config.Count, config.MinRequests)
+		d.rotated = now
+	}
+	if _, ok := d.counts[path]; ok || len(d.counts) < hotFilesMaxTracked {
+		d.counts[path]++
+	}
+	return d.hot[path]
+}
+
+// rotate elects the hot files of the interval and resets the counters
+func (d *hotFileDetector) rotate(count, minRequests int) {
+	type file struct {
+		path     string
+		requests int
+	}
+	var files []file
+	for path, requests := range d.counts {
+		if requests >= minRequests {
+			files = append(files, file{path, requests})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].requests > files[j].requests
+	})
+
+	d.hot = make(map[string]bool, count)
+	for i := 0; i < len(files) && i < count; i++ {
+		d.hot[files[i].path] = true
+	}
+	d.counts = make(map[string]int)
+}
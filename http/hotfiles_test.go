@@ -0,0 +1,47 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+)
+
+func TestHotFileDetector(t *testing.T) {
+	config := &Configuration{}
+	config.HotFiles.Interval = 1
+	config.HotFiles.MinRequests = 2
+	SetConfiguration(config)
+	d := &hotFileDetector{}
+	if d.hit("/a") || d.counts != nil {
+		t.Fatalf("The detector must be disabled when Count is 0")
+	}
+
+	config.HotFiles.Count = 1
+	for i := 0; i < 3; i++ {
+		d.hit("/a")
+	}
+	d.hit("/b")
+	d.hit("/b")
+	if d.hit("/a") {
+		t.Fatalf("No file is hot before the end of the first interval")
+	}
+
+	// Only the most requested file of the interval is elected
+	d.rotated = d.rotated.Add(-2 * time.Second)
+	if !d.hit("/a") {
+		t.Fatalf("/a is expected to be hot")
+	}
+	if d.hit("/b") {
+		t.Fatalf("/b is not expected to be hot")
+	}
+
+	// The files requested less than MinRequests times are not hot
+	d.rotated = d.rotated.Add(-2 * time.Second)
+	if d.hit("/a") || d.hit("/b") {
+		t.Fatalf("No file is expected to be hot")
+	}
+}
@@ -7,10 +7,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"io"
 	"math/rand"
+	"mime"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -26,6 +30,8 @@ import (
 	"github.com/etix/mirrorbits/logs"
 	"github.com/etix/mirrorbits/mirrors"
 	"github.com/etix/mirrorbits/network"
+	"github.com/etix/mirrorbits/objectstorage"
+	"github.com/etix/mirrorbits/tracing"
 	"github.com/etix/mirrorbits/utils"
 	"github.com/gomodule/redigo/redis"
 	"github.com/op/go-logging"
@@ -33,7 +39,7 @@ import (
 )
 
 var (
-	log = logging.MustGetLogger("main")
+	log = logging.MustGetLogger("http")
 )
 
 // HTTP represents an instance of the HTTP webserver
@@ -44,9 +50,17 @@ type HTTP struct {
 	Listener       *net.Listener
 	server         *graceful.Server
 	serverStopChan <-chan struct{}
+	handler        http.Handler
 	stats          *Stats
 	cache          *mirrors.Cache
 	engine         mirrorSelection
+	crawlers       crawlerLimiter
+	torrents       torrentCache
+	probes         probeCache
+	fileIndex      fileIndexCache
+	manifest       manifestCache
+	propagation    propagationCache
+	signatures     signatureCache
 	Restarting     bool
 	stopped        bool
 	stoppedMutex   sync.Mutex
@@ -58,6 +72,8 @@ type Templates struct {
 
 	mirrorlist  *template.Template
 	mirrorstats *template.Template
+	directory   *template.Template
+	landing     map[string]*template.Template
 }
 
 // HTTPServer is the constructor of the HTTP server
@@ -68,10 +84,20 @@ func HTTPServer(redis *database.Redis, cache *mirrors.Cache) *HTTP {
 	h.templates.RWMutex = new(sync.RWMutex)
 	h.templates.mirrorlist = template.Must(h.LoadTemplates("mirrorlist"))
 	h.templates.mirrorstats = template.Must(h.LoadTemplates("mirrorstats"))
+	if GetConfig().DirectoryListing {
+		h.templates.directory = template.Must(h.LoadTemplates("directory"))
+	}
+	if GetConfig().UsesOutputMode("landing") {
+		landing, err := h.loadLandingTemplates()
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		h.templates.landing = landing
+	}
 	h.cache = cache
 	h.stats = NewStats(redis)
-	h.engine = DefaultEngine{}
-	http.Handle("/", NewGzipHandler(h.requestDispatcher))
+	h.engine = DefaultEngine{hot: &hotFileDetector{}}
+	h.handler = NewGzipHandler(h.requestDispatcher)
 
 	// Load the GeoIP databases
 	if err := h.geoip.LoadGeoIP(); err != nil {
@@ -147,6 +173,20 @@ func (h *HTTP) Reload() {
 	} else {
 		log.Errorf("could not reload templates 'mirrorstats': %s", err.Error())
 	}
+	if GetConfig().DirectoryListing {
+		if t, err := h.LoadTemplates("directory"); err == nil {
+			h.templates.directory = t
+		} else {
+			log.Errorf("could not reload templates 'directory': %s", err.Error())
+		}
+	}
+	if GetConfig().UsesOutputMode("landing") {
+		if t, err := h.loadLandingTemplates(); err == nil {
+			h.templates.landing = t
+		} else {
+			log.Errorf("could not reload templates 'landing': %s", err.Error())
+		}
+	}
 	h.templates.Unlock()
 }
 
@@ -171,7 +211,7 @@ func (h *HTTP) RunServer() (err error) {
 	h.server = &graceful.Server{
 		// http
 		Server: &http.Server{
-			Handler:        nil,
+			Handler:        h.handler,
 			ReadTimeout:    10 * time.Second,
 			WriteTimeout:   10 * time.Second,
 			MaxHeaderBytes: 1 << 20,
@@ -197,11 +237,104 @@ func (h *HTTP) RunServer() (err error) {
 }
 
 func (h *HTTP) requestDispatcher(w http.ResponseWriter, r *http.Request) {
+	ew := &errorPageWriter{ResponseWriter: w}
+	defer ew.Finish()
+	w = ew
+
+	spanCtx, span := tracing.Start(tracing.Extract(r), tracing.KindServer, "HTTP "+r.Method)
+	r = r.WithContext(spanCtx)
+
+	// Map the legacy paths to the current tree before anything else
+	if target, redirect := GetConfig().PathAlias(r.URL.Path); target != "" {
+		if redirect {
+			u := url.URL{Path: target, RawQuery: r.URL.RawQuery}
+			http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+			span.End()
+			return
+		}
+		r.URL.Path = target
+		r.URL.RawPath = ""
+	}
+
 	h.templates.RLock()
 	ctx := NewContext(w, r, h.templates)
 	h.templates.RUnlock()
+	ew.requestID = ctx.RequestID()
+
+	span.SetAttribute("http.method", r.Method)
+	span.SetAttribute("http.target", r.URL.Path)
+	span.SetAttribute("mirrorbits.request_id", ctx.RequestID())
+	defer func() {
+		span.SetAttribute("http.status_code", ew.Status())
+		span.End()
+	}()
 
 	w.Header().Set("Server", "Mirrorbits/"+core.VERSION)
+	w.Header().Set("X-Request-ID", ctx.RequestID())
+	counters.Add("requests", 1)
+
+	if healthPath := GetConfig().HealthPath; healthPath != "" && r.URL.Path == healthPath {
+		h.healthHandler(w, r)
+		return
+	}
+	if readinessPath := GetConfig().ReadinessPath; readinessPath != "" && r.URL.Path == readinessPath {
+		h.readinessHandler(w, r)
+		return
+	}
+	if reportPath := GetConfig().ErrorReportPath; reportPath != "" && r.URL.Path == reportPath {
+		h.errorReportHandler(w, r)
+		return
+	}
+
+	for name, value := range GetConfig().PathResponseHeaders(path.Clean(r.URL.Path)) {
+		w.Header().Set(name, value)
+	}
+
+	if r.URL.Path == "/robots.txt" && GetConfig().RobotsTxt != "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(GetConfig().RobotsTxt))
+		return
+	}
+
+	if r.URL.Path == "/sitemap.xml" && GetConfig().FileIndex.Sitemap {
+		h.sitemapHandler(w, r)
+		return
+	}
+	if indexPath := GetConfig().FileIndex.Path; indexPath != "" && r.URL.Path == indexPath {
+		h.fileIndexHandler(w, r, ctx)
+		return
+	}
+	if manifestPath := GetConfig().SyncManifest.Path; manifestPath != "" && r.URL.Path == manifestPath {
+		h.syncManifestHandler(w, r)
+		return
+	}
+
+	if policy := GetConfig().CrawlerPolicy(r.UserAgent()); policy != nil {
+		if policy.Block {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		if policy.RateLimit > 0 && !h.crawlers.allow(policy.UserAgent, policy.RateLimit, time.Now()) {
+			w.Header().Set("Retry-After", "60")
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	// Refuse to serve the files under embargo
+	if status := GetConfig().EmbargoStatus(path.Clean(r.URL.Path)); status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	// Restricted paths require a valid signature
+	if secret := GetConfig().SigningSecret(path.Clean(r.URL.Path)); secret != "" {
+		q := r.URL.Query()
+		if !utils.CheckPathSignature(secret, path.Clean(r.URL.Path), q.Get("expires"), q.Get("signature"), time.Now()) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+	}
 
 	switch ctx.Type() {
 	case MIRRORLIST:
@@ -214,6 +347,20 @@ func (h *HTTP) requestDispatcher(w http.ResponseWriter, r *http.Request) {
 		h.fileStatsHandler(w, r, ctx)
 	case CHECKSUM:
 		h.checksumHandler(w, r, ctx)
+	case PROPAGATION:
+		h.propagationHandler(w, r, ctx)
+	case REGISTER:
+		h.registerHandler(w, r, ctx)
+	case VERIFYCONTACT:
+		h.verifyContactHandler(w, r, ctx)
+	case MIRRORAPI:
+		h.mirrorAPIHandler(w, r, ctx)
+	case DIRECTORY:
+		if GetConfig().DirectoryListing {
+			h.directoryHandler(w, r, ctx)
+		} else {
+			h.mirrorHandler(w, r, ctx)
+		}
 	}
 }
 
@@ -221,15 +368,63 @@ func (h *HTTP) mirrorHandler(w http.ResponseWriter, r *http.Request, ctx *Contex
 	//XXX it would be safer to recover in case of panic
 
 	// Sanitize path
-	urlPath, err := filesystem.EvaluateFilePath(GetConfig().Repository, r.URL.Path)
+	urlPath, err := h.evaluateFilePath(r.URL.Path)
 	if err != nil {
 		if err == filesystem.ErrOutsideRepo {
 			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 			return
 		}
+		if os.IsNotExist(err) {
+			// The checksum files can be generated from the index
+			if h.checksumFileHandler(w, r) {
+				return
+			}
+			// The client and the repository might use a different Unicode
+			// normalization form for the accented characters
+			if alt := utils.AlternateNormalization(path.Clean(r.URL.Path)); alt != "" {
+				if _, err := h.evaluateFilePath(alt); err == nil {
+					u := url.URL{Path: alt, RawQuery: r.URL.RawQuery}
+					http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+					return
+				}
+			}
+			// The file might have been moved elsewhere
+			if target := h.resolveAlias(path.Clean(r.URL.Path)); target != "" {
+				u := url.URL{Path: target, RawQuery: r.URL.RawQuery}
+				http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+				return
+			}
+			// The path might differ by its case or its trailing slash
+			if h.tolerantLookupRedirect(w, r) {
+				return
+			}
+			// The file might have been removed recently
+			if h.goneHandler(w, r) {
+				return
+			}
+		}
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 		return
 	}
+	if !checkTargetAccess(w, r, r.URL.Path) {
+		return
+	}
+
+	// Keep the bandwidth of the mirrors for the sites allowed to link the file
+	hotlinked := false
+	if policy := GetConfig().RefererPolicy(urlPath, r.Referer(), r.Host); policy != nil {
+		counters.Add("hotlinks", 1)
+		switch policy.Action {
+		case "block":
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		case "redirect":
+			http.Redirect(w, r, policy.RedirectURL, http.StatusFound)
+			return
+		case "landing":
+			hotlinked = true
+		}
+	}
 
 	fileInfo := filesystem.NewFileInfo(urlPath)
 
@@ -245,29 +440,74 @@ func (h *HTTP) mirrorHandler(w http.ResponseWriter, r *http.Request, ctx *Contex
 		}
 	}
 
+	ctx.SetRemoteIP(remoteIP)
+
+	_, span := tracing.Start(r.Context(), tracing.KindInternal, "geoip.lookup")
 	clientInfo := h.geoip.GetRecord(remoteIP) //TODO return a pointer?
+	span.SetAttribute("geo.country", clientInfo.CountryCode)
+	span.End()
 
+	// Some files can't be served in some countries or networks
+	if status := GetConfig().GeoRestrictionStatus(fileInfo.Path, clientInfo.CountryCode, clientInfo.ASNum); status != 0 {
+		counters.Add("georestricted", 1)
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	_, span = tracing.Start(r.Context(), tracing.KindInternal, "selection")
 	mlist, excluded, err := h.engine.Selection(ctx, h.cache, &fileInfo, clientInfo)
+	span.SetAttribute("mirrorbits.selected", len(mlist))
+	span.SetAttribute("mirrorbits.excluded", len(excluded))
+	span.SetError(err)
+	span.End()
+
+	outputMode := "mirrorlist"
+	if !ctx.IsMirrorlist() {
+		outputMode = GetConfig().UserAgentOutputMode(fileInfo.Path, r.UserAgent())
+		if hotlinked {
+			outputMode = "landing"
+		}
+		if outputMode == "auto" {
+			if strings.Contains(r.Header.Get("Accept"), "application/json") {
+				outputMode = "json"
+			} else {
+				outputMode = "redirect"
+			}
+		}
+	}
 
 	/* Handle errors */
 	fallback := false
-	if _, ok := err.(net.Error); ok || len(mlist) == 0 {
+	if err == ErrNotPropagated && len(GetConfig().Fallbacks) == 0 {
+		if outputMode != "json" {
+			// Not enough mirrors are serving this file yet, serve it ourselves
+			h.serveLocalFile(w, r, fileInfo.Path)
+			return
+		}
+		// The details are still returned, without any mirror
+		err = nil
+	} else if _, ok := err.(net.Error); ok || len(mlist) == 0 {
 		/* Handle fallbacks */
 		fallbacks := GetConfig().Fallbacks
 		if len(fallbacks) > 0 {
 			fallback = true
+			counters.Add("fallbacks", 1)
 			for i, f := range fallbacks {
+				continent := strings.ToUpper(f.ContinentCode)
+				if continent == "" {
+					continent = utils.CountryContinent(f.CountryCode)
+				}
 				mlist = append(mlist, mirrors.Mirror{
 					ID:            i * -1,
 					Name:          fmt.Sprintf("fallback%d", i),
 					HttpURL:       f.URL,
-					CountryCodes:  strings.ToUpper(f.CountryCode),
-					CountryFields: []string{strings.ToUpper(f.CountryCode)},
-					ContinentCode: strings.ToUpper(f.ContinentCode)})
+					CountryCodes:  mirrors.CountryList{strings.ToUpper(f.CountryCode)},
+					ContinentCode: continent})
 			}
 			sort.Sort(mirrors.ByRank{Mirrors: mlist, ClientInfo: clientInfo})
 		} else {
 			// No fallback in stock, there's nothing else we can do
+			counters.Add("unavailable", 1)
 			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
 			return
 		}
@@ -276,6 +516,14 @@ func (h *HTTP) mirrorHandler(w http.ResponseWriter, r *http.Request, ctx *Contex
 		return
 	}
 
+	// Make sure the mirror answers before redirecting to a critical file
+	if !ctx.IsMirrorlist() && !fallback && len(mlist) > 0 && GetConfig().IsProbedPath(fileInfo.Path) {
+		_, span = tracing.Start(r.Context(), tracing.KindClient, "probe")
+		mlist, excluded = h.probes.probeMirrors(fileInfo.Path, mlist, excluded)
+		span.SetAttribute("mirrorbits.mirror", mlist[0].Name)
+		span.End()
+	}
+
 	results := &mirrors.Results{
 		FileInfo:     fileInfo,
 		MirrorList:   mlist,
@@ -284,24 +532,23 @@ func (h *HTTP) mirrorHandler(w http.ResponseWriter, r *http.Request, ctx *Contex
 		IP:           remoteIP,
 		Fallback:     fallback,
 		LocalJSPath:  GetConfig().LocalJSPath,
+		RequestID:    ctx.RequestID(),
 	}
+	results.TorrentPath, results.MagnetLink = h.torrentLinks(fileInfo.Path, fileInfo.Size)
 
 	var resultRenderer resultsRenderer
 
-	if ctx.IsMirrorlist() {
+	if outputMode == "mirrorlist" {
 		resultRenderer = &MirrorListRenderer{}
 	} else {
-		switch GetConfig().OutputMode {
+		switch outputMode {
 		case "json":
 			resultRenderer = &JSONRenderer{}
 		case "redirect":
 			resultRenderer = &RedirectRenderer{}
-		case "auto":
-			accept := r.Header.Get("Accept")
-			if strings.Index(accept, "application/json") >= 0 {
-				resultRenderer = &JSONRenderer{}
-			} else {
-				resultRenderer = &RedirectRenderer{}
+		case "landing":
+			resultRenderer = &LandingPageRenderer{
+				SignaturePath: h.signaturePath(fileInfo.Path),
 			}
 		default:
 			http.Error(w, "No page renderer", http.StatusInternalServerError)
@@ -316,16 +563,176 @@ func (h *HTTP) mirrorHandler(w http.ResponseWriter, r *http.Request, ctx *Contex
 		http.Error(w, err.Error(), status)
 	}
 
-	if !ctx.IsMirrorlist() {
+	if outputMode != "mirrorlist" {
 		logs.LogDownload(resultRenderer.Type(), status, results, err)
-		if len(mlist) > 0 {
-			h.stats.CountDownload(mlist[0], fileInfo)
+		policy := GetConfig().CrawlerPolicy(r.UserAgent())
+		if len(mlist) > 0 && (policy == nil || !policy.NoStats) {
+			h.stats.CountDownload(mlist[0], fileInfo, clientInfo.CountryCode)
+		}
+		if err == nil {
+			counters.Add("downloads", 1)
+		}
+		if retention := GetConfig().RequestTraceRetention; retention > 0 && !h.redis.Degraded() {
+			trace := mirrors.NewTrace(ctx.RequestID(), resultRenderer.Type(), status, results, err)
+			if err := mirrors.SaveTrace(h.redis, trace, time.Duration(retention)*time.Minute); err != nil {
+				log.Warningf("Unable to save the trace of request %s: %s", ctx.RequestID(), err)
+			}
 		}
 	}
 
 	return
 }
 
+// evaluateFilePath sanitizes and validates the requested path against the
+// local repository or, when the repository is stored in a bucket, against
+// the index of the repository
+func (h *HTTP) evaluateFilePath(urlPath string) (string, error) {
+	if !GetConfig().ObjectStorage.Enabled() {
+		return filesystem.EvaluateFilePath(GetConfig().Repository, urlPath, GetConfig().SymlinkPolicy)
+	}
+	urlPath = path.Clean("/" + urlPath)
+
+	conn := h.redis.Get()
+	defer conn.Close()
+
+	exists, err := redis.Bool(conn.Do("SISMEMBER", "FILES", urlPath))
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", os.ErrNotExist
+	}
+	return urlPath, nil
+}
+
+// serveLocalFile serves the given file directly from the local repository
+func (h *HTTP) serveLocalFile(w http.ResponseWriter, r *http.Request, filePath string) {
+	if ctype := GetConfig().MimeType(filePath); ctype != "" {
+		w.Header().Set("Content-Type", ctype)
+	}
+	if disposition := GetConfig().ContentDispositionType(filePath); disposition != "" {
+		value := mime.FormatMediaType(disposition, map[string]string{
+			"filename": path.Base(filePath),
+		})
+		if value == "" {
+			// The filename can't be encoded
+			value = disposition
+		}
+		w.Header().Set("Content-Disposition", value)
+	}
+	if GetConfig().ObjectStorage.Enabled() {
+		h.serveObject(w, r, filePath)
+		return
+	}
+	http.ServeFile(w, r, GetConfig().Repository+filePath)
+}
+
+// serveObject serves the given file from the bucket holding the repository
+func (h *HTTP) serveObject(w http.ResponseWriter, r *http.Request, filePath string) {
+	bucket, err := objectstorage.NewBucket()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	header := make(http.Header)
+	for _, k := range []string{"Range", "If-Range", "If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since"} {
+		if v := r.Header.Get(k); v != "" {
+			header.Set(k, v)
+		}
+	}
+	resp, err := bucket.Get(r.Context(), bucket.Key(filePath), header)
+	if err != nil {
+		if e, ok := err.(*objectstorage.Error); ok && e.StatusCode == http.StatusNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		log.Errorf("Unable to fetch %s from the bucket: %s", filePath, err)
+		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for _, k := range []string{"Content-Type", "Content-Length", "Content-Range", "Content-Encoding", "Accept-Ranges", "ETag", "Last-Modified"} {
+		// The headers already set by the configuration take precedence
+		if v := resp.Header.Get(k); v != "" && w.Header().Get(k) == "" {
+			w.Header().Set(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	if r.Method != http.MethodHead {
+		io.Copy(w, resp.Body)
+	}
+}
+
+// resolveAlias returns the new location of a file that has been moved within
+// the repository or an empty string if the file is unknown
+func (h *HTTP) resolveAlias(urlPath string) string {
+	conn := h.redis.Get()
+	defer conn.Close()
+
+	var target string
+	// Follow the successive moves of a file
+	for i := 0; i < 10; i++ {
+		next, err := redis.String(conn.Do("GET", fmt.Sprintf("FILEALIAS_%s", urlPath)))
+		if err != nil {
+			break
+		}
+		target, urlPath = next, next
+	}
+	return target
+}
+
+// goneHandler answers with 410 Gone and returns true if the requested file
+// has been removed from the repository recently (see Tombstones)
+func (h *HTTP) goneHandler(w http.ResponseWriter, r *http.Request) bool {
+	urlPath := path.Clean(r.URL.Path)
+
+	conn := h.redis.Get()
+	removed, err := redis.Int64(conn.Do("GET", fmt.Sprintf("FILETOMBSTONE_%s", urlPath)))
+	conn.Close()
+	if err != nil {
+		return false
+	}
+
+	counters.Add("gone", 1)
+	_, message := GetConfig().Tombstone(urlPath)
+	if message == "" {
+		message = fmt.Sprintf("This file has been removed from the repository on %s.", time.Unix(removed, 0).UTC().Format("2006-01-02"))
+	}
+	http.Error(w, message, http.StatusGone)
+	return true
+}
+
+// resolveLookup returns the indexed file or directory matching the path
+// case-insensitively and regardless of its trailing slash (see
+// TolerantLookups) or an empty string
+func (h *HTTP) resolveLookup(urlPath string) string {
+	if !GetConfig().TolerantLookups {
+		return ""
+	}
+	conn := h.redis.Get()
+	defer conn.Close()
+
+	target, err := redis.String(conn.Do("HGET", "FILES_LOOKUP", utils.LookupKey(urlPath)))
+	if err != nil || target == urlPath {
+		return ""
+	}
+	return target
+}
+
+// tolerantLookupRedirect redirects the request to the indexed path matching
+// the requested one (see resolveLookup) and returns true if such a path exists
+func (h *HTTP) tolerantLookupRedirect(w http.ResponseWriter, r *http.Request) bool {
+	target := h.resolveLookup(r.URL.Path)
+	if target == "" {
+		return false
+	}
+	u := url.URL{Path: target, RawQuery: r.URL.RawQuery}
+	http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+	return true
+}
+
 // LoadTemplates pre-loads templates from the configured template directory
 func (h *HTTP) LoadTemplates(name string) (t *template.Template, err error) {
 	t = template.New("t")
@@ -432,7 +839,7 @@ func (h *HTTP) fileStatsHandler(w http.ResponseWriter, r *http.Request, ctx *Con
 func (h *HTTP) checksumHandler(w http.ResponseWriter, r *http.Request, ctx *Context) {
 
 	// Sanitize path
-	urlPath, err := filesystem.EvaluateFilePath(GetConfig().Repository, r.URL.Path)
+	urlPath, err := h.evaluateFilePath(r.URL.Path)
 	if err != nil {
 		if err == filesystem.ErrOutsideRepo {
 			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
@@ -441,6 +848,9 @@ func (h *HTTP) checksumHandler(w http.ResponseWriter, r *http.Request, ctx *Cont
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 		return
 	}
+	if !checkTargetAccess(w, r, r.URL.Path) {
+		return
+	}
 
 	fileInfo, err := h.cache.GetFileInfo(urlPath)
 	if err == redis.ErrNil {
@@ -473,6 +883,105 @@ func (h *HTTP) checksumHandler(w http.ResponseWriter, r *http.Request, ctx *Cont
 	return
 }
 
+// checksumFileHandler serves a checksum file (i.e. file.iso.sha256) missing
+// from the repository by generating it from the hashes of the indexed file,
+// along with its detached signature (file.iso.sha256.asc) if a signing key is
+// configured. It returns false if the request doesn't target such a file.
+func (h *HTTP) checksumFileHandler(w http.ResponseWriter, r *http.Request) bool {
+	checksumPath := r.URL.Path
+	signed := false
+
+	if strings.HasSuffix(checksumPath, ".asc") && GetConfig().GPGSigning.Key != "" {
+		checksumPath = strings.TrimSuffix(checksumPath, ".asc")
+		// Only sign the checksum files we generate
+		if _, err := h.evaluateFilePath(checksumPath); !os.IsNotExist(err) {
+			return false
+		}
+		signed = true
+	}
+
+	ext := strings.TrimPrefix(path.Ext(checksumPath), ".")
+	if ext == "" || !utils.IsInSlice(ext, GetConfig().ChecksumFiles) {
+		return false
+	}
+
+	filePath := strings.TrimSuffix(checksumPath, "."+ext)
+	urlPath, err := h.evaluateFilePath(filePath)
+	if err != nil {
+		return false
+	}
+	if !checkTargetAccess(w, r, filePath) {
+		return true
+	}
+
+	fileInfo, err := h.cache.GetFileInfo(urlPath)
+	if err != nil {
+		return false
+	}
+
+	var hash string
+
+	switch ext {
+	case "md5":
+		hash = fileInfo.Md5
+	case "sha1":
+		hash = fileInfo.Sha1
+	case "sha256":
+		hash = fileInfo.Sha256
+	}
+
+	if len(hash) == 0 {
+		return false
+	}
+
+	content := []byte(fmt.Sprintf("%s  %s\n", hash, filepath.Base(fileInfo.Path)))
+
+	if signed {
+		content, err = h.signatures.sign(content)
+		if err != nil {
+			log.Errorf("Unable to sign %s: %s", checksumPath, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return true
+		}
+		w.Header().Set("Content-Type", "application/pgp-signature")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+	}
+
+	w.Write(content)
+	return true
+}
+
+// checkTargetAccess refuses the requests reaching a file under embargo or
+// restricted to signed URLs through a symlink, the requested path itself
+// being checked by the dispatcher. Such a request must be signed for the
+// target of the symlink. It returns false if the request was refused.
+func checkTargetAccess(w http.ResponseWriter, r *http.Request, requested string) bool {
+	if GetConfig().ObjectStorage.Enabled() {
+		// There are no symlinks in a bucket
+		return true
+	}
+	requested = path.Clean(requested)
+	target, err := filesystem.EvaluateFilePath(GetConfig().Repository, requested, SymlinkAlias)
+	if err != nil || target == requested {
+		return true
+	}
+	if status := GetConfig().EmbargoStatus(target); status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return false
+	}
+	// The signature of the requested path was already checked if both
+	// paths share the same secret
+	if secret := GetConfig().SigningSecret(target); secret != "" && secret != GetConfig().SigningSecret(requested) {
+		q := r.URL.Query()
+		if !utils.CheckPathSignature(secret, target, q.Get("expires"), q.Get("signature"), time.Now()) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return false
+		}
+	}
+	return true
+}
+
 // MirrorStats contains the stats of a given mirror
 type MirrorStats struct {
 	ID         int
@@ -483,6 +992,8 @@ type MirrorStats struct {
 	PercentB   float32
 	SyncOffset SyncOffset
 	TZOffset   time.Duration
+	Errors     int64
+	ErrorCodes string
 }
 
 // SyncOffset contains the time offset between the mirror and the local repository
@@ -498,6 +1009,7 @@ type MirrorStatsPage struct {
 	MirrorList       []mirrors.Mirror
 	LocalJSPath      string
 	HasTZAdjustement bool
+	HasErrors        bool
 }
 
 // byDownloadNumbers is a sorting function
@@ -553,6 +1065,7 @@ func (h *HTTP) mirrorStatsHandler(w http.ResponseWriter, r *http.Request, ctx *C
 	}
 
 	var hasTZAdjustement bool
+	var hasErrors bool
 	var maxdownloads int64
 	var maxbytes int64
 	var results []MirrorStats
@@ -606,6 +1119,12 @@ func (h *HTTP) mirrorStatsHandler(w http.ResponseWriter, r *http.Request, ctx *C
 			},
 			TZOffset: tzoffset,
 		}
+		window := time.Duration(GetConfig().HTTPErrorsWindow) * time.Hour
+		if httpErrors, err := mirrors.GetHTTPErrors(h.redis, id, window); err == nil && httpErrors.Total() > 0 {
+			s.Errors = httpErrors.Total()
+			s.ErrorCodes = httpErrors.String()
+			hasErrors = true
+		}
 		results = append(results, s)
 		index += 2
 	}
@@ -618,10 +1137,46 @@ func (h *HTTP) mirrorStatsHandler(w http.ResponseWriter, r *http.Request, ctx *C
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	err = ctx.Templates().mirrorstats.ExecuteTemplate(w, "base", MirrorStatsPage{results, mlist, GetConfig().LocalJSPath, hasTZAdjustement})
+	err = ctx.Templates().mirrorstats.ExecuteTemplate(w, "base", MirrorStatsPage{results, mlist, GetConfig().LocalJSPath, hasTZAdjustement, hasErrors})
 	if err != nil {
 		log.Errorf("HTTP error: %s", err.Error())
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
+
+func (h *HTTP) propagationHandler(w http.ResponseWriter, r *http.Request, ctx *Context) {
+	var output []byte
+
+	page := requestedPage(r)
+	if page == 0 {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	files, pages, err := h.propagation.get(h.redis, h.cache, r.URL.Path, page)
+	if err != nil {
+		http.Error(w, "Cannot compute the propagation", http.StatusInternalServerError)
+		return
+	}
+	if page > pages {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	if page < pages {
+		w.Header().Set("Link", fmt.Sprintf("<%s?propagation&page=%d>; rel=\"next\"", utils.EscapePath(r.URL.Path), page+1))
+	}
+
+	if ctx.IsPretty() {
+		output, err = json.MarshalIndent(files, "", "    ")
+	} else {
+		output, err = json.Marshal(files)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(output)
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/filesystem"
+	"github.com/etix/mirrorbits/mirrors"
+	"github.com/etix/mirrorbits/network"
+	. "github.com/etix/mirrorbits/testing"
+)
+
+// staticEngine returns the same selection for all the requests
+type staticEngine struct {
+	mlist    mirrors.Mirrors
+	excluded mirrors.Mirrors
+	err      error
+}
+
+func (e staticEngine) Selection(ctx *Context, cache *mirrors.Cache, fileInfo *filesystem.FileInfo, clientInfo network.GeoIPRecord) (mirrors.Mirrors, mirrors.Mirrors, error) {
+	return e.mlist, e.excluded, e.err
+}
+
+func prepareMirrorHandlerTest(t *testing.T, engine mirrorSelection) (*HTTP, func()) {
+	repository, err := ioutil.TempDir("", "mirrorbits")
+	if err != nil {
+		t.Fatalf("Unable to create the repository: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(repository, "file.tgz"), []byte("content"), 0644); err != nil {
+		t.Fatalf("Unable to create the file: %s", err)
+	}
+
+	SetConfiguration(&Configuration{
+		Repository: repository,
+		OutputMode: "auto",
+	})
+
+	_, conn := PrepareRedisTest()
+	h := &HTTP{
+		geoip:  network.NewGeoIP(),
+		redis:  conn,
+		engine: engine,
+	}
+	return h, func() {
+		os.RemoveAll(repository)
+	}
+}
+
+func TestMirrorHandler_notPropagated(t *testing.T) {
+	h, cleanup := prepareMirrorHandlerTest(t, staticEngine{
+		excluded: mirrors.Mirrors{{ID: 1, Name: "m1"}},
+		err:      ErrNotPropagated,
+	})
+	defer cleanup()
+
+	// The file is served directly
+	r := httptest.NewRequest("GET", "/file.tgz", nil)
+	w := httptest.NewRecorder()
+	h.mirrorHandler(w, r, NewContext(w, r, Templates{}))
+	if w.Code != http.StatusOK || w.Body.String() != "content" {
+		t.Fatalf("Expected the local file, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The details are returned without any mirror
+	r = httptest.NewRequest("GET", "/file.tgz", nil)
+	r.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	h.mirrorHandler(w, r, NewContext(w, r, Templates{}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected the JSON details, got %d: %s", w.Code, w.Body.String())
+	}
+	var results mirrors.Results
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Invalid JSON output: %s", err)
+	}
+	if len(results.MirrorList) != 0 || len(results.ExcludedList) != 1 {
+		t.Fatalf("Expected no mirror and one excluded mirror, got %v and %v", results.MirrorList, results.ExcludedList)
+	}
+}
+
+func TestMirrorHandler_propagated(t *testing.T) {
+	h, cleanup := prepareMirrorHandlerTest(t, staticEngine{
+		mlist: mirrors.Mirrors{{ID: 1, Name: "m1", HttpURL: "http://m1.example.org/"}},
+	})
+	defer cleanup()
+	h.stats = &Stats{countChan: make(chan countItem, 1)}
+
+	r := httptest.NewRequest("GET", "/file.tgz", nil)
+	w := httptest.NewRecorder()
+	h.mirrorHandler(w, r, NewContext(w, r, Templates{}))
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "http://m1.example.org/file.tgz" {
+		t.Fatalf("Expected a redirection to the mirror, got %d to %s", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestRedirectRenderer(t *testing.T) {
+	c := &Configuration{MaxLinkHeaders: 2}
+	SetConfiguration(c)
+
+	results := &mirrors.Results{
+		FileInfo: filesystem.FileInfo{Path: "/releases/project 2.0.iso"},
+		MirrorList: mirrors.Mirrors{
+			{ID: 1, HttpURL: "http://m1.example.org/", CountryCodes: mirrors.CountryList{"FR"}},
+			{ID: 2, HttpURL: "http://m2.example.org", CountryCodes: mirrors.CountryList{"DE", "AT"}},
+			{ID: 3, HttpURL: "http://m3.example.org/project/", CountryCodes: mirrors.CountryList{"US"}},
+			{ID: 4, HttpURL: "http://m4.example.org/", CountryCodes: mirrors.CountryList{"JP"}},
+		},
+	}
+
+	r := httptest.NewRequest("GET", "/releases/project%202.0.iso", nil)
+	w := httptest.NewRecorder()
+
+	status, err := (&RedirectRenderer{}).Write(NewContext(w, r, Templates{}), results)
+	if err != nil || status != http.StatusFound {
+		t.Fatalf("Expected a redirect, got %d (%v)", status, err)
+	}
+	if location := w.Header().Get("Location"); location != "http://m1.example.org/releases/project%202.0.iso" {
+		t.Fatalf("Unexpected location %s", location)
+	}
+
+	expected := []string{
+		"<http://m2.example.org/releases/project%202.0.iso>; rel=duplicate; pri=1; geo=de",
+		"<http://m3.example.org/project/releases/project%202.0.iso>; rel=duplicate; pri=2; geo=us",
+	}
+	links := w.Header()["Link"]
+	if len(links) != len(expected) {
+		t.Fatalf("Expected %d links, got %v", len(expected), links)
+	}
+	for i := range expected {
+		if links[i] != expected[i] {
+			t.Fatalf("Expected %s, got %s", expected[i], links[i])
+		}
+	}
+}
+
+func BenchmarkRedirectRenderer(b *testing.B) {
+	c := &Configuration{MaxLinkHeaders: 10}
+	SetConfiguration(c)
+
+	results := &mirrors.Results{
+		FileInfo: filesystem.FileInfo{Path: "/releases/2.0/project-2.0-x86_64.iso"},
+	}
+	for i := 1; i <= 10; i++ {
+		results.MirrorList = append(results.MirrorList, mirrors.Mirror{
+			ID:           i,
+			HttpURL:      "http://mirror.example.org/project/",
+			CountryCodes: mirrors.CountryList{"FR", "DE"},
+		})
+	}
+
+	r := httptest.NewRequest("GET", "/releases/2.0/project-2.0-x86_64.iso", nil)
+	renderer := &RedirectRenderer{}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := httptest.NewRecorder()
+		renderer.Write(NewContext(w, r, Templates{}), results)
+	}
+}
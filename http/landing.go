@@ -0,0 +1,159 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/mirrors"
+	"github.com/gomodule/redigo/redis"
+)
+
+// signatureExtensions are the extensions of the detached signatures looked up
+// for the landing page
+var signatureExtensions = []string{".sig", ".asc", ".gpg"}
+
+// LandingPage is the data passed to the landing page template
+type LandingPage struct {
+	*mirrors.Results
+	Lang          string
+	DownloadURL   string
+	SignaturePath string
+}
+
+// LandingPageRenderer is used to render a download page showing the selected
+// mirror, the hashes of the file and the list of mirrors
+type LandingPageRenderer struct {
+	SignaturePath string
+}
+
+// Type returns the type of renderer
+func (w *LandingPageRenderer) Type() string {
+	return "LANDING"
+}
+
+// Write is used to write the result to the ResponseWriter
+func (w *LandingPageRenderer) Write(ctx *Context, results *mirrors.Results) (statusCode int, err error) {
+	if len(results.MirrorList) == 0 {
+		http.NotFound(ctx.ResponseWriter(), ctx.Request())
+		return http.StatusNotFound, nil
+	}
+
+	lang := negotiateLanguage(ctx.QueryParam("lang"), ctx.Request().Header.Get("Accept-Language"), ctx.Templates().landing)
+	tmpl := ctx.Templates().landing[lang]
+	if tmpl == nil {
+		return http.StatusInternalServerError, ErrTemplatesNotFound
+	}
+
+	page := LandingPage{
+		Results:       results,
+		Lang:          lang,
+		DownloadURL:   results.MirrorList[0].FileURL(results.FileInfo.Path),
+		SignaturePath: w.SignaturePath,
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.ExecuteTemplate(&buf, "base", page)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	ctx.ResponseWriter().Header().Set("Content-Type", "text/html; charset=utf-8")
+	ctx.ResponseWriter().Header().Set("Vary", "Accept-Language")
+	buf.WriteTo(ctx.ResponseWriter())
+	return http.StatusOK, nil
+}
+
+// negotiateLanguage returns the language of the landing page to use based on
+// the lang query parameter or the Accept-Language header, the empty string
+// being the default language
+func negotiateLanguage(query, acceptLanguage string, available map[string]*template.Template) string {
+	match := func(tag string) (string, bool) {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if _, ok := available[tag]; ok && tag != "" {
+			return tag, true
+		}
+		if i := strings.IndexAny(tag, "-_"); i > 0 {
+			if _, ok := available[tag[:i]]; ok {
+				return tag[:i], true
+			}
+		}
+		return "", false
+	}
+
+	if lang, ok := match(query); ok {
+		return lang
+	}
+
+	type weightedTag struct {
+		tag string
+		q   float64
+	}
+	var tags []weightedTag
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		t := weightedTag{tag: fields[0], q: 1}
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				if q, err := strconv.ParseFloat(f[2:], 64); err == nil {
+					t.q = q
+				}
+			}
+		}
+		if t.q > 0 {
+			tags = append(tags, t)
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	for _, t := range tags {
+		if lang, ok := match(t.tag); ok {
+			return lang
+		}
+	}
+	return ""
+}
+
+// loadLandingTemplates loads the default landing page template along with
+// the translated ones
+func (h *HTTP) loadLandingTemplates() (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template)
+	t, err := h.LoadTemplates("landing")
+	if err != nil {
+		return nil, err
+	}
+	templates[""] = t
+	for _, lang := range GetConfig().LandingPageLanguages {
+		t, err := h.LoadTemplates("landing." + lang)
+		if err != nil {
+			return nil, err
+		}
+		templates[lang] = t
+	}
+	return templates, nil
+}
+
+// signaturePath returns the path of the detached signature of the given file
+// if any
+func (h *HTTP) signaturePath(filePath string) string {
+	conn := h.redis.Get()
+	defer conn.Close()
+
+	for _, ext := range signatureExtensions {
+		exists, err := redis.Bool(conn.Do("SISMEMBER", "FILES", filePath+ext))
+		if err == nil && exists {
+			return filePath + ext
+		}
+	}
+	return ""
+}
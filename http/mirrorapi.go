@@ -0,0 +1,111 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/etix/mirrorbits/core"
+	"github.com/etix/mirrorbits/mirrors"
+)
+
+// MirrorAPIReply is the reply sent to the self-service API of the mirrors
+type MirrorAPIReply struct {
+	Mirror string `json:",omitempty"`
+	Action string `json:",omitempty"`
+	Error  string `json:",omitempty"`
+}
+
+// mirrorAPIHandler lets the admins of a mirror trigger a scan, put their
+// mirror in maintenance or update its sponsor using the API key of the mirror
+func (h *HTTP) mirrorAPIHandler(w http.ResponseWriter, r *http.Request, ctx *Context) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeMirrorAPIReply(w, http.StatusUnauthorized, MirrorAPIReply{Error: "missing API key"})
+		return
+	}
+
+	id, err := mirrors.MirrorFromAPIKey(h.redis, strings.TrimPrefix(auth, "Bearer "))
+	if err == mirrors.ErrInvalidAPIKey {
+		writeMirrorAPIReply(w, http.StatusUnauthorized, MirrorAPIReply{Error: err.Error()})
+		return
+	} else if err != nil {
+		log.Errorf("Unable to check the API key: %s", err)
+		writeMirrorAPIReply(w, http.StatusInternalServerError, MirrorAPIReply{Error: "internal error"})
+		return
+	}
+
+	mirror, err := h.cache.GetMirror(id)
+	if err != nil {
+		log.Errorf("Unable to fetch mirror %d: %s", id, err)
+		writeMirrorAPIReply(w, http.StatusInternalServerError, MirrorAPIReply{Error: "internal error"})
+		return
+	}
+
+	action := ctx.QueryParam("mirrorapi")
+	reply := MirrorAPIReply{
+		Mirror: mirror.Name,
+		Action: action,
+	}
+
+	switch action {
+	case "scan":
+		if !mirror.Enabled {
+			reply.Error = "the mirror is disabled"
+			writeMirrorAPIReply(w, http.StatusConflict, reply)
+			return
+		}
+		err = mirrors.RequestScan(h.redis, id, core.ScanSourceAPI)
+	case "maintenance":
+		err = mirrors.SetMaintenance(h.redis, id, true)
+	case "resume":
+		err = mirrors.SetMaintenance(h.redis, id, false)
+	case "sponsor":
+		if err := r.ParseForm(); err != nil {
+			reply.Error = "invalid form"
+			writeMirrorAPIReply(w, http.StatusBadRequest, reply)
+			return
+		}
+		err = mirrors.UpdateSponsor(h.redis, id,
+			r.PostForm.Get("SponsorName"),
+			r.PostForm.Get("SponsorURL"),
+			r.PostForm.Get("SponsorLogoURL"))
+		if err == mirrors.ErrInvalidSponsorURL {
+			reply.Error = err.Error()
+			writeMirrorAPIReply(w, http.StatusBadRequest, reply)
+			return
+		}
+	default:
+		reply.Error = "unknown action, expected scan, maintenance, resume or sponsor"
+		writeMirrorAPIReply(w, http.StatusBadRequest, reply)
+		return
+	}
+
+	if err != nil {
+		log.Errorf("Unable to %s mirror %s: %s", action, mirror.Name, err)
+		reply.Error = "internal error"
+		writeMirrorAPIReply(w, http.StatusInternalServerError, reply)
+		return
+	}
+
+	log.Noticef("Mirror %s: %s requested through the API", mirror.Name, action)
+
+	writeMirrorAPIReply(w, http.StatusOK, reply)
+}
+
+func writeMirrorAPIReply(w http.ResponseWriter, status int, reply MirrorAPIReply) {
+	output, _ := json.Marshal(reply)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(output)
+}
@@ -11,7 +11,6 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
-	"strings"
 
 	. "github.com/etix/mirrorbits/config"
 	"github.com/etix/mirrorbits/mirrors"
@@ -72,8 +71,6 @@ func (w *RedirectRenderer) Write(ctx *Context, results *mirrors.Results) (status
 	if len(results.MirrorList) > 0 {
 		ctx.ResponseWriter().Header().Set("Content-Type", "text/html; charset=utf-8")
 
-		path := strings.TrimPrefix(results.FileInfo.Path, "/")
-
 		mh := len(results.MirrorList)
 		maxheaders := GetConfig().MaxLinkHeaders
 		if mh > maxheaders+1 {
@@ -81,18 +78,31 @@ func (w *RedirectRenderer) Write(ctx *Context, results *mirrors.Results) (status
 		}
 
 		if mh >= 1 {
-			// Generate the header alternative links
-			for i, m := range results.MirrorList[1:mh] {
-				var countryCode string
-				if len(m.CountryFields) > 0 {
-					countryCode = strings.ToLower(m.CountryFields[0])
-				}
-				ctx.ResponseWriter().Header().Add("Link", fmt.Sprintf("<%s>; rel=duplicate; pri=%d; geo=%s", m.HttpURL+path, i+1, countryCode))
+			// Generate the header alternative links, reusing the same buffer
+			// to allocate the links only
+			header := ctx.ResponseWriter().Header()
+			links := header["Link"]
+			var buf []byte
+			for i := 1; i < mh; i++ {
+				m := &results.MirrorList[i]
+				buf = append(buf[:0], '<')
+				buf = m.AppendFileURL(buf, results.FileInfo.Path)
+				buf = append(buf, ">; rel=duplicate; pri="...)
+				buf = strconv.AppendInt(buf, int64(i), 10)
+				buf = append(buf, "; geo="...)
+				buf = appendLower(buf, m.CountryCodes.Primary())
+				links = append(links, string(buf))
 			}
+			header["Link"] = links
+		}
+
+		// Advertise the torrent as described in RFC 6249
+		if results.TorrentPath != "" {
+			ctx.ResponseWriter().Header().Add("Link", fmt.Sprintf("<%s>; rel=describedby; type=\"application/x-bittorrent\"", results.TorrentPath))
 		}
 
 		// Finally issue the redirect
-		http.Redirect(ctx.ResponseWriter(), ctx.Request(), results.MirrorList[0].HttpURL+path, http.StatusFound)
+		http.Redirect(ctx.ResponseWriter(), ctx.Request(), results.MirrorList[0].FileURL(results.FileInfo.Path), http.StatusFound)
 		return http.StatusFound, nil
 	}
 	// No mirror returned for this request
@@ -133,3 +143,15 @@ func (w *MirrorListRenderer) Write(ctx *Context, results *mirrors.Results) (stat
 	buf.WriteTo(ctx.ResponseWriter())
 	return http.StatusOK, nil
 }
+
+// appendLower appends the ASCII string s in lower case to b
+func appendLower(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		b = append(b, c)
+	}
+	return b
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/core"
+	"github.com/etix/mirrorbits/mirrors"
+)
+
+const (
+	// Remove the expired results once the cache reaches this size
+	probeCacheCleanup = 10000
+)
+
+var (
+	// probeTransport reaches the mirrors through the proxy given in the
+	// context of the request (see OutboundProxy)
+	probeTransport = &http.Transport{
+		Proxy: mirrors.ProxyFromContext,
+	}
+)
+
+// probeCache holds the recent results of the probes of the mirrors done
+// before redirecting to the critical files (see FirstByteProbe)
+type probeCache struct {
+	sync.Mutex
+	entries  map[string]probeEntry
+	inflight map[string]*probeCall
+}
+
+type probeEntry struct {
+	err     error
+	expires time.Time
+}
+
+// probeCall is a probe in progress shared by the concurrent requests for
+// the same URL
+type probeCall struct {
+	done chan struct{}
+	err  error
+}
+
+// probe sends a HEAD request for the given URL through the given proxy (nil
+// for a direct connection), or returns the cached result of a recent probe.
+// Concurrent probes of the same URL are collapsed into a single request.
+func (p *probeCache) probe(fileURL string, proxy *url.URL) error {
+	now := time.Now()
+
+	p.Lock()
+	entry, ok := p.entries[fileURL]
+	if ok && now.Before(entry.expires) {
+		p.Unlock()
+		return entry.err
+	}
+	call, running := p.inflight[fileURL]
+	if !running {
+		if p.inflight == nil {
+			p.inflight = make(map[string]*probeCall)
+		}
+		call = &probeCall{
+			done: make(chan struct{}),
+		}
+		p.inflight[fileURL] = call
+	}
+	p.Unlock()
+
+	if running {
+		<-call.done
+		return call.err
+	}
+
+	call.err = probeURL(fileURL, proxy, time.Duration(GetConfig().FirstByteProbe.Timeout)*time.Millisecond)
+	p.store(fileURL, call.err, now)
+
+	p.Lock()
+	delete(p.inflight, fileURL)
+	p.Unlock()
+	close(call.done)
+
+	return call.err
+}
+
+// store caches the result of the probe of the given URL
+func (p *probeCache) store(url string, err error, now time.Time) {
+	p.Lock()
+	defer p.Unlock()
+	if p.entries == nil {
+		p.entries = make(map[string]probeEntry)
+	}
+	if len(p.entries) >= probeCacheCleanup {
+		for k, e := range p.entries {
+			if now.After(e.expires) {
+				delete(p.entries, k)
+			}
+		}
+	}
+	if ttl := GetConfig().FirstByteProbe.CacheTTL; ttl > 0 {
+		p.entries[url] = probeEntry{
+			err:     err,
+			expires: now.Add(time.Duration(ttl) * time.Second),
+		}
+	}
+}
+
+func probeURL(fileURL string, proxy *url.URL, timeout time.Duration) error {
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: probeTransport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	req, err := http.NewRequest("HEAD", fileURL, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(context.WithValue(req.Context(), core.ContextProxy, proxy))
+	req.Header.Set("User-Agent", "Mirrorbits/"+core.VERSION+" PING CHECK")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
+
+// probeMirrors moves the first mirror answering for the file at the head of
+// the list, the mirrors failing the probe being excluded. Only the first
+// MaxProbes mirrors are probed, all at once, so the redirect is delayed by
+// one probe timeout at most. The list is left untouched if none of them
+// answers.
+func (p *probeCache) probeMirrors(filePath string, mlist, excluded mirrors.Mirrors) (mirrors.Mirrors, mirrors.Mirrors) {
+	n := len(mlist)
+	if max := GetConfig().FirstByteProbe.MaxProbes; n > max {
+		n = max
+	}
+
+	results := make([]chan error, n)
+	for i := 0; i < n; i++ {
+		results[i] = make(chan error, 1)
+		proxy, err := mlist[i].ProxyURL()
+		if err != nil {
+			results[i] <- err
+			continue
+		}
+		go func(fileURL string, proxy *url.URL, result chan<- error) {
+			result <- p.probe(fileURL, proxy)
+		}(mlist[i].FileURL(filePath), proxy, results[i])
+	}
+
+	// The mirrors are checked in order of preference, a mirror answering
+	// being selected without waiting for the probes of the next ones
+	for i := 0; i < n; i++ {
+		err := <-results[i]
+		if err == nil {
+			if i == 0 {
+				return mlist, excluded
+			}
+			excluded = append(excluded, mlist[:i]...)
+			return mlist[i:], excluded
+		}
+		counters.Add("probe_failures", 1)
+		log.Debugf("Probe of %s failed for %s: %s", mlist[i].Name, filePath, err)
+		mlist[i].ExcludeReason = "Probe failed: " + err.Error()
+	}
+	for i := 0; i < n; i++ {
+		mlist[i].ExcludeReason = ""
+	}
+	return mlist, excluded
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/mirrors"
+)
+
+func TestProbeMirrors(t *testing.T) {
+	c := &Configuration{}
+	c.FirstByteProbe.Timeout = 1000
+	c.FirstByteProbe.CacheTTL = 30
+	c.FirstByteProbe.MaxProbes = 3
+	SetConfiguration(c)
+
+	var requests int32
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.NotFound(w, r)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "HEAD" || r.URL.Path != "/dir/file.iso" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer up.Close()
+
+	var p probeCache
+	list := mirrors.Mirrors{
+		{ID: 1, Name: "down", HttpURL: down.URL + "/"},
+		{ID: 2, Name: "up", HttpURL: up.URL + "/"},
+	}
+
+	mlist, excluded := p.probeMirrors("/dir/file.iso", append(mirrors.Mirrors{}, list...), nil)
+	if len(mlist) != 1 || mlist[0].Name != "up" {
+		t.Fatalf("The mirror answering should be selected, got %v", mlist)
+	}
+	if len(excluded) != 1 || excluded[0].Name != "down" || excluded[0].ExcludeReason == "" {
+		t.Fatalf("The failing mirror should be excluded, got %v", excluded)
+	}
+
+	// The result of the probe is cached
+	p.probeMirrors("/dir/file.iso", append(mirrors.Mirrors{}, list...), nil)
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Fatalf("The failing mirror should have been probed once, got %d", requests)
+	}
+
+	// The list is kept if no mirror answers
+	mlist, excluded = p.probeMirrors("/dir/file.iso", append(mirrors.Mirrors{}, list[0]), nil)
+	if len(mlist) != 1 || len(excluded) != 0 || mlist[0].ExcludeReason != "" {
+		t.Fatalf("The list should be untouched, got %v and %v", mlist, excluded)
+	}
+}
+
+func TestProbeMirrors_maxProbes(t *testing.T) {
+	c := &Configuration{}
+	c.FirstByteProbe.Timeout = 1000
+	c.FirstByteProbe.MaxProbes = 2
+	SetConfiguration(c)
+
+	var requests int32
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.NotFound(w, r)
+	}))
+	defer down.Close()
+
+	var p probeCache
+	list := mirrors.Mirrors{
+		{ID: 1, Name: "m1", HttpURL: down.URL + "/m1/"},
+		{ID: 2, Name: "m2", HttpURL: down.URL + "/m2/"},
+		{ID: 3, Name: "m3", HttpURL: down.URL + "/m3/"},
+	}
+
+	mlist, excluded := p.probeMirrors("/file.iso", list, nil)
+	if len(mlist) != 3 || len(excluded) != 0 {
+		t.Fatalf("The list should be untouched, got %v and %v", mlist, excluded)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Fatalf("Only the first 2 mirrors should have been probed, got %d probes", n)
+	}
+}
+
+func TestProbeCache_collapse(t *testing.T) {
+	c := &Configuration{}
+	c.FirstByteProbe.Timeout = 1000
+	c.FirstByteProbe.CacheTTL = 30
+	SetConfiguration(c)
+
+	var requests int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+	}))
+	defer server.Close()
+
+	var p probeCache
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.probe(server.URL+"/file.iso", nil); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+		}()
+	}
+
+	// Wait for the probe to reach the server before letting it answer
+	for atomic.LoadInt32(&requests) == 0 {
+		runtime.Gosched()
+	}
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("The concurrent probes should have been collapsed, got %d probes", n)
+	}
+}
+
+func TestProbeMirrors_proxy(t *testing.T) {
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A proxy receives the absolute URL of the mirror
+		if r.URL.Host == "mirror.invalid" {
+			atomic.AddInt32(&proxied, 1)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer proxy.Close()
+
+	c := &Configuration{}
+	c.FirstByteProbe.Timeout = 1000
+	c.FirstByteProbe.MaxProbes = 3
+	c.OutboundProxy = proxy.URL
+	SetConfiguration(c)
+
+	var p probeCache
+	list := mirrors.Mirrors{
+		{ID: 1, Name: "global", HttpURL: "http://mirror.invalid/global/"},
+	}
+	mlist, excluded := p.probeMirrors("/file.iso", list, nil)
+	if len(mlist) != 1 || len(excluded) != 0 || mlist[0].ExcludeReason != "" {
+		t.Fatalf("The mirror should be probed through the global proxy, got %v and %v", mlist, excluded)
+	}
+
+	// The proxy of the mirror takes precedence
+	c.OutboundProxy = "http://127.0.0.1:1"
+	list = mirrors.Mirrors{
+		{ID: 2, Name: "own", HttpURL: "http://mirror.invalid/own/", Proxy: proxy.URL},
+	}
+	mlist, excluded = p.probeMirrors("/file.iso", list, nil)
+	if len(mlist) != 1 || len(excluded) != 0 || mlist[0].ExcludeReason != "" {
+		t.Fatalf("The mirror should be probed through its own proxy, got %v and %v", mlist, excluded)
+	}
+
+	if atomic.LoadInt32(&proxied) != 2 {
+		t.Fatalf("Expected 2 probes through the proxy, got %d", proxied)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/database"
+	"github.com/etix/mirrorbits/mirrors"
+)
+
+const (
+	// Number of files per page of the propagation report
+	propagationPageSize = 100
+	// Lifetime of the pages of the propagation report
+	propagationCacheTTL = time.Minute
+	// Maximum number of pages kept in cache
+	propagationCacheSize = 1000
+)
+
+// propagationCache holds the recently requested pages of the propagation
+// report, sparing the computation of the propagation of each file on every
+// request
+type propagationCache struct {
+	sync.Mutex
+	pages map[string]propagationPage
+}
+
+type propagationPage struct {
+	files   []mirrors.FilePropagation
+	pages   int
+	expires time.Time
+}
+
+// get returns the propagation of the files of the given page (starting at 1)
+// of the files found under the prefix, and the number of pages. The files
+// under embargo are not disclosed.
+func (p *propagationCache) get(r *database.Redis, c *mirrors.Cache, prefix string, page int) ([]mirrors.FilePropagation, int, error) {
+	key := fmt.Sprintf("%d %s", page, prefix)
+	now := time.Now()
+
+	p.Lock()
+	cached, ok := p.pages[key]
+	p.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.files, cached.pages, nil
+	}
+
+	files, err := c.GetFiles(prefix)
+	if err != nil {
+		return nil, 0, err
+	}
+	visible := files[:0]
+	for _, f := range files {
+		if GetConfig().EmbargoStatus(f) == 0 {
+			visible = append(visible, f)
+		}
+	}
+
+	files, pages := paginate(visible, page, propagationPageSize)
+	result := []mirrors.FilePropagation{}
+	if len(files) > 0 {
+		result, err = mirrors.GetFilesPropagation(r, c, files)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	p.Lock()
+	if p.pages == nil || len(p.pages) >= propagationCacheSize {
+		p.pages = make(map[string]propagationPage)
+	}
+	p.pages[key] = propagationPage{
+		files:   result,
+		pages:   pages,
+		expires: now.Add(propagationCacheTTL),
+	}
+	p.Unlock()
+
+	return result, pages, nil
+}
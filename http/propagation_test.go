@@ -0,0 +1,53 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"testing"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/mirrors"
+	. "github.com/etix/mirrorbits/testing"
+)
+
+func TestPropagationCache(t *testing.T) {
+	SetConfiguration(&Configuration{})
+
+	mock, conn := PrepareRedisTest()
+	conn.ConnectPubsub()
+	cache := mirrors.NewCache(conn)
+
+	mock.Command("SMEMBERS", "FILES").Expect([]interface{}{
+		[]byte("/release/file.tgz"),
+		[]byte("/nightly/file.tgz"),
+	})
+	cmdMirrors := mock.Command("HKEYS", "MIRRORS").Expect([]interface{}{})
+	mock.Command("HMGET", "FILE_/release/file.tgz", "size", "modTime", "sha1", "sha256", "md5").Expect([]interface{}{
+		[]byte("42"),
+		[]byte(""),
+		[]byte(""),
+		[]byte(""),
+		[]byte(""),
+	})
+	mock.Command("SMEMBERS", "FILEMIRRORS_/release/file.tgz").Expect([]interface{}{})
+
+	var p propagationCache
+	for i := 0; i < 2; i++ {
+		files, pages, err := p.get(conn, cache, "/release/", 1)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if pages != 1 || len(files) != 1 || files[0].Path != "/release/file.tgz" {
+			t.Fatalf("Unexpected propagation: %v (%d pages)", files, pages)
+		}
+	}
+	if mock.Stats(cmdMirrors) != 1 {
+		t.Fatalf("The page is expected to be cached")
+	}
+
+	files, pages, err := p.get(conn, cache, "/release/", 2)
+	if err != nil || pages != 1 || len(files) != 0 {
+		t.Fatalf("Expected an empty page past the end, got %v (%d pages)", files, pages)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/mirrors"
+	"github.com/etix/mirrorbits/network"
+)
+
+// maxRegistrationSize is the maximum size of a registration request body
+const maxRegistrationSize = 64 * 1024
+
+// RegistrationReply is the reply sent to a mirror registration
+type RegistrationReply struct {
+	ID    int    `json:",omitempty"`
+	Error string `json:",omitempty"`
+}
+
+// registerHandler stores a mirror submitted by its administrator, either as
+// a form or as a json document, into the review queue
+func (h *HTTP) registerHandler(w http.ResponseWriter, r *http.Request, ctx *Context) {
+	if !GetConfig().MirrorRegistration {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRegistrationSize)
+
+	var pending mirrors.PendingMirror
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&pending); err != nil {
+			writeRegistrationReply(w, http.StatusBadRequest, RegistrationReply{Error: "invalid json document"})
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			writeRegistrationReply(w, http.StatusBadRequest, RegistrationReply{Error: "invalid form"})
+			return
+		}
+		pending = mirrors.PendingMirror{
+			Name:        r.PostForm.Get("Name"),
+			HttpURL:     r.PostForm.Get("HttpURL"),
+			RsyncURL:    r.PostForm.Get("RsyncURL"),
+			FtpURL:      r.PostForm.Get("FtpURL"),
+			SponsorName: r.PostForm.Get("SponsorName"),
+			SponsorURL:  r.PostForm.Get("SponsorURL"),
+			AdminName:   r.PostForm.Get("AdminName"),
+			AdminEmail:  r.PostForm.Get("AdminEmail"),
+			Comment:     r.PostForm.Get("Comment"),
+		}
+	}
+
+	if err := pending.Validate(); err != nil {
+		writeRegistrationReply(w, http.StatusBadRequest, RegistrationReply{Error: err.Error()})
+		return
+	}
+
+	pending.RemoteIP = network.ExtractRemoteIP(r.Header.Get("X-Forwarded-For"))
+	if len(pending.RemoteIP) == 0 {
+		pending.RemoteIP = network.RemoteIPFromAddr(r.RemoteAddr)
+	}
+	pending.Submitted = time.Now().UTC()
+
+	id, err := mirrors.AddPendingMirror(h.redis, &pending)
+	if err == mirrors.ErrPendingQueueFull {
+		writeRegistrationReply(w, http.StatusServiceUnavailable, RegistrationReply{Error: err.Error()})
+		return
+	} else if err != nil {
+		log.Errorf("Unable to store the registration of %s: %s", pending.Name, err)
+		writeRegistrationReply(w, http.StatusInternalServerError, RegistrationReply{Error: "unable to store the registration"})
+		return
+	}
+
+	log.Noticef("Mirror %s submitted for review (pending #%d)", pending.Name, id)
+
+	writeRegistrationReply(w, http.StatusAccepted, RegistrationReply{ID: id})
+}
+
+func writeRegistrationReply(w http.ResponseWriter, status int, reply RegistrationReply) {
+	output, _ := json.Marshal(reply)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(output)
+}
+
+// verifyContactHandler confirms the admin email of a mirror using the token
+// sent by email
+func (h *HTTP) verifyContactHandler(w http.ResponseWriter, r *http.Request, ctx *Context) {
+	token := ctx.QueryParam("verifycontact")
+	if _, err := hex.DecodeString(token); err != nil || len(token) != 32 {
+		http.Error(w, mirrors.ErrInvalidContactToken.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := mirrors.ConfirmContactToken(h.redis, token)
+	if err == mirrors.ErrInvalidContactToken {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Errorf("Unable to verify the contact: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	name := strconv.Itoa(id)
+	if mirror, err := h.cache.GetMirror(id); err == nil {
+		name = mirror.Name
+	}
+
+	log.Noticef("Contact of mirror %s verified", name)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "Thank you, the contact of the mirror %s has been verified.\n", name)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/mirrors"
+	"github.com/gomodule/redigo/redis"
+)
+
+// errorReportHandler records the HTTP error returned by a mirror to a
+// client, the mirror being found from the trace of the request
+func (h *HTTP) errorReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.FormValue("request_id")
+	status, err := strconv.Atoi(r.FormValue("status"))
+	if !validRequestID(id) || err != nil || status < 400 || status > 599 {
+		http.Error(w, "A request_id and an error status are required", http.StatusBadRequest)
+		return
+	}
+
+	trace, err := mirrors.GetTrace(h.redis, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if trace == nil || len(trace.Mirrors) == 0 || trace.Fallback {
+		http.Error(w, "Unknown request", http.StatusNotFound)
+		return
+	}
+
+	mirrorsMap, err := h.redis.GetListOfMirrors()
+	if err != nil {
+		http.Error(w, "Cannot fetch the list of mirrors", http.StatusInternalServerError)
+		return
+	}
+	mirrorID := 0
+	for mid, name := range mirrorsMap {
+		if name == trace.Mirrors[0] {
+			mirrorID = mid
+			break
+		}
+	}
+	if mirrorID == 0 {
+		http.Error(w, "Unknown request", http.StatusNotFound)
+		return
+	}
+
+	// Each request can only be reported once
+	retention := time.Duration(GetConfig().RequestTraceRetention) * time.Minute
+	conn := h.redis.Get()
+	defer conn.Close()
+	_, err = redis.String(conn.Do("SET", "REPORTED_"+id, 1, "NX", "EX", int(retention.Seconds())))
+	if err == redis.ErrNil {
+		http.Error(w, "Request already reported", http.StatusConflict)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	window := time.Duration(GetConfig().HTTPErrorsWindow) * time.Hour
+	if err := mirrors.RecordHTTPError(h.redis, mirrorID, mirrors.ErrorSourceClient, status, window); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Noticef("Error %d reported for %s on request %s", status, trace.Mirrors[0], id)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -4,7 +4,9 @@
 package http
 
 import (
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"math"
 	"math/rand"
 	"sort"
@@ -13,56 +15,155 @@ import (
 
 	. "github.com/etix/mirrorbits/config"
 	"github.com/etix/mirrorbits/filesystem"
+	"github.com/etix/mirrorbits/hooks"
 	"github.com/etix/mirrorbits/mirrors"
 	"github.com/etix/mirrorbits/network"
+	"github.com/etix/mirrorbits/tracing"
 	"github.com/etix/mirrorbits/utils"
 )
 
+var (
+	// ErrNotPropagated is returned when the file is not yet available on enough mirrors
+	ErrNotPropagated = errors.New("file not yet propagated to enough mirrors")
+)
+
 type mirrorSelection interface {
 	// Selection must return an ordered list of selected mirror,
 	// a list of rejected mirrors and and an error code.
 	Selection(*Context, *mirrors.Cache, *filesystem.FileInfo, network.GeoIPRecord) (mirrors.Mirrors, mirrors.Mirrors, error)
 }
 
+// Randomizer is the source of randomness of the selection
+type Randomizer interface {
+	Intn(n int) int
+	Int31n(n int32) int32
+}
+
+// globalRandomizer uses the global source of math/rand
+type globalRandomizer struct{}
+
+func (globalRandomizer) Intn(n int) int       { return rand.Intn(n) }
+func (globalRandomizer) Int31n(n int32) int32 { return rand.Int31n(n) }
+
 // DefaultEngine is the default algorithm used for mirror selection
-type DefaultEngine struct{}
+type DefaultEngine struct {
+	// Rand is the source of randomness, the global source of math/rand is
+	// used if nil. It is ignored when SelectionSeed is set.
+	Rand Randomizer
+
+	// hot detects the hot files, whose candidate mirrors are precomputed
+	// (see HotFiles). Disabled if nil.
+	hot *hotFileDetector
+}
+
+// randomizer returns the source of randomness for the given request. When
+// a SelectionSeed is configured the source only depends on the seed, the
+// file and the client, so that identical requests select the same mirrors.
+func (h DefaultEngine) randomizer(ctx *Context, fileInfo *filesystem.FileInfo) Randomizer {
+	if seed := GetConfig().SelectionSeed; seed != 0 {
+		hash := fnv.New64a()
+		hash.Write([]byte(fileInfo.Path))
+		hash.Write(ctx.remoteIP)
+		return rand.New(rand.NewSource(seed ^ int64(hash.Sum64())))
+	}
+	if h.Rand != nil {
+		return h.Rand
+	}
+	return globalRandomizer{}
+}
 
 // Selection returns an ordered list of selected mirror, a list of rejected mirrors and and an error code
 func (h DefaultEngine) Selection(ctx *Context, cache *mirrors.Cache, fileInfo *filesystem.FileInfo, clientInfo network.GeoIPRecord) (mlist mirrors.Mirrors, excluded mirrors.Mirrors, err error) {
+	rng := h.randomizer(ctx, fileInfo)
+
 	// Get details about the requested file
+	_, span := tracing.Start(ctx.Request().Context(), tracing.KindClient, "cache.GetFileInfo")
 	*fileInfo, err = cache.GetFileInfo(fileInfo.Path)
+	span.SetAttribute("db.system", "redis")
+	span.SetError(err)
+	span.End()
 	if err != nil {
 		return
 	}
 
-	// Prepare and return the list of all potential mirrors
-	mlist, err = cache.GetMirrors(fileInfo.Path, clientInfo)
-	if err != nil {
-		return
+	// The mirrors of the hot files excluded for all the clients of a
+	// continent are filtered out beforehand. Their order depends on the
+	// exact location of each client and on a random draw, it is computed
+	// per request below.
+	var prefiltered bool
+	var unavailable mirrors.Mirrors
+	var continent string
+	if clientInfo.IsValid() {
+		continent = clientInfo.ContinentCode
+	}
+	hot := !ctx.IsMirrorlist() && h.hot != nil && h.hot.hit(fileInfo.Path)
+	if hot {
+		mlist, unavailable, prefiltered = cache.GetCandidates(fileInfo.Path, continent)
+	}
+
+	if !prefiltered {
+		// Prepare and return the list of all potential mirrors
+		_, span = tracing.Start(ctx.Request().Context(), tracing.KindClient, "cache.GetMirrors")
+		if hot {
+			mlist, err = cache.GetFileMirrors(fileInfo.Path, continent)
+		} else {
+			mlist, err = cache.GetMirrors(fileInfo.Path, clientInfo)
+		}
+		span.SetAttribute("db.system", "redis")
+		span.SetError(err)
+		span.End()
+		if err != nil {
+			return
+		}
+	}
+
+	// Is the file available on enough mirrors to be redirected? The
+	// precomputed candidates are checked as well since the thresholds
+	// may have been reloaded since.
+	if !ctx.IsMirrorlist() && (GetConfig().MinimumMirrors > 0 || GetConfig().MinimumPropagation > 0) {
+		all := mlist
+		if prefiltered {
+			all = append(append(make(mirrors.Mirrors, 0, len(mlist)+len(unavailable)), mlist...), unavailable...)
+		}
+		var propagated bool
+		propagated, err = isPropagated(cache, all, fileInfo)
+		if err != nil {
+			return
+		}
+		if !propagated {
+			excluded = all
+			mlist = nil
+			err = ErrNotPropagated
+			return
+		}
+	}
+
+	if hot && !prefiltered {
+		mlist, unavailable = prefilter(mlist, fileInfo, clientInfo)
+		cache.SetCandidates(fileInfo.Path, continent, mlist, unavailable, time.Duration(GetConfig().HotFiles.Interval)*time.Second)
+		prefiltered = true
+	}
+
+	if prefiltered {
+		for i := range mlist {
+			mlist[i].Locate(clientInfo)
+		}
 	}
 
 	// Filter
 	safeIndex := 0
-	excluded = make([]mirrors.Mirror, 0, len(mlist))
+	located := 0
+	excluded = make([]mirrors.Mirror, 0, len(mlist)+len(unavailable))
+	excluded = append(excluded, unavailable...)
 	var closestMirror float32
 	var farthestMirror float32
+	now := time.Now()
 	for i, m := range mlist {
-		// Does it support http? Is it well formated?
-		if !strings.HasPrefix(m.HttpURL, "http://") && !strings.HasPrefix(m.HttpURL, "https://") {
-			m.ExcludeReason = "Invalid URL"
-			goto discard
-		}
-		// Is it enabled?
-		if !m.Enabled {
-			m.ExcludeReason = "Disabled"
-			goto discard
-		}
-		// Is it up?
-		if !m.Up {
-			if m.ExcludeReason == "" {
-				m.ExcludeReason = "Down"
+		if !prefiltered {
+			if reason := availabilityExclusion(&m); reason != "" {
+				m.ExcludeReason = reason
+				goto discard
 			}
-			goto discard
 		}
 		if ctx.SecureOption() == WITHTLS && !m.IsHTTPS() {
 			m.ExcludeReason = "Not HTTPS"
@@ -72,39 +173,46 @@ func (h DefaultEngine) Selection(ctx *Context, cache *mirrors.Cache, fileInfo *f
 			m.ExcludeReason = "Not HTTP"
 			goto discard
 		}
-		// Is it the same size / modtime as source?
-		if m.FileInfo != nil {
-			if m.FileInfo.Size != fileInfo.Size {
-				m.ExcludeReason = "File size mismatch"
-				goto discard
-			}
-			if !m.FileInfo.ModTime.IsZero() {
-				mModTime := m.FileInfo.ModTime
-				if GetConfig().FixTimezoneOffsets {
-					mModTime = mModTime.Add(time.Duration(m.TZOffset) * time.Millisecond)
-				}
-				mModTime = mModTime.Truncate(m.LastSuccessfulSyncPrecision.Duration())
-				lModTime := fileInfo.ModTime.Truncate(m.LastSuccessfulSyncPrecision.Duration())
-				if !mModTime.Equal(lModTime) {
-					m.ExcludeReason = fmt.Sprintf("Mod time mismatch (diff: %s)", lModTime.Sub(mModTime))
-					goto discard
-				}
-			}
+		// Is it reachable over the IP family of the client?
+		if ctx.IsIPv6() && m.IPv4 && !m.IPv6 {
+			m.ExcludeReason = "IPv4 only"
+			goto discard
 		}
-		// Is it configured to serve its continent only?
-		if m.ContinentOnly {
-			if !clientInfo.IsValid() || clientInfo.ContinentCode != m.ContinentCode {
-				m.ExcludeReason = "Continent only"
+		if ctx.IsIPv4() && m.IPv6 && !m.IPv4 {
+			m.ExcludeReason = "IPv6 only"
+			goto discard
+		}
+		if !prefiltered {
+			if reason := fileExclusion(&m, fileInfo, clientInfo); reason != "" {
+				m.ExcludeReason = reason
 				goto discard
 			}
 		}
 		// Is it configured to serve its country only?
 		if m.CountryOnly {
-			if !clientInfo.IsValid() || !utils.IsInSlice(clientInfo.CountryCode, m.CountryFields) {
+			if !clientInfo.IsValid() || !utils.IsInSlice(clientInfo.CountryCode, m.CountryCodes) {
 				m.ExcludeReason = "Country only"
 				goto discard
 			}
 		}
+		// Is it configured to serve its region only?
+		if m.RegionOnly {
+			if !clientInfo.IsValid() || !m.InRegion(clientInfo.RegionCode) {
+				m.ExcludeReason = "Region only"
+				goto discard
+			}
+		}
+		// Does it accept this request at this time of the day?
+		if share := m.Schedule.Share(now); share < 100 {
+			if share <= 0 {
+				m.ExcludeReason = "Outside its schedule"
+				goto discard
+			}
+			if !ctx.IsMirrorlist() && rng.Intn(100) >= share {
+				m.ExcludeReason = fmt.Sprintf("Traffic shaping (%d%%)", share)
+				goto discard
+			}
+		}
 		// Is it in the same AS number?
 		if m.ASOnly {
 			if !clientInfo.IsValid() || clientInfo.ASNum != m.Asnum {
@@ -113,18 +221,10 @@ func (h DefaultEngine) Selection(ctx *Context, cache *mirrors.Cache, fileInfo *f
 			}
 		}
 		// Is the user's country code allowed on this mirror?
-		if clientInfo.IsValid() && utils.IsInSlice(clientInfo.CountryCode, m.ExcludedCountryFields) {
+		if clientInfo.IsValid() && utils.IsInSlice(clientInfo.CountryCode, m.ExcludedCountryCodes) {
 			m.ExcludeReason = "User's country restriction"
 			goto discard
 		}
-		if safeIndex == 0 {
-			closestMirror = m.Distance
-		} else if closestMirror > m.Distance {
-			closestMirror = m.Distance
-		}
-		if m.Distance > farthestMirror {
-			farthestMirror = m.Distance
-		}
 		mlist[safeIndex] = mlist[i]
 		safeIndex++
 		continue
@@ -135,11 +235,39 @@ func (h DefaultEngine) Selection(ctx *Context, cache *mirrors.Cache, fileInfo *f
 	// Reduce the slice to its new size
 	mlist = mlist[:safeIndex]
 
+	// Let the site-specific policies adjust the scores or veto mirrors
+	if hooks.Enabled() {
+		var vetoed mirrors.Mirrors
+		mlist, vetoed = runSelectionHooks(ctx, fileInfo, clientInfo, mlist)
+		excluded = append(excluded, vetoed...)
+	}
+
+	// Keep the mirrors of another continent as a last resort
+	if clientInfo.IsValid() {
+		var far mirrors.Mirrors
+		mlist, far = distanceExclusion(mlist, GetConfig().DistanceLimit(clientInfo.ContinentCode))
+		excluded = append(excluded, far...)
+	}
+
+	for _, m := range mlist {
+		// CDN mirrors have no fixed location
+		if m.CDN {
+			continue
+		}
+		if located == 0 || closestMirror > m.Distance {
+			closestMirror = m.Distance
+		}
+		if m.Distance > farthestMirror {
+			farthestMirror = m.Distance
+		}
+		located++
+	}
+
 	if !clientInfo.IsValid() {
 		// Shuffle the list
 		//XXX Should we use the fallbacks instead?
 		for i := range mlist {
-			j := rand.Intn(i + 1)
+			j := rng.Intn(i + 1)
 			mlist[i], mlist[j] = mlist[j], mlist[i]
 		}
 
@@ -163,23 +291,34 @@ func (h DefaultEngine) Selection(ctx *Context, cache *mirrors.Cache, fileInfo *f
 	// - mirrors found in a 1.5x (configurable) range from the closest mirror
 	// - mirrors targeting the given country (as primary or secondary)
 	// - mirrors being in the same AS number
+	// - mirrors being in the same region of a large country
+	// - mirrors being in the same metropolitan area
 	totalScore := 0
 	baseScore := int(farthestMirror)
 	weights := map[int]int{}
+	// The order of the weighted mirrors, to not depend on the map ordering
+	var weighted []int
+	var cdn []int
 	for i := 0; i < len(mlist); i++ {
 		m := &mlist[i]
 
+		if m.CDN {
+			cdn = append(cdn, i)
+			m.ComputedScore = 1
+			continue
+		}
+
 		m.ComputedScore = baseScore - int(m.Distance) + 1
 
 		if m.Distance <= closestMirror*GetConfig().WeightDistributionRange {
 			score := (float32(baseScore) - m.Distance)
-			if !utils.IsPrimaryCountry(clientInfo, m.CountryFields) {
+			if !utils.IsPrimaryCountry(clientInfo, m.CountryCodes) {
 				score /= 2
 			}
 			m.ComputedScore += int(score)
-		} else if utils.IsPrimaryCountry(clientInfo, m.CountryFields) {
+		} else if utils.IsPrimaryCountry(clientInfo, m.CountryCodes) {
 			m.ComputedScore += int(float32(baseScore) - (m.Distance * 5))
-		} else if utils.IsAdditionalCountry(clientInfo, m.CountryFields) {
+		} else if utils.IsAdditionalCountry(clientInfo, m.CountryCodes) {
 			m.ComputedScore += int(float32(baseScore) - closestMirror)
 		}
 
@@ -187,6 +326,14 @@ func (h DefaultEngine) Selection(ctx *Context, cache *mirrors.Cache, fileInfo *f
 			m.ComputedScore += baseScore / 2
 		}
 
+		if m.InRegion(clientInfo.RegionCode) {
+			m.ComputedScore += baseScore / 4
+		}
+
+		if m.InMetroArea(clientInfo) {
+			m.ComputedScore += baseScore / 2
+		}
+
 		floatingScore := float64(m.ComputedScore) + (float64(m.ComputedScore) * (float64(m.Score) / 100)) + 0.5
 
 		// The minimum allowed score is 1
@@ -196,9 +343,27 @@ func (h DefaultEngine) Selection(ctx *Context, cache *mirrors.Cache, fileInfo *f
 			// The weight must always be > 0 to not break the randomization below
 			totalScore += m.ComputedScore - baseScore
 			weights[m.ID] = m.ComputedScore - baseScore
+			weighted = append(weighted, m.ID)
 		}
 	}
 
+	// The CDN mirrors share a fixed percentage of the total weight
+	if share := GetConfig().CDNWeight; share > 0 && len(cdn) > 0 {
+		cdnTotal := totalScore * share / (100 - share)
+		weight := utils.Max(cdnTotal/len(cdn), 1)
+		for _, i := range cdn {
+			m := &mlist[i]
+			w := utils.Max(weight+weight*m.Score/100, 1)
+			m.ComputedScore = baseScore + w
+			totalScore += w
+			weights[m.ID] = w
+			weighted = append(weighted, m.ID)
+		}
+	}
+
+	// Trade the spreading of the load for the locality if configured
+	weighted, totalScore = narrowWeights(mlist, weights, weighted, baseScore)
+
 	// Get the final number of mirrors selected for weight distribution
 	selected := len(weights)
 
@@ -224,9 +389,13 @@ func (h DefaultEngine) Selection(ctx *Context, cache *mirrors.Cache, fileInfo *f
 			rest := totalScore
 			for i := 0; i < selected; i++ {
 				var id int
-				rv := rand.Int31n(int32(rest))
+				rv := rng.Int31n(int32(rest))
 				s := 0
-				for k, v := range weights {
+				for _, k := range weighted {
+					v, ok := weights[k]
+					if !ok {
+						continue
+					}
 					s += v
 					if int32(s) > rv {
 						id = k
@@ -256,3 +425,232 @@ func (h DefaultEngine) Selection(ctx *Context, cache *mirrors.Cache, fileInfo *f
 	}
 	return
 }
+
+// availabilityExclusion returns the reason to exclude a mirror unable to
+// serve any request, or an empty string
+// distanceExclusion excludes the mirrors farther than limit km from the
+// client, unless none of the mirrors is within this distance
+func distanceExclusion(mlist mirrors.Mirrors, limit int) (selected, excluded mirrors.Mirrors) {
+	if limit <= 0 {
+		return mlist, nil
+	}
+	for _, m := range mlist {
+		if !m.CDN && m.Distance > float32(limit) {
+			m.ExcludeReason = fmt.Sprintf("Too far (%d km)", int(m.Distance))
+			excluded = append(excluded, m)
+		} else {
+			selected = append(selected, m)
+		}
+	}
+	if len(selected) == 0 {
+		return mlist, nil
+	}
+	return selected, excluded
+}
+
+// narrowWeights applies SelectionTopK and SelectionRandomness to the weights
+// of the mirrors eligible for the weight distribution, the mirrors left out
+// becoming mere fallbacks. It returns the remaining weighted mirrors and the
+// new total of their weights.
+func narrowWeights(mlist mirrors.Mirrors, weights map[int]int, weighted []int, baseScore int) ([]int, int) {
+	topK := GetConfig().SelectionTopK
+	randomness := GetConfig().SelectionRandomness
+	if randomness == 0 {
+		// Strictly the best mirror
+		topK = 1
+	}
+
+	if topK > 0 && len(weighted) > topK {
+		order := append([]int(nil), weighted...)
+		sort.SliceStable(order, func(i, j int) bool {
+			return weights[order[i]] > weights[order[j]]
+		})
+		for _, id := range order[topK:] {
+			delete(weights, id)
+		}
+		var kept []int
+		for _, id := range weighted {
+			if _, ok := weights[id]; ok {
+				kept = append(kept, id)
+			}
+		}
+		weighted = kept
+		// Keep the weighted mirrors at the head of the list once sorted
+		for i := range mlist {
+			if _, ok := weights[mlist[i].ID]; !ok && mlist[i].ComputedScore > baseScore {
+				mlist[i].ComputedScore = baseScore
+			}
+		}
+	}
+
+	if randomness > 0 && randomness < 100 {
+		// Raise the relative weights to a power growing as the randomness
+		// decreases, the best mirrors taking a larger share
+		maxWeight := 1
+		for _, id := range weighted {
+			maxWeight = utils.Max(maxWeight, weights[id])
+		}
+		exponent := 100 / float64(randomness)
+		for _, id := range weighted {
+			w := math.Pow(float64(weights[id])/float64(maxWeight), exponent) * 10000
+			weights[id] = utils.Max(int(w+0.5), 1)
+		}
+	}
+
+	total := 0
+	for _, id := range weighted {
+		total += weights[id]
+	}
+	return weighted, total
+}
+
+func availabilityExclusion(m *mirrors.Mirror) string {
+	// Does it support http? Is it well formated?
+	if !strings.HasPrefix(m.HttpURL, "http://") && !strings.HasPrefix(m.HttpURL, "https://") {
+		return "Invalid URL"
+	}
+	// Is it enabled?
+	if !m.Enabled {
+		return "Disabled"
+	}
+	// Is it in maintenance?
+	if m.Maintenance {
+		return "Maintenance"
+	}
+	// Is it up?
+	if !m.Up {
+		if m.ExcludeReason == "" {
+			return "Down"
+		}
+		return m.ExcludeReason
+	}
+	return ""
+}
+
+// fileExclusion returns the reason to exclude a mirror unable to serve the
+// file to the clients of the continent of the given client, or an empty
+// string
+func fileExclusion(m *mirrors.Mirror, fileInfo *filesystem.FileInfo, clientInfo network.GeoIPRecord) string {
+	// Is the file part of the channels carried by the mirror?
+	if !m.Carries(fileInfo.Path) {
+		return "Not in its channels"
+	}
+	// Is it the same size / modtime as source?
+	if reason := m.FileMismatch(fileInfo); reason != "" {
+		return reason
+	}
+	// Is it configured to serve its continent only?
+	if m.ContinentOnly {
+		if !clientInfo.IsValid() || clientInfo.ContinentCode != m.ContinentCode {
+			return "Continent only"
+		}
+	}
+	return ""
+}
+
+// prefilter splits the mirrors of a file between the mirrors able to serve
+// it to the clients of the continent of the given client and the others
+func prefilter(mlist mirrors.Mirrors, fileInfo *filesystem.FileInfo, clientInfo network.GeoIPRecord) (selected, excluded mirrors.Mirrors) {
+	for _, m := range mlist {
+		reason := availabilityExclusion(&m)
+		if reason == "" {
+			reason = fileExclusion(&m, fileInfo, clientInfo)
+		}
+		if reason != "" {
+			m.ExcludeReason = reason
+			excluded = append(excluded, m)
+			continue
+		}
+		selected = append(selected, m)
+	}
+	return
+}
+
+// runSelectionHooks passes the candidates to the selection hooks and
+// returns the mirrors kept, with their adjusted score, and the vetoed ones
+func runSelectionHooks(ctx *Context, fileInfo *filesystem.FileInfo, clientInfo network.GeoIPRecord, mlist mirrors.Mirrors) (mirrors.Mirrors, mirrors.Mirrors) {
+	req := &hooks.Request{
+		Path:          fileInfo.Path,
+		CountryCode:   clientInfo.CountryCode,
+		ContinentCode: clientInfo.ContinentCode,
+		ASNum:         clientInfo.ASNum,
+		ASName:        clientInfo.ASName,
+		Latitude:      clientInfo.Latitude,
+		Longitude:     clientInfo.Longitude,
+	}
+	if ctx.remoteIP != nil {
+		req.IP = ctx.remoteIP.String()
+	}
+
+	candidates := make([]hooks.Candidate, len(mlist))
+	for i, m := range mlist {
+		candidates[i] = hooks.Candidate{
+			ID:            m.ID,
+			Name:          m.Name,
+			HttpURL:       m.HttpURL,
+			CountryCodes:  append([]string(nil), m.CountryCodes...),
+			ContinentCode: m.ContinentCode,
+			ASNum:         m.Asnum,
+			Distance:      m.Distance,
+			CDN:           m.CDN,
+			Score:         m.Score,
+		}
+	}
+
+	hooks.Run(req, candidates)
+
+	// The hooks may have reordered the candidates
+	byID := make(map[int]hooks.Candidate, len(candidates))
+	for _, c := range candidates {
+		byID[c.ID] = c
+	}
+
+	var vetoed mirrors.Mirrors
+	kept := mlist[:0]
+	for _, m := range mlist {
+		c, ok := byID[m.ID]
+		if !ok {
+			kept = append(kept, m)
+			continue
+		}
+		if c.Veto {
+			m.ExcludeReason = "Vetoed by hook"
+			if c.VetoReason != "" {
+				m.ExcludeReason += ": " + c.VetoReason
+			}
+			vetoed = append(vetoed, m)
+			continue
+		}
+		m.Score = c.Score
+		kept = append(kept, m)
+	}
+	return kept, vetoed
+}
+
+// isPropagated returns true if enough active mirrors are serving the same
+// version of the file as the source (see MinimumMirrors and MinimumPropagation)
+func isPropagated(cache *mirrors.Cache, mlist mirrors.Mirrors, fileInfo *filesystem.FileInfo) (bool, error) {
+	available := 0
+	for i := range mlist {
+		m := &mlist[i]
+		if m.Enabled && m.Up && m.FileMismatch(fileInfo) == "" {
+			available++
+		}
+	}
+
+	if available < GetConfig().MinimumMirrors {
+		return false, nil
+	}
+
+	if GetConfig().MinimumPropagation > 0 {
+		total, err := cache.GetEnabledMirrorCount()
+		if err != nil {
+			return false, err
+		}
+		if total > 0 && available*100 < total*GetConfig().MinimumPropagation {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"math/rand"
+	"net/http"
+	"testing"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/filesystem"
+	"github.com/etix/mirrorbits/mirrors"
+	. "github.com/etix/mirrorbits/testing"
+)
+
+func drawSequence(r Randomizer) []int32 {
+	var seq []int32
+	for i := 0; i < 10; i++ {
+		seq = append(seq, r.Int31n(1000))
+	}
+	return seq
+}
+
+func TestRandomizer(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/file.tgz", nil)
+	ctx := NewContext(nil, r, Templates{})
+	ctx.SetRemoteIP("192.0.2.1")
+	fileInfo := &filesystem.FileInfo{Path: "/file.tgz"}
+
+	// Injected source of randomness
+	SetConfiguration(&Configuration{})
+	rng := rand.New(rand.NewSource(1))
+	if (DefaultEngine{Rand: rng}).randomizer(ctx, fileInfo) != rng {
+		t.Fatalf("The injected randomizer must be used")
+	}
+	if _, ok := (DefaultEngine{}).randomizer(ctx, fileInfo).(globalRandomizer); !ok {
+		t.Fatalf("The global randomizer must be used by default")
+	}
+
+	// Identical requests must draw identical sequences when seeded
+	SetConfiguration(&Configuration{SelectionSeed: 42})
+	a := drawSequence((DefaultEngine{}).randomizer(ctx, fileInfo))
+	b := drawSequence((DefaultEngine{Rand: rng}).randomizer(ctx, fileInfo))
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Seeded sequences differ: %v != %v", a, b)
+		}
+	}
+
+	ctx.SetRemoteIP("192.0.2.2")
+	c := drawSequence((DefaultEngine{}).randomizer(ctx, fileInfo))
+	same := true
+	for i := range a {
+		same = same && a[i] == c[i]
+	}
+	if same {
+		t.Fatalf("Different clients are expected to draw different sequences")
+	}
+}
+
+func TestIsPropagated(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+	conn.ConnectPubsub()
+	cache := mirrors.NewCache(conn)
+
+	// Three of the four mirrors are enabled
+	cmdMirrors := mock.Command("HKEYS", "MIRRORS").Expect([]interface{}{
+		[]byte("1"),
+		[]byte("2"),
+		[]byte("3"),
+		[]byte("4"),
+	})
+	for _, id := range []string{"1", "2", "3"} {
+		mock.Command("HGETALL", "MIRROR_"+id).ExpectMap(map[string]string{"ID": id, "enabled": "1"})
+	}
+	mock.Command("HGETALL", "MIRROR_4").ExpectMap(map[string]string{"ID": "4"})
+
+	// The file is only available on the first two mirrors
+	fileInfo := &filesystem.FileInfo{Path: "/file.tgz", Size: 100}
+	mlist := mirrors.Mirrors{
+		{ID: 1, Enabled: true, Up: true, FileInfo: &filesystem.FileInfo{Size: 100}},
+		{ID: 2, Enabled: true, Up: true, FileInfo: &filesystem.FileInfo{Size: 100}},
+		{ID: 3, Enabled: true, Up: false, FileInfo: &filesystem.FileInfo{Size: 100}},
+		{ID: 4, Enabled: true, Up: true, FileInfo: &filesystem.FileInfo{Size: 50}},
+	}
+
+	tests := []struct {
+		minimumMirrors     int
+		minimumPropagation int
+		propagated         bool
+	}{
+		{2, 0, true},
+		{3, 0, false},
+		{0, 66, true},
+		{0, 67, false},
+		{2, 66, true},
+		{3, 66, false},
+	}
+	for _, test := range tests {
+		SetConfiguration(&Configuration{
+			MinimumMirrors:     test.minimumMirrors,
+			MinimumPropagation: test.minimumPropagation,
+		})
+		propagated, err := isPropagated(cache, mlist, fileInfo)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if propagated != test.propagated {
+			t.Fatalf("Expected propagated=%t with %d mirrors and %d%%", test.propagated, test.minimumMirrors, test.minimumPropagation)
+		}
+	}
+
+	if mock.Stats(cmdMirrors) != 1 {
+		t.Fatalf("The number of enabled mirrors is expected to be cached")
+	}
+}
+
+func TestNarrowWeights(t *testing.T) {
+	newList := func() (mirrors.Mirrors, map[int]int, []int) {
+		mlist := mirrors.Mirrors{
+			{ID: 1, ComputedScore: 110},
+			{ID: 2, ComputedScore: 140},
+			{ID: 3, ComputedScore: 120},
+		}
+		return mlist, map[int]int{1: 10, 2: 40, 3: 20}, []int{1, 2, 3}
+	}
+
+	// Weighted draw among all the mirrors by default
+	SetConfiguration(&Configuration{SelectionRandomness: 100})
+	mlist, weights, weighted := newList()
+	weighted, total := narrowWeights(mlist, weights, weighted, 100)
+	if len(weighted) != 3 || total != 70 || weights[2] != 40 {
+		t.Fatalf("The weights must be untouched, got %v (total %d)", weights, total)
+	}
+
+	// Only the two best mirrors are drawn
+	SetConfiguration(&Configuration{SelectionRandomness: 100, SelectionTopK: 2})
+	mlist, weights, weighted = newList()
+	weighted, total = narrowWeights(mlist, weights, weighted, 100)
+	if len(weighted) != 2 || weighted[0] != 2 || weighted[1] != 3 || total != 60 {
+		t.Fatalf("Expected the mirrors 2 and 3, got %v (total %d)", weighted, total)
+	}
+	if _, ok := weights[1]; ok || mlist[0].ComputedScore != 100 {
+		t.Fatalf("The mirror 1 must become a fallback")
+	}
+
+	// Less randomness favors the best mirror
+	SetConfiguration(&Configuration{SelectionRandomness: 50})
+	mlist, weights, weighted = newList()
+	_, total = narrowWeights(mlist, weights, weighted, 100)
+	if share := float64(weights[2]) / float64(total); share <= 40.0/70 {
+		t.Fatalf("The share of the best mirror must grow, got %.2f", share)
+	}
+
+	// No randomness at all
+	SetConfiguration(&Configuration{SelectionRandomness: 0})
+	mlist, weights, weighted = newList()
+	weighted, _ = narrowWeights(mlist, weights, weighted, 100)
+	if len(weighted) != 1 || weighted[0] != 2 || len(weights) != 1 {
+		t.Fatalf("Only the best mirror must remain, got %v", weighted)
+	}
+}
+
+func TestDistanceExclusion(t *testing.T) {
+	mlist := mirrors.Mirrors{
+		{ID: 1, Distance: 500},
+		{ID: 2, Distance: 7000},
+		{ID: 3, CDN: true},
+	}
+
+	selected, excluded := distanceExclusion(mlist, 0)
+	if len(selected) != 3 || len(excluded) != 0 {
+		t.Fatalf("No mirror must be excluded without a limit")
+	}
+
+	selected, excluded = distanceExclusion(mlist, 3000)
+	if len(selected) != 2 || selected[0].ID != 1 || selected[1].ID != 3 {
+		t.Fatalf("Expected the mirrors 1 and 3, got %v", selected)
+	}
+	if len(excluded) != 1 || excluded[0].ID != 2 || excluded[0].ExcludeReason == "" {
+		t.Fatalf("Expected the mirror 2 to be excluded, got %v", excluded)
+	}
+
+	// The far mirrors are kept as a last resort
+	selected, excluded = distanceExclusion(mlist[1:2], 3000)
+	if len(selected) != 1 || len(excluded) != 0 {
+		t.Fatalf("The far mirror must be kept when there is no alternative")
+	}
+}
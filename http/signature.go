@@ -0,0 +1,73 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"sync"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/utils"
+)
+
+const (
+	// Maximum number of signatures kept in cache
+	signatureCacheSize = 10000
+)
+
+// signatureCache keeps the detached signatures of the generated checksum
+// files. A signature is keyed by the signing key and the signed content,
+// which holds the hash of the file, so it remains valid until the file
+// changes and gpg runs only once per file and per key.
+type signatureCache struct {
+	sync.Mutex
+	entries map[string]*signatureEntry
+}
+
+type signatureEntry struct {
+	done      chan struct{}
+	signature []byte
+	err       error
+}
+
+// sign returns the detached signature of the given content, the concurrent
+// requests for the same content waiting for a single run of gpg
+func (s *signatureCache) sign(content []byte) ([]byte, error) {
+	signer := utils.GPGSigner{
+		Binary:  GetConfig().GPGSigning.Binary,
+		Homedir: GetConfig().GPGSigning.Homedir,
+		Key:     GetConfig().GPGSigning.Key,
+	}
+	key := signer.Key + "\x00" + string(content)
+
+	s.Lock()
+	entry, ok := s.entries[key]
+	if !ok {
+		if s.entries == nil || len(s.entries) >= signatureCacheSize {
+			s.entries = make(map[string]*signatureEntry)
+		}
+		entry = &signatureEntry{
+			done: make(chan struct{}),
+		}
+		s.entries[key] = entry
+	}
+	s.Unlock()
+
+	if ok {
+		<-entry.done
+		return entry.signature, entry.err
+	}
+
+	entry.signature, entry.err = signer.DetachSign(content)
+	if entry.err != nil {
+		// Let the next request try again
+		s.Lock()
+		if s.entries[key] == entry {
+			delete(s.entries, key)
+		}
+		s.Unlock()
+	}
+	close(entry.done)
+
+	return entry.signature, entry.err
+}
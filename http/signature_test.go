@@ -0,0 +1,65 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/etix/mirrorbits/config"
+)
+
+func TestSignatureCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mirrorbits-tests")
+	if err != nil {
+		t.Fatalf("Unable to create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Stand-in for gpg counting its runs
+	runs := filepath.Join(dir, "runs")
+	binary := filepath.Join(dir, "gpg")
+	script := "#!/bin/sh\necho run >> " + runs + "\necho SIGNATURE; cat\n"
+	if err := ioutil.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatalf("Unable to create the gpg script: %s", err)
+	}
+
+	c := &Configuration{}
+	c.GPGSigning.Key = "0x0123456789ABCDEF"
+	c.GPGSigning.Binary = binary
+	SetConfiguration(c)
+	defer SetConfiguration(&Configuration{})
+
+	var s signatureCache
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sig, err := s.sign([]byte("abcdef  file.iso\n"))
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if string(sig) != "SIGNATURE\nabcdef  file.iso\n" {
+				t.Errorf("Unexpected signature: %q", sig)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := s.sign([]byte("012345  file.iso\n")); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(runs)
+	if err != nil {
+		t.Fatalf("Unable to read the runs: %s", err)
+	}
+	if n := strings.Count(string(data), "run"); n != 2 {
+		t.Fatalf("Expected gpg to run once per content, ran %d times", n)
+	}
+}
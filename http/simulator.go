@@ -0,0 +1,109 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/etix/mirrorbits/filesystem"
+	"github.com/etix/mirrorbits/hooks"
+	"github.com/etix/mirrorbits/mirrors"
+	"github.com/etix/mirrorbits/network"
+	"github.com/etix/mirrorbits/utils"
+)
+
+// Simulator runs the mirror selection in-process, without serving the
+// requests nor counting the downloads
+type Simulator struct {
+	geoip  *network.GeoIP
+	cache  *mirrors.Cache
+	engine mirrorSelection
+}
+
+// NewSimulator returns a simulator using the given cache, the GeoIP
+// databases and the selection hooks of the configuration
+func NewSimulator(cache *mirrors.Cache) (*Simulator, error) {
+	s := &Simulator{
+		geoip:  network.NewGeoIP(),
+		cache:  cache,
+		engine: DefaultEngine{},
+	}
+	if err := s.geoip.LoadGeoIP(); err != nil {
+		if gerr, ok := err.(network.GeoIPError); !ok || gerr.IsFatal() {
+			return nil, err
+		}
+	}
+	hooks.Reload()
+	return s, nil
+}
+
+// Select returns the mirrors selected for the given file and client, as
+// they would be for a redirection, along with the excluded mirrors
+func (s *Simulator) Select(filePath, ip string) (mirrors.Mirrors, mirrors.Mirrors, error) {
+	r := &http.Request{
+		Method: "GET",
+		URL:    &url.URL{Path: path.Clean("/" + filePath)},
+		Header: make(http.Header),
+	}
+	ctx := NewContext(nil, r, Templates{})
+	ctx.SetRemoteIP(ip)
+
+	fileInfo := filesystem.NewFileInfo(r.URL.Path)
+	clientInfo := s.geoip.GetRecord(ip)
+
+	return s.engine.Selection(ctx, s.cache, &fileInfo, clientInfo)
+}
+
+// Locate returns the location of the given IP address
+func (s *Simulator) Locate(ip string) network.GeoIPRecord {
+	return s.geoip.GetRecord(ip)
+}
+
+// CountryLocation returns the location of a client of the given country,
+// placed at the average location of the mirrors of this country carrying
+// the given file
+func (s *Simulator) CountryLocation(filePath, countryCode string) (network.GeoIPRecord, error) {
+	clientInfo := network.GeoIPRecord{
+		CountryCode:   countryCode,
+		ContinentCode: utils.CountryContinent(countryCode),
+	}
+	mlist, err := s.cache.GetMirrors(path.Clean("/"+filePath), clientInfo)
+	if err != nil {
+		return clientInfo, err
+	}
+	var located int
+	var latitude, longitude float32
+	for _, m := range mlist {
+		if len(m.CountryCodes) > 0 && m.CountryCodes[0] == countryCode && !m.CDN {
+			latitude += m.Latitude
+			longitude += m.Longitude
+			located++
+		}
+	}
+	if located == 0 {
+		return clientInfo, errors.New("no mirror of this country carries the file, the location must be given")
+	}
+	clientInfo.Latitude = latitude / float32(located)
+	clientInfo.Longitude = longitude / float32(located)
+	return clientInfo, nil
+}
+
+// Weights returns the mirrors eligible for the given file and client with
+// the share of the requests each of them would receive (their Weight), as
+// computed for the mirror list, along with the excluded mirrors
+func (s *Simulator) Weights(filePath string, clientInfo network.GeoIPRecord) (mirrors.Mirrors, mirrors.Mirrors, error) {
+	r := &http.Request{
+		Method: "GET",
+		URL:    &url.URL{Path: path.Clean("/" + filePath), RawQuery: "mirrorlist"},
+		Header: make(http.Header),
+	}
+	ctx := NewContext(nil, r, Templates{})
+
+	fileInfo := filesystem.NewFileInfo(r.URL.Path)
+
+	return s.engine.Selection(ctx, s.cache, &fileInfo, clientInfo)
+}
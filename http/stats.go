@@ -9,8 +9,11 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/core"
 	"github.com/etix/mirrorbits/database"
 	"github.com/etix/mirrorbits/filesystem"
 	"github.com/etix/mirrorbits/mirrors"
@@ -31,6 +34,15 @@ import (
 	STATS_MIRROR_[year]					= mirror -> value	By year
 	STATS_MIRROR_[year]_[month]			= mirror -> value	By month
 	STATS_MIRROR_[year]_[month]_[day]	= mirror -> value	By day
+
+	List of hashes for the country of the clients:
+	STATS_COUNTRY						= country -> value	All time
+	STATS_COUNTRY_[year]					= country -> value	By year
+	STATS_COUNTRY_[year]_[month]			= country -> value	By month
+	STATS_COUNTRY_[year]_[month]_[day]	= country -> value	By day
+
+	The sum of the distances (km) between the clients and the mirrors is
+	kept the same way in STATS_COUNTRY_DISTANCE.
 */
 
 var (
@@ -46,12 +58,16 @@ type Stats struct {
 	stop       chan bool
 	wg         sync.WaitGroup
 	downgraded bool
+	// Set while the stats are pushed to the database, updated atomically
+	pushing int32
 }
 
 type countItem struct {
 	mirrorID int
 	filepath string
 	size     int64
+	country  string
+	distance float32
 	time     time.Time
 }
 
@@ -59,14 +75,25 @@ type countItem struct {
 func NewStats(redis *database.Redis) *Stats {
 	s := &Stats{
 		r:         redis,
-		countChan: make(chan countItem, 1000),
+		countChan: make(chan countItem, GetConfig().StatsQueueSize),
 		mapStats:  make(map[string]int64),
 		stop:      make(chan bool),
 	}
 	go s.processCountDownload()
+	core.RegisterWorkerPool("stats", s.pool)
 	return s
 }
 
+// pool reports the activity of the stats writer
+func (s *Stats) pool() core.WorkerPool {
+	return core.WorkerPool{
+		Workers:   1,
+		Busy:      int(atomic.LoadInt32(&s.pushing)),
+		Queued:    len(s.countChan),
+		QueueSize: cap(s.countChan),
+	}
+}
+
 // Terminate stops the stats handler and commit results to the database
 func (s *Stats) Terminate() {
 	close(s.stop)
@@ -75,7 +102,8 @@ func (s *Stats) Terminate() {
 }
 
 // CountDownload is a lightweight method used to count a new download for a specific file and mirror
-func (s *Stats) CountDownload(m mirrors.Mirror, fileinfo filesystem.FileInfo) error {
+// from a client of the given country
+func (s *Stats) CountDownload(m mirrors.Mirror, fileinfo filesystem.FileInfo, country string) error {
 	if m.Name == "" {
 		return errUnknownMirror
 	}
@@ -83,7 +111,7 @@ func (s *Stats) CountDownload(m mirrors.Mirror, fileinfo filesystem.FileInfo) er
 		return errEmptyFileError
 	}
 
-	s.countChan <- countItem{m.ID, fileinfo.Path, fileinfo.Size, time.Now().UTC()}
+	s.countChan <- countItem{m.ID, fileinfo.Path, fileinfo.Size, country, m.Distance, time.Now().UTC()}
 	return nil
 }
 
@@ -103,6 +131,10 @@ func (s *Stats) processCountDownload() {
 			s.mapStats["f"+date+c.filepath]++
 			s.mapStats["m"+date+strconv.Itoa(c.mirrorID)]++
 			s.mapStats["s"+date+strconv.Itoa(c.mirrorID)] += c.size
+			if c.country != "" {
+				s.mapStats["c"+date+c.country]++
+				s.mapStats["d"+date+c.country] += int64(c.distance)
+			}
 		case <-pushTicker.C:
 			s.pushStats()
 		}
@@ -115,6 +147,15 @@ func (s *Stats) pushStats() {
 		return
 	}
 
+	atomic.StoreInt32(&s.pushing, 1)
+	defer atomic.StoreInt32(&s.pushing, 0)
+
+	// Keep the stats in memory until the database is writable again
+	if s.r.Degraded() {
+		s.downgraded = true
+		return
+	}
+
 	rconn := s.r.Get()
 	defer rconn.Close()
 
@@ -124,6 +165,7 @@ func (s *Stats) pushStats() {
 		}
 
 		s.downgraded = true
+		s.r.SetDegraded(rconn.Err())
 		return
 	}
 
@@ -173,6 +215,18 @@ func (s *Stats) pushStats() {
 				rconn.Send("HINCRBY", mkey, object, v)
 				mkey = mkey[:strings.LastIndex(mkey, "_")]
 			}
+		} else if typ == "c" || typ == "d" {
+			// Country
+
+			ckey := fmt.Sprintf("STATS_COUNTRY_%s", date)
+			if typ == "d" {
+				ckey = fmt.Sprintf("STATS_COUNTRY_DISTANCE_%s", date)
+			}
+
+			for i := 0; i < 4; i++ {
+				rconn.Send("HINCRBY", ckey, object, v)
+				ckey = ckey[:strings.LastIndex(ckey, "_")]
+			}
 		} else {
 			log.Warning("Stats: unknown type", typ)
 		}
@@ -182,6 +236,10 @@ func (s *Stats) pushStats() {
 
 	if err != nil {
 		log.Errorf("Stats: could not save stats to redis: %s", err.Error())
+		if database.IsWriteError(err) {
+			s.downgraded = true
+			s.r.SetDegraded(err)
+		}
 		return
 	}
 
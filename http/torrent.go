@@ -0,0 +1,98 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/utils"
+	"github.com/gomodule/redigo/redis"
+)
+
+// torrentCache keeps the info hash of the torrents found in the repository
+// to avoid decoding them on each request
+type torrentCache struct {
+	sync.Mutex
+	entries map[string]torrentEntry
+}
+
+type torrentEntry struct {
+	modTime  time.Time
+	infoHash string
+}
+
+// infoHash returns the info hash of the torrent at the given path of the
+// repository, decoding the file only if it changed since the last call
+func (t *torrentCache) infoHash(torrentPath string) (string, error) {
+	if GetConfig().ObjectStorage.Enabled() {
+		// The torrents stored in a bucket are not decoded
+		return "", os.ErrNotExist
+	}
+	localPath := GetConfig().Repository + torrentPath
+
+	fi, err := os.Stat(localPath)
+	if err != nil {
+		return "", err
+	}
+
+	t.Lock()
+	entry, ok := t.entries[torrentPath]
+	t.Unlock()
+	if ok && entry.modTime.Equal(fi.ModTime()) {
+		return entry.infoHash, nil
+	}
+
+	data, err := ioutil.ReadFile(localPath)
+	if err != nil {
+		return "", err
+	}
+	hash, err := utils.TorrentInfoHash(data)
+	if err != nil {
+		return "", err
+	}
+
+	t.Lock()
+	if t.entries == nil {
+		t.entries = make(map[string]torrentEntry)
+	}
+	t.entries[torrentPath] = torrentEntry{
+		modTime:  fi.ModTime(),
+		infoHash: hash,
+	}
+	t.Unlock()
+
+	return hash, nil
+}
+
+// torrentLinks returns the path of the torrent of the given file along with
+// its magnet link if such a torrent exists in the repository
+func (h *HTTP) torrentLinks(filePath string, size int64) (torrentPath, magnet string) {
+	conn := h.redis.Get()
+	exists, err := redis.Bool(conn.Do("SISMEMBER", "FILES", filePath+".torrent"))
+	conn.Close()
+	if err != nil || !exists {
+		return "", ""
+	}
+
+	torrentPath = filePath + ".torrent"
+
+	hash, err := h.torrents.infoHash(torrentPath)
+	if err != nil {
+		log.Warningf("Unable to read the torrent %s: %s", torrentPath, err)
+		return torrentPath, ""
+	}
+
+	magnet = fmt.Sprintf("magnet:?xt=urn:btih:%s&dn=%s", hash, url.QueryEscape(path.Base(filePath)))
+	if size > 0 {
+		magnet += fmt.Sprintf("&xl=%d", size)
+	}
+	return torrentPath, magnet
+}
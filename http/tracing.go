@@ -0,0 +1,73 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	maxRequestIDLength = 128
+)
+
+// requestID returns the X-Request-ID given by the client (usually set by a
+// proxy) or generates a new one
+func requestID(r *http.Request) string {
+	if id := r.Header.Get("X-Request-ID"); validRequestID(id) {
+		return id
+	}
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// validRequestID returns true if the given identifier can be safely used in
+// the headers, the logs and as a database key
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:+=/", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// errorPageWriter appends the request id to the plain text error pages
+// and records the status of the response
+type errorPageWriter struct {
+	http.ResponseWriter
+	requestID string
+	errorPage bool
+	status    int
+}
+
+func (w *errorPageWriter) WriteHeader(code int) {
+	w.status = code
+	if code >= 400 && strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		w.errorPage = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Status returns the status code of the response
+func (w *errorPageWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Finish writes the request id at the end of the error page, if any
+func (w *errorPageWriter) Finish() {
+	if w.errorPage {
+		fmt.Fprintf(w.ResponseWriter, "Request ID: %s\n", w.requestID)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/op/go-logging"
+)
+
+// jsonFormatter formats the records as json documents
+type jsonFormatter struct {
+	time bool
+	file bool
+}
+
+type jsonRecord struct {
+	Time    string `json:"time,omitempty"`
+	Level   string `json:"level"`
+	Module  string `json:"module"`
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+}
+
+func (f jsonFormatter) Format(calldepth int, r *logging.Record, output io.Writer) error {
+	record := jsonRecord{
+		Level:   strings.ToLower(r.Level.String()),
+		Module:  r.Module,
+		Message: r.Message(),
+	}
+	if f.time {
+		record.Time = r.Time.Format(time.RFC3339Nano)
+	}
+	if f.file {
+		if _, file, line, ok := runtime.Caller(calldepth + 1); ok {
+			record.File = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+		}
+	}
+	out, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = output.Write(out)
+	return err
+}
+
+// journalBackend sends the records to the systemd journal
+type journalBackend struct {
+	vars map[string]string
+}
+
+func (b journalBackend) Log(level logging.Level, calldepth int, rec *logging.Record) error {
+	vars := map[string]string{
+		"MIRRORBITS_MODULE": rec.Module,
+	}
+	for k, v := range b.vars {
+		vars[k] = v
+	}
+	return journalSend(rec.Formatted(calldepth+1), level, vars)
+}
+
+// journalVars returns the fields identifying mirrorbits in the journal
+func journalVars() map[string]string {
+	settings := GetConfig().Logging.Syslog
+	vars := map[string]string{
+		"SYSLOG_IDENTIFIER": settings.Tag,
+	}
+	for code, name := range SyslogFacilities {
+		if name == settings.Facility {
+			vars["SYSLOG_FACILITY"] = strconv.Itoa(code)
+		}
+	}
+	return vars
+}
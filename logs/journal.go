@@ -0,0 +1,31 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+//go:build !windows
+// +build !windows
+
+package logs
+
+import (
+	"github.com/coreos/go-systemd/journal"
+	"github.com/op/go-logging"
+)
+
+var journalPriorities = map[logging.Level]journal.Priority{
+	logging.CRITICAL: journal.PriCrit,
+	logging.ERROR:    journal.PriErr,
+	logging.WARNING:  journal.PriWarning,
+	logging.NOTICE:   journal.PriNotice,
+	logging.INFO:     journal.PriInfo,
+	logging.DEBUG:    journal.PriDebug,
+}
+
+// journalEnabled returns true if the systemd journal is available
+func journalEnabled() bool {
+	return journal.Enabled()
+}
+
+// journalSend sends a message to the systemd journal
+func journalSend(msg string, level logging.Level, vars map[string]string) error {
+	return journal.Send(msg, journalPriorities[level], vars)
+}
@@ -0,0 +1,20 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package logs
+
+import (
+	"errors"
+
+	"github.com/op/go-logging"
+)
+
+// journalEnabled returns true if the systemd journal is available
+func journalEnabled() bool {
+	return false
+}
+
+// journalSend sends a message to the systemd journal
+func journalSend(msg string, level logging.Level, vars map[string]string) error {
+	return errors.New("journald is not available")
+}
@@ -5,6 +5,7 @@ package logs
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	stdlog "log"
@@ -18,6 +19,7 @@ import (
 	. "github.com/etix/mirrorbits/config"
 	"github.com/etix/mirrorbits/core"
 	"github.com/etix/mirrorbits/mirrors"
+	"github.com/etix/mirrorbits/network"
 	"github.com/op/go-logging"
 )
 
@@ -28,7 +30,48 @@ var (
 )
 
 type runtimeLogger struct {
-	f *os.File
+	f      *os.File
+	syslog *syslogWriter
+}
+
+// Open returns the backend writing on the given output
+func (r *runtimeLogger) Open(output, file string) (logging.Backend, error) {
+	switch output {
+	case "syslog":
+		settings := GetConfig().Logging.Syslog
+		w, err := dialSyslog(settings.Address, settings.Facility, settings.Tag)
+		if err != nil {
+			return nil, err
+		}
+		r.syslog = w
+		return syslogBackend{w: w}, nil
+	case "journald":
+		if !journalEnabled() {
+			return nil, errors.New("journald is not available")
+		}
+		return journalBackend{vars: journalVars()}, nil
+	case "file":
+		f, _, err := openLogFile(file)
+		if err != nil {
+			return nil, err
+		}
+		r.f = f
+	default:
+		r.f = os.Stderr
+	}
+	return logging.NewLogBackend(r.f, "", 0), nil
+}
+
+// Close closes the current output, unless it is the standard error
+func (r *runtimeLogger) Close() {
+	if r.f != nil && r.f != os.Stderr {
+		r.f.Close()
+	}
+	r.f = nil
+	if r.syslog != nil {
+		r.syslog.Close()
+		r.syslog = nil
+	}
 }
 
 type downloadsLogger struct {
@@ -61,40 +104,61 @@ func isTerminal(f *os.File) bool {
 	return false
 }
 
-// ReloadRuntimeLogs reopens the runtime logs for writing
+// ReloadRuntimeLogs reopens the runtime logs for writing and applies the
+// output, the format and the levels of the configuration
 func ReloadRuntimeLogs() {
-	if rlogger.f == os.Stderr && core.RunLog == "" {
-		// Logger already set up and connected to the console.
-		// Don't reload to avoid breaking journald.
-		return
+	output, file, format := "stderr", "", "text"
+	level := logging.INFO
+	var levels map[string]string
+	if core.Daemon {
+		c := GetConfig().Logging
+		output, file, format = c.Output, c.File, c.Format
+		level, _ = logging.LogLevel(c.Level)
+		levels = c.Levels
 	}
-
-	if rlogger.f != nil && rlogger.f != os.Stderr {
-		rlogger.f.Close()
+	if core.RunLog != "" {
+		output, file = "file", core.RunLog
 	}
 
-	if core.RunLog != "" {
-		var err error
-		rlogger.f, _, err = openLogFile(core.RunLog)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "Cannot open log file for writing")
-			rlogger.f = os.Stderr
-		}
-	} else {
-		rlogger.f = os.Stderr
+	rlogger.Close()
+
+	backend, err := rlogger.Open(output, file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot open the %s log output: %s\n", output, err)
+		output = "stderr"
+		backend, _ = rlogger.Open(output, "")
+	}
+	if b, ok := backend.(*logging.LogBackend); ok {
+		b.Color = format == "text" && isTerminal(rlogger.f) //TODO make color optional
 	}
 
-	logBackend := logging.NewLogBackend(rlogger.f, "", 0)
-	logBackend.Color = isTerminal(rlogger.f) //TODO make color optional
+	logging.SetBackend(backend)
 
-	logging.SetBackend(logBackend)
+	// The timestamps are already added by syslog and journald
+	timestamp := output == "stderr" || output == "file"
 
-	if core.Debug {
+	switch {
+	case format == "json":
+		logging.SetFormatter(jsonFormatter{time: timestamp, file: core.Debug})
+	case timestamp && core.Debug:
 		logging.SetFormatter(logging.MustStringFormatter("%{shortfile:-20s}%{time:2006/01/02 15:04:05.000 MST} %{message}"))
-		logging.SetLevel(logging.DEBUG, "main")
-	} else {
+	case timestamp:
 		logging.SetFormatter(logging.MustStringFormatter("%{time:2006/01/02 15:04:05.000 MST} %{message}"))
-		logging.SetLevel(logging.INFO, "main")
+	case core.Debug:
+		logging.SetFormatter(logging.MustStringFormatter("%{shortfile:-20s}%{message}"))
+	default:
+		logging.SetFormatter(logging.MustStringFormatter("%{message}"))
+	}
+
+	if core.Debug {
+		logging.SetLevel(logging.DEBUG, "")
+		return
+	}
+	logging.SetLevel(level, "")
+	for module, l := range levels {
+		if l, err := logging.LogLevel(l); err == nil {
+			logging.SetLevel(l, module)
+		}
 	}
 }
 
@@ -115,7 +179,11 @@ func openLogFile(logfile string) (*os.File, bool, error) {
 }
 
 func setDownloadLogWriter(writer io.Writer, createHeader bool) {
-	dlogger.l = stdlog.New(writer, "", stdlog.Ldate|stdlog.Lmicroseconds)
+	setDownloadLogWriterFlags(writer, createHeader, stdlog.Ldate|stdlog.Lmicroseconds)
+}
+
+func setDownloadLogWriterFlags(writer io.Writer, createHeader bool, flags int) {
+	dlogger.l = stdlog.New(writer, "", flags)
 
 	if createHeader {
 		var buf bytes.Buffer
@@ -134,6 +202,30 @@ func ReloadDownloadLogs() {
 
 	dlogger.Close()
 
+	switch GetConfig().Logging.Downloads {
+	case "syslog":
+		settings := GetConfig().Logging.Syslog
+		w, err := dialSyslog(settings.Address, settings.Facility, settings.Tag)
+		if err != nil {
+			log.Criticalf("Cannot open the downloads log: %s", err)
+			return
+		}
+		dlogger.f = syslogStream{w: w, msgid: "downloads"}
+		// The timestamp is part of the syslog message
+		setDownloadLogWriterFlags(dlogger.f, false, 0)
+		return
+	case "journald":
+		if !journalEnabled() {
+			log.Critical("Cannot open the downloads log: journald is not available")
+			return
+		}
+		vars := journalVars()
+		vars["MIRRORBITS_LOG"] = "downloads"
+		dlogger.f = journalStream{vars: vars}
+		setDownloadLogWriterFlags(dlogger.f, false, 0)
+		return
+	}
+
 	if GetConfig().LogDir == "" {
 		return
 	}
@@ -163,11 +255,21 @@ func LogDownload(typ string, statuscode int, p *mirrors.Results, err error) {
 		errstr = err.Error()
 	}
 
+	requestID := ""
+	if p != nil && p.RequestID != "" {
+		requestID = " id:" + p.RequestID
+	}
+
+	var ip string
+	if p != nil {
+		ip = network.AnonymizeIP(p.IP)
+	}
+
 	if (statuscode == 302 || statuscode == 200) && p != nil && len(p.MirrorList) > 0 {
 		var distance, countries string
 		m := p.MirrorList[0]
 		distance = strconv.FormatFloat(float64(m.Distance), 'f', 2, 32)
-		countries = strings.Join(m.CountryFields, ",")
+		countries = strings.Join(m.CountryCodes, ",")
 		fallback := ""
 		if p.Fallback == true {
 			fallback = " fallback:true"
@@ -177,22 +279,21 @@ func LogDownload(typ string, statuscode int, p *mirrors.Results, err error) {
 			sameASNum = "same"
 		}
 
-		dlogger.l.Printf("%s %d \"%s\" ip:%s mirror:%s%s %sasn:%d distance:%skm countries:%s",
-			typ, statuscode, p.FileInfo.Path, p.IP, m.Name, fallback, sameASNum, m.Asnum, distance, countries)
+		dlogger.l.Printf("%s %d \"%s\" ip:%s mirror:%s%s %sasn:%d distance:%skm countries:%s%s",
+			typ, statuscode, p.FileInfo.Path, ip, m.Name, fallback, sameASNum, m.Asnum, distance, countries, requestID)
 	} else if statuscode == 404 && p != nil {
-		dlogger.l.Printf("%s 404 \"%s\" ip:%s", typ, p.FileInfo.Path, p.IP)
+		dlogger.l.Printf("%s 404 \"%s\" ip:%s%s", typ, p.FileInfo.Path, ip, requestID)
 	} else if statuscode == 500 && p != nil {
 		mirrorName := "unknown"
 		if len(p.MirrorList) > 0 {
 			mirrorName = p.MirrorList[0].Name
 		}
-		dlogger.l.Printf("%s 500 \"%s\" ip:%s mirror:%s error:%s", typ, p.FileInfo.Path, p.IP, mirrorName, errstr)
+		dlogger.l.Printf("%s 500 \"%s\" ip:%s mirror:%s error:%s%s", typ, p.FileInfo.Path, ip, mirrorName, errstr, requestID)
 	} else {
-		var path, ip string
+		var path string
 		if p != nil {
 			path = p.FileInfo.Path
-			ip = p.IP
 		}
-		dlogger.l.Printf("%s %d \"%s\" ip:%s error:%s", typ, statuscode, path, ip, errstr)
+		dlogger.l.Printf("%s %d \"%s\" ip:%s error:%s%s", typ, statuscode, path, ip, errstr, requestID)
 	}
 }
@@ -5,13 +5,17 @@ package logs
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"net"
 	"os"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	. "github.com/etix/mirrorbits/config"
 	"github.com/etix/mirrorbits/core"
 	"github.com/etix/mirrorbits/filesystem"
 	"github.com/etix/mirrorbits/mirrors"
@@ -142,6 +146,29 @@ func TestReloadRuntimeLogs(t *testing.T) {
 
 }
 
+func TestJSONFormatter(t *testing.T) {
+	var buf bytes.Buffer
+
+	backend := logging.NewLogBackend(&buf, "", 0)
+	formatter := logging.NewBackendFormatter(backend, jsonFormatter{})
+	leveled := logging.AddModuleLevel(formatter)
+	logger := &logging.Logger{Module: "scan"}
+	logger.SetBackend(leveled)
+
+	logger.Warningf("Testing %d", 42)
+
+	var record jsonRecord
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("The output is not valid json: %s", err)
+	}
+	if record.Level != "warning" || record.Module != "scan" || record.Message != "Testing 42" {
+		t.Fatalf("Unexpected record %+v", record)
+	}
+	if record.Time != "" || record.File != "" {
+		t.Fatalf("The time and the file must be omitted")
+	}
+}
+
 func TestOpenLogFile(t *testing.T) {
 	path, err := ioutil.TempDir("", "mirrorbits-tests")
 	if err != nil {
@@ -238,6 +265,8 @@ func TestReloadDownloadLogs(t *testing.T) {
 func TestLogDownload(t *testing.T) {
 	var buf bytes.Buffer
 
+	SetConfiguration(&Configuration{})
+
 	dlogger.Close()
 
 	// The next line isn't supposed to crash.
@@ -267,11 +296,11 @@ func TestLogDownload(t *testing.T) {
 		},
 		MirrorList: mirrors.Mirrors{
 			mirrors.Mirror{
-				ID:            1,
-				Name:          "m1",
-				Asnum:         444,
-				Distance:      99,
-				CountryFields: []string{"FR", "UK", "DE"},
+				ID:           1,
+				Name:         "m1",
+				Asnum:        444,
+				Distance:     99,
+				CountryCodes: mirrors.CountryList{"FR", "UK", "DE"},
 			},
 			mirrors.Mirror{
 				ID:   2,
@@ -351,3 +380,37 @@ func TestLogDownload(t *testing.T) {
 
 	buf.Reset()
 }
+
+func TestSyslogWriter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %s", err)
+	}
+	defer conn.Close()
+
+	w, err := dialSyslog("udp://"+conn.LocalAddr().String(), "local3", "mirrorbits")
+	if err != nil {
+		t.Fatalf("Unable to dial: %s", err)
+	}
+	defer w.Close()
+
+	if err := w.Send(severityWarning, "downloads", "Testing42\n"); err != nil {
+		t.Fatalf("Unable to send: %s", err)
+	}
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Unable to read: %s", err)
+	}
+
+	// local3 (19) * 8 + warning (4)
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<156>1 ") {
+		t.Fatalf("Invalid priority or version: %s", msg)
+	}
+	if !strings.Contains(msg, " mirrorbits ") || !strings.HasSuffix(msg, " downloads - Testing42") {
+		t.Fatalf("Invalid message: %s", msg)
+	}
+}
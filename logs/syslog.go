@@ -0,0 +1,205 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/op/go-logging"
+)
+
+const (
+	severityCritical = 2
+	severityError    = 3
+	severityWarning  = 4
+	severityNotice   = 5
+	severityInfo     = 6
+	severityDebug    = 7
+)
+
+var (
+	severities = map[logging.Level]int{
+		logging.CRITICAL: severityCritical,
+		logging.ERROR:    severityError,
+		logging.WARNING:  severityWarning,
+		logging.NOTICE:   severityNotice,
+		logging.INFO:     severityInfo,
+		logging.DEBUG:    severityDebug,
+	}
+
+	// localSyslogSockets are the usual locations of the local syslog daemon
+	localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+)
+
+// syslogWriter sends RFC 5424 messages to a syslog daemon over udp, tcp or
+// a unix socket
+type syslogWriter struct {
+	sync.Mutex
+	network  string
+	address  string
+	facility int
+	tag      string
+	hostname string
+	conn     net.Conn
+}
+
+// dialSyslog connects to the syslog daemon at the given address
+// (udp://host:port, tcp://host:port, unix:///path or empty for the local
+// daemon)
+func dialSyslog(address, facility, tag string) (*syslogWriter, error) {
+	w := &syslogWriter{
+		tag: tag,
+	}
+	for code, name := range SyslogFacilities {
+		if name == facility {
+			w.facility = code
+		}
+	}
+	w.hostname, _ = os.Hostname()
+	if w.hostname == "" {
+		w.hostname = "-"
+	}
+
+	if address != "" {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, err
+		}
+		w.network = u.Scheme
+		w.address = u.Host
+		if u.Scheme == "unix" {
+			w.address = u.Path
+		}
+	}
+
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *syslogWriter) connect() (err error) {
+	if w.network == "" {
+		for _, path := range localSyslogSockets {
+			for _, network := range []string{"unixgram", "unix"} {
+				if w.conn, err = net.Dial(network, path); err == nil {
+					return nil
+				}
+			}
+		}
+		return errors.New("local syslog daemon not found")
+	}
+	if w.network == "unix" {
+		if w.conn, err = net.Dial("unixgram", w.address); err == nil {
+			return nil
+		}
+	}
+	w.conn, err = net.DialTimeout(w.network, w.address, 5*time.Second)
+	return err
+}
+
+// Send writes a message with the given severity and message id
+func (w *syslogWriter) Send(severity int, msgid, msg string) error {
+	w.Lock()
+	defer w.Unlock()
+
+	if msgid == "" {
+		msgid = "-"
+	}
+	msg = fmt.Sprintf("<%d>1 %s %s %s %d %s - %s",
+		w.facility*8+severity, time.Now().Format("2006-01-02T15:04:05.000000Z07:00"),
+		w.hostname, w.tag, os.Getpid(), msgid, strings.TrimSuffix(msg, "\n"))
+
+	if w.conn != nil {
+		if err := w.write(msg); err == nil {
+			return nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	// Reconnect once in case the daemon was restarted
+	if err := w.connect(); err != nil {
+		return err
+	}
+	return w.write(msg)
+}
+
+func (w *syslogWriter) write(msg string) error {
+	var err error
+	switch w.conn.LocalAddr().Network() {
+	case "tcp":
+		// Octet counting framing (RFC 6587)
+		_, err = fmt.Fprintf(w.conn, "%d %s", len(msg), msg)
+	case "unix":
+		_, err = fmt.Fprintf(w.conn, "%s\n", msg)
+	default:
+		_, err = w.conn.Write([]byte(msg))
+	}
+	return err
+}
+
+// Close closes the connection to the syslog daemon
+func (w *syslogWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// syslogBackend sends the runtime logs to syslog
+type syslogBackend struct {
+	w *syslogWriter
+}
+
+func (b syslogBackend) Log(level logging.Level, calldepth int, rec *logging.Record) error {
+	return b.w.Send(severities[level], "", rec.Formatted(calldepth+1))
+}
+
+// syslogStream sends each line written to syslog, it is used for the
+// downloads log
+type syslogStream struct {
+	w     *syslogWriter
+	msgid string
+}
+
+func (s syslogStream) Write(p []byte) (int, error) {
+	if err := s.w.Send(severityInfo, s.msgid, string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s syslogStream) Close() error {
+	return s.w.Close()
+}
+
+// journalStream sends each line written to the systemd journal, it is used
+// for the downloads log
+type journalStream struct {
+	vars map[string]string
+}
+
+func (s journalStream) Write(p []byte) (int, error) {
+	if err := journalSend(strings.TrimSuffix(string(p), "\n"), logging.INFO, s.vars); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s journalStream) Close() error {
+	return nil
+}
@@ -6,7 +6,6 @@ package main
 import (
 	"fmt"
 	"os"
-	"os/signal"
 	"runtime/pprof"
 	"strings"
 	"syscall"
@@ -17,11 +16,13 @@ import (
 	"github.com/etix/mirrorbits/core"
 	"github.com/etix/mirrorbits/daemon"
 	"github.com/etix/mirrorbits/database"
+	"github.com/etix/mirrorbits/hooks"
 	"github.com/etix/mirrorbits/http"
 	"github.com/etix/mirrorbits/logs"
 	"github.com/etix/mirrorbits/mirrors"
 	"github.com/etix/mirrorbits/process"
 	"github.com/etix/mirrorbits/rpc"
+	"github.com/etix/mirrorbits/tracing"
 	"github.com/op/go-logging"
 	"github.com/pkg/errors"
 )
@@ -44,10 +45,19 @@ func main() {
 	}
 
 	if core.Daemon {
-		LoadConfig()
+		if err := LoadConfig(); err != nil {
+			log.Fatal(err)
+		}
 		logs.ReloadLogs()
+		tracing.Reload()
+		hooks.Reload()
 
-		process.WritePidFile()
+		if err := process.LockInstance(core.ConfigFile); err != nil {
+			log.Fatal(errors.Wrap(err, core.ConfigFile))
+		}
+		if err := process.WritePidFile(); err != nil {
+			log.Fatal(err)
+		}
 
 		// Show our nice welcome logo
 		fmt.Printf(core.Banner+"\n\n", core.VERSION)
@@ -66,33 +76,42 @@ func main() {
 		rpcs.SetCache(c)
 		h := http.HTTPServer(r, c)
 
+		/* Setup the debug listener */
+		if GetConfig().DebugListenAddress != "" {
+			if err := http.StartDebugServer(); err != nil {
+				log.Fatal(errors.Wrap(err, "debug error"))
+			}
+		}
+
 		/* Start the background monitor */
 		m := daemon.NewMonitor(r, c)
 		if core.Monitor {
-			go m.MonitorLoop()
+			rpcs.SetMonitor(m)
 		}
+		go func() {
+			/* Seed an empty database */
+			if core.Bootstrap != "" {
+				if err := rpcs.Bootstrap(core.Bootstrap, !core.Monitor); err != nil {
+					log.Errorf("Bootstrap failed: %s", err)
+				}
+			}
+			if core.Monitor {
+				m.MonitorLoop()
+			}
+		}()
 
 		/* Handle SIGNALS */
 		k := make(chan os.Signal, 1)
 		rpcs.SetSignals(k)
-		signal.Notify(k,
-			syscall.SIGINT,  // Terminate
-			syscall.SIGTERM, // Terminate
-			syscall.SIGQUIT, // Stop gracefully
-			syscall.SIGHUP,  // Reload config
-			syscall.SIGUSR1, // Reopen log files
-			syscall.SIGUSR2, // Seamless binary upgrade
-		)
+		process.Notify(k)
 		go func() {
 			for {
 				sig := <-k
 				switch sig {
-				case syscall.SIGINT:
-					fallthrough
-				case syscall.SIGTERM:
+				case os.Interrupt, syscall.SIGTERM:
 					process.RemovePidFile()
 					os.Exit(0)
-				case syscall.SIGQUIT:
+				case process.SigStop:
 					m.Stop()
 					rpcs.Close()
 					if h.Listener != nil {
@@ -102,23 +121,26 @@ func main() {
 						process.RemovePidFile()
 						os.Exit(0)
 					}
-				case syscall.SIGHUP:
+				case process.SigReload:
 					listenAddress := GetConfig().ListenAddress
 					if err := ReloadConfig(); err != nil {
-						log.Warningf("SIGHUP Received: %s\n", err)
+						log.Warningf("Reload failed: %s\n", err)
 					} else {
-						log.Notice("SIGHUP Received: Reloading configuration...")
+						log.Notice("Reloading configuration...")
+						logs.ReloadRuntimeLogs()
+						tracing.Reload()
+						hooks.Reload()
 					}
 					if GetConfig().ListenAddress != listenAddress {
 						h.Restarting = true
 						h.Stop(1 * time.Second)
 					}
 					h.Reload()
-				case syscall.SIGUSR1:
-					log.Notice("SIGUSR1 Received: Re-opening logs...")
+				case process.SigReopenLogs:
+					log.Notice("Re-opening logs...")
 					logs.ReloadLogs()
-				case syscall.SIGUSR2:
-					log.Notice("SIGUSR2 Received: Seamless binary upgrade...")
+				case process.SigUpgrade:
+					log.Notice("Seamless binary upgrade...")
 					rpcs.Close()
 					err := process.Relaunch(*h.Listener)
 					if err != nil {
@@ -128,7 +150,7 @@ func main() {
 			}
 		}()
 
-		// Recover an existing listener (see process.go)
+		// Recover an existing listener (see process_unix.go)
 		if l, ppid, err := process.Recover(); err == nil {
 			h.SetListener(l)
 			go func() {
@@ -171,8 +193,7 @@ func main() {
 	} else {
 		args := os.Args[len(os.Args)-core.NArg:]
 		if err := cli.ParseCommands(args...); err != nil {
-			fmt.Fprintf(os.Stderr, "%s\n", err)
-			os.Exit(1)
+			os.Exit(cli.HandleError(err))
 		}
 	}
 	os.Exit(0)
@@ -0,0 +1,154 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/etix/mirrorbits/database"
+	"github.com/gomodule/redigo/redis"
+)
+
+var (
+	// ErrInvalidAPIKey is returned when the API key doesn't belong to any mirror
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrInvalidSponsorURL is returned when a sponsor URL is not an http(s) URL
+	ErrInvalidSponsorURL = errors.New("the sponsor URLs must be http or https URLs")
+)
+
+func apiKeyDigest(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIKey issues a new API key for the given mirror, replacing the
+// previous one. Only a digest of the key is stored.
+func GenerateAPIKey(r *database.Redis, id int) (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(b)
+
+	conn := r.Get()
+	defer conn.Close()
+
+	previous, err := redis.String(conn.Do("GET", fmt.Sprintf("APIKEY_%d", id)))
+	if err != nil && err != redis.ErrNil {
+		return "", err
+	}
+
+	digest := apiKeyDigest(key)
+
+	conn.Send("MULTI")
+	if previous != "" {
+		conn.Send("HDEL", "APIKEYS", previous)
+	}
+	conn.Send("HSET", "APIKEYS", digest, id)
+	conn.Send("SET", fmt.Sprintf("APIKEY_%d", id), digest)
+	_, err = conn.Do("EXEC")
+	if err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// RevokeAPIKey revokes the API key of the given mirror
+func RevokeAPIKey(r *database.Redis, id int) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	key := fmt.Sprintf("APIKEY_%d", id)
+
+	digest, err := redis.String(conn.Do("GET", key))
+	if err == redis.ErrNil {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	conn.Send("MULTI")
+	conn.Send("HDEL", "APIKEYS", digest)
+	conn.Send("DEL", key)
+	_, err = conn.Do("EXEC")
+	return err
+}
+
+// MirrorFromAPIKey returns the identifier of the mirror owning the API key
+func MirrorFromAPIKey(r *database.Redis, key string) (int, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	id, err := redis.Int(conn.Do("HGET", "APIKEYS", apiKeyDigest(key)))
+	if err == redis.ErrNil {
+		return 0, ErrInvalidAPIKey
+	}
+	return id, err
+}
+
+// RequestScan asks for the given mirror to be scanned as soon as possible,
+// source being the origin of the request shown in the scan queue
+func RequestScan(r *database.Redis, id int, source string) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	// Resetting the date of the last sync makes the monitor of the node
+	// handling the mirror queue a scan
+	_, err := conn.Do("HMSET", fmt.Sprintf("MIRROR_%d", id), "lastSync", 0, "scanRequestedBy", source)
+	if err == nil {
+		database.Publish(conn, database.MIRROR_UPDATE, strconv.Itoa(id))
+	}
+	return err
+}
+
+// SetMaintenance puts the given mirror in maintenance or takes it out of
+// maintenance. A mirror in maintenance is not selected, yet it stays enabled
+// since only the operator can enable or disable a mirror.
+func SetMaintenance(r *database.Redis, id int, state bool) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("HSET", fmt.Sprintf("MIRROR_%d", id), "maintenance", state)
+	if err == nil {
+		database.Publish(conn, database.MIRROR_UPDATE, strconv.Itoa(id))
+	}
+	return err
+}
+
+// UpdateSponsor updates the sponsor details of the given mirror, the URLs
+// can be empty or must be http(s) URLs
+func UpdateSponsor(r *database.Redis, id int, name, sponsorURL, logoURL string) error {
+	for _, u := range []string{sponsorURL, logoURL} {
+		if u != "" && !isHTTPURL(u) {
+			return ErrInvalidSponsorURL
+		}
+	}
+
+	conn := r.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("HMSET", fmt.Sprintf("MIRROR_%d", id),
+		"sponsorName", name,
+		"sponsorURL", sponsorURL,
+		"sponsorLogo", logoURL)
+	if err == nil {
+		database.Publish(conn, database.MIRROR_UPDATE, strconv.Itoa(id))
+	}
+	return err
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
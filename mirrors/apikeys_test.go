@@ -0,0 +1,63 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"testing"
+
+	. "github.com/etix/mirrorbits/testing"
+	"github.com/gomodule/redigo/redis"
+)
+
+func TestMirrorFromAPIKey(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	mock.Command("HGET", "APIKEYS", apiKeyDigest("goodkey")).Expect([]byte("42"))
+	mock.Command("HGET", "APIKEYS", apiKeyDigest("badkey")).ExpectError(redis.ErrNil)
+
+	id, err := MirrorFromAPIKey(conn, "goodkey")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if id != 42 {
+		t.Fatalf("Expected 42, got %d", id)
+	}
+
+	if _, err := MirrorFromAPIKey(conn, "badkey"); err != ErrInvalidAPIKey {
+		t.Fatalf("Expected ErrInvalidAPIKey, got %v", err)
+	}
+}
+
+func TestUpdateSponsor(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	for _, u := range []string{"javascript:alert(1)", "ftp://example.org/", "//example.org/", "http://"} {
+		if err := UpdateSponsor(conn, 1, "Sponsor", u, ""); err != ErrInvalidSponsorURL {
+			t.Fatalf("Expected ErrInvalidSponsorURL for %s, got %v", u, err)
+		}
+		if err := UpdateSponsor(conn, 1, "Sponsor", "", u); err != ErrInvalidSponsorURL {
+			t.Fatalf("Expected ErrInvalidSponsorURL for the logo %s, got %v", u, err)
+		}
+	}
+
+	cmd := mock.Command("HMSET", "MIRROR_1", "sponsorName", "Sponsor", "sponsorURL", "https://example.org/", "sponsorLogo", "").Expect("OK")
+	if err := UpdateSponsor(conn, 1, "Sponsor", "https://example.org/", ""); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if mock.Stats(cmd) != 1 {
+		t.Fatalf("The sponsor was not updated")
+	}
+}
+
+func TestSetMaintenance(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	cmd := mock.Command("HSET", "MIRROR_1", "maintenance", true).Expect(int64(1))
+	if err := SetMaintenance(conn, 1, true); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if mock.Stats(cmd) != 1 {
+		t.Fatalf("The maintenance flag was not set")
+	}
+}
@@ -7,13 +7,14 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unsafe"
 
+	. "github.com/etix/mirrorbits/config"
 	"github.com/etix/mirrorbits/database"
 	"github.com/etix/mirrorbits/filesystem"
 	"github.com/etix/mirrorbits/network"
-	"github.com/etix/mirrorbits/utils"
 	"github.com/gomodule/redigo/redis"
 )
 
@@ -26,6 +27,12 @@ type Cache struct {
 	mCache   *LRUCache
 	fimCache *LRUCache
 
+	candidates candidateCache
+	tree       fileTree
+
+	indexLock sync.Mutex
+	index     *mirrorIndex
+
 	mirrorUpdateEvent      chan string
 	fileUpdateEvent        chan string
 	mirrorFileUpdateEvent  chan string
@@ -43,12 +50,25 @@ func (f *fileInfoValue) Size() int {
 
 type fileMirrorValue struct {
 	value []int
+	// The file is carried by more than MaxCandidateMirrors mirrors, a
+	// sample of them is drawn for each request
+	sampled bool
 }
 
 func (f *fileMirrorValue) Size() int {
+	if f.sampled {
+		return 1
+	}
 	return cap(f.value)
 }
 
+// mirrorIndex holds the number of enabled mirrors and the identifiers of the
+// enabled mirrors of each continent
+type mirrorIndex struct {
+	enabled     int
+	byContinent map[string][]int
+}
+
 type mirrorValue struct {
 	value Mirror
 }
@@ -93,6 +113,8 @@ func NewCache(r *database.Redis) *Cache {
 			select {
 			case data := <-c.mirrorUpdateEvent:
 				c.mCache.Delete(data)
+				c.candidates.invalidate("")
+				c.invalidateIndex()
 				select {
 				case c.invalidationEvent <- data:
 				default:
@@ -100,10 +122,13 @@ func NewCache(r *database.Redis) *Cache {
 				}
 			case data := <-c.fileUpdateEvent:
 				c.fiCache.Delete(data)
+				c.candidates.invalidate(data)
+				c.tree.invalidate()
 			case data := <-c.mirrorFileUpdateEvent:
 				s := strings.SplitN(data, " ", 2)
 				c.fmCache.Delete(s[1])
-				c.fimCache.Delete(fmt.Sprintf("%s|%s", s[0], s[1]))
+				c.fimCache.Delete(s[0] + "|" + s[1])
+				c.candidates.invalidate(s[1])
 			case <-c.pubsubReconnectedEvent:
 				c.Clear()
 			}
@@ -119,6 +144,9 @@ func (c *Cache) Clear() {
 	c.fmCache.Clear()
 	c.mCache.Clear()
 	c.fimCache.Clear()
+	c.candidates.invalidate("")
+	c.invalidateIndex()
+	c.tree.invalidate()
 }
 
 // GetMirrorInvalidationEvent returns a channel that contains ID of mirrors
@@ -163,63 +191,165 @@ func (c *Cache) fetchFileInfo(path string) (f filesystem.FileInfo, err error) {
 // GetMirrors returns all the mirrors serving a given file either from the cache
 // or directly from the database if the object is not yet stored in the cache.
 func (c *Cache) GetMirrors(path string, clientInfo network.GeoIPRecord) (mirrors []Mirror, err error) {
+	var continent string
+	if clientInfo.IsValid() {
+		continent = clientInfo.ContinentCode
+	}
+	mirrors, err = c.GetFileMirrors(path, continent)
+	if err != nil {
+		return
+	}
+	for i := range mirrors {
+		mirrors[i].Locate(clientInfo)
+	}
+	return
+}
+
+// GetFileMirrors returns all the mirrors serving a given file like GetMirrors,
+// without locating them relative to a client. The files carried by more than
+// MaxCandidateMirrors mirrors only return a random sample of them, drawn for
+// each call, along with all the enabled mirrors of the given continent
+// carrying the file.
+func (c *Cache) GetFileMirrors(path, continent string) (mirrors []Mirror, err error) {
 	var mirrorsIDs []int
+	var sampled bool
 	v, ok := c.fmCache.Get(path)
 	if ok {
-		mirrorsIDs = v.(*fileMirrorValue).value
+		mirrorsIDs, sampled = v.(*fileMirrorValue).value, v.(*fileMirrorValue).sampled
 	} else {
-		mirrorsIDs, err = c.fetchFileMirrors(path)
+		mirrorsIDs, sampled, err = c.fetchFileMirrors(path)
+		if err != nil {
+			return
+		}
+	}
+	if sampled {
+		mirrorsIDs, err = c.sampleFileMirrors(path, continent)
 		if err != nil {
 			return
 		}
 	}
-	mirrors = make([]Mirror, 0, len(mirrorsIDs))
-	for _, id := range mirrorsIDs {
-		var mirror Mirror
-		var fileInfo filesystem.FileInfo
-		v, ok := c.mCache.Get(strconv.Itoa(id))
-		if ok {
-			mirror = v.(*mirrorValue).value
+
+	// Look up the cache once and load all the missing entries at once, the
+	// mirrors and their information about the file being stored in two
+	// slices to save an allocation per mirror
+	mirrors = make([]Mirror, len(mirrorsIDs))
+	fileInfos := make([]filesystem.FileInfo, len(mirrorsIDs))
+	var missingMirrors, missingFileInfos []int
+	for i, id := range mirrorsIDs {
+		if v, ok := c.mCache.Get(strconv.Itoa(id)); ok {
+			mirrors[i] = v.(*mirrorValue).value
+		} else {
+			missingMirrors = append(missingMirrors, i)
+		}
+		if v, ok := c.fimCache.Get(fileMirrorKey(id, path)); ok {
+			fileInfos[i] = v.(*fileInfoValue).value
 		} else {
-			//TODO execute missing items in a MULTI query
-			mirror, err = c.fetchMirror(id)
-			if err != nil {
+			missingFileInfos = append(missingFileInfos, i)
+		}
+	}
+	if len(missingMirrors)+len(missingFileInfos) > 0 {
+		if err = c.prefetch(path, mirrorsIDs, missingMirrors, missingFileInfos); err != nil {
+			return
+		}
+		for _, i := range missingMirrors {
+			v, ok := c.mCache.Get(strconv.Itoa(mirrorsIDs[i]))
+			if ok {
+				mirrors[i] = v.(*mirrorValue).value
+			} else if mirrors[i], err = c.fetchMirror(mirrorsIDs[i]); err != nil {
 				return
 			}
 		}
-		v, ok = c.fimCache.Get(fmt.Sprintf("%d|%s", id, path))
-		if ok {
-			fileInfo = v.(*fileInfoValue).value
-		} else {
-			fileInfo, err = c.fetchFileInfoMirror(id, path)
-			if err != nil {
+		for _, i := range missingFileInfos {
+			v, ok := c.fimCache.Get(fileMirrorKey(mirrorsIDs[i], path))
+			if ok {
+				fileInfos[i] = v.(*fileInfoValue).value
+			} else if fileInfos[i], err = c.fetchFileInfoMirror(mirrorsIDs[i], path); err != nil {
 				return
 			}
 		}
-		if fileInfo.Size >= 0 {
-			mirror.FileInfo = &fileInfo
+	}
+	for i := range mirrors {
+		applyPendingState(&mirrors[i])
+		if fileInfos[i].Size >= 0 {
+			mirrors[i].FileInfo = &fileInfos[i]
 		}
 
 		// Add the path in the results so we can access it from the templates
-		mirror.FileInfo.Path = path
+		mirrors[i].FileInfo.Path = path
+	}
+	return
+}
 
-		if clientInfo.IsValid() {
-			mirror.Distance = utils.GetDistanceKm(clientInfo.Latitude,
-				clientInfo.Longitude,
-				mirror.Latitude,
-				mirror.Longitude)
-		} else {
-			mirror.Distance = 0
+// prefetch loads the mirrors and the information about the file missing
+// from the cache, given by their index in mirrorsIDs, in a single round trip
+func (c *Cache) prefetch(path string, mirrorsIDs, missingMirrors, missingFileInfos []int) error {
+	if len(missingMirrors)+len(missingFileInfos) <= 1 {
+		// Nothing to gain
+		return nil
+	}
+
+	var mirrors, fileInfos []int
+	for _, i := range missingMirrors {
+		mirrors = append(mirrors, mirrorsIDs[i])
+	}
+	for _, i := range missingFileInfos {
+		fileInfos = append(fileInfos, mirrorsIDs[i])
+	}
+
+	rconn := c.r.Get()
+	defer rconn.Close()
+
+	for _, id := range mirrors {
+		rconn.Send("HGETALL", fmt.Sprintf("MIRROR_%d", id))
+	}
+	for _, id := range fileInfos {
+		rconn.Send("HGET", fmt.Sprintf("FILEINFOS_%d", id), path)
+	}
+	if err := rconn.Flush(); err != nil {
+		return err
+	}
+
+	for _, id := range mirrors {
+		reply, err := redis.Values(rconn.Receive())
+		if err != nil {
+			return err
+		}
+		if len(reply) > 0 {
+			// Missing mirrors are reported when fetched
+			c.storeMirror(id, reply)
 		}
-		mirrors = append(mirrors, mirror)
 	}
-	return
+	for _, id := range fileInfos {
+		reply, err := redis.Bytes(rconn.Receive())
+		if err != nil && err != redis.ErrNil {
+			return err
+		}
+		c.storeFileInfoMirror(id, path, reply)
+	}
+	return nil
 }
 
-func (c *Cache) fetchFileMirrors(path string) (ids []int, err error) {
+func (c *Cache) fetchFileMirrors(path string) (ids []int, sampled bool, err error) {
 	rconn := c.r.Get()
 	defer rconn.Close()
-	ids, err = redis.Ints(rconn.Do("SMEMBERS", fmt.Sprintf("FILEMIRRORS_%s", path)))
+
+	key := fmt.Sprintf("FILEMIRRORS_%s", path)
+
+	// The files carried by many mirrors are sampled for each request,
+	// sparing the transfer of the whole set (see sampleFileMirrors)
+	if max := GetConfig().MaxCandidateMirrors; max > 0 {
+		var count int
+		count, err = redis.Int(rconn.Do("SCARD", key))
+		if err != nil {
+			return
+		}
+		if count > max {
+			c.fmCache.Set(path, &fileMirrorValue{sampled: true})
+			return nil, true, nil
+		}
+	}
+
+	ids, err = redis.Ints(rconn.Do("SMEMBERS", key))
 	if err != nil {
 		return
 	}
@@ -227,6 +357,101 @@ func (c *Cache) fetchFileMirrors(path string) (ids []int, err error) {
 	return
 }
 
+// sampleFileMirrors returns a random sample of MaxCandidateMirrors mirrors of
+// the file, completed by the enabled mirrors of the given continent carrying
+// it so that the closest mirrors are still considered
+func (c *Cache) sampleFileMirrors(path, continent string) (ids []int, err error) {
+	index, err := c.getIndex()
+	if err != nil {
+		return
+	}
+
+	rconn := c.r.Get()
+	defer rconn.Close()
+
+	key := fmt.Sprintf("FILEMIRRORS_%s", path)
+
+	ids, err = redis.Ints(rconn.Do("SRANDMEMBER", key, GetConfig().MaxCandidateMirrors))
+	if err != nil {
+		return
+	}
+
+	sampled := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		sampled[id] = true
+	}
+	var local []int
+	for _, id := range index.byContinent[continent] {
+		if !sampled[id] {
+			rconn.Send("SISMEMBER", key, id)
+			local = append(local, id)
+		}
+	}
+	if len(local) == 0 {
+		return
+	}
+	if err = rconn.Flush(); err != nil {
+		return
+	}
+	for _, id := range local {
+		var member bool
+		member, err = redis.Bool(rconn.Receive())
+		if err != nil {
+			return
+		}
+		if member {
+			ids = append(ids, id)
+		}
+	}
+	return
+}
+
+// getIndex returns the index of the mirrors, built on first use after any
+// update of the mirrors
+func (c *Cache) getIndex() (*mirrorIndex, error) {
+	c.indexLock.Lock()
+	defer c.indexLock.Unlock()
+
+	if c.index != nil {
+		return c.index, nil
+	}
+
+	rconn := c.r.Get()
+	ids, err := redis.Ints(rconn.Do("HKEYS", "MIRRORS"))
+	rconn.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	index := &mirrorIndex{
+		byContinent: make(map[string][]int),
+	}
+	for _, id := range ids {
+		mirror, err := c.GetMirror(id)
+		if err == redis.ErrNil {
+			// Being removed
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if !mirror.Enabled {
+			continue
+		}
+		index.enabled++
+		if mirror.ContinentCode != "" {
+			index.byContinent[mirror.ContinentCode] = append(index.byContinent[mirror.ContinentCode], id)
+		}
+	}
+	c.index = index
+	return index, nil
+}
+
+func (c *Cache) invalidateIndex() {
+	c.indexLock.Lock()
+	c.index = nil
+	c.indexLock.Unlock()
+}
+
 func (c *Cache) fetchMirror(mirrorID int) (mirror Mirror, err error) {
 	rconn := c.r.Get()
 	defer rconn.Close()
@@ -238,19 +463,28 @@ func (c *Cache) fetchMirror(mirrorID int) (mirror Mirror, err error) {
 		err = redis.ErrNil
 		return
 	}
+	return c.storeMirror(mirrorID, reply)
+}
+
+func (c *Cache) storeMirror(mirrorID int, reply []interface{}) (mirror Mirror, err error) {
 	err = redis.ScanStruct(reply, &mirror)
 	if err != nil {
 		return
 	}
-	mirror.Prepare()
 	c.mCache.Set(strconv.Itoa(mirrorID), &mirrorValue{value: mirror})
 	return
 }
 
+// fileMirrorKey returns the key of the information about the file on the
+// given mirror in fimCache
+func fileMirrorKey(id int, path string) string {
+	return strconv.Itoa(id) + "|" + path
+}
+
 func (c *Cache) GetFileInfoMirror(mirrorID int, path string) (f filesystem.FileInfo, err error) {
 	var fileInfo filesystem.FileInfo
 
-	v, ok := c.fimCache.Get(fmt.Sprintf("%d|%s", mirrorID, path))
+	v, ok := c.fimCache.Get(fileMirrorKey(mirrorID, path))
 	if ok {
 		fileInfo = v.(*fileInfoValue).value
 	} else {
@@ -265,23 +499,24 @@ func (c *Cache) GetFileInfoMirror(mirrorID int, path string) (f filesystem.FileI
 func (c *Cache) fetchFileInfoMirror(id int, path string) (f filesystem.FileInfo, err error) {
 	rconn := c.r.Get()
 	defer rconn.Close()
-	f.Path = path // Path is not stored in the object instance in redis
 
-	reply, err := redis.Strings(rconn.Do("HMGET", fmt.Sprintf("FILEINFO_%d_%s", id, path), "size", "modTime", "sha1", "sha256", "md5"))
-	if err != nil {
+	reply, err := redis.Bytes(rconn.Do("HGET", fmt.Sprintf("FILEINFOS_%d", id), path))
+	if err != nil && err != redis.ErrNil {
 		return
 	}
+	return c.storeFileInfoMirror(id, path, reply)
+}
 
-	// Note: as of today, only the size is stored by the scanners
-	// all other fields are left blank.
+func (c *Cache) storeFileInfoMirror(id int, path string, reply []byte) (f filesystem.FileInfo, err error) {
+	// Note: as of today, only the size and the modification time are
+	// stored by the scanners, all other fields are left blank.
 
-	f.Size, _ = strconv.ParseInt(reply[0], 10, 64)
-	f.ModTime, _ = time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", reply[1])
-	f.Sha1 = reply[2]
-	f.Sha256 = reply[3]
-	f.Md5 = reply[4]
+	f, err = filesystem.UnpackFileInfo(path, reply)
+	if err != nil {
+		return
+	}
 
-	c.fimCache.Set(fmt.Sprintf("%d|%s", id, path), &fileInfoValue{value: f})
+	c.fimCache.Set(fileMirrorKey(id, path), &fileInfoValue{value: f})
 	return
 }
 
@@ -297,5 +532,15 @@ func (c *Cache) GetMirror(id int) (mirror Mirror, err error) {
 			return
 		}
 	}
+	applyPendingState(&mirror)
 	return
 }
+
+// GetEnabledMirrorCount returns the number of mirrors currently enabled
+func (c *Cache) GetEnabledMirrorCount() (int, error) {
+	index, err := c.getIndex()
+	if err != nil {
+		return 0, err
+	}
+	return index.enabled, nil
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 	"unsafe"
 
+	. "github.com/etix/mirrorbits/config"
 	"github.com/etix/mirrorbits/filesystem"
 	"github.com/etix/mirrorbits/network"
 	. "github.com/etix/mirrorbits/testing"
@@ -182,13 +183,15 @@ func TestCache_GetFileInfo(t *testing.T) {
 }
 
 func TestCache_fetchFileMirrors(t *testing.T) {
+	SetConfiguration(&Configuration{})
+
 	mock, conn := PrepareRedisTest()
 	conn.ConnectPubsub()
 
 	c := NewCache(conn)
 	filename := "/test/file.tgz"
 
-	_, err := c.fetchFileMirrors(filename)
+	_, _, err := c.fetchFileMirrors(filename)
 	if err == nil {
 		t.Fatalf("Error expected, mock command not yet registered")
 	}
@@ -199,7 +202,7 @@ func TestCache_fetchFileMirrors(t *testing.T) {
 		[]byte("5"),
 	})
 
-	ids, err := c.fetchFileMirrors(filename)
+	ids, _, err := c.fetchFileMirrors(filename)
 	if err != nil {
 		t.Fatalf("Unexpected error: %s", err.Error())
 	}
@@ -216,6 +219,102 @@ func TestCache_fetchFileMirrors(t *testing.T) {
 	if !ok {
 		t.Fatalf("Not stored in cache")
 	}
+
+	/* The files carried by many mirrors are sampled for each request */
+
+	SetConfiguration(&Configuration{MaxCandidateMirrors: 2})
+	defer SetConfiguration(&Configuration{})
+
+	c.fmCache.Clear()
+	mock.Command("SCARD", "FILEMIRRORS_"+filename).Expect(int64(3))
+
+	ids, sampled, err := c.fetchFileMirrors(filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if !sampled || len(ids) != 0 || mock.Stats(cmdGetFilemirrors) != 1 {
+		t.Fatalf("Expected the file to be sampled, got %v", ids)
+	}
+	if v, ok := c.fmCache.Get(filename); !ok || !v.(*fileMirrorValue).sampled {
+		t.Fatalf("The sampling must be stored in cache")
+	}
+}
+
+func TestCache_GetMirrors_sampled(t *testing.T) {
+	SetConfiguration(&Configuration{MaxCandidateMirrors: 1})
+	defer SetConfiguration(&Configuration{})
+
+	mock, conn := PrepareRedisTest()
+	conn.ConnectPubsub()
+
+	c := NewCache(conn)
+
+	filename := "/test/file.tgz"
+	key := "FILEMIRRORS_" + filename
+
+	// A client in Paris
+	clientInfo := network.GeoIPRecord{
+		CountryCode:   "FR",
+		ContinentCode: "EU",
+		Latitude:      48.8567,
+		Longitude:     2.3508,
+	}
+
+	mock.Command("SCARD", key).Expect(int64(3))
+	// The random sample only holds the mirror in New York
+	cmdSample := mock.Command("SRANDMEMBER", key, 1).Expect([]interface{}{
+		[]byte("2"),
+	})
+	mock.Command("HKEYS", "MIRRORS").Expect([]interface{}{
+		[]byte("1"),
+		[]byte("2"),
+		[]byte("3"),
+	})
+	mock.Command("HGETALL", "MIRROR_1").ExpectMap(map[string]string{
+		"ID":            "1",
+		"enabled":       "1",
+		"continentCode": "EU",
+		"latitude":      "52.5167",
+		"longitude":     "13.3833",
+	})
+	mock.Command("HGETALL", "MIRROR_2").ExpectMap(map[string]string{
+		"ID":            "2",
+		"enabled":       "1",
+		"continentCode": "NA",
+		"latitude":      "40.7128",
+		"longitude":     "-74.0060",
+	})
+	mock.Command("HGETALL", "MIRROR_3").ExpectMap(map[string]string{
+		"ID":            "3",
+		"enabled":       "1",
+		"continentCode": "EU",
+		"latitude":      "51.5072",
+		"longitude":     "0.1275",
+	})
+	// The mirror in Berlin carries the file, not the one in London
+	cmdMember1 := mock.Command("SISMEMBER", key, 1).Expect(int64(1))
+	cmdMember3 := mock.Command("SISMEMBER", key, 3).Expect(int64(0))
+	for _, id := range []string{"1", "2", "3"} {
+		mock.Command("HGET", "FILEINFOS_"+id, filename).Expect(filesystem.FileInfo{Size: 44000}.Pack())
+	}
+
+	for i := 1; i <= 2; i++ {
+		mirrors, err := c.GetMirrors(filename, clientInfo)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if len(mirrors) != 2 || mirrors[0].ID != 2 || mirrors[1].ID != 1 {
+			t.Fatalf("Expected the sampled mirror and the mirror of the continent of the client, got %v", mirrors)
+		}
+		// The closest mirror is still a candidate
+		if mirrors[1].Distance >= mirrors[0].Distance {
+			t.Fatalf("The mirror of the continent should be the closest: %f >= %f", mirrors[1].Distance, mirrors[0].Distance)
+		}
+		// The sample is not cached
+		if mock.Stats(cmdSample) != i || mock.Stats(cmdMember1) != i || mock.Stats(cmdMember3) != i {
+			t.Fatalf("Expected a new sample for each request")
+		}
+	}
 }
 
 func TestCache_fetchMirror(t *testing.T) {
@@ -243,7 +342,7 @@ func TestCache_fetchMirror(t *testing.T) {
 		Latitude:       -20.0,
 		Longitude:      55.0,
 		ContinentCode:  "EU",
-		CountryCodes:   "FR UK",
+		CountryCodes:   CountryList{"FR", "GB"},
 		Asnum:          444,
 		Comment:        "m1comment",
 		Enabled:        true,
@@ -274,7 +373,7 @@ func TestCache_fetchMirror(t *testing.T) {
 		"latitude":      fmt.Sprintf("%f", testmirror.Latitude),
 		"longitude":     fmt.Sprintf("%f", testmirror.Longitude),
 		"continentCode": testmirror.ContinentCode,
-		"countryCodes":  testmirror.CountryCodes,
+		"countryCodes":  `["FR","GB"]`,
 		"asnum":         strconv.FormatInt(int64(testmirror.Asnum), 10),
 		"comment":       testmirror.Comment,
 		"enabled":       strconv.FormatBool(testmirror.Enabled),
@@ -290,9 +389,6 @@ func TestCache_fetchMirror(t *testing.T) {
 		t.Fatalf("HGETALL not executed")
 	}
 
-	// This is required to reach DeepEqual(ity)
-	testmirror.Prepare()
-
 	if !reflect.DeepEqual(testmirror, m) {
 		t.Fatalf("Result is different")
 	}
@@ -323,19 +419,17 @@ func TestCache_fetchFileInfoMirror(t *testing.T) {
 		t.Fatalf("Error expected, mock command not yet registered")
 	}
 
-	cmdGetFileinfomirror := mock.Command("HMGET", "FILEINFO_1_"+testfile.Path, "size", "modTime", "sha1", "sha256", "md5").ExpectMap(map[string]string{
-		"size":    strconv.FormatInt(testfile.Size, 10),
-		"modTime": testfile.ModTime.String(),
-		"sha1":    testfile.Sha1,
-		"sha256":  testfile.Sha256,
-		"md5":     testfile.Md5,
-	})
+	cmdGetFileinfomirror := mock.Command("HGET", "FILEINFOS_1", testfile.Path).Expect(testfile.Pack())
 
-	_, err = c.fetchFileInfoMirror(1, testfile.Path)
+	f, err := c.fetchFileInfoMirror(1, testfile.Path)
 	if err != nil {
 		t.Fatalf("Unexpected error: %s", err.Error())
 	}
 
+	if f.Path != testfile.Path || f.Size != testfile.Size || !f.ModTime.Equal(testfile.ModTime) {
+		t.Fatalf("Expected %+v, got %+v", testfile, f)
+	}
+
 	if mock.Stats(cmdGetFileinfomirror) < 1 {
 		t.Fatalf("HGETALL not executed")
 	}
@@ -385,6 +479,8 @@ func TestCache_GetMirror(t *testing.T) {
 }
 
 func TestCache_GetMirrors(t *testing.T) {
+	SetConfiguration(&Configuration{})
+
 	mock, conn := PrepareRedisTest()
 	conn.ConnectPubsub()
 
@@ -420,21 +516,9 @@ func TestCache_GetMirrors(t *testing.T) {
 		"longitude": "0.1275",
 	})
 
-	cmdGetFileinfomirrorM1 := mock.Command("HMGET", "FILEINFO_1_"+filename, "size", "modTime", "sha1", "sha256", "md5").ExpectMap(map[string]string{
-		"size":    "44000",
-		"modTime": "",
-		"sha1":    "",
-		"sha256":  "",
-		"md5":     "",
-	})
+	cmdGetFileinfomirrorM1 := mock.Command("HGET", "FILEINFOS_1", filename).Expect(filesystem.FileInfo{Size: 44000}.Pack())
 
-	cmdGetFileinfomirrorM2 := mock.Command("HMGET", "FILEINFO_2_"+filename, "size", "modTime", "sha1", "sha256", "md5").ExpectMap(map[string]string{
-		"size":    "44000",
-		"modTime": "",
-		"sha1":    "",
-		"sha256":  "",
-		"md5":     "",
-	})
+	cmdGetFileinfomirrorM2 := mock.Command("HGET", "FILEINFOS_2", filename).Expect(filesystem.FileInfo{Size: 44000}.Pack())
 
 	mirrors, err := c.GetMirrors(filename, clientInfo)
 	if err != nil {
@@ -469,3 +553,84 @@ func TestCache_GetMirrors(t *testing.T) {
 		t.Fatalf("Distance between user and m2 is wrong, got %d, expected 334", int(mirrors[1].Distance))
 	}
 }
+
+func TestCache_GetCandidates(t *testing.T) {
+	_, conn := PrepareRedisTest()
+	conn.ConnectPubsub()
+
+	c := NewCache(conn)
+
+	if _, _, ok := c.GetCandidates("/file.tgz", "EU"); ok {
+		t.Fatalf("No candidates expected")
+	}
+
+	c.SetCandidates("/file.tgz", "EU", Mirrors{{ID: 1}}, Mirrors{{ID: 2}}, time.Minute)
+	c.SetCandidates("/other.tgz", "EU", Mirrors{{ID: 1}}, nil, -time.Second)
+
+	selected, excluded, ok := c.GetCandidates("/file.tgz", "EU")
+	if !ok || len(selected) != 1 || selected[0].ID != 1 || len(excluded) != 1 || excluded[0].ID != 2 {
+		t.Fatalf("Unexpected candidates: %v %v %t", selected, excluded, ok)
+	}
+	selected[0].ID = 3
+	if selected, _, _ = c.GetCandidates("/file.tgz", "EU"); selected[0].ID != 1 {
+		t.Fatalf("The stored candidates must not be modified by the caller")
+	}
+	if _, _, ok = c.GetCandidates("/file.tgz", "NA"); ok {
+		t.Fatalf("No candidates expected for another continent")
+	}
+	if _, _, ok = c.GetCandidates("/other.tgz", "EU"); ok {
+		t.Fatalf("The expired candidates must be ignored")
+	}
+
+	c.candidates.invalidate("/file.tgz")
+	if _, _, ok = c.GetCandidates("/file.tgz", "EU"); ok {
+		t.Fatalf("The candidates of the file must be dropped")
+	}
+
+	c.SetCandidates("/file.tgz", "EU", Mirrors{{ID: 1}}, nil, time.Minute)
+	c.Clear()
+	if _, _, ok = c.GetCandidates("/file.tgz", "EU"); ok {
+		t.Fatalf("The candidates must be dropped along with the cache")
+	}
+}
+
+func BenchmarkCache_GetMirrors(b *testing.B) {
+	SetConfiguration(&Configuration{})
+
+	mock, conn := PrepareRedisTest()
+	conn.ConnectPubsub()
+
+	c := NewCache(conn)
+
+	filename := "/test/file.tgz"
+
+	clientInfo := network.GeoIPRecord{
+		CountryCode:   "FR",
+		ContinentCode: "EU",
+		Latitude:      48.8567,
+		Longitude:     2.3508,
+	}
+
+	var ids []interface{}
+	for i := 1; i <= 10; i++ {
+		ids = append(ids, []byte(strconv.Itoa(i)))
+		mock.Command("HGETALL", fmt.Sprintf("MIRROR_%d", i)).ExpectMap(map[string]string{
+			"ID":        strconv.Itoa(i),
+			"latitude":  "52.5167",
+			"longitude": "13.3833",
+		})
+		mock.Command("HGET", fmt.Sprintf("FILEINFOS_%d", i), filename).Expect(filesystem.FileInfo{Size: 44000}.Pack())
+	}
+	mock.Command("SMEMBERS", "FILEMIRRORS_"+filename).Expect(ids)
+
+	// Warm up the cache
+	if _, err := c.GetMirrors(filename, clientInfo); err != nil {
+		b.Fatalf("Unexpected error: %s", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.GetMirrors(filename, clientInfo)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// Remove the expired candidates once the cache holds this many files
+	candidateCacheCleanup = 1000
+)
+
+// candidateCache holds the mirrors of the hot files precomputed for the
+// clients of each continent. The entries are dropped on any update of the
+// mirrors or of the file.
+//
+// Only the mirrors able to serve the file are cached, not their order:
+// the scores depend on the distance, the country, the AS and the region of
+// each client, and the final order on a random draw weighted by these
+// scores.
+type candidateCache struct {
+	sync.RWMutex
+	entries map[string]map[string]candidates
+}
+
+type candidates struct {
+	selected Mirrors
+	excluded Mirrors
+	expires  time.Time
+}
+
+// GetCandidates returns a copy of the candidate mirrors of the file stored
+// for the clients of the given continent and of the mirrors excluded for
+// all of them
+func (c *Cache) GetCandidates(path, continent string) (selected, excluded Mirrors, ok bool) {
+	c.candidates.RLock()
+	entry, ok := c.candidates.entries[path][continent]
+	c.candidates.RUnlock()
+	if !ok || time.Now().After(entry.expires) {
+		return nil, nil, false
+	}
+	selected = append(make(Mirrors, 0, len(entry.selected)), entry.selected...)
+	excluded = append(make(Mirrors, 0, len(entry.excluded)), entry.excluded...)
+	return selected, excluded, true
+}
+
+// SetCandidates stores the candidate mirrors of the file for the clients of
+// the given continent until the next update or the given time to live.
+// The mirrors must not be located relative to a client (see GetFileMirrors).
+// For the files carried by many mirrors, the random sample of their mirrors
+// is kept along with the candidates.
+func (c *Cache) SetCandidates(path, continent string, selected, excluded Mirrors, ttl time.Duration) {
+	c.candidates.Lock()
+	defer c.candidates.Unlock()
+	if c.candidates.entries == nil {
+		c.candidates.entries = make(map[string]map[string]candidates)
+	}
+	if c.candidates.entries[path] == nil {
+		if len(c.candidates.entries) >= candidateCacheCleanup {
+			c.candidates.cleanup()
+		}
+		c.candidates.entries[path] = make(map[string]candidates)
+	}
+	c.candidates.entries[path][continent] = candidates{
+		selected: append(Mirrors(nil), selected...),
+		excluded: append(Mirrors(nil), excluded...),
+		expires:  time.Now().Add(ttl),
+	}
+}
+
+// cleanup drops the expired candidates
+func (c *candidateCache) cleanup() {
+	now := time.Now()
+	for path, continents := range c.entries {
+		for continent, entry := range continents {
+			if now.After(entry.expires) {
+				delete(continents, continent)
+			}
+		}
+		if len(continents) == 0 {
+			delete(c.entries, path)
+		}
+	}
+}
+
+// invalidate drops the candidates of the given file, or of all the files
+// if empty
+func (c *candidateCache) invalidate(path string) {
+	c.Lock()
+	defer c.Unlock()
+	if path == "" {
+		c.entries = nil
+	} else {
+		delete(c.entries, path)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/utils"
+)
+
+// ChannelList is the list of channels (see Channels in the configuration)
+// carried by a mirror, an empty list meaning the whole repository
+type ChannelList []string
+
+// ParseChannelList parses a list of channels separated by spaces or commas
+func ParseChannelList(input string) ChannelList {
+	return nilIfEmptyChannels(strings.Fields(strings.Replace(input, ",", " ", -1)))
+}
+
+// Normalize returns the list in lower case without duplicates or an error
+// if any of the channels is unknown
+func (c ChannelList) Normalize() (ChannelList, error) {
+	var list []string
+	for _, name := range c {
+		name = strings.ToLower(name)
+		if _, ok := GetConfig().Channels[name]; !ok {
+			return nil, fmt.Errorf("unknown channel %s", name)
+		}
+		if !utils.IsInSlice(name, list) {
+			list = append(list, name)
+		}
+	}
+	return nilIfEmptyChannels(list), nil
+}
+
+// String returns the channels separated by spaces
+func (c ChannelList) String() string {
+	return strings.Join(c, " ")
+}
+
+// Prefixes returns the sorted directories of the channels, without the
+// ones contained in another, or nil if the list is empty (i.e. the whole
+// repository)
+func (c ChannelList) Prefixes() []string {
+	if len(c) == 0 {
+		return nil
+	}
+	prefixes := []string{}
+	for _, name := range c {
+		for _, p := range GetConfig().Channels[name] {
+			if !utils.IsInSlice(p, prefixes) {
+				prefixes = append(prefixes, p)
+			}
+		}
+	}
+	sort.Strings(prefixes)
+
+	// Remove the directories contained in another one
+	list := prefixes[:0]
+	for _, p := range prefixes {
+		if !HasPathPrefix(p, list) {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// RedisArg implements the redis.Argument interface
+func (c ChannelList) RedisArg() interface{} {
+	return c.String()
+}
+
+// RedisScan implements the redis.Scanner interface
+func (c *ChannelList) RedisScan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		*c = nilIfEmptyChannels(strings.Fields(string(v)))
+	case string:
+		*c = nilIfEmptyChannels(strings.Fields(v))
+	case nil:
+		*c = nil
+	default:
+		return fmt.Errorf("cannot convert from %T to ChannelList", src)
+	}
+	return nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface and accepts
+// either a sequence or a string of channels separated by spaces or commas
+func (c *ChannelList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*c = nilIfEmptyChannels(list)
+		return nil
+	}
+
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	*c = ParseChannelList(s)
+	return nil
+}
+
+// Carries returns true if the file is part of one of the channels of the
+// mirror. A mirror without channel carries the whole repository.
+func (m *Mirror) Carries(path string) bool {
+	prefixes := m.Channels.Prefixes()
+	return prefixes == nil || HasPathPrefix(path, prefixes)
+}
+
+// HasPathPrefix returns true if the path is one of the given directories
+// or is located below one of them
+func HasPathPrefix(path string, prefixes []string) bool {
+	for _, p := range prefixes {
+		p = strings.TrimSuffix(p, "/")
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func nilIfEmptyChannels(list []string) ChannelList {
+	if len(list) == 0 {
+		return nil
+	}
+	return ChannelList(list)
+}
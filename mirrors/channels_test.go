@@ -0,0 +1,53 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/etix/mirrorbits/config"
+)
+
+func TestChannelList(t *testing.T) {
+	SetConfiguration(&Configuration{
+		Channels: map[string][]string{
+			"releases":  {"/releases"},
+			"nightlies": {"/nightlies/", "/releases/beta"},
+		},
+	})
+
+	list, err := ParseChannelList("Releases, nightlies releases").Normalize()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(list, ChannelList{"releases", "nightlies"}) {
+		t.Fatalf("Unexpected list %v", list)
+	}
+	if _, err := ParseChannelList("torrents").Normalize(); err == nil {
+		t.Fatalf("An unknown channel should be rejected")
+	}
+
+	if p := list.Prefixes(); !reflect.DeepEqual(p, []string{"/nightlies/", "/releases"}) {
+		t.Fatalf("Unexpected prefixes %v", p)
+	}
+	if p := ChannelList(nil).Prefixes(); p != nil {
+		t.Fatalf("A mirror without channel should have no prefix, got %v", p)
+	}
+
+	m := Mirror{Channels: ChannelList{"nightlies"}}
+	for path, expected := range map[string]bool{
+		"/nightlies/a.iso":     true,
+		"/releases/beta/b.iso": true,
+		"/releases/c.iso":      false,
+		"/releases-old/d.iso":  false,
+	} {
+		if m.Carries(path) != expected {
+			t.Fatalf("Carries(%s) should be %t", path, expected)
+		}
+	}
+	if m := (Mirror{}); !m.Carries("/releases/c.iso") {
+		t.Fatalf("A mirror without channel should carry all the files")
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/database"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// ContactUnverified means the contact never confirmed its address
+	ContactUnverified = "unverified"
+	// ContactPending means a verification token has been sent
+	ContactPending = "pending"
+	// ContactVerified means the contact confirmed its address
+	ContactVerified = "verified"
+	// ContactBounced means the verification email could not be delivered
+	ContactBounced = "bounced"
+)
+
+var (
+	// ErrContactVerificationDisabled is returned when no SMTP server is configured
+	ErrContactVerificationDisabled = errors.New("contact verification is not configured")
+	// ErrNoContact is returned when the mirror has no admin email
+	ErrNoContact = errors.New("the mirror has no admin email")
+	// ErrInvalidContactToken is returned when the token is unknown or expired
+	ErrInvalidContactToken = errors.New("invalid or expired token")
+)
+
+// ContactVerification is the verification status of the admin email of a mirror
+type ContactVerification struct {
+	Email  string
+	Status string
+	Since  time.Time
+}
+
+type contactToken struct {
+	ID    int
+	Email string
+}
+
+// GetContactVerification returns the verification status of the given email
+// of the mirror. A status recorded for another address is discarded.
+func GetContactVerification(r *database.Redis, id int, email string) (ContactVerification, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	return getContactVerification(conn, id, email)
+}
+
+func getContactVerification(conn redis.Conn, id int, email string) (ContactVerification, error) {
+	v := ContactVerification{
+		Email:  email,
+		Status: ContactUnverified,
+	}
+
+	value, err := redis.Bytes(conn.Do("GET", fmt.Sprintf("CONTACT_%d", id)))
+	if err == redis.ErrNil {
+		return v, nil
+	} else if err != nil {
+		return v, err
+	}
+
+	var stored ContactVerification
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return v, err
+	}
+	if !strings.EqualFold(stored.Email, email) {
+		return v, nil
+	}
+	return stored, nil
+}
+
+// SetContactStatus records the verification status of the email of a mirror
+func SetContactStatus(r *database.Redis, id int, email, status string) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	return setContactStatus(conn, id, email, status)
+}
+
+func setContactStatus(conn redis.Conn, id int, email, status string) error {
+	value, err := json.Marshal(ContactVerification{
+		Email:  email,
+		Status: status,
+		Since:  time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = conn.Do("SET", fmt.Sprintf("CONTACT_%d", id), value)
+	return err
+}
+
+// SendContactVerification emails a verification link to the admin of the
+// given mirror. The contact is marked as bounced if the email is refused.
+func SendContactVerification(r *database.Redis, mirror *Mirror) error {
+	cfg := GetConfig().ContactVerification
+	if cfg.SMTPServer == "" {
+		return ErrContactVerificationDisabled
+	}
+	if mirror.AdminEmail == "" {
+		return ErrNoContact
+	}
+
+	conn := r.Get()
+	defer conn.Close()
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(b)
+
+	value, err := json.Marshal(contactToken{
+		ID:    mirror.ID,
+		Email: mirror.AdminEmail,
+	})
+	if err != nil {
+		return err
+	}
+
+	validity := time.Duration(cfg.Validity) * 24 * time.Hour
+	_, err = conn.Do("SET", "CONTACTTOKEN_"+token, value, "EX", int(validity.Seconds()))
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hello %s,\r\n\r\n"+
+		"You are registered as the contact of the mirror %s (%s).\r\n"+
+		"Please confirm your email address by opening the following link within %d days:\r\n\r\n"+
+		"%s?verifycontact=%s\r\n",
+		mirror.AdminName, mirror.Name, mirror.HttpURL, cfg.Validity, cfg.URL, token)
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: Please confirm the contact of mirror %s\r\n"+
+		"Content-Type: text/plain; charset=utf-8\r\n\r\n%s",
+		cfg.From, mirror.AdminEmail, mirror.Name, body)
+
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		host, _, _ := net.SplitHostPort(cfg.SMTPServer)
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, host)
+	}
+
+	if err := smtp.SendMail(cfg.SMTPServer, auth, cfg.From, []string{mirror.AdminEmail}, []byte(msg)); err != nil {
+		conn.Do("DEL", "CONTACTTOKEN_"+token)
+		if isPermanentFailure(err) {
+			setContactStatus(conn, mirror.ID, mirror.AdminEmail, ContactBounced)
+		}
+		return err
+	}
+
+	return setContactStatus(conn, mirror.ID, mirror.AdminEmail, ContactPending)
+}
+
+// isPermanentFailure returns true if the SMTP server refused the email with
+// a permanent failure (5xx), likely caused by an invalid address
+func isPermanentFailure(err error) bool {
+	tperr, ok := err.(*textproto.Error)
+	return ok && tperr.Code >= 500
+}
+
+// ConfirmContactToken marks the contact associated to the token as verified
+// and returns the identifier of the mirror. The token is consumed atomically
+// so it can only be used once.
+func ConfirmContactToken(r *database.Redis, token string) (int, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	key := "CONTACTTOKEN_" + token
+
+	conn.Send("MULTI")
+	conn.Send("GET", key)
+	conn.Send("DEL", key)
+	res, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := redis.Bytes(res[0], nil)
+	if err == redis.ErrNil {
+		return 0, ErrInvalidContactToken
+	} else if err != nil {
+		return 0, err
+	}
+
+	var t contactToken
+	if err := json.Unmarshal(value, &t); err != nil {
+		return 0, err
+	}
+
+	if err := setContactStatus(conn, t.ID, t.Email, ContactVerified); err != nil {
+		return 0, err
+	}
+
+	return t.ID, nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"errors"
+	"net/textproto"
+	"testing"
+
+	. "github.com/etix/mirrorbits/testing"
+	"github.com/gomodule/redigo/redis"
+)
+
+func TestGetContactVerification(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	mock.Command("GET", "CONTACT_1").Expect([]byte(`{"Email":"Admin@example.org","Status":"verified"}`))
+	mock.Command("GET", "CONTACT_2").ExpectError(redis.ErrNil)
+
+	v, err := GetContactVerification(conn, 1, "admin@example.org")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if v.Status != ContactVerified {
+		t.Fatalf("Expected %s, got %s", ContactVerified, v.Status)
+	}
+
+	v, err = GetContactVerification(conn, 1, "other@example.org")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if v.Status != ContactUnverified {
+		t.Fatalf("Expected the status of another address to be discarded, got %s", v.Status)
+	}
+
+	v, err = GetContactVerification(conn, 2, "admin@example.org")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if v.Status != ContactUnverified {
+		t.Fatalf("Expected %s, got %s", ContactUnverified, v.Status)
+	}
+}
+
+func TestConfirmContactToken(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	mock.Command("MULTI")
+	mock.Command("GET", "CONTACTTOKEN_abc")
+	cmdDel := mock.Command("DEL", "CONTACTTOKEN_abc")
+	mock.Command("EXEC").
+		Expect([]interface{}{[]byte(`{"ID":1,"Email":"admin@example.org"}`), int64(1)}).
+		Expect([]interface{}{nil, int64(0)})
+	cmdStatus := mock.GenericCommand("SET").Expect("OK")
+
+	id, err := ConfirmContactToken(conn, "abc")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if id != 1 {
+		t.Fatalf("Expected mirror 1, got %d", id)
+	}
+	if mock.Stats(cmdDel) != 1 || mock.Stats(cmdStatus) != 1 {
+		t.Fatalf("Expected the token to be consumed and the contact to be verified")
+	}
+
+	// The token can only be used once
+	if _, err := ConfirmContactToken(conn, "abc"); err != ErrInvalidContactToken {
+		t.Fatalf("Expected ErrInvalidContactToken, got %v", err)
+	}
+	if mock.Stats(cmdStatus) != 1 {
+		t.Fatalf("The contact status is not supposed to be updated")
+	}
+}
+
+func TestIsPermanentFailure(t *testing.T) {
+	tests := []struct {
+		err       error
+		permanent bool
+	}{
+		{&textproto.Error{Code: 550, Msg: "No such user"}, true},
+		{&textproto.Error{Code: 554, Msg: "Transaction failed"}, true},
+		{&textproto.Error{Code: 451, Msg: "Try again later"}, false},
+		{errors.New("550 not an SMTP reply"), false},
+		{errors.New("dial tcp: connection refused"), false},
+	}
+
+	for _, test := range tests {
+		if isPermanentFailure(test.err) != test.permanent {
+			t.Fatalf("Expected %q to be permanent: %t", test.err, test.permanent)
+		}
+	}
+}
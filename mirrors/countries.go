@@ -0,0 +1,131 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/utils"
+)
+
+// CountryList is an ordered list of ISO 3166-1 alpha-2 country codes, the
+// first one being the primary country
+type CountryList []string
+
+// ParseCountryList parses a list of country codes separated by spaces or
+// commas
+func ParseCountryList(input string) (CountryList, error) {
+	list, err := utils.ParseCountryCodes(input)
+	return CountryList(list), err
+}
+
+// Normalize returns the list in upper case without duplicates or an error
+// if any of the codes is invalid
+func (c CountryList) Normalize() (CountryList, error) {
+	list, err := utils.NormalizeCountryCodes(c)
+	return CountryList(list), err
+}
+
+// Primary returns the primary country or an empty string
+func (c CountryList) Primary() string {
+	if len(c) == 0 {
+		return ""
+	}
+	return c[0]
+}
+
+// Contains returns true if the given code is part of the list
+func (c CountryList) Contains(code string) bool {
+	return utils.IsInSlice(code, c)
+}
+
+// String returns the codes separated by spaces
+func (c CountryList) String() string {
+	return strings.Join(c, " ")
+}
+
+// RedisArg implements the redis.Argument interface, the list being stored
+// as a json array
+func (c CountryList) RedisArg() interface{} {
+	if c == nil {
+		return "[]"
+	}
+	b, _ := json.Marshal([]string(c))
+	return string(b)
+}
+
+// RedisScan implements the redis.Scanner interface. Lists stored as a
+// space separated string by older versions are still understood.
+func (c *CountryList) RedisScan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case nil:
+		*c = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot convert from %T to CountryList", src)
+	}
+
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") {
+		var list []string
+		if err := json.Unmarshal([]byte(s), &list); err != nil {
+			return err
+		}
+		*c = nilIfEmpty(list)
+		return nil
+	}
+	*c = nilIfEmpty(strings.Fields(s))
+	return nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface and accepts
+// either a sequence or a string of codes separated by spaces or commas
+func (c *CountryList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*c = nilIfEmpty(list)
+		return nil
+	}
+
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	*c = nilIfEmpty(strings.Fields(strings.Replace(s, ",", " ", -1)))
+	return nil
+}
+
+// NormalizeRegionCode returns the given region in upper case or an error if
+// it is neither an ISO 3166-2 subdivision code (e.g. US-CA) nor the name of
+// a group of Regions
+func NormalizeRegionCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return "", nil
+	}
+	if i := strings.Index(code, "-"); i > 0 && i < len(code)-1 && utils.IsValidCountryCode(code[:i]) {
+		return code, nil
+	}
+	for name := range GetConfig().Regions.Groups {
+		if strings.ToUpper(name) == code {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("invalid region %s", code)
+}
+
+func nilIfEmpty(list []string) CountryList {
+	if len(list) == 0 {
+		return nil
+	}
+	return CountryList(list)
+}
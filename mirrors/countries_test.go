@@ -0,0 +1,91 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/etix/mirrorbits/config"
+	"gopkg.in/yaml.v2"
+)
+
+func TestCountryList_RedisScan(t *testing.T) {
+	var c CountryList
+
+	if err := c.RedisScan([]byte(`["FR","BE"]`)); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(c, CountryList{"FR", "BE"}) {
+		t.Fatalf("Unexpected list %#v", c)
+	}
+
+	// Format used before the database version 2
+	if err := c.RedisScan([]byte("DE  CH")); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(c, CountryList{"DE", "CH"}) {
+		t.Fatalf("Unexpected list %#v", c)
+	}
+
+	if err := c.RedisScan([]byte("[]")); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if c != nil {
+		t.Fatalf("Expected an empty list, got %#v", c)
+	}
+
+	if err := c.RedisScan(int64(1)); err == nil {
+		t.Fatalf("Error expected")
+	}
+}
+
+func TestCountryList_RedisArg(t *testing.T) {
+	if arg := (CountryList{"FR", "BE"}).RedisArg(); arg != `["FR","BE"]` {
+		t.Fatalf("Unexpected argument %v", arg)
+	}
+	if arg := CountryList(nil).RedisArg(); arg != "[]" {
+		t.Fatalf("Unexpected argument %v", arg)
+	}
+}
+
+func TestCountryList_UnmarshalYAML(t *testing.T) {
+	var m struct {
+		A CountryList `yaml:"A"`
+		B CountryList `yaml:"B"`
+	}
+
+	if err := yaml.Unmarshal([]byte("A: [FR, BE]\nB: DE,CH IT\n"), &m); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(m.A, CountryList{"FR", "BE"}) {
+		t.Fatalf("Unexpected list %#v", m.A)
+	}
+	if !reflect.DeepEqual(m.B, CountryList{"DE", "CH", "IT"}) {
+		t.Fatalf("Unexpected list %#v", m.B)
+	}
+}
+
+func TestNormalizeRegionCode(t *testing.T) {
+	config := &Configuration{}
+	config.Regions.Groups = map[string][]string{"US-West": {"US-CA"}}
+	SetConfiguration(config)
+
+	cases := map[string]string{"us-ca": "US-CA", " BR-SP": "BR-SP", "us-west": "US-WEST", "": ""}
+	for code, expected := range cases {
+		region, err := NormalizeRegionCode(code)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %s", code, err)
+		}
+		if region != expected {
+			t.Fatalf("Expected %q for %q, got %q", expected, code, region)
+		}
+	}
+
+	for _, code := range []string{"US", "XX-CA", "US-", "EAST"} {
+		if _, err := NormalizeRegionCode(code); err == nil {
+			t.Fatalf("Expected an error for %q", code)
+		}
+	}
+}
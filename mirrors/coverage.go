@@ -0,0 +1,167 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/etix/mirrorbits/database"
+	"github.com/gomodule/redigo/redis"
+)
+
+// CountryCoverage is the traffic of a country and the mirrors serving it
+type CountryCoverage struct {
+	CountryCode     string
+	Requests        int64
+	AverageDistance float32
+	LocalMirrors    int
+	Gap             bool
+}
+
+// CoverageReport cross-references the redirections per country with the
+// location of the mirrors, the countries with traffic but no nearby mirror
+// being reported as coverage gaps
+type CoverageReport struct {
+	Generated time.Time
+	Days      int
+	Countries []CountryCoverage
+}
+
+// Gaps returns the countries without nearby mirror
+func (c *CoverageReport) Gaps() []CountryCoverage {
+	var gaps []CountryCoverage
+	for _, country := range c.Countries {
+		if country.Gap {
+			gaps = append(gaps, country)
+		}
+	}
+	return gaps
+}
+
+// ComputeCoverage builds the coverage report of the last given days. The
+// countries with at least minRequests redirections, no local mirror and an
+// average distance to the selected mirrors above maxDistance km are gaps.
+func ComputeCoverage(r *database.Redis, days int, minRequests int64, maxDistance float32) (*CoverageReport, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	requests := make(map[string]int64)
+	distances := make(map[string]int64)
+	now := time.Now().UTC()
+	for i := 0; i < days; i++ {
+		date := now.AddDate(0, 0, -i).Format("2006_01_02")
+		conn.Send("HGETALL", "STATS_COUNTRY_"+date)
+		conn.Send("HGETALL", "STATS_COUNTRY_DISTANCE_"+date)
+	}
+	conn.Flush()
+	for i := 0; i < days; i++ {
+		for _, m := range []map[string]int64{requests, distances} {
+			values, err := redis.Int64Map(conn.Receive())
+			if err != nil {
+				return nil, err
+			}
+			for country, v := range values {
+				m[country] += v
+			}
+		}
+	}
+
+	local, err := mirrorsPerCountry(r, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CoverageReport{
+		Generated: now,
+		Days:      days,
+		Countries: countryCoverage(requests, distances, local, minRequests, maxDistance),
+	}, nil
+}
+
+// countryCoverage returns the coverage of the countries with traffic, by
+// decreasing number of requests
+func countryCoverage(requests, distances map[string]int64, local map[string]int, minRequests int64, maxDistance float32) []CountryCoverage {
+	var countries []CountryCoverage
+	for country, count := range requests {
+		if count <= 0 {
+			continue
+		}
+		c := CountryCoverage{
+			CountryCode:     country,
+			Requests:        count,
+			AverageDistance: float32(distances[country]) / float32(count),
+			LocalMirrors:    local[country],
+		}
+		c.Gap = c.Requests >= minRequests && c.LocalMirrors == 0 && c.AverageDistance > maxDistance
+		countries = append(countries, c)
+	}
+	sort.Slice(countries, func(i, j int) bool {
+		if countries[i].Requests == countries[j].Requests {
+			return countries[i].CountryCode < countries[j].CountryCode
+		}
+		return countries[i].Requests > countries[j].Requests
+	})
+	return countries
+}
+
+// mirrorsPerCountry returns the number of enabled mirrors per country
+func mirrorsPerCountry(r *database.Redis, conn redis.Conn) (map[string]int, error) {
+	ids, err := r.GetListOfMirrors()
+	if err != nil {
+		return nil, err
+	}
+	count := make(map[string]int)
+	for id := range ids {
+		values, err := redis.Values(conn.Do("HMGET", fmt.Sprintf("MIRROR_%d", id), "enabled", "countryCodes"))
+		if err != nil {
+			return nil, err
+		}
+		var enabled bool
+		var countries CountryList
+		if _, err := redis.Scan(values, &enabled, &countries); err != nil {
+			return nil, err
+		}
+		if !enabled {
+			continue
+		}
+		for _, country := range countries {
+			count[country]++
+		}
+	}
+	return count, nil
+}
+
+// SaveCoverage stores the coverage report
+func SaveCoverage(r *database.Redis, report *CoverageReport) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	b, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Do("SET", "COVERAGE_REPORT", b)
+	return err
+}
+
+// GetCoverage returns the last coverage report or nil if none was computed
+func GetCoverage(r *database.Redis) (*CoverageReport, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	b, err := redis.Bytes(conn.Do("GET", "COVERAGE_REPORT"))
+	if err == redis.ErrNil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	report := &CoverageReport{}
+	if err := json.Unmarshal(b, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
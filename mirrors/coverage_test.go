@@ -0,0 +1,36 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"testing"
+)
+
+func TestCountryCoverage(t *testing.T) {
+	requests := map[string]int64{"FR": 1000, "NZ": 500, "TV": 10, "DE": 0}
+	distances := map[string]int64{"FR": 200000, "NZ": 1000000, "TV": 50000}
+	local := map[string]int{"FR": 3}
+
+	countries := countryCoverage(requests, distances, local, 100, 1000)
+	if len(countries) != 3 {
+		t.Fatalf("Expected 3 countries, got %v", countries)
+	}
+	if countries[0].CountryCode != "FR" || countries[1].CountryCode != "NZ" || countries[2].CountryCode != "TV" {
+		t.Fatalf("Countries should be sorted by requests, got %v", countries)
+	}
+	if countries[0].Gap || countries[0].LocalMirrors != 3 || countries[0].AverageDistance != 200 {
+		t.Fatalf("FR has local mirrors, got %+v", countries[0])
+	}
+	if !countries[1].Gap || countries[1].AverageDistance != 2000 {
+		t.Fatalf("NZ should be a gap, got %+v", countries[1])
+	}
+	if countries[2].Gap {
+		t.Fatalf("TV doesn't have enough requests to be a gap, got %+v", countries[2])
+	}
+
+	report := CoverageReport{Countries: countries}
+	if gaps := report.Gaps(); len(gaps) != 1 || gaps[0].CountryCode != "NZ" {
+		t.Fatalf("Unexpected gaps %v", gaps)
+	}
+}
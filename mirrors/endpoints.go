@@ -0,0 +1,218 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/etix/mirrorbits/database"
+	"github.com/etix/mirrorbits/network"
+	"github.com/etix/mirrorbits/utils"
+)
+
+// Endpoint is an additional location serving the files of a multi-homed
+// mirror (e.g. a second datacenter), monitored separately from the main
+// HTTP URL of the mirror
+type Endpoint struct {
+	HttpURL   string  `yaml:"HttpURL"`
+	Latitude  float32 `yaml:"Latitude"`
+	Longitude float32 `yaml:"Longitude"`
+}
+
+// Endpoints is the list of the additional endpoints of a mirror
+type Endpoints []Endpoint
+
+// ParseEndpoints returns the endpoints of a list of URLs separated by spaces
+// or commas, their location being left to the GeoIP database
+func ParseEndpoints(input string) Endpoints {
+	var list Endpoints
+	for _, u := range strings.Fields(strings.Replace(input, ",", " ", -1)) {
+		if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			u = "http://" + u
+		}
+		list = append(list, Endpoint{HttpURL: u})
+	}
+	return list
+}
+
+// Validate returns an error if one of the endpoints is invalid
+func (e Endpoints) Validate() error {
+	for _, endpoint := range e {
+		if !strings.HasPrefix(endpoint.HttpURL, "http://") && !strings.HasPrefix(endpoint.HttpURL, "https://") {
+			return fmt.Errorf("invalid endpoint %s: the URL must start with http:// or https://", endpoint.HttpURL)
+		}
+		if endpoint.Latitude < -90 || endpoint.Latitude > 90 || endpoint.Longitude < -180 || endpoint.Longitude > 180 {
+			return fmt.Errorf("invalid endpoint %s: invalid coordinates", endpoint.HttpURL)
+		}
+	}
+	return nil
+}
+
+// Normalize normalizes the URL of the endpoints
+func (e Endpoints) Normalize() {
+	for i := range e {
+		e[i].HttpURL = utils.NormalizeURL(e[i].HttpURL)
+	}
+}
+
+// Locate sets the coordinates of the endpoints without location using the
+// GeoIP database
+func (e Endpoints) Locate(geo *network.GeoIP) []string {
+	var warnings []string
+	for i := range e {
+		if e[i].Latitude != 0 || e[i].Longitude != 0 {
+			continue
+		}
+		host := e[i].HttpURL
+		if u, err := url.Parse(host); err == nil {
+			host = u.Host
+		}
+		ip, err := network.LookupMirrorIP(host)
+		if err != nil && err != network.ErrMultipleAddresses {
+			warnings = append(warnings, fmt.Sprintf("Warning: unable to resolve the endpoint %s: %s", e[i].HttpURL, err))
+			continue
+		}
+		geoRec := geo.GetRecord(ip)
+		if !geoRec.IsValid() {
+			warnings = append(warnings, fmt.Sprintf("Warning: unable to guess the location of the endpoint %s", e[i].HttpURL))
+			continue
+		}
+		e[i].Latitude = geoRec.Latitude
+		e[i].Longitude = geoRec.Longitude
+	}
+	return warnings
+}
+
+// RedisArg implements the redis.Argument interface, the endpoints being
+// stored as a json array
+func (e Endpoints) RedisArg() interface{} {
+	if len(e) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal([]Endpoint(e))
+	return string(b)
+}
+
+// RedisScan implements the redis.Scanner interface
+func (e *Endpoints) RedisScan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	case nil:
+		*e = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot convert from %T to Endpoints", src)
+	}
+	if len(b) == 0 {
+		*e = nil
+		return nil
+	}
+	var list []Endpoint
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	*e = Endpoints(list)
+	return nil
+}
+
+// URLList is a list of URLs stored separated by spaces
+type URLList []string
+
+// Contains returns true if the URL is part of the list
+func (u URLList) Contains(rawurl string) bool {
+	return utils.IsInSlice(rawurl, u)
+}
+
+// RedisArg implements the redis.Argument interface
+func (u URLList) RedisArg() interface{} {
+	return strings.Join(u, " ")
+}
+
+// RedisScan implements the redis.Scanner interface
+func (u *URLList) RedisScan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		*u = URLList(strings.Fields(string(v)))
+	case string:
+		*u = URLList(strings.Fields(v))
+	case nil:
+		*u = nil
+	default:
+		return fmt.Errorf("cannot convert from %T to URLList", src)
+	}
+	if len(*u) == 0 {
+		*u = nil
+	}
+	return nil
+}
+
+// SelectEndpoint switches the HTTP URL and the location of a multi-homed
+// mirror to its live endpoint closest to the given coordinates, or to its
+// first live endpoint if the location is unknown. The main HTTP URL is kept
+// if every endpoint is down.
+func (m *Mirror) SelectEndpoint(latitude, longitude float32, located bool) {
+	if len(m.Endpoints) == 0 {
+		return
+	}
+	candidates := append(Endpoints{{HttpURL: m.HttpURL, Latitude: m.Latitude, Longitude: m.Longitude}}, m.Endpoints...)
+
+	selected := -1
+	var selectedDistance float32
+	for i, e := range candidates {
+		if m.EndpointsDown.Contains(e.HttpURL) {
+			continue
+		}
+		if !located {
+			selected = i
+			break
+		}
+		d := utils.GetDistanceKm(latitude, longitude, e.Latitude, e.Longitude)
+		if selected < 0 || d < selectedDistance {
+			selected, selectedDistance = i, d
+		}
+	}
+	if selected <= 0 {
+		return
+	}
+	m.HttpURL = candidates[selected].HttpURL
+	m.Latitude = candidates[selected].Latitude
+	m.Longitude = candidates[selected].Longitude
+}
+
+// Locate selects the endpoint of the mirror serving the given client and
+// computes its distance to the client
+func (m *Mirror) Locate(clientInfo network.GeoIPRecord) {
+	// Serve the client from the closest endpoint of multi-homed mirrors
+	m.SelectEndpoint(clientInfo.Latitude, clientInfo.Longitude, clientInfo.IsValid() && !m.CDN)
+
+	if clientInfo.IsValid() && !m.CDN {
+		m.Distance = utils.GetDistanceKm(clientInfo.Latitude,
+			clientInfo.Longitude,
+			m.Latitude,
+			m.Longitude)
+	} else {
+		m.Distance = 0
+	}
+}
+
+// SetEndpointsDown records the endpoints of the mirror (including its main
+// HTTP URL) found down by the last health check
+func SetEndpointsDown(r *database.Redis, id int, down URLList) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("HSET", fmt.Sprintf("MIRROR_%d", id), "endpointsDown", down)
+	if err == nil {
+		database.Publish(conn, database.MIRROR_UPDATE, strconv.Itoa(id))
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEndpoints(t *testing.T) {
+	e := ParseEndpoints("a.example.org, https://b.example.org/pub")
+	expected := Endpoints{
+		{HttpURL: "http://a.example.org"},
+		{HttpURL: "https://b.example.org/pub"},
+	}
+	if !reflect.DeepEqual(e, expected) {
+		t.Fatalf("Expected %v, got %v", expected, e)
+	}
+	if ParseEndpoints("") != nil {
+		t.Fatalf("Expected nil endpoints")
+	}
+}
+
+func TestEndpoints_Redis(t *testing.T) {
+	e := Endpoints{{HttpURL: "http://a.example.org/", Latitude: 48.8, Longitude: 2.3}}
+
+	var scanned Endpoints
+	if err := scanned.RedisScan([]byte(e.RedisArg().(string))); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(scanned, e) {
+		t.Fatalf("Expected %v, got %v", e, scanned)
+	}
+
+	if err := scanned.RedisScan([]byte("")); err != nil || scanned != nil {
+		t.Fatalf("Expected nil endpoints, got %v (%v)", scanned, err)
+	}
+}
+
+func TestURLList_Redis(t *testing.T) {
+	var u URLList
+	if err := u.RedisScan([]byte("http://a/ http://b/")); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !u.Contains("http://b/") || u.Contains("http://c/") {
+		t.Fatalf("Unexpected list %v", u)
+	}
+	if u.RedisArg() != "http://a/ http://b/" {
+		t.Fatalf("Unexpected argument %v", u.RedisArg())
+	}
+}
+
+func TestMirror_SelectEndpoint(t *testing.T) {
+	newMirror := func() *Mirror {
+		return &Mirror{
+			HttpURL:   "http://paris/",
+			Latitude:  48.85,
+			Longitude: 2.35,
+			Endpoints: Endpoints{
+				{HttpURL: "http://newyork/", Latitude: 40.71, Longitude: -74.00},
+			},
+		}
+	}
+
+	// Client in Boston
+	m := newMirror()
+	m.SelectEndpoint(42.36, -71.05, true)
+	if m.HttpURL != "http://newyork/" || m.Latitude != 40.71 {
+		t.Fatalf("Expected the New York endpoint, got %s", m.HttpURL)
+	}
+
+	// Client in Berlin
+	m = newMirror()
+	m.SelectEndpoint(52.52, 13.40, true)
+	if m.HttpURL != "http://paris/" {
+		t.Fatalf("Expected the main endpoint, got %s", m.HttpURL)
+	}
+
+	// Main endpoint down
+	m = newMirror()
+	m.EndpointsDown = URLList{"http://paris/"}
+	m.SelectEndpoint(52.52, 13.40, true)
+	if m.HttpURL != "http://newyork/" {
+		t.Fatalf("Expected the New York endpoint, got %s", m.HttpURL)
+	}
+
+	// Unknown location
+	m = newMirror()
+	m.SelectEndpoint(0, 0, false)
+	if m.HttpURL != "http://paris/" {
+		t.Fatalf("Expected the main endpoint, got %s", m.HttpURL)
+	}
+
+	// Everything down
+	m = newMirror()
+	m.EndpointsDown = URLList{"http://paris/", "http://newyork/"}
+	m.SelectEndpoint(42.36, -71.05, true)
+	if m.HttpURL != "http://paris/" {
+		t.Fatalf("Expected the main endpoint, got %s", m.HttpURL)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Directory lists the direct sub-directories and files of a directory of the
+// repository
+type Directory struct {
+	Dirs  []string
+	Files []string
+}
+
+// fileTree indexes the files of the repository by directory. It is built
+// from the FILES set on first use and dropped on any update of the files.
+type fileTree struct {
+	sync.Mutex
+	files []string
+	dirs  map[string]*Directory
+}
+
+// loadTree builds the index if needed, the lock must be held
+func (c *Cache) loadTree() error {
+	if c.tree.dirs != nil {
+		return nil
+	}
+	rconn := c.r.Get()
+	files, err := redis.Strings(rconn.Do("SMEMBERS", "FILES"))
+	rconn.Close()
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	c.tree.files = files
+	c.tree.dirs = buildTree(files)
+	return nil
+}
+
+// GetDirectory returns the content of the given directory (ending with a
+// slash) of the repository and false if it doesn't exist. The returned
+// lists are shared and must not be modified.
+func (c *Cache) GetDirectory(dir string) (Directory, bool, error) {
+	c.tree.Lock()
+	defer c.tree.Unlock()
+
+	if err := c.loadTree(); err != nil {
+		return Directory{}, false, err
+	}
+
+	d, ok := c.tree.dirs[dir]
+	if !ok {
+		return Directory{}, false, nil
+	}
+	return *d, true, nil
+}
+
+// GetFiles returns the sorted list of the files of the repository starting
+// with the given prefix
+func (c *Cache) GetFiles(prefix string) ([]string, error) {
+	c.tree.Lock()
+	defer c.tree.Unlock()
+
+	if err := c.loadTree(); err != nil {
+		return nil, err
+	}
+
+	files := c.tree.files
+	start := sort.SearchStrings(files, prefix)
+	end := start
+	for end < len(files) && strings.HasPrefix(files[end], prefix) {
+		end++
+	}
+	return append([]string(nil), files[start:end]...), nil
+}
+
+// buildTree returns the content of each directory of the given files
+func buildTree(files []string) map[string]*Directory {
+	dirs := map[string]*Directory{"/": {}}
+	for _, f := range files {
+		parts := strings.Split(strings.TrimPrefix(f, "/"), "/")
+		dir := "/"
+		for i, name := range parts {
+			d := dirs[dir]
+			if i == len(parts)-1 {
+				d.Files = append(d.Files, name)
+				break
+			}
+			dir += name + "/"
+			if _, ok := dirs[dir]; !ok {
+				dirs[dir] = &Directory{}
+				d.Dirs = append(d.Dirs, name)
+			}
+		}
+	}
+	for _, d := range dirs {
+		sort.Strings(d.Dirs)
+		sort.Strings(d.Files)
+	}
+	return dirs
+}
+
+func (t *fileTree) invalidate() {
+	t.Lock()
+	t.files = nil
+	t.dirs = nil
+	t.Unlock()
+}
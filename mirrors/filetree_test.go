@@ -0,0 +1,103 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/etix/mirrorbits/testing"
+)
+
+func TestBuildTree(t *testing.T) {
+	dirs := buildTree([]string{
+		"/README",
+		"/release/1.0/file.tgz",
+		"/release/1.0/file.tgz.sha256",
+		"/release-candidates/file.tgz",
+		"/release/2.0/file.tgz",
+		"/release/NEWS",
+	})
+
+	tests := map[string]Directory{
+		"/":                    {Dirs: []string{"release", "release-candidates"}, Files: []string{"README"}},
+		"/release/":            {Dirs: []string{"1.0", "2.0"}, Files: []string{"NEWS"}},
+		"/release/1.0/":        {Files: []string{"file.tgz", "file.tgz.sha256"}},
+		"/release-candidates/": {Files: []string{"file.tgz"}},
+	}
+	if len(dirs) != len(tests)+1 {
+		t.Fatalf("Expected %d directories, got %d", len(tests)+1, len(dirs))
+	}
+	for dir, expected := range tests {
+		if d, ok := dirs[dir]; !ok || !reflect.DeepEqual(*d, expected) {
+			t.Fatalf("Unexpected content of %s: %v", dir, d)
+		}
+	}
+}
+
+func TestCache_GetDirectory(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+	conn.ConnectPubsub()
+
+	c := NewCache(conn)
+
+	cmdFiles := mock.Command("SMEMBERS", "FILES").Expect([]interface{}{
+		[]byte("/release/file.tgz"),
+		[]byte("/README"),
+	})
+
+	for i := 0; i < 2; i++ {
+		d, ok, err := c.GetDirectory("/release/")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !ok || !reflect.DeepEqual(d.Files, []string{"file.tgz"}) {
+			t.Fatalf("Unexpected content: %v", d)
+		}
+	}
+	if _, ok, _ := c.GetDirectory("/missing/"); ok {
+		t.Fatalf("The directory is not expected to exist")
+	}
+	if mock.Stats(cmdFiles) != 1 {
+		t.Fatalf("The index is expected to be cached")
+	}
+
+	c.tree.invalidate()
+	if _, _, err := c.GetDirectory("/"); err != nil || mock.Stats(cmdFiles) != 2 {
+		t.Fatalf("The index is expected to be rebuilt once invalidated")
+	}
+}
+
+func TestCache_GetFiles(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+	conn.ConnectPubsub()
+
+	c := NewCache(conn)
+
+	mock.Command("SMEMBERS", "FILES").Expect([]interface{}{
+		[]byte("/release/2.0/file.tar.gz"),
+		[]byte("/nightly/file.tar.gz"),
+		[]byte("/release/1.0/file.tar.gz"),
+		[]byte("/release.txt"),
+	})
+
+	files, err := c.GetFiles("/release/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []string{
+		"/release/1.0/file.tar.gz",
+		"/release/2.0/file.tar.gz",
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("Expected %v, got %v", expected, files)
+	}
+
+	if files, _ = c.GetFiles("/c"); len(files) != 0 {
+		t.Fatalf("Expected no file, got %v", files)
+	}
+	if files, _ = c.GetFiles("/"); len(files) != 4 {
+		t.Fatalf("Expected all the files, got %v", files)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/etix/mirrorbits/database"
+	"github.com/gomodule/redigo/redis"
+)
+
+// Keys left behind by a removed mirror, suffixed by its id
+var mirrorKeyPrefixes = []string{
+	"MIRROR_",
+	"MIRRORFILES_",
+	"MIRRORFILESTMP_",
+	"MIRRORFILESQUARANTINE_",
+	"HANDLEDFILES_",
+	"MIRRORLOGS_",
+	"SCANSUMMARIES_",
+	"CONTACT_",
+}
+
+// GCReport is the number of orphaned keys and entries found (and removed
+// unless in dry-run mode) by CollectGarbage
+type GCReport struct {
+	// Keys of the removed mirrors
+	MirrorKeys int64
+	// FILEINFOS keys of the removed mirrors
+	FileInfos int64
+	// FILEMIRRORS keys of the files removed from the repository
+	FileMirrors int64
+	// FILEMIRRORS entries of the removed mirrors
+	FileMirrorEntries int64
+	// Temporary sets left by an interrupted scan
+	TmpKeys int64
+	// HANDLEDFILES entries of the files removed from the repository
+	HandledFiles int64
+}
+
+// scanKeys calls fn for each key matching the given pattern
+func scanKeys(conn redis.Conn, pattern string, fn func(key string) error) error {
+	cursor := "0"
+	for {
+		values, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", pattern, "COUNT", 1000))
+		if err != nil {
+			return err
+		}
+		if len(values) != 2 {
+			return fmt.Errorf("unexpected reply to SCAN")
+		}
+		cursor, _ = redis.String(values[0], nil)
+		keys, err := redis.Strings(values[1], nil)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := fn(key); err != nil {
+				return err
+			}
+		}
+		if cursor == "0" {
+			return nil
+		}
+	}
+}
+
+// CollectGarbage looks for the keys and entries no longer referenced in the
+// database: the keys of the removed mirrors, the mirrors of the files removed
+// from the repository, the temporary sets of the interrupted scans, and
+// removes them unless dryRun is set.
+func CollectGarbage(r *database.Redis, dryRun bool) (*GCReport, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	names, err := redis.StringMap(conn.Do("HGETALL", "MIRRORS"))
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int]bool, len(names))
+	for id := range names {
+		if i, err := strconv.Atoi(id); err == nil {
+			ids[i] = true
+		}
+	}
+
+	report := &GCReport{}
+
+	remove := func(cmd string, args ...interface{}) error {
+		if dryRun {
+			return nil
+		}
+		_, err := conn.Do(cmd, args...)
+		return err
+	}
+
+	isScanning := func(id int) bool {
+		scanning, err := redis.Bool(conn.Do("EXISTS", fmt.Sprintf("SCANNING_%d", id)))
+		return err != nil || scanning
+	}
+
+	// Keys of the removed mirrors and temporary sets of the interrupted scans
+	for _, prefix := range mirrorKeyPrefixes {
+		err = scanKeys(conn, prefix+"*", func(key string) error {
+			id, err := strconv.Atoi(strings.TrimPrefix(key, prefix))
+			if err != nil {
+				return nil
+			}
+			if ids[id] {
+				if prefix != "MIRRORFILESTMP_" || isScanning(id) {
+					return nil
+				}
+				report.TmpKeys++
+			} else {
+				report.MirrorKeys++
+			}
+			return remove("DEL", key)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The source repository is indexed in a transaction, its temporary sets
+	// are only left behind by a crash of Redis in the middle of it
+	sourceScanning, err := redis.Bool(conn.Do("EXISTS", "SOURCE_REPO_SYNC"))
+	if err != nil {
+		return nil, err
+	}
+	if !sourceScanning {
+		for _, key := range []string{"FILES_TMP", "FILES_LOOKUP_TMP"} {
+			exists, err := redis.Bool(conn.Do("EXISTS", key))
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				report.TmpKeys++
+				if err := remove("DEL", key); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// File information of the removed mirrors
+	err = scanKeys(conn, "FILEINFOS_*", func(key string) error {
+		id, err := strconv.Atoi(strings.TrimPrefix(key, "FILEINFOS_"))
+		if err != nil {
+			return nil
+		}
+		if ids[id] {
+			return nil
+		}
+		report.FileInfos++
+		return remove("DEL", key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Mirrors of the files removed from the repository and removed mirrors
+	// still listed as serving a file
+	err = scanKeys(conn, "FILEMIRRORS_*", func(key string) error {
+		path := strings.TrimPrefix(key, "FILEMIRRORS_")
+		known, err := redis.Bool(conn.Do("SISMEMBER", "FILES", path))
+		if err != nil {
+			return err
+		}
+		if !known {
+			report.FileMirrors++
+			return remove("DEL", key)
+		}
+		members, err := redis.Ints(conn.Do("SMEMBERS", key))
+		if err != nil {
+			return err
+		}
+		for _, id := range members {
+			if ids[id] {
+				continue
+			}
+			report.FileMirrorEntries++
+			if err := remove("SREM", key, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The files handled by a mirror are only computed during its scans
+	for id := range ids {
+		key := fmt.Sprintf("HANDLEDFILES_%d", id)
+		stale, err := redis.Strings(conn.Do("SDIFF", key, "FILES"))
+		if err != nil {
+			return nil, err
+		}
+		if len(stale) == 0 || isScanning(id) {
+			continue
+		}
+		report.HandledFiles += int64(len(stale))
+		if err := remove("SINTERSTORE", key, "FILES", fmt.Sprintf("MIRRORFILES_%d", id)); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"testing"
+
+	. "github.com/etix/mirrorbits/testing"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	keys := map[string][]string{
+		"MIRROR_*":         {"MIRROR_1", "MIRROR_2", "MIRROR_3"},
+		"MIRRORFILES_*":    {"MIRRORFILES_1", "MIRRORFILES_3"},
+		"MIRRORFILESTMP_*": {"MIRRORFILESTMP_1", "MIRRORFILESTMP_2"},
+		"FILEINFOS_*":      {"FILEINFOS_1", "FILEINFOS_2", "FILEINFOS_3"},
+		"FILEMIRRORS_*":    {"FILEMIRRORS_/a.tgz", "FILEMIRRORS_/old.tgz"},
+	}
+	for _, prefix := range append(mirrorKeyPrefixes, "FILEINFOS_", "FILEMIRRORS_") {
+		values := []interface{}{}
+		for _, key := range keys[prefix+"*"] {
+			values = append(values, []byte(key))
+		}
+		mock.Command("SCAN", "0", "MATCH", prefix+"*", "COUNT", 1000).Expect([]interface{}{[]byte("0"), values})
+	}
+
+	mock.Command("HGETALL", "MIRRORS").ExpectMap(map[string]string{"1": "m1", "2": "m2"})
+	mock.Command("EXISTS", "SCANNING_1").Expect(int64(1))
+	mock.Command("EXISTS", "SCANNING_2").Expect(int64(0))
+	mock.Command("EXISTS", "SOURCE_REPO_SYNC").Expect(int64(0))
+	mock.Command("EXISTS", "FILES_TMP").Expect(int64(0))
+	mock.Command("EXISTS", "FILES_LOOKUP_TMP").Expect(int64(1))
+	mock.Command("SISMEMBER", "FILES", "/a.tgz").Expect(int64(1))
+	mock.Command("SISMEMBER", "FILES", "/old.tgz").Expect(int64(0))
+	mock.Command("SMEMBERS", "FILEMIRRORS_/a.tgz").Expect([]interface{}{[]byte("1"), []byte("3")})
+	mock.Command("SDIFF", "HANDLEDFILES_1", "FILES").Expect([]interface{}{})
+	mock.Command("SDIFF", "HANDLEDFILES_2", "FILES").Expect([]interface{}{[]byte("/old.tgz"), []byte("/older.tgz")})
+
+	cmdDel := mock.GenericCommand("DEL").Expect(int64(1))
+	cmdSrem := mock.Command("SREM", "FILEMIRRORS_/a.tgz", 3).Expect(int64(1))
+	cmdHandled := mock.Command("SINTERSTORE", "HANDLEDFILES_2", "FILES", "MIRRORFILES_2").Expect(int64(1))
+
+	check := func(report *GCReport) {
+		if report.MirrorKeys != 2 || report.FileInfos != 1 || report.FileMirrors != 1 ||
+			report.FileMirrorEntries != 1 || report.TmpKeys != 2 || report.HandledFiles != 2 {
+			t.Fatalf("Unexpected report %+v", report)
+		}
+	}
+
+	report, err := CollectGarbage(conn, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	check(report)
+	if mock.Stats(cmdDel) != 0 || mock.Stats(cmdSrem) != 0 || mock.Stats(cmdHandled) != 0 {
+		t.Fatalf("Nothing is supposed to be removed in dry-run mode")
+	}
+
+	report, err = CollectGarbage(conn, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	check(report)
+	if mock.Stats(cmdDel) != 6 || mock.Stats(cmdSrem) != 1 || mock.Stats(cmdHandled) != 1 {
+		t.Fatalf("Expected the orphaned keys to be removed")
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etix/mirrorbits/database"
+	"github.com/gomodule/redigo/redis"
+)
+
+// Sources of the HTTP errors of the mirrors
+const (
+	ErrorSourceMonitor = "monitor"
+	ErrorSourceClient  = "client"
+)
+
+const (
+	httpErrorsBucket = time.Hour
+)
+
+// HTTPErrors is the number of HTTP errors returned by a mirror per source
+// and status code
+type HTTPErrors map[string]map[int]int64
+
+// Total returns the number of errors of all the sources
+func (e HTTPErrors) Total() (total int64) {
+	for _, codes := range e {
+		for _, count := range codes {
+			total += count
+		}
+	}
+	return
+}
+
+// Codes returns the number of errors per status code of all the sources
+func (e HTTPErrors) Codes() map[int]int64 {
+	codes := make(map[int]int64)
+	for _, c := range e {
+		for code, count := range c {
+			codes[code] += count
+		}
+	}
+	return codes
+}
+
+// String returns the number of errors per status code, e.g. "404: 3, 503: 1"
+func (e HTTPErrors) String() string {
+	codes := e.Codes()
+	var list []int
+	for code := range codes {
+		list = append(list, code)
+	}
+	sort.Ints(list)
+	var s []string
+	for _, code := range list {
+		s = append(s, fmt.Sprintf("%d: %d", code, codes[code]))
+	}
+	return strings.Join(s, ", ")
+}
+
+func httpErrorsKey(id int, t time.Time) string {
+	return fmt.Sprintf("HTTPERRORS_%d_%s", id, t.UTC().Format("2006010215"))
+}
+
+// RecordHTTPError counts an error status code returned by the mirror, the
+// counts being kept for the given window
+func RecordHTTPError(r *database.Redis, id int, source string, code int, window time.Duration) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	key := httpErrorsKey(id, time.Now())
+	conn.Send("MULTI")
+	conn.Send("HINCRBY", key, fmt.Sprintf("%s:%d", source, code), 1)
+	conn.Send("EXPIRE", key, int((window + httpErrorsBucket).Seconds()))
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+// GetHTTPErrors returns the errors returned by the mirror during the given
+// window (rounded to the hour)
+func GetHTTPErrors(r *database.Redis, id int, window time.Duration) (HTTPErrors, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	now := time.Now()
+	buckets := int(window / httpErrorsBucket)
+	conn.Send("MULTI")
+	for i := 0; i <= buckets; i++ {
+		conn.Send("HGETALL", httpErrorsKey(id, now.Add(-time.Duration(i)*httpErrorsBucket)))
+	}
+	res, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		return nil, err
+	}
+
+	errors := make(HTTPErrors)
+	for _, v := range res {
+		fields, err := redis.Int64Map(v, nil)
+		if err != nil {
+			return nil, err
+		}
+		for field, count := range fields {
+			i := strings.LastIndex(field, ":")
+			if i < 0 {
+				continue
+			}
+			code, err := strconv.Atoi(field[i+1:])
+			if err != nil {
+				continue
+			}
+			source := field[:i]
+			if errors[source] == nil {
+				errors[source] = make(map[int]int64)
+			}
+			errors[source][code] += count
+		}
+	}
+	return errors, nil
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"testing"
+)
+
+func TestHTTPErrors(t *testing.T) {
+	e := HTTPErrors{
+		ErrorSourceMonitor: {404: 2, 503: 1},
+		ErrorSourceClient:  {404: 3},
+	}
+
+	if e.Total() != 6 {
+		t.Fatalf("Total should be 6, got %d", e.Total())
+	}
+	if s := e.String(); s != "404: 5, 503: 1" {
+		t.Fatalf("Unexpected string %q", s)
+	}
+	if s := (HTTPErrors{}).String(); s != "" {
+		t.Fatalf("Unexpected string %q", s)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/database"
+	"github.com/etix/mirrorbits/network"
+	"github.com/etix/mirrorbits/utils"
+)
+
+// UpdateMirrorAddress records the new address of a mirror along with the
+// location derived from it. A warning is attached to the mirror when the
+// location moved farther than the configured threshold. The location of the
+// mirrors set by hand (see ManualLocation) is kept. The distance between both
+// locations is returned.
+func UpdateMirrorAddress(r *database.Redis, mirror *Mirror, ip string, geoRec network.GeoIPRecord) (float32, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	key := fmt.Sprintf("MIRROR_%d", mirror.ID)
+	args := []interface{}{key, "ip", ip}
+
+	var distance float32
+	if geoRec.IsValid() && !mirror.ManualLocation {
+		if mirror.Latitude != 0 || mirror.Longitude != 0 {
+			distance = utils.GetDistanceKm(mirror.Latitude, mirror.Longitude, geoRec.Latitude, geoRec.Longitude)
+		}
+
+		// Only the primary country is derived from the address
+		countries := append(CountryList{}, mirror.CountryCodes...)
+		if len(countries) == 0 {
+			countries = CountryList{geoRec.CountryCode}
+		} else {
+			countries[0] = geoRec.CountryCode
+		}
+		countries, _ = countries.Normalize()
+
+		// The region is only kept for the countries routed by region
+		var region string
+		if GetConfig().Region(geoRec.RegionCode) != "" {
+			region = geoRec.RegionCode
+		}
+
+		args = append(args,
+			"latitude", geoRec.Latitude,
+			"longitude", geoRec.Longitude,
+			"continentCode", geoRec.ContinentCode,
+			"countryCodes", countries,
+			"regionCode", region,
+			"asnum", geoRec.ASNum)
+
+		threshold := GetConfig().RelocationThreshold
+		if threshold > 0 && distance > float32(threshold) {
+			warning := fmt.Sprintf("Moved %d km (%s -> %s) on %s", int(distance),
+				mirror.CountryCodes.Primary(), geoRec.CountryCode, time.Now().UTC().Format("2006-01-02"))
+			args = append(args, "locationWarning", warning)
+		}
+	} else if geoRec.IsValid() {
+		// Only the AS number follows the address of the mirrors located by hand
+		args = append(args, "asnum", geoRec.ASNum)
+	}
+
+	_, err := conn.Do("HMSET", args...)
+	if err == nil {
+		database.Publish(conn, database.MIRROR_UPDATE, strconv.Itoa(mirror.ID))
+	}
+	return distance, err
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"testing"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/database"
+	"github.com/etix/mirrorbits/network"
+	. "github.com/etix/mirrorbits/testing"
+	"github.com/rafaeljusto/redigomock"
+)
+
+func TestUpdateMirrorAddress(t *testing.T) {
+	config := &Configuration{
+		RelocationThreshold: 500,
+	}
+	config.Regions.Countries = []string{"US"}
+	SetConfiguration(config)
+
+	mock, conn := PrepareRedisTest()
+
+	mock.Command("PUBLISH", string(database.MIRROR_UPDATE), redigomock.NewAnyData()).Expect("ok")
+
+	mirror := &Mirror{
+		ID:           1,
+		Latitude:     48.8566,
+		Longitude:    2.3522,
+		CountryCodes: CountryList{"FR", "BE"},
+	}
+
+	cmdIP := mock.Command("HMSET", "MIRROR_1", "ip", "192.0.2.1").Expect("ok")
+
+	if _, err := UpdateMirrorAddress(conn, mirror, "192.0.2.1", network.GeoIPRecord{}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if mock.Stats(cmdIP) != 1 {
+		t.Fatalf("Expected only the address to be set without a location")
+	}
+
+	/* */
+
+	geoRec := network.GeoIPRecord{
+		CountryCode:   "US",
+		ContinentCode: "NA",
+		RegionCode:    "US-NY",
+		Latitude:      40.7128,
+		Longitude:     -74.0060,
+		ASNum:         64496,
+	}
+
+	cmdMoved := mock.Command("HMSET", "MIRROR_1", "ip", "198.51.100.1",
+		"latitude", geoRec.Latitude,
+		"longitude", geoRec.Longitude,
+		"continentCode", "NA",
+		"countryCodes", CountryList{"US", "BE"},
+		"regionCode", "US-NY",
+		"asnum", uint(64496),
+		"locationWarning", redigomock.NewAnyData()).Expect("ok")
+
+	distance, err := UpdateMirrorAddress(conn, mirror, "198.51.100.1", geoRec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if distance < 5000 {
+		t.Fatalf("Unexpected distance %f", distance)
+	}
+	if mock.Stats(cmdMoved) != 1 {
+		t.Fatalf("Expected the location to be updated with a warning")
+	}
+
+	/* */
+
+	mirror.ManualLocation = true
+	cmdManual := mock.Command("HMSET", "MIRROR_1", "ip", "203.0.113.1", "asnum", uint(64496)).Expect("ok")
+
+	distance, err = UpdateMirrorAddress(conn, mirror, "203.0.113.1", geoRec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if distance != 0 || mock.Stats(cmdManual) != 1 {
+		t.Fatalf("Expected the location set by hand to be kept")
+	}
+}
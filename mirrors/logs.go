@@ -30,6 +30,7 @@ const (
 	LOGTYPE_STATECHANGED
 	LOGTYPE_SCANSTARTED
 	LOGTYPE_SCANCOMPLETED
+	LOGTYPE_MOVED
 )
 
 func typeToInstance(typ LogType) LogAction {
@@ -50,6 +51,8 @@ func typeToInstance(typ LogType) LogAction {
 		return &LogScanStarted{}
 	case LOGTYPE_SCANCOMPLETED:
 		return &LogScanCompleted{}
+	case LOGTYPE_MOVED:
+		return &LogMoved{}
 	default:
 	}
 	return nil
@@ -212,6 +215,8 @@ func (l *LogScanStarted) GetOutput() string {
 		return "RSYNC scan started"
 	case core.FTP:
 		return "FTP scan started"
+	case core.MANIFEST:
+		return "Manifest scan started"
 	default:
 		return "Scan started using a unknown protocol"
 	}
@@ -259,6 +264,33 @@ func NewLogScanCompleted(id int, files, known, removed, tzoffset int64) LogActio
 	}
 }
 
+type LogMoved struct {
+	LogCommonAction
+	URL     string
+	Count   int
+	Updated bool
+}
+
+func (l *LogMoved) GetOutput() string {
+	if l.Updated {
+		return fmt.Sprintf("HTTP URL updated to %s after %d permanent redirects", l.URL, l.Count)
+	}
+	return fmt.Sprintf("HTTP URL permanently redirects to %s", l.URL)
+}
+
+func NewLogMoved(id int, url string, count int, updated bool) LogAction {
+	return &LogMoved{
+		LogCommonAction: LogCommonAction{
+			Type:      LOGTYPE_MOVED,
+			MirrorID:  id,
+			Timestamp: time.Now(),
+		},
+		URL:     url,
+		Count:   count,
+		Updated: updated,
+	}
+}
+
 func PushLog(r *database.Redis, logAction LogAction) error {
 	conn := r.Get()
 	defer conn.Close()
@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	. "github.com/etix/mirrorbits/config"
@@ -34,24 +35,34 @@ type Mirror struct {
 	CustomData                  string           `redis:"customData" yaml:"CustomData"`
 	ContinentOnly               bool             `redis:"continentOnly" yaml:"ContinentOnly"`
 	CountryOnly                 bool             `redis:"countryOnly" yaml:"CountryOnly"`
+	RegionOnly                  bool             `redis:"regionOnly" json:",omitempty" yaml:"RegionOnly"`
 	ASOnly                      bool             `redis:"asOnly" yaml:"ASOnly"`
 	Score                       int              `redis:"score" yaml:"Score"`
 	Latitude                    float32          `redis:"latitude" yaml:"Latitude"`
 	Longitude                   float32          `redis:"longitude" yaml:"Longitude"`
 	ContinentCode               string           `redis:"continentCode" yaml:"ContinentCode"`
-	CountryCodes                string           `redis:"countryCodes" yaml:"CountryCodes"`
-	ExcludedCountryCodes        string           `redis:"excludedCountryCodes" yaml:"ExcludedCountryCodes"`
+	CountryCodes                CountryList      `redis:"countryCodes" yaml:"CountryCodes"`
+	RegionCode                  string           `redis:"regionCode" json:",omitempty" yaml:"RegionCode"`
+	ExcludedCountryCodes        CountryList      `redis:"excludedCountryCodes" yaml:"ExcludedCountryCodes"`
 	Asnum                       uint             `redis:"asnum" yaml:"ASNum"`
+	ManualLocation              bool             `redis:"manualLocation" json:",omitempty" yaml:"ManualLocation"`
 	Comment                     string           `redis:"comment" yaml:"-"`
 	Enabled                     bool             `redis:"enabled" yaml:"Enabled"`
+	Maintenance                 bool             `redis:"maintenance" json:",omitempty" yaml:"-"`
 	Up                          bool             `redis:"up" json:"-" yaml:"-"`
 	ExcludeReason               string           `redis:"excludeReason" json:",omitempty" yaml:"-"`
 	StateSince                  Time             `redis:"stateSince" json:",omitempty" yaml:"-"`
 	AllowRedirects              Redirects        `redis:"allowredirects" json:",omitempty" yaml:"AllowRedirects"`
+	CDN                         bool             `redis:"cdn" json:",omitempty" yaml:"CDN"`
+	Channels                    ChannelList      `redis:"channels" json:",omitempty" yaml:"Channels"`
+	PathRewrites                PathRewrites     `redis:"pathRewrites" json:",omitempty" yaml:"PathRewrites"`
+	Endpoints                   Endpoints        `redis:"endpoints" json:",omitempty" yaml:"Endpoints"`
+	EndpointsDown               URLList          `redis:"endpointsDown" json:",omitempty" yaml:"-"`
+	Schedule                    Schedule         `redis:"schedule" json:",omitempty" yaml:"Schedule"`
+	Probe                       Probe            `redis:"probe" json:"-" yaml:"Probe"`
+	Proxy                       string           `redis:"proxy" json:"-" yaml:"Proxy"`
 	TZOffset                    int64            `redis:"tzoffset" json:"-" yaml:"-"` // timezone offset in ms
 	Distance                    float32          `redis:"-" yaml:"-"`
-	CountryFields               []string         `redis:"-" json:"-" yaml:"-"`
-	ExcludedCountryFields       []string         `redis:"-" json:"-" yaml:"-"`
 	Filepath                    string           `redis:"-" json:"-" yaml:"-"`
 	Weight                      float32          `redis:"-" json:"-" yaml:"-"`
 	ComputedScore               int              `redis:"-" yaml:"-"`
@@ -60,21 +71,63 @@ type Mirror struct {
 	LastSuccessfulSyncProtocol  core.ScannerType `redis:"lastSuccessfulSyncProtocol" yaml:"-"`
 	LastSuccessfulSyncPrecision core.Precision   `redis:"lastSuccessfulSyncPrecision" yaml:"-"`
 	LastModTime                 Time             `redis:"lastModTime" yaml:"-"`
+	IPv4                        bool             `redis:"ipv4" yaml:"-"`
+	IPv6                        bool             `redis:"ipv6" yaml:"-"`
+	IPAddress                   string           `redis:"ip" yaml:"-"`
+	LocationWarning             string           `redis:"locationWarning" json:",omitempty" yaml:"LocationWarning,omitempty"`
+	MovedTo                     string           `redis:"movedTo" json:",omitempty" yaml:"-"`
+	MovedCount                  int              `redis:"movedCount" json:"-" yaml:"-"`
+	ScanRequestedBy             string           `redis:"scanRequestedBy" json:"-" yaml:"-"`
+	QuarantinedSince            Time             `redis:"quarantinedSince" json:"-" yaml:"-"`
+	QuarantinedFiles            int64            `redis:"quarantinedFiles" json:"-" yaml:"-"`
+	QuarantinedPrevious         int64            `redis:"quarantinedPrevious" json:"-" yaml:"-"`
 
 	FileInfo *filesystem.FileInfo `redis:"-" json:"-" yaml:"-"` // Details of the requested file on this specific mirror
 }
 
-// Prepare must be called after retrieval from the database to reformat some values
-func (m *Mirror) Prepare() {
-	m.CountryFields = strings.Fields(m.CountryCodes)
-	m.ExcludedCountryFields = strings.Fields(m.ExcludedCountryCodes)
-}
-
 // IsHTTPS returns true if the mirror has an HTTPS address
 func (m *Mirror) IsHTTPS() bool {
 	return strings.HasPrefix(m.HttpURL, "https://")
 }
 
+// InRegion returns true if the mirror is located in the region of the given
+// subdivision, for the countries routed by region (see Regions)
+func (m *Mirror) InRegion(subdivision string) bool {
+	region := GetConfig().Region(subdivision)
+	return region != "" && GetConfig().Region(m.RegionCode) == region
+}
+
+// InMetroArea returns true if the mirror lies in the metropolitan area of a
+// client located down to its city (see MetroAreaRadius). The distance to the
+// client must have been computed.
+func (m *Mirror) InMetroArea(clientInfo network.GeoIPRecord) bool {
+	radius := GetConfig().MetroAreaRadius
+	return radius > 0 && clientInfo.City != "" && !m.CDN && m.Distance <= float32(radius)
+}
+
+// FileMismatch returns the reason why the file on the mirror differs from
+// the source or an empty string if both files match
+func (m *Mirror) FileMismatch(fileInfo *filesystem.FileInfo) string {
+	if m.FileInfo == nil {
+		return ""
+	}
+	if m.FileInfo.Size != fileInfo.Size {
+		return "File size mismatch"
+	}
+	if !m.FileInfo.ModTime.IsZero() {
+		mModTime := m.FileInfo.ModTime
+		if GetConfig().FixTimezoneOffsets {
+			mModTime = mModTime.Add(time.Duration(m.TZOffset) * time.Millisecond)
+		}
+		mModTime = mModTime.Truncate(m.LastSuccessfulSyncPrecision.Duration())
+		lModTime := fileInfo.ModTime.Truncate(m.LastSuccessfulSyncPrecision.Duration())
+		if !mModTime.Equal(lModTime) {
+			return fmt.Sprintf("Mod time mismatch (diff: %s)", lModTime.Sub(mModTime))
+		}
+	}
+	return ""
+}
+
 // Mirrors represents a slice of Mirror
 type Mirrors []Mirror
 
@@ -103,11 +156,20 @@ func (m ByRank) Less(i, j int) bool {
 
 		//TODO Simplify me
 		if m.ClientInfo.CountryCode != "" {
-			if utils.IsInSlice(m.ClientInfo.CountryCode, m.Mirrors[i].CountryFields) {
-				if !utils.IsInSlice(m.ClientInfo.CountryCode, m.Mirrors[j].CountryFields) {
+			if utils.IsInSlice(m.ClientInfo.CountryCode, m.Mirrors[i].CountryCodes) {
+				if !utils.IsInSlice(m.ClientInfo.CountryCode, m.Mirrors[j].CountryCodes) {
+					return true
+				}
+			} else if utils.IsInSlice(m.ClientInfo.CountryCode, m.Mirrors[j].CountryCodes) {
+				return false
+			}
+		}
+		if m.ClientInfo.RegionCode != "" {
+			if m.Mirrors[i].InRegion(m.ClientInfo.RegionCode) {
+				if !m.Mirrors[j].InRegion(m.ClientInfo.RegionCode) {
 					return true
 				}
-			} else if utils.IsInSlice(m.ClientInfo.CountryCode, m.Mirrors[j].CountryFields) {
+			} else if m.Mirrors[j].InRegion(m.ClientInfo.RegionCode) {
 				return false
 			}
 		}
@@ -192,6 +254,28 @@ func MarkMirrorDown(r *database.Redis, id int, reason string) error {
 	return SetMirrorState(r, id, false, reason)
 }
 
+// pendingState is the state of a mirror changed while the database was not
+// writable, applied to the cached mirrors until it is saved
+type pendingState struct {
+	up     bool
+	reason string
+	since  time.Time
+}
+
+// pendingStates holds the pendingState of the mirrors by ID
+var pendingStates sync.Map
+
+// applyPendingState overrides the state of the mirror by the one changed
+// while the database was not writable, if any
+func applyPendingState(mirror *Mirror) {
+	if v, ok := pendingStates.Load(mirror.ID); ok {
+		p := v.(pendingState)
+		mirror.Up = p.up
+		mirror.ExcludeReason = p.reason
+		mirror.StateSince = Time{}.FromTime(p.since)
+	}
+}
+
 // SetMirrorState sets the state of a mirror to up or down with an optional reason
 func SetMirrorState(r *database.Redis, id int, state bool, reason string) error {
 	conn := r.Get()
@@ -203,6 +287,9 @@ func SetMirrorState(r *database.Redis, id int, state bool, reason string) error
 	if err != nil && err != redis.ErrNil {
 		return err
 	}
+	if v, ok := pendingStates.Load(id); ok {
+		previousState = v.(pendingState).up
+	}
 
 	var args []interface{}
 	args = append(args, key, "up", state, "excludeReason", reason)
@@ -213,6 +300,20 @@ func SetMirrorState(r *database.Redis, id int, state bool, reason string) error
 
 	_, err = conn.Do("HMSET", args...)
 
+	if database.IsWriteError(err) {
+		// Serve the new state from the cache until the database is
+		// writable again
+		r.SetDegraded(err)
+		p := pendingState{up: state, reason: reason, since: time.Now()}
+		if v, ok := pendingStates.Load(id); ok && state == previousState {
+			p.since = v.(pendingState).since
+		}
+		pendingStates.Store(id, p)
+		return r.Defer(key+" state", func(conn redis.Conn) error {
+			return savePendingState(r, conn, id)
+		})
+	}
+
 	if err == nil {
 		// Publish update
 		database.Publish(conn, database.MIRROR_UPDATE, strconv.Itoa(id))
@@ -225,6 +326,127 @@ func SetMirrorState(r *database.Redis, id int, state bool, reason string) error
 	return err
 }
 
+// savePendingState saves the state of the mirror changed while the database
+// was not writable
+func savePendingState(r *database.Redis, conn redis.Conn, id int) error {
+	v, ok := pendingStates.Load(id)
+	if !ok {
+		return nil
+	}
+	p := v.(pendingState)
+	key := fmt.Sprintf("MIRROR_%d", id)
+
+	previousState, err := redis.Bool(conn.Do("HGET", key, "up"))
+	if err != nil && err != redis.ErrNil {
+		return err
+	}
+
+	args := []interface{}{key, "up", p.up, "excludeReason", p.reason}
+	if p.up != previousState {
+		args = append(args, "stateSince", p.since.Unix())
+	}
+	if _, err = conn.Do("HMSET", args...); err != nil {
+		return err
+	}
+	pendingStates.Delete(id)
+
+	database.Publish(conn, database.MIRROR_UPDATE, strconv.Itoa(id))
+	if p.up != previousState {
+		PushLog(r, NewLogStateChanged(id, p.up, p.reason))
+	}
+	return nil
+}
+
+// RecordMirrorMove records that the HTTP URL of the mirror permanently
+// redirects to the given URL and returns the number of consecutive health
+// checks having observed this redirection
+func RecordMirrorMove(r *database.Redis, id int, url string) (int, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	key := fmt.Sprintf("MIRROR_%d", id)
+
+	previous, err := redis.String(conn.Do("HGET", key, "movedTo"))
+	if err != nil && err != redis.ErrNil {
+		return 0, err
+	}
+
+	count := 1
+	if previous == url {
+		count, err = redis.Int(conn.Do("HINCRBY", key, "movedCount", 1))
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		_, err = conn.Do("HMSET", key, "movedTo", url, "movedCount", count)
+		if err != nil {
+			return 0, err
+		}
+		database.Publish(conn, database.MIRROR_UPDATE, strconv.Itoa(id))
+		PushLog(r, NewLogMoved(id, url, count, false))
+	}
+	return count, nil
+}
+
+// ClearMirrorMove forgets the permanent redirection of the HTTP URL of the
+// mirror, if any
+func ClearMirrorMove(r *database.Redis, id int) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	removed, err := redis.Int(conn.Do("HDEL", fmt.Sprintf("MIRROR_%d", id), "movedTo", "movedCount"))
+	if err == nil && removed > 0 {
+		database.Publish(conn, database.MIRROR_UPDATE, strconv.Itoa(id))
+	}
+	return err
+}
+
+// MoveMirror replaces the HTTP URL of the mirror by the location it
+// permanently redirects to
+func MoveMirror(r *database.Redis, id int, url string, count int) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	key := fmt.Sprintf("MIRROR_%d", id)
+	conn.Send("MULTI")
+	conn.Send("HSET", key, "http", url)
+	conn.Send("HDEL", key, "movedTo", "movedCount")
+	_, err := conn.Do("EXEC")
+
+	if err == nil {
+		database.Publish(conn, database.MIRROR_UPDATE, strconv.Itoa(id))
+		PushLog(r, NewLogMoved(id, url, count, true))
+	}
+	return err
+}
+
+// SetMirrorIPFamilies records whether the HTTP endpoint of the mirror can be
+// reached over IPv4 and IPv6
+func SetMirrorIPFamilies(r *database.Redis, id int, ipv4, ipv6 bool) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	key := fmt.Sprintf("MIRROR_%d", id)
+
+	previous, err := redis.Values(conn.Do("HMGET", key, "ipv4", "ipv6"))
+	if err != nil {
+		return err
+	}
+	var prevIPv4, prevIPv6 bool
+	if _, err := redis.Scan(previous, &prevIPv4, &prevIPv6); err != nil {
+		return err
+	}
+	if prevIPv4 == ipv4 && prevIPv6 == ipv6 {
+		return nil
+	}
+
+	_, err = conn.Do("HMSET", key, "ipv4", ipv4, "ipv6", ipv6)
+	if err == nil {
+		database.Publish(conn, database.MIRROR_UPDATE, strconv.Itoa(id))
+	}
+	return err
+}
+
 // Results is the resulting struct of a request and is
 // used by the renderers to generate the final page.
 type Results struct {
@@ -234,7 +456,10 @@ type Results struct {
 	MirrorList   Mirrors
 	ExcludedList Mirrors `json:",omitempty"`
 	Fallback     bool    `json:",omitempty"`
+	TorrentPath  string  `json:",omitempty"`
+	MagnetLink   string  `json:",omitempty"`
 	LocalJSPath  string
+	RequestID    string `json:",omitempty"`
 }
 
 // Redirects is handling the per-mirror authorization of HTTP redirects
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	. "github.com/etix/mirrorbits/config"
 	"github.com/etix/mirrorbits/database"
 	"github.com/etix/mirrorbits/network"
 	. "github.com/etix/mirrorbits/testing"
@@ -211,24 +212,24 @@ func TestByRank_Less(t *testing.T) {
 
 	m = Mirrors{
 		Mirror{
-			ID:            1,
-			Name:          "M1",
-			CountryFields: []string{"IT", "UK"},
+			ID:           1,
+			Name:         "M1",
+			CountryCodes: CountryList{"IT", "UK"},
 		},
 		Mirror{
-			ID:            2,
-			Name:          "M2",
-			CountryFields: []string{"IT", "UK"},
+			ID:           2,
+			Name:         "M2",
+			CountryCodes: CountryList{"IT", "UK"},
 		},
 		Mirror{
-			ID:            3,
-			Name:          "M3",
-			CountryFields: []string{"IT", "FR"},
+			ID:           3,
+			Name:         "M3",
+			CountryCodes: CountryList{"IT", "FR"},
 		},
 		Mirror{
-			ID:            4,
-			Name:          "M4",
-			CountryFields: []string{"FR", "UK"},
+			ID:           4,
+			Name:         "M4",
+			CountryCodes: CountryList{"FR", "UK"},
 		},
 	}
 
@@ -288,22 +289,22 @@ func TestByRank_Less(t *testing.T) {
 			ID:            1,
 			Name:          "M1",
 			Distance:      100.0,
-			CountryFields: []string{"IT", "FR"},
+			CountryCodes:  CountryList{"IT", "FR"},
 			ContinentCode: "EU",
 		},
 		Mirror{
 			ID:            2,
 			Name:          "M2",
 			Distance:      200.0,
-			CountryFields: []string{"FR", "CH"},
+			CountryCodes:  CountryList{"FR", "CH"},
 			ContinentCode: "EU",
 		},
 		Mirror{
-			ID:            3,
-			Name:          "M3",
-			Distance:      1000.0,
-			CountryFields: []string{"UK", "DE"},
-			Asnum:         4444,
+			ID:           3,
+			Name:         "M3",
+			Distance:     1000.0,
+			CountryCodes: CountryList{"UK", "DE"},
+			Asnum:        4444,
 		},
 	}
 
@@ -312,6 +313,41 @@ func TestByRank_Less(t *testing.T) {
 	if !matchingMirrorOrder(m, []int{3, 1, 2}) {
 		t.Fatalf("Order doesn't seem right: %s, expected M3, M1, M2", formatMirrorOrder(m))
 	}
+
+	/* */
+
+	config := &Configuration{}
+	config.Regions.Countries = []string{"US"}
+	SetConfiguration(config)
+
+	c = network.GeoIPRecord{
+		CountryCode:   "US",
+		ContinentCode: "NA",
+		RegionCode:    "US-CA",
+	}
+
+	m = Mirrors{
+		Mirror{
+			ID:           1,
+			Name:         "M1",
+			Distance:     100.0,
+			CountryCodes: CountryList{"US"},
+			RegionCode:   "US-NV",
+		},
+		Mirror{
+			ID:           2,
+			Name:         "M2",
+			Distance:     500.0,
+			CountryCodes: CountryList{"US"},
+			RegionCode:   "US-CA",
+		},
+	}
+
+	sort.Sort(ByRank{m, c})
+
+	if !matchingMirrorOrder(m, []int{2, 1}) {
+		t.Fatalf("Order doesn't seem right: %s, expected M2, M1", formatMirrorOrder(m))
+	}
 }
 
 func TestByComputedScore_Less(t *testing.T) {
@@ -345,6 +381,32 @@ func TestByComputedScore_Less(t *testing.T) {
 	}
 }
 
+func TestMirror_InMetroArea(t *testing.T) {
+	client := network.GeoIPRecord{CountryCode: "FR", City: "Paris"}
+	m := Mirror{Distance: 20}
+
+	SetConfiguration(&Configuration{})
+	if m.InMetroArea(client) {
+		t.Fatalf("The metropolitan areas must be disabled by default")
+	}
+
+	SetConfiguration(&Configuration{MetroAreaRadius: 50})
+	if !m.InMetroArea(client) {
+		t.Fatalf("The mirror is expected in the metropolitan area")
+	}
+	if m.InMetroArea(network.GeoIPRecord{CountryCode: "FR"}) {
+		t.Fatalf("The client located by country only has no metropolitan area")
+	}
+	m.Distance = 80
+	if m.InMetroArea(client) {
+		t.Fatalf("The mirror is too far from the client")
+	}
+	cdn := Mirror{CDN: true}
+	if cdn.InMetroArea(client) {
+		t.Fatalf("The CDN mirrors have no location")
+	}
+}
+
 func TestByExcludeReason_Less(t *testing.T) {
 	m := Mirrors{
 		Mirror{
@@ -541,3 +603,96 @@ func TestSetMirrorState(t *testing.T) {
 		t.Fatalf("Event MIRROR_UPDATE not published")
 	}
 }
+
+func TestSetMirrorState_Degraded(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	cmdPublish := mock.Command("PUBLISH", string(database.MIRROR_UPDATE), redigomock.NewAnyData()).Expect("ok")
+	mock.Command("HGET", "MIRROR_2", "up").Expect(int64(1))
+	cmdState := mock.Command("HMSET", "MIRROR_2", "up", false, "excludeReason", "Unreachable", "stateSince", redigomock.NewAnyInt()).
+		ExpectError(redis.Error("READONLY You can't write against a read only replica.")).
+		Expect("ok")
+
+	if err := SetMirrorState(conn, 2, false, "Unreachable"); err != database.ErrDeferred {
+		t.Fatalf("Expected ErrDeferred, got %v", err)
+	}
+	if !conn.Degraded() || conn.PendingWrites() != 1 {
+		t.Fatalf("Expected the state to be kept until the database is writable")
+	}
+	if mock.Stats(cmdPublish) != 0 {
+		t.Fatalf("Event MIRROR_UPDATE not supposed to be published")
+	}
+
+	m := Mirror{ID: 2, Up: true}
+	applyPendingState(&m)
+	if m.Up || m.ExcludeReason != "Unreachable" {
+		t.Fatalf("Expected the pending state to be applied, got %+v", m)
+	}
+
+	/* */
+
+	cmdLog := mock.Command("RPUSH", "MIRRORLOGS_2", redigomock.NewAnyData()).Expect("ok")
+
+	if err := savePendingState(conn, conn.Get(), 2); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if mock.Stats(cmdState) != 2 || mock.Stats(cmdPublish) != 1 || mock.Stats(cmdLog) != 1 {
+		t.Fatalf("Expected the pending state to be saved")
+	}
+
+	m = Mirror{ID: 2, Up: true}
+	applyPendingState(&m)
+	if !m.Up {
+		t.Fatalf("The pending state is supposed to be cleared once saved")
+	}
+}
+
+func TestSetMirrorIPFamilies(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	cmdPublish := mock.Command("PUBLISH", string(database.MIRROR_UPDATE), redigomock.NewAnyData()).Expect("ok")
+	mock.Command("HMGET", "MIRROR_1", "ipv4", "ipv6").Expect([]interface{}{[]byte("1"), []byte("0")})
+	cmdSet := mock.Command("HMSET", "MIRROR_1", "ipv4", true, "ipv6", true).Expect("ok")
+
+	if err := SetMirrorIPFamilies(conn, 1, true, true); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if mock.Stats(cmdSet) != 1 {
+		t.Fatalf("IP families not set")
+	}
+	if mock.Stats(cmdPublish) != 1 {
+		t.Fatalf("Event MIRROR_UPDATE not published")
+	}
+
+	/* */
+
+	if err := SetMirrorIPFamilies(conn, 1, true, false); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if mock.Stats(cmdSet) != 1 || mock.Stats(cmdPublish) != 1 {
+		t.Fatalf("Unchanged IP families are not supposed to be set")
+	}
+}
+
+func TestRecordMirrorMove(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	mock.Command("PUBLISH", string(database.MIRROR_UPDATE), redigomock.NewAnyData()).Expect("ok")
+	mock.Command("RPUSH", "MIRRORLOGS_1", redigomock.NewAnyData()).Expect("ok")
+
+	// First observation
+	mock.Command("HGET", "MIRROR_1", "movedTo").ExpectError(redis.ErrNil)
+	cmdSet := mock.Command("HMSET", "MIRROR_1", "movedTo", "https://new/", "movedCount", 1).Expect("ok")
+	count, err := RecordMirrorMove(conn, 1, "https://new/")
+	if err != nil || count != 1 || mock.Stats(cmdSet) != 1 {
+		t.Fatalf("The move should be recorded once, got %d (%v)", count, err)
+	}
+
+	// Same location again
+	mock.Command("HGET", "MIRROR_1", "movedTo").Expect("https://new/")
+	mock.Command("HINCRBY", "MIRROR_1", "movedCount", 1).Expect(int64(2))
+	count, err = RecordMirrorMove(conn, 1, "https://new/")
+	if err != nil || count != 2 {
+		t.Fatalf("The move should have been observed twice, got %d (%v)", count, err)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/etix/mirrorbits/database"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// maxPendingMirrors is the maximum number of registrations awaiting review
+	maxPendingMirrors = 1000
+)
+
+var (
+	// ErrPendingQueueFull is returned when too many registrations are awaiting review
+	ErrPendingQueueFull = errors.New("too many pending registrations")
+	// ErrPendingNotFound is returned when the pending registration doesn't exist
+	ErrPendingNotFound = errors.New("pending registration not found")
+)
+
+// PendingMirror is a mirror submitted by its administrator and awaiting
+// the review of an operator
+type PendingMirror struct {
+	ID          int
+	Name        string
+	HttpURL     string
+	RsyncURL    string
+	FtpURL      string
+	SponsorName string
+	SponsorURL  string
+	AdminName   string
+	AdminEmail  string
+	Comment     string
+	RemoteIP    string
+	Submitted   time.Time
+}
+
+// Validate checks the fields submitted for a pending mirror
+func (p *PendingMirror) Validate() error {
+	if p.Name == "" || strings.ContainsAny(p.Name, " \t\r\n") {
+		return errors.New("the name cannot be empty or contain a space")
+	}
+	if !strings.HasPrefix(p.HttpURL, "http://") && !strings.HasPrefix(p.HttpURL, "https://") {
+		return errors.New("an HTTP URL is required")
+	}
+	for _, u := range []string{p.HttpURL, p.RsyncURL, p.FtpURL, p.SponsorURL} {
+		if u == "" {
+			continue
+		}
+		if _, err := url.Parse(u); err != nil {
+			return errors.New("invalid URL: " + u)
+		}
+	}
+	if p.RsyncURL != "" && !strings.HasPrefix(p.RsyncURL, "rsync://") {
+		return errors.New("the rsync URL must start with rsync://")
+	}
+	if p.FtpURL != "" && !strings.HasPrefix(p.FtpURL, "ftp://") {
+		return errors.New("the FTP URL must start with ftp://")
+	}
+	if !strings.Contains(p.AdminEmail, "@") {
+		return errors.New("a valid admin email is required")
+	}
+	return nil
+}
+
+// AddPendingMirror stores the given mirror in the review queue and returns its
+// identifier
+func AddPendingMirror(r *database.Redis, p *PendingMirror) (int, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	count, err := redis.Int(conn.Do("HLEN", "PENDINGMIRRORS"))
+	if err != nil {
+		return 0, err
+	}
+	if count >= maxPendingMirrors {
+		return 0, ErrPendingQueueFull
+	}
+
+	p.ID, err = redis.Int(conn.Do("INCR", "LAST_PENDINGID"))
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := json.Marshal(p)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = conn.Do("HSET", "PENDINGMIRRORS", p.ID, value)
+	return p.ID, err
+}
+
+// GetPendingMirrors returns the mirrors awaiting review, oldest first
+func GetPendingMirrors(r *database.Redis) ([]PendingMirror, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	values, err := redis.StringMap(conn.Do("HGETALL", "PENDINGMIRRORS"))
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]PendingMirror, 0, len(values))
+	for _, v := range values {
+		var p PendingMirror
+		if err := json.Unmarshal([]byte(v), &p); err != nil {
+			log.Warningf("Unable to parse pending mirror: %s", err)
+			continue
+		}
+		pending = append(pending, p)
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].ID < pending[j].ID
+	})
+
+	return pending, nil
+}
+
+// RemovePendingMirror removes a mirror from the review queue
+func RemovePendingMirror(r *database.Redis, id int) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	removed, err := redis.Int(conn.Do("HDEL", "PENDINGMIRRORS", id))
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrPendingNotFound
+	}
+	return nil
+}
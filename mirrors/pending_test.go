@@ -0,0 +1,44 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"testing"
+)
+
+func TestPendingMirror_Validate(t *testing.T) {
+	p := PendingMirror{
+		Name:       "m1",
+		HttpURL:    "https://mirror.example.org/",
+		RsyncURL:   "rsync://mirror.example.org/project/",
+		AdminEmail: "admin@example.org",
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Expected a valid mirror, got %s", err)
+	}
+
+	invalid := p
+	invalid.Name = "my mirror"
+	if invalid.Validate() == nil {
+		t.Fatalf("Expected an error for a name containing a space")
+	}
+
+	invalid = p
+	invalid.HttpURL = "mirror.example.org"
+	if invalid.Validate() == nil {
+		t.Fatalf("Expected an error for a missing HTTP scheme")
+	}
+
+	invalid = p
+	invalid.RsyncURL = "http://mirror.example.org/"
+	if invalid.Validate() == nil {
+		t.Fatalf("Expected an error for an invalid rsync URL")
+	}
+
+	invalid = p
+	invalid.AdminEmail = ""
+	if invalid.Validate() == nil {
+		t.Fatalf("Expected an error for a missing admin email")
+	}
+}
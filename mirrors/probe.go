@@ -0,0 +1,107 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Probe holds the settings of the health checks of a mirror requiring
+// more than a plain request, e.g. a token or a Host header override for the
+// requests coming from the redirector. A mirror answering one of the
+// expected status codes (200 by default) is considered up.
+type Probe struct {
+	Headers        map[string]string `yaml:"Headers,omitempty"`
+	Username       string            `yaml:"Username,omitempty"`
+	Password       string            `yaml:"Password,omitempty"`
+	ExpectedStatus []int             `yaml:"ExpectedStatus,omitempty"`
+}
+
+// IsZero returns true if the probe has no custom setting
+func (p Probe) IsZero() bool {
+	return len(p.Headers) == 0 && p.Username == "" && p.Password == "" && len(p.ExpectedStatus) == 0
+}
+
+// Validate returns an error if one of the settings is invalid
+func (p Probe) Validate() error {
+	for name, value := range p.Headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return fmt.Errorf("invalid probe header %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid value of the probe header %s", name)
+		}
+	}
+	if p.Password != "" && p.Username == "" {
+		return fmt.Errorf("invalid probe: the password requires a username")
+	}
+	for _, code := range p.ExpectedStatus {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("invalid probe: unknown status code %d", code)
+		}
+	}
+	return nil
+}
+
+// Apply sets the headers and the credentials of the probe on the given
+// request
+func (p Probe) Apply(req *http.Request) {
+	for name, value := range p.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	if p.Username != "" {
+		req.SetBasicAuth(p.Username, p.Password)
+	}
+}
+
+// Expects returns true if the given status code means the mirror is up
+func (p Probe) Expects(statusCode int) bool {
+	if len(p.ExpectedStatus) == 0 {
+		return statusCode == 200
+	}
+	for _, code := range p.ExpectedStatus {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// RedisArg implements the redis.Argument interface, the probe being stored
+// as a json document
+func (p Probe) RedisArg() interface{} {
+	if p.IsZero() {
+		return ""
+	}
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
+// RedisScan implements the redis.Scanner interface
+func (p *Probe) RedisScan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	case nil:
+		*p = Probe{}
+		return nil
+	default:
+		return fmt.Errorf("cannot convert from %T to Probe", src)
+	}
+	*p = Probe{}
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, p)
+}
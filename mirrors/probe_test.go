@@ -0,0 +1,87 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestProbe_Validate(t *testing.T) {
+	valid := Probe{
+		Headers:        map[string]string{"Host": "mirror.example.org", "X-Token": "secret"},
+		Username:       "monitor",
+		Password:       "pass",
+		ExpectedStatus: []int{200, 206},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	invalid := []Probe{
+		{Headers: map[string]string{"X Token": "secret"}},
+		{Headers: map[string]string{"X-Token": "secret\r\nX-Other: 1"}},
+		{Password: "pass"},
+		{ExpectedStatus: []int{42}},
+	}
+	for _, p := range invalid {
+		if err := p.Validate(); err == nil {
+			t.Fatalf("Expected an error for %+v", p)
+		}
+	}
+}
+
+func TestProbe_Apply(t *testing.T) {
+	p := Probe{
+		Headers:  map[string]string{"host": "mirror.example.org", "X-Token": "secret"},
+		Username: "monitor",
+		Password: "pass",
+	}
+	req, _ := http.NewRequest("HEAD", "http://192.0.2.1/file", nil)
+	p.Apply(req)
+
+	if req.Host != "mirror.example.org" {
+		t.Fatalf("Expected the host to be overridden, got %s", req.Host)
+	}
+	if req.Header.Get("X-Token") != "secret" {
+		t.Fatalf("Expected the header X-Token to be set")
+	}
+	if user, pass, ok := req.BasicAuth(); !ok || user != "monitor" || pass != "pass" {
+		t.Fatalf("Expected the basic authentication to be set")
+	}
+}
+
+func TestProbe_Expects(t *testing.T) {
+	var p Probe
+	if !p.Expects(200) || p.Expects(401) {
+		t.Fatalf("Expected only 200 by default")
+	}
+	p.ExpectedStatus = []int{200, 401}
+	if !p.Expects(401) || p.Expects(404) {
+		t.Fatalf("Expected the configured status codes only")
+	}
+}
+
+func TestProbe_Redis(t *testing.T) {
+	p := Probe{
+		Headers:        map[string]string{"X-Token": "secret"},
+		ExpectedStatus: []int{200, 206},
+	}
+
+	var scanned Probe
+	if err := scanned.RedisScan([]byte(p.RedisArg().(string))); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(scanned, p) {
+		t.Fatalf("Expected %+v, got %+v", p, scanned)
+	}
+
+	if (Probe{}).RedisArg() != "" {
+		t.Fatalf("Expected an empty probe to be stored as an empty string")
+	}
+	if err := scanned.RedisScan([]byte("")); err != nil || !scanned.IsZero() {
+		t.Fatalf("Expected an empty probe, got %+v (%v)", scanned, err)
+	}
+}
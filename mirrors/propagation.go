@@ -0,0 +1,121 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"sort"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/database"
+	"github.com/etix/mirrorbits/network"
+	"github.com/gomodule/redigo/redis"
+)
+
+// FilePropagation describes how far a file of the repository has been
+// propagated to the mirrors
+type FilePropagation struct {
+	Path       string
+	Mirrors    []string
+	Missing    []string
+	Percentage float32
+	ETA        time.Time
+}
+
+// GetPropagation returns the propagation of all the files found under the
+// given prefix across the enabled mirrors (see GetFilesPropagation)
+func GetPropagation(r *database.Redis, c *Cache, prefix string) ([]FilePropagation, error) {
+	files, err := c.GetFiles(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return GetFilesPropagation(r, c, files)
+}
+
+// GetFilesPropagation returns the propagation of the given files across the
+// enabled mirrors. The ETA is an estimation of the time at which all the
+// mirrors will have been rescanned.
+func GetFilesPropagation(r *database.Redis, c *Cache, files []string) ([]FilePropagation, error) {
+	conn := r.Get()
+	ids, err := redis.Ints(conn.Do("HKEYS", "MIRRORS"))
+	conn.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return getPropagation(c, files, ids)
+}
+
+func getPropagation(c *Cache, files []string, ids []int) ([]FilePropagation, error) {
+	enabled := make(map[int]Mirror)
+	for _, id := range ids {
+		mirror, err := c.GetMirror(id)
+		if err != nil {
+			return nil, err
+		}
+		if mirror.Enabled {
+			enabled[id] = mirror
+		}
+	}
+
+	scanInterval := time.Duration(GetConfig().ScanInterval) * time.Minute
+	now := time.Now()
+
+	result := make([]FilePropagation, 0, len(files))
+
+	for _, path := range files {
+		fileInfo, err := c.GetFileInfo(path)
+		if err != nil {
+			return nil, err
+		}
+
+		mlist, err := c.GetMirrors(path, network.GeoIPRecord{})
+		if err != nil {
+			return nil, err
+		}
+
+		p := FilePropagation{
+			Path:    path,
+			Mirrors: []string{},
+			Missing: []string{},
+		}
+
+		found := make(map[int]bool)
+		for i := range mlist {
+			m := &mlist[i]
+			if !m.Enabled || m.FileMismatch(&fileInfo) != "" {
+				continue
+			}
+			found[m.ID] = true
+			p.Mirrors = append(p.Mirrors, m.Name)
+		}
+
+		for id, m := range enabled {
+			if found[id] {
+				continue
+			}
+			p.Missing = append(p.Missing, m.Name)
+
+			// The file should be found during the next scan
+			next := m.LastSync.Add(scanInterval)
+			if next.Before(now) {
+				next = now
+			}
+			if next.After(p.ETA) {
+				p.ETA = next
+			}
+		}
+
+		sort.Strings(p.Mirrors)
+		sort.Strings(p.Missing)
+
+		if len(enabled) > 0 {
+			p.Percentage = float32(len(p.Mirrors)) * 100 / float32(len(enabled))
+		}
+
+		result = append(result, p)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"net/http"
+	"net/url"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/core"
+)
+
+// ProxyURL returns the proxy reaching the mirror, its own one if set or the
+// global OutboundProxy otherwise. A nil URL means a direct connection.
+func (m *Mirror) ProxyURL() (*url.URL, error) {
+	if m.Proxy != "" {
+		return ParseProxy(m.Proxy)
+	}
+	return ParseProxy(GetConfig().OutboundProxy)
+}
+
+// ProxyFromContext is the Proxy function of an http.Transport using the
+// proxy stored in the context of the request (see core.ContextProxy)
+func ProxyFromContext(req *http.Request) (*url.URL, error) {
+	proxy, _ := req.Context().Value(core.ContextProxy).(*url.URL)
+	return proxy, nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/core"
+)
+
+func TestMirror_ProxyURL(t *testing.T) {
+	SetConfiguration(&Configuration{OutboundProxy: "http://proxy.example.org:3128"})
+	defer SetConfiguration(&Configuration{})
+
+	m := Mirror{}
+	if u, err := m.ProxyURL(); err != nil || u == nil || u.Host != "proxy.example.org:3128" {
+		t.Fatalf("Expected the global proxy, got %v (%v)", u, err)
+	}
+
+	m.Proxy = "socks5://local.example.org:1080"
+	if u, err := m.ProxyURL(); err != nil || u == nil || u.Host != "local.example.org:1080" {
+		t.Fatalf("Expected the proxy of the mirror, got %v (%v)", u, err)
+	}
+
+	m.Proxy = "direct"
+	if u, err := m.ProxyURL(); err != nil || u != nil {
+		t.Fatalf("Expected a direct connection, got %v (%v)", u, err)
+	}
+}
+
+func TestProxyFromContext(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://mirror.example.org/", nil)
+	if u, err := ProxyFromContext(req); err != nil || u != nil {
+		t.Fatalf("Expected a direct connection, got %v (%v)", u, err)
+	}
+
+	proxy, _ := url.Parse("http://proxy.example.org:3128")
+	req = req.WithContext(context.WithValue(req.Context(), core.ContextProxy, proxy))
+	if u, err := ProxyFromContext(req); err != nil || u != proxy {
+		t.Fatalf("Expected %s, got %v (%v)", proxy, u, err)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/core"
+	"github.com/etix/mirrorbits/database"
+)
+
+const (
+	pushTimeout = 30 * time.Second
+)
+
+// PushEvent is the document posted to the webhooks of the mirrors
+type PushEvent struct {
+	Event  string
+	Mirror string
+	Time   time.Time
+}
+
+// PushMirrors notifies the mirrors having a push hook that the repository has
+// been updated so they can start syncing immediately. A follow-up scan of the
+// notified mirrors is scheduled after the configured delay.
+func PushMirrors(r *database.Redis) {
+	hooks := GetConfig().PushMirroring.Hooks
+	if len(hooks) == 0 {
+		return
+	}
+
+	list, err := r.GetListOfMirrors()
+	if err != nil {
+		log.Errorf("Push mirroring: can't fetch the list of mirrors: %s", err)
+		return
+	}
+
+	ids := make(map[string]int, len(list))
+	for id, name := range list {
+		ids[name] = id
+	}
+
+	delay := time.Duration(GetConfig().PushMirroring.ScanDelay) * time.Minute
+
+	for _, hook := range hooks {
+		id, ok := ids[hook.Mirror]
+		if !ok {
+			log.Warningf("Push mirroring: unknown mirror %s", hook.Mirror)
+			continue
+		}
+
+		go func(id int, url, ssh, sshKey, command, name string) {
+			var err error
+			if url != "" {
+				err = pushWebhook(url, name)
+			} else {
+				err = pushSSH(ssh, sshKey, command)
+			}
+			if err != nil {
+				log.Warningf("Push mirroring: unable to notify %s: %s", name, err)
+				return
+			}
+			log.Noticef("Push mirroring: %s notified", name)
+
+			if delay > 0 {
+				time.AfterFunc(delay, func() {
+					if err := RequestScan(r, id, core.ScanSourcePush); err != nil {
+						log.Warningf("Push mirroring: unable to schedule the scan of %s: %s", name, err)
+					}
+				})
+			}
+		}(id, hook.URL, hook.SSH, hook.SSHKey, hook.Command, hook.Mirror)
+	}
+}
+
+func pushWebhook(url, name string) error {
+	body, err := json.Marshal(PushEvent{
+		Event:  "refresh",
+		Mirror: name,
+		Time:   time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Mirrorbits/"+core.VERSION+" PUSH")
+
+	client := &http.Client{
+		Timeout: pushTimeout,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func pushSSH(destination, key, command string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+	defer cancel()
+
+	args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=10"}
+	if key != "" {
+		args = append(args, "-i", key)
+	}
+	args = append(args, destination)
+	if command != "" {
+		args = append(args, command)
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
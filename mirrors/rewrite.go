@@ -0,0 +1,150 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/etix/mirrorbits/utils"
+)
+
+var (
+	// Compiled patterns of the regular expression rules
+	rewritePatterns sync.Map
+)
+
+// PathRewrite is a rule mapping the paths of the repository to the paths
+// of a mirror hosting the tree under a different prefix or with different
+// directory names.
+//
+// A prefix rule replaces the prefix From of the paths of the repository by
+// To, and To by From in the paths found by the scans. A regular expression
+// rule replaces the matches of From by To (which can refer to the groups
+// of From with $1) and only applies to the redirections, or to the scans if
+// Scan is set.
+type PathRewrite struct {
+	From   string `yaml:"From"`
+	To     string `yaml:"To"`
+	Regexp bool   `yaml:"Regexp,omitempty"`
+	Scan   bool   `yaml:"Scan,omitempty"`
+}
+
+// PathRewrites is the list of rules of a mirror, the first matching rule
+// is applied
+type PathRewrites []PathRewrite
+
+// Validate returns an error if one of the rules is invalid
+func (p PathRewrites) Validate() error {
+	for _, r := range p {
+		if r.Regexp {
+			if _, err := regexp.Compile(r.From); err != nil {
+				return fmt.Errorf("invalid path rewrite %s: %s", r.From, err)
+			}
+			continue
+		}
+		if !strings.HasPrefix(r.From, "/") || !strings.HasPrefix(r.To, "/") {
+			return fmt.Errorf("invalid path rewrite %s => %s: the prefixes must start with /", r.From, r.To)
+		}
+	}
+	return nil
+}
+
+func rewritePattern(pattern string) *regexp.Regexp {
+	if re, ok := rewritePatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil
+	}
+	rewritePatterns.Store(pattern, re)
+	return re
+}
+
+// ToMirror returns the path of the given file of the repository on the
+// mirror
+func (p PathRewrites) ToMirror(path string) string {
+	for _, r := range p {
+		if r.Regexp {
+			if r.Scan {
+				continue
+			}
+			if re := rewritePattern(r.From); re != nil && re.MatchString(path) {
+				return re.ReplaceAllString(path, r.To)
+			}
+		} else if strings.HasPrefix(path, r.From) {
+			return r.To + strings.TrimPrefix(path, r.From)
+		}
+	}
+	return path
+}
+
+// FromMirror returns the path in the repository of a file found on the
+// mirror
+func (p PathRewrites) FromMirror(path string) string {
+	for _, r := range p {
+		if r.Regexp {
+			if !r.Scan {
+				continue
+			}
+			if re := rewritePattern(r.From); re != nil && re.MatchString(path) {
+				return re.ReplaceAllString(path, r.To)
+			}
+		} else if strings.HasPrefix(path, r.To) {
+			return r.From + strings.TrimPrefix(path, r.To)
+		}
+	}
+	return path
+}
+
+// RedisArg implements the redis.Argument interface, the rules being stored
+// as a json array
+func (p PathRewrites) RedisArg() interface{} {
+	if len(p) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal([]PathRewrite(p))
+	return string(b)
+}
+
+// RedisScan implements the redis.Scanner interface
+func (p *PathRewrites) RedisScan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	case nil:
+		*p = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot convert from %T to PathRewrites", src)
+	}
+	if len(b) == 0 {
+		*p = nil
+		return nil
+	}
+	var list []PathRewrite
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	*p = PathRewrites(list)
+	return nil
+}
+
+// FileURL returns the URL of the given file of the repository on the
+// mirror, with the path percent-encoded
+func (m *Mirror) FileURL(path string) string {
+	return utils.ConcatURL(m.HttpURL, utils.EscapePath(m.PathRewrites.ToMirror(path)))
+}
+
+// AppendFileURL appends the URL of the given file to b like FileURL
+func (m *Mirror) AppendFileURL(b []byte, path string) []byte {
+	return utils.AppendURL(b, m.HttpURL, utils.EscapePath(m.PathRewrites.ToMirror(path)))
+}
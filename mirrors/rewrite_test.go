@@ -0,0 +1,61 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"testing"
+)
+
+func TestPathRewrites(t *testing.T) {
+	rules := PathRewrites{
+		{From: "/releases/", To: "/pub/project/releases/"},
+		{From: `^/nightly/(\d+)/`, To: "/nightlies/build-$1/", Regexp: true},
+		{From: `^/nightlies/build-(\d+)/`, To: "/nightly/$1/", Regexp: true, Scan: true},
+	}
+	if err := rules.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for path, expected := range map[string]string{
+		"/releases/1.0/a.iso": "/pub/project/releases/1.0/a.iso",
+		"/nightly/42/b.iso":   "/nightlies/build-42/b.iso",
+		"/other/c.iso":        "/other/c.iso",
+	} {
+		if p := rules.ToMirror(path); p != expected {
+			t.Fatalf("ToMirror(%s) should be %s, got %s", path, expected, p)
+		}
+		if p := rules.FromMirror(expected); p != path {
+			t.Fatalf("FromMirror(%s) should be %s, got %s", expected, path, p)
+		}
+	}
+
+	m := Mirror{HttpURL: "http://example.org/", PathRewrites: rules}
+	if u := m.FileURL("/releases/1.0/a.iso"); u != "http://example.org/pub/project/releases/1.0/a.iso" {
+		t.Fatalf("Unexpected URL %s", u)
+	}
+	if u := m.FileURL("/releases/1.0/my file #2?.iso"); u != "http://example.org/pub/project/releases/1.0/my%20file%20%232%3F.iso" {
+		t.Fatalf("Unexpected URL %s", u)
+	}
+
+	if err := (PathRewrites{{From: "releases", To: "/pub"}}).Validate(); err == nil {
+		t.Fatalf("A prefix without leading slash should be rejected")
+	}
+	if err := (PathRewrites{{From: "(", Regexp: true}}).Validate(); err == nil {
+		t.Fatalf("An invalid regular expression should be rejected")
+	}
+
+	var scanned PathRewrites
+	if err := scanned.RedisScan(rules.RedisArg()); err != nil || len(scanned) != 3 || !scanned[2].Scan {
+		t.Fatalf("Unexpected rules %v (%v)", scanned, err)
+	}
+}
+
+func BenchmarkMirror_FileURL(b *testing.B) {
+	m := &Mirror{HttpURL: "http://mirror.example.org/project/"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m.FileURL("/releases/2.0/project-2.0-x86_64.iso")
+	}
+}
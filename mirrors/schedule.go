@@ -0,0 +1,171 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/etix/mirrorbits/utils"
+)
+
+var (
+	// Time zones of the schedules already loaded
+	scheduleLocations sync.Map
+
+	weekDays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// Schedule shapes the traffic sent to a mirror over the day, e.g. for the
+// sponsors only donating their off-peak bandwidth. The share of its normal
+// traffic (in percent) given by the first period matching the local time of
+// the mirror is sent to it, or Default outside of the periods. A schedule
+// without period lets the mirror handle its full traffic.
+type Schedule struct {
+	TimeZone string           `yaml:"TimeZone,omitempty"`
+	Default  int              `yaml:"Default,omitempty"`
+	Periods  []SchedulePeriod `yaml:"Periods,omitempty"`
+}
+
+// SchedulePeriod is a period of the day, from From (included) to To
+// (excluded) in the HH:MM format, restricted to the given days (mon, tue...)
+// if any. A period ending before its start spans midnight.
+type SchedulePeriod struct {
+	Days  []string `yaml:"Days,omitempty"`
+	From  string   `yaml:"From"`
+	To    string   `yaml:"To"`
+	Share int      `yaml:"Share"`
+}
+
+// IsZero returns true if the schedule has no period
+func (s Schedule) IsZero() bool {
+	return len(s.Periods) == 0
+}
+
+// parseClock returns the number of minutes since midnight of the given time
+// in the HH:MM format
+func parseClock(clock string) (int, error) {
+	var hours, minutes int
+	if _, err := fmt.Sscanf(clock, "%d:%d", &hours, &minutes); err != nil || len(clock) != 5 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	if hours < 0 || minutes < 0 || minutes > 59 || hours*60+minutes > 24*60 {
+		return 0, fmt.Errorf("invalid time %q", clock)
+	}
+	return hours*60 + minutes, nil
+}
+
+func scheduleLocation(name string) (*time.Location, error) {
+	if loc, ok := scheduleLocations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	scheduleLocations.Store(name, loc)
+	return loc, nil
+}
+
+// Validate returns an error if the schedule is invalid
+func (s Schedule) Validate() error {
+	if _, err := scheduleLocation(s.TimeZone); err != nil {
+		return fmt.Errorf("invalid schedule: unknown time zone %s", s.TimeZone)
+	}
+	if s.Default < 0 || s.Default > 100 {
+		return fmt.Errorf("invalid schedule: the default share must be between 0 and 100")
+	}
+	for _, p := range s.Periods {
+		if _, err := parseClock(p.From); err != nil {
+			return fmt.Errorf("invalid schedule: %s", err)
+		}
+		if _, err := parseClock(p.To); err != nil {
+			return fmt.Errorf("invalid schedule: %s", err)
+		}
+		if p.Share < 0 || p.Share > 100 {
+			return fmt.Errorf("invalid schedule: the share of %s-%s must be between 0 and 100", p.From, p.To)
+		}
+		for _, d := range p.Days {
+			if !utils.IsInSlice(strings.ToLower(d), weekDays) {
+				return fmt.Errorf("invalid schedule: unknown day %s", d)
+			}
+		}
+	}
+	return nil
+}
+
+// Share returns the share of its traffic (in percent) the mirror accepts at
+// the given time
+func (s Schedule) Share(now time.Time) int {
+	if s.IsZero() {
+		return 100
+	}
+	loc, err := scheduleLocation(s.TimeZone)
+	if err != nil {
+		return 100
+	}
+	local := now.In(loc)
+	minutes := local.Hour()*60 + local.Minute()
+	day := weekDays[local.Weekday()]
+
+	for _, p := range s.Periods {
+		if len(p.Days) > 0 && !utils.IsInSlice(day, lower(p.Days)) {
+			continue
+		}
+		from, err1 := parseClock(p.From)
+		to, err2 := parseClock(p.To)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if from < to && minutes >= from && minutes < to {
+			return p.Share
+		}
+		if from >= to && (minutes >= from || minutes < to) {
+			return p.Share
+		}
+	}
+	return s.Default
+}
+
+func lower(list []string) []string {
+	out := make([]string, len(list))
+	for i, s := range list {
+		out[i] = strings.ToLower(s)
+	}
+	return out
+}
+
+// RedisArg implements the redis.Argument interface, the schedule being
+// stored as a json document
+func (s Schedule) RedisArg() interface{} {
+	if s.IsZero() {
+		return ""
+	}
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// RedisScan implements the redis.Scanner interface
+func (s *Schedule) RedisScan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	case nil:
+		*s = Schedule{}
+		return nil
+	default:
+		return fmt.Errorf("cannot convert from %T to Schedule", src)
+	}
+	*s = Schedule{}
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, s)
+}
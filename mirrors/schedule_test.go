@@ -0,0 +1,108 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchedule_Share(t *testing.T) {
+	var s Schedule
+	if share := s.Share(time.Now()); share != 100 {
+		t.Fatalf("Expected the full traffic without schedule, got %d", share)
+	}
+
+	// Full traffic from midnight to 8am in Paris, 30% otherwise
+	s = Schedule{
+		TimeZone: "Europe/Paris",
+		Default:  30,
+		Periods: []SchedulePeriod{
+			{From: "00:00", To: "08:00", Share: 100},
+		},
+	}
+	cases := map[string]int{
+		"2019-01-15T22:30:00Z": 30,  // 23:30 in Paris
+		"2019-01-15T23:30:00Z": 100, // 00:30 in Paris
+		"2019-01-16T06:59:00Z": 100,
+		"2019-01-16T07:00:00Z": 30,
+		"2019-01-16T12:00:00Z": 30,
+	}
+	for date, expected := range cases {
+		now, _ := time.Parse(time.RFC3339, date)
+		if share := s.Share(now); share != expected {
+			t.Fatalf("Expected %d%% at %s, got %d%%", expected, date, share)
+		}
+	}
+
+	// Period spanning midnight, on week-ends only
+	s = Schedule{
+		Periods: []SchedulePeriod{
+			{Days: []string{"Sat", "sun"}, From: "22:00", To: "06:00", Share: 50},
+		},
+	}
+	cases = map[string]int{
+		"2019-01-19T23:00:00Z": 50, // Saturday
+		"2019-01-20T05:00:00Z": 50, // Sunday
+		"2019-01-21T05:00:00Z": 0,  // Monday
+		"2019-01-19T12:00:00Z": 0,
+	}
+	for date, expected := range cases {
+		now, _ := time.Parse(time.RFC3339, date)
+		if share := s.Share(now); share != expected {
+			t.Fatalf("Expected %d%% at %s, got %d%%", expected, date, share)
+		}
+	}
+}
+
+func TestSchedule_Validate(t *testing.T) {
+	valid := Schedule{
+		TimeZone: "America/New_York",
+		Default:  30,
+		Periods: []SchedulePeriod{
+			{Days: []string{"mon"}, From: "20:00", To: "24:00", Share: 100},
+		},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	invalid := []Schedule{
+		{TimeZone: "Mars/Olympus"},
+		{Default: 101},
+		{Periods: []SchedulePeriod{{From: "8:00", To: "10:00"}}},
+		{Periods: []SchedulePeriod{{From: "08:00", To: "24:30"}}},
+		{Periods: []SchedulePeriod{{From: "08:00", To: "10:00", Share: -1}}},
+		{Periods: []SchedulePeriod{{Days: []string{"monday"}, From: "08:00", To: "10:00"}}},
+	}
+	for _, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Fatalf("Expected an error for %+v", s)
+		}
+	}
+}
+
+func TestSchedule_Redis(t *testing.T) {
+	s := Schedule{
+		TimeZone: "UTC",
+		Default:  30,
+		Periods:  []SchedulePeriod{{From: "00:00", To: "08:00", Share: 100}},
+	}
+
+	var scanned Schedule
+	if err := scanned.RedisScan([]byte(s.RedisArg().(string))); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(scanned, s) {
+		t.Fatalf("Expected %+v, got %+v", s, scanned)
+	}
+
+	if (Schedule{}).RedisArg() != "" {
+		t.Fatalf("Expected an empty schedule to be stored as an empty string")
+	}
+	if err := scanned.RedisScan([]byte("")); err != nil || !scanned.IsZero() {
+		t.Fatalf("Expected an empty schedule, got %+v (%v)", scanned, err)
+	}
+}
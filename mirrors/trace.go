@@ -0,0 +1,96 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/etix/mirrorbits/database"
+	"github.com/etix/mirrorbits/network"
+	"github.com/gomodule/redigo/redis"
+)
+
+// Trace is the record of the selection made for a request
+type Trace struct {
+	RequestID string
+	Time      time.Time
+	Path      string
+	IP        string
+	Country   string
+	ASNum     uint
+	Output    string
+	Status    int
+	Error     string `json:",omitempty"`
+	Fallback  bool
+	Mirrors   []string
+	Excluded  []TraceExclusion
+}
+
+// TraceExclusion is a mirror excluded during a selection
+type TraceExclusion struct {
+	Name   string
+	Reason string
+}
+
+// NewTrace returns the trace of the given results
+func NewTrace(requestID, output string, status int, results *Results, err error) *Trace {
+	t := &Trace{
+		RequestID: requestID,
+		Time:      time.Now(),
+		Path:      results.FileInfo.Path,
+		IP:        network.AnonymizeIP(results.IP),
+		Country:   results.ClientInfo.CountryCode,
+		ASNum:     results.ClientInfo.ASNum,
+		Output:    output,
+		Status:    status,
+		Fallback:  results.Fallback,
+	}
+	if err != nil {
+		t.Error = err.Error()
+	}
+	for _, m := range results.MirrorList {
+		t.Mirrors = append(t.Mirrors, m.Name)
+	}
+	for _, m := range results.ExcludedList {
+		t.Excluded = append(t.Excluded, TraceExclusion{
+			Name:   m.Name,
+			Reason: m.ExcludeReason,
+		})
+	}
+	return t
+}
+
+// SaveTrace stores the trace in the database for the given duration
+func SaveTrace(r *database.Redis, trace *Trace, retention time.Duration) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	value, err := json.Marshal(trace)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Do("SET", "REQUEST_"+trace.RequestID, value, "EX", int(retention.Seconds()))
+	return err
+}
+
+// GetTrace returns the trace of the given request or nil if it has expired
+func GetTrace(r *database.Redis, requestID string) (*Trace, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	value, err := redis.Bytes(conn.Do("GET", "REQUEST_"+requestID))
+	if err == redis.ErrNil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	trace := &Trace{}
+	if err := json.Unmarshal(value, trace); err != nil {
+		return nil, err
+	}
+	return trace, nil
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package mirrors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/etix/mirrorbits/filesystem"
+	. "github.com/etix/mirrorbits/testing"
+	"github.com/gomodule/redigo/redis"
+)
+
+func TestNewTrace(t *testing.T) {
+	results := &Results{
+		FileInfo:     filesystem.FileInfo{Path: "/test/file.tgz"},
+		IP:           "192.0.2.1",
+		MirrorList:   Mirrors{{Name: "m1"}, {Name: "m2"}},
+		ExcludedList: Mirrors{{Name: "m3", ExcludeReason: "File not present"}},
+		Fallback:     true,
+	}
+
+	trace := NewTrace("abc", "REDIRECT", 302, results, errors.New("test"))
+
+	if trace.RequestID != "abc" || trace.Path != "/test/file.tgz" || trace.Status != 302 || !trace.Fallback {
+		t.Fatalf("Unexpected trace %+v", trace)
+	}
+	if len(trace.Mirrors) != 2 || trace.Mirrors[0] != "m1" {
+		t.Fatalf("Unexpected mirrors %v", trace.Mirrors)
+	}
+	if len(trace.Excluded) != 1 || trace.Excluded[0].Reason != "File not present" {
+		t.Fatalf("Unexpected exclusions %v", trace.Excluded)
+	}
+	if trace.Error != "test" {
+		t.Fatalf("Expected the error to be recorded")
+	}
+}
+
+func TestGetTrace(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	mock.Command("GET", "REQUEST_abc").Expect([]byte(`{"RequestID":"abc","Path":"/file","Mirrors":["m1"]}`))
+	mock.Command("GET", "REQUEST_expired").ExpectError(redis.ErrNil)
+
+	trace, err := GetTrace(conn, "abc")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if trace == nil || trace.Path != "/file" || len(trace.Mirrors) != 1 {
+		t.Fatalf("Unexpected trace %+v", trace)
+	}
+
+	trace, err = GetTrace(conn, "expired")
+	if err != nil || trace != nil {
+		t.Fatalf("Expected no trace, got %+v (%v)", trace, err)
+	}
+}
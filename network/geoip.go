@@ -39,6 +39,7 @@ type GeoIPRecord struct {
 	// City DB
 	CountryCode   string
 	ContinentCode string
+	RegionCode    string // ISO 3166-2 subdivision code (e.g. US-CA)
 	City          string
 	Country       string
 	Latitude      float32
@@ -112,6 +113,29 @@ func (g *GeoIP) loadDB(filename string, geodb **geoipDB, geoiperror *GeoIPError)
 	return nil
 }
 
+// GeoIPDatabase describes a GeoIP database loaded in memory
+type GeoIPDatabase struct {
+	Filename  string
+	BuildDate time.Time
+}
+
+// Databases returns the GeoIP databases loaded in memory
+func (g *GeoIP) Databases() []GeoIPDatabase {
+	g.RLock()
+	defer g.RUnlock()
+
+	var list []GeoIPDatabase
+	for _, db := range []*geoipDB{g.city, g.asn} {
+		if db != nil && db.db != nil {
+			list = append(list, GeoIPDatabase{
+				Filename:  db.filename,
+				BuildDate: db.modTime,
+			})
+		}
+	}
+	return list
+}
+
 // GeoIPError holds errors while loading the different databases
 type GeoIPError struct {
 	Errors []error
@@ -165,6 +189,9 @@ func (g *GeoIP) GetRecord(ip string) (ret GeoIPRecord) {
 		Continent struct {
 			Code string `maxminddb:"code"`
 		} `maxminddb:"continent"`
+		Subdivisions []struct {
+			IsoCode string `maxminddb:"iso_code"`
+		} `maxminddb:"subdivisions"`
 		Location struct {
 			Latitude  float64 `maxminddb:"latitude"`
 			Longitude float64 `maxminddb:"longitude"`
@@ -190,6 +217,9 @@ func (g *GeoIP) GetRecord(ip string) (ret GeoIPRecord) {
 		}
 		ret.CountryCode = cityDb.Country.IsoCode
 		ret.ContinentCode = cityDb.Continent.Code
+		if len(cityDb.Subdivisions) > 0 && cityDb.Subdivisions[0].IsoCode != "" && ret.CountryCode != "" {
+			ret.RegionCode = ret.CountryCode + "-" + cityDb.Subdivisions[0].IsoCode
+		}
 		ret.City = cityDb.City.Names.English
 		ret.Country = cityDb.Country.Names.English
 		ret.Latitude = float32(cityDb.Location.Latitude)
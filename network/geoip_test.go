@@ -90,6 +90,33 @@ func TestGeoIP_GetRecord(t *testing.T) {
 	}
 }
 
+func TestGeoIP_Databases(t *testing.T) {
+	g := NewGeoIP()
+
+	if len(g.Databases()) != 0 {
+		t.Fatalf("Expected no database loaded")
+	}
+
+	built := time.Date(2019, 5, 14, 0, 0, 0, 0, time.UTC)
+	g.city = &geoipDB{
+		filename: "city.mmdb",
+		modTime:  built,
+		db:       &GeoIPMockCity{},
+	}
+	// Failed to load
+	g.asn = &geoipDB{
+		filename: "asn.mmdb",
+	}
+
+	dbs := g.Databases()
+	if len(dbs) != 1 {
+		t.Fatalf("Expected 1 database, got %d", len(dbs))
+	}
+	if dbs[0].Filename != "city.mmdb" || !dbs[0].BuildDate.Equal(built) {
+		t.Fatalf("Unexpected database: %+v", dbs[0])
+	}
+}
+
 func TestIsIPv6(t *testing.T) {
 	g := NewGeoIP()
 	if g.IsIPv6("192.168.0.1") == true {
@@ -0,0 +1,71 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package network
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+)
+
+var (
+	salt struct {
+		sync.Mutex
+		value  []byte
+		period int64
+	}
+)
+
+// AnonymizeIP returns the given address of a client truncated to its
+// network and hashed as configured in ClientPrivacy
+func AnonymizeIP(ip string) string {
+	conf := GetConfig().ClientPrivacy
+	// IPv6 addresses taken from RemoteAddr keep their brackets
+	parsed := net.ParseIP(strings.Trim(ip, "[]"))
+	if parsed == nil {
+		return ip
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		if conf.IPv4Prefix > 0 {
+			parsed = v4.Mask(net.CIDRMask(conf.IPv4Prefix, 32))
+		}
+	} else if conf.IPv6Prefix > 0 {
+		parsed = parsed.Mask(net.CIDRMask(conf.IPv6Prefix, 128))
+	}
+	if conf.HashRotation <= 0 {
+		return parsed.String()
+	}
+
+	mac := hmac.New(sha256.New, currentSalt(time.Duration(conf.HashRotation)*time.Hour))
+	mac.Write(parsed)
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// currentSalt returns the salt of the current period, a new one being drawn
+// at the beginning of each period
+func currentSalt(rotation time.Duration) []byte {
+	salt.Lock()
+	defer salt.Unlock()
+
+	period := time.Now().UnixNano() / int64(rotation)
+	if salt.value == nil || salt.period != period {
+		value := make([]byte, 32)
+		if _, err := rand.Read(value); err != nil {
+			log.Errorf("Unable to renew the salt of the addresses: %s", err)
+			if salt.value != nil {
+				return salt.value
+			}
+		}
+		salt.value = value
+		salt.period = period
+	}
+	return salt.value
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package network
+
+import (
+	"testing"
+
+	. "github.com/etix/mirrorbits/config"
+)
+
+func TestAnonymizeIP(t *testing.T) {
+	SetConfiguration(&Configuration{})
+	if ip := AnonymizeIP("192.0.2.42"); ip != "192.0.2.42" {
+		t.Fatalf("The address must be kept by default, got %s", ip)
+	}
+
+	c := &Configuration{}
+	c.ClientPrivacy.IPv4Prefix = 24
+	c.ClientPrivacy.IPv6Prefix = 48
+	SetConfiguration(c)
+	tests := map[string]string{
+		"192.0.2.42":              "192.0.2.0",
+		"2001:db8:1234:5678::1":   "2001:db8:1234::",
+		"[2001:db8:1234:5678::1]": "2001:db8:1234::",
+		"invalid":                 "invalid",
+	}
+	for ip, expected := range tests {
+		if a := AnonymizeIP(ip); a != expected {
+			t.Fatalf("%s: expected %s, got %s", ip, expected, a)
+		}
+	}
+
+	c.ClientPrivacy.HashRotation = 24
+	a := AnonymizeIP("192.0.2.42")
+	if a == "192.0.2.0" || len(a) != 16 {
+		t.Fatalf("Expected a hash, got %s", a)
+	}
+	if b := AnonymizeIP("192.0.2.1"); b != a {
+		t.Fatalf("The addresses of the same network must match: %s != %s", a, b)
+	}
+	if c := AnonymizeIP("198.51.100.1"); c == a {
+		t.Fatalf("The addresses of different networks must differ")
+	}
+}
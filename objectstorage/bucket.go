@@ -0,0 +1,262 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package objectstorage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+)
+
+const (
+	// The hash of an empty payload, all the requests are bodyless
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+var (
+	// The objects are streamed, only the connection and the headers are
+	// subject to a timeout
+	client = &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 30 * time.Second,
+			MaxIdleConnsPerHost:   10,
+			// The objects are passed along as stored
+			DisableCompression: true,
+		},
+	}
+)
+
+// Object is an object of the bucket
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	ETag         string
+}
+
+// Bucket gives access to the S3-compatible bucket holding the repository
+type Bucket struct {
+	endpoint     *url.URL
+	region       string
+	name         string
+	prefix       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	pathStyle    bool
+}
+
+// Error is an error returned by the object storage
+type Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("object storage: %s", http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("object storage: %s: %s", e.Code, e.Message)
+}
+
+// NewBucket returns the bucket configured in ObjectStorage. The credentials
+// default to the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN environment variables, the bucket is accessed
+// anonymously without credentials.
+func NewBucket() (*Bucket, error) {
+	conf := GetConfig().ObjectStorage
+	endpoint, err := url.Parse(conf.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bucket{
+		endpoint:  endpoint,
+		region:    conf.Region,
+		name:      conf.Bucket,
+		prefix:    conf.Prefix,
+		accessKey: conf.AccessKey,
+		secretKey: conf.SecretKey,
+		pathStyle: conf.PathStyle,
+	}
+	if b.accessKey == "" {
+		b.accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		b.secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		b.sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	return b, nil
+}
+
+// Path returns the path in the repository of the given key, or an empty
+// string if the key lies outside of the prefix of the repository
+func (b *Bucket) Path(key string) string {
+	if !strings.HasPrefix(key, b.prefix) {
+		return ""
+	}
+	return "/" + key[len(b.prefix):]
+}
+
+// Key returns the key of the object stored at the given path of the repository
+func (b *Bucket) Key(path string) string {
+	return b.prefix + strings.TrimPrefix(path, "/")
+}
+
+// objectURL returns the URL of the given key
+func (b *Bucket) objectURL(key string, query url.Values) *url.URL {
+	u := *b.endpoint
+	p := strings.TrimSuffix(u.Path, "/")
+	if b.pathStyle {
+		p += "/" + b.name
+	} else {
+		u.Host = b.name + "." + u.Host
+	}
+	if key == "" && b.pathStyle {
+		// The bucket itself
+		u.Path, u.RawPath = p, uriEncode(p, false)
+	} else {
+		u.Path = p + "/" + key
+		u.RawPath = uriEncode(p, false) + "/" + uriEncode(key, false)
+	}
+	if query != nil {
+		u.RawQuery = canonicalQuery(query)
+	}
+	return &u
+}
+
+func (b *Bucket) newRequest(ctx context.Context, method, key string, query url.Values, header http.Header) (*http.Request, error) {
+	req, err := http.NewRequest(method, b.objectURL(key, query).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	b.sign(req, time.Now())
+	return req, nil
+}
+
+// do sends the request and returns the response if its status is one of the
+// expected ones
+func do(req *http.Request, expected ...int) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return resp, nil
+		}
+	}
+	defer resp.Body.Close()
+	e := &Error{StatusCode: resp.StatusCode}
+	if req.Method != http.MethodHead {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		xml.Unmarshal(body, e)
+	}
+	return nil, e
+}
+
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		ETag         string    `xml:"ETag"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+}
+
+// List calls fn for each object of the repository, the directory markers
+// (empty keys ending with a slash) being skipped
+func (b *Bucket) List(ctx context.Context, fn func(Object) error) error {
+	var token string
+	for {
+		query := url.Values{
+			"list-type": {"2"},
+			"prefix":    {b.prefix},
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := b.newRequest(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := do(req, http.StatusOK)
+		if err != nil {
+			return err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		for _, c := range result.Contents {
+			if strings.HasSuffix(c.Key, "/") {
+				continue
+			}
+			err := fn(Object{
+				Key:          c.Key,
+				Size:         c.Size,
+				LastModified: c.LastModified,
+				ETag:         strings.Trim(c.ETag, `"`),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Head returns the headers of the given object, including the checksums
+// stored along with it
+func (b *Bucket) Head(ctx context.Context, key string) (http.Header, error) {
+	req, err := b.newRequest(ctx, http.MethodHead, key, nil, http.Header{
+		"X-Amz-Checksum-Mode": {"ENABLED"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := do(req, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp.Header, nil
+}
+
+// Get returns the response to a request of the given object. The given
+// headers (i.e. Range or the conditional headers) are passed along, the
+// partial and the not modified responses being returned as well.
+func (b *Bucket) Get(ctx context.Context, key string, header http.Header) (*http.Response, error) {
+	req, err := b.newRequest(ctx, http.MethodGet, key, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	return do(req, http.StatusOK, http.StatusPartialContent, http.StatusNotModified,
+		http.StatusPreconditionFailed, http.StatusRequestedRangeNotSatisfiable)
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package objectstorage
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var (
+	md5Hex = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+// Checksums returns the hex encoded checksums stored along with an object:
+// the checksums computed by the object storage (x-amz-checksum-*), those
+// set by the uploader as metadata (x-amz-meta-sha256, x-amz-meta-sha1 and
+// x-amz-meta-md5) and the ETag which is the MD5 of the objects uploaded in
+// a single part without encryption by KMS. The header can be nil.
+func Checksums(obj Object, header http.Header) (sha1, sha256, md5 string) {
+	checksum := func(name string) string {
+		if header == nil {
+			return ""
+		}
+		// The checksums of the multipart uploads are checksums of checksums
+		if v := header.Get("X-Amz-Checksum-" + name); v != "" && !strings.Contains(v, "-") {
+			if b, err := base64.StdEncoding.DecodeString(v); err == nil {
+				return hex.EncodeToString(b)
+			}
+		}
+		return strings.ToLower(header.Get("X-Amz-Meta-" + name))
+	}
+
+	sha1 = checksum("Sha1")
+	sha256 = checksum("Sha256")
+	md5 = checksum("Md5")
+
+	etag := strings.ToLower(strings.Trim(obj.ETag, `"`))
+	if md5 == "" && md5Hex.MatchString(etag) && (header == nil || header.Get("X-Amz-Server-Side-Encryption") != "aws:kms") {
+		md5 = etag
+	}
+	return
+}
@@ -4,18 +4,17 @@
 package process
 
 import (
-	"errors"
 	"fmt"
 	"io/ioutil"
-	"net"
 	"os"
-	"os/exec"
-	"path"
+	"path/filepath"
 	"strconv"
-	"syscall"
+	"strings"
 
+	. "github.com/etix/mirrorbits/config"
 	"github.com/etix/mirrorbits/core"
 	"github.com/op/go-logging"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -26,153 +25,60 @@ var (
 var (
 	// ErrInvalidfd is returned when the given file descriptor is invalid
 	ErrInvalidfd = errors.New("invalid file descriptor")
+	// ErrUnsupported is returned when the operation is not available on
+	// this platform
+	ErrUnsupported = errors.New("not supported on this platform")
+	// ErrAlreadyRunning is returned when another daemon uses the same
+	// configuration file
+	ErrAlreadyRunning = errors.New("another instance is running with this configuration")
+
+	// The file locked by LockInstance
+	instanceLock *os.File
 
 	log = logging.MustGetLogger("main")
 )
 
-// Relaunch launches {self} as a child process passing listener details
-// to provide a seamless binary upgrade.
-func Relaunch(l net.Listener) error {
-	argv0, err := exec.LookPath(os.Args[0])
-	if err != nil {
-		return err
-	}
-	if _, err := os.Stat(argv0); err != nil {
-		return err
-	}
-	wd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
-
-	var file *os.File
-
-	switch t := l.(type) {
-	case *net.TCPListener:
-		file, err = t.File()
-	case *net.UnixListener:
-		file, err = t.File()
-	default:
-		return ErrInvalidfd
-	}
-	if err != nil {
-		return err
-	}
-
-	fd := file.Fd()
-	sysfile := file.Name()
-
-	listener, ok := l.(*net.TCPListener)
-	if ok {
-		listenerFile, err := listener.File()
-		if err != nil {
-			return err
-		}
-		fd = listenerFile.Fd()
-		sysfile = listenerFile.Name()
-	}
-
-	if fd < uintptr(syscall.Stderr) {
-		return ErrInvalidfd
-	}
-
-	if err := os.Setenv("OLD_FD", fmt.Sprint(fd)); err != nil {
-		return err
-	}
-	if err := os.Setenv("OLD_NAME", fmt.Sprintf("tcp:%s->", l.Addr().String())); err != nil {
-		return err
-	}
-	if err := os.Setenv("OLD_PPID", fmt.Sprint(syscall.Getpid())); err != nil {
-		return err
-	}
-
-	files := make([]*os.File, fd+1)
-	files[syscall.Stdin] = os.Stdin
-	files[syscall.Stdout] = os.Stdout
-	files[syscall.Stderr] = os.Stderr
-	files[fd] = os.NewFile(fd, sysfile)
-	p, err := os.StartProcess(argv0, os.Args, &os.ProcAttr{
-		Dir:   wd,
-		Env:   os.Environ(),
-		Files: files,
-		Sys:   &syscall.SysProcAttr{},
-	})
-	if err != nil {
-		return err
-	}
-	log.Infof("Spawned child %d\n", p.Pid)
-	return nil
-}
-
-// Recover from a seamless binary upgrade and use an already
-// existing listener to take over the connections
-func Recover() (l net.Listener, ppid int, err error) {
-	var fd uintptr
-	_, err = fmt.Sscan(os.Getenv("OLD_FD"), &fd)
-	if err != nil {
-		return
-	}
-	var i net.Listener
-	i, err = net.FileListener(os.NewFile(fd, os.Getenv("OLD_NAME")))
-	if err != nil {
-		return
-	}
-	switch i.(type) {
-	case *net.TCPListener:
-		l = i.(*net.TCPListener)
-	case *net.UnixListener:
-		l = i.(*net.UnixListener)
-	default:
-		err = fmt.Errorf("file descriptor is %T not *net.TCPListener or *net.UnixListener", i)
-		return
-	}
-	if err = syscall.Close(int(fd)); err != nil {
-		return
-	}
-	_, err = fmt.Sscan(os.Getenv("OLD_PPID"), &ppid)
-	if err != nil {
-		return
-	}
-	return
-}
-
-// KillParent sends a signal to make the parent exit gracefully with SIGQUIT
-func KillParent(ppid int) error {
-	log.Info("Asking parent to quit")
-	return syscall.Kill(ppid, syscall.SIGQUIT)
-}
-
 // GetPidLocation finds the location to store our pid file
-// and fallback to /var/run if none found
+// and fallback to the run directory of the system if none found
 func GetPidLocation() string {
-	if core.PidFile == "" { // Runtime
-		rdir := os.Getenv("XDG_RUNTIME_DIR")
-		if rdir == "" {
-			if defaultPidFile == "" { // Compile time
-				return "/run/mirrorbits/mirrorbits.pid" // Fallback
-			}
-			return defaultPidFile
+	if core.PidFile != "" { // Runtime
+		return core.PidFile
+	}
+	if GetConfig().PidFile != "" {
+		return GetConfig().PidFile
+	}
+	rdir := os.Getenv("XDG_RUNTIME_DIR")
+	if rdir == "" {
+		if defaultPidFile == "" { // Compile time
+			return fallbackPidFile()
 		}
-		return rdir + "/mirrorbits.pid"
+		return defaultPidFile
 	}
-	return core.PidFile
+	return filepath.Join(rdir, "mirrorbits.pid")
 }
 
-// WritePidFile writes the current pid file to disk
-func WritePidFile() {
+// WritePidFile writes the current pid file to disk, unless it belongs to
+// another running process
+func WritePidFile() error {
 	// Get the pid destination
 	p := GetPidLocation()
 
+	// The parent replaced during a seamless binary upgrade still runs
+	if pid := GetRemoteProcPid(); pid > 0 && pid != os.Getpid() && strconv.Itoa(pid) != os.Getenv("OLD_PPID") {
+		return fmt.Errorf("pid file %s belongs to the running process %d", p, pid)
+	}
+
 	// Create the whole directory path
-	if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
-		log.Errorf("Unable to write pid file: %v", err)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return errors.Wrap(err, "unable to write pid file")
 	}
 
 	// Get our own PID and write it
 	pid := strconv.Itoa(os.Getpid())
-	if err := ioutil.WriteFile(p, []byte(pid), 0644); err != nil {
-		log.Errorf("Unable to write pid file: %v", err)
+	if err := ioutil.WriteFile(p, []byte(pid+"\n"), 0644); err != nil {
+		return errors.Wrap(err, "unable to write pid file")
 	}
+	return nil
 }
 
 // RemovePidFile removes the current pid file
@@ -189,15 +95,39 @@ func RemovePidFile() {
 	}
 }
 
-// GetRemoteProcPid gets the pid as it appears in the pid file (maybe not ours)
+// GetRemoteProcPid gets the pid as it appears in the pid file (maybe not
+// ours), -1 if the file is missing or stale
 func GetRemoteProcPid() int {
 	b, err := ioutil.ReadFile(GetPidLocation())
 	if err != nil {
 		return -1
 	}
-	i, err := strconv.ParseInt(string(b), 10, 0)
-	if err != nil {
+	i, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 0)
+	if err != nil || i <= 0 {
+		return -1
+	}
+	if !processAlive(int(i)) {
+		// Left behind by a process that did not exit cleanly
 		return -1
 	}
 	return int(i)
 }
+
+// LockInstance ensures a single daemon runs per configuration file by
+// holding an exclusive lock on it until the process exits
+func LockInstance(configFile string) error {
+	if f := inheritedLock(); f != nil {
+		instanceLock = f
+		return nil
+	}
+	f, err := os.Open(configFile)
+	if err != nil {
+		return err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return err
+	}
+	instanceLock = f
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package process
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	. "github.com/etix/mirrorbits/config"
+)
+
+func TestGetRemoteProcPid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mirrorbits-process")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pidFile := filepath.Join(dir, "mirrorbits.pid")
+	SetConfiguration(&Configuration{
+		PidFile: pidFile,
+	})
+
+	if pid := GetRemoteProcPid(); pid != -1 {
+		t.Fatalf("Expected -1 without pid file, got %d", pid)
+	}
+
+	if err := WritePidFile(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pid := GetRemoteProcPid(); pid != os.Getpid() {
+		t.Fatalf("Expected our pid %d, got %d", os.Getpid(), pid)
+	}
+
+	// A pid file left behind by a process that no longer exists
+	if err := ioutil.WriteFile(pidFile, []byte("2147483646\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if pid := GetRemoteProcPid(); pid != -1 {
+		t.Fatalf("Expected a stale pid file, got %d", pid)
+	}
+	if err := WritePidFile(); err != nil {
+		t.Fatalf("Expected the stale pid file to be replaced: %v", err)
+	}
+
+	// A pid file belonging to another running process
+	if err := ioutil.WriteFile(pidFile, []byte(strconv.Itoa(os.Getppid())), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WritePidFile(); err == nil {
+		t.Fatalf("Expected an error, the pid file belongs to the parent process")
+	}
+
+	RemovePidFile()
+	if _, err := os.Stat(pidFile); err != nil {
+		t.Fatalf("The pid file of another process must not be removed")
+	}
+}
+
+func TestLockInstance(t *testing.T) {
+	f, err := ioutil.TempFile("", "mirrorbits-conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	if err := LockInstance(f.Name()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lock := instanceLock
+	defer lock.Close()
+
+	if err := LockInstance(f.Name()); err != ErrAlreadyRunning {
+		t.Fatalf("Expected ErrAlreadyRunning, got %v", err)
+	}
+}
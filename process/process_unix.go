@@ -0,0 +1,201 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+//go:build !windows
+// +build !windows
+
+package process
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"syscall"
+)
+
+// The signals controlling the daemon, they can also be sent by the CLI
+// through the RPC
+const (
+	// SigStop stops the server gracefully
+	SigStop = syscall.SIGQUIT
+	// SigReload reloads the configuration
+	SigReload = syscall.SIGHUP
+	// SigReopenLogs re-opens the log files
+	SigReopenLogs = syscall.SIGUSR1
+	// SigUpgrade triggers a seamless binary upgrade
+	SigUpgrade = syscall.SIGUSR2
+)
+
+// SupportsUpgrade is true when the seamless binary upgrade is available
+const SupportsUpgrade = true
+
+// Notify relays the signals controlling the daemon to c
+func Notify(c chan<- os.Signal) {
+	signal.Notify(c,
+		syscall.SIGINT,  // Terminate
+		syscall.SIGTERM, // Terminate
+		SigStop,
+		SigReload,
+		SigReopenLogs,
+		SigUpgrade,
+	)
+}
+
+// processAlive returns true if the given process exists
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// lockFile takes an exclusive lock on f without waiting
+func lockFile(f *os.File) error {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return ErrAlreadyRunning
+	}
+	return err
+}
+
+// inheritedLock returns the lock of the configuration file passed by the
+// parent during a seamless binary upgrade
+func inheritedLock() *os.File {
+	if os.Getenv("OLD_PPID") == "" {
+		return nil
+	}
+	var fd uintptr
+	if _, err := fmt.Sscan(os.Getenv("OLD_LOCK_FD"), &fd); err != nil {
+		return nil
+	}
+	return os.NewFile(fd, "lock")
+}
+
+func fallbackPidFile() string {
+	if runtime.GOOS == "linux" {
+		return "/run/mirrorbits/mirrorbits.pid"
+	}
+	return "/var/run/mirrorbits/mirrorbits.pid"
+}
+
+// Relaunch launches {self} as a child process passing listener details
+// to provide a seamless binary upgrade.
+func Relaunch(l net.Listener) error {
+	argv0, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(argv0); err != nil {
+		return err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	var file *os.File
+
+	switch t := l.(type) {
+	case *net.TCPListener:
+		file, err = t.File()
+	case *net.UnixListener:
+		file, err = t.File()
+	default:
+		return ErrInvalidfd
+	}
+	if err != nil {
+		return err
+	}
+
+	fd := file.Fd()
+	sysfile := file.Name()
+
+	listener, ok := l.(*net.TCPListener)
+	if ok {
+		listenerFile, err := listener.File()
+		if err != nil {
+			return err
+		}
+		fd = listenerFile.Fd()
+		sysfile = listenerFile.Name()
+	}
+
+	if fd < uintptr(syscall.Stderr) {
+		return ErrInvalidfd
+	}
+
+	if err := os.Setenv("OLD_FD", fmt.Sprint(fd)); err != nil {
+		return err
+	}
+	if err := os.Setenv("OLD_NAME", fmt.Sprintf("tcp:%s->", l.Addr().String())); err != nil {
+		return err
+	}
+	if err := os.Setenv("OLD_PPID", fmt.Sprint(syscall.Getpid())); err != nil {
+		return err
+	}
+
+	files := make([]*os.File, fd+1)
+	files[syscall.Stdin] = os.Stdin
+	files[syscall.Stdout] = os.Stdout
+	files[syscall.Stderr] = os.Stderr
+	files[fd] = os.NewFile(fd, sysfile)
+	if instanceLock != nil {
+		// The child inherits the lock of the configuration file
+		if err := os.Setenv("OLD_LOCK_FD", fmt.Sprint(len(files))); err != nil {
+			return err
+		}
+		files = append(files, instanceLock)
+	} else if err := os.Unsetenv("OLD_LOCK_FD"); err != nil {
+		return err
+	}
+	p, err := os.StartProcess(argv0, os.Args, &os.ProcAttr{
+		Dir:   wd,
+		Env:   os.Environ(),
+		Files: files,
+		Sys:   &syscall.SysProcAttr{},
+	})
+	if err != nil {
+		return err
+	}
+	log.Infof("Spawned child %d\n", p.Pid)
+	return nil
+}
+
+// Recover from a seamless binary upgrade and use an already
+// existing listener to take over the connections
+func Recover() (l net.Listener, ppid int, err error) {
+	var fd uintptr
+	_, err = fmt.Sscan(os.Getenv("OLD_FD"), &fd)
+	if err != nil {
+		return
+	}
+	var i net.Listener
+	i, err = net.FileListener(os.NewFile(fd, os.Getenv("OLD_NAME")))
+	if err != nil {
+		return
+	}
+	switch i.(type) {
+	case *net.TCPListener:
+		l = i.(*net.TCPListener)
+	case *net.UnixListener:
+		l = i.(*net.UnixListener)
+	default:
+		err = fmt.Errorf("file descriptor is %T not *net.TCPListener or *net.UnixListener", i)
+		return
+	}
+	if err = syscall.Close(int(fd)); err != nil {
+		return
+	}
+	_, err = fmt.Sscan(os.Getenv("OLD_PPID"), &ppid)
+	if err != nil {
+		return
+	}
+	return
+}
+
+// KillParent sends a signal to make the parent exit gracefully with SIGQUIT
+func KillParent(ppid int) error {
+	log.Info("Asking parent to quit")
+	return syscall.Kill(ppid, syscall.SIGQUIT)
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package process
+
+import (
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+// controlSignal is a signal that Windows cannot deliver to a process, it is
+// only sent by the CLI through the RPC
+type controlSignal string
+
+func (s controlSignal) String() string { return string(s) }
+func (s controlSignal) Signal()        {}
+
+// The signals controlling the daemon, they are sent by the CLI through the
+// RPC
+const (
+	// SigStop stops the server gracefully
+	SigStop = controlSignal("stop")
+	// SigReload reloads the configuration
+	SigReload = controlSignal("reload")
+	// SigReopenLogs re-opens the log files
+	SigReopenLogs = controlSignal("reopen logs")
+	// SigUpgrade triggers a seamless binary upgrade
+	SigUpgrade = controlSignal("upgrade")
+)
+
+// SupportsUpgrade is true when the seamless binary upgrade is available,
+// Windows cannot pass the listening socket to a new process
+const SupportsUpgrade = false
+
+// Notify relays the signals controlling the daemon to c, the console events
+// (Ctrl+C, closing the console, logoff and shutdown) terminate it
+func Notify(c chan<- os.Signal) {
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+}
+
+var procLockFileEx = syscall.NewLazyDLL("kernel32.dll").NewProc("LockFileEx")
+
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+	errorLockViolation      = syscall.Errno(33)
+)
+
+// processAlive returns true if the given process exists
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// lockFile takes an exclusive lock on f without waiting, the locked byte
+// lies far beyond the end of the file so others can still read it
+func lockFile(f *os.File) error {
+	ol := &syscall.Overlapped{OffsetHigh: 0x7fffffff}
+	r, _, err := procLockFileEx.Call(f.Fd(), lockfileExclusiveLock|lockfileFailImmediately, 0, 1, 0, uintptr(unsafe.Pointer(ol)))
+	if r == 0 {
+		if err == errorLockViolation {
+			return ErrAlreadyRunning
+		}
+		return err
+	}
+	return nil
+}
+
+// inheritedLock returns nil, there is no seamless binary upgrade on Windows
+func inheritedLock() *os.File {
+	return nil
+}
+
+func fallbackPidFile() string {
+	return filepath.Join(os.TempDir(), "mirrorbits", "mirrorbits.pid")
+}
+
+// Relaunch is not supported on Windows
+func Relaunch(l net.Listener) error {
+	return ErrUnsupported
+}
+
+// Recover is not supported on Windows
+func Recover() (l net.Listener, ppid int, err error) {
+	return nil, 0, ErrUnsupported
+}
+
+// KillParent is not supported on Windows
+func KillParent(ppid int) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package rpc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/etix/mirrorbits/database"
+	"github.com/etix/mirrorbits/mirrors"
+	"github.com/etix/mirrorbits/scan"
+	"github.com/op/go-logging"
+	"github.com/pkg/errors"
+	context "golang.org/x/net/context"
+	"gopkg.in/yaml.v2"
+)
+
+// logger is the logger of the daemon, the log package of the standard
+// library is used by the rest of the rpc package
+var logger = logging.MustGetLogger("main")
+
+// seedMirror is a mirror of the seed file, the seeded mirrors are enabled
+// unless stated otherwise
+type seedMirror struct {
+	mirrors.Mirror `yaml:",inline"`
+}
+
+func (s *seedMirror) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	s.Enabled = true
+	return unmarshal(&s.Mirror)
+}
+
+// ParseSeedFile returns the mirrors of a seed file, a yaml list of mirrors
+// using the fields of the edit command
+func ParseSeedFile(data []byte) ([]*mirrors.Mirror, error) {
+	var seed []seedMirror
+	if err := yaml.UnmarshalStrict(data, &seed); err != nil {
+		return nil, err
+	}
+
+	list := make([]*mirrors.Mirror, 0, len(seed))
+	names := make(map[string]bool)
+	for i := range seed {
+		m := &seed[i].Mirror
+		if m.Name == "" || strings.Contains(m.Name, " ") {
+			return nil, fmt.Errorf("mirror #%d: invalid name '%s'", i+1, m.Name)
+		}
+		if names[m.Name] {
+			return nil, fmt.Errorf("mirror %s: duplicate name", m.Name)
+		}
+		names[m.Name] = true
+		if m.HttpURL == "" {
+			return nil, fmt.Errorf("mirror %s: HttpURL is required", m.Name)
+		}
+		if !strings.HasPrefix(m.HttpURL, "http://") && !strings.HasPrefix(m.HttpURL, "https://") {
+			m.HttpURL = "http://" + m.HttpURL
+		}
+		// The location given explicitly is never overwritten by the GeoIP database
+		if m.Latitude != 0 || m.Longitude != 0 || len(m.CountryCodes) > 0 || m.ContinentCode != "" || m.RegionCode != "" {
+			m.ManualLocation = true
+		}
+		list = append(list, m)
+	}
+	return list, nil
+}
+
+// Bootstrap adds the mirrors of the seed file when the database holds no
+// mirror yet, so that a new instance comes up functional without manual
+// steps. The local repository is indexed as well if refresh is true,
+// otherwise the monitor takes care of it along with the scans of the mirrors.
+func (c *CLI) Bootstrap(seedFile string, refresh bool) error {
+	data, err := ioutil.ReadFile(seedFile)
+	if err != nil {
+		return err
+	}
+	seed, err := ParseSeedFile(data)
+	if err != nil {
+		return errors.Wrap(err, seedFile)
+	}
+
+	// Wait until the database is ready to be used
+	for {
+		r := c.redis.Get()
+		err := r.Err()
+		r.Close()
+		if _, ok := err.(database.NetReadyError); ok {
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		break
+	}
+
+	ids, err := c.redis.GetListOfMirrors()
+	if err != nil {
+		return errors.Wrap(err, "can't fetch the list of mirrors")
+	}
+	if len(ids) > 0 {
+		logger.Noticef("Bootstrap: the database already holds %d mirrors, skipping %s", len(ids), seedFile)
+		return nil
+	}
+
+	ctx := context.Background()
+	added := 0
+	for _, m := range seed {
+		in, err := MirrorToRPC(m)
+		if err != nil {
+			return errors.Wrap(err, m.Name)
+		}
+		reply, err := c.AddMirror(ctx, in)
+		if err != nil {
+			// The other mirrors are still usable
+			logger.Errorf("Bootstrap: unable to add mirror %s: %s", m.Name, err)
+			continue
+		}
+		for _, w := range reply.Warnings {
+			logger.Warningf("Bootstrap: mirror %s: %s", m.Name, w)
+		}
+		added++
+	}
+	logger.Noticef("Bootstrap: %d mirrors loaded from %s", added, seedFile)
+
+	if refresh {
+		return scan.ScanSource(ctx, c.redis, false)
+	}
+	return nil
+}
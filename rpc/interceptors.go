@@ -5,8 +5,11 @@ package rpc
 
 import (
 	"context"
+	"net"
 
 	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/database"
+	"github.com/pkg/errors"
 	grpc "google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -18,7 +21,7 @@ func StreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.Str
 		return err
 	}
 
-	return handler(srv, stream)
+	return translateError(handler(srv, stream))
 }
 
 func UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
@@ -26,7 +29,29 @@ func UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServ
 		return nil, err
 	}
 
-	return handler(ctx, req)
+	resp, err := handler(ctx, req)
+	return resp, translateError(err)
+}
+
+// translateError gives a status code to the errors wrapping a status or
+// caused by an unreachable database so the clients can tell them apart
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	cause := errors.Cause(err)
+	if s, ok := status.FromError(cause); ok {
+		return status.Error(s.Code(), err.Error())
+	}
+	if _, ok := cause.(net.Error); ok || cause == database.ErrUnreachable {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	return err
 }
 
 func authorize(ctx context.Context) error {
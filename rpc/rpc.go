@@ -9,18 +9,21 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"path"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
-	"syscall"
+	"time"
 
 	. "github.com/etix/mirrorbits/config"
 	"github.com/etix/mirrorbits/core"
 	"github.com/etix/mirrorbits/database"
 	"github.com/etix/mirrorbits/mirrors"
 	"github.com/etix/mirrorbits/network"
+	"github.com/etix/mirrorbits/process"
 	"github.com/etix/mirrorbits/scan"
 	"github.com/etix/mirrorbits/utils"
 	"github.com/golang/protobuf/ptypes"
@@ -47,10 +50,23 @@ type CLI struct {
 	sig      chan<- os.Signal
 	redis    *database.Redis
 	cache    *mirrors.Cache
+	monitor  Monitor
+	started  time.Time
+
+	// Queue of the scans when the monitor is disabled
+	scansOnce sync.Once
+	scans     *scan.Queue
+}
+
+// Monitor is implemented by the mirrors monitor to report its activity
+type Monitor interface {
+	QueueStatus() (checks, scans, pendingScans int)
+	ScanQueue() *scan.Queue
 }
 
 func (c *CLI) Start() error {
 	var err error
+	c.started = time.Now()
 	c.listener, err = net.Listen("tcp", GetConfig().RPCListenAddress)
 	if err != nil {
 		return err
@@ -86,39 +102,224 @@ func (c *CLI) SetCache(cache *mirrors.Cache) {
 	c.cache = cache
 }
 
+func (c *CLI) SetMonitor(monitor Monitor) {
+	c.monitor = monitor
+}
+
 func (c *CLI) Ping(context.Context, *empty.Empty) (*empty.Empty, error) {
 	return &empty.Empty{}, nil
 }
 
 func (c *CLI) GetVersion(context.Context, *empty.Empty) (*VersionReply, error) {
-	return &VersionReply{
+	reply := &VersionReply{
 		Version:    core.VERSION,
 		Build:      core.BUILD + core.DEV,
+		Commit:     core.COMMIT,
+		BuildDate:  core.BUILD_DATE,
 		GoVersion:  runtime.Version(),
 		OS:         runtime.GOOS,
 		Arch:       runtime.GOARCH,
 		GoMaxProcs: int32(runtime.GOMAXPROCS(0)),
-	}, nil
+		ConfigFile: core.ConfigFile,
+	}
+
+	version, err := c.redis.ServerVersion()
+	if err != nil {
+		reply.DatabaseError = err.Error()
+	} else {
+		reply.RedisVersion = version
+
+		conn := c.redis.UnblockedGet()
+		defer conn.Close()
+		dbVersion, _ := redis.Int(conn.Do("GET", core.DBVersionKey))
+		reply.DBVersion = int32(dbVersion)
+	}
+
+	// The databases that could not be loaded are left out
+	geo := network.NewGeoIP()
+	geo.LoadGeoIP()
+	for _, db := range geo.Databases() {
+		buildDate, _ := ptypes.TimestampProto(db.BuildDate)
+		reply.GeoIPDatabases = append(reply.GeoIPDatabases, &GeoIPDatabase{
+			Filename:  db.Filename,
+			BuildDate: buildDate,
+		})
+	}
+
+	return reply, nil
 }
 
 func (c *CLI) Upgrade(ctx context.Context, in *empty.Empty) (*empty.Empty, error) {
-	select {
-	case c.sig <- syscall.SIGUSR2:
-	default:
-		return nil, status.Error(codes.Internal, "signal handler not ready")
+	if !process.SupportsUpgrade {
+		return nil, status.Error(codes.Unimplemented, "seamless binary upgrade not supported on this platform")
 	}
-	return &empty.Empty{}, nil
+	return c.signal(process.SigUpgrade)
 }
 
 func (c *CLI) Reload(ctx context.Context, in *empty.Empty) (*empty.Empty, error) {
+	return c.signal(process.SigReload)
+}
+
+func (c *CLI) ReopenLogs(ctx context.Context, in *empty.Empty) (*empty.Empty, error) {
+	return c.signal(process.SigReopenLogs)
+}
+
+func (c *CLI) Stop(ctx context.Context, in *empty.Empty) (*empty.Empty, error) {
+	return c.signal(process.SigStop)
+}
+
+// signal hands the given signal to the signal handler of the daemon
+func (c *CLI) signal(sig os.Signal) (*empty.Empty, error) {
 	select {
-	case c.sig <- syscall.SIGHUP:
+	case c.sig <- sig:
 	default:
 		return nil, status.Error(codes.Internal, "signal handler not ready")
 	}
 	return &empty.Empty{}, nil
 }
 
+func (c *CLI) Status(ctx context.Context, in *empty.Empty) (*StatusReply, error) {
+	reply := &StatusReply{
+		Version:          core.VERSION,
+		ConfigFile:       core.ConfigFile,
+		ListenAddress:    GetConfig().ListenAddress,
+		RPCListenAddress: GetConfig().RPCListenAddress,
+		Monitor:          c.monitor != nil,
+	}
+	reply.Started, _ = ptypes.TimestampProto(c.started)
+
+	for _, p := range core.WorkerPools() {
+		reply.WorkerPools = append(reply.WorkerPools, &WorkerPool{
+			Name:      p.Name,
+			Workers:   int32(p.Workers),
+			Busy:      int32(p.Busy),
+			Queued:    int32(p.Queued),
+			QueueSize: int32(p.QueueSize),
+		})
+	}
+
+	if c.monitor != nil {
+		checks, scans, pendingScans := c.monitor.QueueStatus()
+		reply.HealthChecks = int32(checks)
+		reply.Scans = int32(scans)
+		reply.PendingScans = int32(pendingScans)
+	}
+
+	for _, s := range c.scanQueue().Contents() {
+		queued := &QueuedScan{
+			MirrorID:   int32(s.MirrorID),
+			MirrorName: s.MirrorName,
+			Source:     s.Source,
+			Force:      s.Force,
+		}
+		queued.Queued, _ = ptypes.TimestampProto(s.Queued)
+		if !s.Started.IsZero() {
+			queued.Started, _ = ptypes.TimestampProto(s.Started)
+		}
+		reply.ScanQueue = append(reply.ScanQueue, queued)
+	}
+
+	conn, err := c.redis.Connect()
+	if err == nil {
+		defer conn.Close()
+		_, err = conn.Do("PING")
+	}
+	if err != nil {
+		reply.DatabaseError = err.Error()
+		return reply, nil
+	}
+	reply.DatabaseReachable = true
+	reply.Degraded = c.redis.Degraded()
+	reply.PendingWrites = int32(c.redis.PendingWrites())
+
+	if lastScan, err := redis.Int64(conn.Do("GET", "LAST_SOURCE_SCAN")); err == nil {
+		reply.LastRepositoryScan, _ = ptypes.TimestampProto(time.Unix(lastScan, 0))
+	}
+
+	paused, err := c.redis.GetPaused()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't fetch the paused activities")
+	}
+	if since, ok := paused[database.PauseScanning]; ok {
+		reply.ScanningPaused, _ = ptypes.TimestampProto(since)
+	}
+	if since, ok := paused[database.PauseMonitoring]; ok {
+		reply.MonitoringPaused, _ = ptypes.TimestampProto(since)
+	}
+
+	mirrorsIDs, err := c.redis.GetListOfMirrors()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't fetch the list of mirrors")
+	}
+	for id := range mirrorsIDs {
+		mirror, err := c.cache.GetMirror(id)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't fetch the mirror")
+		}
+		switch {
+		case !mirror.Enabled:
+			reply.MirrorsDisabled++
+		case mirror.Up:
+			reply.MirrorsUp++
+		default:
+			reply.MirrorsDown++
+		}
+		if !mirror.QuarantinedSince.IsZero() {
+			quarantined := &QuarantinedMirror{
+				MirrorID:   int32(mirror.ID),
+				MirrorName: mirror.Name,
+				Files:      mirror.QuarantinedFiles,
+				Previous:   mirror.QuarantinedPrevious,
+			}
+			quarantined.Since, _ = ptypes.TimestampProto(mirror.QuarantinedSince.Time)
+			reply.Quarantined = append(reply.Quarantined, quarantined)
+		}
+	}
+	sort.Slice(reply.Quarantined, func(i, j int) bool {
+		return reply.Quarantined[i].MirrorID < reply.Quarantined[j].MirrorID
+	})
+
+	return reply, nil
+}
+
+// pauseActivities returns the background activities designated by the
+// request
+func pauseActivities(in *PauseRequest) ([]string, error) {
+	switch in.Activity {
+	case "", "all":
+		return database.PauseActivities, nil
+	case database.PauseScanning, database.PauseMonitoring:
+		return []string{in.Activity}, nil
+	}
+	return nil, status.Errorf(codes.InvalidArgument, "unknown activity %s", in.Activity)
+}
+
+func (c *CLI) Pause(ctx context.Context, in *PauseRequest) (*empty.Empty, error) {
+	activities, err := pauseActivities(in)
+	if err != nil {
+		return nil, err
+	}
+	for _, activity := range activities {
+		if err := c.redis.SetPaused(activity, true); err != nil {
+			return nil, errors.Wrap(err, "can't pause the "+activity)
+		}
+	}
+	return &empty.Empty{}, nil
+}
+
+func (c *CLI) Resume(ctx context.Context, in *PauseRequest) (*empty.Empty, error) {
+	activities, err := pauseActivities(in)
+	if err != nil {
+		return nil, err
+	}
+	for _, activity := range activities {
+		if err := c.redis.SetPaused(activity, false); err != nil {
+			return nil, errors.Wrap(err, "can't resume the "+activity)
+		}
+	}
+	return &empty.Empty{}, nil
+}
+
 func (c *CLI) MatchMirror(ctx context.Context, in *MatchRequest) (*MatchReply, error) {
 	if c.redis == nil {
 		return nil, status.Error(codes.Internal, "database not ready")
@@ -131,8 +332,29 @@ func (c *CLI) MatchMirror(ctx context.Context, in *MatchRequest) (*MatchReply, e
 
 	reply := &MatchReply{}
 
+	pattern := strings.ToLower(in.Pattern)
+	glob := strings.ContainsAny(pattern, "*?[")
+
 	for id, name := range mirrors {
-		if strings.Contains(strings.ToLower(name), strings.ToLower(in.Pattern)) {
+		lname := strings.ToLower(name)
+		if pattern != "" && lname == pattern {
+			// An exact match always wins
+			return &MatchReply{
+				Mirrors: []*MirrorID{{ID: int32(id), Name: name}},
+			}, nil
+		}
+
+		var match bool
+		if glob {
+			match, err = path.Match(pattern, lname)
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, "invalid pattern: "+err.Error())
+			}
+		} else {
+			match = strings.Contains(lname, pattern)
+		}
+
+		if match {
 			reply.Mirrors = append(reply.Mirrors, &MirrorID{
 				ID:   int32(id),
 				Name: name,
@@ -143,6 +365,375 @@ func (c *CLI) MatchMirror(ctx context.Context, in *MatchRequest) (*MatchReply, e
 	return reply, nil
 }
 
+// ListFiles returns the files of the repository starting with the given
+// prefix. Only the next path component is returned for the files located
+// in subdirectories, their name ending with a slash.
+func (c *CLI) ListFiles(ctx context.Context, in *ListFilesRequest) (*ListFilesReply, error) {
+	if c.redis == nil {
+		return nil, status.Error(codes.Internal, "database not ready")
+	}
+
+	conn, err := c.redis.Connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	files, err := redis.Strings(conn.Do("SMEMBERS", "FILES"))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't fetch the list of files")
+	}
+
+	prefix := in.Prefix
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+
+	seen := make(map[string]bool)
+	reply := &ListFilesReply{}
+
+	for _, file := range files {
+		if !strings.HasPrefix(file, prefix) {
+			continue
+		}
+		if i := strings.Index(file[len(prefix):], "/"); i >= 0 {
+			file = file[:len(prefix)+i+1]
+		}
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+		reply.Files = append(reply.Files, file)
+	}
+
+	sort.Strings(reply.Files)
+
+	return reply, nil
+}
+
+func (c *CLI) ListPendingMirrors(ctx context.Context, in *empty.Empty) (*PendingMirrorsReply, error) {
+	pending, err := mirrors.GetPendingMirrors(c.redis)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't fetch the pending mirrors")
+	}
+
+	reply := &PendingMirrorsReply{}
+	for _, p := range pending {
+		submitted, err := ptypes.TimestampProto(p.Submitted)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't fetch the pending mirrors")
+		}
+		reply.Mirrors = append(reply.Mirrors, &PendingMirror{
+			ID:          int32(p.ID),
+			Name:        p.Name,
+			HttpURL:     p.HttpURL,
+			RsyncURL:    p.RsyncURL,
+			FtpURL:      p.FtpURL,
+			SponsorName: p.SponsorName,
+			SponsorURL:  p.SponsorURL,
+			AdminName:   p.AdminName,
+			AdminEmail:  p.AdminEmail,
+			Comment:     p.Comment,
+			RemoteIP:    p.RemoteIP,
+			Submitted:   submitted,
+		})
+	}
+
+	return reply, nil
+}
+
+func (c *CLI) RemovePendingMirror(ctx context.Context, in *PendingMirrorRequest) (*empty.Empty, error) {
+	err := mirrors.RemovePendingMirror(c.redis, int(in.ID))
+	if err == mirrors.ErrPendingNotFound {
+		return nil, status.Error(codes.NotFound, err.Error())
+	} else if err != nil {
+		return nil, errors.Wrap(err, "can't remove the pending mirror")
+	}
+	return &empty.Empty{}, nil
+}
+
+func (c *CLI) VerifyContact(ctx context.Context, in *VerifyContactRequest) (*empty.Empty, error) {
+	mirror, err := c.cache.GetMirror(int(in.ID))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't fetch the mirror")
+	}
+
+	if in.Bounced {
+		if mirror.AdminEmail == "" {
+			return nil, status.Error(codes.FailedPrecondition, mirrors.ErrNoContact.Error())
+		}
+		err = mirrors.SetContactStatus(c.redis, mirror.ID, mirror.AdminEmail, mirrors.ContactBounced)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't update the contact")
+		}
+		return &empty.Empty{}, nil
+	}
+
+	err = mirrors.SendContactVerification(c.redis, &mirror)
+	if err == mirrors.ErrContactVerificationDisabled || err == mirrors.ErrNoContact {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	} else if err != nil {
+		return nil, errors.Wrap(err, "can't send the verification email")
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (c *CLI) GetContactStatuses(ctx context.Context, in *empty.Empty) (*ContactStatusesReply, error) {
+	mirrorsIDs, err := c.redis.GetListOfMirrors()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't fetch the list of mirrors")
+	}
+
+	reply := &ContactStatusesReply{}
+	for id := range mirrorsIDs {
+		mirror, err := c.cache.GetMirror(id)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't fetch the mirror")
+		}
+		v, err := mirrors.GetContactVerification(c.redis, id, mirror.AdminEmail)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't fetch the contact status")
+		}
+		s := &ContactStatus{
+			ID:     int32(id),
+			Email:  v.Email,
+			Status: v.Status,
+		}
+		if !v.Since.IsZero() {
+			s.Since, _ = ptypes.TimestampProto(v.Since)
+		}
+		reply.Statuses = append(reply.Statuses, s)
+	}
+
+	return reply, nil
+}
+
+func (c *CLI) GetHTTPErrors(ctx context.Context, in *empty.Empty) (*HTTPErrorsReply, error) {
+	mirrorsIDs, err := c.redis.GetListOfMirrors()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't fetch the list of mirrors")
+	}
+
+	window := time.Duration(GetConfig().HTTPErrorsWindow) * time.Hour
+	reply := &HTTPErrorsReply{}
+	for id := range mirrorsIDs {
+		httpErrors, err := mirrors.GetHTTPErrors(c.redis, id, window)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't fetch the http errors")
+		}
+		m := &MirrorHTTPErrors{ID: int32(id)}
+		for source, codes := range httpErrors {
+			for code, count := range codes {
+				m.Errors = append(m.Errors, &HTTPErrorCount{
+					Source: source,
+					Code:   int32(code),
+					Count:  count,
+				})
+			}
+		}
+		reply.Mirrors = append(reply.Mirrors, m)
+	}
+
+	return reply, nil
+}
+
+func (c *CLI) GetCoverage(ctx context.Context, in *empty.Empty) (*CoverageReply, error) {
+	report, err := mirrors.GetCoverage(c.redis)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't fetch the coverage report")
+	}
+
+	reply := &CoverageReply{}
+	if report == nil {
+		return reply, nil
+	}
+	reply.Generated, _ = ptypes.TimestampProto(report.Generated)
+	reply.Days = int32(report.Days)
+	for _, country := range report.Countries {
+		reply.Countries = append(reply.Countries, &CountryCoverage{
+			CountryCode:     country.CountryCode,
+			Requests:        country.Requests,
+			AverageDistance: country.AverageDistance,
+			LocalMirrors:    int32(country.LocalMirrors),
+			Gap:             country.Gap,
+		})
+	}
+	return reply, nil
+}
+
+func (c *CLI) CollectGarbage(ctx context.Context, in *GCRequest) (*GCReply, error) {
+	report, err := mirrors.CollectGarbage(c.redis, in.DryRun)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't collect the orphaned keys")
+	}
+	return &GCReply{
+		MirrorKeys:        report.MirrorKeys,
+		FileInfos:         report.FileInfos,
+		FileMirrors:       report.FileMirrors,
+		FileMirrorEntries: report.FileMirrorEntries,
+		TmpKeys:           report.TmpKeys,
+		HandledFiles:      report.HandledFiles,
+	}, nil
+}
+
+func (c *CLI) DBInfo(ctx context.Context, in *DBInfoRequest) (*DBInfoReply, error) {
+	samples := int(in.Samples)
+	if samples <= 0 {
+		samples = 100
+	}
+	report, err := c.redis.MemoryReport(samples)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't compute the memory report")
+	}
+	reply := &DBInfoReply{
+		Keys:       report.Keys,
+		UsedMemory: report.UsedMemory,
+	}
+	for _, f := range report.Families {
+		reply.Families = append(reply.Families, &KeyFamily{
+			Name:    f.Name,
+			Keys:    f.Keys,
+			Sampled: f.Sampled,
+			Memory:  f.Memory,
+		})
+	}
+	return reply, nil
+}
+
+func (c *CLI) GenerateAPIKey(ctx context.Context, in *MirrorIDRequest) (*APIKeyReply, error) {
+	key, err := mirrors.GenerateAPIKey(c.redis, int(in.ID))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't generate the API key")
+	}
+	return &APIKeyReply{Key: key}, nil
+}
+
+func (c *CLI) RevokeAPIKey(ctx context.Context, in *MirrorIDRequest) (*empty.Empty, error) {
+	if err := mirrors.RevokeAPIKey(c.redis, int(in.ID)); err != nil {
+		return nil, errors.Wrap(err, "can't revoke the API key")
+	}
+	return &empty.Empty{}, nil
+}
+
+func (c *CLI) GetRequestTrace(ctx context.Context, in *RequestTraceRequest) (*RequestTraceReply, error) {
+	trace, err := mirrors.GetTrace(c.redis, in.RequestID)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't fetch the trace")
+	}
+	if trace == nil {
+		return nil, status.Error(codes.NotFound, "no trace for this request (unknown or expired)")
+	}
+
+	reply := &RequestTraceReply{
+		RequestID: trace.RequestID,
+		Path:      trace.Path,
+		IP:        trace.IP,
+		Country:   trace.Country,
+		ASNum:     int64(trace.ASNum),
+		Output:    trace.Output,
+		Status:    int32(trace.Status),
+		Error:     trace.Error,
+		Fallback:  trace.Fallback,
+		Mirrors:   trace.Mirrors,
+	}
+	reply.Time, _ = ptypes.TimestampProto(trace.Time)
+	for _, e := range trace.Excluded {
+		reply.Excluded = append(reply.Excluded, &TraceExclusion{
+			Name:   e.Name,
+			Reason: e.Reason,
+		})
+	}
+	return reply, nil
+}
+
+func (c *CLI) SignURL(ctx context.Context, in *SignURLRequest) (*SignURLReply, error) {
+	if !strings.HasPrefix(in.Path, "/") {
+		return nil, status.Error(codes.FailedPrecondition, "path must start with a /")
+	}
+	if in.Validity <= 0 {
+		return nil, status.Error(codes.FailedPrecondition, "validity must be positive")
+	}
+
+	p := path.Clean(in.Path)
+	secret := GetConfig().SigningSecret(p)
+	if secret == "" {
+		return nil, status.Error(codes.FailedPrecondition, "path is not restricted by a signed URL prefix")
+	}
+
+	expires := time.Now().Unix() + in.Validity
+
+	return &SignURLReply{
+		Path:      p,
+		Expires:   expires,
+		Signature: utils.SignPath(secret, p, expires),
+	}, nil
+}
+
+func (c *CLI) ListRsyncModules(ctx context.Context, in *RsyncModulesRequest) (*RsyncModulesReply, error) {
+	u, err := url.Parse(in.URL)
+	if err != nil || u.Scheme != "rsync" || u.Host == "" {
+		return nil, status.Error(codes.FailedPrecondition, "invalid rsync URL")
+	}
+
+	modules, err := scan.ListRsyncModules(in.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't fetch the list of rsync modules")
+	}
+
+	reply := &RsyncModulesReply{
+		Modules: make([]*RsyncModule, len(modules)),
+	}
+
+	for i, m := range modules {
+		reply.Modules[i] = &RsyncModule{
+			Name:    m.Name,
+			Comment: m.Comment,
+		}
+	}
+
+	// Check which modules are holding the repository, running at most
+	// ConcurrentSync rsync processes at once
+	workers := GetConfig().ConcurrentSync
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(modules) {
+		workers = len(modules)
+	}
+
+	jobs := make(chan *RsyncModule)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for module := range jobs {
+				mu := *u
+				mu.Path = "/" + module.Name + "/"
+				module.ContainsRepository, _ = scan.RsyncContainsRepository(mu.String())
+			}
+		}()
+	}
+
+feed:
+	for _, module := range reply.Modules {
+		select {
+		case jobs <- module:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return reply, nil
+}
+
 func (c *CLI) ChangeStatus(ctx context.Context, in *ChangeStatusRequest) (*empty.Empty, error) {
 	if in.ID <= 0 {
 		return nil, status.Error(codes.FailedPrecondition, "invalid mirror id")
@@ -264,23 +855,47 @@ func (c *CLI) AddMirror(ctx context.Context, in *Mirror) (*AddMirrorReply, error
 		return nil, errors.WithStack(err)
 	}
 
+	mirror.IPAddress = ip
+
+	// The location given explicitly takes precedence over the GeoIP database
 	geoRec := geo.GetRecord(ip)
 	if geoRec.IsValid() {
-		mirror.Latitude = geoRec.Latitude
-		mirror.Longitude = geoRec.Longitude
-		mirror.ContinentCode = geoRec.ContinentCode
-		mirror.CountryCodes = geoRec.CountryCode
+		if !mirror.ManualLocation || (mirror.Latitude == 0 && mirror.Longitude == 0) {
+			mirror.Latitude = geoRec.Latitude
+			mirror.Longitude = geoRec.Longitude
+		}
+		if !mirror.ManualLocation || len(mirror.CountryCodes) == 0 {
+			mirror.CountryCodes = mirrors.CountryList{geoRec.CountryCode}
+		}
+		if !mirror.ManualLocation {
+			mirror.ContinentCode = geoRec.ContinentCode
+		}
+		if (!mirror.ManualLocation || mirror.RegionCode == "") &&
+			mirror.CountryCodes.Primary() == geoRec.CountryCode && GetConfig().Region(geoRec.RegionCode) != "" {
+			mirror.RegionCode = geoRec.RegionCode
+		}
 		mirror.Asnum = geoRec.ASNum
 
-		reply.Latitude = geoRec.Latitude
-		reply.Longitude = geoRec.Longitude
-		reply.Continent = geoRec.ContinentCode
 		reply.Country = geoRec.Country
+		if mirror.CountryCodes.Primary() != geoRec.CountryCode {
+			reply.Country = mirror.CountryCodes.Primary()
+		}
 		reply.ASN = fmt.Sprintf("%s (%d)", geoRec.ASName, geoRec.ASNum)
+	} else if mirror.ManualLocation {
+		reply.Country = mirror.CountryCodes.Primary()
 	} else {
 		reply.Warnings = append(reply.Warnings,
 			"Warning: unable to guess the geographic location of this mirror")
 	}
+	if mirror.ContinentCode == "" {
+		mirror.ContinentCode = utils.CountryContinent(mirror.CountryCodes.Primary())
+	}
+	reply.Latitude = mirror.Latitude
+	reply.Longitude = mirror.Longitude
+	reply.Continent = mirror.ContinentCode
+
+	// Locate the other endpoints of a multi-homed mirror
+	reply.Warnings = append(reply.Warnings, mirror.Endpoints.Locate(geo)...)
 
 	return reply, c.setMirror(mirror)
 }
@@ -312,10 +927,37 @@ func (c *CLI) UpdateMirror(ctx context.Context, in *Mirror) (*UpdateMirrorReply,
 		return nil, err
 	}
 
+	// A location changed by hand must not be overwritten by the GeoIP
+	// database afterwards
+	if mirror.ManualLocation == original.ManualLocation && !mirror.ManualLocation &&
+		(mirror.Latitude != original.Latitude || mirror.Longitude != original.Longitude ||
+			mirror.CountryCodes.String() != original.CountryCodes.String() ||
+			!strings.EqualFold(mirror.RegionCode, original.RegionCode)) {
+		mirror.ManualLocation = true
+	}
+
+	// The continent follows the country unless it was changed as well
+	if mirror.CountryCodes.Primary() != original.CountryCodes.Primary() && mirror.ContinentCode == original.ContinentCode {
+		if continent := utils.CountryContinent(mirror.CountryCodes.Primary()); continent != "" {
+			mirror.ContinentCode = continent
+		}
+	}
+
+	// Locate the new endpoints of a multi-homed mirror
+	var warnings []string
+	if len(mirror.Endpoints) > 0 {
+		geo := network.NewGeoIP()
+		if err := geo.LoadGeoIP(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		warnings = mirror.Endpoints.Locate(geo)
+	}
+
 	diff := createDiff(&original, mirror)
 
 	return &UpdateMirrorReply{
-		Diff: diff,
+		Diff:     diff,
+		Warnings: warnings,
 	}, c.setMirror(mirror)
 }
 
@@ -336,6 +978,42 @@ func createDiff(mirror1, mirror2 *mirrors.Mirror) (out string) {
 }
 
 func (c *CLI) setMirror(mirror *mirrors.Mirror) error {
+	var err error
+
+	// Validate the country codes
+	mirror.CountryCodes, err = mirror.CountryCodes.Normalize()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	mirror.ExcludedCountryCodes, err = mirror.ExcludedCountryCodes.Normalize()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "excluded countries: "+err.Error())
+	}
+	mirror.Channels, err = mirror.Channels.Normalize()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = mirror.PathRewrites.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	mirror.Endpoints.Normalize()
+	if err = mirror.Endpoints.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	mirror.RegionCode, err = mirrors.NormalizeRegionCode(mirror.RegionCode)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = mirror.Schedule.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = mirror.Probe.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err = ParseProxy(mirror.Proxy); err != nil {
+		return status.Error(codes.InvalidArgument, "proxy: "+err.Error())
+	}
+
 	conn, err := c.redis.Connect()
 	if err != nil {
 		return err
@@ -366,12 +1044,11 @@ func (c *CLI) setMirror(mirror *mirrors.Mirror) error {
 		}
 	}
 
-	// Reformat contry codes
-	mirror.CountryCodes = utils.SanitizeLocationCodes(mirror.CountryCodes)
-	mirror.ExcludedCountryCodes = utils.SanitizeLocationCodes(mirror.ExcludedCountryCodes)
-
-	// Reformat continent code
+	// Reformat continent code, derived from the country if missing
 	mirror.ContinentCode = utils.SanitizeLocationCodes(mirror.ContinentCode)
+	if mirror.ContinentCode == "" {
+		mirror.ContinentCode = utils.CountryContinent(mirror.CountryCodes.Primary())
+	}
 
 	// Normalize URLs
 	if mirror.HttpURL != "" {
@@ -400,6 +1077,7 @@ func (c *CLI) setMirror(mirror *mirrors.Mirror) error {
 		"customData", mirror.CustomData,
 		"continentOnly", mirror.ContinentOnly,
 		"countryOnly", mirror.CountryOnly,
+		"regionOnly", mirror.RegionOnly,
 		"asOnly", mirror.ASOnly,
 		"score", mirror.Score,
 		"latitude", mirror.Latitude,
@@ -407,9 +1085,20 @@ func (c *CLI) setMirror(mirror *mirrors.Mirror) error {
 		"continentCode", mirror.ContinentCode,
 		"countryCodes", mirror.CountryCodes,
 		"excludedCountryCodes", mirror.ExcludedCountryCodes,
+		"regionCode", mirror.RegionCode,
 		"asnum", mirror.Asnum,
+		"manualLocation", mirror.ManualLocation,
 		"comment", mirror.Comment,
 		"allowredirects", mirror.AllowRedirects,
+		"cdn", mirror.CDN,
+		"channels", mirror.Channels,
+		"pathRewrites", mirror.PathRewrites,
+		"endpoints", mirror.Endpoints,
+		"schedule", mirror.Schedule,
+		"probe", mirror.Probe,
+		"proxy", mirror.Proxy,
+		"ip", mirror.IPAddress,
+		"locationWarning", mirror.LocationWarning,
 		"enabled", mirror.Enabled)
 
 	// The name of the mirror has been changed.
@@ -451,6 +1140,12 @@ func (c *CLI) RemoveMirror(ctx context.Context, in *MirrorIDRequest) (*empty.Emp
 		return nil, errors.Wrap(err, "unable to disable the mirror")
 	}
 
+	// The admins of the mirror can't use the API anymore
+	err = mirrors.RevokeAPIKey(c.redis, int(in.ID))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to revoke the API key")
+	}
+
 	// Get all files supported by the given mirror
 	files, err := redis.Strings(conn.Do("SMEMBERS", fmt.Sprintf("MIRRORFILES_%d", in.ID)))
 	if err != nil {
@@ -459,11 +1154,10 @@ func (c *CLI) RemoveMirror(ctx context.Context, in *MirrorIDRequest) (*empty.Emp
 
 	conn.Send("MULTI")
 
-	// Remove each FILEINFO / FILEMIRRORS
+	// Remove each FILEMIRRORS
 	for _, file := range files {
-		conn.Send("DEL", fmt.Sprintf("FILEINFO_%d_%s", in.ID, file))
 		conn.Send("SREM", fmt.Sprintf("FILEMIRRORS_%s", file), in.ID)
-		conn.Send("PUBLISH", database.MIRROR_FILE_UPDATE, fmt.Sprintf("%d %s", in.ID, file))
+		database.SendPublish(conn, database.MIRROR_FILE_UPDATE, fmt.Sprintf("%d %s", in.ID, file))
 	}
 
 	// Remove all other keys
@@ -471,9 +1165,13 @@ func (c *CLI) RemoveMirror(ctx context.Context, in *MirrorIDRequest) (*empty.Emp
 		fmt.Sprintf("MIRROR_%d", in.ID),
 		fmt.Sprintf("MIRRORFILES_%d", in.ID),
 		fmt.Sprintf("MIRRORFILESTMP_%d", in.ID),
+		fmt.Sprintf("MIRRORFILESQUARANTINE_%d", in.ID),
+		fmt.Sprintf("FILEINFOS_%d", in.ID),
 		fmt.Sprintf("HANDLEDFILES_%d", in.ID),
 		fmt.Sprintf("SCANNING_%d", in.ID),
-		fmt.Sprintf("MIRRORLOGS_%d", in.ID))
+		fmt.Sprintf("MIRRORLOGS_%d", in.ID),
+		fmt.Sprintf("SCANSUMMARIES_%d", in.ID),
+		fmt.Sprintf("CONTACT_%d", in.ID))
 
 	// Remove the last reference
 	conn.Send("HDEL", "MIRRORS", in.ID)
@@ -489,8 +1187,74 @@ func (c *CLI) RemoveMirror(ctx context.Context, in *MirrorIDRequest) (*empty.Emp
 	return &empty.Empty{}, nil
 }
 
+func (c *CLI) RenameMirror(ctx context.Context, in *RenameMirrorRequest) (*empty.Empty, error) {
+	if in.ID <= 0 {
+		return nil, status.Error(codes.FailedPrecondition, "invalid mirror id")
+	}
+	if in.Name == "" || strings.ContainsAny(in.Name, " \t") {
+		return nil, status.Error(codes.InvalidArgument, "invalid mirror name")
+	}
+
+	conn, err := c.redis.Connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	mirrorsIDs, err := c.redis.GetListOfMirrors()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't fetch the list of mirrors")
+	}
+
+	if _, ok := mirrorsIDs[int(in.ID)]; !ok {
+		return nil, status.Error(codes.NotFound, "mirror not found")
+	}
+	for id, name := range mirrorsIDs {
+		if id != int(in.ID) && name == in.Name {
+			return nil, ErrNameAlreadyTaken
+		}
+	}
+
+	// All the other keys are indexed by the id of the mirror, only the
+	// references to its name have to be changed
+	conn.Send("MULTI")
+	conn.Send("HSET", fmt.Sprintf("MIRROR_%d", in.ID), "name", in.Name)
+	conn.Send("HSET", "MIRRORS", in.ID, in.Name)
+	_, err = conn.Do("EXEC")
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't rename the mirror")
+	}
+
+	// Publish update
+	database.Publish(conn, database.MIRROR_UPDATE, strconv.Itoa(int(in.ID)))
+
+	mirrors.PushLog(c.redis, mirrors.NewLogEdited(int(in.ID)))
+
+	return &empty.Empty{}, nil
+}
+
 func (c *CLI) RefreshRepository(ctx context.Context, in *RefreshRepositoryRequest) (*empty.Empty, error) {
-	return &empty.Empty{}, scan.ScanSource(c.redis, in.Rehash, nil)
+	err := scan.ScanSource(ctx, c.redis, in.Rehash)
+	if err == nil && in.Push {
+		mirrors.PushMirrors(c.redis)
+	}
+	return &empty.Empty{}, err
+}
+
+func (c *CLI) VerifyRepository(ctx context.Context, in *VerifyRepositoryRequest) (*VerifyRepositoryReply, error) {
+	if !strings.HasPrefix(in.Prefix, "/") {
+		return nil, status.Error(codes.InvalidArgument, "the prefix must start with a /")
+	}
+	res, err := scan.VerifySource(ctx, c.redis, in.Prefix, 0)
+	if err == scan.ErrVerifyUnsupported {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	} else if err != nil {
+		return nil, err
+	}
+	return &VerifyRepositoryReply{
+		Checked:    res.Checked,
+		Mismatches: res.Mismatches,
+	}, nil
 }
 
 func (c *CLI) ScanMirror(ctx context.Context, in *ScanMirrorRequest) (*ScanMirrorReply, error) {
@@ -525,45 +1289,18 @@ func (c *CLI) ScanMirror(ctx context.Context, in *ScanMirrorRequest) (*ScanMirro
 		return nil, err
 	}
 
-	var wg sync.WaitGroup
-	trace := scan.NewTraceHandler(c.redis, make(<-chan struct{}))
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		err := trace.GetLastUpdate(mirror)
-		if err != nil && err != scan.ErrNoTrace {
-			if numError, ok := err.(*strconv.NumError); ok {
-				if numError.Err == strconv.ErrSyntax {
-					//log.Warningf("[%s] parsing trace file failed: %s is not a valid timestamp", mirror.Name, strconv.Quote(numError.Num))
-					return
-				}
-			} else {
-				//log.Warningf("[%s] fetching trace file failed: %s", mirror.Name, err)
-			}
-		}
-	}()
-
-	err = scan.ErrNoSyncMethod
-	var res *scan.ScanResult
-
-	if in.Protocol == ScanMirrorRequest_ALL {
-		// Use rsync (if applicable) and fallback to FTP
-		if mirror.RsyncURL != "" {
-			res, err = scan.Scan(core.RSYNC, c.redis, c.cache, mirror.RsyncURL, mirror.ID, ctx.Done())
-		}
-		if err != nil && mirror.FtpURL != "" {
-			res, err = scan.Scan(core.FTP, c.redis, c.cache, mirror.FtpURL, mirror.ID, ctx.Done())
-		}
-	} else {
-		// Use the requested protocol
-		if in.Protocol == ScanMirrorRequest_RSYNC && mirror.RsyncURL != "" {
-			res, err = scan.Scan(core.RSYNC, c.redis, c.cache, mirror.RsyncURL, mirror.ID, ctx.Done())
-		} else if in.Protocol == ScanMirrorRequest_FTP && mirror.FtpURL != "" {
-			res, err = scan.Scan(core.FTP, c.redis, c.cache, mirror.FtpURL, mirror.ID, ctx.Done())
-		}
+	var methods []core.ScannerType
+	switch in.Protocol {
+	case ScanMirrorRequest_RSYNC:
+		methods = []core.ScannerType{core.RSYNC}
+	case ScanMirrorRequest_FTP:
+		methods = []core.ScannerType{core.FTP}
 	}
 
+	// The scan goes through the queue of the daemon so it never overlaps
+	// another scan of the mirror, it is aborted if the client leaves first
+	queued, _ := c.scanQueue().Add(mirror.ID, mirror.Name, core.ScanSourceCLI, methods, in.Force)
+	res, err := queued.Wait(ctx)
 	if err != nil {
 		return nil, errors.New(fmt.Sprintf("scanning %s failed: %s", mirror.Name, err))
 	}
@@ -583,11 +1320,28 @@ func (c *CLI) ScanMirror(ctx context.Context, in *ScanMirrorRequest) (*ScanMirro
 		reply.Enabled = true
 	}
 
-	wg.Wait()
-
 	return reply, nil
 }
 
+// scanQueue returns the queue of the scans of the monitor, or a queue of
+// its own running ConcurrentSync scans at once if the monitor is disabled
+func (c *CLI) scanQueue() *scan.Queue {
+	if c.monitor != nil {
+		return c.monitor.ScanQueue()
+	}
+	c.scansOnce.Do(func() {
+		trace := scan.NewTraceHandler(c.redis, make(<-chan struct{}))
+		c.scans = scan.NewQueue(func(ctx context.Context, s *scan.QueuedScan) (*scan.ScanResult, error) {
+			if mirror, err := c.cache.GetMirror(s.MirrorID); err == nil {
+				go trace.GetLastUpdate(mirror)
+			}
+			return scan.ScanMirror(ctx, c.redis, c.cache, s)
+		})
+		go c.scans.Run(context.Background(), GetConfig().ConcurrentSync)
+	})
+	return c.scans
+}
+
 func (c *CLI) StatsFile(ctx context.Context, in *StatsFileRequest) (*StatsFileReply, error) {
 	conn, err := c.redis.Connect()
 	if err != nil {
@@ -728,3 +1482,62 @@ func (c *CLI) GetMirrorLogs(ctx context.Context, in *GetMirrorLogsRequest) (*Get
 
 	return &GetMirrorLogsReply{Line: lines}, nil
 }
+
+func (c *CLI) GetScanSummaries(ctx context.Context, in *GetScanSummariesRequest) (*GetScanSummariesReply, error) {
+	if in.ID <= 0 {
+		return nil, status.Error(codes.FailedPrecondition, "invalid mirror id")
+	}
+
+	summaries, err := scan.ReadScanSummaries(c.redis, int(in.ID), int(in.MaxResults))
+	if err != nil {
+		return nil, errors.Wrap(err, "scan summaries error")
+	}
+
+	reply := &GetScanSummariesReply{}
+	for _, s := range summaries {
+		timestamp, err := ptypes.TimestampProto(s.Timestamp)
+		if err != nil {
+			return nil, errors.Wrap(err, "scan summaries error")
+		}
+		reply.Summaries = append(reply.Summaries, &ScanSummary{
+			Timestamp:    timestamp,
+			DurationMs:   int64(s.Duration / time.Millisecond),
+			Method:       s.Method,
+			FilesIndexed: s.FilesIndexed,
+			KnownIndexed: s.KnownIndexed,
+			Added:        s.Added,
+			Removed:      s.Removed,
+			Error:        s.Error,
+			Quarantined:  s.Quarantined,
+			Accepted:     s.Accepted,
+		})
+	}
+
+	return reply, nil
+}
+
+func (c *CLI) GetPropagation(ctx context.Context, in *PropagationRequest) (*PropagationReply, error) {
+	files, err := mirrors.GetPropagation(c.redis, c.cache, in.Prefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "propagation error")
+	}
+
+	reply := &PropagationReply{}
+	for _, f := range files {
+		p := &FilePropagation{
+			Path:       f.Path,
+			Mirrors:    f.Mirrors,
+			Missing:    f.Missing,
+			Percentage: f.Percentage,
+		}
+		if !f.ETA.IsZero() {
+			p.ETA, err = ptypes.TimestampProto(f.ETA)
+			if err != nil {
+				return nil, errors.Wrap(err, "propagation error")
+			}
+		}
+		reply.Files = append(reply.Files, p)
+	}
+
+	return reply, nil
+}
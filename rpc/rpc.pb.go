@@ -51,19 +51,26 @@ func (x ScanMirrorRequest_Method) String() string {
 }
 
 func (ScanMirrorRequest_Method) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_77a6da22d6a3feb1, []int{11, 0}
+	return fileDescriptor_77a6da22d6a3feb1, []int{35, 0}
 }
 
 type VersionReply struct {
-	Version              string   `protobuf:"bytes,1,opt,name=Version,proto3" json:"Version,omitempty"`
-	Build                string   `protobuf:"bytes,2,opt,name=Build,proto3" json:"Build,omitempty"`
-	GoVersion            string   `protobuf:"bytes,3,opt,name=GoVersion,proto3" json:"GoVersion,omitempty"`
-	OS                   string   `protobuf:"bytes,4,opt,name=OS,proto3" json:"OS,omitempty"`
-	Arch                 string   `protobuf:"bytes,5,opt,name=Arch,proto3" json:"Arch,omitempty"`
-	GoMaxProcs           int32    `protobuf:"varint,6,opt,name=GoMaxProcs,proto3" json:"GoMaxProcs,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Version              string           `protobuf:"bytes,1,opt,name=Version,proto3" json:"Version,omitempty"`
+	Build                string           `protobuf:"bytes,2,opt,name=Build,proto3" json:"Build,omitempty"`
+	GoVersion            string           `protobuf:"bytes,3,opt,name=GoVersion,proto3" json:"GoVersion,omitempty"`
+	OS                   string           `protobuf:"bytes,4,opt,name=OS,proto3" json:"OS,omitempty"`
+	Arch                 string           `protobuf:"bytes,5,opt,name=Arch,proto3" json:"Arch,omitempty"`
+	GoMaxProcs           int32            `protobuf:"varint,6,opt,name=GoMaxProcs,proto3" json:"GoMaxProcs,omitempty"`
+	ConfigFile           string           `protobuf:"bytes,7,opt,name=ConfigFile,proto3" json:"ConfigFile,omitempty"`
+	Commit               string           `protobuf:"bytes,8,opt,name=Commit,proto3" json:"Commit,omitempty"`
+	BuildDate            string           `protobuf:"bytes,9,opt,name=BuildDate,proto3" json:"BuildDate,omitempty"`
+	RedisVersion         string           `protobuf:"bytes,10,opt,name=RedisVersion,proto3" json:"RedisVersion,omitempty"`
+	DBVersion            int32            `protobuf:"varint,11,opt,name=DBVersion,proto3" json:"DBVersion,omitempty"`
+	DatabaseError        string           `protobuf:"bytes,12,opt,name=DatabaseError,proto3" json:"DatabaseError,omitempty"`
+	GeoIPDatabases       []*GeoIPDatabase `protobuf:"bytes,13,rep,name=GeoIPDatabases,proto3" json:"GeoIPDatabases,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
 }
 
 func (m *VersionReply) Reset()         { *m = VersionReply{} }
@@ -133,1764 +140,5656 @@ func (m *VersionReply) GetGoMaxProcs() int32 {
 	return 0
 }
 
-type MatchRequest struct {
-	Pattern              string   `protobuf:"bytes,1,opt,name=Pattern,proto3" json:"Pattern,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *VersionReply) GetConfigFile() string {
+	if m != nil {
+		return m.ConfigFile
+	}
+	return ""
 }
 
-func (m *MatchRequest) Reset()         { *m = MatchRequest{} }
-func (m *MatchRequest) String() string { return proto.CompactTextString(m) }
-func (*MatchRequest) ProtoMessage()    {}
-func (*MatchRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77a6da22d6a3feb1, []int{1}
+func (m *VersionReply) GetCommit() string {
+	if m != nil {
+		return m.Commit
+	}
+	return ""
 }
 
-func (m *MatchRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_MatchRequest.Unmarshal(m, b)
-}
-func (m *MatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_MatchRequest.Marshal(b, m, deterministic)
-}
-func (m *MatchRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_MatchRequest.Merge(m, src)
-}
-func (m *MatchRequest) XXX_Size() int {
-	return xxx_messageInfo_MatchRequest.Size(m)
+func (m *VersionReply) GetBuildDate() string {
+	if m != nil {
+		return m.BuildDate
+	}
+	return ""
 }
-func (m *MatchRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_MatchRequest.DiscardUnknown(m)
+
+func (m *VersionReply) GetRedisVersion() string {
+	if m != nil {
+		return m.RedisVersion
+	}
+	return ""
 }
 
-var xxx_messageInfo_MatchRequest proto.InternalMessageInfo
+func (m *VersionReply) GetDBVersion() int32 {
+	if m != nil {
+		return m.DBVersion
+	}
+	return 0
+}
 
-func (m *MatchRequest) GetPattern() string {
+func (m *VersionReply) GetDatabaseError() string {
 	if m != nil {
-		return m.Pattern
+		return m.DatabaseError
 	}
 	return ""
 }
 
-type Mirror struct {
-	ID                   int32                `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Name                 string               `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
-	HttpURL              string               `protobuf:"bytes,3,opt,name=HttpURL,proto3" json:"HttpURL,omitempty"`
-	RsyncURL             string               `protobuf:"bytes,4,opt,name=RsyncURL,proto3" json:"RsyncURL,omitempty"`
-	FtpURL               string               `protobuf:"bytes,5,opt,name=FtpURL,proto3" json:"FtpURL,omitempty"`
-	SponsorName          string               `protobuf:"bytes,6,opt,name=SponsorName,proto3" json:"SponsorName,omitempty"`
-	SponsorURL           string               `protobuf:"bytes,7,opt,name=SponsorURL,proto3" json:"SponsorURL,omitempty"`
-	SponsorLogoURL       string               `protobuf:"bytes,8,opt,name=SponsorLogoURL,proto3" json:"SponsorLogoURL,omitempty"`
-	AdminName            string               `protobuf:"bytes,9,opt,name=AdminName,proto3" json:"AdminName,omitempty"`
-	AdminEmail           string               `protobuf:"bytes,10,opt,name=AdminEmail,proto3" json:"AdminEmail,omitempty"`
-	CustomData           string               `protobuf:"bytes,11,opt,name=CustomData,proto3" json:"CustomData,omitempty"`
-	ContinentOnly        bool                 `protobuf:"varint,12,opt,name=ContinentOnly,proto3" json:"ContinentOnly,omitempty"`
-	CountryOnly          bool                 `protobuf:"varint,13,opt,name=CountryOnly,proto3" json:"CountryOnly,omitempty"`
-	ASOnly               bool                 `protobuf:"varint,14,opt,name=ASOnly,proto3" json:"ASOnly,omitempty"`
-	Score                int32                `protobuf:"varint,15,opt,name=Score,proto3" json:"Score,omitempty"`
-	Latitude             float32              `protobuf:"fixed32,16,opt,name=Latitude,proto3" json:"Latitude,omitempty"`
-	Longitude            float32              `protobuf:"fixed32,17,opt,name=Longitude,proto3" json:"Longitude,omitempty"`
-	ContinentCode        string               `protobuf:"bytes,18,opt,name=ContinentCode,proto3" json:"ContinentCode,omitempty"`
-	CountryCodes         string               `protobuf:"bytes,19,opt,name=CountryCodes,proto3" json:"CountryCodes,omitempty"`
-	ExcludedCountryCodes string               `protobuf:"bytes,20,opt,name=ExcludedCountryCodes,proto3" json:"ExcludedCountryCodes,omitempty"`
-	Asnum                uint32               `protobuf:"varint,21,opt,name=Asnum,proto3" json:"Asnum,omitempty"`
-	Comment              string               `protobuf:"bytes,22,opt,name=Comment,proto3" json:"Comment,omitempty"`
-	Enabled              bool                 `protobuf:"varint,23,opt,name=Enabled,proto3" json:"Enabled,omitempty"`
-	Up                   bool                 `protobuf:"varint,24,opt,name=Up,proto3" json:"Up,omitempty"`
-	ExcludeReason        string               `protobuf:"bytes,25,opt,name=ExcludeReason,proto3" json:"ExcludeReason,omitempty"`
-	StateSince           *timestamp.Timestamp `protobuf:"bytes,26,opt,name=StateSince,proto3" json:"StateSince,omitempty"`
-	AllowRedirects       int32                `protobuf:"varint,27,opt,name=AllowRedirects,proto3" json:"AllowRedirects,omitempty"`
-	LastSync             *timestamp.Timestamp `protobuf:"bytes,28,opt,name=LastSync,proto3" json:"LastSync,omitempty"`
-	LastSuccessfulSync   *timestamp.Timestamp `protobuf:"bytes,29,opt,name=LastSuccessfulSync,proto3" json:"LastSuccessfulSync,omitempty"`
-	LastModTime          *timestamp.Timestamp `protobuf:"bytes,30,opt,name=LastModTime,proto3" json:"LastModTime,omitempty"`
+func (m *VersionReply) GetGeoIPDatabases() []*GeoIPDatabase {
+	if m != nil {
+		return m.GeoIPDatabases
+	}
+	return nil
+}
+
+type GeoIPDatabase struct {
+	Filename             string               `protobuf:"bytes,1,opt,name=Filename,proto3" json:"Filename,omitempty"`
+	BuildDate            *timestamp.Timestamp `protobuf:"bytes,2,opt,name=BuildDate,proto3" json:"BuildDate,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
 }
 
-func (m *Mirror) Reset()         { *m = Mirror{} }
-func (m *Mirror) String() string { return proto.CompactTextString(m) }
-func (*Mirror) ProtoMessage()    {}
-func (*Mirror) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77a6da22d6a3feb1, []int{2}
+func (m *GeoIPDatabase) Reset()         { *m = GeoIPDatabase{} }
+func (m *GeoIPDatabase) String() string { return proto.CompactTextString(m) }
+func (*GeoIPDatabase) ProtoMessage()    {}
+func (*GeoIPDatabase) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{1}
 }
 
-func (m *Mirror) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Mirror.Unmarshal(m, b)
+func (m *GeoIPDatabase) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GeoIPDatabase.Unmarshal(m, b)
 }
-func (m *Mirror) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Mirror.Marshal(b, m, deterministic)
+func (m *GeoIPDatabase) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GeoIPDatabase.Marshal(b, m, deterministic)
 }
-func (m *Mirror) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Mirror.Merge(m, src)
+func (m *GeoIPDatabase) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GeoIPDatabase.Merge(m, src)
 }
-func (m *Mirror) XXX_Size() int {
-	return xxx_messageInfo_Mirror.Size(m)
+func (m *GeoIPDatabase) XXX_Size() int {
+	return xxx_messageInfo_GeoIPDatabase.Size(m)
 }
-func (m *Mirror) XXX_DiscardUnknown() {
-	xxx_messageInfo_Mirror.DiscardUnknown(m)
+func (m *GeoIPDatabase) XXX_DiscardUnknown() {
+	xxx_messageInfo_GeoIPDatabase.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Mirror proto.InternalMessageInfo
+var xxx_messageInfo_GeoIPDatabase proto.InternalMessageInfo
 
-func (m *Mirror) GetID() int32 {
+func (m *GeoIPDatabase) GetFilename() string {
 	if m != nil {
-		return m.ID
+		return m.Filename
 	}
-	return 0
+	return ""
 }
 
-func (m *Mirror) GetName() string {
+func (m *GeoIPDatabase) GetBuildDate() *timestamp.Timestamp {
 	if m != nil {
-		return m.Name
+		return m.BuildDate
 	}
-	return ""
+	return nil
 }
 
-func (m *Mirror) GetHttpURL() string {
+type StatusReply struct {
+	Version              string               `protobuf:"bytes,1,opt,name=Version,proto3" json:"Version,omitempty"`
+	Started              *timestamp.Timestamp `protobuf:"bytes,2,opt,name=Started,proto3" json:"Started,omitempty"`
+	ConfigFile           string               `protobuf:"bytes,3,opt,name=ConfigFile,proto3" json:"ConfigFile,omitempty"`
+	ListenAddress        string               `protobuf:"bytes,4,opt,name=ListenAddress,proto3" json:"ListenAddress,omitempty"`
+	RPCListenAddress     string               `protobuf:"bytes,5,opt,name=RPCListenAddress,proto3" json:"RPCListenAddress,omitempty"`
+	DatabaseReachable    bool                 `protobuf:"varint,6,opt,name=DatabaseReachable,proto3" json:"DatabaseReachable,omitempty"`
+	DatabaseError        string               `protobuf:"bytes,7,opt,name=DatabaseError,proto3" json:"DatabaseError,omitempty"`
+	MirrorsUp            int32                `protobuf:"varint,8,opt,name=MirrorsUp,proto3" json:"MirrorsUp,omitempty"`
+	MirrorsDown          int32                `protobuf:"varint,9,opt,name=MirrorsDown,proto3" json:"MirrorsDown,omitempty"`
+	MirrorsDisabled      int32                `protobuf:"varint,10,opt,name=MirrorsDisabled,proto3" json:"MirrorsDisabled,omitempty"`
+	LastRepositoryScan   *timestamp.Timestamp `protobuf:"bytes,11,opt,name=LastRepositoryScan,proto3" json:"LastRepositoryScan,omitempty"`
+	Monitor              bool                 `protobuf:"varint,12,opt,name=Monitor,proto3" json:"Monitor,omitempty"`
+	HealthChecks         int32                `protobuf:"varint,13,opt,name=HealthChecks,proto3" json:"HealthChecks,omitempty"`
+	Scans                int32                `protobuf:"varint,14,opt,name=Scans,proto3" json:"Scans,omitempty"`
+	PendingScans         int32                `protobuf:"varint,15,opt,name=PendingScans,proto3" json:"PendingScans,omitempty"`
+	ScanningPaused       *timestamp.Timestamp `protobuf:"bytes,16,opt,name=ScanningPaused,proto3" json:"ScanningPaused,omitempty"`
+	MonitoringPaused     *timestamp.Timestamp `protobuf:"bytes,17,opt,name=MonitoringPaused,proto3" json:"MonitoringPaused,omitempty"`
+	Degraded             bool                 `protobuf:"varint,18,opt,name=Degraded,proto3" json:"Degraded,omitempty"`
+	PendingWrites        int32                `protobuf:"varint,19,opt,name=PendingWrites,proto3" json:"PendingWrites,omitempty"`
+	WorkerPools          []*WorkerPool        `protobuf:"bytes,20,rep,name=WorkerPools,proto3" json:"WorkerPools,omitempty"`
+	ScanQueue            []*QueuedScan        `protobuf:"bytes,21,rep,name=ScanQueue,proto3" json:"ScanQueue,omitempty"`
+	Quarantined          []*QuarantinedMirror `protobuf:"bytes,22,rep,name=Quarantined,proto3" json:"Quarantined,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *StatusReply) Reset()         { *m = StatusReply{} }
+func (m *StatusReply) String() string { return proto.CompactTextString(m) }
+func (*StatusReply) ProtoMessage()    {}
+func (*StatusReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{2}
+}
+
+func (m *StatusReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatusReply.Unmarshal(m, b)
+}
+func (m *StatusReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatusReply.Marshal(b, m, deterministic)
+}
+func (m *StatusReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatusReply.Merge(m, src)
+}
+func (m *StatusReply) XXX_Size() int {
+	return xxx_messageInfo_StatusReply.Size(m)
+}
+func (m *StatusReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatusReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatusReply proto.InternalMessageInfo
+
+func (m *StatusReply) GetVersion() string {
 	if m != nil {
-		return m.HttpURL
+		return m.Version
 	}
 	return ""
 }
 
-func (m *Mirror) GetRsyncURL() string {
+func (m *StatusReply) GetStarted() *timestamp.Timestamp {
 	if m != nil {
-		return m.RsyncURL
+		return m.Started
 	}
-	return ""
+	return nil
 }
 
-func (m *Mirror) GetFtpURL() string {
+func (m *StatusReply) GetConfigFile() string {
 	if m != nil {
-		return m.FtpURL
+		return m.ConfigFile
 	}
 	return ""
 }
 
-func (m *Mirror) GetSponsorName() string {
+func (m *StatusReply) GetListenAddress() string {
 	if m != nil {
-		return m.SponsorName
+		return m.ListenAddress
 	}
 	return ""
 }
 
-func (m *Mirror) GetSponsorURL() string {
+func (m *StatusReply) GetRPCListenAddress() string {
 	if m != nil {
-		return m.SponsorURL
+		return m.RPCListenAddress
 	}
 	return ""
 }
 
-func (m *Mirror) GetSponsorLogoURL() string {
+func (m *StatusReply) GetDatabaseReachable() bool {
 	if m != nil {
-		return m.SponsorLogoURL
+		return m.DatabaseReachable
 	}
-	return ""
+	return false
 }
 
-func (m *Mirror) GetAdminName() string {
+func (m *StatusReply) GetDatabaseError() string {
 	if m != nil {
-		return m.AdminName
+		return m.DatabaseError
 	}
 	return ""
 }
 
-func (m *Mirror) GetAdminEmail() string {
+func (m *StatusReply) GetMirrorsUp() int32 {
 	if m != nil {
-		return m.AdminEmail
+		return m.MirrorsUp
 	}
-	return ""
+	return 0
 }
 
-func (m *Mirror) GetCustomData() string {
+func (m *StatusReply) GetMirrorsDown() int32 {
 	if m != nil {
-		return m.CustomData
+		return m.MirrorsDown
 	}
-	return ""
+	return 0
 }
 
-func (m *Mirror) GetContinentOnly() bool {
+func (m *StatusReply) GetMirrorsDisabled() int32 {
 	if m != nil {
-		return m.ContinentOnly
+		return m.MirrorsDisabled
 	}
-	return false
+	return 0
 }
 
-func (m *Mirror) GetCountryOnly() bool {
+func (m *StatusReply) GetLastRepositoryScan() *timestamp.Timestamp {
 	if m != nil {
-		return m.CountryOnly
+		return m.LastRepositoryScan
 	}
-	return false
+	return nil
 }
 
-func (m *Mirror) GetASOnly() bool {
+func (m *StatusReply) GetMonitor() bool {
 	if m != nil {
-		return m.ASOnly
+		return m.Monitor
 	}
 	return false
 }
 
-func (m *Mirror) GetScore() int32 {
+func (m *StatusReply) GetHealthChecks() int32 {
 	if m != nil {
-		return m.Score
+		return m.HealthChecks
 	}
 	return 0
 }
 
-func (m *Mirror) GetLatitude() float32 {
+func (m *StatusReply) GetScans() int32 {
 	if m != nil {
-		return m.Latitude
+		return m.Scans
 	}
 	return 0
 }
 
-func (m *Mirror) GetLongitude() float32 {
+func (m *StatusReply) GetPendingScans() int32 {
 	if m != nil {
-		return m.Longitude
+		return m.PendingScans
 	}
 	return 0
 }
 
-func (m *Mirror) GetContinentCode() string {
+func (m *StatusReply) GetScanningPaused() *timestamp.Timestamp {
 	if m != nil {
-		return m.ContinentCode
+		return m.ScanningPaused
 	}
-	return ""
+	return nil
 }
 
-func (m *Mirror) GetCountryCodes() string {
+func (m *StatusReply) GetMonitoringPaused() *timestamp.Timestamp {
 	if m != nil {
-		return m.CountryCodes
+		return m.MonitoringPaused
 	}
-	return ""
+	return nil
 }
 
-func (m *Mirror) GetExcludedCountryCodes() string {
+func (m *StatusReply) GetDegraded() bool {
 	if m != nil {
-		return m.ExcludedCountryCodes
+		return m.Degraded
 	}
-	return ""
+	return false
 }
 
-func (m *Mirror) GetAsnum() uint32 {
+func (m *StatusReply) GetPendingWrites() int32 {
 	if m != nil {
-		return m.Asnum
+		return m.PendingWrites
 	}
 	return 0
 }
 
-func (m *Mirror) GetComment() string {
+func (m *StatusReply) GetWorkerPools() []*WorkerPool {
 	if m != nil {
-		return m.Comment
+		return m.WorkerPools
 	}
-	return ""
+	return nil
 }
 
-func (m *Mirror) GetEnabled() bool {
+func (m *StatusReply) GetScanQueue() []*QueuedScan {
 	if m != nil {
-		return m.Enabled
+		return m.ScanQueue
 	}
-	return false
+	return nil
 }
 
-func (m *Mirror) GetUp() bool {
+func (m *StatusReply) GetQuarantined() []*QuarantinedMirror {
 	if m != nil {
-		return m.Up
+		return m.Quarantined
 	}
-	return false
+	return nil
 }
 
-func (m *Mirror) GetExcludeReason() string {
-	if m != nil {
-		return m.ExcludeReason
-	}
-	return ""
+type QuarantinedMirror struct {
+	MirrorID             int32                `protobuf:"varint,1,opt,name=MirrorID,proto3" json:"MirrorID,omitempty"`
+	MirrorName           string               `protobuf:"bytes,2,opt,name=MirrorName,proto3" json:"MirrorName,omitempty"`
+	Since                *timestamp.Timestamp `protobuf:"bytes,3,opt,name=Since,proto3" json:"Since,omitempty"`
+	Files                int64                `protobuf:"varint,4,opt,name=Files,proto3" json:"Files,omitempty"`
+	Previous             int64                `protobuf:"varint,5,opt,name=Previous,proto3" json:"Previous,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
-func (m *Mirror) GetStateSince() *timestamp.Timestamp {
+func (m *QuarantinedMirror) Reset()         { *m = QuarantinedMirror{} }
+func (m *QuarantinedMirror) String() string { return proto.CompactTextString(m) }
+func (*QuarantinedMirror) ProtoMessage()    {}
+func (*QuarantinedMirror) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{3}
+}
+
+func (m *QuarantinedMirror) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QuarantinedMirror.Unmarshal(m, b)
+}
+func (m *QuarantinedMirror) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QuarantinedMirror.Marshal(b, m, deterministic)
+}
+func (m *QuarantinedMirror) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QuarantinedMirror.Merge(m, src)
+}
+func (m *QuarantinedMirror) XXX_Size() int {
+	return xxx_messageInfo_QuarantinedMirror.Size(m)
+}
+func (m *QuarantinedMirror) XXX_DiscardUnknown() {
+	xxx_messageInfo_QuarantinedMirror.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QuarantinedMirror proto.InternalMessageInfo
+
+func (m *QuarantinedMirror) GetMirrorID() int32 {
 	if m != nil {
-		return m.StateSince
+		return m.MirrorID
 	}
-	return nil
+	return 0
 }
 
-func (m *Mirror) GetAllowRedirects() int32 {
+func (m *QuarantinedMirror) GetMirrorName() string {
 	if m != nil {
-		return m.AllowRedirects
+		return m.MirrorName
 	}
-	return 0
+	return ""
 }
 
-func (m *Mirror) GetLastSync() *timestamp.Timestamp {
+func (m *QuarantinedMirror) GetSince() *timestamp.Timestamp {
 	if m != nil {
-		return m.LastSync
+		return m.Since
 	}
 	return nil
 }
 
-func (m *Mirror) GetLastSuccessfulSync() *timestamp.Timestamp {
+func (m *QuarantinedMirror) GetFiles() int64 {
 	if m != nil {
-		return m.LastSuccessfulSync
+		return m.Files
 	}
-	return nil
+	return 0
 }
 
-func (m *Mirror) GetLastModTime() *timestamp.Timestamp {
+func (m *QuarantinedMirror) GetPrevious() int64 {
 	if m != nil {
-		return m.LastModTime
+		return m.Previous
 	}
-	return nil
+	return 0
 }
 
-type MirrorListReply struct {
-	Mirrors              []*Mirror `protobuf:"bytes,1,rep,name=Mirrors,proto3" json:"Mirrors,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+type QueuedScan struct {
+	MirrorID             int32                `protobuf:"varint,1,opt,name=MirrorID,proto3" json:"MirrorID,omitempty"`
+	MirrorName           string               `protobuf:"bytes,2,opt,name=MirrorName,proto3" json:"MirrorName,omitempty"`
+	Source               string               `protobuf:"bytes,3,opt,name=Source,proto3" json:"Source,omitempty"`
+	Force                bool                 `protobuf:"varint,4,opt,name=Force,proto3" json:"Force,omitempty"`
+	Queued               *timestamp.Timestamp `protobuf:"bytes,5,opt,name=Queued,proto3" json:"Queued,omitempty"`
+	Started              *timestamp.Timestamp `protobuf:"bytes,6,opt,name=Started,proto3" json:"Started,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
-func (m *MirrorListReply) Reset()         { *m = MirrorListReply{} }
-func (m *MirrorListReply) String() string { return proto.CompactTextString(m) }
-func (*MirrorListReply) ProtoMessage()    {}
-func (*MirrorListReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77a6da22d6a3feb1, []int{3}
+func (m *QueuedScan) Reset()         { *m = QueuedScan{} }
+func (m *QueuedScan) String() string { return proto.CompactTextString(m) }
+func (*QueuedScan) ProtoMessage()    {}
+func (*QueuedScan) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{4}
 }
 
-func (m *MirrorListReply) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_MirrorListReply.Unmarshal(m, b)
+func (m *QueuedScan) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueuedScan.Unmarshal(m, b)
 }
-func (m *MirrorListReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_MirrorListReply.Marshal(b, m, deterministic)
+func (m *QueuedScan) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueuedScan.Marshal(b, m, deterministic)
 }
-func (m *MirrorListReply) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_MirrorListReply.Merge(m, src)
+func (m *QueuedScan) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueuedScan.Merge(m, src)
 }
-func (m *MirrorListReply) XXX_Size() int {
-	return xxx_messageInfo_MirrorListReply.Size(m)
+func (m *QueuedScan) XXX_Size() int {
+	return xxx_messageInfo_QueuedScan.Size(m)
 }
-func (m *MirrorListReply) XXX_DiscardUnknown() {
-	xxx_messageInfo_MirrorListReply.DiscardUnknown(m)
+func (m *QueuedScan) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueuedScan.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_MirrorListReply proto.InternalMessageInfo
+var xxx_messageInfo_QueuedScan proto.InternalMessageInfo
 
-func (m *MirrorListReply) GetMirrors() []*Mirror {
+func (m *QueuedScan) GetMirrorID() int32 {
 	if m != nil {
-		return m.Mirrors
+		return m.MirrorID
 	}
-	return nil
+	return 0
 }
 
-type MirrorID struct {
-	ID                   int32    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Name                 string   `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *QueuedScan) GetMirrorName() string {
+	if m != nil {
+		return m.MirrorName
+	}
+	return ""
 }
 
-func (m *MirrorID) Reset()         { *m = MirrorID{} }
-func (m *MirrorID) String() string { return proto.CompactTextString(m) }
-func (*MirrorID) ProtoMessage()    {}
-func (*MirrorID) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77a6da22d6a3feb1, []int{4}
+func (m *QueuedScan) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
 }
 
-func (m *MirrorID) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_MirrorID.Unmarshal(m, b)
-}
-func (m *MirrorID) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_MirrorID.Marshal(b, m, deterministic)
-}
-func (m *MirrorID) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_MirrorID.Merge(m, src)
-}
-func (m *MirrorID) XXX_Size() int {
-	return xxx_messageInfo_MirrorID.Size(m)
-}
-func (m *MirrorID) XXX_DiscardUnknown() {
-	xxx_messageInfo_MirrorID.DiscardUnknown(m)
+func (m *QueuedScan) GetForce() bool {
+	if m != nil {
+		return m.Force
+	}
+	return false
 }
 
-var xxx_messageInfo_MirrorID proto.InternalMessageInfo
-
-func (m *MirrorID) GetID() int32 {
+func (m *QueuedScan) GetQueued() *timestamp.Timestamp {
 	if m != nil {
-		return m.ID
+		return m.Queued
 	}
-	return 0
+	return nil
 }
 
-func (m *MirrorID) GetName() string {
+func (m *QueuedScan) GetStarted() *timestamp.Timestamp {
 	if m != nil {
-		return m.Name
+		return m.Started
 	}
-	return ""
+	return nil
 }
 
-type MatchReply struct {
-	Mirrors              []*MirrorID `protobuf:"bytes,1,rep,name=Mirrors,proto3" json:"Mirrors,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
-	XXX_unrecognized     []byte      `json:"-"`
-	XXX_sizecache        int32       `json:"-"`
+type WorkerPool struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Workers              int32    `protobuf:"varint,2,opt,name=Workers,proto3" json:"Workers,omitempty"`
+	Busy                 int32    `protobuf:"varint,3,opt,name=Busy,proto3" json:"Busy,omitempty"`
+	Queued               int32    `protobuf:"varint,4,opt,name=Queued,proto3" json:"Queued,omitempty"`
+	QueueSize            int32    `protobuf:"varint,5,opt,name=QueueSize,proto3" json:"QueueSize,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *MatchReply) Reset()         { *m = MatchReply{} }
-func (m *MatchReply) String() string { return proto.CompactTextString(m) }
-func (*MatchReply) ProtoMessage()    {}
-func (*MatchReply) Descriptor() ([]byte, []int) {
+func (m *WorkerPool) Reset()         { *m = WorkerPool{} }
+func (m *WorkerPool) String() string { return proto.CompactTextString(m) }
+func (*WorkerPool) ProtoMessage()    {}
+func (*WorkerPool) Descriptor() ([]byte, []int) {
 	return fileDescriptor_77a6da22d6a3feb1, []int{5}
 }
 
-func (m *MatchReply) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_MatchReply.Unmarshal(m, b)
+func (m *WorkerPool) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WorkerPool.Unmarshal(m, b)
 }
-func (m *MatchReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_MatchReply.Marshal(b, m, deterministic)
+func (m *WorkerPool) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WorkerPool.Marshal(b, m, deterministic)
 }
-func (m *MatchReply) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_MatchReply.Merge(m, src)
+func (m *WorkerPool) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WorkerPool.Merge(m, src)
 }
-func (m *MatchReply) XXX_Size() int {
-	return xxx_messageInfo_MatchReply.Size(m)
+func (m *WorkerPool) XXX_Size() int {
+	return xxx_messageInfo_WorkerPool.Size(m)
 }
-func (m *MatchReply) XXX_DiscardUnknown() {
-	xxx_messageInfo_MatchReply.DiscardUnknown(m)
+func (m *WorkerPool) XXX_DiscardUnknown() {
+	xxx_messageInfo_WorkerPool.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_MatchReply proto.InternalMessageInfo
+var xxx_messageInfo_WorkerPool proto.InternalMessageInfo
 
-func (m *MatchReply) GetMirrors() []*MirrorID {
+func (m *WorkerPool) GetName() string {
 	if m != nil {
-		return m.Mirrors
+		return m.Name
 	}
-	return nil
+	return ""
 }
 
-type ChangeStatusRequest struct {
-	ID                   int32    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Enabled              bool     `protobuf:"varint,2,opt,name=Enabled,proto3" json:"Enabled,omitempty"`
+func (m *WorkerPool) GetWorkers() int32 {
+	if m != nil {
+		return m.Workers
+	}
+	return 0
+}
+
+func (m *WorkerPool) GetBusy() int32 {
+	if m != nil {
+		return m.Busy
+	}
+	return 0
+}
+
+func (m *WorkerPool) GetQueued() int32 {
+	if m != nil {
+		return m.Queued
+	}
+	return 0
+}
+
+func (m *WorkerPool) GetQueueSize() int32 {
+	if m != nil {
+		return m.QueueSize
+	}
+	return 0
+}
+
+type PauseRequest struct {
+	Activity             string   `protobuf:"bytes,1,opt,name=Activity,proto3" json:"Activity,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ChangeStatusRequest) Reset()         { *m = ChangeStatusRequest{} }
-func (m *ChangeStatusRequest) String() string { return proto.CompactTextString(m) }
-func (*ChangeStatusRequest) ProtoMessage()    {}
-func (*ChangeStatusRequest) Descriptor() ([]byte, []int) {
+func (m *PauseRequest) Reset()         { *m = PauseRequest{} }
+func (m *PauseRequest) String() string { return proto.CompactTextString(m) }
+func (*PauseRequest) ProtoMessage()    {}
+func (*PauseRequest) Descriptor() ([]byte, []int) {
 	return fileDescriptor_77a6da22d6a3feb1, []int{6}
 }
 
-func (m *ChangeStatusRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ChangeStatusRequest.Unmarshal(m, b)
+func (m *PauseRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PauseRequest.Unmarshal(m, b)
 }
-func (m *ChangeStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ChangeStatusRequest.Marshal(b, m, deterministic)
+func (m *PauseRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PauseRequest.Marshal(b, m, deterministic)
 }
-func (m *ChangeStatusRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ChangeStatusRequest.Merge(m, src)
+func (m *PauseRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PauseRequest.Merge(m, src)
 }
-func (m *ChangeStatusRequest) XXX_Size() int {
-	return xxx_messageInfo_ChangeStatusRequest.Size(m)
+func (m *PauseRequest) XXX_Size() int {
+	return xxx_messageInfo_PauseRequest.Size(m)
 }
-func (m *ChangeStatusRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_ChangeStatusRequest.DiscardUnknown(m)
+func (m *PauseRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PauseRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ChangeStatusRequest proto.InternalMessageInfo
-
-func (m *ChangeStatusRequest) GetID() int32 {
-	if m != nil {
-		return m.ID
-	}
-	return 0
-}
+var xxx_messageInfo_PauseRequest proto.InternalMessageInfo
 
-func (m *ChangeStatusRequest) GetEnabled() bool {
+func (m *PauseRequest) GetActivity() string {
 	if m != nil {
-		return m.Enabled
+		return m.Activity
 	}
-	return false
+	return ""
 }
 
-type MirrorIDRequest struct {
-	ID                   int32    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+type GCRequest struct {
+	DryRun               bool     `protobuf:"varint,1,opt,name=DryRun,proto3" json:"DryRun,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *MirrorIDRequest) Reset()         { *m = MirrorIDRequest{} }
-func (m *MirrorIDRequest) String() string { return proto.CompactTextString(m) }
-func (*MirrorIDRequest) ProtoMessage()    {}
-func (*MirrorIDRequest) Descriptor() ([]byte, []int) {
+func (m *GCRequest) Reset()         { *m = GCRequest{} }
+func (m *GCRequest) String() string { return proto.CompactTextString(m) }
+func (*GCRequest) ProtoMessage()    {}
+func (*GCRequest) Descriptor() ([]byte, []int) {
 	return fileDescriptor_77a6da22d6a3feb1, []int{7}
 }
 
-func (m *MirrorIDRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_MirrorIDRequest.Unmarshal(m, b)
+func (m *GCRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GCRequest.Unmarshal(m, b)
 }
-func (m *MirrorIDRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_MirrorIDRequest.Marshal(b, m, deterministic)
+func (m *GCRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GCRequest.Marshal(b, m, deterministic)
 }
-func (m *MirrorIDRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_MirrorIDRequest.Merge(m, src)
+func (m *GCRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GCRequest.Merge(m, src)
 }
-func (m *MirrorIDRequest) XXX_Size() int {
-	return xxx_messageInfo_MirrorIDRequest.Size(m)
+func (m *GCRequest) XXX_Size() int {
+	return xxx_messageInfo_GCRequest.Size(m)
 }
-func (m *MirrorIDRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_MirrorIDRequest.DiscardUnknown(m)
+func (m *GCRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GCRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_MirrorIDRequest proto.InternalMessageInfo
+var xxx_messageInfo_GCRequest proto.InternalMessageInfo
 
-func (m *MirrorIDRequest) GetID() int32 {
+func (m *GCRequest) GetDryRun() bool {
 	if m != nil {
-		return m.ID
+		return m.DryRun
 	}
-	return 0
+	return false
 }
 
-type AddMirrorReply struct {
-	Latitude             float32  `protobuf:"fixed32,1,opt,name=Latitude,proto3" json:"Latitude,omitempty"`
-	Longitude            float32  `protobuf:"fixed32,2,opt,name=Longitude,proto3" json:"Longitude,omitempty"`
-	Country              string   `protobuf:"bytes,3,opt,name=Country,proto3" json:"Country,omitempty"`
-	Continent            string   `protobuf:"bytes,4,opt,name=Continent,proto3" json:"Continent,omitempty"`
-	ASN                  string   `protobuf:"bytes,5,opt,name=ASN,proto3" json:"ASN,omitempty"`
-	Warnings             []string `protobuf:"bytes,6,rep,name=Warnings,proto3" json:"Warnings,omitempty"`
+type GCReply struct {
+	MirrorKeys           int64    `protobuf:"varint,1,opt,name=MirrorKeys,proto3" json:"MirrorKeys,omitempty"`
+	FileInfos            int64    `protobuf:"varint,2,opt,name=FileInfos,proto3" json:"FileInfos,omitempty"`
+	FileMirrors          int64    `protobuf:"varint,3,opt,name=FileMirrors,proto3" json:"FileMirrors,omitempty"`
+	FileMirrorEntries    int64    `protobuf:"varint,4,opt,name=FileMirrorEntries,proto3" json:"FileMirrorEntries,omitempty"`
+	TmpKeys              int64    `protobuf:"varint,5,opt,name=TmpKeys,proto3" json:"TmpKeys,omitempty"`
+	HandledFiles         int64    `protobuf:"varint,6,opt,name=HandledFiles,proto3" json:"HandledFiles,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *AddMirrorReply) Reset()         { *m = AddMirrorReply{} }
-func (m *AddMirrorReply) String() string { return proto.CompactTextString(m) }
-func (*AddMirrorReply) ProtoMessage()    {}
-func (*AddMirrorReply) Descriptor() ([]byte, []int) {
+func (m *GCReply) Reset()         { *m = GCReply{} }
+func (m *GCReply) String() string { return proto.CompactTextString(m) }
+func (*GCReply) ProtoMessage()    {}
+func (*GCReply) Descriptor() ([]byte, []int) {
 	return fileDescriptor_77a6da22d6a3feb1, []int{8}
 }
 
-func (m *AddMirrorReply) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_AddMirrorReply.Unmarshal(m, b)
+func (m *GCReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GCReply.Unmarshal(m, b)
 }
-func (m *AddMirrorReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_AddMirrorReply.Marshal(b, m, deterministic)
+func (m *GCReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GCReply.Marshal(b, m, deterministic)
 }
-func (m *AddMirrorReply) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_AddMirrorReply.Merge(m, src)
+func (m *GCReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GCReply.Merge(m, src)
 }
-func (m *AddMirrorReply) XXX_Size() int {
-	return xxx_messageInfo_AddMirrorReply.Size(m)
+func (m *GCReply) XXX_Size() int {
+	return xxx_messageInfo_GCReply.Size(m)
 }
-func (m *AddMirrorReply) XXX_DiscardUnknown() {
-	xxx_messageInfo_AddMirrorReply.DiscardUnknown(m)
+func (m *GCReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GCReply.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_AddMirrorReply proto.InternalMessageInfo
+var xxx_messageInfo_GCReply proto.InternalMessageInfo
 
-func (m *AddMirrorReply) GetLatitude() float32 {
+func (m *GCReply) GetMirrorKeys() int64 {
 	if m != nil {
-		return m.Latitude
+		return m.MirrorKeys
 	}
 	return 0
 }
 
-func (m *AddMirrorReply) GetLongitude() float32 {
+func (m *GCReply) GetFileInfos() int64 {
 	if m != nil {
-		return m.Longitude
+		return m.FileInfos
 	}
 	return 0
 }
 
-func (m *AddMirrorReply) GetCountry() string {
+func (m *GCReply) GetFileMirrors() int64 {
 	if m != nil {
-		return m.Country
+		return m.FileMirrors
 	}
-	return ""
+	return 0
 }
 
-func (m *AddMirrorReply) GetContinent() string {
+func (m *GCReply) GetFileMirrorEntries() int64 {
 	if m != nil {
-		return m.Continent
+		return m.FileMirrorEntries
 	}
-	return ""
+	return 0
 }
 
-func (m *AddMirrorReply) GetASN() string {
+func (m *GCReply) GetTmpKeys() int64 {
 	if m != nil {
-		return m.ASN
+		return m.TmpKeys
 	}
-	return ""
+	return 0
 }
 
-func (m *AddMirrorReply) GetWarnings() []string {
+func (m *GCReply) GetHandledFiles() int64 {
 	if m != nil {
-		return m.Warnings
+		return m.HandledFiles
 	}
-	return nil
+	return 0
 }
 
-type UpdateMirrorReply struct {
-	Diff                 string   `protobuf:"bytes,1,opt,name=Diff,proto3" json:"Diff,omitempty"`
+type DBInfoRequest struct {
+	Samples              int32    `protobuf:"varint,1,opt,name=Samples,proto3" json:"Samples,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *UpdateMirrorReply) Reset()         { *m = UpdateMirrorReply{} }
-func (m *UpdateMirrorReply) String() string { return proto.CompactTextString(m) }
-func (*UpdateMirrorReply) ProtoMessage()    {}
-func (*UpdateMirrorReply) Descriptor() ([]byte, []int) {
+func (m *DBInfoRequest) Reset()         { *m = DBInfoRequest{} }
+func (m *DBInfoRequest) String() string { return proto.CompactTextString(m) }
+func (*DBInfoRequest) ProtoMessage()    {}
+func (*DBInfoRequest) Descriptor() ([]byte, []int) {
 	return fileDescriptor_77a6da22d6a3feb1, []int{9}
 }
 
-func (m *UpdateMirrorReply) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_UpdateMirrorReply.Unmarshal(m, b)
+func (m *DBInfoRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DBInfoRequest.Unmarshal(m, b)
 }
-func (m *UpdateMirrorReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_UpdateMirrorReply.Marshal(b, m, deterministic)
+func (m *DBInfoRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DBInfoRequest.Marshal(b, m, deterministic)
 }
-func (m *UpdateMirrorReply) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_UpdateMirrorReply.Merge(m, src)
+func (m *DBInfoRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DBInfoRequest.Merge(m, src)
 }
-func (m *UpdateMirrorReply) XXX_Size() int {
-	return xxx_messageInfo_UpdateMirrorReply.Size(m)
+func (m *DBInfoRequest) XXX_Size() int {
+	return xxx_messageInfo_DBInfoRequest.Size(m)
 }
-func (m *UpdateMirrorReply) XXX_DiscardUnknown() {
-	xxx_messageInfo_UpdateMirrorReply.DiscardUnknown(m)
+func (m *DBInfoRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DBInfoRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_UpdateMirrorReply proto.InternalMessageInfo
+var xxx_messageInfo_DBInfoRequest proto.InternalMessageInfo
 
-func (m *UpdateMirrorReply) GetDiff() string {
+func (m *DBInfoRequest) GetSamples() int32 {
 	if m != nil {
-		return m.Diff
+		return m.Samples
 	}
-	return ""
+	return 0
 }
 
-type RefreshRepositoryRequest struct {
-	Rehash               bool     `protobuf:"varint,1,opt,name=Rehash,proto3" json:"Rehash,omitempty"`
+type KeyFamily struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Keys                 int64    `protobuf:"varint,2,opt,name=Keys,proto3" json:"Keys,omitempty"`
+	Sampled              int64    `protobuf:"varint,3,opt,name=Sampled,proto3" json:"Sampled,omitempty"`
+	Memory               int64    `protobuf:"varint,4,opt,name=Memory,proto3" json:"Memory,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *RefreshRepositoryRequest) Reset()         { *m = RefreshRepositoryRequest{} }
-func (m *RefreshRepositoryRequest) String() string { return proto.CompactTextString(m) }
-func (*RefreshRepositoryRequest) ProtoMessage()    {}
-func (*RefreshRepositoryRequest) Descriptor() ([]byte, []int) {
+func (m *KeyFamily) Reset()         { *m = KeyFamily{} }
+func (m *KeyFamily) String() string { return proto.CompactTextString(m) }
+func (*KeyFamily) ProtoMessage()    {}
+func (*KeyFamily) Descriptor() ([]byte, []int) {
 	return fileDescriptor_77a6da22d6a3feb1, []int{10}
 }
 
-func (m *RefreshRepositoryRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_RefreshRepositoryRequest.Unmarshal(m, b)
+func (m *KeyFamily) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyFamily.Unmarshal(m, b)
 }
-func (m *RefreshRepositoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_RefreshRepositoryRequest.Marshal(b, m, deterministic)
+func (m *KeyFamily) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyFamily.Marshal(b, m, deterministic)
 }
-func (m *RefreshRepositoryRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_RefreshRepositoryRequest.Merge(m, src)
+func (m *KeyFamily) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyFamily.Merge(m, src)
 }
-func (m *RefreshRepositoryRequest) XXX_Size() int {
-	return xxx_messageInfo_RefreshRepositoryRequest.Size(m)
+func (m *KeyFamily) XXX_Size() int {
+	return xxx_messageInfo_KeyFamily.Size(m)
 }
-func (m *RefreshRepositoryRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_RefreshRepositoryRequest.DiscardUnknown(m)
+func (m *KeyFamily) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyFamily.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_RefreshRepositoryRequest proto.InternalMessageInfo
+var xxx_messageInfo_KeyFamily proto.InternalMessageInfo
 
-func (m *RefreshRepositoryRequest) GetRehash() bool {
+func (m *KeyFamily) GetName() string {
 	if m != nil {
-		return m.Rehash
+		return m.Name
 	}
-	return false
+	return ""
 }
 
-type ScanMirrorRequest struct {
-	ID                   int32                    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	AutoEnable           bool                     `protobuf:"varint,2,opt,name=AutoEnable,proto3" json:"AutoEnable,omitempty"`
-	Protocol             ScanMirrorRequest_Method `protobuf:"varint,3,opt,name=Protocol,proto3,enum=ScanMirrorRequest_Method" json:"Protocol,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
-	XXX_unrecognized     []byte                   `json:"-"`
-	XXX_sizecache        int32                    `json:"-"`
+func (m *KeyFamily) GetKeys() int64 {
+	if m != nil {
+		return m.Keys
+	}
+	return 0
 }
 
-func (m *ScanMirrorRequest) Reset()         { *m = ScanMirrorRequest{} }
-func (m *ScanMirrorRequest) String() string { return proto.CompactTextString(m) }
-func (*ScanMirrorRequest) ProtoMessage()    {}
-func (*ScanMirrorRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77a6da22d6a3feb1, []int{11}
+func (m *KeyFamily) GetSampled() int64 {
+	if m != nil {
+		return m.Sampled
+	}
+	return 0
 }
 
-func (m *ScanMirrorRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ScanMirrorRequest.Unmarshal(m, b)
-}
-func (m *ScanMirrorRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ScanMirrorRequest.Marshal(b, m, deterministic)
+func (m *KeyFamily) GetMemory() int64 {
+	if m != nil {
+		return m.Memory
+	}
+	return 0
 }
-func (m *ScanMirrorRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ScanMirrorRequest.Merge(m, src)
+
+type DBInfoReply struct {
+	Families             []*KeyFamily `protobuf:"bytes,1,rep,name=Families,proto3" json:"Families,omitempty"`
+	Keys                 int64        `protobuf:"varint,2,opt,name=Keys,proto3" json:"Keys,omitempty"`
+	UsedMemory           int64        `protobuf:"varint,3,opt,name=UsedMemory,proto3" json:"UsedMemory,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
 }
-func (m *ScanMirrorRequest) XXX_Size() int {
-	return xxx_messageInfo_ScanMirrorRequest.Size(m)
+
+func (m *DBInfoReply) Reset()         { *m = DBInfoReply{} }
+func (m *DBInfoReply) String() string { return proto.CompactTextString(m) }
+func (*DBInfoReply) ProtoMessage()    {}
+func (*DBInfoReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{11}
 }
-func (m *ScanMirrorRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_ScanMirrorRequest.DiscardUnknown(m)
+
+func (m *DBInfoReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DBInfoReply.Unmarshal(m, b)
+}
+func (m *DBInfoReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DBInfoReply.Marshal(b, m, deterministic)
+}
+func (m *DBInfoReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DBInfoReply.Merge(m, src)
+}
+func (m *DBInfoReply) XXX_Size() int {
+	return xxx_messageInfo_DBInfoReply.Size(m)
+}
+func (m *DBInfoReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_DBInfoReply.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ScanMirrorRequest proto.InternalMessageInfo
+var xxx_messageInfo_DBInfoReply proto.InternalMessageInfo
 
-func (m *ScanMirrorRequest) GetID() int32 {
+func (m *DBInfoReply) GetFamilies() []*KeyFamily {
 	if m != nil {
-		return m.ID
+		return m.Families
 	}
-	return 0
+	return nil
 }
 
-func (m *ScanMirrorRequest) GetAutoEnable() bool {
+func (m *DBInfoReply) GetKeys() int64 {
 	if m != nil {
-		return m.AutoEnable
+		return m.Keys
 	}
-	return false
+	return 0
 }
 
-func (m *ScanMirrorRequest) GetProtocol() ScanMirrorRequest_Method {
+func (m *DBInfoReply) GetUsedMemory() int64 {
 	if m != nil {
-		return m.Protocol
+		return m.UsedMemory
 	}
-	return ScanMirrorRequest_ALL
+	return 0
 }
 
-type ScanMirrorReply struct {
-	Enabled              bool     `protobuf:"varint,1,opt,name=Enabled,proto3" json:"Enabled,omitempty"`
-	FilesIndexed         int64    `protobuf:"varint,2,opt,name=FilesIndexed,proto3" json:"FilesIndexed,omitempty"`
-	KnownIndexed         int64    `protobuf:"varint,3,opt,name=KnownIndexed,proto3" json:"KnownIndexed,omitempty"`
-	Removed              int64    `protobuf:"varint,4,opt,name=Removed,proto3" json:"Removed,omitempty"`
-	TZOffsetMs           int64    `protobuf:"varint,5,opt,name=TZOffsetMs,proto3" json:"TZOffsetMs,omitempty"`
+type MatchRequest struct {
+	Pattern              string   `protobuf:"bytes,1,opt,name=Pattern,proto3" json:"Pattern,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ScanMirrorReply) Reset()         { *m = ScanMirrorReply{} }
-func (m *ScanMirrorReply) String() string { return proto.CompactTextString(m) }
-func (*ScanMirrorReply) ProtoMessage()    {}
-func (*ScanMirrorReply) Descriptor() ([]byte, []int) {
+func (m *MatchRequest) Reset()         { *m = MatchRequest{} }
+func (m *MatchRequest) String() string { return proto.CompactTextString(m) }
+func (*MatchRequest) ProtoMessage()    {}
+func (*MatchRequest) Descriptor() ([]byte, []int) {
 	return fileDescriptor_77a6da22d6a3feb1, []int{12}
 }
 
-func (m *ScanMirrorReply) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ScanMirrorReply.Unmarshal(m, b)
+func (m *MatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MatchRequest.Unmarshal(m, b)
 }
-func (m *ScanMirrorReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ScanMirrorReply.Marshal(b, m, deterministic)
+func (m *MatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MatchRequest.Marshal(b, m, deterministic)
 }
-func (m *ScanMirrorReply) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ScanMirrorReply.Merge(m, src)
+func (m *MatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MatchRequest.Merge(m, src)
 }
-func (m *ScanMirrorReply) XXX_Size() int {
-	return xxx_messageInfo_ScanMirrorReply.Size(m)
+func (m *MatchRequest) XXX_Size() int {
+	return xxx_messageInfo_MatchRequest.Size(m)
 }
-func (m *ScanMirrorReply) XXX_DiscardUnknown() {
-	xxx_messageInfo_ScanMirrorReply.DiscardUnknown(m)
+func (m *MatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MatchRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ScanMirrorReply proto.InternalMessageInfo
+var xxx_messageInfo_MatchRequest proto.InternalMessageInfo
 
-func (m *ScanMirrorReply) GetEnabled() bool {
+func (m *MatchRequest) GetPattern() string {
 	if m != nil {
-		return m.Enabled
+		return m.Pattern
 	}
-	return false
+	return ""
 }
 
-func (m *ScanMirrorReply) GetFilesIndexed() int64 {
+type Mirror struct {
+	ID                   int32                `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Name                 string               `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	HttpURL              string               `protobuf:"bytes,3,opt,name=HttpURL,proto3" json:"HttpURL,omitempty"`
+	RsyncURL             string               `protobuf:"bytes,4,opt,name=RsyncURL,proto3" json:"RsyncURL,omitempty"`
+	FtpURL               string               `protobuf:"bytes,5,opt,name=FtpURL,proto3" json:"FtpURL,omitempty"`
+	SponsorName          string               `protobuf:"bytes,6,opt,name=SponsorName,proto3" json:"SponsorName,omitempty"`
+	SponsorURL           string               `protobuf:"bytes,7,opt,name=SponsorURL,proto3" json:"SponsorURL,omitempty"`
+	SponsorLogoURL       string               `protobuf:"bytes,8,opt,name=SponsorLogoURL,proto3" json:"SponsorLogoURL,omitempty"`
+	AdminName            string               `protobuf:"bytes,9,opt,name=AdminName,proto3" json:"AdminName,omitempty"`
+	AdminEmail           string               `protobuf:"bytes,10,opt,name=AdminEmail,proto3" json:"AdminEmail,omitempty"`
+	CustomData           string               `protobuf:"bytes,11,opt,name=CustomData,proto3" json:"CustomData,omitempty"`
+	ContinentOnly        bool                 `protobuf:"varint,12,opt,name=ContinentOnly,proto3" json:"ContinentOnly,omitempty"`
+	CountryOnly          bool                 `protobuf:"varint,13,opt,name=CountryOnly,proto3" json:"CountryOnly,omitempty"`
+	ASOnly               bool                 `protobuf:"varint,14,opt,name=ASOnly,proto3" json:"ASOnly,omitempty"`
+	Score                int32                `protobuf:"varint,15,opt,name=Score,proto3" json:"Score,omitempty"`
+	Latitude             float32              `protobuf:"fixed32,16,opt,name=Latitude,proto3" json:"Latitude,omitempty"`
+	Longitude            float32              `protobuf:"fixed32,17,opt,name=Longitude,proto3" json:"Longitude,omitempty"`
+	ContinentCode        string               `protobuf:"bytes,18,opt,name=ContinentCode,proto3" json:"ContinentCode,omitempty"`
+	CountryCodes         []string             `protobuf:"bytes,19,rep,name=CountryCodes,proto3" json:"CountryCodes,omitempty"`
+	ExcludedCountryCodes []string             `protobuf:"bytes,20,rep,name=ExcludedCountryCodes,proto3" json:"ExcludedCountryCodes,omitempty"`
+	Asnum                uint32               `protobuf:"varint,21,opt,name=Asnum,proto3" json:"Asnum,omitempty"`
+	Comment              string               `protobuf:"bytes,22,opt,name=Comment,proto3" json:"Comment,omitempty"`
+	Enabled              bool                 `protobuf:"varint,23,opt,name=Enabled,proto3" json:"Enabled,omitempty"`
+	Up                   bool                 `protobuf:"varint,24,opt,name=Up,proto3" json:"Up,omitempty"`
+	ExcludeReason        string               `protobuf:"bytes,25,opt,name=ExcludeReason,proto3" json:"ExcludeReason,omitempty"`
+	StateSince           *timestamp.Timestamp `protobuf:"bytes,26,opt,name=StateSince,proto3" json:"StateSince,omitempty"`
+	AllowRedirects       int32                `protobuf:"varint,27,opt,name=AllowRedirects,proto3" json:"AllowRedirects,omitempty"`
+	LastSync             *timestamp.Timestamp `protobuf:"bytes,28,opt,name=LastSync,proto3" json:"LastSync,omitempty"`
+	LastSuccessfulSync   *timestamp.Timestamp `protobuf:"bytes,29,opt,name=LastSuccessfulSync,proto3" json:"LastSuccessfulSync,omitempty"`
+	LastModTime          *timestamp.Timestamp `protobuf:"bytes,30,opt,name=LastModTime,proto3" json:"LastModTime,omitempty"`
+	IPv4                 bool                 `protobuf:"varint,31,opt,name=IPv4,proto3" json:"IPv4,omitempty"`
+	IPv6                 bool                 `protobuf:"varint,32,opt,name=IPv6,proto3" json:"IPv6,omitempty"`
+	IPAddress            string               `protobuf:"bytes,33,opt,name=IPAddress,proto3" json:"IPAddress,omitempty"`
+	LocationWarning      string               `protobuf:"bytes,34,opt,name=LocationWarning,proto3" json:"LocationWarning,omitempty"`
+	CDN                  bool                 `protobuf:"varint,35,opt,name=CDN,proto3" json:"CDN,omitempty"`
+	Channels             []string             `protobuf:"bytes,36,rep,name=Channels,proto3" json:"Channels,omitempty"`
+	MovedTo              string               `protobuf:"bytes,37,opt,name=MovedTo,proto3" json:"MovedTo,omitempty"`
+	PathRewrites         []*PathRewrite       `protobuf:"bytes,38,rep,name=PathRewrites,proto3" json:"PathRewrites,omitempty"`
+	ManualLocation       bool                 `protobuf:"varint,39,opt,name=ManualLocation,proto3" json:"ManualLocation,omitempty"`
+	Endpoints            []*Endpoint          `protobuf:"bytes,40,rep,name=Endpoints,proto3" json:"Endpoints,omitempty"`
+	EndpointsDown        []string             `protobuf:"bytes,41,rep,name=EndpointsDown,proto3" json:"EndpointsDown,omitempty"`
+	RegionCode           string               `protobuf:"bytes,42,opt,name=RegionCode,proto3" json:"RegionCode,omitempty"`
+	RegionOnly           bool                 `protobuf:"varint,43,opt,name=RegionOnly,proto3" json:"RegionOnly,omitempty"`
+	Schedule             *Schedule            `protobuf:"bytes,44,opt,name=Schedule,proto3" json:"Schedule,omitempty"`
+	Probe                *Probe               `protobuf:"bytes,45,opt,name=Probe,proto3" json:"Probe,omitempty"`
+	Proxy                string               `protobuf:"bytes,46,opt,name=Proxy,proto3" json:"Proxy,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Mirror) Reset()         { *m = Mirror{} }
+func (m *Mirror) String() string { return proto.CompactTextString(m) }
+func (*Mirror) ProtoMessage()    {}
+func (*Mirror) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{13}
+}
+
+func (m *Mirror) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Mirror.Unmarshal(m, b)
+}
+func (m *Mirror) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Mirror.Marshal(b, m, deterministic)
+}
+func (m *Mirror) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Mirror.Merge(m, src)
+}
+func (m *Mirror) XXX_Size() int {
+	return xxx_messageInfo_Mirror.Size(m)
+}
+func (m *Mirror) XXX_DiscardUnknown() {
+	xxx_messageInfo_Mirror.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Mirror proto.InternalMessageInfo
+
+func (m *Mirror) GetID() int32 {
 	if m != nil {
-		return m.FilesIndexed
+		return m.ID
 	}
 	return 0
 }
 
-func (m *ScanMirrorReply) GetKnownIndexed() int64 {
+func (m *Mirror) GetName() string {
 	if m != nil {
-		return m.KnownIndexed
+		return m.Name
 	}
-	return 0
+	return ""
 }
 
-func (m *ScanMirrorReply) GetRemoved() int64 {
+func (m *Mirror) GetHttpURL() string {
 	if m != nil {
-		return m.Removed
+		return m.HttpURL
 	}
-	return 0
+	return ""
 }
 
-func (m *ScanMirrorReply) GetTZOffsetMs() int64 {
+func (m *Mirror) GetRsyncURL() string {
 	if m != nil {
-		return m.TZOffsetMs
+		return m.RsyncURL
 	}
-	return 0
+	return ""
 }
 
-type StatsFileRequest struct {
-	Pattern              string               `protobuf:"bytes,1,opt,name=Pattern,proto3" json:"Pattern,omitempty"`
-	DateStart            *timestamp.Timestamp `protobuf:"bytes,2,opt,name=DateStart,proto3" json:"DateStart,omitempty"`
-	DateEnd              *timestamp.Timestamp `protobuf:"bytes,3,opt,name=DateEnd,proto3" json:"DateEnd,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+func (m *Mirror) GetFtpURL() string {
+	if m != nil {
+		return m.FtpURL
+	}
+	return ""
 }
 
-func (m *StatsFileRequest) Reset()         { *m = StatsFileRequest{} }
-func (m *StatsFileRequest) String() string { return proto.CompactTextString(m) }
-func (*StatsFileRequest) ProtoMessage()    {}
-func (*StatsFileRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77a6da22d6a3feb1, []int{13}
+func (m *Mirror) GetSponsorName() string {
+	if m != nil {
+		return m.SponsorName
+	}
+	return ""
 }
 
-func (m *StatsFileRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_StatsFileRequest.Unmarshal(m, b)
-}
-func (m *StatsFileRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_StatsFileRequest.Marshal(b, m, deterministic)
-}
-func (m *StatsFileRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_StatsFileRequest.Merge(m, src)
-}
-func (m *StatsFileRequest) XXX_Size() int {
-	return xxx_messageInfo_StatsFileRequest.Size(m)
-}
-func (m *StatsFileRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_StatsFileRequest.DiscardUnknown(m)
+func (m *Mirror) GetSponsorURL() string {
+	if m != nil {
+		return m.SponsorURL
+	}
+	return ""
 }
 
-var xxx_messageInfo_StatsFileRequest proto.InternalMessageInfo
-
-func (m *StatsFileRequest) GetPattern() string {
+func (m *Mirror) GetSponsorLogoURL() string {
 	if m != nil {
-		return m.Pattern
+		return m.SponsorLogoURL
 	}
 	return ""
 }
 
-func (m *StatsFileRequest) GetDateStart() *timestamp.Timestamp {
+func (m *Mirror) GetAdminName() string {
 	if m != nil {
-		return m.DateStart
+		return m.AdminName
 	}
-	return nil
+	return ""
 }
 
-func (m *StatsFileRequest) GetDateEnd() *timestamp.Timestamp {
+func (m *Mirror) GetAdminEmail() string {
 	if m != nil {
-		return m.DateEnd
+		return m.AdminEmail
 	}
-	return nil
+	return ""
 }
 
-type StatsFileReply struct {
-	Files                map[string]int64 `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
-	XXX_unrecognized     []byte           `json:"-"`
-	XXX_sizecache        int32            `json:"-"`
+func (m *Mirror) GetCustomData() string {
+	if m != nil {
+		return m.CustomData
+	}
+	return ""
 }
 
-func (m *StatsFileReply) Reset()         { *m = StatsFileReply{} }
-func (m *StatsFileReply) String() string { return proto.CompactTextString(m) }
-func (*StatsFileReply) ProtoMessage()    {}
-func (*StatsFileReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77a6da22d6a3feb1, []int{14}
+func (m *Mirror) GetContinentOnly() bool {
+	if m != nil {
+		return m.ContinentOnly
+	}
+	return false
 }
 
-func (m *StatsFileReply) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_StatsFileReply.Unmarshal(m, b)
-}
-func (m *StatsFileReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_StatsFileReply.Marshal(b, m, deterministic)
-}
-func (m *StatsFileReply) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_StatsFileReply.Merge(m, src)
-}
-func (m *StatsFileReply) XXX_Size() int {
-	return xxx_messageInfo_StatsFileReply.Size(m)
-}
-func (m *StatsFileReply) XXX_DiscardUnknown() {
-	xxx_messageInfo_StatsFileReply.DiscardUnknown(m)
+func (m *Mirror) GetCountryOnly() bool {
+	if m != nil {
+		return m.CountryOnly
+	}
+	return false
 }
 
-var xxx_messageInfo_StatsFileReply proto.InternalMessageInfo
-
-func (m *StatsFileReply) GetFiles() map[string]int64 {
+func (m *Mirror) GetASOnly() bool {
 	if m != nil {
-		return m.Files
+		return m.ASOnly
 	}
-	return nil
+	return false
 }
 
-type StatsMirrorRequest struct {
-	ID                   int32                `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	DateStart            *timestamp.Timestamp `protobuf:"bytes,2,opt,name=DateStart,proto3" json:"DateStart,omitempty"`
-	DateEnd              *timestamp.Timestamp `protobuf:"bytes,3,opt,name=DateEnd,proto3" json:"DateEnd,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+func (m *Mirror) GetScore() int32 {
+	if m != nil {
+		return m.Score
+	}
+	return 0
 }
 
-func (m *StatsMirrorRequest) Reset()         { *m = StatsMirrorRequest{} }
-func (m *StatsMirrorRequest) String() string { return proto.CompactTextString(m) }
-func (*StatsMirrorRequest) ProtoMessage()    {}
-func (*StatsMirrorRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77a6da22d6a3feb1, []int{15}
+func (m *Mirror) GetLatitude() float32 {
+	if m != nil {
+		return m.Latitude
+	}
+	return 0
 }
 
-func (m *StatsMirrorRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_StatsMirrorRequest.Unmarshal(m, b)
+func (m *Mirror) GetLongitude() float32 {
+	if m != nil {
+		return m.Longitude
+	}
+	return 0
 }
-func (m *StatsMirrorRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_StatsMirrorRequest.Marshal(b, m, deterministic)
+
+func (m *Mirror) GetContinentCode() string {
+	if m != nil {
+		return m.ContinentCode
+	}
+	return ""
 }
-func (m *StatsMirrorRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_StatsMirrorRequest.Merge(m, src)
+
+func (m *Mirror) GetCountryCodes() []string {
+	if m != nil {
+		return m.CountryCodes
+	}
+	return nil
 }
-func (m *StatsMirrorRequest) XXX_Size() int {
-	return xxx_messageInfo_StatsMirrorRequest.Size(m)
+
+func (m *Mirror) GetExcludedCountryCodes() []string {
+	if m != nil {
+		return m.ExcludedCountryCodes
+	}
+	return nil
 }
-func (m *StatsMirrorRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_StatsMirrorRequest.DiscardUnknown(m)
+
+func (m *Mirror) GetAsnum() uint32 {
+	if m != nil {
+		return m.Asnum
+	}
+	return 0
 }
 
-var xxx_messageInfo_StatsMirrorRequest proto.InternalMessageInfo
+func (m *Mirror) GetComment() string {
+	if m != nil {
+		return m.Comment
+	}
+	return ""
+}
 
-func (m *StatsMirrorRequest) GetID() int32 {
+func (m *Mirror) GetEnabled() bool {
 	if m != nil {
-		return m.ID
+		return m.Enabled
 	}
-	return 0
+	return false
 }
 
-func (m *StatsMirrorRequest) GetDateStart() *timestamp.Timestamp {
+func (m *Mirror) GetUp() bool {
 	if m != nil {
-		return m.DateStart
+		return m.Up
 	}
-	return nil
+	return false
 }
 
-func (m *StatsMirrorRequest) GetDateEnd() *timestamp.Timestamp {
+func (m *Mirror) GetExcludeReason() string {
 	if m != nil {
-		return m.DateEnd
+		return m.ExcludeReason
 	}
-	return nil
+	return ""
 }
 
-type StatsMirrorReply struct {
-	Mirror               *Mirror  `protobuf:"bytes,1,opt,name=Mirror,proto3" json:"Mirror,omitempty"`
-	Requests             int64    `protobuf:"varint,2,opt,name=Requests,proto3" json:"Requests,omitempty"`
-	Bytes                int64    `protobuf:"varint,3,opt,name=Bytes,proto3" json:"Bytes,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *Mirror) GetStateSince() *timestamp.Timestamp {
+	if m != nil {
+		return m.StateSince
+	}
+	return nil
 }
 
-func (m *StatsMirrorReply) Reset()         { *m = StatsMirrorReply{} }
-func (m *StatsMirrorReply) String() string { return proto.CompactTextString(m) }
-func (*StatsMirrorReply) ProtoMessage()    {}
-func (*StatsMirrorReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77a6da22d6a3feb1, []int{16}
+func (m *Mirror) GetAllowRedirects() int32 {
+	if m != nil {
+		return m.AllowRedirects
+	}
+	return 0
 }
 
-func (m *StatsMirrorReply) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_StatsMirrorReply.Unmarshal(m, b)
+func (m *Mirror) GetLastSync() *timestamp.Timestamp {
+	if m != nil {
+		return m.LastSync
+	}
+	return nil
 }
-func (m *StatsMirrorReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_StatsMirrorReply.Marshal(b, m, deterministic)
+
+func (m *Mirror) GetLastSuccessfulSync() *timestamp.Timestamp {
+	if m != nil {
+		return m.LastSuccessfulSync
+	}
+	return nil
 }
-func (m *StatsMirrorReply) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_StatsMirrorReply.Merge(m, src)
+
+func (m *Mirror) GetLastModTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.LastModTime
+	}
+	return nil
 }
-func (m *StatsMirrorReply) XXX_Size() int {
-	return xxx_messageInfo_StatsMirrorReply.Size(m)
+
+func (m *Mirror) GetIPv4() bool {
+	if m != nil {
+		return m.IPv4
+	}
+	return false
 }
-func (m *StatsMirrorReply) XXX_DiscardUnknown() {
-	xxx_messageInfo_StatsMirrorReply.DiscardUnknown(m)
+
+func (m *Mirror) GetIPv6() bool {
+	if m != nil {
+		return m.IPv6
+	}
+	return false
 }
 
-var xxx_messageInfo_StatsMirrorReply proto.InternalMessageInfo
+func (m *Mirror) GetIPAddress() string {
+	if m != nil {
+		return m.IPAddress
+	}
+	return ""
+}
 
-func (m *StatsMirrorReply) GetMirror() *Mirror {
+func (m *Mirror) GetLocationWarning() string {
 	if m != nil {
-		return m.Mirror
+		return m.LocationWarning
 	}
-	return nil
+	return ""
 }
 
-func (m *StatsMirrorReply) GetRequests() int64 {
+func (m *Mirror) GetCDN() bool {
 	if m != nil {
-		return m.Requests
+		return m.CDN
 	}
-	return 0
+	return false
 }
 
-func (m *StatsMirrorReply) GetBytes() int64 {
+func (m *Mirror) GetChannels() []string {
 	if m != nil {
-		return m.Bytes
+		return m.Channels
 	}
-	return 0
+	return nil
 }
 
-type GetMirrorLogsRequest struct {
-	ID                   int32    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	MaxResults           int32    `protobuf:"varint,2,opt,name=MaxResults,proto3" json:"MaxResults,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *Mirror) GetMovedTo() string {
+	if m != nil {
+		return m.MovedTo
+	}
+	return ""
 }
 
-func (m *GetMirrorLogsRequest) Reset()         { *m = GetMirrorLogsRequest{} }
-func (m *GetMirrorLogsRequest) String() string { return proto.CompactTextString(m) }
-func (*GetMirrorLogsRequest) ProtoMessage()    {}
-func (*GetMirrorLogsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77a6da22d6a3feb1, []int{17}
+func (m *Mirror) GetPathRewrites() []*PathRewrite {
+	if m != nil {
+		return m.PathRewrites
+	}
+	return nil
 }
 
-func (m *GetMirrorLogsRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetMirrorLogsRequest.Unmarshal(m, b)
+func (m *Mirror) GetManualLocation() bool {
+	if m != nil {
+		return m.ManualLocation
+	}
+	return false
 }
-func (m *GetMirrorLogsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetMirrorLogsRequest.Marshal(b, m, deterministic)
+
+func (m *Mirror) GetEndpoints() []*Endpoint {
+	if m != nil {
+		return m.Endpoints
+	}
+	return nil
 }
-func (m *GetMirrorLogsRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetMirrorLogsRequest.Merge(m, src)
+
+func (m *Mirror) GetEndpointsDown() []string {
+	if m != nil {
+		return m.EndpointsDown
+	}
+	return nil
 }
-func (m *GetMirrorLogsRequest) XXX_Size() int {
-	return xxx_messageInfo_GetMirrorLogsRequest.Size(m)
+
+func (m *Mirror) GetRegionCode() string {
+	if m != nil {
+		return m.RegionCode
+	}
+	return ""
 }
-func (m *GetMirrorLogsRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetMirrorLogsRequest.DiscardUnknown(m)
+
+func (m *Mirror) GetRegionOnly() bool {
+	if m != nil {
+		return m.RegionOnly
+	}
+	return false
 }
 
-var xxx_messageInfo_GetMirrorLogsRequest proto.InternalMessageInfo
+func (m *Mirror) GetSchedule() *Schedule {
+	if m != nil {
+		return m.Schedule
+	}
+	return nil
+}
 
-func (m *GetMirrorLogsRequest) GetID() int32 {
+func (m *Mirror) GetProbe() *Probe {
 	if m != nil {
-		return m.ID
+		return m.Probe
 	}
-	return 0
+	return nil
 }
 
-func (m *GetMirrorLogsRequest) GetMaxResults() int32 {
+func (m *Mirror) GetProxy() string {
 	if m != nil {
-		return m.MaxResults
+		return m.Proxy
 	}
-	return 0
+	return ""
 }
 
-type GetMirrorLogsReply struct {
-	Line                 []string `protobuf:"bytes,1,rep,name=line,proto3" json:"line,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type Probe struct {
+	Headers              map[string]string `protobuf:"bytes,1,rep,name=Headers,proto3" json:"Headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Username             string            `protobuf:"bytes,2,opt,name=Username,proto3" json:"Username,omitempty"`
+	Password             string            `protobuf:"bytes,3,opt,name=Password,proto3" json:"Password,omitempty"`
+	ExpectedStatus       []int32           `protobuf:"varint,4,rep,packed,name=ExpectedStatus,proto3" json:"ExpectedStatus,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
-func (m *GetMirrorLogsReply) Reset()         { *m = GetMirrorLogsReply{} }
-func (m *GetMirrorLogsReply) String() string { return proto.CompactTextString(m) }
-func (*GetMirrorLogsReply) ProtoMessage()    {}
-func (*GetMirrorLogsReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_77a6da22d6a3feb1, []int{18}
+func (m *Probe) Reset()         { *m = Probe{} }
+func (m *Probe) String() string { return proto.CompactTextString(m) }
+func (*Probe) ProtoMessage()    {}
+func (*Probe) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{14}
 }
 
-func (m *GetMirrorLogsReply) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetMirrorLogsReply.Unmarshal(m, b)
+func (m *Probe) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Probe.Unmarshal(m, b)
 }
-func (m *GetMirrorLogsReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetMirrorLogsReply.Marshal(b, m, deterministic)
+func (m *Probe) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Probe.Marshal(b, m, deterministic)
 }
-func (m *GetMirrorLogsReply) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetMirrorLogsReply.Merge(m, src)
+func (m *Probe) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Probe.Merge(m, src)
 }
-func (m *GetMirrorLogsReply) XXX_Size() int {
-	return xxx_messageInfo_GetMirrorLogsReply.Size(m)
+func (m *Probe) XXX_Size() int {
+	return xxx_messageInfo_Probe.Size(m)
 }
-func (m *GetMirrorLogsReply) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetMirrorLogsReply.DiscardUnknown(m)
+func (m *Probe) XXX_DiscardUnknown() {
+	xxx_messageInfo_Probe.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetMirrorLogsReply proto.InternalMessageInfo
+var xxx_messageInfo_Probe proto.InternalMessageInfo
 
-func (m *GetMirrorLogsReply) GetLine() []string {
+func (m *Probe) GetHeaders() map[string]string {
 	if m != nil {
-		return m.Line
+		return m.Headers
 	}
 	return nil
 }
 
-func init() {
-	proto.RegisterEnum("ScanMirrorRequest_Method", ScanMirrorRequest_Method_name, ScanMirrorRequest_Method_value)
-	proto.RegisterType((*VersionReply)(nil), "VersionReply")
-	proto.RegisterType((*MatchRequest)(nil), "MatchRequest")
-	proto.RegisterType((*Mirror)(nil), "Mirror")
-	proto.RegisterType((*MirrorListReply)(nil), "MirrorListReply")
-	proto.RegisterType((*MirrorID)(nil), "MirrorID")
-	proto.RegisterType((*MatchReply)(nil), "MatchReply")
-	proto.RegisterType((*ChangeStatusRequest)(nil), "ChangeStatusRequest")
-	proto.RegisterType((*MirrorIDRequest)(nil), "MirrorIDRequest")
-	proto.RegisterType((*AddMirrorReply)(nil), "AddMirrorReply")
-	proto.RegisterType((*UpdateMirrorReply)(nil), "UpdateMirrorReply")
-	proto.RegisterType((*RefreshRepositoryRequest)(nil), "RefreshRepositoryRequest")
-	proto.RegisterType((*ScanMirrorRequest)(nil), "ScanMirrorRequest")
-	proto.RegisterType((*ScanMirrorReply)(nil), "ScanMirrorReply")
-	proto.RegisterType((*StatsFileRequest)(nil), "StatsFileRequest")
+func (m *Probe) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *Probe) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
+func (m *Probe) GetExpectedStatus() []int32 {
+	if m != nil {
+		return m.ExpectedStatus
+	}
+	return nil
+}
+
+type Schedule struct {
+	TimeZone             string            `protobuf:"bytes,1,opt,name=TimeZone,proto3" json:"TimeZone,omitempty"`
+	Default              int32             `protobuf:"varint,2,opt,name=Default,proto3" json:"Default,omitempty"`
+	Periods              []*SchedulePeriod `protobuf:"bytes,3,rep,name=Periods,proto3" json:"Periods,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Schedule) Reset()         { *m = Schedule{} }
+func (m *Schedule) String() string { return proto.CompactTextString(m) }
+func (*Schedule) ProtoMessage()    {}
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{15}
+}
+
+func (m *Schedule) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Schedule.Unmarshal(m, b)
+}
+func (m *Schedule) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Schedule.Marshal(b, m, deterministic)
+}
+func (m *Schedule) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Schedule.Merge(m, src)
+}
+func (m *Schedule) XXX_Size() int {
+	return xxx_messageInfo_Schedule.Size(m)
+}
+func (m *Schedule) XXX_DiscardUnknown() {
+	xxx_messageInfo_Schedule.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Schedule proto.InternalMessageInfo
+
+func (m *Schedule) GetTimeZone() string {
+	if m != nil {
+		return m.TimeZone
+	}
+	return ""
+}
+
+func (m *Schedule) GetDefault() int32 {
+	if m != nil {
+		return m.Default
+	}
+	return 0
+}
+
+func (m *Schedule) GetPeriods() []*SchedulePeriod {
+	if m != nil {
+		return m.Periods
+	}
+	return nil
+}
+
+type SchedulePeriod struct {
+	Days                 []string `protobuf:"bytes,1,rep,name=Days,proto3" json:"Days,omitempty"`
+	From                 string   `protobuf:"bytes,2,opt,name=From,proto3" json:"From,omitempty"`
+	To                   string   `protobuf:"bytes,3,opt,name=To,proto3" json:"To,omitempty"`
+	Share                int32    `protobuf:"varint,4,opt,name=Share,proto3" json:"Share,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SchedulePeriod) Reset()         { *m = SchedulePeriod{} }
+func (m *SchedulePeriod) String() string { return proto.CompactTextString(m) }
+func (*SchedulePeriod) ProtoMessage()    {}
+func (*SchedulePeriod) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{16}
+}
+
+func (m *SchedulePeriod) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SchedulePeriod.Unmarshal(m, b)
+}
+func (m *SchedulePeriod) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SchedulePeriod.Marshal(b, m, deterministic)
+}
+func (m *SchedulePeriod) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SchedulePeriod.Merge(m, src)
+}
+func (m *SchedulePeriod) XXX_Size() int {
+	return xxx_messageInfo_SchedulePeriod.Size(m)
+}
+func (m *SchedulePeriod) XXX_DiscardUnknown() {
+	xxx_messageInfo_SchedulePeriod.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SchedulePeriod proto.InternalMessageInfo
+
+func (m *SchedulePeriod) GetDays() []string {
+	if m != nil {
+		return m.Days
+	}
+	return nil
+}
+
+func (m *SchedulePeriod) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *SchedulePeriod) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+func (m *SchedulePeriod) GetShare() int32 {
+	if m != nil {
+		return m.Share
+	}
+	return 0
+}
+
+type Endpoint struct {
+	HttpURL              string   `protobuf:"bytes,1,opt,name=HttpURL,proto3" json:"HttpURL,omitempty"`
+	Latitude             float32  `protobuf:"fixed32,2,opt,name=Latitude,proto3" json:"Latitude,omitempty"`
+	Longitude            float32  `protobuf:"fixed32,3,opt,name=Longitude,proto3" json:"Longitude,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Endpoint) Reset()         { *m = Endpoint{} }
+func (m *Endpoint) String() string { return proto.CompactTextString(m) }
+func (*Endpoint) ProtoMessage()    {}
+func (*Endpoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{17}
+}
+
+func (m *Endpoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Endpoint.Unmarshal(m, b)
+}
+func (m *Endpoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Endpoint.Marshal(b, m, deterministic)
+}
+func (m *Endpoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Endpoint.Merge(m, src)
+}
+func (m *Endpoint) XXX_Size() int {
+	return xxx_messageInfo_Endpoint.Size(m)
+}
+func (m *Endpoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_Endpoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Endpoint proto.InternalMessageInfo
+
+func (m *Endpoint) GetHttpURL() string {
+	if m != nil {
+		return m.HttpURL
+	}
+	return ""
+}
+
+func (m *Endpoint) GetLatitude() float32 {
+	if m != nil {
+		return m.Latitude
+	}
+	return 0
+}
+
+func (m *Endpoint) GetLongitude() float32 {
+	if m != nil {
+		return m.Longitude
+	}
+	return 0
+}
+
+type PathRewrite struct {
+	From                 string   `protobuf:"bytes,1,opt,name=From,proto3" json:"From,omitempty"`
+	To                   string   `protobuf:"bytes,2,opt,name=To,proto3" json:"To,omitempty"`
+	Regexp               bool     `protobuf:"varint,3,opt,name=Regexp,proto3" json:"Regexp,omitempty"`
+	Scan                 bool     `protobuf:"varint,4,opt,name=Scan,proto3" json:"Scan,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PathRewrite) Reset()         { *m = PathRewrite{} }
+func (m *PathRewrite) String() string { return proto.CompactTextString(m) }
+func (*PathRewrite) ProtoMessage()    {}
+func (*PathRewrite) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{18}
+}
+
+func (m *PathRewrite) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PathRewrite.Unmarshal(m, b)
+}
+func (m *PathRewrite) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PathRewrite.Marshal(b, m, deterministic)
+}
+func (m *PathRewrite) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PathRewrite.Merge(m, src)
+}
+func (m *PathRewrite) XXX_Size() int {
+	return xxx_messageInfo_PathRewrite.Size(m)
+}
+func (m *PathRewrite) XXX_DiscardUnknown() {
+	xxx_messageInfo_PathRewrite.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PathRewrite proto.InternalMessageInfo
+
+func (m *PathRewrite) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *PathRewrite) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+func (m *PathRewrite) GetRegexp() bool {
+	if m != nil {
+		return m.Regexp
+	}
+	return false
+}
+
+func (m *PathRewrite) GetScan() bool {
+	if m != nil {
+		return m.Scan
+	}
+	return false
+}
+
+type MirrorListReply struct {
+	Mirrors              []*Mirror `protobuf:"bytes,1,rep,name=Mirrors,proto3" json:"Mirrors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *MirrorListReply) Reset()         { *m = MirrorListReply{} }
+func (m *MirrorListReply) String() string { return proto.CompactTextString(m) }
+func (*MirrorListReply) ProtoMessage()    {}
+func (*MirrorListReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{19}
+}
+
+func (m *MirrorListReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MirrorListReply.Unmarshal(m, b)
+}
+func (m *MirrorListReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MirrorListReply.Marshal(b, m, deterministic)
+}
+func (m *MirrorListReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MirrorListReply.Merge(m, src)
+}
+func (m *MirrorListReply) XXX_Size() int {
+	return xxx_messageInfo_MirrorListReply.Size(m)
+}
+func (m *MirrorListReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_MirrorListReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MirrorListReply proto.InternalMessageInfo
+
+func (m *MirrorListReply) GetMirrors() []*Mirror {
+	if m != nil {
+		return m.Mirrors
+	}
+	return nil
+}
+
+type MirrorID struct {
+	ID                   int32    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MirrorID) Reset()         { *m = MirrorID{} }
+func (m *MirrorID) String() string { return proto.CompactTextString(m) }
+func (*MirrorID) ProtoMessage()    {}
+func (*MirrorID) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{20}
+}
+
+func (m *MirrorID) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MirrorID.Unmarshal(m, b)
+}
+func (m *MirrorID) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MirrorID.Marshal(b, m, deterministic)
+}
+func (m *MirrorID) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MirrorID.Merge(m, src)
+}
+func (m *MirrorID) XXX_Size() int {
+	return xxx_messageInfo_MirrorID.Size(m)
+}
+func (m *MirrorID) XXX_DiscardUnknown() {
+	xxx_messageInfo_MirrorID.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MirrorID proto.InternalMessageInfo
+
+func (m *MirrorID) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *MirrorID) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type MatchReply struct {
+	Mirrors              []*MirrorID `protobuf:"bytes,1,rep,name=Mirrors,proto3" json:"Mirrors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *MatchReply) Reset()         { *m = MatchReply{} }
+func (m *MatchReply) String() string { return proto.CompactTextString(m) }
+func (*MatchReply) ProtoMessage()    {}
+func (*MatchReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{21}
+}
+
+func (m *MatchReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MatchReply.Unmarshal(m, b)
+}
+func (m *MatchReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MatchReply.Marshal(b, m, deterministic)
+}
+func (m *MatchReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MatchReply.Merge(m, src)
+}
+func (m *MatchReply) XXX_Size() int {
+	return xxx_messageInfo_MatchReply.Size(m)
+}
+func (m *MatchReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_MatchReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MatchReply proto.InternalMessageInfo
+
+func (m *MatchReply) GetMirrors() []*MirrorID {
+	if m != nil {
+		return m.Mirrors
+	}
+	return nil
+}
+
+type ListFilesRequest struct {
+	Prefix               string   `protobuf:"bytes,1,opt,name=Prefix,proto3" json:"Prefix,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListFilesRequest) Reset()         { *m = ListFilesRequest{} }
+func (m *ListFilesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListFilesRequest) ProtoMessage()    {}
+func (*ListFilesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{22}
+}
+
+func (m *ListFilesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListFilesRequest.Unmarshal(m, b)
+}
+func (m *ListFilesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListFilesRequest.Marshal(b, m, deterministic)
+}
+func (m *ListFilesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListFilesRequest.Merge(m, src)
+}
+func (m *ListFilesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListFilesRequest.Size(m)
+}
+func (m *ListFilesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListFilesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListFilesRequest proto.InternalMessageInfo
+
+func (m *ListFilesRequest) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+type ListFilesReply struct {
+	Files                []string `protobuf:"bytes,1,rep,name=Files,proto3" json:"Files,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListFilesReply) Reset()         { *m = ListFilesReply{} }
+func (m *ListFilesReply) String() string { return proto.CompactTextString(m) }
+func (*ListFilesReply) ProtoMessage()    {}
+func (*ListFilesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{23}
+}
+
+func (m *ListFilesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListFilesReply.Unmarshal(m, b)
+}
+func (m *ListFilesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListFilesReply.Marshal(b, m, deterministic)
+}
+func (m *ListFilesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListFilesReply.Merge(m, src)
+}
+func (m *ListFilesReply) XXX_Size() int {
+	return xxx_messageInfo_ListFilesReply.Size(m)
+}
+func (m *ListFilesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListFilesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListFilesReply proto.InternalMessageInfo
+
+func (m *ListFilesReply) GetFiles() []string {
+	if m != nil {
+		return m.Files
+	}
+	return nil
+}
+
+type RequestTraceRequest struct {
+	RequestID            string   `protobuf:"bytes,1,opt,name=RequestID,proto3" json:"RequestID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestTraceRequest) Reset()         { *m = RequestTraceRequest{} }
+func (m *RequestTraceRequest) String() string { return proto.CompactTextString(m) }
+func (*RequestTraceRequest) ProtoMessage()    {}
+func (*RequestTraceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{24}
+}
+
+func (m *RequestTraceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequestTraceRequest.Unmarshal(m, b)
+}
+func (m *RequestTraceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequestTraceRequest.Marshal(b, m, deterministic)
+}
+func (m *RequestTraceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestTraceRequest.Merge(m, src)
+}
+func (m *RequestTraceRequest) XXX_Size() int {
+	return xxx_messageInfo_RequestTraceRequest.Size(m)
+}
+func (m *RequestTraceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestTraceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestTraceRequest proto.InternalMessageInfo
+
+func (m *RequestTraceRequest) GetRequestID() string {
+	if m != nil {
+		return m.RequestID
+	}
+	return ""
+}
+
+type RequestTraceReply struct {
+	RequestID            string               `protobuf:"bytes,1,opt,name=RequestID,proto3" json:"RequestID,omitempty"`
+	Time                 *timestamp.Timestamp `protobuf:"bytes,2,opt,name=Time,proto3" json:"Time,omitempty"`
+	Path                 string               `protobuf:"bytes,3,opt,name=Path,proto3" json:"Path,omitempty"`
+	IP                   string               `protobuf:"bytes,4,opt,name=IP,proto3" json:"IP,omitempty"`
+	Country              string               `protobuf:"bytes,5,opt,name=Country,proto3" json:"Country,omitempty"`
+	ASNum                int64                `protobuf:"varint,6,opt,name=ASNum,proto3" json:"ASNum,omitempty"`
+	Output               string               `protobuf:"bytes,7,opt,name=Output,proto3" json:"Output,omitempty"`
+	Status               int32                `protobuf:"varint,8,opt,name=Status,proto3" json:"Status,omitempty"`
+	Error                string               `protobuf:"bytes,9,opt,name=Error,proto3" json:"Error,omitempty"`
+	Fallback             bool                 `protobuf:"varint,10,opt,name=Fallback,proto3" json:"Fallback,omitempty"`
+	Mirrors              []string             `protobuf:"bytes,11,rep,name=Mirrors,proto3" json:"Mirrors,omitempty"`
+	Excluded             []*TraceExclusion    `protobuf:"bytes,12,rep,name=Excluded,proto3" json:"Excluded,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *RequestTraceReply) Reset()         { *m = RequestTraceReply{} }
+func (m *RequestTraceReply) String() string { return proto.CompactTextString(m) }
+func (*RequestTraceReply) ProtoMessage()    {}
+func (*RequestTraceReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{25}
+}
+
+func (m *RequestTraceReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequestTraceReply.Unmarshal(m, b)
+}
+func (m *RequestTraceReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequestTraceReply.Marshal(b, m, deterministic)
+}
+func (m *RequestTraceReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestTraceReply.Merge(m, src)
+}
+func (m *RequestTraceReply) XXX_Size() int {
+	return xxx_messageInfo_RequestTraceReply.Size(m)
+}
+func (m *RequestTraceReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestTraceReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestTraceReply proto.InternalMessageInfo
+
+func (m *RequestTraceReply) GetRequestID() string {
+	if m != nil {
+		return m.RequestID
+	}
+	return ""
+}
+
+func (m *RequestTraceReply) GetTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *RequestTraceReply) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *RequestTraceReply) GetIP() string {
+	if m != nil {
+		return m.IP
+	}
+	return ""
+}
+
+func (m *RequestTraceReply) GetCountry() string {
+	if m != nil {
+		return m.Country
+	}
+	return ""
+}
+
+func (m *RequestTraceReply) GetASNum() int64 {
+	if m != nil {
+		return m.ASNum
+	}
+	return 0
+}
+
+func (m *RequestTraceReply) GetOutput() string {
+	if m != nil {
+		return m.Output
+	}
+	return ""
+}
+
+func (m *RequestTraceReply) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *RequestTraceReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *RequestTraceReply) GetFallback() bool {
+	if m != nil {
+		return m.Fallback
+	}
+	return false
+}
+
+func (m *RequestTraceReply) GetMirrors() []string {
+	if m != nil {
+		return m.Mirrors
+	}
+	return nil
+}
+
+func (m *RequestTraceReply) GetExcluded() []*TraceExclusion {
+	if m != nil {
+		return m.Excluded
+	}
+	return nil
+}
+
+type TraceExclusion struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Reason               string   `protobuf:"bytes,2,opt,name=Reason,proto3" json:"Reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TraceExclusion) Reset()         { *m = TraceExclusion{} }
+func (m *TraceExclusion) String() string { return proto.CompactTextString(m) }
+func (*TraceExclusion) ProtoMessage()    {}
+func (*TraceExclusion) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{26}
+}
+
+func (m *TraceExclusion) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TraceExclusion.Unmarshal(m, b)
+}
+func (m *TraceExclusion) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TraceExclusion.Marshal(b, m, deterministic)
+}
+func (m *TraceExclusion) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TraceExclusion.Merge(m, src)
+}
+func (m *TraceExclusion) XXX_Size() int {
+	return xxx_messageInfo_TraceExclusion.Size(m)
+}
+func (m *TraceExclusion) XXX_DiscardUnknown() {
+	xxx_messageInfo_TraceExclusion.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TraceExclusion proto.InternalMessageInfo
+
+func (m *TraceExclusion) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *TraceExclusion) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type ChangeStatusRequest struct {
+	ID                   int32    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Enabled              bool     `protobuf:"varint,2,opt,name=Enabled,proto3" json:"Enabled,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChangeStatusRequest) Reset()         { *m = ChangeStatusRequest{} }
+func (m *ChangeStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*ChangeStatusRequest) ProtoMessage()    {}
+func (*ChangeStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{27}
+}
+
+func (m *ChangeStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChangeStatusRequest.Unmarshal(m, b)
+}
+func (m *ChangeStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChangeStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *ChangeStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChangeStatusRequest.Merge(m, src)
+}
+func (m *ChangeStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_ChangeStatusRequest.Size(m)
+}
+func (m *ChangeStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChangeStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChangeStatusRequest proto.InternalMessageInfo
+
+func (m *ChangeStatusRequest) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *ChangeStatusRequest) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+type MirrorIDRequest struct {
+	ID                   int32    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MirrorIDRequest) Reset()         { *m = MirrorIDRequest{} }
+func (m *MirrorIDRequest) String() string { return proto.CompactTextString(m) }
+func (*MirrorIDRequest) ProtoMessage()    {}
+func (*MirrorIDRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{28}
+}
+
+func (m *MirrorIDRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MirrorIDRequest.Unmarshal(m, b)
+}
+func (m *MirrorIDRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MirrorIDRequest.Marshal(b, m, deterministic)
+}
+func (m *MirrorIDRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MirrorIDRequest.Merge(m, src)
+}
+func (m *MirrorIDRequest) XXX_Size() int {
+	return xxx_messageInfo_MirrorIDRequest.Size(m)
+}
+func (m *MirrorIDRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MirrorIDRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MirrorIDRequest proto.InternalMessageInfo
+
+func (m *MirrorIDRequest) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+type RenameMirrorRequest struct {
+	ID                   int32    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RenameMirrorRequest) Reset()         { *m = RenameMirrorRequest{} }
+func (m *RenameMirrorRequest) String() string { return proto.CompactTextString(m) }
+func (*RenameMirrorRequest) ProtoMessage()    {}
+func (*RenameMirrorRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{29}
+}
+
+func (m *RenameMirrorRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RenameMirrorRequest.Unmarshal(m, b)
+}
+func (m *RenameMirrorRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RenameMirrorRequest.Marshal(b, m, deterministic)
+}
+func (m *RenameMirrorRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RenameMirrorRequest.Merge(m, src)
+}
+func (m *RenameMirrorRequest) XXX_Size() int {
+	return xxx_messageInfo_RenameMirrorRequest.Size(m)
+}
+func (m *RenameMirrorRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RenameMirrorRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RenameMirrorRequest proto.InternalMessageInfo
+
+func (m *RenameMirrorRequest) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *RenameMirrorRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type AddMirrorReply struct {
+	Latitude             float32  `protobuf:"fixed32,1,opt,name=Latitude,proto3" json:"Latitude,omitempty"`
+	Longitude            float32  `protobuf:"fixed32,2,opt,name=Longitude,proto3" json:"Longitude,omitempty"`
+	Country              string   `protobuf:"bytes,3,opt,name=Country,proto3" json:"Country,omitempty"`
+	Continent            string   `protobuf:"bytes,4,opt,name=Continent,proto3" json:"Continent,omitempty"`
+	ASN                  string   `protobuf:"bytes,5,opt,name=ASN,proto3" json:"ASN,omitempty"`
+	Warnings             []string `protobuf:"bytes,6,rep,name=Warnings,proto3" json:"Warnings,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddMirrorReply) Reset()         { *m = AddMirrorReply{} }
+func (m *AddMirrorReply) String() string { return proto.CompactTextString(m) }
+func (*AddMirrorReply) ProtoMessage()    {}
+func (*AddMirrorReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{30}
+}
+
+func (m *AddMirrorReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddMirrorReply.Unmarshal(m, b)
+}
+func (m *AddMirrorReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddMirrorReply.Marshal(b, m, deterministic)
+}
+func (m *AddMirrorReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddMirrorReply.Merge(m, src)
+}
+func (m *AddMirrorReply) XXX_Size() int {
+	return xxx_messageInfo_AddMirrorReply.Size(m)
+}
+func (m *AddMirrorReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddMirrorReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddMirrorReply proto.InternalMessageInfo
+
+func (m *AddMirrorReply) GetLatitude() float32 {
+	if m != nil {
+		return m.Latitude
+	}
+	return 0
+}
+
+func (m *AddMirrorReply) GetLongitude() float32 {
+	if m != nil {
+		return m.Longitude
+	}
+	return 0
+}
+
+func (m *AddMirrorReply) GetCountry() string {
+	if m != nil {
+		return m.Country
+	}
+	return ""
+}
+
+func (m *AddMirrorReply) GetContinent() string {
+	if m != nil {
+		return m.Continent
+	}
+	return ""
+}
+
+func (m *AddMirrorReply) GetASN() string {
+	if m != nil {
+		return m.ASN
+	}
+	return ""
+}
+
+func (m *AddMirrorReply) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+type UpdateMirrorReply struct {
+	Diff                 string   `protobuf:"bytes,1,opt,name=Diff,proto3" json:"Diff,omitempty"`
+	Warnings             []string `protobuf:"bytes,2,rep,name=Warnings,proto3" json:"Warnings,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateMirrorReply) Reset()         { *m = UpdateMirrorReply{} }
+func (m *UpdateMirrorReply) String() string { return proto.CompactTextString(m) }
+func (*UpdateMirrorReply) ProtoMessage()    {}
+func (*UpdateMirrorReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{31}
+}
+
+func (m *UpdateMirrorReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateMirrorReply.Unmarshal(m, b)
+}
+func (m *UpdateMirrorReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateMirrorReply.Marshal(b, m, deterministic)
+}
+func (m *UpdateMirrorReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateMirrorReply.Merge(m, src)
+}
+func (m *UpdateMirrorReply) XXX_Size() int {
+	return xxx_messageInfo_UpdateMirrorReply.Size(m)
+}
+func (m *UpdateMirrorReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateMirrorReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateMirrorReply proto.InternalMessageInfo
+
+func (m *UpdateMirrorReply) GetDiff() string {
+	if m != nil {
+		return m.Diff
+	}
+	return ""
+}
+
+func (m *UpdateMirrorReply) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+type RefreshRepositoryRequest struct {
+	Rehash               bool     `protobuf:"varint,1,opt,name=Rehash,proto3" json:"Rehash,omitempty"`
+	Push                 bool     `protobuf:"varint,2,opt,name=Push,proto3" json:"Push,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RefreshRepositoryRequest) Reset()         { *m = RefreshRepositoryRequest{} }
+func (m *RefreshRepositoryRequest) String() string { return proto.CompactTextString(m) }
+func (*RefreshRepositoryRequest) ProtoMessage()    {}
+func (*RefreshRepositoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{32}
+}
+
+func (m *RefreshRepositoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RefreshRepositoryRequest.Unmarshal(m, b)
+}
+func (m *RefreshRepositoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RefreshRepositoryRequest.Marshal(b, m, deterministic)
+}
+func (m *RefreshRepositoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RefreshRepositoryRequest.Merge(m, src)
+}
+func (m *RefreshRepositoryRequest) XXX_Size() int {
+	return xxx_messageInfo_RefreshRepositoryRequest.Size(m)
+}
+func (m *RefreshRepositoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RefreshRepositoryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RefreshRepositoryRequest proto.InternalMessageInfo
+
+func (m *RefreshRepositoryRequest) GetRehash() bool {
+	if m != nil {
+		return m.Rehash
+	}
+	return false
+}
+
+func (m *RefreshRepositoryRequest) GetPush() bool {
+	if m != nil {
+		return m.Push
+	}
+	return false
+}
+
+type VerifyRepositoryRequest struct {
+	Prefix               string   `protobuf:"bytes,1,opt,name=Prefix,proto3" json:"Prefix,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifyRepositoryRequest) Reset()         { *m = VerifyRepositoryRequest{} }
+func (m *VerifyRepositoryRequest) String() string { return proto.CompactTextString(m) }
+func (*VerifyRepositoryRequest) ProtoMessage()    {}
+func (*VerifyRepositoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{33}
+}
+
+func (m *VerifyRepositoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyRepositoryRequest.Unmarshal(m, b)
+}
+func (m *VerifyRepositoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyRepositoryRequest.Marshal(b, m, deterministic)
+}
+func (m *VerifyRepositoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyRepositoryRequest.Merge(m, src)
+}
+func (m *VerifyRepositoryRequest) XXX_Size() int {
+	return xxx_messageInfo_VerifyRepositoryRequest.Size(m)
+}
+func (m *VerifyRepositoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyRepositoryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyRepositoryRequest proto.InternalMessageInfo
+
+func (m *VerifyRepositoryRequest) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+type VerifyRepositoryReply struct {
+	Checked              int64    `protobuf:"varint,1,opt,name=Checked,proto3" json:"Checked,omitempty"`
+	Mismatches           []string `protobuf:"bytes,2,rep,name=Mismatches,proto3" json:"Mismatches,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifyRepositoryReply) Reset()         { *m = VerifyRepositoryReply{} }
+func (m *VerifyRepositoryReply) String() string { return proto.CompactTextString(m) }
+func (*VerifyRepositoryReply) ProtoMessage()    {}
+func (*VerifyRepositoryReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{34}
+}
+
+func (m *VerifyRepositoryReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyRepositoryReply.Unmarshal(m, b)
+}
+func (m *VerifyRepositoryReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyRepositoryReply.Marshal(b, m, deterministic)
+}
+func (m *VerifyRepositoryReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyRepositoryReply.Merge(m, src)
+}
+func (m *VerifyRepositoryReply) XXX_Size() int {
+	return xxx_messageInfo_VerifyRepositoryReply.Size(m)
+}
+func (m *VerifyRepositoryReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyRepositoryReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyRepositoryReply proto.InternalMessageInfo
+
+func (m *VerifyRepositoryReply) GetChecked() int64 {
+	if m != nil {
+		return m.Checked
+	}
+	return 0
+}
+
+func (m *VerifyRepositoryReply) GetMismatches() []string {
+	if m != nil {
+		return m.Mismatches
+	}
+	return nil
+}
+
+type ScanMirrorRequest struct {
+	ID                   int32                    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	AutoEnable           bool                     `protobuf:"varint,2,opt,name=AutoEnable,proto3" json:"AutoEnable,omitempty"`
+	Protocol             ScanMirrorRequest_Method `protobuf:"varint,3,opt,name=Protocol,proto3,enum=ScanMirrorRequest_Method" json:"Protocol,omitempty"`
+	Force                bool                     `protobuf:"varint,4,opt,name=Force,proto3" json:"Force,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *ScanMirrorRequest) Reset()         { *m = ScanMirrorRequest{} }
+func (m *ScanMirrorRequest) String() string { return proto.CompactTextString(m) }
+func (*ScanMirrorRequest) ProtoMessage()    {}
+func (*ScanMirrorRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{35}
+}
+
+func (m *ScanMirrorRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScanMirrorRequest.Unmarshal(m, b)
+}
+func (m *ScanMirrorRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScanMirrorRequest.Marshal(b, m, deterministic)
+}
+func (m *ScanMirrorRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScanMirrorRequest.Merge(m, src)
+}
+func (m *ScanMirrorRequest) XXX_Size() int {
+	return xxx_messageInfo_ScanMirrorRequest.Size(m)
+}
+func (m *ScanMirrorRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScanMirrorRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScanMirrorRequest proto.InternalMessageInfo
+
+func (m *ScanMirrorRequest) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *ScanMirrorRequest) GetAutoEnable() bool {
+	if m != nil {
+		return m.AutoEnable
+	}
+	return false
+}
+
+func (m *ScanMirrorRequest) GetProtocol() ScanMirrorRequest_Method {
+	if m != nil {
+		return m.Protocol
+	}
+	return ScanMirrorRequest_ALL
+}
+
+func (m *ScanMirrorRequest) GetForce() bool {
+	if m != nil {
+		return m.Force
+	}
+	return false
+}
+
+type ScanMirrorReply struct {
+	Enabled              bool     `protobuf:"varint,1,opt,name=Enabled,proto3" json:"Enabled,omitempty"`
+	FilesIndexed         int64    `protobuf:"varint,2,opt,name=FilesIndexed,proto3" json:"FilesIndexed,omitempty"`
+	KnownIndexed         int64    `protobuf:"varint,3,opt,name=KnownIndexed,proto3" json:"KnownIndexed,omitempty"`
+	Removed              int64    `protobuf:"varint,4,opt,name=Removed,proto3" json:"Removed,omitempty"`
+	TZOffsetMs           int64    `protobuf:"varint,5,opt,name=TZOffsetMs,proto3" json:"TZOffsetMs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ScanMirrorReply) Reset()         { *m = ScanMirrorReply{} }
+func (m *ScanMirrorReply) String() string { return proto.CompactTextString(m) }
+func (*ScanMirrorReply) ProtoMessage()    {}
+func (*ScanMirrorReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{36}
+}
+
+func (m *ScanMirrorReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScanMirrorReply.Unmarshal(m, b)
+}
+func (m *ScanMirrorReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScanMirrorReply.Marshal(b, m, deterministic)
+}
+func (m *ScanMirrorReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScanMirrorReply.Merge(m, src)
+}
+func (m *ScanMirrorReply) XXX_Size() int {
+	return xxx_messageInfo_ScanMirrorReply.Size(m)
+}
+func (m *ScanMirrorReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScanMirrorReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScanMirrorReply proto.InternalMessageInfo
+
+func (m *ScanMirrorReply) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+func (m *ScanMirrorReply) GetFilesIndexed() int64 {
+	if m != nil {
+		return m.FilesIndexed
+	}
+	return 0
+}
+
+func (m *ScanMirrorReply) GetKnownIndexed() int64 {
+	if m != nil {
+		return m.KnownIndexed
+	}
+	return 0
+}
+
+func (m *ScanMirrorReply) GetRemoved() int64 {
+	if m != nil {
+		return m.Removed
+	}
+	return 0
+}
+
+func (m *ScanMirrorReply) GetTZOffsetMs() int64 {
+	if m != nil {
+		return m.TZOffsetMs
+	}
+	return 0
+}
+
+type StatsFileRequest struct {
+	Pattern              string               `protobuf:"bytes,1,opt,name=Pattern,proto3" json:"Pattern,omitempty"`
+	DateStart            *timestamp.Timestamp `protobuf:"bytes,2,opt,name=DateStart,proto3" json:"DateStart,omitempty"`
+	DateEnd              *timestamp.Timestamp `protobuf:"bytes,3,opt,name=DateEnd,proto3" json:"DateEnd,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *StatsFileRequest) Reset()         { *m = StatsFileRequest{} }
+func (m *StatsFileRequest) String() string { return proto.CompactTextString(m) }
+func (*StatsFileRequest) ProtoMessage()    {}
+func (*StatsFileRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{37}
+}
+
+func (m *StatsFileRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatsFileRequest.Unmarshal(m, b)
+}
+func (m *StatsFileRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatsFileRequest.Marshal(b, m, deterministic)
+}
+func (m *StatsFileRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatsFileRequest.Merge(m, src)
+}
+func (m *StatsFileRequest) XXX_Size() int {
+	return xxx_messageInfo_StatsFileRequest.Size(m)
+}
+func (m *StatsFileRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatsFileRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatsFileRequest proto.InternalMessageInfo
+
+func (m *StatsFileRequest) GetPattern() string {
+	if m != nil {
+		return m.Pattern
+	}
+	return ""
+}
+
+func (m *StatsFileRequest) GetDateStart() *timestamp.Timestamp {
+	if m != nil {
+		return m.DateStart
+	}
+	return nil
+}
+
+func (m *StatsFileRequest) GetDateEnd() *timestamp.Timestamp {
+	if m != nil {
+		return m.DateEnd
+	}
+	return nil
+}
+
+type StatsFileReply struct {
+	Files                map[string]int64 `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *StatsFileReply) Reset()         { *m = StatsFileReply{} }
+func (m *StatsFileReply) String() string { return proto.CompactTextString(m) }
+func (*StatsFileReply) ProtoMessage()    {}
+func (*StatsFileReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{38}
+}
+
+func (m *StatsFileReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatsFileReply.Unmarshal(m, b)
+}
+func (m *StatsFileReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatsFileReply.Marshal(b, m, deterministic)
+}
+func (m *StatsFileReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatsFileReply.Merge(m, src)
+}
+func (m *StatsFileReply) XXX_Size() int {
+	return xxx_messageInfo_StatsFileReply.Size(m)
+}
+func (m *StatsFileReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatsFileReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatsFileReply proto.InternalMessageInfo
+
+func (m *StatsFileReply) GetFiles() map[string]int64 {
+	if m != nil {
+		return m.Files
+	}
+	return nil
+}
+
+type StatsMirrorRequest struct {
+	ID                   int32                `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	DateStart            *timestamp.Timestamp `protobuf:"bytes,2,opt,name=DateStart,proto3" json:"DateStart,omitempty"`
+	DateEnd              *timestamp.Timestamp `protobuf:"bytes,3,opt,name=DateEnd,proto3" json:"DateEnd,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *StatsMirrorRequest) Reset()         { *m = StatsMirrorRequest{} }
+func (m *StatsMirrorRequest) String() string { return proto.CompactTextString(m) }
+func (*StatsMirrorRequest) ProtoMessage()    {}
+func (*StatsMirrorRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{39}
+}
+
+func (m *StatsMirrorRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatsMirrorRequest.Unmarshal(m, b)
+}
+func (m *StatsMirrorRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatsMirrorRequest.Marshal(b, m, deterministic)
+}
+func (m *StatsMirrorRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatsMirrorRequest.Merge(m, src)
+}
+func (m *StatsMirrorRequest) XXX_Size() int {
+	return xxx_messageInfo_StatsMirrorRequest.Size(m)
+}
+func (m *StatsMirrorRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatsMirrorRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatsMirrorRequest proto.InternalMessageInfo
+
+func (m *StatsMirrorRequest) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *StatsMirrorRequest) GetDateStart() *timestamp.Timestamp {
+	if m != nil {
+		return m.DateStart
+	}
+	return nil
+}
+
+func (m *StatsMirrorRequest) GetDateEnd() *timestamp.Timestamp {
+	if m != nil {
+		return m.DateEnd
+	}
+	return nil
+}
+
+type StatsMirrorReply struct {
+	Mirror               *Mirror  `protobuf:"bytes,1,opt,name=Mirror,proto3" json:"Mirror,omitempty"`
+	Requests             int64    `protobuf:"varint,2,opt,name=Requests,proto3" json:"Requests,omitempty"`
+	Bytes                int64    `protobuf:"varint,3,opt,name=Bytes,proto3" json:"Bytes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StatsMirrorReply) Reset()         { *m = StatsMirrorReply{} }
+func (m *StatsMirrorReply) String() string { return proto.CompactTextString(m) }
+func (*StatsMirrorReply) ProtoMessage()    {}
+func (*StatsMirrorReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{40}
+}
+
+func (m *StatsMirrorReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatsMirrorReply.Unmarshal(m, b)
+}
+func (m *StatsMirrorReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatsMirrorReply.Marshal(b, m, deterministic)
+}
+func (m *StatsMirrorReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatsMirrorReply.Merge(m, src)
+}
+func (m *StatsMirrorReply) XXX_Size() int {
+	return xxx_messageInfo_StatsMirrorReply.Size(m)
+}
+func (m *StatsMirrorReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatsMirrorReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatsMirrorReply proto.InternalMessageInfo
+
+func (m *StatsMirrorReply) GetMirror() *Mirror {
+	if m != nil {
+		return m.Mirror
+	}
+	return nil
+}
+
+func (m *StatsMirrorReply) GetRequests() int64 {
+	if m != nil {
+		return m.Requests
+	}
+	return 0
+}
+
+func (m *StatsMirrorReply) GetBytes() int64 {
+	if m != nil {
+		return m.Bytes
+	}
+	return 0
+}
+
+type GetMirrorLogsRequest struct {
+	ID                   int32    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	MaxResults           int32    `protobuf:"varint,2,opt,name=MaxResults,proto3" json:"MaxResults,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetMirrorLogsRequest) Reset()         { *m = GetMirrorLogsRequest{} }
+func (m *GetMirrorLogsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetMirrorLogsRequest) ProtoMessage()    {}
+func (*GetMirrorLogsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{41}
+}
+
+func (m *GetMirrorLogsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetMirrorLogsRequest.Unmarshal(m, b)
+}
+func (m *GetMirrorLogsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetMirrorLogsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetMirrorLogsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetMirrorLogsRequest.Merge(m, src)
+}
+func (m *GetMirrorLogsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetMirrorLogsRequest.Size(m)
+}
+func (m *GetMirrorLogsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetMirrorLogsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetMirrorLogsRequest proto.InternalMessageInfo
+
+func (m *GetMirrorLogsRequest) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *GetMirrorLogsRequest) GetMaxResults() int32 {
+	if m != nil {
+		return m.MaxResults
+	}
+	return 0
+}
+
+type GetMirrorLogsReply struct {
+	Line                 []string `protobuf:"bytes,1,rep,name=line,proto3" json:"line,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetMirrorLogsReply) Reset()         { *m = GetMirrorLogsReply{} }
+func (m *GetMirrorLogsReply) String() string { return proto.CompactTextString(m) }
+func (*GetMirrorLogsReply) ProtoMessage()    {}
+func (*GetMirrorLogsReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{42}
+}
+
+func (m *GetMirrorLogsReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetMirrorLogsReply.Unmarshal(m, b)
+}
+func (m *GetMirrorLogsReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetMirrorLogsReply.Marshal(b, m, deterministic)
+}
+func (m *GetMirrorLogsReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetMirrorLogsReply.Merge(m, src)
+}
+func (m *GetMirrorLogsReply) XXX_Size() int {
+	return xxx_messageInfo_GetMirrorLogsReply.Size(m)
+}
+func (m *GetMirrorLogsReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetMirrorLogsReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetMirrorLogsReply proto.InternalMessageInfo
+
+func (m *GetMirrorLogsReply) GetLine() []string {
+	if m != nil {
+		return m.Line
+	}
+	return nil
+}
+
+type GetScanSummariesRequest struct {
+	ID                   int32    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	MaxResults           int32    `protobuf:"varint,2,opt,name=MaxResults,proto3" json:"MaxResults,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetScanSummariesRequest) Reset()         { *m = GetScanSummariesRequest{} }
+func (m *GetScanSummariesRequest) String() string { return proto.CompactTextString(m) }
+func (*GetScanSummariesRequest) ProtoMessage()    {}
+func (*GetScanSummariesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{43}
+}
+
+func (m *GetScanSummariesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetScanSummariesRequest.Unmarshal(m, b)
+}
+func (m *GetScanSummariesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetScanSummariesRequest.Marshal(b, m, deterministic)
+}
+func (m *GetScanSummariesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetScanSummariesRequest.Merge(m, src)
+}
+func (m *GetScanSummariesRequest) XXX_Size() int {
+	return xxx_messageInfo_GetScanSummariesRequest.Size(m)
+}
+func (m *GetScanSummariesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetScanSummariesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetScanSummariesRequest proto.InternalMessageInfo
+
+func (m *GetScanSummariesRequest) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *GetScanSummariesRequest) GetMaxResults() int32 {
+	if m != nil {
+		return m.MaxResults
+	}
+	return 0
+}
+
+type ScanSummary struct {
+	Timestamp            *timestamp.Timestamp `protobuf:"bytes,1,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
+	DurationMs           int64                `protobuf:"varint,2,opt,name=DurationMs,proto3" json:"DurationMs,omitempty"`
+	Method               string               `protobuf:"bytes,3,opt,name=Method,proto3" json:"Method,omitempty"`
+	FilesIndexed         int64                `protobuf:"varint,4,opt,name=FilesIndexed,proto3" json:"FilesIndexed,omitempty"`
+	KnownIndexed         int64                `protobuf:"varint,5,opt,name=KnownIndexed,proto3" json:"KnownIndexed,omitempty"`
+	Added                int64                `protobuf:"varint,6,opt,name=Added,proto3" json:"Added,omitempty"`
+	Removed              int64                `protobuf:"varint,7,opt,name=Removed,proto3" json:"Removed,omitempty"`
+	Error                string               `protobuf:"bytes,8,opt,name=Error,proto3" json:"Error,omitempty"`
+	Quarantined          bool                 `protobuf:"varint,9,opt,name=Quarantined,proto3" json:"Quarantined,omitempty"`
+	Accepted             bool                 `protobuf:"varint,10,opt,name=Accepted,proto3" json:"Accepted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ScanSummary) Reset()         { *m = ScanSummary{} }
+func (m *ScanSummary) String() string { return proto.CompactTextString(m) }
+func (*ScanSummary) ProtoMessage()    {}
+func (*ScanSummary) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{44}
+}
+
+func (m *ScanSummary) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScanSummary.Unmarshal(m, b)
+}
+func (m *ScanSummary) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScanSummary.Marshal(b, m, deterministic)
+}
+func (m *ScanSummary) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScanSummary.Merge(m, src)
+}
+func (m *ScanSummary) XXX_Size() int {
+	return xxx_messageInfo_ScanSummary.Size(m)
+}
+func (m *ScanSummary) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScanSummary.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScanSummary proto.InternalMessageInfo
+
+func (m *ScanSummary) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+func (m *ScanSummary) GetDurationMs() int64 {
+	if m != nil {
+		return m.DurationMs
+	}
+	return 0
+}
+
+func (m *ScanSummary) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *ScanSummary) GetFilesIndexed() int64 {
+	if m != nil {
+		return m.FilesIndexed
+	}
+	return 0
+}
+
+func (m *ScanSummary) GetKnownIndexed() int64 {
+	if m != nil {
+		return m.KnownIndexed
+	}
+	return 0
+}
+
+func (m *ScanSummary) GetAdded() int64 {
+	if m != nil {
+		return m.Added
+	}
+	return 0
+}
+
+func (m *ScanSummary) GetRemoved() int64 {
+	if m != nil {
+		return m.Removed
+	}
+	return 0
+}
+
+func (m *ScanSummary) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *ScanSummary) GetQuarantined() bool {
+	if m != nil {
+		return m.Quarantined
+	}
+	return false
+}
+
+func (m *ScanSummary) GetAccepted() bool {
+	if m != nil {
+		return m.Accepted
+	}
+	return false
+}
+
+type GetScanSummariesReply struct {
+	Summaries            []*ScanSummary `protobuf:"bytes,1,rep,name=Summaries,proto3" json:"Summaries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *GetScanSummariesReply) Reset()         { *m = GetScanSummariesReply{} }
+func (m *GetScanSummariesReply) String() string { return proto.CompactTextString(m) }
+func (*GetScanSummariesReply) ProtoMessage()    {}
+func (*GetScanSummariesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{45}
+}
+
+func (m *GetScanSummariesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetScanSummariesReply.Unmarshal(m, b)
+}
+func (m *GetScanSummariesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetScanSummariesReply.Marshal(b, m, deterministic)
+}
+func (m *GetScanSummariesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetScanSummariesReply.Merge(m, src)
+}
+func (m *GetScanSummariesReply) XXX_Size() int {
+	return xxx_messageInfo_GetScanSummariesReply.Size(m)
+}
+func (m *GetScanSummariesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetScanSummariesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetScanSummariesReply proto.InternalMessageInfo
+
+func (m *GetScanSummariesReply) GetSummaries() []*ScanSummary {
+	if m != nil {
+		return m.Summaries
+	}
+	return nil
+}
+
+type PropagationRequest struct {
+	Prefix               string   `protobuf:"bytes,1,opt,name=Prefix,proto3" json:"Prefix,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PropagationRequest) Reset()         { *m = PropagationRequest{} }
+func (m *PropagationRequest) String() string { return proto.CompactTextString(m) }
+func (*PropagationRequest) ProtoMessage()    {}
+func (*PropagationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{46}
+}
+
+func (m *PropagationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PropagationRequest.Unmarshal(m, b)
+}
+func (m *PropagationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PropagationRequest.Marshal(b, m, deterministic)
+}
+func (m *PropagationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PropagationRequest.Merge(m, src)
+}
+func (m *PropagationRequest) XXX_Size() int {
+	return xxx_messageInfo_PropagationRequest.Size(m)
+}
+func (m *PropagationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PropagationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PropagationRequest proto.InternalMessageInfo
+
+func (m *PropagationRequest) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+type FilePropagation struct {
+	Path                 string               `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	Mirrors              []string             `protobuf:"bytes,2,rep,name=Mirrors,proto3" json:"Mirrors,omitempty"`
+	Missing              []string             `protobuf:"bytes,3,rep,name=Missing,proto3" json:"Missing,omitempty"`
+	Percentage           float32              `protobuf:"fixed32,4,opt,name=Percentage,proto3" json:"Percentage,omitempty"`
+	ETA                  *timestamp.Timestamp `protobuf:"bytes,5,opt,name=ETA,proto3" json:"ETA,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *FilePropagation) Reset()         { *m = FilePropagation{} }
+func (m *FilePropagation) String() string { return proto.CompactTextString(m) }
+func (*FilePropagation) ProtoMessage()    {}
+func (*FilePropagation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{47}
+}
+
+func (m *FilePropagation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FilePropagation.Unmarshal(m, b)
+}
+func (m *FilePropagation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FilePropagation.Marshal(b, m, deterministic)
+}
+func (m *FilePropagation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FilePropagation.Merge(m, src)
+}
+func (m *FilePropagation) XXX_Size() int {
+	return xxx_messageInfo_FilePropagation.Size(m)
+}
+func (m *FilePropagation) XXX_DiscardUnknown() {
+	xxx_messageInfo_FilePropagation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FilePropagation proto.InternalMessageInfo
+
+func (m *FilePropagation) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *FilePropagation) GetMirrors() []string {
+	if m != nil {
+		return m.Mirrors
+	}
+	return nil
+}
+
+func (m *FilePropagation) GetMissing() []string {
+	if m != nil {
+		return m.Missing
+	}
+	return nil
+}
+
+func (m *FilePropagation) GetPercentage() float32 {
+	if m != nil {
+		return m.Percentage
+	}
+	return 0
+}
+
+func (m *FilePropagation) GetETA() *timestamp.Timestamp {
+	if m != nil {
+		return m.ETA
+	}
+	return nil
+}
+
+type PropagationReply struct {
+	Files                []*FilePropagation `protobuf:"bytes,1,rep,name=Files,proto3" json:"Files,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *PropagationReply) Reset()         { *m = PropagationReply{} }
+func (m *PropagationReply) String() string { return proto.CompactTextString(m) }
+func (*PropagationReply) ProtoMessage()    {}
+func (*PropagationReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{48}
+}
+
+func (m *PropagationReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PropagationReply.Unmarshal(m, b)
+}
+func (m *PropagationReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PropagationReply.Marshal(b, m, deterministic)
+}
+func (m *PropagationReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PropagationReply.Merge(m, src)
+}
+func (m *PropagationReply) XXX_Size() int {
+	return xxx_messageInfo_PropagationReply.Size(m)
+}
+func (m *PropagationReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_PropagationReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PropagationReply proto.InternalMessageInfo
+
+func (m *PropagationReply) GetFiles() []*FilePropagation {
+	if m != nil {
+		return m.Files
+	}
+	return nil
+}
+
+type RsyncModulesRequest struct {
+	URL                  string   `protobuf:"bytes,1,opt,name=URL,proto3" json:"URL,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RsyncModulesRequest) Reset()         { *m = RsyncModulesRequest{} }
+func (m *RsyncModulesRequest) String() string { return proto.CompactTextString(m) }
+func (*RsyncModulesRequest) ProtoMessage()    {}
+func (*RsyncModulesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{49}
+}
+
+func (m *RsyncModulesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RsyncModulesRequest.Unmarshal(m, b)
+}
+func (m *RsyncModulesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RsyncModulesRequest.Marshal(b, m, deterministic)
+}
+func (m *RsyncModulesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RsyncModulesRequest.Merge(m, src)
+}
+func (m *RsyncModulesRequest) XXX_Size() int {
+	return xxx_messageInfo_RsyncModulesRequest.Size(m)
+}
+func (m *RsyncModulesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RsyncModulesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RsyncModulesRequest proto.InternalMessageInfo
+
+func (m *RsyncModulesRequest) GetURL() string {
+	if m != nil {
+		return m.URL
+	}
+	return ""
+}
+
+type RsyncModule struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Comment              string   `protobuf:"bytes,2,opt,name=Comment,proto3" json:"Comment,omitempty"`
+	ContainsRepository   bool     `protobuf:"varint,3,opt,name=ContainsRepository,proto3" json:"ContainsRepository,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RsyncModule) Reset()         { *m = RsyncModule{} }
+func (m *RsyncModule) String() string { return proto.CompactTextString(m) }
+func (*RsyncModule) ProtoMessage()    {}
+func (*RsyncModule) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{50}
+}
+
+func (m *RsyncModule) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RsyncModule.Unmarshal(m, b)
+}
+func (m *RsyncModule) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RsyncModule.Marshal(b, m, deterministic)
+}
+func (m *RsyncModule) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RsyncModule.Merge(m, src)
+}
+func (m *RsyncModule) XXX_Size() int {
+	return xxx_messageInfo_RsyncModule.Size(m)
+}
+func (m *RsyncModule) XXX_DiscardUnknown() {
+	xxx_messageInfo_RsyncModule.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RsyncModule proto.InternalMessageInfo
+
+func (m *RsyncModule) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *RsyncModule) GetComment() string {
+	if m != nil {
+		return m.Comment
+	}
+	return ""
+}
+
+func (m *RsyncModule) GetContainsRepository() bool {
+	if m != nil {
+		return m.ContainsRepository
+	}
+	return false
+}
+
+type RsyncModulesReply struct {
+	Modules              []*RsyncModule `protobuf:"bytes,1,rep,name=Modules,proto3" json:"Modules,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *RsyncModulesReply) Reset()         { *m = RsyncModulesReply{} }
+func (m *RsyncModulesReply) String() string { return proto.CompactTextString(m) }
+func (*RsyncModulesReply) ProtoMessage()    {}
+func (*RsyncModulesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{51}
+}
+
+func (m *RsyncModulesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RsyncModulesReply.Unmarshal(m, b)
+}
+func (m *RsyncModulesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RsyncModulesReply.Marshal(b, m, deterministic)
+}
+func (m *RsyncModulesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RsyncModulesReply.Merge(m, src)
+}
+func (m *RsyncModulesReply) XXX_Size() int {
+	return xxx_messageInfo_RsyncModulesReply.Size(m)
+}
+func (m *RsyncModulesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_RsyncModulesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RsyncModulesReply proto.InternalMessageInfo
+
+func (m *RsyncModulesReply) GetModules() []*RsyncModule {
+	if m != nil {
+		return m.Modules
+	}
+	return nil
+}
+
+type SignURLRequest struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	Validity             int64    `protobuf:"varint,2,opt,name=Validity,proto3" json:"Validity,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SignURLRequest) Reset()         { *m = SignURLRequest{} }
+func (m *SignURLRequest) String() string { return proto.CompactTextString(m) }
+func (*SignURLRequest) ProtoMessage()    {}
+func (*SignURLRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{52}
+}
+
+func (m *SignURLRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SignURLRequest.Unmarshal(m, b)
+}
+func (m *SignURLRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SignURLRequest.Marshal(b, m, deterministic)
+}
+func (m *SignURLRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignURLRequest.Merge(m, src)
+}
+func (m *SignURLRequest) XXX_Size() int {
+	return xxx_messageInfo_SignURLRequest.Size(m)
+}
+func (m *SignURLRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignURLRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignURLRequest proto.InternalMessageInfo
+
+func (m *SignURLRequest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *SignURLRequest) GetValidity() int64 {
+	if m != nil {
+		return m.Validity
+	}
+	return 0
+}
+
+type SignURLReply struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	Expires              int64    `protobuf:"varint,2,opt,name=Expires,proto3" json:"Expires,omitempty"`
+	Signature            string   `protobuf:"bytes,3,opt,name=Signature,proto3" json:"Signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SignURLReply) Reset()         { *m = SignURLReply{} }
+func (m *SignURLReply) String() string { return proto.CompactTextString(m) }
+func (*SignURLReply) ProtoMessage()    {}
+func (*SignURLReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{53}
+}
+
+func (m *SignURLReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SignURLReply.Unmarshal(m, b)
+}
+func (m *SignURLReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SignURLReply.Marshal(b, m, deterministic)
+}
+func (m *SignURLReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignURLReply.Merge(m, src)
+}
+func (m *SignURLReply) XXX_Size() int {
+	return xxx_messageInfo_SignURLReply.Size(m)
+}
+func (m *SignURLReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignURLReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignURLReply proto.InternalMessageInfo
+
+func (m *SignURLReply) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *SignURLReply) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+func (m *SignURLReply) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+type PendingMirror struct {
+	ID                   int32                `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Name                 string               `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	HttpURL              string               `protobuf:"bytes,3,opt,name=HttpURL,proto3" json:"HttpURL,omitempty"`
+	RsyncURL             string               `protobuf:"bytes,4,opt,name=RsyncURL,proto3" json:"RsyncURL,omitempty"`
+	FtpURL               string               `protobuf:"bytes,5,opt,name=FtpURL,proto3" json:"FtpURL,omitempty"`
+	SponsorName          string               `protobuf:"bytes,6,opt,name=SponsorName,proto3" json:"SponsorName,omitempty"`
+	SponsorURL           string               `protobuf:"bytes,7,opt,name=SponsorURL,proto3" json:"SponsorURL,omitempty"`
+	AdminName            string               `protobuf:"bytes,8,opt,name=AdminName,proto3" json:"AdminName,omitempty"`
+	AdminEmail           string               `protobuf:"bytes,9,opt,name=AdminEmail,proto3" json:"AdminEmail,omitempty"`
+	Comment              string               `protobuf:"bytes,10,opt,name=Comment,proto3" json:"Comment,omitempty"`
+	RemoteIP             string               `protobuf:"bytes,11,opt,name=RemoteIP,proto3" json:"RemoteIP,omitempty"`
+	Submitted            *timestamp.Timestamp `protobuf:"bytes,12,opt,name=Submitted,proto3" json:"Submitted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *PendingMirror) Reset()         { *m = PendingMirror{} }
+func (m *PendingMirror) String() string { return proto.CompactTextString(m) }
+func (*PendingMirror) ProtoMessage()    {}
+func (*PendingMirror) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{54}
+}
+
+func (m *PendingMirror) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PendingMirror.Unmarshal(m, b)
+}
+func (m *PendingMirror) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PendingMirror.Marshal(b, m, deterministic)
+}
+func (m *PendingMirror) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PendingMirror.Merge(m, src)
+}
+func (m *PendingMirror) XXX_Size() int {
+	return xxx_messageInfo_PendingMirror.Size(m)
+}
+func (m *PendingMirror) XXX_DiscardUnknown() {
+	xxx_messageInfo_PendingMirror.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PendingMirror proto.InternalMessageInfo
+
+func (m *PendingMirror) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *PendingMirror) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *PendingMirror) GetHttpURL() string {
+	if m != nil {
+		return m.HttpURL
+	}
+	return ""
+}
+
+func (m *PendingMirror) GetRsyncURL() string {
+	if m != nil {
+		return m.RsyncURL
+	}
+	return ""
+}
+
+func (m *PendingMirror) GetFtpURL() string {
+	if m != nil {
+		return m.FtpURL
+	}
+	return ""
+}
+
+func (m *PendingMirror) GetSponsorName() string {
+	if m != nil {
+		return m.SponsorName
+	}
+	return ""
+}
+
+func (m *PendingMirror) GetSponsorURL() string {
+	if m != nil {
+		return m.SponsorURL
+	}
+	return ""
+}
+
+func (m *PendingMirror) GetAdminName() string {
+	if m != nil {
+		return m.AdminName
+	}
+	return ""
+}
+
+func (m *PendingMirror) GetAdminEmail() string {
+	if m != nil {
+		return m.AdminEmail
+	}
+	return ""
+}
+
+func (m *PendingMirror) GetComment() string {
+	if m != nil {
+		return m.Comment
+	}
+	return ""
+}
+
+func (m *PendingMirror) GetRemoteIP() string {
+	if m != nil {
+		return m.RemoteIP
+	}
+	return ""
+}
+
+func (m *PendingMirror) GetSubmitted() *timestamp.Timestamp {
+	if m != nil {
+		return m.Submitted
+	}
+	return nil
+}
+
+type PendingMirrorsReply struct {
+	Mirrors              []*PendingMirror `protobuf:"bytes,1,rep,name=Mirrors,proto3" json:"Mirrors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PendingMirrorsReply) Reset()         { *m = PendingMirrorsReply{} }
+func (m *PendingMirrorsReply) String() string { return proto.CompactTextString(m) }
+func (*PendingMirrorsReply) ProtoMessage()    {}
+func (*PendingMirrorsReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{55}
+}
+
+func (m *PendingMirrorsReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PendingMirrorsReply.Unmarshal(m, b)
+}
+func (m *PendingMirrorsReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PendingMirrorsReply.Marshal(b, m, deterministic)
+}
+func (m *PendingMirrorsReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PendingMirrorsReply.Merge(m, src)
+}
+func (m *PendingMirrorsReply) XXX_Size() int {
+	return xxx_messageInfo_PendingMirrorsReply.Size(m)
+}
+func (m *PendingMirrorsReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_PendingMirrorsReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PendingMirrorsReply proto.InternalMessageInfo
+
+func (m *PendingMirrorsReply) GetMirrors() []*PendingMirror {
+	if m != nil {
+		return m.Mirrors
+	}
+	return nil
+}
+
+type PendingMirrorRequest struct {
+	ID                   int32    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PendingMirrorRequest) Reset()         { *m = PendingMirrorRequest{} }
+func (m *PendingMirrorRequest) String() string { return proto.CompactTextString(m) }
+func (*PendingMirrorRequest) ProtoMessage()    {}
+func (*PendingMirrorRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{56}
+}
+
+func (m *PendingMirrorRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PendingMirrorRequest.Unmarshal(m, b)
+}
+func (m *PendingMirrorRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PendingMirrorRequest.Marshal(b, m, deterministic)
+}
+func (m *PendingMirrorRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PendingMirrorRequest.Merge(m, src)
+}
+func (m *PendingMirrorRequest) XXX_Size() int {
+	return xxx_messageInfo_PendingMirrorRequest.Size(m)
+}
+func (m *PendingMirrorRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PendingMirrorRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PendingMirrorRequest proto.InternalMessageInfo
+
+func (m *PendingMirrorRequest) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+type VerifyContactRequest struct {
+	ID                   int32    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Bounced              bool     `protobuf:"varint,2,opt,name=Bounced,proto3" json:"Bounced,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifyContactRequest) Reset()         { *m = VerifyContactRequest{} }
+func (m *VerifyContactRequest) String() string { return proto.CompactTextString(m) }
+func (*VerifyContactRequest) ProtoMessage()    {}
+func (*VerifyContactRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{57}
+}
+
+func (m *VerifyContactRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyContactRequest.Unmarshal(m, b)
+}
+func (m *VerifyContactRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyContactRequest.Marshal(b, m, deterministic)
+}
+func (m *VerifyContactRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyContactRequest.Merge(m, src)
+}
+func (m *VerifyContactRequest) XXX_Size() int {
+	return xxx_messageInfo_VerifyContactRequest.Size(m)
+}
+func (m *VerifyContactRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyContactRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyContactRequest proto.InternalMessageInfo
+
+func (m *VerifyContactRequest) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *VerifyContactRequest) GetBounced() bool {
+	if m != nil {
+		return m.Bounced
+	}
+	return false
+}
+
+type ContactStatus struct {
+	ID                   int32                `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Email                string               `protobuf:"bytes,2,opt,name=Email,proto3" json:"Email,omitempty"`
+	Status               string               `protobuf:"bytes,3,opt,name=Status,proto3" json:"Status,omitempty"`
+	Since                *timestamp.Timestamp `protobuf:"bytes,4,opt,name=Since,proto3" json:"Since,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ContactStatus) Reset()         { *m = ContactStatus{} }
+func (m *ContactStatus) String() string { return proto.CompactTextString(m) }
+func (*ContactStatus) ProtoMessage()    {}
+func (*ContactStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{58}
+}
+
+func (m *ContactStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ContactStatus.Unmarshal(m, b)
+}
+func (m *ContactStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ContactStatus.Marshal(b, m, deterministic)
+}
+func (m *ContactStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ContactStatus.Merge(m, src)
+}
+func (m *ContactStatus) XXX_Size() int {
+	return xxx_messageInfo_ContactStatus.Size(m)
+}
+func (m *ContactStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_ContactStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ContactStatus proto.InternalMessageInfo
+
+func (m *ContactStatus) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *ContactStatus) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *ContactStatus) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *ContactStatus) GetSince() *timestamp.Timestamp {
+	if m != nil {
+		return m.Since
+	}
+	return nil
+}
+
+type ContactStatusesReply struct {
+	Statuses             []*ContactStatus `protobuf:"bytes,1,rep,name=Statuses,proto3" json:"Statuses,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *ContactStatusesReply) Reset()         { *m = ContactStatusesReply{} }
+func (m *ContactStatusesReply) String() string { return proto.CompactTextString(m) }
+func (*ContactStatusesReply) ProtoMessage()    {}
+func (*ContactStatusesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{59}
+}
+
+func (m *ContactStatusesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ContactStatusesReply.Unmarshal(m, b)
+}
+func (m *ContactStatusesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ContactStatusesReply.Marshal(b, m, deterministic)
+}
+func (m *ContactStatusesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ContactStatusesReply.Merge(m, src)
+}
+func (m *ContactStatusesReply) XXX_Size() int {
+	return xxx_messageInfo_ContactStatusesReply.Size(m)
+}
+func (m *ContactStatusesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ContactStatusesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ContactStatusesReply proto.InternalMessageInfo
+
+func (m *ContactStatusesReply) GetStatuses() []*ContactStatus {
+	if m != nil {
+		return m.Statuses
+	}
+	return nil
+}
+
+type APIKeyReply struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=Key,proto3" json:"Key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *APIKeyReply) Reset()         { *m = APIKeyReply{} }
+func (m *APIKeyReply) String() string { return proto.CompactTextString(m) }
+func (*APIKeyReply) ProtoMessage()    {}
+func (*APIKeyReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{60}
+}
+
+func (m *APIKeyReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_APIKeyReply.Unmarshal(m, b)
+}
+func (m *APIKeyReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_APIKeyReply.Marshal(b, m, deterministic)
+}
+func (m *APIKeyReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_APIKeyReply.Merge(m, src)
+}
+func (m *APIKeyReply) XXX_Size() int {
+	return xxx_messageInfo_APIKeyReply.Size(m)
+}
+func (m *APIKeyReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_APIKeyReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_APIKeyReply proto.InternalMessageInfo
+
+func (m *APIKeyReply) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+type HTTPErrorCount struct {
+	Source               string   `protobuf:"bytes,1,opt,name=Source,proto3" json:"Source,omitempty"`
+	Code                 int32    `protobuf:"varint,2,opt,name=Code,proto3" json:"Code,omitempty"`
+	Count                int64    `protobuf:"varint,3,opt,name=Count,proto3" json:"Count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HTTPErrorCount) Reset()         { *m = HTTPErrorCount{} }
+func (m *HTTPErrorCount) String() string { return proto.CompactTextString(m) }
+func (*HTTPErrorCount) ProtoMessage()    {}
+func (*HTTPErrorCount) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{61}
+}
+
+func (m *HTTPErrorCount) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HTTPErrorCount.Unmarshal(m, b)
+}
+func (m *HTTPErrorCount) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HTTPErrorCount.Marshal(b, m, deterministic)
+}
+func (m *HTTPErrorCount) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HTTPErrorCount.Merge(m, src)
+}
+func (m *HTTPErrorCount) XXX_Size() int {
+	return xxx_messageInfo_HTTPErrorCount.Size(m)
+}
+func (m *HTTPErrorCount) XXX_DiscardUnknown() {
+	xxx_messageInfo_HTTPErrorCount.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HTTPErrorCount proto.InternalMessageInfo
+
+func (m *HTTPErrorCount) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *HTTPErrorCount) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *HTTPErrorCount) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type MirrorHTTPErrors struct {
+	ID                   int32             `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Errors               []*HTTPErrorCount `protobuf:"bytes,2,rep,name=Errors,proto3" json:"Errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *MirrorHTTPErrors) Reset()         { *m = MirrorHTTPErrors{} }
+func (m *MirrorHTTPErrors) String() string { return proto.CompactTextString(m) }
+func (*MirrorHTTPErrors) ProtoMessage()    {}
+func (*MirrorHTTPErrors) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{62}
+}
+
+func (m *MirrorHTTPErrors) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MirrorHTTPErrors.Unmarshal(m, b)
+}
+func (m *MirrorHTTPErrors) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MirrorHTTPErrors.Marshal(b, m, deterministic)
+}
+func (m *MirrorHTTPErrors) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MirrorHTTPErrors.Merge(m, src)
+}
+func (m *MirrorHTTPErrors) XXX_Size() int {
+	return xxx_messageInfo_MirrorHTTPErrors.Size(m)
+}
+func (m *MirrorHTTPErrors) XXX_DiscardUnknown() {
+	xxx_messageInfo_MirrorHTTPErrors.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MirrorHTTPErrors proto.InternalMessageInfo
+
+func (m *MirrorHTTPErrors) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *MirrorHTTPErrors) GetErrors() []*HTTPErrorCount {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+type HTTPErrorsReply struct {
+	Mirrors              []*MirrorHTTPErrors `protobuf:"bytes,1,rep,name=Mirrors,proto3" json:"Mirrors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *HTTPErrorsReply) Reset()         { *m = HTTPErrorsReply{} }
+func (m *HTTPErrorsReply) String() string { return proto.CompactTextString(m) }
+func (*HTTPErrorsReply) ProtoMessage()    {}
+func (*HTTPErrorsReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{63}
+}
+
+func (m *HTTPErrorsReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HTTPErrorsReply.Unmarshal(m, b)
+}
+func (m *HTTPErrorsReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HTTPErrorsReply.Marshal(b, m, deterministic)
+}
+func (m *HTTPErrorsReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HTTPErrorsReply.Merge(m, src)
+}
+func (m *HTTPErrorsReply) XXX_Size() int {
+	return xxx_messageInfo_HTTPErrorsReply.Size(m)
+}
+func (m *HTTPErrorsReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_HTTPErrorsReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HTTPErrorsReply proto.InternalMessageInfo
+
+func (m *HTTPErrorsReply) GetMirrors() []*MirrorHTTPErrors {
+	if m != nil {
+		return m.Mirrors
+	}
+	return nil
+}
+
+type CountryCoverage struct {
+	CountryCode          string   `protobuf:"bytes,1,opt,name=CountryCode,proto3" json:"CountryCode,omitempty"`
+	Requests             int64    `protobuf:"varint,2,opt,name=Requests,proto3" json:"Requests,omitempty"`
+	AverageDistance      float32  `protobuf:"fixed32,3,opt,name=AverageDistance,proto3" json:"AverageDistance,omitempty"`
+	LocalMirrors         int32    `protobuf:"varint,4,opt,name=LocalMirrors,proto3" json:"LocalMirrors,omitempty"`
+	Gap                  bool     `protobuf:"varint,5,opt,name=Gap,proto3" json:"Gap,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CountryCoverage) Reset()         { *m = CountryCoverage{} }
+func (m *CountryCoverage) String() string { return proto.CompactTextString(m) }
+func (*CountryCoverage) ProtoMessage()    {}
+func (*CountryCoverage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{64}
+}
+
+func (m *CountryCoverage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CountryCoverage.Unmarshal(m, b)
+}
+func (m *CountryCoverage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CountryCoverage.Marshal(b, m, deterministic)
+}
+func (m *CountryCoverage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CountryCoverage.Merge(m, src)
+}
+func (m *CountryCoverage) XXX_Size() int {
+	return xxx_messageInfo_CountryCoverage.Size(m)
+}
+func (m *CountryCoverage) XXX_DiscardUnknown() {
+	xxx_messageInfo_CountryCoverage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CountryCoverage proto.InternalMessageInfo
+
+func (m *CountryCoverage) GetCountryCode() string {
+	if m != nil {
+		return m.CountryCode
+	}
+	return ""
+}
+
+func (m *CountryCoverage) GetRequests() int64 {
+	if m != nil {
+		return m.Requests
+	}
+	return 0
+}
+
+func (m *CountryCoverage) GetAverageDistance() float32 {
+	if m != nil {
+		return m.AverageDistance
+	}
+	return 0
+}
+
+func (m *CountryCoverage) GetLocalMirrors() int32 {
+	if m != nil {
+		return m.LocalMirrors
+	}
+	return 0
+}
+
+func (m *CountryCoverage) GetGap() bool {
+	if m != nil {
+		return m.Gap
+	}
+	return false
+}
+
+type CoverageReply struct {
+	Generated            *timestamp.Timestamp `protobuf:"bytes,1,opt,name=Generated,proto3" json:"Generated,omitempty"`
+	Days                 int32                `protobuf:"varint,2,opt,name=Days,proto3" json:"Days,omitempty"`
+	Countries            []*CountryCoverage   `protobuf:"bytes,3,rep,name=Countries,proto3" json:"Countries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *CoverageReply) Reset()         { *m = CoverageReply{} }
+func (m *CoverageReply) String() string { return proto.CompactTextString(m) }
+func (*CoverageReply) ProtoMessage()    {}
+func (*CoverageReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_77a6da22d6a3feb1, []int{65}
+}
+
+func (m *CoverageReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CoverageReply.Unmarshal(m, b)
+}
+func (m *CoverageReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CoverageReply.Marshal(b, m, deterministic)
+}
+func (m *CoverageReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CoverageReply.Merge(m, src)
+}
+func (m *CoverageReply) XXX_Size() int {
+	return xxx_messageInfo_CoverageReply.Size(m)
+}
+func (m *CoverageReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_CoverageReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CoverageReply proto.InternalMessageInfo
+
+func (m *CoverageReply) GetGenerated() *timestamp.Timestamp {
+	if m != nil {
+		return m.Generated
+	}
+	return nil
+}
+
+func (m *CoverageReply) GetDays() int32 {
+	if m != nil {
+		return m.Days
+	}
+	return 0
+}
+
+func (m *CoverageReply) GetCountries() []*CountryCoverage {
+	if m != nil {
+		return m.Countries
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("ScanMirrorRequest_Method", ScanMirrorRequest_Method_name, ScanMirrorRequest_Method_value)
+	proto.RegisterType((*VersionReply)(nil), "VersionReply")
+	proto.RegisterType((*GeoIPDatabase)(nil), "GeoIPDatabase")
+	proto.RegisterType((*StatusReply)(nil), "StatusReply")
+	proto.RegisterType((*QuarantinedMirror)(nil), "QuarantinedMirror")
+	proto.RegisterType((*QueuedScan)(nil), "QueuedScan")
+	proto.RegisterType((*WorkerPool)(nil), "WorkerPool")
+	proto.RegisterType((*PauseRequest)(nil), "PauseRequest")
+	proto.RegisterType((*GCRequest)(nil), "GCRequest")
+	proto.RegisterType((*GCReply)(nil), "GCReply")
+	proto.RegisterType((*DBInfoRequest)(nil), "DBInfoRequest")
+	proto.RegisterType((*KeyFamily)(nil), "KeyFamily")
+	proto.RegisterType((*DBInfoReply)(nil), "DBInfoReply")
+	proto.RegisterType((*MatchRequest)(nil), "MatchRequest")
+	proto.RegisterType((*Mirror)(nil), "Mirror")
+	proto.RegisterType((*Probe)(nil), "Probe")
+	proto.RegisterMapType((map[string]string)(nil), "Probe.HeadersEntry")
+	proto.RegisterType((*Schedule)(nil), "Schedule")
+	proto.RegisterType((*SchedulePeriod)(nil), "SchedulePeriod")
+	proto.RegisterType((*Endpoint)(nil), "Endpoint")
+	proto.RegisterType((*PathRewrite)(nil), "PathRewrite")
+	proto.RegisterType((*MirrorListReply)(nil), "MirrorListReply")
+	proto.RegisterType((*MirrorID)(nil), "MirrorID")
+	proto.RegisterType((*MatchReply)(nil), "MatchReply")
+	proto.RegisterType((*ListFilesRequest)(nil), "ListFilesRequest")
+	proto.RegisterType((*ListFilesReply)(nil), "ListFilesReply")
+	proto.RegisterType((*RequestTraceRequest)(nil), "RequestTraceRequest")
+	proto.RegisterType((*RequestTraceReply)(nil), "RequestTraceReply")
+	proto.RegisterType((*TraceExclusion)(nil), "TraceExclusion")
+	proto.RegisterType((*ChangeStatusRequest)(nil), "ChangeStatusRequest")
+	proto.RegisterType((*MirrorIDRequest)(nil), "MirrorIDRequest")
+	proto.RegisterType((*RenameMirrorRequest)(nil), "RenameMirrorRequest")
+	proto.RegisterType((*AddMirrorReply)(nil), "AddMirrorReply")
+	proto.RegisterType((*UpdateMirrorReply)(nil), "UpdateMirrorReply")
+	proto.RegisterType((*RefreshRepositoryRequest)(nil), "RefreshRepositoryRequest")
+	proto.RegisterType((*VerifyRepositoryRequest)(nil), "VerifyRepositoryRequest")
+	proto.RegisterType((*VerifyRepositoryReply)(nil), "VerifyRepositoryReply")
+	proto.RegisterType((*ScanMirrorRequest)(nil), "ScanMirrorRequest")
+	proto.RegisterType((*ScanMirrorReply)(nil), "ScanMirrorReply")
+	proto.RegisterType((*StatsFileRequest)(nil), "StatsFileRequest")
 	proto.RegisterType((*StatsFileReply)(nil), "StatsFileReply")
 	proto.RegisterMapType((map[string]int64)(nil), "StatsFileReply.FilesEntry")
 	proto.RegisterType((*StatsMirrorRequest)(nil), "StatsMirrorRequest")
 	proto.RegisterType((*StatsMirrorReply)(nil), "StatsMirrorReply")
 	proto.RegisterType((*GetMirrorLogsRequest)(nil), "GetMirrorLogsRequest")
 	proto.RegisterType((*GetMirrorLogsReply)(nil), "GetMirrorLogsReply")
+	proto.RegisterType((*GetScanSummariesRequest)(nil), "GetScanSummariesRequest")
+	proto.RegisterType((*ScanSummary)(nil), "ScanSummary")
+	proto.RegisterType((*GetScanSummariesReply)(nil), "GetScanSummariesReply")
+	proto.RegisterType((*PropagationRequest)(nil), "PropagationRequest")
+	proto.RegisterType((*FilePropagation)(nil), "FilePropagation")
+	proto.RegisterType((*PropagationReply)(nil), "PropagationReply")
+	proto.RegisterType((*RsyncModulesRequest)(nil), "RsyncModulesRequest")
+	proto.RegisterType((*RsyncModule)(nil), "RsyncModule")
+	proto.RegisterType((*RsyncModulesReply)(nil), "RsyncModulesReply")
+	proto.RegisterType((*SignURLRequest)(nil), "SignURLRequest")
+	proto.RegisterType((*SignURLReply)(nil), "SignURLReply")
+	proto.RegisterType((*PendingMirror)(nil), "PendingMirror")
+	proto.RegisterType((*PendingMirrorsReply)(nil), "PendingMirrorsReply")
+	proto.RegisterType((*PendingMirrorRequest)(nil), "PendingMirrorRequest")
+	proto.RegisterType((*VerifyContactRequest)(nil), "VerifyContactRequest")
+	proto.RegisterType((*ContactStatus)(nil), "ContactStatus")
+	proto.RegisterType((*ContactStatusesReply)(nil), "ContactStatusesReply")
+	proto.RegisterType((*APIKeyReply)(nil), "APIKeyReply")
+	proto.RegisterType((*HTTPErrorCount)(nil), "HTTPErrorCount")
+	proto.RegisterType((*MirrorHTTPErrors)(nil), "MirrorHTTPErrors")
+	proto.RegisterType((*HTTPErrorsReply)(nil), "HTTPErrorsReply")
+	proto.RegisterType((*CountryCoverage)(nil), "CountryCoverage")
+	proto.RegisterType((*CoverageReply)(nil), "CoverageReply")
+}
+
+func init() { proto.RegisterFile("rpc.proto", fileDescriptor_77a6da22d6a3feb1) }
+
+var fileDescriptor_77a6da22d6a3feb1 = []byte{
+	// 3875 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x3a, 0x5d, 0x6f, 0x1b, 0xc7,
+	0x76, 0x22, 0x29, 0x4a, 0xe4, 0x21, 0x45, 0x51, 0x63, 0x59, 0xd9, 0xf0, 0xba, 0x89, 0x33, 0x49,
+	0x1c, 0xd9, 0x49, 0xf6, 0x3a, 0xbe, 0xbe, 0xa9, 0xeb, 0x7b, 0x7b, 0x1b, 0x59, 0x92, 0x65, 0xd5,
+	0x92, 0xcd, 0x2c, 0xe5, 0x04, 0x0d, 0xd0, 0x0b, 0xac, 0xb9, 0x23, 0x69, 0x61, 0x72, 0x97, 0xdd,
+	0x0f, 0x5b, 0x2c, 0x0a, 0xf4, 0xa5, 0xaf, 0x7d, 0x29, 0x8a, 0x3e, 0xf5, 0xbd, 0x4f, 0xc5, 0x2d,
+	0xd0, 0x87, 0xfe, 0x84, 0x3e, 0xf4, 0xad, 0x40, 0x5f, 0x8b, 0xfe, 0x85, 0xfe, 0x83, 0xe2, 0x9c,
+	0x99, 0xd9, 0x9d, 0x5d, 0x52, 0x94, 0x92, 0x02, 0x05, 0xfa, 0x36, 0xe7, 0xcc, 0x99, 0x99, 0x33,
+	0x67, 0xce, 0xf7, 0x2e, 0x34, 0xa3, 0xc9, 0xd0, 0x9e, 0x44, 0x61, 0x12, 0xf6, 0x7e, 0x76, 0x16,
+	0x86, 0x67, 0x23, 0xf1, 0x73, 0x82, 0x5e, 0xa7, 0xa7, 0x3f, 0x17, 0xe3, 0x49, 0x32, 0x55, 0x93,
+	0x1f, 0x96, 0x27, 0x13, 0x7f, 0x2c, 0xe2, 0xc4, 0x1d, 0x4f, 0x24, 0x01, 0xff, 0xbb, 0x1a, 0xb4,
+	0xbf, 0x13, 0x51, 0xec, 0x87, 0x81, 0x23, 0x26, 0xa3, 0x29, 0xb3, 0x60, 0x55, 0xc1, 0x56, 0xe5,
+	0x76, 0x65, 0xbb, 0xe9, 0x68, 0x90, 0x6d, 0x42, 0xfd, 0x49, 0xea, 0x8f, 0x3c, 0xab, 0x4a, 0x78,
+	0x09, 0xb0, 0x5b, 0xd0, 0x3c, 0x08, 0xf5, 0x8a, 0x1a, 0xcd, 0xe4, 0x08, 0xd6, 0x81, 0xea, 0xcb,
+	0x81, 0xb5, 0x4c, 0xe8, 0xea, 0xcb, 0x01, 0x63, 0xb0, 0xbc, 0x13, 0x0d, 0xcf, 0xad, 0x3a, 0x61,
+	0x68, 0xcc, 0x3e, 0x00, 0x38, 0x08, 0x8f, 0xdd, 0x8b, 0x7e, 0x14, 0x0e, 0x63, 0x6b, 0xe5, 0x76,
+	0x65, 0xbb, 0xee, 0x18, 0x18, 0x9c, 0xdf, 0x0d, 0x83, 0x53, 0xff, 0xec, 0xa9, 0x3f, 0x12, 0xd6,
+	0x2a, 0xad, 0x34, 0x30, 0x6c, 0x0b, 0x56, 0x76, 0xc3, 0xf1, 0xd8, 0x4f, 0xac, 0x06, 0xcd, 0x29,
+	0x08, 0x39, 0x23, 0x16, 0xf7, 0xdc, 0x44, 0x58, 0x4d, 0xc9, 0x59, 0x86, 0x60, 0x1c, 0xda, 0x8e,
+	0xf0, 0xfc, 0x58, 0xb3, 0x0e, 0x44, 0x50, 0xc0, 0xe1, 0x0e, 0x7b, 0x4f, 0x34, 0x41, 0x8b, 0x18,
+	0xcb, 0x11, 0xec, 0x13, 0x58, 0xdb, 0x73, 0x13, 0xf7, 0xb5, 0x1b, 0x8b, 0xfd, 0x28, 0x0a, 0x23,
+	0xab, 0x4d, 0x5b, 0x14, 0x91, 0xec, 0x6b, 0xe8, 0x1c, 0x88, 0xf0, 0xb0, 0xaf, 0xb1, 0xb1, 0xb5,
+	0x76, 0xbb, 0xb6, 0xdd, 0x7a, 0xd0, 0xb1, 0x0b, 0x68, 0xa7, 0x44, 0xc5, 0x05, 0xac, 0x15, 0x30,
+	0xac, 0x07, 0x0d, 0xbc, 0x6e, 0xe0, 0x8e, 0x85, 0x7a, 0x99, 0x0c, 0x66, 0x8f, 0xcc, 0xab, 0xe2,
+	0xf3, 0xb4, 0x1e, 0xf4, 0x6c, 0xf9, 0xf4, 0xb6, 0x7e, 0x7a, 0xfb, 0x44, 0x3f, 0xbd, 0x21, 0x06,
+	0xfe, 0xbb, 0x55, 0x68, 0x0d, 0x12, 0x37, 0x49, 0xe3, 0xab, 0x9e, 0xff, 0x21, 0xac, 0x0e, 0x12,
+	0x37, 0x4a, 0x84, 0x77, 0x8d, 0x13, 0x34, 0x69, 0xe9, 0xf1, 0x6a, 0x33, 0x8f, 0xf7, 0x09, 0xac,
+	0x1d, 0xf9, 0x71, 0x22, 0x82, 0x1d, 0xcf, 0x8b, 0x44, 0x1c, 0x2b, 0x5d, 0x29, 0x22, 0xd9, 0x3d,
+	0xe8, 0x3a, 0xfd, 0xdd, 0x22, 0xa1, 0x54, 0xa1, 0x19, 0x3c, 0xfb, 0x02, 0x36, 0x32, 0xa1, 0x0a,
+	0x77, 0x78, 0xee, 0xbe, 0x1e, 0x09, 0xd2, 0xaa, 0x86, 0x33, 0x3b, 0x31, 0xfb, 0x88, 0xab, 0xf3,
+	0x1e, 0xf1, 0x16, 0x34, 0x8f, 0x7d, 0x1c, 0xc5, 0xaf, 0x26, 0xa4, 0x65, 0x75, 0x27, 0x47, 0xb0,
+	0xdb, 0xd0, 0x52, 0xc0, 0x5e, 0xf8, 0x2e, 0x20, 0x55, 0xab, 0x3b, 0x26, 0x8a, 0x6d, 0xc3, 0xba,
+	0x06, 0xfd, 0x18, 0xcf, 0xf5, 0x48, 0xdf, 0xea, 0x4e, 0x19, 0xcd, 0xfe, 0x18, 0xd8, 0x91, 0x1b,
+	0x27, 0x8e, 0x98, 0x84, 0xb1, 0x9f, 0x84, 0xd1, 0x74, 0x30, 0x74, 0xa5, 0xee, 0x2d, 0x16, 0xf8,
+	0x9c, 0x55, 0xf8, 0x96, 0xc7, 0x61, 0xe0, 0x27, 0x4a, 0x35, 0x1b, 0x8e, 0x06, 0x51, 0xf9, 0x9f,
+	0x09, 0x77, 0x94, 0x9c, 0xef, 0x9e, 0x8b, 0xe1, 0x1b, 0x54, 0x49, 0x64, 0xa6, 0x80, 0x43, 0x73,
+	0xc7, 0x5d, 0x62, 0xab, 0x43, 0x93, 0x12, 0xc0, 0x95, 0x7d, 0x11, 0x78, 0x7e, 0x70, 0x26, 0x27,
+	0xd7, 0xe5, 0x4a, 0x13, 0xc7, 0x9e, 0x40, 0x07, 0x07, 0x81, 0x1f, 0x9c, 0xf5, 0xdd, 0x34, 0x16,
+	0x9e, 0xd5, 0xbd, 0x92, 0xff, 0xd2, 0x0a, 0xf6, 0x14, 0xba, 0x8a, 0xd9, 0x7c, 0x97, 0x8d, 0x2b,
+	0x77, 0x99, 0x59, 0x83, 0x56, 0xb3, 0x27, 0xce, 0x22, 0xd7, 0x13, 0x9e, 0xc5, 0x48, 0x08, 0x19,
+	0x8c, 0x6f, 0xaf, 0xf8, 0xfe, 0x3e, 0xf2, 0x13, 0x11, 0x5b, 0x37, 0xe8, 0x32, 0x45, 0x24, 0xfb,
+	0x12, 0x5a, 0xdf, 0x87, 0xd1, 0x1b, 0x11, 0xf5, 0xc3, 0x70, 0x14, 0x5b, 0x9b, 0x64, 0xbd, 0x2d,
+	0x3b, 0xc7, 0x39, 0xe6, 0x3c, 0xbb, 0x0b, 0x4d, 0xbc, 0xca, 0xb7, 0xa9, 0x48, 0x85, 0x75, 0x53,
+	0x11, 0x13, 0xe4, 0x21, 0xde, 0xc9, 0x67, 0xd9, 0x43, 0x68, 0x7d, 0x9b, 0xba, 0x91, 0x1b, 0x24,
+	0x7e, 0x20, 0x3c, 0x6b, 0x8b, 0x88, 0x99, 0x6d, 0xe0, 0xa4, 0x76, 0x38, 0x26, 0x19, 0xff, 0xe7,
+	0x0a, 0x6c, 0xcc, 0x90, 0xe0, 0x3d, 0xe5, 0xe8, 0x70, 0x8f, 0x0c, 0xb7, 0xee, 0x64, 0x30, 0xda,
+	0xa0, 0x1c, 0xbf, 0x40, 0xdf, 0x21, 0xbd, 0xb7, 0x81, 0x61, 0xf7, 0xa1, 0x3e, 0xf0, 0x83, 0xa1,
+	0x34, 0xcf, 0xc5, 0x02, 0x96, 0x84, 0xa8, 0x1b, 0x68, 0xbd, 0xd2, 0x5a, 0x6b, 0x8e, 0x04, 0x90,
+	0x87, 0x7e, 0x24, 0xde, 0xfa, 0x61, 0x2a, 0xad, 0xb3, 0xe6, 0x64, 0x30, 0xff, 0xaf, 0x0a, 0x40,
+	0x2e, 0x85, 0xff, 0x15, 0xbb, 0x5b, 0xb0, 0x32, 0x08, 0xd3, 0x68, 0xa8, 0xdd, 0x89, 0x82, 0x88,
+	0xa9, 0x10, 0xd1, 0xcb, 0xf4, 0xce, 0x12, 0x60, 0x0f, 0x60, 0x45, 0x9e, 0x6b, 0xd5, 0xaf, 0xbc,
+	0x9d, 0xa2, 0x34, 0x5d, 0xdd, 0xca, 0xb5, 0x5d, 0x1d, 0xff, 0xab, 0x0a, 0x40, 0xae, 0x09, 0x18,
+	0xea, 0x5e, 0xe4, 0xbe, 0x9a, 0xc6, 0x68, 0x91, 0x92, 0x22, 0xa6, 0x7b, 0xd5, 0x1d, 0x0d, 0x22,
+	0xf5, 0x93, 0x34, 0x9e, 0xd2, 0x95, 0xea, 0x0e, 0x8d, 0xf1, 0xa2, 0x8a, 0xf5, 0x65, 0xc2, 0x6a,
+	0xf6, 0x6e, 0x41, 0x93, 0x46, 0x03, 0xff, 0xcf, 0x05, 0xdd, 0xaa, 0xee, 0xe4, 0x08, 0x7e, 0x0f,
+	0xda, 0xa4, 0xfb, 0x8e, 0xf8, 0xb3, 0x54, 0xc4, 0x09, 0x8a, 0x7a, 0x67, 0x98, 0xf8, 0x6f, 0xfd,
+	0x64, 0xaa, 0xe3, 0x86, 0x86, 0xf9, 0xc7, 0xd0, 0x3c, 0xd8, 0xd5, 0x84, 0x5b, 0xb0, 0xb2, 0x17,
+	0x4d, 0x9d, 0x54, 0x7a, 0xfe, 0x86, 0xa3, 0x20, 0xfe, 0x1f, 0x15, 0x58, 0x3d, 0xd8, 0x95, 0xe1,
+	0x21, 0x7b, 0x9b, 0xe7, 0x62, 0x1a, 0x13, 0x5d, 0xcd, 0x31, 0x30, 0xc8, 0x1a, 0xea, 0xc2, 0x61,
+	0x70, 0x1a, 0xca, 0x2b, 0xd6, 0x9c, 0x1c, 0x81, 0x8e, 0x12, 0x01, 0x49, 0x1f, 0xd3, 0x5d, 0x6b,
+	0x8e, 0x89, 0x42, 0xe7, 0x9d, 0x83, 0xfb, 0x41, 0x12, 0xf9, 0x99, 0x92, 0xcd, 0x4e, 0xa0, 0x38,
+	0x4f, 0xc6, 0x13, 0x62, 0x45, 0xea, 0x9b, 0x06, 0xc9, 0xc1, 0xb9, 0x81, 0x37, 0x12, 0x9e, 0xd4,
+	0xd3, 0x15, 0x9a, 0x2e, 0xe0, 0xf8, 0x5d, 0x58, 0xdb, 0x7b, 0x82, 0x8c, 0x69, 0x01, 0x58, 0xb0,
+	0x3a, 0x70, 0xc7, 0x93, 0x91, 0x90, 0x37, 0xab, 0x3b, 0x1a, 0xe4, 0x02, 0x9a, 0xcf, 0xc5, 0xf4,
+	0xa9, 0x3b, 0xf6, 0x47, 0xd3, 0xb9, 0x0f, 0xcb, 0x60, 0x99, 0xd8, 0x90, 0x57, 0xa6, 0x71, 0xbe,
+	0x9d, 0xa7, 0x6e, 0xaa, 0x41, 0x94, 0xf4, 0xb1, 0x18, 0x87, 0xd1, 0x54, 0x5d, 0x4d, 0x41, 0xdc,
+	0x87, 0x96, 0xe6, 0x08, 0x85, 0x7d, 0x07, 0x1a, 0x74, 0xa4, 0x4f, 0x0c, 0xa1, 0x73, 0x00, 0x3b,
+	0x63, 0xc3, 0xc9, 0xe6, 0xe6, 0x1e, 0xfe, 0x01, 0xc0, 0xab, 0x58, 0x78, 0xea, 0x18, 0x79, 0xbe,
+	0x81, 0xe1, 0xdb, 0xd0, 0x3e, 0x76, 0x93, 0xe1, 0xb9, 0x71, 0xf7, 0xbe, 0x9b, 0x24, 0x22, 0xca,
+	0xe2, 0xbe, 0x02, 0xf9, 0xdf, 0xb4, 0x61, 0x45, 0x39, 0x99, 0x0e, 0x54, 0x33, 0x7b, 0xad, 0x1e,
+	0xee, 0x65, 0x92, 0xa8, 0x16, 0x55, 0xfc, 0x59, 0x92, 0x4c, 0x5e, 0x39, 0x47, 0xca, 0x3c, 0x35,
+	0x88, 0x8a, 0xe8, 0xc4, 0xd3, 0x60, 0x88, 0x53, 0x32, 0xca, 0x67, 0x30, 0x4a, 0xe4, 0xa9, 0x5c,
+	0x24, 0xc3, 0xba, 0x82, 0x50, 0x63, 0x06, 0x93, 0x30, 0x88, 0x95, 0x33, 0x58, 0xa1, 0x49, 0x13,
+	0x85, 0x17, 0x55, 0x20, 0xae, 0x56, 0xd9, 0x61, 0x8e, 0x61, 0x77, 0xa0, 0xa3, 0xa0, 0xa3, 0xf0,
+	0x2c, 0x44, 0x1a, 0x99, 0x25, 0x96, 0xb0, 0xa8, 0xb9, 0x3b, 0xde, 0xd8, 0x0f, 0xe8, 0x1c, 0x95,
+	0x2d, 0x66, 0x08, 0x3c, 0x85, 0x80, 0xfd, 0xb1, 0xeb, 0x8f, 0x54, 0xae, 0x68, 0x60, 0x28, 0xcd,
+	0x49, 0xe3, 0x24, 0x1c, 0x63, 0xde, 0x60, 0xb5, 0x54, 0x9a, 0x93, 0x61, 0x30, 0xd4, 0xec, 0x86,
+	0xe4, 0xb0, 0x83, 0xe4, 0x65, 0x30, 0x9a, 0xaa, 0x80, 0x5c, 0x44, 0xe2, 0x6d, 0x77, 0xc3, 0x34,
+	0x48, 0xa2, 0x29, 0xd1, 0xac, 0x11, 0x8d, 0x89, 0x42, 0x39, 0xed, 0x0c, 0x68, 0xb2, 0x23, 0x6d,
+	0x54, 0x42, 0x32, 0x58, 0x87, 0x91, 0x50, 0xf1, 0x58, 0x02, 0x28, 0xf1, 0x23, 0x37, 0xf1, 0x93,
+	0xd4, 0x13, 0x14, 0x82, 0xab, 0x4e, 0x06, 0xe3, 0x7d, 0x8f, 0xc2, 0xe0, 0x4c, 0x4e, 0x6e, 0xd0,
+	0x64, 0x8e, 0x28, 0xf0, 0xbb, 0x1b, 0x7a, 0x82, 0x62, 0x67, 0xd3, 0x29, 0x22, 0xd1, 0xca, 0x14,
+	0x73, 0x08, 0x62, 0xfc, 0xac, 0x61, 0x0e, 0x6d, 0xe2, 0xd8, 0x03, 0xd8, 0xdc, 0xbf, 0x18, 0x8e,
+	0x52, 0x4f, 0x78, 0x05, 0xda, 0x4d, 0xa2, 0x9d, 0x3b, 0x87, 0xb7, 0xd9, 0x89, 0x83, 0x74, 0x6c,
+	0xdd, 0xbc, 0x5d, 0xd9, 0x5e, 0x73, 0x24, 0x80, 0x9a, 0x85, 0x99, 0xbd, 0x08, 0x12, 0x6b, 0x4b,
+	0x6a, 0x96, 0x02, 0x71, 0x66, 0x3f, 0x90, 0x69, 0xd5, 0x7b, 0x32, 0xd1, 0x51, 0x20, 0x6a, 0xec,
+	0xab, 0x89, 0x65, 0x11, 0xb2, 0xfa, 0x6a, 0x82, 0xf7, 0x52, 0x27, 0x3a, 0xc2, 0x8d, 0xc3, 0xc0,
+	0x7a, 0x5f, 0xde, 0xab, 0x80, 0x64, 0x8f, 0x01, 0x30, 0x27, 0x16, 0x32, 0x2a, 0xf6, 0xae, 0x0c,
+	0x01, 0x06, 0x35, 0xea, 0xdb, 0xce, 0x68, 0x14, 0xbe, 0xc3, 0x42, 0x22, 0x12, 0xc3, 0x24, 0xb6,
+	0x7e, 0x46, 0x4f, 0x52, 0xc2, 0xb2, 0xaf, 0xf1, 0x6d, 0xe2, 0x64, 0x30, 0x0d, 0x86, 0xd6, 0xad,
+	0x2b, 0x4f, 0xc8, 0x68, 0x75, 0x82, 0x38, 0x48, 0x87, 0x43, 0x11, 0xc7, 0xa7, 0xe9, 0x88, 0x76,
+	0xf8, 0xbd, 0xeb, 0x25, 0x88, 0xc5, 0x55, 0xec, 0xd7, 0xd0, 0x42, 0xec, 0x71, 0xe8, 0x21, 0x9d,
+	0xf5, 0xc1, 0x95, 0x9b, 0x98, 0xe4, 0x68, 0xfd, 0x87, 0xfd, 0xb7, 0x0f, 0xad, 0x0f, 0x49, 0xba,
+	0x34, 0x56, 0xb8, 0xaf, 0xad, 0xdb, 0x19, 0xee, 0x6b, 0xd4, 0xb4, 0xc3, 0xbe, 0xce, 0xda, 0x3f,
+	0x92, 0x96, 0x95, 0x21, 0x30, 0x35, 0x3e, 0x0a, 0x87, 0x6e, 0xe2, 0x87, 0xc1, 0xf7, 0x6e, 0x84,
+	0x19, 0xa0, 0xc5, 0x89, 0xa6, 0x8c, 0x66, 0x5d, 0xa8, 0xed, 0xee, 0xbd, 0xb0, 0x3e, 0xa6, 0xad,
+	0x71, 0x88, 0xfa, 0xbd, 0x7b, 0xee, 0x06, 0x81, 0x18, 0xc5, 0xd6, 0x27, 0xa4, 0x4f, 0x19, 0x2c,
+	0x93, 0xdf, 0xb7, 0xc2, 0x3b, 0x09, 0xad, 0x4f, 0xa5, 0xb6, 0x28, 0x90, 0xdd, 0xc7, 0x00, 0x99,
+	0x9c, 0x3b, 0xe2, 0x9d, 0xcc, 0xfa, 0xee, 0x90, 0x6b, 0x6d, 0xdb, 0x06, 0xd2, 0x29, 0x50, 0xe0,
+	0x9b, 0x1e, 0xbb, 0x41, 0xea, 0x8e, 0x34, 0x4b, 0xd6, 0x67, 0xc4, 0x44, 0x09, 0xcb, 0x3e, 0x83,
+	0xe6, 0x7e, 0xe0, 0x4d, 0x42, 0x3f, 0x48, 0x62, 0x6b, 0x9b, 0xb6, 0x6d, 0xda, 0x1a, 0xe3, 0xe4,
+	0x73, 0xa4, 0x86, 0x1a, 0xa0, 0x9a, 0xe1, 0x2e, 0x71, 0x5f, 0x44, 0xa2, 0x53, 0x71, 0xc4, 0x99,
+	0x1f, 0x06, 0x64, 0x81, 0xf7, 0xa4, 0x53, 0xc9, 0x31, 0xf9, 0x3c, 0x39, 0x84, 0xcf, 0x89, 0x25,
+	0x03, 0xc3, 0x3e, 0x85, 0xc6, 0x60, 0x78, 0x2e, 0xbc, 0x74, 0x24, 0xac, 0x2f, 0xe8, 0x6d, 0x9b,
+	0xb6, 0x46, 0x38, 0xd9, 0x14, 0xbb, 0x05, 0xf5, 0x7e, 0x14, 0xbe, 0x16, 0xd6, 0x97, 0x44, 0xb3,
+	0x62, 0x13, 0xe4, 0x48, 0x24, 0xda, 0x62, 0x3f, 0x0a, 0x2f, 0xa6, 0x96, 0x2d, 0xab, 0x7e, 0x02,
+	0xf8, 0xbf, 0x57, 0xd4, 0x22, 0xf6, 0x25, 0xac, 0x3e, 0x13, 0xae, 0x27, 0x22, 0x1d, 0xa3, 0x6e,
+	0xc8, 0xf5, 0xb6, 0xc2, 0x62, 0xac, 0x9e, 0x3a, 0x9a, 0x06, 0x9f, 0xec, 0x55, 0x2c, 0xa2, 0x20,
+	0x0f, 0x1b, 0x19, 0x8c, 0x73, 0x7d, 0x37, 0x8e, 0xdf, 0x85, 0x91, 0xa7, 0x62, 0x47, 0x06, 0xe3,
+	0x13, 0xec, 0x5f, 0x4c, 0xc4, 0x30, 0x11, 0x9e, 0x2c, 0x57, 0xad, 0xe5, 0xdb, 0x35, 0x34, 0xab,
+	0x22, 0xb6, 0xf7, 0x18, 0xda, 0xea, 0x28, 0x3a, 0x18, 0x95, 0xe6, 0x8d, 0xd0, 0x89, 0x0f, 0x0e,
+	0xf1, 0x42, 0x6f, 0xdd, 0x51, 0xaa, 0x8f, 0x97, 0xc0, 0xe3, 0xea, 0xa3, 0x0a, 0x7f, 0x93, 0xcb,
+	0x0b, 0x79, 0x41, 0x25, 0xff, 0x21, 0x0c, 0xb2, 0x6a, 0x5b, 0xc3, 0xa8, 0x5a, 0x7b, 0xe2, 0xd4,
+	0x4d, 0x47, 0x89, 0xce, 0xe2, 0x14, 0xc8, 0xee, 0xc2, 0x6a, 0x5f, 0x44, 0x7e, 0xe8, 0x61, 0x72,
+	0x83, 0xc2, 0x58, 0xcf, 0x04, 0x2e, 0xf1, 0x8e, 0x9e, 0xe7, 0xbf, 0x85, 0x4e, 0x71, 0x0a, 0x6d,
+	0x67, 0xcf, 0x9d, 0x4a, 0x31, 0x36, 0x1d, 0x1a, 0x23, 0xee, 0x69, 0x14, 0x8e, 0x75, 0x84, 0xc5,
+	0x31, 0xfa, 0xb4, 0x93, 0x50, 0x09, 0xa8, 0x7a, 0x12, 0x92, 0xef, 0x3f, 0x77, 0x23, 0xa1, 0xb2,
+	0x44, 0x09, 0xf0, 0xdf, 0x42, 0x43, 0x6b, 0x93, 0x19, 0x93, 0x2b, 0x33, 0x31, 0x39, 0x8b, 0x10,
+	0xd5, 0x45, 0x11, 0xa2, 0x56, 0x8a, 0x10, 0xfc, 0x4f, 0xa1, 0x65, 0xd8, 0x48, 0xc6, 0x68, 0x65,
+	0x86, 0xd1, 0x6a, 0xc6, 0xe8, 0x16, 0xac, 0x38, 0xe2, 0x4c, 0x5c, 0x4c, 0x68, 0xb7, 0x86, 0xa3,
+	0x20, 0x5c, 0x4b, 0x55, 0xae, 0xcc, 0xdb, 0x69, 0xcc, 0x1f, 0xea, 0x8a, 0x19, 0x8b, 0x7b, 0x99,
+	0x0e, 0x7d, 0x04, 0xab, 0x3a, 0x73, 0x94, 0x9a, 0xb6, 0x6a, 0x4b, 0xd8, 0xd1, 0x78, 0x6e, 0xe7,
+	0x65, 0xc5, 0x75, 0x92, 0x15, 0xfe, 0x15, 0x80, 0xca, 0x82, 0xf0, 0x80, 0x8f, 0xcb, 0x07, 0x34,
+	0x6d, 0xbd, 0x5b, 0x7e, 0xc4, 0x3d, 0xe8, 0x22, 0x4b, 0x94, 0x42, 0x1a, 0x99, 0x73, 0x3f, 0x12,
+	0xa7, 0xfe, 0x85, 0xba, 0xbe, 0x82, 0xf8, 0x1d, 0xe8, 0x18, 0xb4, 0x13, 0x19, 0xa7, 0x09, 0x52,
+	0x8f, 0x2c, 0x01, 0xfe, 0x0b, 0xb8, 0xa1, 0xb6, 0x3a, 0x89, 0xdc, 0x61, 0x96, 0xb9, 0xdf, 0x82,
+	0xa6, 0x1a, 0xaa, 0x8b, 0x34, 0x9d, 0x1c, 0xc1, 0xff, 0xb3, 0x0a, 0x1b, 0xc5, 0x55, 0x78, 0xc0,
+	0xc2, 0x35, 0xcc, 0x86, 0xe5, 0x13, 0x5f, 0xc9, 0x60, 0xb1, 0xa7, 0x5f, 0xd6, 0x2e, 0x1e, 0x1f,
+	0x59, 0x29, 0x1b, 0x8d, 0x49, 0xae, 0x7d, 0xdd, 0xd2, 0x3b, 0xec, 0xcb, 0xb0, 0x4c, 0xc1, 0x5b,
+	0xe5, 0x6e, 0x1a, 0xa4, 0x30, 0x3e, 0x78, 0x91, 0x8e, 0x55, 0xf6, 0x2d, 0x01, 0x14, 0xd6, 0xcb,
+	0x34, 0x99, 0xa4, 0x89, 0x4a, 0xd6, 0x14, 0x84, 0x78, 0x65, 0xd9, 0xb2, 0xc1, 0xa2, 0x20, 0xdc,
+	0x45, 0x76, 0x66, 0x64, 0x52, 0x26, 0x01, 0xea, 0x86, 0xb9, 0xa3, 0xd1, 0x6b, 0x77, 0xf8, 0x86,
+	0xd2, 0xb1, 0x86, 0x93, 0xc1, 0xe4, 0xfa, 0xd5, 0x3b, 0xb6, 0x48, 0xcc, 0x1a, 0x64, 0x9f, 0x43,
+	0x43, 0x27, 0x1c, 0x56, 0x5b, 0x19, 0x28, 0x09, 0x8f, 0xb0, 0xd4, 0x04, 0xcd, 0x08, 0xf8, 0xaf,
+	0xa1, 0x53, 0x9c, 0x9b, 0x9b, 0xf9, 0x93, 0x52, 0x53, 0x2a, 0x21, 0x15, 0x4b, 0x41, 0xfc, 0x8f,
+	0xe0, 0x06, 0xc6, 0xa2, 0x33, 0xa1, 0xbb, 0x6b, 0xf2, 0x4d, 0xcb, 0x5a, 0x69, 0xa4, 0x2e, 0xd5,
+	0x42, 0xea, 0xc2, 0x3f, 0xd2, 0x16, 0x70, 0xb8, 0x77, 0xc9, 0x62, 0xfe, 0x07, 0xa8, 0x37, 0xe8,
+	0x3a, 0x95, 0x1d, 0x5c, 0x72, 0xc6, 0x3c, 0xcd, 0xff, 0xa7, 0x0a, 0x74, 0x76, 0x3c, 0xdd, 0x60,
+	0x20, 0xd5, 0x31, 0x7d, 0x41, 0x65, 0x91, 0x2f, 0xa8, 0x96, 0xb3, 0x45, 0x43, 0x05, 0x6a, 0x45,
+	0x15, 0xb8, 0x05, 0xcd, 0x2c, 0x65, 0x54, 0x3a, 0x93, 0x23, 0xd0, 0x39, 0xef, 0x0c, 0x5e, 0x28,
+	0xb5, 0xc1, 0x21, 0xf2, 0xa0, 0xc2, 0x3d, 0xd6, 0x6c, 0x14, 0xd1, 0x35, 0xcc, 0x77, 0x61, 0xe3,
+	0xd5, 0xc4, 0x73, 0x13, 0x61, 0x32, 0x8d, 0x4e, 0xd3, 0x3f, 0x3d, 0xd5, 0x4f, 0x82, 0xe3, 0xc2,
+	0x26, 0xd5, 0xd2, 0x26, 0x4f, 0xc1, 0x72, 0xc4, 0x69, 0x24, 0xe2, 0xf3, 0xbc, 0x59, 0x66, 0x98,
+	0xb1, 0x23, 0xce, 0xdd, 0xf8, 0x5c, 0x17, 0xc0, 0x12, 0x22, 0x2b, 0x48, 0xe3, 0x73, 0xf5, 0x40,
+	0x34, 0xe6, 0x5f, 0xc1, 0x7b, 0xdf, 0x89, 0xc8, 0x3f, 0x9d, 0xce, 0xdd, 0x66, 0xae, 0x37, 0xf8,
+	0x16, 0x6e, 0xce, 0x2e, 0x51, 0x3d, 0x57, 0xea, 0xb9, 0x09, 0x4f, 0x55, 0xd4, 0x1a, 0x94, 0xe5,
+	0x76, 0x3c, 0x46, 0x17, 0x25, 0xf4, 0x5d, 0x0c, 0x0c, 0xff, 0x97, 0x0a, 0x6c, 0xa0, 0xbb, 0x5c,
+	0xfc, 0xfe, 0x58, 0xbc, 0xa4, 0x49, 0x28, 0x15, 0x4b, 0xdd, 0xc2, 0xc0, 0xb0, 0x5f, 0x62, 0xdf,
+	0x26, 0x4c, 0xc2, 0x61, 0x38, 0xa2, 0xf7, 0xeb, 0x3c, 0x78, 0xdf, 0x9e, 0xd9, 0xd5, 0x3e, 0x16,
+	0xc9, 0x79, 0xe8, 0x39, 0x19, 0xe9, 0xfc, 0x7e, 0x0b, 0xff, 0x14, 0x56, 0x24, 0x25, 0x5b, 0x85,
+	0xda, 0xce, 0xd1, 0x51, 0x77, 0x09, 0x07, 0x4f, 0x4f, 0xfa, 0xdd, 0x0a, 0x6b, 0x42, 0xdd, 0x19,
+	0xfc, 0xc9, 0x8b, 0xdd, 0x6e, 0x95, 0xff, 0x63, 0x05, 0xd6, 0xcd, 0x33, 0x94, 0x1c, 0xb4, 0x2d,
+	0x54, 0x8a, 0x69, 0x3c, 0x87, 0x36, 0x79, 0xca, 0xc3, 0xc0, 0x13, 0x17, 0xca, 0x54, 0x6a, 0x4e,
+	0x01, 0x87, 0x34, 0xcf, 0x83, 0xf0, 0x5d, 0xa0, 0x69, 0x64, 0xcd, 0x5b, 0xc0, 0xe1, 0x09, 0x8e,
+	0x18, 0x63, 0x1e, 0xa8, 0x2a, 0x6f, 0x0d, 0xa2, 0x8c, 0x4e, 0x7e, 0x78, 0x79, 0x7a, 0x1a, 0x8b,
+	0xe4, 0x58, 0x77, 0x13, 0x0c, 0x0c, 0xff, 0xfb, 0x0a, 0x74, 0xd1, 0x92, 0x63, 0x3c, 0xf3, 0xca,
+	0xa2, 0x19, 0x1b, 0xf2, 0xd8, 0x5e, 0xa7, 0xd6, 0xd0, 0x75, 0x1a, 0xf2, 0x19, 0x31, 0xf6, 0x9e,
+	0x10, 0xd8, 0x0f, 0xbc, 0x6b, 0xb4, 0xe3, 0x34, 0x29, 0xff, 0x0b, 0xe8, 0x18, 0xdc, 0xa1, 0x30,
+	0xef, 0x43, 0xfd, 0x34, 0x8b, 0x34, 0xb8, 0x4b, 0x71, 0xde, 0xc6, 0x91, 0x4a, 0xce, 0x24, 0x61,
+	0xef, 0x11, 0x40, 0x8e, 0xbc, 0x2a, 0x71, 0xaa, 0x99, 0x89, 0xd3, 0xdf, 0x56, 0x80, 0xd1, 0xf6,
+	0x8b, 0xf5, 0xf0, 0xff, 0x5a, 0x28, 0x02, 0xba, 0x05, 0xae, 0x50, 0x2c, 0x1f, 0xea, 0x66, 0x06,
+	0xf1, 0x65, 0xe4, 0x10, 0x2b, 0x79, 0x23, 0x55, 0xf1, 0xaf, 0x1b, 0x2a, 0x19, 0x4c, 0x5f, 0xc0,
+	0xa6, 0x58, 0x32, 0x48, 0xdd, 0x92, 0x00, 0x7f, 0x0a, 0x9b, 0x07, 0x22, 0x51, 0xd9, 0x4a, 0x78,
+	0x16, 0x2f, 0x30, 0xc3, 0x63, 0xf7, 0xc2, 0x11, 0x71, 0x3a, 0x4a, 0x74, 0xff, 0xcf, 0xc0, 0xf0,
+	0x6d, 0x60, 0xa5, 0x7d, 0x94, 0x83, 0x1b, 0xf9, 0x94, 0x84, 0x52, 0x56, 0x88, 0x63, 0x7e, 0x08,
+	0xef, 0x1d, 0x88, 0x04, 0xcd, 0x67, 0x90, 0x8e, 0xc7, 0x6e, 0xe4, 0x8b, 0x9f, 0x7c, 0xe8, 0xbf,
+	0x55, 0xa1, 0x95, 0x6f, 0x34, 0xc5, 0x37, 0xca, 0x24, 0x69, 0x55, 0xae, 0x94, 0x75, 0x4e, 0x8c,
+	0x27, 0xed, 0xa5, 0x11, 0x15, 0x42, 0xc7, 0x5a, 0x74, 0x06, 0x86, 0x6d, 0x69, 0xc7, 0xa0, 0xdb,
+	0xb6, 0x12, 0x9a, 0xb1, 0xed, 0xe5, 0x6b, 0xd8, 0x76, 0x7d, 0x8e, 0x6d, 0x63, 0xb6, 0xe1, 0x79,
+	0xc2, 0xcb, 0xb2, 0x0d, 0x04, 0x4c, 0x8b, 0x5f, 0x2d, 0x5a, 0x7c, 0x96, 0x57, 0x34, 0xcc, 0xbc,
+	0xe2, 0x76, 0xb1, 0x27, 0xdf, 0x94, 0x2d, 0x18, 0x03, 0x25, 0xfb, 0xa9, 0x43, 0x31, 0x49, 0xd4,
+	0x47, 0x9c, 0x86, 0x93, 0xc1, 0x7c, 0x17, 0x6e, 0xce, 0x3e, 0x0c, 0xbe, 0xe2, 0x3d, 0x68, 0x66,
+	0x18, 0x65, 0x91, 0x6d, 0xdb, 0x90, 0xbb, 0x93, 0x4f, 0xf3, 0x2f, 0x80, 0xf5, 0xa3, 0x70, 0xe2,
+	0x9e, 0x91, 0xe4, 0xae, 0x8a, 0x2a, 0xff, 0x50, 0x81, 0x75, 0x94, 0x95, 0xb1, 0x24, 0x4b, 0xdb,
+	0x2a, 0x46, 0xda, 0x66, 0x24, 0x45, 0xd5, 0x62, 0x52, 0x44, 0x33, 0x71, 0x8c, 0x95, 0x77, 0x4d,
+	0xcf, 0x10, 0x88, 0x4f, 0xda, 0x17, 0xd1, 0x50, 0x04, 0x89, 0x7b, 0x26, 0xdd, 0x7c, 0xd5, 0x31,
+	0x30, 0xec, 0x0b, 0xa8, 0xed, 0x9f, 0xec, 0x5c, 0xa3, 0xb1, 0x8e, 0x64, 0xfc, 0x31, 0x74, 0x0b,
+	0xf7, 0x92, 0x2d, 0x4e, 0x23, 0x1f, 0x6e, 0x3d, 0xe8, 0xda, 0xa5, 0xab, 0xe8, 0x0c, 0xf9, 0x33,
+	0xb8, 0x41, 0xbd, 0xc2, 0xe3, 0x10, 0x0b, 0xa6, 0x4c, 0xdb, 0xbb, 0x50, 0xcb, 0x8b, 0x1a, 0x1c,
+	0xf2, 0x37, 0xd0, 0x32, 0x08, 0x2f, 0x6b, 0xc2, 0xeb, 0x3e, 0x52, 0xb5, 0xd8, 0x47, 0xb2, 0x81,
+	0x61, 0x72, 0xe2, 0xfa, 0x41, 0x9c, 0xc7, 0x68, 0x55, 0xac, 0xcc, 0x99, 0xe1, 0xbf, 0x82, 0x8d,
+	0x22, 0x57, 0xf2, 0x4a, 0xab, 0x0a, 0xce, 0x1e, 0xda, 0x20, 0x72, 0xf4, 0x24, 0xff, 0x06, 0x3a,
+	0x03, 0xff, 0x2c, 0x78, 0xe5, 0x1c, 0xe9, 0xdb, 0xcc, 0x7b, 0xb6, 0x1e, 0x34, 0xbe, 0x73, 0x47,
+	0xbe, 0x87, 0xdd, 0x7b, 0xe5, 0x8e, 0x34, 0xcc, 0x7f, 0x80, 0x76, 0xb6, 0x83, 0x72, 0x15, 0xf3,
+	0x9e, 0x7d, 0xff, 0x62, 0xe2, 0x47, 0x42, 0x9b, 0xa4, 0x06, 0x31, 0x35, 0xc3, 0xd5, 0x6e, 0x92,
+	0x46, 0xfa, 0x4b, 0x4a, 0x8e, 0xe0, 0xff, 0x5d, 0xcd, 0x3e, 0x8e, 0xfd, 0x3f, 0x6e, 0xfe, 0x16,
+	0x9a, 0xba, 0x8d, 0xc5, 0x4d, 0xdd, 0xe6, 0x4c, 0x53, 0xd7, 0x50, 0x14, 0x28, 0x2a, 0x0a, 0x05,
+	0x89, 0x71, 0x98, 0x88, 0xc3, 0xbe, 0x6a, 0xf6, 0x66, 0x30, 0x7a, 0xd0, 0x41, 0xfa, 0x7a, 0xec,
+	0x27, 0x09, 0x15, 0x19, 0x57, 0x7a, 0xd0, 0x8c, 0x18, 0x4b, 0x86, 0x82, 0xc8, 0x95, 0x42, 0x6d,
+	0x97, 0xcb, 0xd2, 0x8e, 0x5d, 0x20, 0xcb, 0x6b, 0xd3, 0x3b, 0xb0, 0x59, 0x9c, 0xb9, 0xa4, 0x6e,
+	0xf8, 0x06, 0x36, 0x65, 0x26, 0x4a, 0x3a, 0x3d, 0x4c, 0x16, 0x14, 0x27, 0x4f, 0xc2, 0x34, 0x18,
+	0xe6, 0xc5, 0x89, 0x02, 0xf9, 0x5f, 0xca, 0xfe, 0xb0, 0x3b, 0x4c, 0x54, 0x95, 0x56, 0x5e, 0x8a,
+	0xde, 0x95, 0xc4, 0xaa, 0xba, 0x2c, 0x04, 0x18, 0x35, 0x9e, 0xfe, 0x74, 0x27, 0x57, 0x67, 0x5f,
+	0x20, 0x97, 0xaf, 0xf9, 0x05, 0x92, 0x3f, 0x81, 0xcd, 0x02, 0x03, 0xb9, 0xa3, 0x6d, 0x68, 0x44,
+	0x26, 0xad, 0x02, 0xa1, 0x93, 0xcd, 0xf3, 0x0f, 0xa1, 0xb5, 0xd3, 0x3f, 0x7c, 0x2e, 0x54, 0x1a,
+	0xde, 0x85, 0xda, 0xf3, 0x3c, 0xe3, 0x79, 0x2e, 0xa6, 0xdc, 0x81, 0xce, 0xb3, 0x93, 0x93, 0x3e,
+	0x45, 0x06, 0xaa, 0x68, 0x8c, 0x6f, 0x8f, 0x95, 0xc2, 0xb7, 0x47, 0x06, 0xcb, 0xd4, 0xa4, 0x93,
+	0x01, 0x96, 0xc6, 0x28, 0x02, 0x5a, 0xa4, 0xb3, 0x05, 0x02, 0xf8, 0x73, 0xe8, 0xca, 0xc7, 0xc9,
+	0x76, 0x9e, 0x15, 0xde, 0x67, 0xb0, 0xb2, 0x9f, 0xbb, 0x6a, 0x2c, 0x52, 0x8b, 0x6c, 0x38, 0x6a,
+	0x9a, 0xff, 0x06, 0xd6, 0xf3, 0x6d, 0xe4, 0x2d, 0x3e, 0x2f, 0x6b, 0xcb, 0x86, 0x5d, 0x3e, 0x2f,
+	0x57, 0x98, 0xdf, 0x55, 0x60, 0x3d, 0xeb, 0xbc, 0xbf, 0x15, 0x11, 0x3a, 0xf5, 0xfc, 0x23, 0x04,
+	0xdd, 0x48, 0xde, 0xd3, 0x44, 0x2d, 0x4c, 0x91, 0xb6, 0x61, 0x7d, 0x47, 0x6e, 0xb4, 0xe7, 0xc7,
+	0x89, 0xab, 0xbf, 0x2a, 0x57, 0x9d, 0x32, 0x1a, 0x63, 0x3a, 0x36, 0x4e, 0x47, 0x9a, 0x5b, 0xd9,
+	0xbd, 0x2a, 0xe0, 0xf0, 0x49, 0x0e, 0xdc, 0x09, 0xb9, 0x85, 0x86, 0x83, 0x43, 0xfe, 0xd7, 0x15,
+	0xd4, 0x3c, 0xb9, 0x95, 0xbc, 0xf0, 0x23, 0x68, 0x1e, 0x88, 0x40, 0x44, 0x6e, 0xa2, 0xea, 0x86,
+	0x2b, 0xec, 0x2d, 0x23, 0xce, 0x1a, 0x6e, 0xea, 0xd1, 0x70, 0xcc, 0x6c, 0x68, 0xca, 0xab, 0xfa,
+	0x42, 0xf7, 0xf0, 0xba, 0x76, 0x49, 0x44, 0x4e, 0x4e, 0xf2, 0xe0, 0x5f, 0x37, 0xa0, 0xb6, 0x7b,
+	0x74, 0xc8, 0x7e, 0x09, 0x70, 0x20, 0x12, 0xfd, 0xaf, 0xcc, 0xd6, 0x0c, 0x03, 0xfb, 0xf8, 0x53,
+	0x56, 0x6f, 0xcd, 0x36, 0xff, 0xb5, 0xe2, 0x4b, 0xec, 0x57, 0xb0, 0xfa, 0x6a, 0x42, 0xbf, 0x23,
+	0x5c, 0xba, 0xe6, 0x12, 0x3c, 0x5f, 0x62, 0x8f, 0xb1, 0x5e, 0x1d, 0x85, 0xae, 0xf7, 0x13, 0xd6,
+	0xfe, 0x06, 0x7b, 0xc7, 0xe1, 0x44, 0x04, 0x98, 0x69, 0xfe, 0x84, 0xf5, 0x8f, 0x60, 0x79, 0x90,
+	0x84, 0x93, 0x9f, 0xb0, 0xf2, 0xbe, 0xf6, 0x01, 0x97, 0xae, 0x6d, 0xdb, 0xc6, 0x1f, 0x49, 0xc4,
+	0x6b, 0xdb, 0x6c, 0xa5, 0xb0, 0x4d, 0x7b, 0x4e, 0x67, 0x65, 0xc1, 0x89, 0x0f, 0x60, 0x19, 0xdb,
+	0x70, 0x97, 0x9e, 0xd7, 0xb5, 0x4b, 0xad, 0x46, 0xbe, 0xc4, 0xee, 0xea, 0x0f, 0xdd, 0xf8, 0x39,
+	0x96, 0x75, 0xed, 0x52, 0x2b, 0xa6, 0xa7, 0xeb, 0x06, 0xbe, 0x84, 0x5d, 0xff, 0xac, 0x93, 0xc2,
+	0x34, 0xbe, 0xb7, 0x6e, 0x17, 0xdb, 0x2b, 0x7c, 0x89, 0x7d, 0x09, 0x6d, 0xb3, 0x81, 0x91, 0xd3,
+	0x32, 0x7b, 0xa6, 0xb1, 0x41, 0xcf, 0xdb, 0x96, 0xb9, 0xaa, 0x22, 0x9f, 0x65, 0x62, 0xd1, 0xf3,
+	0xb6, 0xcd, 0xce, 0x10, 0xdb, 0xb4, 0xe7, 0x34, 0x8a, 0x16, 0xac, 0x7f, 0x06, 0x1b, 0x33, 0x6d,
+	0x12, 0xf6, 0xbe, 0x7d, 0x59, 0xeb, 0x64, 0xc1, 0x4e, 0x4f, 0xa1, 0x5b, 0xee, 0x7a, 0x30, 0xcb,
+	0xbe, 0xa4, 0x77, 0xd2, 0xdb, 0xb2, 0xe7, 0xb6, 0x48, 0xf8, 0x12, 0x7b, 0x08, 0x90, 0xf7, 0x0b,
+	0x18, 0x9b, 0x6d, 0x50, 0xf4, 0xba, 0x76, 0xa9, 0xa1, 0xc0, 0x97, 0xd8, 0x57, 0xd0, 0xcc, 0xea,
+	0x5e, 0xb6, 0x61, 0x97, 0x2b, 0xf8, 0xde, 0x7a, 0xa9, 0x2c, 0xe6, 0x4b, 0xec, 0xf7, 0xa1, 0x65,
+	0x54, 0x8d, 0xec, 0x86, 0x3d, 0x5b, 0xd9, 0xf6, 0x36, 0xec, 0x72, 0x61, 0x29, 0x4d, 0xa2, 0x8f,
+	0x59, 0xf3, 0x8f, 0x37, 0x89, 0x3f, 0x84, 0xb5, 0x42, 0xe5, 0xc7, 0x6e, 0xda, 0xf3, 0x2a, 0xca,
+	0xde, 0x0d, 0x7b, 0xb6, 0x40, 0x94, 0x22, 0x2e, 0x57, 0x1d, 0xcc, 0xb2, 0x2f, 0xa9, 0x10, 0x7b,
+	0x5b, 0xf6, 0xdc, 0x12, 0x85, 0x14, 0xae, 0x73, 0x20, 0x12, 0xb3, 0x90, 0xb8, 0x61, 0x1b, 0x50,
+	0x7e, 0xf9, 0x72, 0x1a, 0xcf, 0x97, 0xd8, 0x1e, 0x30, 0x34, 0x9f, 0x62, 0xfe, 0x72, 0xa9, 0x28,
+	0x36, 0xed, 0x39, 0x89, 0x0e, 0xdd, 0xe4, 0x86, 0x54, 0xf9, 0xc2, 0x34, 0xbb, 0x69, 0xcf, 0x4b,
+	0x6b, 0x16, 0x08, 0xf4, 0x1b, 0x58, 0x2b, 0x24, 0x38, 0xec, 0xa6, 0x3d, 0x2f, 0xe1, 0x59, 0xb0,
+	0xc3, 0x3e, 0x15, 0xe3, 0xa5, 0x14, 0xe3, 0xd2, 0xfb, 0xdc, 0xb4, 0xe7, 0x25, 0x23, 0xe4, 0x7a,
+	0x3a, 0x3a, 0xde, 0xc8, 0x54, 0x63, 0x8e, 0x15, 0xb7, 0x6d, 0x23, 0x0b, 0xd1, 0x76, 0xff, 0x36,
+	0x7c, 0x73, 0xf9, 0x8a, 0x45, 0x9a, 0xb4, 0x7e, 0x20, 0x12, 0xf3, 0xb3, 0x00, 0x99, 0xfe, 0xcc,
+	0xb7, 0x85, 0x1e, 0xb3, 0x67, 0xbe, 0x1d, 0x50, 0x38, 0x42, 0x45, 0x34, 0x32, 0x93, 0xcb, 0x5d,
+	0x66, 0x29, 0xef, 0x90, 0x86, 0x43, 0x22, 0x53, 0x79, 0xc4, 0x65, 0x4b, 0x3b, 0xb6, 0x26, 0xd1,
+	0x0b, 0xef, 0x43, 0x9d, 0xfe, 0x58, 0x62, 0x6b, 0xb6, 0xf9, 0xe7, 0xd2, 0x82, 0x6b, 0x7e, 0x85,
+	0x91, 0x2f, 0x4e, 0xc7, 0x3f, 0x62, 0xc9, 0x36, 0x74, 0x76, 0xc3, 0xd1, 0x48, 0x0c, 0x93, 0x03,
+	0x37, 0x7a, 0x8d, 0x0c, 0x82, 0x9d, 0xfd, 0xfb, 0xd4, 0x6b, 0xd8, 0xea, 0x0f, 0x27, 0xa2, 0x5c,
+	0x91, 0x7f, 0xe1, 0xb0, 0x8e, 0x5d, 0xf8, 0x41, 0xa8, 0xd7, 0xb6, 0x8d, 0xdf, 0x73, 0xf8, 0x12,
+	0xfb, 0x1c, 0x5a, 0xf4, 0xf9, 0x48, 0xa9, 0xe9, 0x9a, 0x6d, 0xfe, 0x52, 0xd3, 0x6b, 0xd9, 0xf9,
+	0xb7, 0x25, 0x72, 0xc9, 0xf4, 0xe1, 0xc8, 0x2c, 0x18, 0xf1, 0x6d, 0x66, 0xab, 0xda, 0x1e, 0x2b,
+	0x61, 0xf5, 0x61, 0xab, 0xaa, 0xda, 0x63, 0xeb, 0x76, 0xb1, 0x72, 0xec, 0xad, 0xd9, 0x66, 0x21,
+	0x28, 0xfd, 0x5e, 0xf6, 0xe5, 0x89, 0x6d, 0xd8, 0xe5, 0x2f, 0x56, 0xbd, 0x75, 0xbb, 0xf8, 0x61,
+	0x8a, 0x2f, 0xbd, 0x5e, 0x21, 0x91, 0xfd, 0xe2, 0x7f, 0x06, 0x00, 0x65, 0xdf, 0x21, 0x8f, 0x5b,
+	0x2e, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// CLIClient is the client API for CLI service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type CLIClient interface {
+	GetVersion(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*VersionReply, error)
+	Upgrade(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
+	Reload(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
+	ReopenLogs(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
+	Stop(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
+	Status(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*StatusReply, error)
+	ChangeStatus(ctx context.Context, in *ChangeStatusRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	List(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*MirrorListReply, error)
+	MirrorInfo(ctx context.Context, in *MirrorIDRequest, opts ...grpc.CallOption) (*Mirror, error)
+	AddMirror(ctx context.Context, in *Mirror, opts ...grpc.CallOption) (*AddMirrorReply, error)
+	UpdateMirror(ctx context.Context, in *Mirror, opts ...grpc.CallOption) (*UpdateMirrorReply, error)
+	RemoveMirror(ctx context.Context, in *MirrorIDRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	RenameMirror(ctx context.Context, in *RenameMirrorRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	RefreshRepository(ctx context.Context, in *RefreshRepositoryRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	VerifyRepository(ctx context.Context, in *VerifyRepositoryRequest, opts ...grpc.CallOption) (*VerifyRepositoryReply, error)
+	ScanMirror(ctx context.Context, in *ScanMirrorRequest, opts ...grpc.CallOption) (*ScanMirrorReply, error)
+	StatsFile(ctx context.Context, in *StatsFileRequest, opts ...grpc.CallOption) (*StatsFileReply, error)
+	StatsMirror(ctx context.Context, in *StatsMirrorRequest, opts ...grpc.CallOption) (*StatsMirrorReply, error)
+	Ping(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
+	GetMirrorLogs(ctx context.Context, in *GetMirrorLogsRequest, opts ...grpc.CallOption) (*GetMirrorLogsReply, error)
+	GetScanSummaries(ctx context.Context, in *GetScanSummariesRequest, opts ...grpc.CallOption) (*GetScanSummariesReply, error)
+	GetPropagation(ctx context.Context, in *PropagationRequest, opts ...grpc.CallOption) (*PropagationReply, error)
+	ListPendingMirrors(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*PendingMirrorsReply, error)
+	RemovePendingMirror(ctx context.Context, in *PendingMirrorRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	VerifyContact(ctx context.Context, in *VerifyContactRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	GetContactStatuses(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ContactStatusesReply, error)
+	GenerateAPIKey(ctx context.Context, in *MirrorIDRequest, opts ...grpc.CallOption) (*APIKeyReply, error)
+	RevokeAPIKey(ctx context.Context, in *MirrorIDRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	GetRequestTrace(ctx context.Context, in *RequestTraceRequest, opts ...grpc.CallOption) (*RequestTraceReply, error)
+	GetHTTPErrors(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*HTTPErrorsReply, error)
+	GetCoverage(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*CoverageReply, error)
+	Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	Resume(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	CollectGarbage(ctx context.Context, in *GCRequest, opts ...grpc.CallOption) (*GCReply, error)
+	DBInfo(ctx context.Context, in *DBInfoRequest, opts ...grpc.CallOption) (*DBInfoReply, error)
+	// Tools
+	MatchMirror(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*MatchReply, error)
+	ListRsyncModules(ctx context.Context, in *RsyncModulesRequest, opts ...grpc.CallOption) (*RsyncModulesReply, error)
+	SignURL(ctx context.Context, in *SignURLRequest, opts ...grpc.CallOption) (*SignURLReply, error)
+	ListFiles(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (*ListFilesReply, error)
+}
+
+type cLIClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewCLIClient(cc *grpc.ClientConn) CLIClient {
+	return &cLIClient{cc}
+}
+
+func (c *cLIClient) GetVersion(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*VersionReply, error) {
+	out := new(VersionReply)
+	err := c.cc.Invoke(ctx, "/CLI/GetVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) Upgrade(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/Upgrade", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) Reload(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/Reload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) ReopenLogs(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/ReopenLogs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) Stop(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/Stop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) Status(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*StatusReply, error) {
+	out := new(StatusReply)
+	err := c.cc.Invoke(ctx, "/CLI/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) ChangeStatus(ctx context.Context, in *ChangeStatusRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/ChangeStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) List(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*MirrorListReply, error) {
+	out := new(MirrorListReply)
+	err := c.cc.Invoke(ctx, "/CLI/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) MirrorInfo(ctx context.Context, in *MirrorIDRequest, opts ...grpc.CallOption) (*Mirror, error) {
+	out := new(Mirror)
+	err := c.cc.Invoke(ctx, "/CLI/MirrorInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) AddMirror(ctx context.Context, in *Mirror, opts ...grpc.CallOption) (*AddMirrorReply, error) {
+	out := new(AddMirrorReply)
+	err := c.cc.Invoke(ctx, "/CLI/AddMirror", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) UpdateMirror(ctx context.Context, in *Mirror, opts ...grpc.CallOption) (*UpdateMirrorReply, error) {
+	out := new(UpdateMirrorReply)
+	err := c.cc.Invoke(ctx, "/CLI/UpdateMirror", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) RemoveMirror(ctx context.Context, in *MirrorIDRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/RemoveMirror", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) RenameMirror(ctx context.Context, in *RenameMirrorRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/RenameMirror", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) RefreshRepository(ctx context.Context, in *RefreshRepositoryRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/RefreshRepository", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) VerifyRepository(ctx context.Context, in *VerifyRepositoryRequest, opts ...grpc.CallOption) (*VerifyRepositoryReply, error) {
+	out := new(VerifyRepositoryReply)
+	err := c.cc.Invoke(ctx, "/CLI/VerifyRepository", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) ScanMirror(ctx context.Context, in *ScanMirrorRequest, opts ...grpc.CallOption) (*ScanMirrorReply, error) {
+	out := new(ScanMirrorReply)
+	err := c.cc.Invoke(ctx, "/CLI/ScanMirror", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) StatsFile(ctx context.Context, in *StatsFileRequest, opts ...grpc.CallOption) (*StatsFileReply, error) {
+	out := new(StatsFileReply)
+	err := c.cc.Invoke(ctx, "/CLI/StatsFile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) StatsMirror(ctx context.Context, in *StatsMirrorRequest, opts ...grpc.CallOption) (*StatsMirrorReply, error) {
+	out := new(StatsMirrorReply)
+	err := c.cc.Invoke(ctx, "/CLI/StatsMirror", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) Ping(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/Ping", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) GetMirrorLogs(ctx context.Context, in *GetMirrorLogsRequest, opts ...grpc.CallOption) (*GetMirrorLogsReply, error) {
+	out := new(GetMirrorLogsReply)
+	err := c.cc.Invoke(ctx, "/CLI/GetMirrorLogs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) GetScanSummaries(ctx context.Context, in *GetScanSummariesRequest, opts ...grpc.CallOption) (*GetScanSummariesReply, error) {
+	out := new(GetScanSummariesReply)
+	err := c.cc.Invoke(ctx, "/CLI/GetScanSummaries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) GetPropagation(ctx context.Context, in *PropagationRequest, opts ...grpc.CallOption) (*PropagationReply, error) {
+	out := new(PropagationReply)
+	err := c.cc.Invoke(ctx, "/CLI/GetPropagation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) ListPendingMirrors(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*PendingMirrorsReply, error) {
+	out := new(PendingMirrorsReply)
+	err := c.cc.Invoke(ctx, "/CLI/ListPendingMirrors", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) RemovePendingMirror(ctx context.Context, in *PendingMirrorRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/RemovePendingMirror", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) VerifyContact(ctx context.Context, in *VerifyContactRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/VerifyContact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) GetContactStatuses(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ContactStatusesReply, error) {
+	out := new(ContactStatusesReply)
+	err := c.cc.Invoke(ctx, "/CLI/GetContactStatuses", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) GenerateAPIKey(ctx context.Context, in *MirrorIDRequest, opts ...grpc.CallOption) (*APIKeyReply, error) {
+	out := new(APIKeyReply)
+	err := c.cc.Invoke(ctx, "/CLI/GenerateAPIKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) RevokeAPIKey(ctx context.Context, in *MirrorIDRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/RevokeAPIKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) GetRequestTrace(ctx context.Context, in *RequestTraceRequest, opts ...grpc.CallOption) (*RequestTraceReply, error) {
+	out := new(RequestTraceReply)
+	err := c.cc.Invoke(ctx, "/CLI/GetRequestTrace", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) GetHTTPErrors(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*HTTPErrorsReply, error) {
+	out := new(HTTPErrorsReply)
+	err := c.cc.Invoke(ctx, "/CLI/GetHTTPErrors", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) GetCoverage(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*CoverageReply, error) {
+	out := new(CoverageReply)
+	err := c.cc.Invoke(ctx, "/CLI/GetCoverage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/Pause", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) Resume(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/CLI/Resume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func init() { proto.RegisterFile("rpc.proto", fileDescriptor_77a6da22d6a3feb1) }
+func (c *cLIClient) CollectGarbage(ctx context.Context, in *GCRequest, opts ...grpc.CallOption) (*GCReply, error) {
+	out := new(GCReply)
+	err := c.cc.Invoke(ctx, "/CLI/CollectGarbage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-var fileDescriptor_77a6da22d6a3feb1 = []byte{
-	// 1413 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x57, 0x5d, 0x73, 0xdb, 0x44,
-	0x17, 0xb6, 0xec, 0xc4, 0xb1, 0x8f, 0x9d, 0xc4, 0xd9, 0xa4, 0x79, 0x55, 0xb5, 0x6f, 0xeb, 0xee,
-	0xfb, 0x51, 0x33, 0x0c, 0x2a, 0x35, 0x2d, 0x64, 0xca, 0xd7, 0x18, 0x3b, 0x49, 0x03, 0x76, 0x93,
-	0x91, 0x1b, 0x18, 0xb8, 0x53, 0xa5, 0xb5, 0xa3, 0x41, 0xd6, 0x1a, 0xed, 0xaa, 0x8d, 0x67, 0xf8,
-	0x19, 0x0c, 0x57, 0x5c, 0xc0, 0x0f, 0x60, 0x86, 0xdf, 0xc7, 0x15, 0xb3, 0x1f, 0xb2, 0x65, 0x3b,
-	0x71, 0x98, 0x5e, 0x70, 0xb7, 0xe7, 0x39, 0x67, 0xf7, 0x7c, 0xe8, 0x9c, 0x67, 0x57, 0x50, 0x8e,
-	0xc7, 0x9e, 0x3d, 0x8e, 0x29, 0xa7, 0xd6, 0x9d, 0x21, 0xa5, 0xc3, 0x90, 0x3c, 0x92, 0xd2, 0xab,
-	0x64, 0xf0, 0x88, 0x8c, 0xc6, 0x7c, 0xa2, 0x95, 0xf7, 0x17, 0x95, 0x3c, 0x18, 0x11, 0xc6, 0xdd,
-	0xd1, 0x58, 0x19, 0xe0, 0x5f, 0x0d, 0xa8, 0x7e, 0x4d, 0x62, 0x16, 0xd0, 0xc8, 0x21, 0xe3, 0x70,
-	0x82, 0x4c, 0xd8, 0xd0, 0xb2, 0x69, 0xd4, 0x8d, 0x46, 0xd9, 0x49, 0x45, 0xb4, 0x07, 0xeb, 0x5f,
-	0x24, 0x41, 0xe8, 0x9b, 0x79, 0x89, 0x2b, 0x01, 0xdd, 0x85, 0xf2, 0x31, 0x4d, 0x77, 0x14, 0xa4,
-	0x66, 0x06, 0xa0, 0x2d, 0xc8, 0x9f, 0xf6, 0xcd, 0x35, 0x09, 0xe7, 0x4f, 0xfb, 0x08, 0xc1, 0x5a,
-	0x2b, 0xf6, 0x2e, 0xcc, 0x75, 0x89, 0xc8, 0x35, 0xba, 0x07, 0x70, 0x4c, 0x7b, 0xee, 0xe5, 0x59,
-	0x4c, 0x3d, 0x66, 0x16, 0xeb, 0x46, 0x63, 0xdd, 0xc9, 0x20, 0xb8, 0x01, 0xd5, 0x9e, 0xcb, 0xbd,
-	0x0b, 0x87, 0xfc, 0x90, 0x10, 0xc6, 0x45, 0x84, 0x67, 0x2e, 0xe7, 0x24, 0x9e, 0x46, 0xa8, 0x45,
-	0xfc, 0x73, 0x09, 0x8a, 0xbd, 0x20, 0x8e, 0x69, 0x2c, 0x1c, 0x9f, 0x74, 0xa4, 0x7e, 0xdd, 0xc9,
-	0x9f, 0x74, 0x84, 0xe3, 0x17, 0xee, 0x88, 0xe8, 0xd8, 0xe5, 0x5a, 0x1c, 0xf4, 0x9c, 0xf3, 0xf1,
-	0xb9, 0xd3, 0xd5, 0x81, 0xa7, 0x22, 0xb2, 0xa0, 0xe4, 0xb0, 0x49, 0xe4, 0x09, 0x95, 0x0a, 0x7e,
-	0x2a, 0xa3, 0x7d, 0x28, 0x1e, 0xa9, 0x4d, 0x2a, 0x09, 0x2d, 0xa1, 0x3a, 0x54, 0xfa, 0x63, 0x1a,
-	0x31, 0x1a, 0x4b, 0x47, 0x45, 0xa9, 0xcc, 0x42, 0x22, 0x51, 0x2d, 0x8a, 0xdd, 0x1b, 0xd2, 0x20,
-	0x83, 0xa0, 0xff, 0xc3, 0x96, 0x96, 0xba, 0x74, 0x48, 0x85, 0x4d, 0x49, 0xda, 0x2c, 0xa0, 0xa2,
-	0xe4, 0x2d, 0x7f, 0x14, 0x44, 0xd2, 0x4f, 0x59, 0x95, 0x7c, 0x0a, 0x08, 0x2f, 0x52, 0x38, 0x1c,
-	0xb9, 0x41, 0x68, 0x82, 0xf2, 0x32, 0x43, 0x84, 0xbe, 0x9d, 0x30, 0x4e, 0x47, 0x1d, 0x97, 0xbb,
-	0x66, 0x45, 0xe9, 0x67, 0x08, 0xfa, 0x2f, 0x6c, 0xb6, 0x69, 0xc4, 0x83, 0x88, 0x44, 0xfc, 0x34,
-	0x0a, 0x27, 0x66, 0xb5, 0x6e, 0x34, 0x4a, 0xce, 0x3c, 0x28, 0xb2, 0x6d, 0xd3, 0x24, 0xe2, 0xf1,
-	0x44, 0xda, 0x6c, 0x4a, 0x9b, 0x2c, 0x24, 0xea, 0xd4, 0xea, 0x4b, 0xe5, 0x96, 0x54, 0x6a, 0x49,
-	0xb4, 0x51, 0xdf, 0xa3, 0x31, 0x31, 0xb7, 0xe5, 0xc7, 0x51, 0x82, 0xa8, 0x78, 0xd7, 0xe5, 0x01,
-	0x4f, 0x7c, 0x62, 0xd6, 0xea, 0x46, 0x23, 0xef, 0x4c, 0x65, 0x91, 0x6f, 0x97, 0x46, 0x43, 0xa5,
-	0xdc, 0x91, 0xca, 0x19, 0x30, 0x17, 0x6f, 0x9b, 0xfa, 0xc4, 0x44, 0x32, 0xa5, 0x79, 0x10, 0x61,
-	0xa8, 0xea, 0xe0, 0x84, 0xc8, 0xcc, 0x5d, 0x69, 0x34, 0x87, 0xa1, 0x26, 0xec, 0x1d, 0x5e, 0x7a,
-	0x61, 0xe2, 0x13, 0x7f, 0xce, 0x76, 0x4f, 0xda, 0x5e, 0xa9, 0x13, 0xd9, 0xb4, 0x58, 0x94, 0x8c,
-	0xcc, 0x5b, 0x75, 0xa3, 0xb1, 0xe9, 0x28, 0x41, 0x74, 0x56, 0x9b, 0x8e, 0x46, 0x24, 0xe2, 0xe6,
-	0xbe, 0xea, 0x2c, 0x2d, 0x0a, 0xcd, 0x61, 0xe4, 0xbe, 0x0a, 0x89, 0x6f, 0xfe, 0x4b, 0x96, 0x25,
-	0x15, 0x45, 0xc7, 0x9e, 0x8f, 0x4d, 0x53, 0x82, 0xf9, 0xf3, 0xb1, 0xc8, 0x4b, 0x7b, 0x74, 0x88,
-	0xcb, 0x68, 0x64, 0xde, 0x56, 0x79, 0xcd, 0x81, 0xe8, 0x19, 0x40, 0x9f, 0xbb, 0x9c, 0xf4, 0x83,
-	0xc8, 0x23, 0xa6, 0x55, 0x37, 0x1a, 0x95, 0xa6, 0x65, 0xab, 0xa9, 0xb7, 0xd3, 0xa9, 0xb7, 0x5f,
-	0xa6, 0x53, 0xef, 0x64, 0xac, 0x45, 0xbf, 0xb5, 0xc2, 0x90, 0xbe, 0x71, 0x88, 0x1f, 0xc4, 0xc4,
-	0xe3, 0xcc, 0xbc, 0x23, 0x3f, 0xc9, 0x02, 0x8a, 0x3e, 0x14, 0xdf, 0x86, 0xf1, 0xfe, 0x24, 0xf2,
-	0xcc, 0xbb, 0x37, 0x7a, 0x98, 0xda, 0xa2, 0x2f, 0x01, 0xc9, 0x75, 0xe2, 0x79, 0x84, 0xb1, 0x41,
-	0x12, 0xca, 0x13, 0xfe, 0x7d, 0xe3, 0x09, 0x57, 0xec, 0x42, 0x9f, 0x40, 0x45, 0xa0, 0x3d, 0xea,
-	0x0b, 0x3b, 0xf3, 0xde, 0x8d, 0x87, 0x64, 0xcd, 0xf1, 0x13, 0xd8, 0x56, 0xbc, 0xd0, 0x0d, 0x18,
-	0x57, 0x3c, 0xf7, 0x00, 0x36, 0x14, 0xc4, 0x4c, 0xa3, 0x5e, 0x68, 0x54, 0x9a, 0x1b, 0xb6, 0x92,
-	0x9d, 0x14, 0xc7, 0x36, 0x94, 0xd4, 0xf2, 0xa4, 0xf3, 0x77, 0xf8, 0x04, 0x3f, 0x06, 0xd0, 0x44,
-	0x25, 0x1c, 0xfc, 0x67, 0xd1, 0x41, 0xd9, 0x4e, 0x4f, 0x9b, 0xb9, 0xf8, 0x1c, 0x76, 0xdb, 0x17,
-	0x6e, 0x34, 0x24, 0xe2, 0xb3, 0x24, 0x2c, 0xa5, 0xb8, 0x45, 0x6f, 0x99, 0xae, 0xc9, 0xcf, 0x75,
-	0x0d, 0x7e, 0x90, 0x66, 0x76, 0xd2, 0xb9, 0x66, 0x33, 0xfe, 0xc3, 0x80, 0xad, 0x96, 0xef, 0xeb,
-	0xec, 0x64, 0x6c, 0xd9, 0x69, 0x33, 0x56, 0x4d, 0x5b, 0x7e, 0x71, 0xda, 0x64, 0x67, 0xcb, 0xfe,
-	0x4f, 0x39, 0x53, 0x8b, 0x62, 0xdf, 0x74, 0xe4, 0x34, 0x69, 0xce, 0x00, 0x54, 0x83, 0x42, 0xab,
-	0xff, 0x42, 0x53, 0xa6, 0x58, 0x8a, 0x18, 0xbe, 0x71, 0xe3, 0x28, 0x88, 0x86, 0x82, 0xf4, 0x0b,
-	0x82, 0x63, 0x53, 0x19, 0x3f, 0x84, 0x9d, 0xf3, 0xb1, 0xef, 0x72, 0x92, 0x0d, 0x1a, 0xc1, 0x5a,
-	0x27, 0x18, 0x0c, 0x34, 0xe9, 0xcb, 0x35, 0x6e, 0x82, 0xe9, 0x90, 0x41, 0x4c, 0x98, 0x28, 0x3a,
-	0x65, 0x01, 0xa7, 0xf1, 0x24, 0xad, 0xc3, 0x3e, 0x14, 0x1d, 0x72, 0xe1, 0xb2, 0x0b, 0xb9, 0xa3,
-	0xe4, 0x68, 0x09, 0xff, 0x66, 0xc0, 0x4e, 0xdf, 0x73, 0xa3, 0xf4, 0xec, 0xab, 0x4b, 0x2e, 0x68,
-	0x34, 0xe1, 0x54, 0xd5, 0x59, 0x57, 0x3d, 0x83, 0xa0, 0xa7, 0x50, 0x3a, 0x13, 0x5d, 0xe7, 0xd1,
-	0x50, 0x56, 0x62, 0xab, 0x79, 0xdb, 0x5e, 0x3a, 0xd5, 0xee, 0x11, 0x7e, 0x41, 0x7d, 0x67, 0x6a,
-	0x8a, 0xff, 0x07, 0x45, 0x85, 0xa1, 0x0d, 0x28, 0xb4, 0xba, 0xdd, 0x5a, 0x4e, 0x2c, 0x8e, 0x5e,
-	0x9e, 0xd5, 0x0c, 0x54, 0x86, 0x75, 0xa7, 0xff, 0xed, 0x8b, 0x76, 0x2d, 0x8f, 0x7f, 0x37, 0x60,
-	0x3b, 0x7b, 0x9a, 0xbe, 0x99, 0xd3, 0x26, 0x30, 0xe6, 0xa9, 0x03, 0x43, 0xf5, 0x28, 0x08, 0x09,
-	0x3b, 0x89, 0x7c, 0x72, 0xa9, 0x7b, 0xa4, 0xe0, 0xcc, 0x61, 0xc2, 0xe6, 0xab, 0x88, 0xbe, 0x89,
-	0x52, 0x9b, 0x82, 0xb2, 0xc9, 0x62, 0xc2, 0x83, 0x43, 0x46, 0xf4, 0x35, 0xf1, 0xe5, 0x07, 0x2c,
-	0x38, 0xa9, 0x28, 0xaa, 0xf1, 0xf2, 0xbb, 0xd3, 0xc1, 0x80, 0x11, 0xde, 0x63, 0xf2, 0x2b, 0x16,
-	0x9c, 0x0c, 0x82, 0x7f, 0x31, 0xa0, 0x26, 0x5a, 0x98, 0x09, 0x9f, 0x37, 0x5e, 0xd4, 0xe8, 0x00,
-	0xca, 0x1d, 0x41, 0x43, 0xdc, 0x8d, 0xb9, 0x8c, 0x76, 0xf5, 0x2c, 0xcf, 0x8c, 0xd1, 0x13, 0xd8,
-	0x10, 0xc2, 0x61, 0xa4, 0x32, 0x58, 0xbd, 0x2f, 0x35, 0xc5, 0x3f, 0xc2, 0x56, 0x26, 0x3a, 0x51,
-	0xcc, 0xf7, 0x61, 0x7d, 0x20, 0xca, 0xa3, 0x67, 0xd3, 0xb2, 0xe7, 0xf5, 0xb6, 0xac, 0xdd, 0xa1,
-	0x68, 0x6c, 0x47, 0x19, 0x5a, 0x07, 0x00, 0x33, 0x50, 0xf4, 0xf3, 0xf7, 0x64, 0xa2, 0xf3, 0x12,
-	0x4b, 0x71, 0x13, 0xbc, 0x76, 0xc3, 0x84, 0xe8, 0xea, 0x2b, 0xe1, 0x59, 0xfe, 0xc0, 0xc0, 0x3f,
-	0x19, 0x80, 0xe4, 0xf1, 0xab, 0x3b, 0xee, 0x9f, 0x2e, 0x0a, 0xd1, 0x9f, 0x2c, 0xdb, 0x63, 0xf7,
-	0xd3, 0x07, 0x94, 0x8c, 0x2b, 0x43, 0x8a, 0xe9, 0xbb, 0x4a, 0xbc, 0x8c, 0x54, 0xfc, 0x4c, 0x27,
-	0x3a, 0x95, 0xe5, 0x03, 0x71, 0xc2, 0x09, 0xd3, 0xbd, 0xa5, 0x04, 0x7c, 0x04, 0x7b, 0xc7, 0x84,
-	0x6b, 0xfa, 0xa5, 0x43, 0xb6, 0x62, 0xe0, 0x7a, 0xee, 0xa5, 0x43, 0x58, 0x12, 0xea, 0xb3, 0xd7,
-	0x9d, 0x0c, 0x82, 0x1b, 0x80, 0x16, 0xce, 0xd1, 0xa4, 0x10, 0x06, 0x11, 0x91, 0x9f, 0xb1, 0xec,
-	0xc8, 0x75, 0xf3, 0xcf, 0x22, 0x14, 0xda, 0xdd, 0x13, 0xf4, 0x14, 0xe0, 0x98, 0xf0, 0xf4, 0x29,
-	0xba, 0xbf, 0x54, 0x93, 0x43, 0xf1, 0x50, 0xb6, 0x36, 0xed, 0xec, 0xfb, 0x17, 0xe7, 0xd0, 0xc7,
-	0xb0, 0x71, 0x3e, 0x1e, 0xc6, 0xae, 0x4f, 0xae, 0xdd, 0x73, 0x0d, 0x8e, 0x73, 0xe8, 0x99, 0x20,
-	0x9d, 0x90, 0xba, 0xfe, 0x5b, 0xec, 0xfd, 0x0c, 0xaa, 0xd9, 0xcb, 0x00, 0xed, 0xd9, 0x57, 0xdc,
-	0x0d, 0x2b, 0xf6, 0x37, 0x61, 0x4d, 0xdc, 0x6f, 0xd7, 0x7a, 0xae, 0xd9, 0x0b, 0x97, 0x20, 0xce,
-	0xa1, 0x77, 0x00, 0xf4, 0xfd, 0x11, 0x0d, 0x28, 0xaa, 0xd9, 0x0b, 0x97, 0x89, 0x95, 0x36, 0x00,
-	0xce, 0xa1, 0x87, 0xe2, 0xd9, 0xa9, 0xaf, 0x11, 0x94, 0xe2, 0xd6, 0xb6, 0x3d, 0x7f, 0xb7, 0xe0,
-	0x1c, 0x7a, 0x0f, 0xaa, 0x59, 0xf6, 0x9e, 0xd9, 0x22, 0x7b, 0x89, 0xd5, 0x65, 0xc9, 0xaa, 0x8a,
-	0x66, 0xb4, 0xf9, 0x72, 0x10, 0xd7, 0xa7, 0xfc, 0x1c, 0x76, 0x96, 0xf8, 0x1f, 0xdd, 0xb6, 0xaf,
-	0xbb, 0x13, 0x56, 0x9c, 0xf4, 0x04, 0x60, 0x46, 0xb8, 0x08, 0x2d, 0x73, 0xb9, 0x55, 0xb3, 0x17,
-	0x18, 0x19, 0xe7, 0xd0, 0x63, 0x28, 0x4f, 0x89, 0x03, 0xed, 0xd8, 0x8b, 0x14, 0x68, 0x6d, 0x2f,
-	0xf0, 0x0a, 0xce, 0xa1, 0x8f, 0xa0, 0x92, 0x19, 0x3b, 0xb4, 0x6b, 0x2f, 0x53, 0x83, 0xb5, 0x63,
-	0x2f, 0x4e, 0x26, 0xce, 0xa1, 0x03, 0x58, 0x3b, 0x0b, 0xa2, 0xe1, 0x5b, 0x34, 0xd6, 0xa7, 0xb0,
-	0x39, 0x37, 0x3a, 0xe8, 0x96, 0x7d, 0xd5, 0x48, 0x5a, 0xbb, 0xf6, 0xf2, 0x84, 0xe1, 0x1c, 0x7a,
-	0x17, 0x2a, 0xf2, 0x5d, 0xa3, 0x23, 0xde, 0xb4, 0xb3, 0xbf, 0x63, 0x56, 0xc5, 0x9e, 0x3d, 0x7a,
-	0x70, 0xee, 0x55, 0x51, 0x7a, 0xff, 0xe0, 0xaf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x30, 0xff, 0xff,
-	0x89, 0xa2, 0x0e, 0x00, 0x00,
+func (c *cLIClient) DBInfo(ctx context.Context, in *DBInfoRequest, opts ...grpc.CallOption) (*DBInfoReply, error) {
+	out := new(DBInfoReply)
+	err := c.cc.Invoke(ctx, "/CLI/DBInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (c *cLIClient) MatchMirror(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*MatchReply, error) {
+	out := new(MatchReply)
+	err := c.cc.Invoke(ctx, "/CLI/MatchMirror", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (c *cLIClient) ListRsyncModules(ctx context.Context, in *RsyncModulesRequest, opts ...grpc.CallOption) (*RsyncModulesReply, error) {
+	out := new(RsyncModulesReply)
+	err := c.cc.Invoke(ctx, "/CLI/ListRsyncModules", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-// CLIClient is the client API for CLI service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type CLIClient interface {
-	GetVersion(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*VersionReply, error)
-	Upgrade(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
-	Reload(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
-	ChangeStatus(ctx context.Context, in *ChangeStatusRequest, opts ...grpc.CallOption) (*empty.Empty, error)
-	List(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*MirrorListReply, error)
-	MirrorInfo(ctx context.Context, in *MirrorIDRequest, opts ...grpc.CallOption) (*Mirror, error)
-	AddMirror(ctx context.Context, in *Mirror, opts ...grpc.CallOption) (*AddMirrorReply, error)
-	UpdateMirror(ctx context.Context, in *Mirror, opts ...grpc.CallOption) (*UpdateMirrorReply, error)
-	RemoveMirror(ctx context.Context, in *MirrorIDRequest, opts ...grpc.CallOption) (*empty.Empty, error)
-	RefreshRepository(ctx context.Context, in *RefreshRepositoryRequest, opts ...grpc.CallOption) (*empty.Empty, error)
-	ScanMirror(ctx context.Context, in *ScanMirrorRequest, opts ...grpc.CallOption) (*ScanMirrorReply, error)
-	StatsFile(ctx context.Context, in *StatsFileRequest, opts ...grpc.CallOption) (*StatsFileReply, error)
-	StatsMirror(ctx context.Context, in *StatsMirrorRequest, opts ...grpc.CallOption) (*StatsMirrorReply, error)
-	Ping(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
-	GetMirrorLogs(ctx context.Context, in *GetMirrorLogsRequest, opts ...grpc.CallOption) (*GetMirrorLogsReply, error)
+func (c *cLIClient) SignURL(ctx context.Context, in *SignURLRequest, opts ...grpc.CallOption) (*SignURLReply, error) {
+	out := new(SignURLReply)
+	err := c.cc.Invoke(ctx, "/CLI/SignURL", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cLIClient) ListFiles(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (*ListFilesReply, error) {
+	out := new(ListFilesReply)
+	err := c.cc.Invoke(ctx, "/CLI/ListFiles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CLIServer is the server API for CLI service.
+type CLIServer interface {
+	GetVersion(context.Context, *empty.Empty) (*VersionReply, error)
+	Upgrade(context.Context, *empty.Empty) (*empty.Empty, error)
+	Reload(context.Context, *empty.Empty) (*empty.Empty, error)
+	ReopenLogs(context.Context, *empty.Empty) (*empty.Empty, error)
+	Stop(context.Context, *empty.Empty) (*empty.Empty, error)
+	Status(context.Context, *empty.Empty) (*StatusReply, error)
+	ChangeStatus(context.Context, *ChangeStatusRequest) (*empty.Empty, error)
+	List(context.Context, *empty.Empty) (*MirrorListReply, error)
+	MirrorInfo(context.Context, *MirrorIDRequest) (*Mirror, error)
+	AddMirror(context.Context, *Mirror) (*AddMirrorReply, error)
+	UpdateMirror(context.Context, *Mirror) (*UpdateMirrorReply, error)
+	RemoveMirror(context.Context, *MirrorIDRequest) (*empty.Empty, error)
+	RenameMirror(context.Context, *RenameMirrorRequest) (*empty.Empty, error)
+	RefreshRepository(context.Context, *RefreshRepositoryRequest) (*empty.Empty, error)
+	VerifyRepository(context.Context, *VerifyRepositoryRequest) (*VerifyRepositoryReply, error)
+	ScanMirror(context.Context, *ScanMirrorRequest) (*ScanMirrorReply, error)
+	StatsFile(context.Context, *StatsFileRequest) (*StatsFileReply, error)
+	StatsMirror(context.Context, *StatsMirrorRequest) (*StatsMirrorReply, error)
+	Ping(context.Context, *empty.Empty) (*empty.Empty, error)
+	GetMirrorLogs(context.Context, *GetMirrorLogsRequest) (*GetMirrorLogsReply, error)
+	GetScanSummaries(context.Context, *GetScanSummariesRequest) (*GetScanSummariesReply, error)
+	GetPropagation(context.Context, *PropagationRequest) (*PropagationReply, error)
+	ListPendingMirrors(context.Context, *empty.Empty) (*PendingMirrorsReply, error)
+	RemovePendingMirror(context.Context, *PendingMirrorRequest) (*empty.Empty, error)
+	VerifyContact(context.Context, *VerifyContactRequest) (*empty.Empty, error)
+	GetContactStatuses(context.Context, *empty.Empty) (*ContactStatusesReply, error)
+	GenerateAPIKey(context.Context, *MirrorIDRequest) (*APIKeyReply, error)
+	RevokeAPIKey(context.Context, *MirrorIDRequest) (*empty.Empty, error)
+	GetRequestTrace(context.Context, *RequestTraceRequest) (*RequestTraceReply, error)
+	GetHTTPErrors(context.Context, *empty.Empty) (*HTTPErrorsReply, error)
+	GetCoverage(context.Context, *empty.Empty) (*CoverageReply, error)
+	Pause(context.Context, *PauseRequest) (*empty.Empty, error)
+	Resume(context.Context, *PauseRequest) (*empty.Empty, error)
+	CollectGarbage(context.Context, *GCRequest) (*GCReply, error)
+	DBInfo(context.Context, *DBInfoRequest) (*DBInfoReply, error)
 	// Tools
-	MatchMirror(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*MatchReply, error)
+	MatchMirror(context.Context, *MatchRequest) (*MatchReply, error)
+	ListRsyncModules(context.Context, *RsyncModulesRequest) (*RsyncModulesReply, error)
+	SignURL(context.Context, *SignURLRequest) (*SignURLReply, error)
+	ListFiles(context.Context, *ListFilesRequest) (*ListFilesReply, error)
 }
 
-type cLIClient struct {
-	cc *grpc.ClientConn
+// UnimplementedCLIServer can be embedded to have forward compatible implementations.
+type UnimplementedCLIServer struct {
+}
+
+func (*UnimplementedCLIServer) GetVersion(ctx context.Context, req *empty.Empty) (*VersionReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
+}
+func (*UnimplementedCLIServer) Upgrade(ctx context.Context, req *empty.Empty) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Upgrade not implemented")
+}
+func (*UnimplementedCLIServer) Reload(ctx context.Context, req *empty.Empty) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reload not implemented")
+}
+func (*UnimplementedCLIServer) ReopenLogs(ctx context.Context, req *empty.Empty) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReopenLogs not implemented")
+}
+func (*UnimplementedCLIServer) Stop(ctx context.Context, req *empty.Empty) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stop not implemented")
+}
+func (*UnimplementedCLIServer) Status(ctx context.Context, req *empty.Empty) (*StatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (*UnimplementedCLIServer) ChangeStatus(ctx context.Context, req *ChangeStatusRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeStatus not implemented")
+}
+func (*UnimplementedCLIServer) List(ctx context.Context, req *empty.Empty) (*MirrorListReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (*UnimplementedCLIServer) MirrorInfo(ctx context.Context, req *MirrorIDRequest) (*Mirror, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MirrorInfo not implemented")
+}
+func (*UnimplementedCLIServer) AddMirror(ctx context.Context, req *Mirror) (*AddMirrorReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMirror not implemented")
+}
+func (*UnimplementedCLIServer) UpdateMirror(ctx context.Context, req *Mirror) (*UpdateMirrorReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateMirror not implemented")
+}
+func (*UnimplementedCLIServer) RemoveMirror(ctx context.Context, req *MirrorIDRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveMirror not implemented")
+}
+func (*UnimplementedCLIServer) RenameMirror(ctx context.Context, req *RenameMirrorRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameMirror not implemented")
+}
+func (*UnimplementedCLIServer) RefreshRepository(ctx context.Context, req *RefreshRepositoryRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshRepository not implemented")
+}
+func (*UnimplementedCLIServer) VerifyRepository(ctx context.Context, req *VerifyRepositoryRequest) (*VerifyRepositoryReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyRepository not implemented")
+}
+func (*UnimplementedCLIServer) ScanMirror(ctx context.Context, req *ScanMirrorRequest) (*ScanMirrorReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ScanMirror not implemented")
+}
+func (*UnimplementedCLIServer) StatsFile(ctx context.Context, req *StatsFileRequest) (*StatsFileReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StatsFile not implemented")
+}
+func (*UnimplementedCLIServer) StatsMirror(ctx context.Context, req *StatsMirrorRequest) (*StatsMirrorReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StatsMirror not implemented")
+}
+func (*UnimplementedCLIServer) Ping(ctx context.Context, req *empty.Empty) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (*UnimplementedCLIServer) GetMirrorLogs(ctx context.Context, req *GetMirrorLogsRequest) (*GetMirrorLogsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMirrorLogs not implemented")
+}
+func (*UnimplementedCLIServer) GetScanSummaries(ctx context.Context, req *GetScanSummariesRequest) (*GetScanSummariesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetScanSummaries not implemented")
+}
+func (*UnimplementedCLIServer) GetPropagation(ctx context.Context, req *PropagationRequest) (*PropagationReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPropagation not implemented")
+}
+func (*UnimplementedCLIServer) ListPendingMirrors(ctx context.Context, req *empty.Empty) (*PendingMirrorsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPendingMirrors not implemented")
+}
+func (*UnimplementedCLIServer) RemovePendingMirror(ctx context.Context, req *PendingMirrorRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemovePendingMirror not implemented")
+}
+func (*UnimplementedCLIServer) VerifyContact(ctx context.Context, req *VerifyContactRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyContact not implemented")
+}
+func (*UnimplementedCLIServer) GetContactStatuses(ctx context.Context, req *empty.Empty) (*ContactStatusesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContactStatuses not implemented")
+}
+func (*UnimplementedCLIServer) GenerateAPIKey(ctx context.Context, req *MirrorIDRequest) (*APIKeyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateAPIKey not implemented")
+}
+func (*UnimplementedCLIServer) RevokeAPIKey(ctx context.Context, req *MirrorIDRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAPIKey not implemented")
+}
+func (*UnimplementedCLIServer) GetRequestTrace(ctx context.Context, req *RequestTraceRequest) (*RequestTraceReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRequestTrace not implemented")
+}
+func (*UnimplementedCLIServer) GetHTTPErrors(ctx context.Context, req *empty.Empty) (*HTTPErrorsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHTTPErrors not implemented")
+}
+func (*UnimplementedCLIServer) GetCoverage(ctx context.Context, req *empty.Empty) (*CoverageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCoverage not implemented")
+}
+func (*UnimplementedCLIServer) Pause(ctx context.Context, req *PauseRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (*UnimplementedCLIServer) Resume(ctx context.Context, req *PauseRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (*UnimplementedCLIServer) CollectGarbage(ctx context.Context, req *GCRequest) (*GCReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CollectGarbage not implemented")
+}
+func (*UnimplementedCLIServer) DBInfo(ctx context.Context, req *DBInfoRequest) (*DBInfoReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DBInfo not implemented")
+}
+func (*UnimplementedCLIServer) MatchMirror(ctx context.Context, req *MatchRequest) (*MatchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MatchMirror not implemented")
+}
+func (*UnimplementedCLIServer) ListRsyncModules(ctx context.Context, req *RsyncModulesRequest) (*RsyncModulesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRsyncModules not implemented")
+}
+func (*UnimplementedCLIServer) SignURL(ctx context.Context, req *SignURLRequest) (*SignURLReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignURL not implemented")
+}
+func (*UnimplementedCLIServer) ListFiles(ctx context.Context, req *ListFilesRequest) (*ListFilesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFiles not implemented")
+}
+
+func RegisterCLIServer(s *grpc.Server, srv CLIServer) {
+	s.RegisterService(&_CLI_serviceDesc, srv)
+}
+
+func _CLI_GetVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CLIServer).GetVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/GetVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).GetVersion(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CLI_Upgrade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CLIServer).Upgrade(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/Upgrade",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).Upgrade(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CLI_Reload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CLIServer).Reload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/Reload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).Reload(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CLI_ReopenLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CLIServer).ReopenLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/ReopenLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).ReopenLogs(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CLI_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CLIServer).Stop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/Stop",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).Stop(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CLI_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CLIServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).Status(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func NewCLIClient(cc *grpc.ClientConn) CLIClient {
-	return &cLIClient{cc}
+func _CLI_ChangeStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CLIServer).ChangeStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/ChangeStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).ChangeStatus(ctx, req.(*ChangeStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) GetVersion(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*VersionReply, error) {
-	out := new(VersionReply)
-	err := c.cc.Invoke(ctx, "/CLI/GetVersion", in, out, opts...)
-	if err != nil {
+func _CLI_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).List(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) Upgrade(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/CLI/Upgrade", in, out, opts...)
-	if err != nil {
+func _CLI_MirrorInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MirrorIDRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).MirrorInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/MirrorInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).MirrorInfo(ctx, req.(*MirrorIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) Reload(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/CLI/Reload", in, out, opts...)
-	if err != nil {
+func _CLI_AddMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Mirror)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).AddMirror(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/AddMirror",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).AddMirror(ctx, req.(*Mirror))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) ChangeStatus(ctx context.Context, in *ChangeStatusRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/CLI/ChangeStatus", in, out, opts...)
-	if err != nil {
+func _CLI_UpdateMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Mirror)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).UpdateMirror(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/UpdateMirror",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).UpdateMirror(ctx, req.(*Mirror))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) List(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*MirrorListReply, error) {
-	out := new(MirrorListReply)
-	err := c.cc.Invoke(ctx, "/CLI/List", in, out, opts...)
-	if err != nil {
+func _CLI_RemoveMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MirrorIDRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).RemoveMirror(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/RemoveMirror",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).RemoveMirror(ctx, req.(*MirrorIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) MirrorInfo(ctx context.Context, in *MirrorIDRequest, opts ...grpc.CallOption) (*Mirror, error) {
-	out := new(Mirror)
-	err := c.cc.Invoke(ctx, "/CLI/MirrorInfo", in, out, opts...)
-	if err != nil {
+func _CLI_RenameMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameMirrorRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).RenameMirror(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/RenameMirror",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).RenameMirror(ctx, req.(*RenameMirrorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) AddMirror(ctx context.Context, in *Mirror, opts ...grpc.CallOption) (*AddMirrorReply, error) {
-	out := new(AddMirrorReply)
-	err := c.cc.Invoke(ctx, "/CLI/AddMirror", in, out, opts...)
-	if err != nil {
+func _CLI_RefreshRepository_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshRepositoryRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).RefreshRepository(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/RefreshRepository",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).RefreshRepository(ctx, req.(*RefreshRepositoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) UpdateMirror(ctx context.Context, in *Mirror, opts ...grpc.CallOption) (*UpdateMirrorReply, error) {
-	out := new(UpdateMirrorReply)
-	err := c.cc.Invoke(ctx, "/CLI/UpdateMirror", in, out, opts...)
-	if err != nil {
+func _CLI_VerifyRepository_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRepositoryRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).VerifyRepository(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/VerifyRepository",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).VerifyRepository(ctx, req.(*VerifyRepositoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) RemoveMirror(ctx context.Context, in *MirrorIDRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/CLI/RemoveMirror", in, out, opts...)
-	if err != nil {
+func _CLI_ScanMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanMirrorRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).ScanMirror(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/ScanMirror",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).ScanMirror(ctx, req.(*ScanMirrorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) RefreshRepository(ctx context.Context, in *RefreshRepositoryRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/CLI/RefreshRepository", in, out, opts...)
-	if err != nil {
+func _CLI_StatsFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsFileRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).StatsFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/StatsFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).StatsFile(ctx, req.(*StatsFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) ScanMirror(ctx context.Context, in *ScanMirrorRequest, opts ...grpc.CallOption) (*ScanMirrorReply, error) {
-	out := new(ScanMirrorReply)
-	err := c.cc.Invoke(ctx, "/CLI/ScanMirror", in, out, opts...)
-	if err != nil {
+func _CLI_StatsMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsMirrorRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).StatsMirror(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/StatsMirror",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).StatsMirror(ctx, req.(*StatsMirrorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) StatsFile(ctx context.Context, in *StatsFileRequest, opts ...grpc.CallOption) (*StatsFileReply, error) {
-	out := new(StatsFileReply)
-	err := c.cc.Invoke(ctx, "/CLI/StatsFile", in, out, opts...)
-	if err != nil {
+func _CLI_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/Ping",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).Ping(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) StatsMirror(ctx context.Context, in *StatsMirrorRequest, opts ...grpc.CallOption) (*StatsMirrorReply, error) {
-	out := new(StatsMirrorReply)
-	err := c.cc.Invoke(ctx, "/CLI/StatsMirror", in, out, opts...)
-	if err != nil {
+func _CLI_GetMirrorLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMirrorLogsRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).GetMirrorLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/GetMirrorLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).GetMirrorLogs(ctx, req.(*GetMirrorLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) Ping(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/CLI/Ping", in, out, opts...)
-	if err != nil {
+func _CLI_GetScanSummaries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetScanSummariesRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).GetScanSummaries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/GetScanSummaries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).GetScanSummaries(ctx, req.(*GetScanSummariesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) GetMirrorLogs(ctx context.Context, in *GetMirrorLogsRequest, opts ...grpc.CallOption) (*GetMirrorLogsReply, error) {
-	out := new(GetMirrorLogsReply)
-	err := c.cc.Invoke(ctx, "/CLI/GetMirrorLogs", in, out, opts...)
-	if err != nil {
+func _CLI_GetPropagation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PropagationRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(CLIServer).GetPropagation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/GetPropagation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).GetPropagation(ctx, req.(*PropagationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *cLIClient) MatchMirror(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*MatchReply, error) {
-	out := new(MatchReply)
-	err := c.cc.Invoke(ctx, "/CLI/MatchMirror", in, out, opts...)
-	if err != nil {
+func _CLI_ListPendingMirrors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
-}
-
-// CLIServer is the server API for CLI service.
-type CLIServer interface {
-	GetVersion(context.Context, *empty.Empty) (*VersionReply, error)
-	Upgrade(context.Context, *empty.Empty) (*empty.Empty, error)
-	Reload(context.Context, *empty.Empty) (*empty.Empty, error)
-	ChangeStatus(context.Context, *ChangeStatusRequest) (*empty.Empty, error)
-	List(context.Context, *empty.Empty) (*MirrorListReply, error)
-	MirrorInfo(context.Context, *MirrorIDRequest) (*Mirror, error)
-	AddMirror(context.Context, *Mirror) (*AddMirrorReply, error)
-	UpdateMirror(context.Context, *Mirror) (*UpdateMirrorReply, error)
-	RemoveMirror(context.Context, *MirrorIDRequest) (*empty.Empty, error)
-	RefreshRepository(context.Context, *RefreshRepositoryRequest) (*empty.Empty, error)
-	ScanMirror(context.Context, *ScanMirrorRequest) (*ScanMirrorReply, error)
-	StatsFile(context.Context, *StatsFileRequest) (*StatsFileReply, error)
-	StatsMirror(context.Context, *StatsMirrorRequest) (*StatsMirrorReply, error)
-	Ping(context.Context, *empty.Empty) (*empty.Empty, error)
-	GetMirrorLogs(context.Context, *GetMirrorLogsRequest) (*GetMirrorLogsReply, error)
-	// Tools
-	MatchMirror(context.Context, *MatchRequest) (*MatchReply, error)
-}
-
-// UnimplementedCLIServer can be embedded to have forward compatible implementations.
-type UnimplementedCLIServer struct {
-}
-
-func (*UnimplementedCLIServer) GetVersion(ctx context.Context, req *empty.Empty) (*VersionReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
-}
-func (*UnimplementedCLIServer) Upgrade(ctx context.Context, req *empty.Empty) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Upgrade not implemented")
-}
-func (*UnimplementedCLIServer) Reload(ctx context.Context, req *empty.Empty) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Reload not implemented")
-}
-func (*UnimplementedCLIServer) ChangeStatus(ctx context.Context, req *ChangeStatusRequest) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ChangeStatus not implemented")
-}
-func (*UnimplementedCLIServer) List(ctx context.Context, req *empty.Empty) (*MirrorListReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
-}
-func (*UnimplementedCLIServer) MirrorInfo(ctx context.Context, req *MirrorIDRequest) (*Mirror, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method MirrorInfo not implemented")
-}
-func (*UnimplementedCLIServer) AddMirror(ctx context.Context, req *Mirror) (*AddMirrorReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method AddMirror not implemented")
-}
-func (*UnimplementedCLIServer) UpdateMirror(ctx context.Context, req *Mirror) (*UpdateMirrorReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method UpdateMirror not implemented")
-}
-func (*UnimplementedCLIServer) RemoveMirror(ctx context.Context, req *MirrorIDRequest) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method RemoveMirror not implemented")
-}
-func (*UnimplementedCLIServer) RefreshRepository(ctx context.Context, req *RefreshRepositoryRequest) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method RefreshRepository not implemented")
-}
-func (*UnimplementedCLIServer) ScanMirror(ctx context.Context, req *ScanMirrorRequest) (*ScanMirrorReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ScanMirror not implemented")
-}
-func (*UnimplementedCLIServer) StatsFile(ctx context.Context, req *StatsFileRequest) (*StatsFileReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method StatsFile not implemented")
-}
-func (*UnimplementedCLIServer) StatsMirror(ctx context.Context, req *StatsMirrorRequest) (*StatsMirrorReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method StatsMirror not implemented")
-}
-func (*UnimplementedCLIServer) Ping(ctx context.Context, req *empty.Empty) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
-}
-func (*UnimplementedCLIServer) GetMirrorLogs(ctx context.Context, req *GetMirrorLogsRequest) (*GetMirrorLogsReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetMirrorLogs not implemented")
-}
-func (*UnimplementedCLIServer) MatchMirror(ctx context.Context, req *MatchRequest) (*MatchReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method MatchMirror not implemented")
-}
-
-func RegisterCLIServer(s *grpc.Server, srv CLIServer) {
-	s.RegisterService(&_CLI_serviceDesc, srv)
+	if interceptor == nil {
+		return srv.(CLIServer).ListPendingMirrors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/CLI/ListPendingMirrors",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLIServer).ListPendingMirrors(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_GetVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
+func _CLI_RemovePendingMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PendingMirrorRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).GetVersion(ctx, in)
+		return srv.(CLIServer).RemovePendingMirror(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/GetVersion",
+		FullMethod: "/CLI/RemovePendingMirror",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).GetVersion(ctx, req.(*empty.Empty))
+		return srv.(CLIServer).RemovePendingMirror(ctx, req.(*PendingMirrorRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_Upgrade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
+func _CLI_VerifyContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyContactRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).Upgrade(ctx, in)
+		return srv.(CLIServer).VerifyContact(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/Upgrade",
+		FullMethod: "/CLI/VerifyContact",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).Upgrade(ctx, req.(*empty.Empty))
+		return srv.(CLIServer).VerifyContact(ctx, req.(*VerifyContactRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_Reload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _CLI_GetContactStatuses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(empty.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).Reload(ctx, in)
+		return srv.(CLIServer).GetContactStatuses(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/Reload",
+		FullMethod: "/CLI/GetContactStatuses",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).Reload(ctx, req.(*empty.Empty))
+		return srv.(CLIServer).GetContactStatuses(ctx, req.(*empty.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_ChangeStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ChangeStatusRequest)
+func _CLI_GenerateAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MirrorIDRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).ChangeStatus(ctx, in)
+		return srv.(CLIServer).GenerateAPIKey(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/ChangeStatus",
+		FullMethod: "/CLI/GenerateAPIKey",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).ChangeStatus(ctx, req.(*ChangeStatusRequest))
+		return srv.(CLIServer).GenerateAPIKey(ctx, req.(*MirrorIDRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
+func _CLI_RevokeAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MirrorIDRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).List(ctx, in)
+		return srv.(CLIServer).RevokeAPIKey(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/List",
+		FullMethod: "/CLI/RevokeAPIKey",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).List(ctx, req.(*empty.Empty))
+		return srv.(CLIServer).RevokeAPIKey(ctx, req.(*MirrorIDRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_MirrorInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(MirrorIDRequest)
+func _CLI_GetRequestTrace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestTraceRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).MirrorInfo(ctx, in)
+		return srv.(CLIServer).GetRequestTrace(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/MirrorInfo",
+		FullMethod: "/CLI/GetRequestTrace",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).MirrorInfo(ctx, req.(*MirrorIDRequest))
+		return srv.(CLIServer).GetRequestTrace(ctx, req.(*RequestTraceRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_AddMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Mirror)
+func _CLI_GetHTTPErrors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).AddMirror(ctx, in)
+		return srv.(CLIServer).GetHTTPErrors(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/AddMirror",
+		FullMethod: "/CLI/GetHTTPErrors",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).AddMirror(ctx, req.(*Mirror))
+		return srv.(CLIServer).GetHTTPErrors(ctx, req.(*empty.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_UpdateMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Mirror)
+func _CLI_GetCoverage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).UpdateMirror(ctx, in)
+		return srv.(CLIServer).GetCoverage(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/UpdateMirror",
+		FullMethod: "/CLI/GetCoverage",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).UpdateMirror(ctx, req.(*Mirror))
+		return srv.(CLIServer).GetCoverage(ctx, req.(*empty.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_RemoveMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(MirrorIDRequest)
+func _CLI_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).RemoveMirror(ctx, in)
+		return srv.(CLIServer).Pause(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/RemoveMirror",
+		FullMethod: "/CLI/Pause",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).RemoveMirror(ctx, req.(*MirrorIDRequest))
+		return srv.(CLIServer).Pause(ctx, req.(*PauseRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_RefreshRepository_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RefreshRepositoryRequest)
+func _CLI_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).RefreshRepository(ctx, in)
+		return srv.(CLIServer).Resume(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/RefreshRepository",
+		FullMethod: "/CLI/Resume",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).RefreshRepository(ctx, req.(*RefreshRepositoryRequest))
+		return srv.(CLIServer).Resume(ctx, req.(*PauseRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_ScanMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ScanMirrorRequest)
+func _CLI_CollectGarbage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GCRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).ScanMirror(ctx, in)
+		return srv.(CLIServer).CollectGarbage(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/ScanMirror",
+		FullMethod: "/CLI/CollectGarbage",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).ScanMirror(ctx, req.(*ScanMirrorRequest))
+		return srv.(CLIServer).CollectGarbage(ctx, req.(*GCRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_StatsFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StatsFileRequest)
+func _CLI_DBInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DBInfoRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).StatsFile(ctx, in)
+		return srv.(CLIServer).DBInfo(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/StatsFile",
+		FullMethod: "/CLI/DBInfo",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).StatsFile(ctx, req.(*StatsFileRequest))
+		return srv.(CLIServer).DBInfo(ctx, req.(*DBInfoRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_StatsMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StatsMirrorRequest)
+func _CLI_MatchMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MatchRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).StatsMirror(ctx, in)
+		return srv.(CLIServer).MatchMirror(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/StatsMirror",
+		FullMethod: "/CLI/MatchMirror",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).StatsMirror(ctx, req.(*StatsMirrorRequest))
+		return srv.(CLIServer).MatchMirror(ctx, req.(*MatchRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
+func _CLI_ListRsyncModules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RsyncModulesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).Ping(ctx, in)
+		return srv.(CLIServer).ListRsyncModules(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/Ping",
+		FullMethod: "/CLI/ListRsyncModules",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).Ping(ctx, req.(*empty.Empty))
+		return srv.(CLIServer).ListRsyncModules(ctx, req.(*RsyncModulesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_GetMirrorLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetMirrorLogsRequest)
+func _CLI_SignURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignURLRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).GetMirrorLogs(ctx, in)
+		return srv.(CLIServer).SignURL(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/GetMirrorLogs",
+		FullMethod: "/CLI/SignURL",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).GetMirrorLogs(ctx, req.(*GetMirrorLogsRequest))
+		return srv.(CLIServer).SignURL(ctx, req.(*SignURLRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CLI_MatchMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(MatchRequest)
+func _CLI_ListFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFilesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CLIServer).MatchMirror(ctx, in)
+		return srv.(CLIServer).ListFiles(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/CLI/MatchMirror",
+		FullMethod: "/CLI/ListFiles",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLIServer).MatchMirror(ctx, req.(*MatchRequest))
+		return srv.(CLIServer).ListFiles(ctx, req.(*ListFilesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -1911,6 +5810,18 @@ var _CLI_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Reload",
 			Handler:    _CLI_Reload_Handler,
 		},
+		{
+			MethodName: "ReopenLogs",
+			Handler:    _CLI_ReopenLogs_Handler,
+		},
+		{
+			MethodName: "Stop",
+			Handler:    _CLI_Stop_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _CLI_Status_Handler,
+		},
 		{
 			MethodName: "ChangeStatus",
 			Handler:    _CLI_ChangeStatus_Handler,
@@ -1935,10 +5846,18 @@ var _CLI_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RemoveMirror",
 			Handler:    _CLI_RemoveMirror_Handler,
 		},
+		{
+			MethodName: "RenameMirror",
+			Handler:    _CLI_RenameMirror_Handler,
+		},
 		{
 			MethodName: "RefreshRepository",
 			Handler:    _CLI_RefreshRepository_Handler,
 		},
+		{
+			MethodName: "VerifyRepository",
+			Handler:    _CLI_VerifyRepository_Handler,
+		},
 		{
 			MethodName: "ScanMirror",
 			Handler:    _CLI_ScanMirror_Handler,
@@ -1959,10 +5878,82 @@ var _CLI_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetMirrorLogs",
 			Handler:    _CLI_GetMirrorLogs_Handler,
 		},
+		{
+			MethodName: "GetScanSummaries",
+			Handler:    _CLI_GetScanSummaries_Handler,
+		},
+		{
+			MethodName: "GetPropagation",
+			Handler:    _CLI_GetPropagation_Handler,
+		},
+		{
+			MethodName: "ListPendingMirrors",
+			Handler:    _CLI_ListPendingMirrors_Handler,
+		},
+		{
+			MethodName: "RemovePendingMirror",
+			Handler:    _CLI_RemovePendingMirror_Handler,
+		},
+		{
+			MethodName: "VerifyContact",
+			Handler:    _CLI_VerifyContact_Handler,
+		},
+		{
+			MethodName: "GetContactStatuses",
+			Handler:    _CLI_GetContactStatuses_Handler,
+		},
+		{
+			MethodName: "GenerateAPIKey",
+			Handler:    _CLI_GenerateAPIKey_Handler,
+		},
+		{
+			MethodName: "RevokeAPIKey",
+			Handler:    _CLI_RevokeAPIKey_Handler,
+		},
+		{
+			MethodName: "GetRequestTrace",
+			Handler:    _CLI_GetRequestTrace_Handler,
+		},
+		{
+			MethodName: "GetHTTPErrors",
+			Handler:    _CLI_GetHTTPErrors_Handler,
+		},
+		{
+			MethodName: "GetCoverage",
+			Handler:    _CLI_GetCoverage_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _CLI_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _CLI_Resume_Handler,
+		},
+		{
+			MethodName: "CollectGarbage",
+			Handler:    _CLI_CollectGarbage_Handler,
+		},
+		{
+			MethodName: "DBInfo",
+			Handler:    _CLI_DBInfo_Handler,
+		},
 		{
 			MethodName: "MatchMirror",
 			Handler:    _CLI_MatchMirror_Handler,
 		},
+		{
+			MethodName: "ListRsyncModules",
+			Handler:    _CLI_ListRsyncModules_Handler,
+		},
+		{
+			MethodName: "SignURL",
+			Handler:    _CLI_SignURL_Handler,
+		},
+		{
+			MethodName: "ListFiles",
+			Handler:    _CLI_ListFiles_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "rpc.proto",
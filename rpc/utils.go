@@ -39,14 +39,17 @@ func MirrorToRPC(m *mirrors.Mirror) (*Mirror, error) {
 		CustomData:           m.CustomData,
 		ContinentOnly:        m.ContinentOnly,
 		CountryOnly:          m.CountryOnly,
+		RegionOnly:           m.RegionOnly,
 		ASOnly:               m.ASOnly,
 		Score:                int32(m.Score),
 		Latitude:             m.Latitude,
 		Longitude:            m.Longitude,
 		ContinentCode:        m.ContinentCode,
-		CountryCodes:         m.CountryCodes,
-		ExcludedCountryCodes: m.ExcludedCountryCodes,
+		CountryCodes:         []string(m.CountryCodes),
+		ExcludedCountryCodes: []string(m.ExcludedCountryCodes),
+		RegionCode:           m.RegionCode,
 		Asnum:                uint32(m.Asnum),
+		ManualLocation:       m.ManualLocation,
 		Comment:              m.Comment,
 		Enabled:              m.Enabled,
 		Up:                   m.Up,
@@ -56,6 +59,19 @@ func MirrorToRPC(m *mirrors.Mirror) (*Mirror, error) {
 		LastSync:             lastSync,
 		LastSuccessfulSync:   lastSuccessfulSync,
 		LastModTime:          lastModTime,
+		IPv4:                 m.IPv4,
+		IPv6:                 m.IPv6,
+		IPAddress:            m.IPAddress,
+		LocationWarning:      m.LocationWarning,
+		CDN:                  m.CDN,
+		Channels:             []string(m.Channels),
+		MovedTo:              m.MovedTo,
+		PathRewrites:         pathRewritesToRPC(m.PathRewrites),
+		Endpoints:            endpointsToRPC(m.Endpoints),
+		EndpointsDown:        []string(m.EndpointsDown),
+		Schedule:             scheduleToRPC(m.Schedule),
+		Probe:                probeToRPC(m.Probe),
+		Proxy:                m.Proxy,
 	}, nil
 }
 
@@ -90,14 +106,17 @@ func MirrorFromRPC(m *Mirror) (*mirrors.Mirror, error) {
 		CustomData:           m.CustomData,
 		ContinentOnly:        m.ContinentOnly,
 		CountryOnly:          m.CountryOnly,
+		RegionOnly:           m.RegionOnly,
 		ASOnly:               m.ASOnly,
 		Score:                int(m.Score),
 		Latitude:             m.Latitude,
 		Longitude:            m.Longitude,
 		ContinentCode:        m.ContinentCode,
-		CountryCodes:         m.CountryCodes,
-		ExcludedCountryCodes: m.ExcludedCountryCodes,
+		CountryCodes:         mirrors.CountryList(m.CountryCodes),
+		ExcludedCountryCodes: mirrors.CountryList(m.ExcludedCountryCodes),
+		RegionCode:           m.RegionCode,
 		Asnum:                uint(m.Asnum),
+		ManualLocation:       m.ManualLocation,
 		Comment:              m.Comment,
 		Enabled:              m.Enabled,
 		Up:                   m.Up,
@@ -107,5 +126,128 @@ func MirrorFromRPC(m *Mirror) (*mirrors.Mirror, error) {
 		LastSync:             mirrors.Time{}.FromTime(lastSync),
 		LastSuccessfulSync:   mirrors.Time{}.FromTime(lastSuccessfulSync),
 		LastModTime:          mirrors.Time{}.FromTime(lastModTime),
+		IPv4:                 m.IPv4,
+		IPv6:                 m.IPv6,
+		IPAddress:            m.IPAddress,
+		LocationWarning:      m.LocationWarning,
+		CDN:                  m.CDN,
+		Channels:             mirrors.ChannelList(m.Channels),
+		MovedTo:              m.MovedTo,
+		PathRewrites:         pathRewritesFromRPC(m.PathRewrites),
+		Endpoints:            endpointsFromRPC(m.Endpoints),
+		EndpointsDown:        mirrors.URLList(m.EndpointsDown),
+		Schedule:             scheduleFromRPC(m.Schedule),
+		Probe:                probeFromRPC(m.Probe),
+		Proxy:                m.Proxy,
 	}, nil
 }
+
+func pathRewritesToRPC(rules mirrors.PathRewrites) (list []*PathRewrite) {
+	for _, r := range rules {
+		list = append(list, &PathRewrite{
+			From:   r.From,
+			To:     r.To,
+			Regexp: r.Regexp,
+			Scan:   r.Scan,
+		})
+	}
+	return
+}
+
+func pathRewritesFromRPC(list []*PathRewrite) (rules mirrors.PathRewrites) {
+	for _, r := range list {
+		rules = append(rules, mirrors.PathRewrite{
+			From:   r.From,
+			To:     r.To,
+			Regexp: r.Regexp,
+			Scan:   r.Scan,
+		})
+	}
+	return
+}
+
+func endpointsToRPC(endpoints mirrors.Endpoints) (list []*Endpoint) {
+	for _, e := range endpoints {
+		list = append(list, &Endpoint{
+			HttpURL:   e.HttpURL,
+			Latitude:  e.Latitude,
+			Longitude: e.Longitude,
+		})
+	}
+	return
+}
+
+func endpointsFromRPC(list []*Endpoint) (endpoints mirrors.Endpoints) {
+	for _, e := range list {
+		endpoints = append(endpoints, mirrors.Endpoint{
+			HttpURL:   e.HttpURL,
+			Latitude:  e.Latitude,
+			Longitude: e.Longitude,
+		})
+	}
+	return
+}
+
+func scheduleToRPC(s mirrors.Schedule) *Schedule {
+	if s.IsZero() && s.TimeZone == "" && s.Default == 0 {
+		return nil
+	}
+	schedule := &Schedule{
+		TimeZone: s.TimeZone,
+		Default:  int32(s.Default),
+	}
+	for _, p := range s.Periods {
+		schedule.Periods = append(schedule.Periods, &SchedulePeriod{
+			Days:  p.Days,
+			From:  p.From,
+			To:    p.To,
+			Share: int32(p.Share),
+		})
+	}
+	return schedule
+}
+
+func scheduleFromRPC(s *Schedule) (schedule mirrors.Schedule) {
+	if s == nil {
+		return
+	}
+	schedule.TimeZone = s.TimeZone
+	schedule.Default = int(s.Default)
+	for _, p := range s.Periods {
+		schedule.Periods = append(schedule.Periods, mirrors.SchedulePeriod{
+			Days:  p.Days,
+			From:  p.From,
+			To:    p.To,
+			Share: int(p.Share),
+		})
+	}
+	return
+}
+
+func probeToRPC(p mirrors.Probe) *Probe {
+	if p.IsZero() {
+		return nil
+	}
+	probe := &Probe{
+		Headers:  p.Headers,
+		Username: p.Username,
+		Password: p.Password,
+	}
+	for _, code := range p.ExpectedStatus {
+		probe.ExpectedStatus = append(probe.ExpectedStatus, int32(code))
+	}
+	return probe
+}
+
+func probeFromRPC(p *Probe) (probe mirrors.Probe) {
+	if p == nil {
+		return
+	}
+	probe.Headers = p.Headers
+	probe.Username = p.Username
+	probe.Password = p.Password
+	for _, code := range p.ExpectedStatus {
+		probe.ExpectedStatus = append(probe.ExpectedStatus, int(code))
+	}
+	return
+}
@@ -4,6 +4,7 @@
 package scan
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
@@ -11,7 +12,6 @@ import (
 
 	ftp "github.com/etix/goftp"
 	"github.com/etix/mirrorbits/core"
-	"github.com/etix/mirrorbits/utils"
 	"github.com/gomodule/redigo/redis"
 )
 
@@ -30,7 +30,7 @@ type FTPScanner struct {
 }
 
 // Scan starts an ftp scan of the given mirror
-func (f *FTPScanner) Scan(scanurl, identifier string, conn redis.Conn, stop <-chan struct{}) (core.Precision, error) {
+func (f *FTPScanner) Scan(ctx context.Context, scanurl, identifier string, conn redis.Conn) (core.Precision, error) {
 	if !strings.HasPrefix(scanurl, "ftp://") {
 		return 0, fmt.Errorf("%s does not start with ftp://", scanurl)
 	}
@@ -40,13 +40,18 @@ func (f *FTPScanner) Scan(scanurl, identifier string, conn redis.Conn, stop <-ch
 		return 0, err
 	}
 
+	// The FTP client dials the control and data connections directly
+	if f.scan.proxy != nil {
+		return 0, ErrProxyUnsupported
+	}
+
 	host := ftpurl.Host
 	if !strings.Contains(host, ":") {
 		host += ":21"
 	}
 
-	if utils.IsStopped(stop) {
-		return 0, ErrScanAborted
+	if ctx.Err() != nil {
+		return 0, abortError(ctx)
 	}
 
 	c, err := ftp.DialTimeout(host, ftpConnTimeout, ftpRWTimeout)
@@ -94,9 +99,25 @@ func (f *FTPScanner) Scan(scanurl, identifier string, conn redis.Conn, stop <-ch
 	// Remove the trailing slash
 	prefix := strings.TrimRight(ftpurl.Path, "/")
 
-	files, err = f.walkFtp(c, files, prefix+"/", stop)
-	if err != nil {
-		return 0, fmt.Errorf("ftp error %s", err.Error())
+	prefixes := f.scan.mirrorPrefixes()
+	if prefixes == nil {
+		files, err = f.walkFtp(ctx, c, files, prefix+"/")
+		if err == ErrScanAborted || err == ErrScanTimeout {
+			return 0, err
+		} else if err != nil {
+			return 0, fmt.Errorf("ftp error %s", err.Error())
+		}
+	}
+	// Only walk the directories of the channels of the mirror
+	for _, p := range prefixes {
+		list, err := f.walkFtp(ctx, c, files, prefix+strings.TrimSuffix(p, "/")+"/")
+		if err == ErrScanAborted || err == ErrScanTimeout {
+			return 0, err
+		} else if err != nil {
+			log.Warningf("[%s] Unable to list %s: %s", identifier, p, err)
+			continue
+		}
+		files = list
 	}
 
 	count := 0
@@ -110,9 +131,9 @@ func (f *FTPScanner) Scan(scanurl, identifier string, conn redis.Conn, stop <-ch
 }
 
 // Walk inside an FTP repository
-func (f *FTPScanner) walkFtp(c *ftp.ServerConn, files []*filedata, path string, stop <-chan struct{}) ([]*filedata, error) {
-	if utils.IsStopped(stop) {
-		return nil, ErrScanAborted
+func (f *FTPScanner) walkFtp(ctx context.Context, c *ftp.ServerConn, files []*filedata, path string) ([]*filedata, error) {
+	if ctx.Err() != nil {
+		return nil, abortError(ctx)
 	}
 
 	flist, err := c.List(path)
@@ -157,7 +178,7 @@ func (f *FTPScanner) walkFtp(c *ftp.ServerConn, files []*filedata, path string,
 			if e.Name == "." || e.Name == ".." {
 				continue
 			}
-			files, err = f.walkFtp(c, files, path+e.Name+"/", stop)
+			files, err = f.walkFtp(ctx, c, files, path+e.Name+"/")
 			if err != nil {
 				return files, err
 			}
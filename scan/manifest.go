@@ -0,0 +1,106 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package scan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/etix/mirrorbits/core"
+	"github.com/etix/mirrorbits/filesystem"
+	"github.com/etix/mirrorbits/mirrors"
+	"github.com/gomodule/redigo/redis"
+)
+
+var (
+	manifestClient = &http.Client{
+		Timeout: 5 * time.Minute,
+		Transport: &http.Transport{
+			Proxy: mirrors.ProxyFromContext,
+		},
+	}
+)
+
+// ManifestScanner is the implementation of a scanner fetching the copy of
+// the sync manifest published by the mirror (see SyncManifest)
+type ManifestScanner struct {
+	scan *scan
+}
+
+// Scan fetches the manifest of the given mirror and indexes its files
+func (m *ManifestScanner) Scan(ctx context.Context, scanurl, identifier string, conn redis.Conn) (core.Precision, error) {
+	req, err := http.NewRequest("GET", scanurl, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "Mirrorbits/"+core.VERSION+" MANIFEST")
+	req = req.WithContext(context.WithValue(ctx, core.ContextProxy, m.scan.proxy))
+
+	log.Infof("[%s] Requesting file list via the sync manifest...", identifier)
+
+	resp, err := manifestClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, abortError(ctx)
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("manifest error %s", resp.Status)
+	}
+
+	manifest, err := filesystem.ReadManifest(resp.Body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, abortError(ctx)
+		}
+		return 0, fmt.Errorf("manifest error %s", err.Error())
+	}
+	if !manifest.Generated.IsZero() {
+		log.Debugf("[%s] Manifest generated on %s", identifier, manifest.Generated)
+	}
+
+	// The manifest holds the paths of the repository, the files whose
+	// hashes differ from the ones of the repository are not indexed
+	rconn := m.scan.redis.Get()
+	defer rconn.Close()
+
+	for i := 0; i < len(manifest.Files); i += scanBatchSize {
+		if ctx.Err() != nil {
+			return 0, abortError(ctx)
+		}
+		end := i + scanBatchSize
+		if end > len(manifest.Files) {
+			end = len(manifest.Files)
+		}
+		batch := manifest.Files[i:end]
+		for _, f := range batch {
+			rconn.Send("HMGET", fmt.Sprintf("FILE_%s", f.Path), "sha1", "sha256", "md5")
+		}
+		replies, err := redis.Values(rconn.Do(""))
+		if err != nil {
+			return 0, err
+		}
+		for j, f := range batch {
+			hashes, _ := redis.Strings(replies[j], nil)
+			if len(hashes) == 3 {
+				local := filesystem.FileInfo{Sha1: hashes[0], Sha256: hashes[1], Md5: hashes[2]}
+				if !local.Matches(f) {
+					log.Debugf("[%s] Ignoring %s: checksum mismatch", identifier, f.Path)
+					continue
+				}
+			}
+			m.scan.addFile(filedata{
+				path:    f.Path,
+				size:    f.Size,
+				modTime: f.ModTime,
+			})
+		}
+	}
+
+	return core.Precision(time.Second), nil
+}
@@ -0,0 +1,371 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package scan
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/core"
+	"github.com/etix/mirrorbits/database"
+	"github.com/etix/mirrorbits/mirrors"
+	"github.com/etix/mirrorbits/utils"
+)
+
+// QueuedScan is a scan of a mirror waiting in the queue or in progress
+type QueuedScan struct {
+	MirrorID   int
+	MirrorName string
+	// Origin of the request: scheduler, cli, api or push
+	Source string
+	// Methods to try in order, nil for the manifest (if enabled), rsync
+	// and FTP
+	Methods []core.ScannerType
+	Force   bool
+	Queued  time.Time
+	// Start of the scan, zero while waiting for a worker
+	Started time.Time
+
+	queue *Queue
+	// Set when a request doesn't wait for the result (e.g. the scheduler),
+	// the scan being then never cancelled by its waiters leaving
+	detached bool
+	waiters  int
+	cancel   context.CancelFunc
+	done     chan struct{}
+	result   *ScanResult
+	err      error
+}
+
+// Wait waits for the end of the scan and returns its result, or the error
+// of the context if it is done first. A scan requested only by waiters
+// (e.g. from the CLI) is dropped, or aborted if in progress, when its last
+// waiter leaves.
+func (s *QueuedScan) Wait(ctx context.Context) (*ScanResult, error) {
+	q := s.queue
+	q.lock.Lock()
+	s.waiters++
+	q.lock.Unlock()
+
+	select {
+	case <-s.done:
+		q.lock.Lock()
+		s.waiters--
+		q.lock.Unlock()
+		return s.result, s.err
+	case <-ctx.Done():
+		q.leave(s)
+		return nil, ctx.Err()
+	}
+}
+
+// Queue is the queue of the scans requested to this process. A mirror is
+// never scanned by two workers at once and the requests for a mirror
+// already waiting are merged into the pending scan.
+type Queue struct {
+	lock    sync.Mutex
+	run     func(ctx context.Context, s *QueuedScan) (*ScanResult, error)
+	pending []*QueuedScan
+	running map[int]*QueuedScan
+	wake    chan struct{}
+	workers int
+}
+
+// NewQueue returns a queue running the scans with the given function
+func NewQueue(run func(ctx context.Context, s *QueuedScan) (*ScanResult, error)) *Queue {
+	return &Queue{
+		run:     run,
+		running: make(map[int]*QueuedScan),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Add queues the scan of the given mirror, unless one is already waiting
+// in which case the request is merged into it: the scan is forced if any of
+// the requests is forced and uses the methods of the latest request giving
+// some. It returns the scan to wait for and whether it was newly queued.
+func (q *Queue) Add(id int, name, source string, methods []core.ScannerType, force bool) (*QueuedScan, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for _, s := range q.pending {
+		if s.MirrorID == id {
+			s.detached = s.detached || source != core.ScanSourceCLI
+			s.Force = s.Force || force
+			if methods != nil {
+				s.Methods = methods
+			}
+			return s, false
+		}
+	}
+
+	s := &QueuedScan{
+		MirrorID:   id,
+		MirrorName: name,
+		Source:     source,
+		Methods:    methods,
+		Force:      force,
+		Queued:     time.Now(),
+		queue:      q,
+		detached:   source != core.ScanSourceCLI,
+		done:       make(chan struct{}),
+	}
+	q.pending = append(q.pending, s)
+	q.signal()
+	return s, true
+}
+
+// Has returns true if a scan of the given mirror is waiting or in progress
+func (q *Queue) Has(id int) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if _, ok := q.running[id]; ok {
+		return true
+	}
+	for _, s := range q.pending {
+		if s.MirrorID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove drops the scan of the given mirror waiting in the queue, if any.
+// Its waiters receive ErrScanAborted.
+func (q *Queue) Remove(id int) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for i, s := range q.pending {
+		if s.MirrorID == id {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			s.err = ErrScanAborted
+			close(s.done)
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveSource drops the scans waiting in the queue requested from one of
+// the given sources and returns their number. Their waiters receive
+// ErrScanAborted.
+func (q *Queue) RemoveSource(sources ...string) int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	removed := 0
+	pending := q.pending[:0]
+	for _, s := range q.pending {
+		if !utils.IsInSlice(s.Source, sources) {
+			pending = append(pending, s)
+			continue
+		}
+		s.err = ErrScanAborted
+		close(s.done)
+		removed++
+	}
+	q.pending = pending
+	return removed
+}
+
+// leave unregisters a waiter of the given scan and drops or aborts the scan
+// if nothing else waits for it
+func (q *Queue) leave(s *QueuedScan) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	s.waiters--
+	if s.waiters > 0 || s.detached {
+		return
+	}
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	if s.cancel != nil {
+		// In progress, the worker reports the end of the scan
+		s.cancel()
+		return
+	}
+	for i, p := range q.pending {
+		if p == s {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			s.err = ErrScanAborted
+			close(s.done)
+			return
+		}
+	}
+}
+
+// Contents returns a copy of the scans in progress followed by the scans
+// waiting, in their order of execution
+func (q *Queue) Contents() []QueuedScan {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	list := make([]QueuedScan, 0, len(q.running)+len(q.pending))
+	for _, s := range q.running {
+		list = append(list, *s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Started.Before(list[j].Started)
+	})
+	for _, s := range q.pending {
+		list = append(list, *s)
+	}
+	return list
+}
+
+// Pool reports the activity of the workers of the queue
+func (q *Queue) Pool() core.WorkerPool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return core.WorkerPool{
+		Workers: q.workers,
+		Busy:    len(q.running),
+		Queued:  len(q.pending),
+	}
+}
+
+// Run runs the scans of the queue with the given number of workers until
+// the context is done. The scans still waiting are then dropped.
+func (q *Queue) Run(ctx context.Context, workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	q.lock.Lock()
+	q.workers = workers
+	q.lock.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+
+	q.lock.Lock()
+	for _, s := range q.pending {
+		s.err = ErrScanAborted
+		close(s.done)
+	}
+	q.pending = nil
+	q.workers = 0
+	q.lock.Unlock()
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		s, sctx := q.next(ctx)
+		if s == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.wake:
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			q.finish(s, nil, ErrScanAborted)
+			return
+		}
+		res, err := q.run(sctx, s)
+		q.finish(s, res, err)
+	}
+}
+
+// next returns the first scan waiting whose mirror is not being scanned
+// along with the context of its execution, nil if there is none
+func (q *Queue) next(ctx context.Context) (*QueuedScan, context.Context) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for i, s := range q.pending {
+		if _, ok := q.running[s.MirrorID]; ok {
+			continue
+		}
+		q.pending = append(q.pending[:i], q.pending[i+1:]...)
+		s.Started = time.Now()
+		q.running[s.MirrorID] = s
+		ctx, s.cancel = context.WithCancel(ctx)
+		// Let another worker pick the next scan
+		if len(q.pending) > 0 {
+			q.signal()
+		}
+		return s, ctx
+	}
+	return nil, nil
+}
+
+func (q *Queue) finish(s *QueuedScan, res *ScanResult, err error) {
+	q.lock.Lock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	delete(q.running, s.MirrorID)
+	s.result, s.err = res, err
+	close(s.done)
+	// A scan of the same mirror may be waiting for this one
+	if len(q.pending) > 0 {
+		q.signal()
+	}
+	q.lock.Unlock()
+}
+
+// signal wakes up a worker, the lock being held
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ScanMirror scans the mirror of the request with the first of its methods
+// succeeding
+func ScanMirror(ctx context.Context, r *database.Redis, c *mirrors.Cache, s *QueuedScan) (*ScanResult, error) {
+	mirror, err := c.GetMirror(s.MirrorID)
+	if err != nil {
+		return nil, err
+	}
+
+	methods := s.Methods
+	if methods == nil {
+		methods = []core.ScannerType{core.MANIFEST, core.RSYNC, core.FTP}
+	}
+
+	var res *ScanResult
+	err = ErrNoSyncMethod
+	for _, typ := range methods {
+		var url string
+		switch typ {
+		case core.MANIFEST:
+			if manifest := GetConfig().SyncManifest; manifest.ScanMirrors {
+				url = utils.ConcatURL(mirror.HttpURL, manifest.Path)
+			}
+		case core.RSYNC:
+			url = mirror.RsyncURL
+		case core.FTP:
+			url = mirror.FtpURL
+		}
+		if url == "" {
+			continue
+		}
+		res, err = Scan(ctx, typ, r, c, url, mirror.ID, s.Force)
+		if err == nil || err == ErrScanAborted || err == ErrScanQuarantined {
+			break
+		}
+	}
+	return res, err
+}
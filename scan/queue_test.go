@@ -0,0 +1,208 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package scan
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/etix/mirrorbits/core"
+)
+
+func TestQueue_Add(t *testing.T) {
+	q := NewQueue(nil)
+
+	s1, queued := q.Add(1, "m1", core.ScanSourceScheduler, nil, false)
+	if !queued {
+		t.Fatalf("Expected the scan to be queued")
+	}
+	s2, queued := q.Add(1, "m1", core.ScanSourceCLI, []core.ScannerType{core.RSYNC}, true)
+	if queued || s2 != s1 {
+		t.Fatalf("Expected the request to be merged into the pending scan")
+	}
+	if !s1.Force || len(s1.Methods) != 1 || s1.Methods[0] != core.RSYNC || s1.Source != core.ScanSourceScheduler {
+		t.Fatalf("Unexpected merged scan %+v", s1)
+	}
+	q.Add(2, "m2", core.ScanSourceAPI, nil, false)
+
+	if !q.Has(1) || !q.Has(2) || q.Has(3) {
+		t.Fatalf("Unexpected content of the queue")
+	}
+	contents := q.Contents()
+	if len(contents) != 2 || contents[0].MirrorID != 1 || contents[1].MirrorID != 2 {
+		t.Fatalf("Unexpected content of the queue: %+v", contents)
+	}
+
+	if !q.Remove(1) || q.Has(1) {
+		t.Fatalf("Expected the scan to be removed")
+	}
+	if _, err := s1.Wait(context.Background()); err != ErrScanAborted {
+		t.Fatalf("Expected ErrScanAborted, got %v", err)
+	}
+
+	s3, _ := q.Add(3, "m3", core.ScanSourceCLI, nil, false)
+	if n := q.RemoveSource(core.ScanSourceScheduler, core.ScanSourceAPI); n != 1 || q.Has(2) || !q.Has(3) {
+		t.Fatalf("Expected only the scan requested from the API to be removed")
+	}
+	select {
+	case <-s3.done:
+		t.Fatalf("Expected the scan requested from the CLI to be kept")
+	default:
+	}
+}
+
+func TestQueue_Run(t *testing.T) {
+	var lock sync.Mutex
+	running := make(map[int]bool)
+	concurrent, maxConcurrent := 0, 0
+	overlap := false
+	release := make(chan struct{})
+
+	q := NewQueue(func(ctx context.Context, s *QueuedScan) (*ScanResult, error) {
+		lock.Lock()
+		if running[s.MirrorID] {
+			overlap = true
+		}
+		running[s.MirrorID] = true
+		concurrent++
+		if concurrent > maxConcurrent {
+			maxConcurrent = concurrent
+		}
+		lock.Unlock()
+
+		<-release
+
+		lock.Lock()
+		running[s.MirrorID] = false
+		concurrent--
+		lock.Unlock()
+		return &ScanResult{MirrorID: s.MirrorID}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx, 2)
+		close(done)
+	}()
+
+	var scans []*QueuedScan
+	for id := 1; id <= 3; id++ {
+		s, _ := q.Add(id, "", core.ScanSourceScheduler, nil, false)
+		scans = append(scans, s)
+	}
+
+	// Wait for the workers to pick two scans
+	for i := 0; i < 100 && q.Pool().Busy < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if pool := q.Pool(); pool.Workers != 2 || pool.Busy != 2 || pool.Queued != 1 {
+		t.Fatalf("Unexpected activity %+v", pool)
+	}
+
+	// A request for a mirror being scanned waits for the end of its scan
+	again, queued := q.Add(1, "", core.ScanSourceCLI, nil, false)
+	if !queued {
+		t.Fatalf("Expected a new scan of the mirror being scanned")
+	}
+
+	close(release)
+	for _, s := range append(scans, again) {
+		res, err := s.Wait(ctx)
+		if err != nil || res.MirrorID != s.MirrorID {
+			t.Fatalf("Unexpected result %+v (%v)", res, err)
+		}
+	}
+	if maxConcurrent != 2 {
+		t.Fatalf("Expected 2 scans at once, got %d", maxConcurrent)
+	}
+	if overlap {
+		t.Fatalf("Expected the scans of a mirror to never overlap")
+	}
+
+	cancel()
+	<-done
+}
+
+func TestQueue_WaitCancel(t *testing.T) {
+	release := make(chan struct{})
+	aborted := make(chan int, 1)
+
+	q := NewQueue(func(ctx context.Context, s *QueuedScan) (*ScanResult, error) {
+		select {
+		case <-ctx.Done():
+			aborted <- s.MirrorID
+			return nil, ErrScanAborted
+		case <-release:
+			return &ScanResult{MirrorID: s.MirrorID}, nil
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx, 1)
+		close(done)
+	}()
+
+	// A scan from the CLI is aborted when its waiter leaves
+	s1, _ := q.Add(1, "m1", core.ScanSourceCLI, nil, false)
+	for i := 0; i < 100 && q.Pool().Busy < 1; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	wctx, wcancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	if _, err := s1.Wait(wctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	wcancel()
+	select {
+	case id := <-aborted:
+		if id != 1 {
+			t.Fatalf("Expected the scan of mirror 1 to be aborted, got %d", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the running scan to be aborted")
+	}
+	if _, err := s1.Wait(ctx); err != ErrScanAborted {
+		t.Fatalf("Expected ErrScanAborted, got %v", err)
+	}
+
+	// Keep the worker busy with a background scan
+	q.Add(2, "m2", core.ScanSourceScheduler, nil, false)
+	for i := 0; i < 100 && q.Pool().Busy < 1; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A pending scan from the CLI is dropped when its waiter leaves
+	s3, _ := q.Add(3, "m3", core.ScanSourceCLI, nil, false)
+	wctx, wcancel = context.WithCancel(ctx)
+	wcancel()
+	if _, err := s3.Wait(wctx); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if q.Has(3) {
+		t.Fatalf("Expected the pending scan to be dropped")
+	}
+
+	// A scan also requested in the background is kept
+	q.Add(4, "m4", core.ScanSourceScheduler, nil, false)
+	s4, _ := q.Add(4, "m4", core.ScanSourceCLI, nil, true)
+	if _, err := s4.Wait(wctx); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if !q.Has(4) {
+		t.Fatalf("Expected the scan requested in the background to be kept")
+	}
+
+	close(release)
+	if res, err := s4.Wait(ctx); err != nil || res.MirrorID != 4 {
+		t.Fatalf("Unexpected result %+v (%v)", res, err)
+	}
+
+	cancel()
+	<-done
+}
@@ -5,9 +5,11 @@ package scan
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/url"
 	"os/exec"
 	"regexp"
@@ -15,11 +17,16 @@ import (
 	"strings"
 	"time"
 
+	. "github.com/etix/mirrorbits/config"
 	"github.com/etix/mirrorbits/core"
-	"github.com/etix/mirrorbits/utils"
 	"github.com/gomodule/redigo/redis"
 )
 
+const (
+	// Maximum number of errors of rsync reported at the end of a scan
+	maxRsyncErrors = 100
+)
+
 var (
 	rsyncOutputLine = regexp.MustCompile(`^.+\s+([0-9,]+)\s+([0-9/]+)\s+([0-9:]+)\s+(.*)$`)
 )
@@ -30,39 +37,20 @@ type RsyncScanner struct {
 }
 
 // Scan starts an rsync scan of the given mirror
-func (r *RsyncScanner) Scan(rsyncURL, identifier string, conn redis.Conn, stop <-chan struct{}) (core.Precision, error) {
-	var env []string
-
-	if !strings.HasPrefix(rsyncURL, "rsync://") {
-		return 0, fmt.Errorf("%s does not start with rsync://", rsyncURL)
+func (r *RsyncScanner) Scan(ctx context.Context, rsyncURL, identifier string, conn redis.Conn) (core.Precision, error) {
+	// Output the UTF-8 characters of the file names as is
+	args := []string{"-r", "--no-motd", "--timeout=30", "--contimeout=30", "--8-bit-output", "--exclude=.~tmp~/"}
+	if GetConfig().SymlinkPolicy == SymlinkFollow {
+		// List the symlinks as the file or directory they point to
+		args = append(args, "--copy-links")
 	}
+	args = append(args, rsyncFilters(r.scan.mirrorPrefixes())...)
 
-	u, err := url.Parse(rsyncURL)
+	cmd, err := rsyncCommand(rsyncURL, r.scan.proxy, args...)
 	if err != nil {
 		return 0, err
 	}
 
-	// Extract the credentials
-	if u.User != nil {
-		if u.User.Username() != "" {
-			env = append(env, fmt.Sprintf("USER=%s", u.User.Username()))
-		}
-		if password, ok := u.User.Password(); ok {
-			env = append(env, fmt.Sprintf("RSYNC_PASSWORD=%s", password))
-		}
-
-		// Remove the credentials from the URL as we pass them through the environnement
-		u.User = nil
-	}
-
-	// Don't use the local timezone, use UTC
-	env = append(env, "TZ=UTC")
-
-	cmd := exec.Command("rsync", "-r", "--no-motd", "--timeout=30", "--contimeout=30", "--exclude=.~tmp~/", u.String())
-
-	// Setup the environnement
-	cmd.Env = env
-
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return 0, err
@@ -77,8 +65,8 @@ func (r *RsyncScanner) Scan(rsyncURL, identifier string, conn redis.Conn, stop <
 	reader := bufio.NewReader(stdout)
 	readerErr := bufio.NewReader(stderr)
 
-	if utils.IsStopped(stop) {
-		return 0, ErrScanAborted
+	if ctx.Err() != nil {
+		return 0, abortError(ctx)
 	}
 
 	// Start the process
@@ -91,7 +79,7 @@ func (r *RsyncScanner) Scan(rsyncURL, identifier string, conn redis.Conn, stop <
 	scanfinished := make(chan bool)
 	go func() {
 		select {
-		case <-stop:
+		case <-ctx.Done():
 			cmd.Process.Kill()
 			return
 		case <-scanfinished:
@@ -100,6 +88,19 @@ func (r *RsyncScanner) Scan(rsyncURL, identifier string, conn redis.Conn, stop <
 	}()
 	defer close(scanfinished)
 
+	// Read the errors while the listing is parsed so that rsync never
+	// blocks on a full pipe, keeping the first ones only
+	rsyncErrors := make(chan []string, 1)
+	go func() {
+		lines := []string{}
+		for line, err := readln(readerErr); err == nil; line, err = readln(readerErr) {
+			if strings.Contains(line, ": opendir ") && len(lines) < maxRsyncErrors {
+				lines = append(lines, line)
+			}
+		}
+		rsyncErrors <- lines
+	}()
+
 	line, err := readln(reader)
 	for err == nil {
 		var size int64
@@ -107,17 +108,24 @@ func (r *RsyncScanner) Scan(rsyncURL, identifier string, conn redis.Conn, stop <
 		var modTime time.Time
 		var modString string
 
-		if utils.IsStopped(stop) {
-			return 0, ErrScanAborted
+		if ctx.Err() != nil {
+			return 0, abortError(ctx)
 		}
 
 		// Parse one line returned by rsync
 		ret := rsyncOutputLine.FindStringSubmatch(line)
+		if ret == nil {
+			log.Warningf("[%s] ScanRsync: Unable to parse line: %s", identifier, line)
+			goto cont
+		}
 		if ret[0][0] == 'd' || ret[0][0] == 'l' {
-			// Skip directories and links
+			// Skip directories and links (unless followed with --copy-links)
 			goto cont
 		}
 
+		// Decode the characters escaped by rsync
+		ret[4] = unescapeRsyncPath(ret[4])
+
 		// Add the leading slash
 		if ret[4][0] != '/' {
 			ret[4] = "/" + ret[4]
@@ -151,36 +159,21 @@ func (r *RsyncScanner) Scan(rsyncURL, identifier string, conn redis.Conn, stop <
 		line, err = readln(reader)
 	}
 
-	rsyncErrors := []string{}
-	for line, err = readln(readerErr); err == nil; line, err = readln(readerErr) {
-		if strings.Contains(line, ": opendir ") {
-			rsyncErrors = append(rsyncErrors, line)
-		}
-	}
+	errorLines := <-rsyncErrors
 
 	if err1 := cmd.Wait(); err1 != nil {
 		switch err1.Error() {
-		case "exit status 5":
-			err1 = errors.New("rsync: Error starting client-server protocol")
-		case "exit status 10":
-			err1 = errors.New("rsync: Error in socket I/O")
-		case "exit status 11":
-			err1 = errors.New("rsync: Error in file I/O")
 		case "exit status 23":
-			for _, line := range rsyncErrors {
+			for _, line := range errorLines {
 				log.Warningf("[%s] %s", identifier, line)
 			}
 			log.Warningf("[%s] rsync: Partial transfer due to error", identifier)
 			err1 = nil
-		case "exit status 30":
-			err1 = errors.New("rsync: Timeout in data send/receive")
-		case "exit status 35":
-			err1 = errors.New("Timeout waiting for daemon connection")
 		default:
-			if utils.IsStopped(stop) {
-				err1 = ErrScanAborted
+			if ctx.Err() != nil {
+				err1 = abortError(ctx)
 			} else {
-				err1 = errors.New("rsync: " + err1.Error())
+				err1 = rsyncError(err1)
 			}
 		}
 		return 0, err1
@@ -193,6 +186,244 @@ func (r *RsyncScanner) Scan(rsyncURL, identifier string, conn redis.Conn, stop <
 	return core.Precision(time.Second), nil
 }
 
+// rsyncFilters returns the rsync filter rules limiting the listing to the
+// given directories (and their parents)
+func rsyncFilters(prefixes []string) []string {
+	if prefixes == nil {
+		return nil
+	}
+	var args []string
+	parents := make(map[string]bool)
+	for _, p := range prefixes {
+		p = strings.Trim(p, "/")
+		if p == "" {
+			// The whole repository
+			return nil
+		}
+		parts := strings.Split(p, "/")
+		for i := 1; i < len(parts); i++ {
+			parent := "/" + strings.Join(parts[:i], "/") + "/"
+			if !parents[parent] {
+				parents[parent] = true
+				args = append(args, "--include="+parent)
+			}
+		}
+		args = append(args, "--include=/"+p+"/***")
+	}
+	return append(args, "--exclude=*")
+}
+
+// unescapeRsyncPath decodes the non-printable characters (including the
+// newlines and the bytes of the invalid UTF-8 sequences) that rsync
+// outputs as \#ooo octal escapes
+func unescapeRsyncPath(path string) string {
+	if !strings.Contains(path, "\\#") {
+		return path
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+4 < len(path) && path[i+1] == '#' {
+			if c, err := strconv.ParseUint(path[i+2:i+5], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 4
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
+
+// RsyncModule is a module exported by an rsync daemon
+type RsyncModule struct {
+	Name    string
+	Comment string
+}
+
+// ListRsyncModules returns the list of modules exported by the rsync daemon
+// running on the host of the given URL
+func ListRsyncModules(rsyncURL string) ([]RsyncModule, error) {
+	u, err := url.Parse(rsyncURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/"
+
+	proxy, err := ParseProxy(GetConfig().OutboundProxy)
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := rsyncCommand(u.String(), proxy, "--no-motd", "--timeout=30", "--contimeout=30")
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, rsyncError(err)
+	}
+
+	modules := []RsyncModule{}
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		// Each line is made of the module name followed by a tab and its comment
+		fields := strings.SplitN(line, "\t", 2)
+		module := RsyncModule{
+			Name: strings.TrimSpace(fields[0]),
+		}
+		if len(fields) == 2 {
+			module.Comment = strings.TrimSpace(fields[1])
+		}
+		modules = append(modules, module)
+	}
+
+	return modules, nil
+}
+
+// ListRsyncDirectory returns the name of the entries found at the given rsync
+// URL without descending into the subdirectories
+func ListRsyncDirectory(rsyncURL string) ([]string, error) {
+	if !strings.HasSuffix(rsyncURL, "/") {
+		rsyncURL += "/"
+	}
+
+	proxy, err := ParseProxy(GetConfig().OutboundProxy)
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := rsyncCommand(rsyncURL, proxy, "--no-motd", "--timeout=30", "--contimeout=30")
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, rsyncError(err)
+	}
+
+	entries := []string{}
+	for _, line := range strings.Split(string(output), "\n") {
+		ret := rsyncOutputLine.FindStringSubmatch(line)
+		if ret == nil || ret[4] == "." {
+			continue
+		}
+		entries = append(entries, ret[4])
+	}
+
+	return entries, nil
+}
+
+// RsyncContainsRepository returns true if the given rsync URL looks like the
+// root of the local repository, i.e. if most of the top-level entries of the
+// local repository can also be found at the root of the remote one
+func RsyncContainsRepository(rsyncURL string) (bool, error) {
+	local, err := ioutil.ReadDir(GetConfig().Repository)
+	if err != nil {
+		return false, err
+	}
+
+	remote, err := ListRsyncDirectory(rsyncURL)
+	if err != nil {
+		return false, err
+	}
+
+	entries := make(map[string]bool, len(remote))
+	for _, e := range remote {
+		entries[e] = true
+	}
+
+	var total, found int
+	for _, f := range local {
+		if strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		total++
+		if entries[f.Name()] {
+			found++
+		}
+	}
+
+	return total > 0 && found*2 >= total, nil
+}
+
+// rsyncCommand prepares an rsync command for the given URL, passing the
+// credentials (if any) and the proxy through the environment. Only the HTTP
+// proxies are supported by rsync.
+func rsyncCommand(rsyncURL string, proxy *url.URL, args ...string) (*exec.Cmd, error) {
+	var env []string
+
+	if !strings.HasPrefix(rsyncURL, "rsync://") {
+		return nil, fmt.Errorf("%s does not start with rsync://", rsyncURL)
+	}
+
+	u, err := url.Parse(rsyncURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract the credentials
+	if u.User != nil {
+		if u.User.Username() != "" {
+			env = append(env, fmt.Sprintf("USER=%s", u.User.Username()))
+		}
+		if password, ok := u.User.Password(); ok {
+			env = append(env, fmt.Sprintf("RSYNC_PASSWORD=%s", password))
+		}
+
+		// Remove the credentials from the URL as we pass them through the environnement
+		u.User = nil
+	}
+
+	if proxy != nil {
+		if proxy.Scheme != "http" {
+			return nil, ErrProxyUnsupported
+		}
+		host := proxy.Host
+		if proxy.Port() == "" {
+			host += ":80"
+		}
+		if proxy.User != nil {
+			auth := proxy.User.Username()
+			if password, ok := proxy.User.Password(); ok {
+				auth += ":" + password
+			}
+			host = auth + "@" + host
+		}
+		env = append(env, fmt.Sprintf("RSYNC_PROXY=%s", host))
+	}
+
+	// Don't use the local timezone, use UTC
+	env = append(env, "TZ=UTC")
+
+	cmd := exec.Command("rsync", append(args, u.String())...)
+
+	// Setup the environnement
+	cmd.Env = env
+
+	return cmd, nil
+}
+
+// rsyncError converts the exit status of rsync into a meaningful error
+func rsyncError(err error) error {
+	switch err.Error() {
+	case "exit status 5":
+		return errors.New("rsync: Error starting client-server protocol")
+	case "exit status 10":
+		return errors.New("rsync: Error in socket I/O")
+	case "exit status 11":
+		return errors.New("rsync: Error in file I/O")
+	case "exit status 23":
+		return errors.New("rsync: Partial transfer due to error")
+	case "exit status 30":
+		return errors.New("rsync: Timeout in data send/receive")
+	case "exit status 35":
+		return errors.New("Timeout waiting for daemon connection")
+	}
+	return errors.New("rsync: " + err.Error())
+}
+
 func readln(r *bufio.Reader) (string, error) {
 	var (
 		isPrefix = true
@@ -0,0 +1,72 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package scan
+
+import (
+	"context"
+	"sync"
+)
+
+var running = &runningScans{
+	scans: make(map[int]*runningScan),
+}
+
+// runningScans keeps the scans in progress in this process so they can be
+// aborted, for instance when their mirror is removed
+type runningScans struct {
+	sync.Mutex
+	scans map[int]*runningScan
+}
+
+type runningScan struct {
+	cancel context.CancelFunc
+}
+
+// add registers the scan of the given mirror and returns the function
+// unregistering it
+func (r *runningScans) add(id int, cancel context.CancelFunc) func() {
+	scan := &runningScan{
+		cancel: cancel,
+	}
+
+	r.Lock()
+	r.scans[id] = scan
+	r.Unlock()
+
+	return func() {
+		r.Lock()
+		if r.scans[id] == scan {
+			delete(r.scans, id)
+		}
+		r.Unlock()
+	}
+}
+
+// cancel aborts the scan of the given mirror, returning false if none is
+// in progress
+func (r *runningScans) cancel(id int) bool {
+	r.Lock()
+	scan, ok := r.scans[id]
+	r.Unlock()
+
+	if ok {
+		scan.cancel()
+	}
+	return ok
+}
+
+// Cancel aborts the scan of the given mirror in progress in this process,
+// returning false if there is none
+func Cancel(id int) bool {
+	return running.cancel(id)
+}
+
+// abortError returns the error matching the reason why the context of a
+// scan is done
+func abortError(ctx context.Context) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return ErrScanTimeout
+	}
+	return ErrScanAborted
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package scan
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCancel(t *testing.T) {
+	if Cancel(1) {
+		t.Fatalf("Expected no scan in progress")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	remove := running.add(1, cancel)
+
+	if !Cancel(1) {
+		t.Fatalf("Expected the scan to be aborted")
+	}
+	if ctx.Err() == nil {
+		t.Fatalf("Expected the context of the scan to be canceled")
+	}
+	if err := abortError(ctx); err != ErrScanAborted {
+		t.Fatalf("Expected ErrScanAborted, got %v", err)
+	}
+
+	// The removal of a previous scan must not forget the next one
+	_, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	remove2 := running.add(1, cancel2)
+	remove()
+	if !Cancel(1) {
+		t.Fatalf("Expected the second scan to be registered")
+	}
+	remove2()
+	if Cancel(1) {
+		t.Fatalf("Expected no scan in progress")
+	}
+}
+
+func TestAbortError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	if err := abortError(ctx); err != ErrScanTimeout {
+		t.Fatalf("Expected ErrScanTimeout, got %v", err)
+	}
+}
@@ -4,11 +4,16 @@
 package scan
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	. "github.com/etix/mirrorbits/config"
@@ -17,25 +22,40 @@ import (
 	"github.com/etix/mirrorbits/filesystem"
 	"github.com/etix/mirrorbits/mirrors"
 	"github.com/etix/mirrorbits/network"
+	"github.com/etix/mirrorbits/objectstorage"
 	"github.com/etix/mirrorbits/utils"
 	"github.com/gomodule/redigo/redis"
 	"github.com/op/go-logging"
 )
 
+const (
+	// Number of files sent to the database at once during a scan, bounding
+	// the replies kept in memory for the mirrors carrying millions of files
+	scanBatchSize = 10000
+)
+
 var (
 	// ErrScanAborted is returned when a scan is aborted by the user
 	ErrScanAborted = errors.New("scan aborted")
+	// ErrScanTimeout is returned when a scan lasts longer than ScanTimeout
+	ErrScanTimeout = errors.New("scan timed out")
 	// ErrScanInProgress is returned when a scan is started while another is already in progress
 	ErrScanInProgress = errors.New("scan already in progress")
 	// ErrNoSyncMethod is returned when no sync protocol is available
 	ErrNoSyncMethod = errors.New("no suitable URL for the scan")
-
-	log = logging.MustGetLogger("main")
+	// ErrScanQuarantined is returned when the result of a scan lost too many files
+	ErrScanQuarantined = errors.New("scan quarantined: too many files lost since the previous scan (use 'scan -force' to accept it)")
+	// ErrNoChannel is returned when none of the channels of the mirror is configured
+	ErrNoChannel = errors.New("none of the channels of the mirror has a directory")
+	// ErrProxyUnsupported is returned when the scan method cannot go through the proxy of the mirror
+	ErrProxyUnsupported = errors.New("scan method unsupported through the proxy")
+
+	log = logging.MustGetLogger("scan")
 )
 
 // Scanner is the interface that all scanners must implement
 type Scanner interface {
-	Scan(url, identifier string, conn redis.Conn, stop <-chan struct{}) (core.Precision, error)
+	Scan(ctx context.Context, url, identifier string, conn redis.Conn) (core.Precision, error)
 }
 
 type filedata struct {
@@ -51,10 +71,25 @@ type scan struct {
 	redis *database.Redis
 	cache *mirrors.Cache
 
-	conn        redis.Conn
-	mirrorid    int
-	filesTmpKey string
-	count       int64
+	conn          redis.Conn
+	mirrorid      int
+	filesTmpKey   string
+	quarantineKey string
+	count         int64
+
+	// The number of commands sent since the last flush and the first error
+	// returned by the database
+	pending int
+	err     error
+
+	// The directories to scan, nil for the whole repository
+	prefixes []string
+	// The rules mapping the paths of the mirror to the repository
+	rewrites mirrors.PathRewrites
+	// The directories of the mirror being synced (see SyncMarkers)
+	syncing map[string]bool
+	// The proxy reaching the mirror, nil for a direct connection
+	proxy *url.URL
 }
 
 type ScanResult struct {
@@ -71,8 +106,21 @@ func IsScanning(conn redis.Conn, id int) (bool, error) {
 	return redis.Bool(conn.Do("EXISTS", fmt.Sprintf("SCANNING_%d", id)))
 }
 
-// Scan starts a scan of the given mirror
-func Scan(typ core.ScannerType, r *database.Redis, c *mirrors.Cache, url string, id int, stop <-chan struct{}) (*ScanResult, error) {
+// Scan starts a scan of the given mirror. Unless force is set, the result
+// is held in quarantine if the mirror lost too many files since the previous
+// scan. A forced scan accepts the result held in quarantine, if any, instead
+// of scanning the mirror again.
+// The scan is aborted when ctx is done, when it lasts longer than
+// ScanTimeout or when Cancel is called for the mirror.
+func Scan(ctx context.Context, typ core.ScannerType, r *database.Redis, c *mirrors.Cache, url string, id int, force bool) (*ScanResult, error) {
+	if timeout := GetConfig().ScanTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Minute)
+		defer cancel()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Connect to the database
 	conn := r.Get()
 	defer conn.Close()
@@ -94,6 +142,10 @@ func Scan(typ core.ScannerType, r *database.Redis, c *mirrors.Cache, url string,
 		scanner = &FTPScanner{
 			scan: s,
 		}
+	case core.MANIFEST:
+		scanner = &ManifestScanner{
+			scan: s,
+		}
 	default:
 		panic(fmt.Sprintf("Unknown scanner"))
 	}
@@ -104,6 +156,20 @@ func Scan(typ core.ScannerType, r *database.Redis, c *mirrors.Cache, url string,
 		return nil, err
 	}
 
+	// Only scan the directories of the channels carried by the mirror
+	mirror, err := c.GetMirror(id)
+	if err != nil {
+		return nil, err
+	}
+	s.rewrites = mirror.PathRewrites
+	if s.proxy, err = mirror.ProxyURL(); err != nil {
+		return nil, err
+	}
+	s.prefixes = mirror.Channels.Prefixes()
+	if s.prefixes != nil && len(s.prefixes) == 0 {
+		return nil, ErrNoChannel
+	}
+
 	// Try to acquire a lock so we don't have a scanning race
 	// from different nodes.
 	// Also make the key expire automatically in case our process
@@ -119,6 +185,26 @@ func Scan(typ core.ScannerType, r *database.Redis, c *mirrors.Cache, url string,
 
 	defer lock.Release()
 
+	defer running.add(id, cancel)()
+
+	filesKey := fmt.Sprintf("MIRRORFILES_%d", id)
+	s.filesTmpKey = fmt.Sprintf("MIRRORFILESTMP_%d", id)
+	s.quarantineKey = fmt.Sprintf("MIRRORFILESQUARANTINE_%d", id)
+
+	// Remove any left over
+	if _, err = conn.Do("DEL", s.filesTmpKey); err != nil {
+		return nil, err
+	}
+
+	var precision core.Precision
+	var accepted bool
+	if force {
+		accepted, err = s.acceptQuarantine(name, &typ, &precision)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	s.setLastSync(conn, id, typ, 0, false)
 
 	mirrors.PushLog(r, mirrors.NewLogScanStarted(id, typ))
@@ -128,18 +214,33 @@ func Scan(typ core.ScannerType, r *database.Redis, c *mirrors.Cache, url string,
 		}
 	}(&err)
 
-	conn.Send("MULTI")
-
-	filesKey := fmt.Sprintf("MIRRORFILES_%d", id)
-	s.filesTmpKey = fmt.Sprintf("MIRRORFILESTMP_%d", id)
-
-	// Remove any left over
-	conn.Send("DEL", s.filesTmpKey)
+	summary := &ScanSummary{
+		Timestamp: time.Now(),
+		Method:    scannerName(typ),
+		Accepted:  accepted,
+	}
+	defer func(err *error) {
+		summary.Duration = time.Since(summary.Timestamp)
+		if err != nil && *err != nil {
+			summary.Error = (*err).Error()
+		}
+		pushScanSummary(r, id, summary)
+	}(&err)
 
-	var precision core.Precision
-	precision, err = scanner.Scan(url, name, conn, stop)
+	// The files found on the mirror are sent by batches as the listing is
+	// parsed. The files carried by the mirror are then known before the
+	// end of a successful scan, the removal of the missing files being the
+	// only step applied at the end.
+	if !accepted {
+		precision, err = scanner.Scan(ctx, url, name, conn)
+		if err == nil {
+			err = s.ScannerCommit()
+		}
+	}
+	if err == nil && ctx.Err() != nil {
+		err = abortError(ctx)
+	}
 	if err != nil {
-		// Discard MULTI
 		s.ScannerDiscard()
 
 		// Remove the temporary key
@@ -149,31 +250,30 @@ func Scan(typ core.ScannerType, r *database.Redis, c *mirrors.Cache, url string,
 		return nil, err
 	}
 
-	// Exec multi
-	s.ScannerCommit()
-
-	// Get the list of files no more present on this mirror
-	var toremove []interface{}
-	toremove, err = redis.Values(conn.Do("SDIFF", filesKey, s.filesTmpKey))
+	// Count the files previously known on this mirror
+	var previous int64
+	previous, err = redis.Int64(conn.Do("SCARD", filesKey))
 	if err != nil {
 		return nil, err
 	}
 
-	// Remove this mirror from the given file SET
-	if len(toremove) > 0 {
-		conn.Send("MULTI")
-		for _, e := range toremove {
-			log.Debugf("[%s] Removing %s from mirror", name, e)
-			conn.Send("SREM", fmt.Sprintf("FILEMIRRORS_%s", e), id)
-			conn.Send("DEL", fmt.Sprintf("FILEINFO_%d_%s", id, e))
-			// Publish update
-			database.SendPublish(conn, database.MIRROR_FILE_UPDATE, fmt.Sprintf("%d %s", id, e))
-
-		}
-		_, err = conn.Do("EXEC")
-		if err != nil {
-			return nil, err
+	// Refuse to apply a massive loss of files unless forced to
+	if err = s.checkQuarantine(name, typ, precision, previous, force); err != nil {
+		if err == ErrScanQuarantined {
+			summary.Quarantined = true
+			summary.FilesIndexed = s.count
 		}
+		return nil, err
+	}
+
+	// Remove this mirror from the files no more present on it
+	var removed int64
+	removed, err = s.removeMissingFiles(name, filesKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.syncing) > 0 {
+		log.Noticef("[%s] Sync in progress in %d directory(ies), the missing files there are kept", name, len(s.syncing))
 	}
 
 	// Finally rename the temporary sets containing the list
@@ -194,6 +294,11 @@ func Scan(typ core.ScannerType, r *database.Redis, c *mirrors.Cache, url string,
 		return nil, err
 	}
 
+	// The result held in quarantine, if any, is superseded
+	if err = s.clearQuarantine(); err != nil {
+		return nil, err
+	}
+
 	s.setLastSync(conn, id, typ, precision, true)
 
 	var tzoffset int64
@@ -202,16 +307,18 @@ func Scan(typ core.ScannerType, r *database.Redis, c *mirrors.Cache, url string,
 		log.Warningf("Unable to check timezone shifts: %s", err)
 	}
 
-	log.Infof("[%s] Indexed %d files (%d known), %d removed", name, s.count, common, len(toremove))
+	log.Infof("[%s] Indexed %d files (%d known), %d removed", name, s.count, common, removed)
 	res := &ScanResult{
 		MirrorID:     id,
 		MirrorName:   name,
 		FilesIndexed: s.count,
 		KnownIndexed: common,
-		Removed:      int64(len(toremove)),
+		Removed:      removed,
 		TZOffsetMs:   tzoffset,
 	}
 
+	summary.setResult(res, previous)
+
 	mirrors.PushLog(r, mirrors.NewLogScanCompleted(
 		res.MirrorID,
 		res.FilesIndexed,
@@ -222,7 +329,126 @@ func Scan(typ core.ScannerType, r *database.Redis, c *mirrors.Cache, url string,
 	return res, nil
 }
 
+// checkQuarantine holds the result of the scan in quarantine and returns
+// ErrScanQuarantined if the mirror lost too many of the previous files it
+// had, unless force is set. The mirror keeps being served with its previous
+// files until the result is accepted by a forced scan.
+func (s *scan) checkQuarantine(name string, typ core.ScannerType, precision core.Precision, previous int64, force bool) error {
+	if force || !isQuarantined(previous, s.count) {
+		return nil
+	}
+
+	s.conn.Send("MULTI")
+	s.conn.Send("DEL", s.quarantineKey)
+	if s.count > 0 {
+		s.conn.Send("RENAME", s.filesTmpKey, s.quarantineKey)
+	}
+	s.conn.Send("HMSET", fmt.Sprintf("MIRROR_%d", s.mirrorid),
+		"quarantinedSince", time.Now().UTC().Unix(),
+		"quarantinedFiles", s.count,
+		"quarantinedPrevious", previous,
+		"quarantinedProtocol", typ,
+		"quarantinedPrecision", precision)
+	if _, err := s.conn.Do("EXEC"); err != nil {
+		return err
+	}
+	database.Publish(s.conn, database.MIRROR_UPDATE, strconv.Itoa(s.mirrorid))
+
+	log.Errorf("[%s] Scan quarantined: %d files found instead of %d", name, s.count, previous)
+	return ErrScanQuarantined
+}
+
+// acceptQuarantine copies the result held in quarantine, if any, to the
+// temporary set of the scan along with the protocol and the precision of the
+// scan having produced it. It returns false if there is no such result.
+func (s *scan) acceptQuarantine(name string, typ *core.ScannerType, precision *core.Precision) (bool, error) {
+	values, err := redis.Values(s.conn.Do("HMGET", fmt.Sprintf("MIRROR_%d", s.mirrorid),
+		"quarantinedSince", "quarantinedProtocol", "quarantinedPrecision"))
+	if err != nil {
+		return false, err
+	}
+	var since int64
+	var heldTyp core.ScannerType
+	var heldPrecision core.Precision
+	if _, err = redis.Scan(values, &since, &heldTyp, &heldPrecision); err != nil {
+		return false, err
+	}
+	if since == 0 {
+		return false, nil
+	}
+
+	// The result stays held until the end of a successful scan
+	s.count, err = redis.Int64(s.conn.Do("SUNIONSTORE", s.filesTmpKey, s.quarantineKey))
+	if err != nil {
+		return false, err
+	}
+	*typ, *precision = heldTyp, heldPrecision
+
+	log.Noticef("[%s] Accepting the scan held in quarantine since %s (%d files)", name, time.Unix(since, 0).Format(time.RFC3339), s.count)
+	return true, nil
+}
+
+// clearQuarantine drops the result held in quarantine, if any
+func (s *scan) clearQuarantine() error {
+	s.conn.Send("MULTI")
+	s.conn.Send("DEL", s.quarantineKey)
+	s.conn.Send("HDEL", fmt.Sprintf("MIRROR_%d", s.mirrorid),
+		"quarantinedSince",
+		"quarantinedFiles",
+		"quarantinedPrevious",
+		"quarantinedProtocol",
+		"quarantinedPrecision")
+	_, err := s.conn.Do("EXEC")
+	return err
+}
+
+// isQuarantined returns true if the scan finding count files on a mirror
+// which had previous files lost at least ScanQuarantineThreshold percent of
+// them
+func isQuarantined(previous, count int64) bool {
+	threshold := int64(GetConfig().ScanQuarantineThreshold)
+	if threshold <= 0 || previous <= 0 {
+		return false
+	}
+	return (previous-count)*100 >= previous*threshold
+}
+
+// mirrorPrefixes returns the directories to scan on the mirror, nil for
+// the whole tree
+func (s *scan) mirrorPrefixes() []string {
+	if s.prefixes == nil {
+		return nil
+	}
+	prefixes := make([]string, 0, len(s.prefixes))
+	for _, p := range s.prefixes {
+		prefixes = append(prefixes, s.rewrites.ToMirror(p))
+	}
+	return prefixes
+}
+
 func (s *scan) ScannerAddFile(f filedata) {
+	// Use the path of the file in the repository
+	f.path = s.rewrites.FromMirror(f.path)
+	s.addFile(f)
+}
+
+// addFile records a file found on the mirror, given its path in the
+// repository
+func (s *scan) addFile(f filedata) {
+	// The in-progress sync artifacts are not files of the repository
+	if dir, ok := syncingDirectory(f.path); ok {
+		if s.syncing == nil {
+			s.syncing = make(map[string]bool)
+		}
+		s.syncing[dir] = true
+		return
+	}
+
+	// Ignore the files outside of the channels of the mirror
+	if s.prefixes != nil && !mirrors.HasPathPrefix(f.path, s.prefixes) {
+		return
+	}
+
 	s.count++
 
 	// Add all the files to a temporary key
@@ -233,20 +459,136 @@ func (s *scan) ScannerAddFile(f filedata) {
 	s.conn.Send("SADD", rk, s.mirrorid)
 
 	// Save the size of the current file found on this mirror
-	ik := fmt.Sprintf("FILEINFO_%d_%s", s.mirrorid, f.path)
-	s.conn.Send("HMSET", ik, "size", f.size, "modTime", f.modTime)
+	fi := filesystem.FileInfo{Size: f.size, ModTime: f.modTime}
+	s.conn.Send("HSET", fmt.Sprintf("FILEINFOS_%d", s.mirrorid), f.path, fi.Pack())
 
 	// Publish update
 	database.SendPublish(s.conn, database.MIRROR_FILE_UPDATE, fmt.Sprintf("%d %s", s.mirrorid, f.path))
+
+	s.pending++
+	if s.pending >= scanBatchSize {
+		s.flush()
+	}
+}
+
+// flush sends the pending commands to the database and checks their
+// replies, keeping the first error
+func (s *scan) flush() error {
+	if s.pending == 0 {
+		return s.err
+	}
+	s.pending = 0
+	replies, err := redis.Values(s.conn.Do(""))
+	if err == nil {
+		for _, reply := range replies {
+			if e, ok := reply.(redis.Error); ok {
+				err = e
+				break
+			}
+		}
+	}
+	if s.err == nil {
+		s.err = err
+	}
+	return s.err
 }
 
+// ScannerDiscard drops the replies of the pending commands of a failed scan
 func (s *scan) ScannerDiscard() {
-	s.conn.Do("DISCARD")
+	if s.pending > 0 {
+		s.pending = 0
+		s.conn.Do("")
+	}
 }
 
+// ScannerCommit sends the last batch of files found on the mirror
 func (s *scan) ScannerCommit() error {
-	_, err := s.conn.Do("EXEC")
-	return err
+	return s.flush()
+}
+
+// removeMissingFiles removes the mirror from the files it had before the
+// scan and no longer has, returning their number. The files are compared by
+// batches to bound the memory used by the mirrors carrying millions of files.
+func (s *scan) removeMissingFiles(name, filesKey string) (int64, error) {
+	var removed int64
+	cursor := 0
+	for {
+		values, err := redis.Values(s.conn.Do("SSCAN", filesKey, cursor, "COUNT", scanBatchSize))
+		if err != nil {
+			return removed, err
+		}
+		var files []string
+		if _, err = redis.Scan(values, &cursor, &files); err != nil {
+			return removed, err
+		}
+
+		if len(files) > 0 {
+			for _, f := range files {
+				s.conn.Send("SISMEMBER", s.filesTmpKey, f)
+			}
+			present, err := redis.Ints(s.conn.Do(""))
+			if err != nil {
+				return removed, err
+			}
+
+			for i, f := range files {
+				if present[i] == 1 {
+					continue
+				}
+				if s.isSyncing(f) {
+					// Judged once the sync of its directory is complete
+					log.Debugf("[%s] Keeping %s until the end of the sync", name, f)
+					s.conn.Send("SADD", s.filesTmpKey, f)
+					s.pending++
+					s.count++
+					continue
+				}
+				log.Debugf("[%s] Removing %s from mirror", name, f)
+				s.conn.Send("SREM", fmt.Sprintf("FILEMIRRORS_%s", f), s.mirrorid)
+				s.conn.Send("HDEL", fmt.Sprintf("FILEINFOS_%d", s.mirrorid), f)
+				// Publish update
+				database.SendPublish(s.conn, database.MIRROR_FILE_UPDATE, fmt.Sprintf("%d %s", s.mirrorid, f))
+				s.pending++
+				removed++
+			}
+			if err := s.flush(); err != nil {
+				return removed, err
+			}
+		}
+
+		if cursor == 0 {
+			return removed, nil
+		}
+	}
+}
+
+// isSyncing returns true if the file lies in a directory of the mirror being
+// synced
+func (s *scan) isSyncing(file string) bool {
+	for dir := range s.syncing {
+		if strings.HasPrefix(file, strings.TrimSuffix(dir, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// syncingDirectory returns the directory being synced if the given path is
+// an in-progress sync marker or lies in one (see SyncMarkers)
+func syncingDirectory(p string) (string, bool) {
+	markers := GetConfig().SyncMarkers
+	if len(markers) == 0 {
+		return "", false
+	}
+	parts := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	for i, part := range parts {
+		for _, marker := range markers {
+			if ok, _ := path.Match(marker, part); ok {
+				return "/" + strings.Join(parts[:i], "/"), true
+			}
+		}
+	}
+	return "", false
 }
 
 func (s *scan) setLastSync(conn redis.Conn, id int, protocol core.ScannerType, precision core.Precision, successful bool) error {
@@ -399,10 +741,22 @@ func (s *sourcescanner) walkSource(conn redis.Conn, path string, f os.FileInfo,
 	d.size = f.Size()
 	d.modTime = f.ModTime()
 
+	err = s.sourceHashes(conn, d, rehash, func() (filesystem.FileInfo, error) {
+		return filesystem.HashFile(GetConfig().Repository + d.path)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// sourceHashes sets the hashes of the given file, those of the index being
+// reused unless the file changed or a rehash is requested
+func (s *sourcescanner) sourceHashes(conn redis.Conn, d *filedata, rehash bool, hash func() (filesystem.FileInfo, error)) error {
 	// Get the previous file properties
 	properties, err := redis.Strings(conn.Do("HMGET", fmt.Sprintf("FILE_%s", d.path), "size", "modTime", "sha1", "sha256", "md5"))
 	if err != nil && err != redis.ErrNil {
-		return nil, err
+		return err
 	} else if len(properties) < 5 {
 		// This will force a rehash
 		properties = make([]string, 5)
@@ -420,7 +774,7 @@ func (s *sourcescanner) walkSource(conn redis.Conn, path string, f os.FileInfo,
 		(GetConfig().Hashes.MD5 && len(md5) == 0)
 
 	if rehash || size != d.size || !modTime.Equal(d.modTime) {
-		h, err := filesystem.HashFile(GetConfig().Repository + d.path)
+		h, err := hash()
 		if err != nil {
 			log.Warningf("%s: hashing failed: %s", d.path, err.Error())
 		} else {
@@ -443,11 +797,198 @@ func (s *sourcescanner) walkSource(conn redis.Conn, path string, f os.FileInfo,
 		d.md5 = md5
 	}
 
-	return d, nil
+	return nil
+}
+
+// walkBucket indexes the objects of the bucket holding the repository
+func (s *sourcescanner) walkBucket(ctx context.Context, conn redis.Conn, bucket *objectstorage.Bucket, rehash bool, sourceFiles *[]*filedata) error {
+	return bucket.List(ctx, func(obj objectstorage.Object) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		d := &filedata{
+			path:    bucket.Path(obj.Key),
+			size:    obj.Size,
+			modTime: obj.LastModified,
+		}
+		if d.path == "" {
+			return nil
+		}
+		err := s.sourceHashes(conn, d, rehash, func() (filesystem.FileInfo, error) {
+			return hashObject(ctx, bucket, obj)
+		})
+		if err != nil {
+			return err
+		}
+		*sourceFiles = append(*sourceFiles, d)
+		return nil
+	})
+}
+
+// hashObject returns the hashes of an object, the object being downloaded
+// only if the checksums stored along with it are not enough
+func hashObject(ctx context.Context, bucket *objectstorage.Bucket, obj objectstorage.Object) (h filesystem.FileInfo, err error) {
+	header, err := bucket.Head(ctx, obj.Key)
+	if err != nil {
+		return h, err
+	}
+	h.Sha1, h.Sha256, h.Md5 = objectstorage.Checksums(obj, header)
+	if !GetConfig().Hashes.SHA1 {
+		h.Sha1 = ""
+	}
+	if !GetConfig().Hashes.SHA256 {
+		h.Sha256 = ""
+	}
+	if !GetConfig().Hashes.MD5 {
+		h.Md5 = ""
+	}
+	if (h.Sha1 != "" || !GetConfig().Hashes.SHA1) &&
+		(h.Sha256 != "" || !GetConfig().Hashes.SHA256) &&
+		(h.Md5 != "" || !GetConfig().Hashes.MD5) {
+		return h, nil
+	}
+
+	resp, err := bucket.Get(ctx, obj.Key, nil)
+	if err != nil {
+		return h, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return h, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return filesystem.HashReader(resp.Body)
+}
+
+// detectRenames returns the removed files for which a file with the same
+// content appeared elsewhere in the repository, along with their new path
+func (s *sourcescanner) detectRenames(conn redis.Conn, sourceFiles []*filedata, added []string, removed []interface{}) (map[string]string, error) {
+	aliases := make(map[string]string)
+
+	if len(added) == 0 || len(removed) == 0 || len(GetConfig().RenameRedirects) == 0 {
+		return aliases, nil
+	}
+
+	fingerprint := func(size int64, sha1, sha256, md5 string) string {
+		if sha256 != "" {
+			return fmt.Sprintf("%d-sha256-%s", size, sha256)
+		} else if sha1 != "" {
+			return fmt.Sprintf("%d-sha1-%s", size, sha1)
+		} else if md5 != "" {
+			return fmt.Sprintf("%d-md5-%s", size, md5)
+		}
+		return ""
+	}
+
+	isNew := make(map[string]bool, len(added))
+	for _, e := range added {
+		isNew[e] = true
+	}
+
+	// Index the new files by content, ignoring the ambiguous ones
+	candidates := make(map[string]string)
+	for _, e := range sourceFiles {
+		if !isNew[e.path] {
+			continue
+		}
+		fp := fingerprint(e.size, e.sha1, e.sha256, e.md5)
+		if fp == "" {
+			continue
+		}
+		if _, ok := candidates[fp]; ok {
+			candidates[fp] = ""
+			continue
+		}
+		candidates[fp] = e.path
+	}
+
+	for _, e := range removed {
+		from := fmt.Sprintf("%s", e)
+		if GetConfig().RenameGracePeriod(from) <= 0 {
+			continue
+		}
+
+		properties, err := redis.Strings(conn.Do("HMGET", fmt.Sprintf("FILE_%s", from), "size", "sha1", "sha256", "md5"))
+		if err != nil {
+			return nil, err
+		}
+
+		size, _ := strconv.ParseInt(properties[0], 10, 64)
+		if to := candidates[fingerprint(size, properties[1], properties[2], properties[3])]; to != "" {
+			log.Infof("[source] %s moved to %s", from, to)
+			aliases[from] = to
+		}
+	}
+
+	return aliases, nil
+}
+
+// walk indexes the files found in root as if they were located in dir,
+// following the symlinks if requested by the configuration
+func (s *sourcescanner) walk(conn redis.Conn, root, dir string, rehash bool, visited map[string]bool, sourceFiles *[]*filedata) error {
+	return filepath.Walk(root, func(path string, f os.FileInfo, err error) error {
+		path = dir + path[len(root):]
+
+		if f != nil && f.Mode()&os.ModeSymlink != 0 && GetConfig().SymlinkPolicy == SymlinkFollow {
+			target, err := filepath.EvalSymlinks(path)
+			if err != nil || !filesystem.IsInRepository(GetConfig().Repository, target) || visited[target] {
+				// Skip broken links, links leaving the repository and loops
+				return nil
+			}
+			if parent, err := filepath.EvalSymlinks(filepath.Dir(path)); err != nil || filesystem.IsInRepository(target, parent) {
+				// Skip links pointing to one of their parents
+				return nil
+			}
+			f, err = os.Stat(target)
+			if err != nil {
+				return nil
+			}
+			if f.IsDir() {
+				visited[target] = true
+				defer delete(visited, target)
+				return s.walk(conn, target, path, rehash, visited, sourceFiles)
+			}
+		}
+
+		fd, err := s.walkSource(conn, path, f, rehash, err)
+		if err != nil {
+			return err
+		}
+		if fd != nil {
+			*sourceFiles = append(*sourceFiles, fd)
+		}
+		return nil
+	})
+}
+
+// lookupIndex maps the lookup keys (see utils.LookupKey) to the files and to
+// the directories (with a trailing slash) of the repository. The keys shared
+// by several paths are left out since they are ambiguous.
+func lookupIndex(files []*filedata) map[string]string {
+	index := make(map[string]string)
+	ambiguous := make(map[string]bool)
+	add := func(p string) {
+		key := utils.LookupKey(p)
+		if key == "" || ambiguous[key] {
+			return
+		}
+		if existing, ok := index[key]; ok && existing != p {
+			delete(index, key)
+			ambiguous[key] = true
+			return
+		}
+		index[key] = p
+	}
+	for _, f := range files {
+		add(f.path)
+		for dir := path.Dir(f.path); dir != "/" && dir != "."; dir = path.Dir(dir) {
+			add(dir + "/")
+		}
+	}
+	return index
 }
 
 // ScanSource starts a scan of the local repository
-func ScanSource(r *database.Redis, forceRehash bool, stop <-chan struct{}) (err error) {
+func ScanSource(ctx context.Context, r *database.Redis, forceRehash bool) (err error) {
 	s := &sourcescanner{}
 
 	conn := r.Get()
@@ -461,23 +1002,29 @@ func ScanSource(r *database.Redis, forceRehash bool, stop <-chan struct{}) (err
 
 	//TODO lock atomically inside redis to avoid two simultaneous scan
 
-	if _, err := os.Stat(GetConfig().Repository); os.IsNotExist(err) {
-		return fmt.Errorf("%s: No such file or directory", GetConfig().Repository)
-	}
-
-	log.Info("[source] Scanning the filesystem...")
-	err = filepath.Walk(GetConfig().Repository, func(path string, f os.FileInfo, err error) error {
-		fd, err := s.walkSource(conn, path, f, forceRehash, err)
+	if GetConfig().ObjectStorage.Enabled() {
+		bucket, err := objectstorage.NewBucket()
 		if err != nil {
 			return err
 		}
-		if fd != nil {
-			sourceFiles = append(sourceFiles, fd)
+		log.Info("[source] Listing the bucket...")
+		err = s.walkBucket(ctx, conn, bucket, forceRehash, &sourceFiles)
+		if ctx.Err() != nil {
+			return ErrScanAborted
 		}
-		return nil
-	})
+		if err != nil {
+			return err
+		}
+	} else {
+		if _, err := os.Stat(GetConfig().Repository); os.IsNotExist(err) {
+			return fmt.Errorf("%s: No such file or directory", GetConfig().Repository)
+		}
+
+		log.Info("[source] Scanning the filesystem...")
+		err = s.walk(conn, GetConfig().Repository, GetConfig().Repository, forceRehash, make(map[string]bool), &sourceFiles)
+	}
 
-	if utils.IsStopped(stop) {
+	if ctx.Err() != nil {
 		return ErrScanAborted
 	}
 	if err != nil {
@@ -516,6 +1063,14 @@ func ScanSource(r *database.Redis, forceRehash bool, stop <-chan struct{}) (err
 		count++
 	}
 
+	// Index the files and directories by their lookup key
+	conn.Send("DEL", "FILES_LOOKUP_TMP")
+	if GetConfig().TolerantLookups {
+		for key, p := range lookupIndex(sourceFiles) {
+			conn.Send("HSET", "FILES_LOOKUP_TMP", key, p)
+		}
+	}
+
 	_, err = conn.Do("EXEC")
 	if err != nil {
 		return err
@@ -524,6 +1079,18 @@ func ScanSource(r *database.Redis, forceRehash bool, stop <-chan struct{}) (err
 	// Do a diff between the sets to get the removed files
 	toremove, err := redis.Values(conn.Do("SDIFF", "FILES", "FILES_TMP"))
 
+	// Do a diff between the sets to get the new files
+	added, err := redis.Strings(conn.Do("SDIFF", "FILES_TMP", "FILES"))
+	if err != nil {
+		return err
+	}
+
+	// Find the files that were moved elsewhere
+	aliases, err := s.detectRenames(conn, sourceFiles, added, toremove)
+	if err != nil {
+		return err
+	}
+
 	// Create/Update the files' hash keys with the fresh infos
 	conn.Send("MULTI")
 	for _, e := range sourceFiles {
@@ -538,6 +1105,18 @@ func ScanSource(r *database.Redis, forceRehash bool, stop <-chan struct{}) (err
 		database.SendPublish(conn, database.FILE_UPDATE, e.path)
 	}
 
+	// A file is back in place, drop its alias and its tombstone
+	for _, e := range added {
+		conn.Send("DEL", fmt.Sprintf("FILEALIAS_%s", e))
+		conn.Send("DEL", fmt.Sprintf("FILETOMBSTONE_%s", e))
+	}
+
+	// Redirect the renamed files to their new location
+	for from, to := range aliases {
+		grace := GetConfig().RenameGracePeriod(from)
+		conn.Send("SET", fmt.Sprintf("FILEALIAS_%s", from), to, "EX", int64(grace.Seconds()))
+	}
+
 	// Remove old keys
 	if len(toremove) > 0 {
 		for _, e := range toremove {
@@ -545,12 +1124,28 @@ func ScanSource(r *database.Redis, forceRehash bool, stop <-chan struct{}) (err
 
 			// Publish update
 			database.SendPublish(conn, database.FILE_UPDATE, fmt.Sprintf("%s", e))
+
+			// Keep track of the removal of the files not moved elsewhere
+			if _, moved := aliases[fmt.Sprintf("%s", e)]; moved {
+				continue
+			}
+			if period, _ := GetConfig().Tombstone(fmt.Sprintf("%s", e)); period > 0 {
+				conn.Send("SET", fmt.Sprintf("FILETOMBSTONE_%s", e), time.Now().Unix(), "EX", int64(period.Seconds()))
+			}
 		}
 	}
 
 	// Finally rename the temporary sets containing the list
 	// of files to the production key
 	conn.Send("RENAME", "FILES_TMP", "FILES")
+	if GetConfig().TolerantLookups {
+		conn.Send("RENAME", "FILES_LOOKUP_TMP", "FILES_LOOKUP")
+	} else {
+		conn.Send("DEL", "FILES_LOOKUP")
+	}
+
+	// Keep track of the last successful scan
+	conn.Send("SET", "LAST_SOURCE_SCAN", time.Now().Unix())
 
 	_, err = conn.Do("EXEC")
 	if err != nil {
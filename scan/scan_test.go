@@ -0,0 +1,280 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package scan
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/core"
+	. "github.com/etix/mirrorbits/testing"
+	"github.com/gomodule/redigo/redis"
+)
+
+func TestLookupIndex(t *testing.T) {
+	files := []*filedata{
+		{path: "/Releases/1.0/Project-1.0.ISO"},
+		{path: "/Releases/1.0/README"},
+		{path: "/docs/readme"},
+		{path: "/docs/README"},
+	}
+	index := lookupIndex(files)
+
+	expected := map[string]string{
+		"/releases/1.0/project-1.0.iso": "/Releases/1.0/Project-1.0.ISO",
+		"/releases/1.0/readme":          "/Releases/1.0/README",
+		"/releases/1.0":                 "/Releases/1.0/",
+		"/releases":                     "/Releases/",
+		"/docs":                         "/docs/",
+	}
+	if len(index) != len(expected) {
+		t.Fatalf("Expected %d entries, got %v", len(expected), index)
+	}
+	for key, p := range expected {
+		if index[key] != p {
+			t.Fatalf("Expected %s for %s, got %s", p, key, index[key])
+		}
+	}
+	if _, ok := index["/docs/readme"]; ok {
+		t.Fatalf("Ambiguous keys should be left out")
+	}
+}
+
+func TestIsQuarantined(t *testing.T) {
+	c := &Configuration{}
+	c.ScanQuarantineThreshold = 40
+	SetConfiguration(c)
+
+	tests := []struct {
+		previous    int64
+		count       int64
+		quarantined bool
+	}{
+		{1000, 1000, false},
+		{1000, 1200, false},
+		{1000, 601, false},
+		// Exactly 40% of the files lost
+		{1000, 600, true},
+		{1000, 599, true},
+		{1000, 0, true},
+		{3, 2, false},
+		{3, 1, true},
+		// First scan of the mirror
+		{0, 0, false},
+		{0, 10, false},
+	}
+
+	for _, test := range tests {
+		if isQuarantined(test.previous, test.count) != test.quarantined {
+			t.Fatalf("%d files found instead of %d: expected quarantined to be %t", test.count, test.previous, test.quarantined)
+		}
+	}
+
+	// The quarantine is disabled by default
+	c.ScanQuarantineThreshold = 0
+	if isQuarantined(1000, 0) {
+		t.Fatalf("The quarantine is supposed to be disabled")
+	}
+}
+
+func TestScan_checkQuarantine(t *testing.T) {
+	c := &Configuration{}
+	c.ScanQuarantineThreshold = 40
+	SetConfiguration(c)
+
+	mock, conn := PrepareRedisTest()
+	mock.Command("MULTI")
+	cmdDel := mock.Command("DEL", "MIRRORFILESQUARANTINE_1")
+	cmdHold := mock.Command("RENAME", "MIRRORFILESTMP_1", "MIRRORFILESQUARANTINE_1")
+	cmdMark := mock.GenericCommand("HMSET")
+	mock.Command("EXEC").Expect([]interface{}{int64(1), "OK", "OK"})
+	mock.GenericCommand("PUBLISH").Expect(int64(0))
+
+	s := &scan{
+		conn:          conn.Get(),
+		mirrorid:      1,
+		filesTmpKey:   "MIRRORFILESTMP_1",
+		quarantineKey: "MIRRORFILESQUARANTINE_1",
+		count:         500,
+	}
+
+	if err := s.checkQuarantine("m1", core.RSYNC, 0, 1000, true); err != nil {
+		t.Fatalf("A forced scan is not supposed to be quarantined, got %s", err)
+	}
+	if mock.Stats(cmdHold) != 0 {
+		t.Fatalf("The result of a forced scan is not supposed to be held")
+	}
+
+	if err := s.checkQuarantine("m1", core.RSYNC, 0, 1000, false); err != ErrScanQuarantined {
+		t.Fatalf("Expected ErrScanQuarantined, got %v", err)
+	}
+	if mock.Stats(cmdDel) != 1 || mock.Stats(cmdHold) != 1 || mock.Stats(cmdMark) != 1 {
+		t.Fatalf("The result of the scan is supposed to be held in quarantine")
+	}
+
+	s.count = 601
+	if err := s.checkQuarantine("m1", core.RSYNC, 0, 1000, false); err != nil {
+		t.Fatalf("Unexpected quarantine: %s", err)
+	}
+}
+
+func TestScan_acceptQuarantine(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	s := &scan{
+		conn:          conn.Get(),
+		mirrorid:      1,
+		filesTmpKey:   "MIRRORFILESTMP_1",
+		quarantineKey: "MIRRORFILESQUARANTINE_1",
+	}
+
+	mock.Command("HMGET", "MIRROR_1", "quarantinedSince", "quarantinedProtocol", "quarantinedPrecision").
+		Expect([]interface{}{nil, nil, nil}).
+		Expect([]interface{}{[]byte("1556712000"), []byte("1"), []byte("1000000000")})
+	cmdCopy := mock.Command("SUNIONSTORE", "MIRRORFILESTMP_1", "MIRRORFILESQUARANTINE_1").Expect(int64(500))
+
+	typ, precision := core.RSYNC, core.Precision(0)
+	accepted, err := s.acceptQuarantine("m1", &typ, &precision)
+	if err != nil || accepted || mock.Stats(cmdCopy) != 0 {
+		t.Fatalf("Nothing is supposed to be accepted without a result held in quarantine")
+	}
+
+	accepted, err = s.acceptQuarantine("m1", &typ, &precision)
+	if err != nil || !accepted {
+		t.Fatalf("Expected the result held in quarantine to be accepted, got %v", err)
+	}
+	if mock.Stats(cmdCopy) != 1 || s.count != 500 {
+		t.Fatalf("Expected the held files to be copied to the temporary set")
+	}
+	if typ != core.FTP || precision != core.Precision(time.Second) {
+		t.Fatalf("Expected the protocol and the precision of the held scan, got %d and %d", typ, precision)
+	}
+}
+
+func TestScan_removeMissingFiles(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	mock.Command("SSCAN", "MIRRORFILES_1", 0, "COUNT", scanBatchSize).Expect([]interface{}{
+		[]byte("12"),
+		[]interface{}{[]byte("/a"), []byte("/b")},
+	})
+	mock.Command("SSCAN", "MIRRORFILES_1", 12, "COUNT", scanBatchSize).Expect([]interface{}{
+		[]byte("0"),
+		[]interface{}{[]byte("/c")},
+	})
+	mock.Command("SISMEMBER", "MIRRORFILESTMP_1", "/a").Expect(int64(1))
+	mock.Command("SISMEMBER", "MIRRORFILESTMP_1", "/b").Expect(int64(0))
+	mock.Command("SISMEMBER", "MIRRORFILESTMP_1", "/c").Expect(int64(1))
+	cmdRemoveA := mock.Command("SREM", "FILEMIRRORS_/a", 1).Expect(int64(1))
+	cmdRemoveB := mock.Command("SREM", "FILEMIRRORS_/b", 1).Expect(int64(1))
+	cmdInfoB := mock.Command("HDEL", "FILEINFOS_1", "/b").Expect(int64(1))
+	mock.GenericCommand("EVAL").Expect(int64(1))
+	mock.GenericCommand("PUBLISH").Expect(int64(1))
+
+	s := &scan{
+		conn:        conn.Get(),
+		mirrorid:    1,
+		filesTmpKey: "MIRRORFILESTMP_1",
+	}
+
+	removed, err := s.removeMissingFiles("m1", "MIRRORFILES_1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if removed != 1 {
+		t.Fatalf("Expected 1 file removed, got %d", removed)
+	}
+	if mock.Stats(cmdRemoveA) != 0 || mock.Stats(cmdRemoveB) != 1 || mock.Stats(cmdInfoB) != 1 {
+		t.Fatalf("Only the missing file is supposed to be removed")
+	}
+}
+
+func TestSyncingDirectory(t *testing.T) {
+	SetConfiguration(&Configuration{
+		SyncMarkers: []string{".~tmp~", "*.part", ".*.??????"},
+	})
+
+	tests := []struct {
+		path    string
+		dir     string
+		syncing bool
+	}{
+		{"/a/b/file.iso", "", false},
+		{"/a/b/file.iso.part", "/a/b", true},
+		{"/a/b/.~tmp~/file.iso", "/a/b", true},
+		{"/a/.file.iso.Xy12Ab", "/a", true},
+		{"/file.part", "/", true},
+		{"/a/.htaccess", "", false},
+	}
+	for _, test := range tests {
+		dir, syncing := syncingDirectory(test.path)
+		if dir != test.dir || syncing != test.syncing {
+			t.Fatalf("%s: expected %q %t, got %q %t", test.path, test.dir, test.syncing, dir, syncing)
+		}
+	}
+}
+
+func TestScan_removeMissingFiles_syncing(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	mock.Command("SSCAN", "MIRRORFILES_1", 0, "COUNT", scanBatchSize).Expect([]interface{}{
+		[]byte("0"),
+		[]interface{}{[]byte("/a/file"), []byte("/b/file")},
+	})
+	mock.Command("SISMEMBER", "MIRRORFILESTMP_1", "/a/file").Expect(int64(0))
+	mock.Command("SISMEMBER", "MIRRORFILESTMP_1", "/b/file").Expect(int64(0))
+	cmdKeep := mock.Command("SADD", "MIRRORFILESTMP_1", "/a/file").Expect(int64(1))
+	cmdRemoveA := mock.Command("SREM", "FILEMIRRORS_/a/file", 1).Expect(int64(1))
+	cmdRemoveB := mock.Command("SREM", "FILEMIRRORS_/b/file", 1).Expect(int64(1))
+	mock.GenericCommand("HDEL").Expect(int64(1))
+	mock.GenericCommand("EVAL").Expect(int64(1))
+	mock.GenericCommand("PUBLISH").Expect(int64(1))
+
+	s := &scan{
+		conn:        conn.Get(),
+		mirrorid:    1,
+		filesTmpKey: "MIRRORFILESTMP_1",
+		syncing:     map[string]bool{"/a": true},
+	}
+
+	removed, err := s.removeMissingFiles("m1", "MIRRORFILES_1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if removed != 1 || s.count != 1 {
+		t.Fatalf("Expected 1 file removed and 1 kept, got %d and %d", removed, s.count)
+	}
+	if mock.Stats(cmdKeep) != 1 || mock.Stats(cmdRemoveA) != 0 || mock.Stats(cmdRemoveB) != 1 {
+		t.Fatalf("Only the file outside of the directory being synced is supposed to be removed")
+	}
+}
+
+func TestScan_flush(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	mock.Command("SADD", "MIRRORFILESTMP_1", "/a").Expect(int64(1))
+	mock.Command("SADD", "MIRRORFILESTMP_1", "/b").ExpectError(redis.Error("OOM command not allowed"))
+
+	s := &scan{
+		conn:        conn.Get(),
+		mirrorid:    1,
+		filesTmpKey: "MIRRORFILESTMP_1",
+	}
+
+	s.conn.Send("SADD", "MIRRORFILESTMP_1", "/a")
+	s.pending++
+	if err := s.flush(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	s.conn.Send("SADD", "MIRRORFILESTMP_1", "/b")
+	s.pending++
+	if err := s.flush(); err == nil {
+		t.Fatalf("The error of the database is supposed to be returned")
+	}
+	if err := s.ScannerCommit(); err == nil {
+		t.Fatalf("The first error is supposed to be kept")
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/etix/mirrorbits/core"
+	"github.com/etix/mirrorbits/database"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// maxScanSummaries is the number of summaries kept for each mirror
+	maxScanSummaries = 100
+)
+
+// ScanSummary is the record of a single scan of a mirror
+type ScanSummary struct {
+	Timestamp    time.Time
+	Duration     time.Duration
+	Method       string
+	FilesIndexed int64
+	KnownIndexed int64
+	Added        int64
+	Removed      int64
+	Error        string
+	// The result was held in quarantine (see ScanQuarantineThreshold)
+	Quarantined bool `json:",omitempty"`
+	// The result held in quarantine was accepted by a forced scan
+	Accepted bool `json:",omitempty"`
+}
+
+func scannerName(typ core.ScannerType) string {
+	switch typ {
+	case core.RSYNC:
+		return "rsync"
+	case core.FTP:
+		return "ftp"
+	case core.MANIFEST:
+		return "manifest"
+	}
+	return "unknown"
+}
+
+// setResult records the result of a scan of a mirror which previously had
+// the given number of files
+func (s *ScanSummary) setResult(res *ScanResult, previous int64) {
+	s.FilesIndexed = res.FilesIndexed
+	s.KnownIndexed = res.KnownIndexed
+	s.Added = res.FilesIndexed - (previous - res.Removed)
+	s.Removed = res.Removed
+}
+
+// pushScanSummary appends the summary to the capped list of the given mirror
+func pushScanSummary(r *database.Redis, id int, summary *ScanSummary) error {
+	conn := r.Get()
+	defer conn.Close()
+
+	value, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("SCANSUMMARIES_%d", id)
+
+	conn.Send("MULTI")
+	conn.Send("RPUSH", key, value)
+	conn.Send("LTRIM", key, -maxScanSummaries, -1)
+	_, err = conn.Do("EXEC")
+	return err
+}
+
+// ReadScanSummaries returns the latest scan summaries of the given mirror,
+// the most recent being the last
+func ReadScanSummaries(r *database.Redis, id, max int) ([]ScanSummary, error) {
+	conn := r.Get()
+	defer conn.Close()
+
+	if max <= 0 || max > maxScanSummaries {
+		max = maxScanSummaries
+	}
+
+	key := fmt.Sprintf("SCANSUMMARIES_%d", id)
+	lines, err := redis.Strings(conn.Do("LRANGE", key, max*-1, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]ScanSummary, 0, len(lines))
+
+	for _, line := range lines {
+		var summary ScanSummary
+		if err := json.Unmarshal([]byte(line), &summary); err != nil {
+			log.Warningf("Unable to parse scan summary: %s", err)
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
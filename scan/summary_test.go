@@ -0,0 +1,88 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package scan
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/etix/mirrorbits/testing"
+)
+
+func TestScanSummary_setResult(t *testing.T) {
+	var summary ScanSummary
+
+	// 1000 files previously, 30 removed and 50 added
+	summary.setResult(&ScanResult{
+		FilesIndexed: 1020,
+		KnownIndexed: 1010,
+		Removed:      30,
+	}, 1000)
+
+	if summary.FilesIndexed != 1020 || summary.KnownIndexed != 1010 {
+		t.Fatalf("Unexpected number of files: %+v", summary)
+	}
+	if summary.Added != 50 || summary.Removed != 30 {
+		t.Fatalf("Expected 50 files added and 30 removed, got %d and %d", summary.Added, summary.Removed)
+	}
+
+	// First scan of the mirror
+	summary.setResult(&ScanResult{FilesIndexed: 10, KnownIndexed: 10}, 0)
+	if summary.Added != 10 || summary.Removed != 0 {
+		t.Fatalf("Expected 10 files added and none removed, got %d and %d", summary.Added, summary.Removed)
+	}
+}
+
+func TestPushScanSummary(t *testing.T) {
+	mock, conn := PrepareRedisTest()
+
+	summary := &ScanSummary{
+		Timestamp:    time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC),
+		Duration:     90 * time.Second,
+		Method:       "rsync",
+		FilesIndexed: 1020,
+		KnownIndexed: 1010,
+		Added:        50,
+		Removed:      30,
+	}
+	value, err := json.Marshal(summary)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	mock.Command("MULTI")
+	cmdPush := mock.Command("RPUSH", "SCANSUMMARIES_1", value)
+	cmdTrim := mock.Command("LTRIM", "SCANSUMMARIES_1", -maxScanSummaries, -1)
+	mock.Command("EXEC").Expect([]interface{}{int64(1), "OK"})
+
+	if err := pushScanSummary(conn, 1, summary); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if mock.Stats(cmdPush) != 1 || mock.Stats(cmdTrim) != 1 {
+		t.Fatalf("Expected the summary to be appended to the capped list")
+	}
+
+	mock.Command("LRANGE", "SCANSUMMARIES_1", -maxScanSummaries, -1).Expect([]interface{}{
+		[]byte("invalid"),
+		value,
+	})
+
+	summaries, err := ReadScanSummaries(conn, 1, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("Expected the invalid summary to be skipped, got %v", summaries)
+	}
+	if !summaries[0].Timestamp.Equal(summary.Timestamp) || summaries[0].Duration != summary.Duration ||
+		summaries[0].Added != 50 || summaries[0].Removed != 30 || summaries[0].Method != "rsync" {
+		t.Fatalf("Unexpected summary %+v", summaries[0])
+	}
+
+	mock.Command("LRANGE", "SCANSUMMARIES_1", -5, -1).Expect([]interface{}{})
+	if _, err := ReadScanSummaries(conn, 1, 5); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
@@ -49,6 +49,7 @@ func NewTraceHandler(redis *database.Redis, stop <-chan struct{}) *Trace {
 	t.transport = http.Transport{
 		DisableKeepAlives:   true,
 		MaxIdleConnsPerHost: 0,
+		Proxy:               mirrors.ProxyFromContext,
 		Dial: func(network, addr string) (net.Conn, error) {
 			deadline := time.Now().Add(clientDeadline)
 			c, err := net.DialTimeout(network, addr, clientTimeout)
@@ -78,6 +79,11 @@ func (t *Trace) GetLastUpdate(mirror mirrors.Mirror) error {
 
 	log.Debugf("Getting latest trace file for %s...", mirror.Name)
 
+	proxy, err := mirror.ProxyURL()
+	if err != nil {
+		return err
+	}
+
 	// Prepare the HTTP request
 	req, err := http.NewRequest("GET", utils.ConcatURL(mirror.HttpURL, traceFile), nil)
 	req.Header.Set("User-Agent", userAgent)
@@ -86,6 +92,7 @@ func (t *Trace) GetLastUpdate(mirror mirrors.Mirror) error {
 	// Prepare contexts
 	ctx, cancel := context.WithTimeout(req.Context(), clientDeadline)
 	ctx = context.WithValue(ctx, core.ContextMirrorID, mirror.ID)
+	ctx = context.WithValue(ctx, core.ContextProxy, proxy)
 	req = req.WithContext(ctx)
 	defer cancel()
 
@@ -0,0 +1,122 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/database"
+	"github.com/etix/mirrorbits/filesystem"
+	"github.com/gomodule/redigo/redis"
+)
+
+var (
+	// ErrVerifyUnsupported is returned when verifying a repository stored
+	// in a bucket
+	ErrVerifyUnsupported = errors.New("the files stored in a bucket cannot be verified")
+)
+
+// VerifyResult is the result of the verification of the local repository
+type VerifyResult struct {
+	// Checked is the number of files hashed
+	Checked int64
+	// Mismatches are the files whose content no longer matches their hashes
+	Mismatches []string
+}
+
+// VerifySource re-hashes the files of the local repository and compares
+// them with the hashes of the index. The files starting with prefix are
+// all verified, otherwise up to limit files are verified starting where the
+// previous call stopped so that the whole repository is covered over time.
+// The files modified since the last scan of the repository are skipped,
+// only the files whose content changed behind their size and modification
+// time (e.g. bit rot or a bad upload) are reported.
+func VerifySource(ctx context.Context, r *database.Redis, prefix string, limit int) (*VerifyResult, error) {
+	if GetConfig().ObjectStorage.Enabled() {
+		return nil, ErrVerifyUnsupported
+	}
+
+	conn := r.Get()
+	defer conn.Close()
+
+	rolling := prefix == ""
+	cursor := 0
+	if rolling {
+		cursor, _ = redis.Int(conn.Do("GET", "VERIFY_CURSOR"))
+	}
+
+	result := &VerifyResult{}
+	for {
+		if ctx.Err() != nil {
+			return result, ErrScanAborted
+		}
+		values, err := redis.Values(conn.Do("SSCAN", "FILES", cursor, "COUNT", 100))
+		if err != nil {
+			return result, err
+		}
+		var files []string
+		if _, err = redis.Scan(values, &cursor, &files); err != nil {
+			return result, err
+		}
+
+		for _, file := range files {
+			if !rolling && !strings.HasPrefix(file, prefix) {
+				continue
+			}
+			ok, err := verifyFile(conn, file)
+			if err != nil {
+				log.Warningf("%s: verification failed: %s", file, err)
+				continue
+			}
+			result.Checked++
+			if !ok {
+				log.Errorf("%s: the content of the file no longer matches its hashes", file)
+				result.Mismatches = append(result.Mismatches, file)
+			}
+		}
+
+		if cursor == 0 || (rolling && result.Checked >= int64(limit)) {
+			break
+		}
+	}
+
+	if rolling {
+		conn.Do("SET", "VERIFY_CURSOR", cursor)
+	}
+	return result, nil
+}
+
+// verifyFile returns false if the file kept its size and modification time
+// but its content no longer matches the hashes of the index
+func verifyFile(conn redis.Conn, path string) (bool, error) {
+	properties, err := redis.Strings(conn.Do("HMGET", fmt.Sprintf("FILE_%s", path), "size", "modTime", "sha1", "sha256", "md5"))
+	if err != nil {
+		return false, err
+	}
+	size, _ := strconv.ParseInt(properties[0], 10, 64)
+	modTime, _ := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", properties[1])
+	indexed := filesystem.FileInfo{Sha1: properties[2], Sha256: properties[3], Md5: properties[4]}
+
+	f, err := os.Stat(GetConfig().Repository + path)
+	if err != nil {
+		return false, err
+	}
+	if f.Size() != size || !f.ModTime().Equal(modTime) {
+		// Changed since the last scan, the next one will rehash it
+		return true, nil
+	}
+
+	h, err := filesystem.HashFile(GetConfig().Repository + path)
+	if err != nil {
+		return false, err
+	}
+	return indexed.Matches(h), nil
+}
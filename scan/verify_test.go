@@ -0,0 +1,60 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package scan
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/etix/mirrorbits/config"
+	. "github.com/etix/mirrorbits/testing"
+)
+
+func TestVerifyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mirrorbits-verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Configuration{Repository: dir}
+	c.Hashes.SHA256 = true
+	SetConfiguration(c)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "a.tgz"), []byte("mirrorbits"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fi, _ := os.Stat(filepath.Join(dir, "a.tgz"))
+	modTime := fi.ModTime().String()
+	sha256 := "1235a5b376903794b373d84ed615bb36013e70ed6aebf30b2f4823321d5182ec"
+	corrupted := "21ef2e3e1d4e2bd8d4fd1ff2d24ecb0fa08da6c0c35c3fbcc7aec3a7e8d7ef6b"
+
+	mock, r := PrepareRedisTest()
+	conn := r.Get()
+	defer conn.Close()
+
+	mock.Command("HMGET", "FILE_/a.tgz", "size", "modTime", "sha1", "sha256", "md5").Expect([]interface{}{
+		[]byte("10"), []byte(modTime), []byte(""), []byte(corrupted), []byte(""),
+	})
+	if ok, err := verifyFile(conn, "/a.tgz"); err != nil || ok {
+		t.Fatalf("Expected a mismatch, got %v (%v)", ok, err)
+	}
+
+	// Modified since the last scan
+	mock.Command("HMGET", "FILE_/a.tgz", "size", "modTime", "sha1", "sha256", "md5").Expect([]interface{}{
+		[]byte("12"), []byte(modTime), []byte(""), []byte(corrupted), []byte(""),
+	})
+	if ok, err := verifyFile(conn, "/a.tgz"); err != nil || !ok {
+		t.Fatalf("The modified files are supposed to be skipped, got %v (%v)", ok, err)
+	}
+
+	mock.Command("HMGET", "FILE_/a.tgz", "size", "modTime", "sha1", "sha256", "md5").Expect([]interface{}{
+		[]byte("10"), []byte(modTime), []byte(""), []byte(sha256), []byte(""),
+	})
+	if ok, err := verifyFile(conn, "/a.tgz"); err != nil || !ok {
+		t.Fatalf("Expected a match, got %v (%v)", ok, err)
+	}
+}
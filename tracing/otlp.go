@@ -0,0 +1,236 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/etix/mirrorbits/core"
+)
+
+const (
+	queueSize     = 4096
+	batchSize     = 512
+	flushInterval = 5 * time.Second
+)
+
+// otlpExporter sends the spans in batches to an OpenTelemetry collector
+// using OTLP over HTTP with the JSON encoding
+type otlpExporter struct {
+	url         string
+	headers     map[string]string
+	serviceName string
+	sampleRatio float64
+	client      *http.Client
+	spans       chan *Span
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+func newOTLPExporter() *otlpExporter {
+	c := GetConfig().Tracing
+	e := &otlpExporter{
+		url:         strings.TrimSuffix(c.Endpoint, "/") + "/v1/traces",
+		headers:     c.Headers,
+		serviceName: c.ServiceName,
+		sampleRatio: c.SampleRatio,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		spans:       make(chan *Span, queueSize),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go e.loop()
+	return e
+}
+
+// Export queues the span, it is dropped if the queue is full
+func (e *otlpExporter) Export(s *Span) {
+	select {
+	case e.spans <- s:
+	default:
+	}
+}
+
+// Stop sends the pending spans and stops the exporter
+func (e *otlpExporter) Stop() {
+	close(e.stop)
+	<-e.done
+}
+
+func (e *otlpExporter) loop() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(batch); err != nil {
+			log.Warningf("Unable to export %d spans: %s", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case s := <-e.spans:
+			batch = append(batch, s)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.stop:
+			for {
+				select {
+				case s := <-e.spans:
+					batch = append(batch, s)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *otlpExporter) send(batch []*Span) error {
+	body, err := json.Marshal(e.encode(batch))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type otlpAttribute struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+func newAttribute(key string, value interface{}) otlpAttribute {
+	var v map[string]interface{}
+	switch value := value.(type) {
+	case string:
+		v = map[string]interface{}{"stringValue": value}
+	case bool:
+		v = map[string]interface{}{"boolValue": value}
+	case int:
+		v = map[string]interface{}{"intValue": strconv.Itoa(value)}
+	case int64:
+		v = map[string]interface{}{"intValue": strconv.FormatInt(value, 10)}
+	case float64:
+		v = map[string]interface{}{"doubleValue": value}
+	default:
+		v = map[string]interface{}{"stringValue": fmt.Sprint(value)}
+	}
+	return otlpAttribute{Key: key, Value: v}
+}
+
+func (e *otlpExporter) encode(batch []*Span) otlpRequest {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, s := range batch {
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		}
+		if s.parentID != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		for k, v := range s.attributes {
+			span.Attributes = append(span.Attributes, newAttribute(k, v))
+		}
+		if s.err != "" {
+			span.Status = &otlpStatus{Code: 2, Message: s.err}
+		}
+		spans = append(spans, span)
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{
+					newAttribute("service.name", e.serviceName),
+					newAttribute("service.version", core.VERSION),
+				},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/etix/mirrorbits", Version: core.VERSION},
+				Spans: spans,
+			}},
+		}},
+	}
+}
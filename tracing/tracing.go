@@ -0,0 +1,182 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	mrand "math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/etix/mirrorbits/config"
+	"github.com/op/go-logging"
+)
+
+var (
+	log = logging.MustGetLogger("main")
+
+	exporterLock sync.RWMutex
+	exporter     *otlpExporter
+)
+
+// SpanKind describes the relationship between the span and its parent
+type SpanKind int
+
+// Kinds of span as defined by OpenTelemetry
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+type contextKey struct{}
+
+type spanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+	sampled bool
+}
+
+// Span is an operation of a trace, a nil span is valid and records nothing
+type Span struct {
+	spanContext
+	parentID   [8]byte
+	name       string
+	kind       SpanKind
+	start      time.Time
+	end        time.Time
+	attributes map[string]interface{}
+	err        string
+	exporter   *otlpExporter
+}
+
+// Reload starts, stops or reconfigures the export of the spans according to
+// the configuration
+func Reload() {
+	exporterLock.Lock()
+	defer exporterLock.Unlock()
+
+	if exporter != nil {
+		exporter.Stop()
+		exporter = nil
+	}
+
+	if GetConfig().Tracing.Endpoint == "" {
+		return
+	}
+	exporter = newOTLPExporter()
+	log.Infof("Exporting the traces to %s", GetConfig().Tracing.Endpoint)
+}
+
+func currentExporter() *otlpExporter {
+	exporterLock.RLock()
+	defer exporterLock.RUnlock()
+	return exporter
+}
+
+// Extract returns a context carrying the remote parent given in the W3C
+// traceparent header of the request, if any
+func Extract(r *http.Request) context.Context {
+	ctx := r.Context()
+	parts := strings.Split(r.Header.Get("traceparent"), "-")
+	if len(parts) != 4 || parts[0] != "00" {
+		return ctx
+	}
+
+	var sc spanContext
+	traceID, err := hex.DecodeString(parts[1])
+	if err != nil || len(traceID) != len(sc.traceID) {
+		return ctx
+	}
+	spanID, err := hex.DecodeString(parts[2])
+	if err != nil || len(spanID) != len(sc.spanID) {
+		return ctx
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return ctx
+	}
+	copy(sc.traceID[:], traceID)
+	copy(sc.spanID[:], spanID)
+	sc.sampled = flags[0]&1 == 1
+
+	return context.WithValue(ctx, contextKey{}, sc)
+}
+
+// Start creates a span as a child of the span carried by the context and
+// returns the context carrying the new span. The returned span is nil if
+// tracing is disabled or the trace isn't sampled.
+func Start(ctx context.Context, kind SpanKind, name string) (context.Context, *Span) {
+	e := currentExporter()
+	if e == nil {
+		return ctx, nil
+	}
+
+	parent, ok := ctx.Value(contextKey{}).(spanContext)
+	if ok && !parent.sampled {
+		return ctx, nil
+	}
+
+	s := &Span{
+		name:     name,
+		kind:     kind,
+		start:    time.Now(),
+		exporter: e,
+	}
+	if ok {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		if mrand.Float64() >= e.sampleRatio {
+			// Propagate the decision to the children
+			return context.WithValue(ctx, contextKey{}, spanContext{}), nil
+		}
+		rand.Read(s.traceID[:])
+	}
+	rand.Read(s.spanID[:])
+	s.sampled = true
+
+	return context.WithValue(ctx, contextKey{}, s.spanContext), s
+}
+
+// SetAttribute adds an attribute to the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	if s.attributes == nil {
+		s.attributes = make(map[string]interface{})
+	}
+	s.attributes[key] = value
+}
+
+// SetError marks the span as failed
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.err = err.Error()
+}
+
+// End completes the span and queues it for the export
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	s.exporter.Export(s)
+}
+
+// TraceParent returns the W3C traceparent of the span
+func (s *Span) TraceParent() string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("00-%x-%x-01", s.traceID, s.spanID)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStartDisabled(t *testing.T) {
+	exporter = nil
+
+	ctx := context.Background()
+	newCtx, span := Start(ctx, KindServer, "test")
+	if span != nil || newCtx != ctx {
+		t.Fatalf("No span is expected when tracing is disabled")
+	}
+
+	// A nil span must be usable
+	span.SetAttribute("key", "value")
+	span.End()
+}
+
+func TestStart(t *testing.T) {
+	exporter = &otlpExporter{sampleRatio: 1, spans: make(chan *Span, 10)}
+	defer func() { exporter = nil }()
+
+	r, _ := http.NewRequest("GET", "/file", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx, root := Start(Extract(r), KindServer, "root")
+	if root == nil {
+		t.Fatalf("The root span must be sampled")
+	}
+	if !strings.HasPrefix(root.TraceParent(), "00-4bf92f3577b34da6a3ce929d0e0e4736-") {
+		t.Fatalf("The trace id of the parent must be kept, got %s", root.TraceParent())
+	}
+
+	_, child := Start(ctx, KindInternal, "child")
+	if child.traceID != root.traceID || child.parentID != root.spanID {
+		t.Fatalf("The child must be linked to its parent")
+	}
+	child.End()
+	root.End()
+
+	req := exporter.encode([]*Span{<-exporter.spans, <-exporter.spans})
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if spans[0].Name != "child" || spans[0].ParentSpanID != spans[1].SpanID {
+		t.Fatalf("Unexpected encoded spans %+v", spans)
+	}
+	if spans[1].ParentSpanID != "00f067aa0ba902b7" {
+		t.Fatalf("The remote parent must be referenced, got %s", spans[1].ParentSpanID)
+	}
+
+	// Unsampled remote parent
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	if _, span := Start(Extract(r), KindServer, "root"); span != nil {
+		t.Fatalf("The decision of the parent must be honored")
+	}
+}
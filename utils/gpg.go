@@ -0,0 +1,162 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var (
+	// ErrNoSigningKey is returned when signing without a key
+	ErrNoSigningKey = errors.New("no signing key configured")
+	// ErrNoSigner is returned when verifying a signature without a trusted signer
+	ErrNoSigner = errors.New("no trusted signer given")
+)
+
+// GPGSigner signs data using the gpg binary. The private key is either taken
+// from the keyring of the given home directory or from a running gpg-agent.
+type GPGSigner struct {
+	Binary  string
+	Homedir string
+	Key     string
+}
+
+// ClearSign returns the given text wrapped into a cleartext signature
+func (g GPGSigner) ClearSign(data []byte) ([]byte, error) {
+	return g.run(data, "--clearsign")
+}
+
+// DetachSign returns an ASCII armored detached signature of the given data
+func (g GPGSigner) DetachSign(data []byte) ([]byte, error) {
+	return g.run(data, "--detach-sign", "--armor")
+}
+
+func (g GPGSigner) run(data []byte, args ...string) ([]byte, error) {
+	if g.Key == "" {
+		return nil, ErrNoSigningKey
+	}
+
+	binary := g.Binary
+	if binary == "" {
+		binary = "gpg"
+	}
+
+	opts := []string{"--batch", "--no-tty", "--yes", "--local-user", g.Key}
+	if g.Homedir != "" {
+		opts = append(opts, "--homedir", g.Homedir)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(binary, append(opts, args...)...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("gpg: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// GPGVerify checks the given detached signature of data against the public
+// keys of the given keyring, or of the keyring of the given home directory
+// (the default keyring if empty). The signature must be made by one of the
+// given signers, identified by the fingerprint of their key or of its
+// primary key: a valid signature from any other key of the keyring is
+// rejected.
+func GPGVerify(binary, homedir, keyring string, signers []string, data, signature []byte) error {
+	if len(signers) == 0 {
+		return ErrNoSigner
+	}
+	if binary == "" {
+		binary = "gpg"
+	}
+
+	sigfile, err := ioutil.TempFile("", "mirrorbits-sig")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(sigfile.Name())
+	_, err = sigfile.Write(signature)
+	if cerr := sigfile.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	opts := []string{"--batch", "--no-tty", "--status-fd", "1"}
+	if homedir != "" {
+		opts = append(opts, "--homedir", homedir)
+	}
+	if keyring != "" {
+		opts = append(opts, "--no-default-keyring", "--keyring", keyring)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(binary, append(opts, "--verify", sigfile.Name(), "-")...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("gpg: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return checkSigners(stdout.Bytes(), signers)
+}
+
+// checkSigners returns an error unless the status output of gpg reports only
+// valid signatures, at least one of them made by one of the given signers
+func checkSigners(status []byte, signers []string) error {
+	var found []string
+	for _, line := range strings.Split(string(status), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "[GNUPG:]" {
+			continue
+		}
+		switch fields[1] {
+		case "BADSIG", "ERRSIG", "EXPSIG", "EXPKEYSIG", "REVKEYSIG":
+			return fmt.Errorf("gpg: %s", strings.Join(fields[1:], " "))
+		case "VALIDSIG":
+			// VALIDSIG <fingerprint> ... <primary key fingerprint>
+			if len(fields) < 3 {
+				continue
+			}
+			fprs := []string{fields[2]}
+			if len(fields) >= 12 {
+				fprs = append(fprs, fields[11])
+			}
+			for _, fpr := range fprs {
+				for _, signer := range signers {
+					if strings.EqualFold(fpr, normalizeFingerprint(signer)) {
+						return nil
+					}
+				}
+			}
+			found = append(found, fields[2])
+		}
+	}
+	if len(found) == 0 {
+		return fmt.Errorf("gpg: no valid signature")
+	}
+	return fmt.Errorf("gpg: signed by %s, not by one of the expected signers", strings.Join(found, ", "))
+}
+
+// normalizeFingerprint removes the spaces and the 0x prefix of a fingerprint
+func normalizeFingerprint(fpr string) string {
+	fpr = strings.Replace(fpr, " ", "", -1)
+	if strings.HasPrefix(fpr, "0x") || strings.HasPrefix(fpr, "0X") {
+		fpr = fpr[2:]
+	}
+	return fpr
+}
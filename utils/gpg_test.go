@@ -0,0 +1,97 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+const (
+	signerFpr = "0123456789ABCDEF0123456789ABCDEF01234567"
+	otherFpr  = "89ABCDEF0123456789ABCDEF0123456789ABCDEF"
+)
+
+func TestCheckSigners(t *testing.T) {
+	valid := func(fpr, primary string) string {
+		return "[GNUPG:] NEWSIG\n" +
+			"[GNUPG:] GOODSIG " + fpr[24:] + " Someone\n" +
+			"[GNUPG:] VALIDSIG " + fpr + " 2019-05-01 1556712000 0 4 0 1 10 00 " + primary + "\n" +
+			"[GNUPG:] TRUST_UNDEFINED 0 pgp\n"
+	}
+
+	if err := checkSigners([]byte(valid(signerFpr, signerFpr)), []string{signerFpr}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	// Signed by a subkey of the signer
+	if err := checkSigners([]byte(valid(otherFpr, signerFpr)), []string{strings.ToLower(signerFpr)}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	// A valid signature of another key of the keyring is rejected
+	if err := checkSigners([]byte(valid(otherFpr, otherFpr)), []string{signerFpr}); err == nil {
+		t.Fatalf("A signature of another key is supposed to be rejected")
+	}
+	if err := checkSigners([]byte("[GNUPG:] BADSIG 0123456789ABCDEF Someone\n"), []string{signerFpr}); err == nil {
+		t.Fatalf("A bad signature is supposed to be rejected")
+	}
+	if err := checkSigners([]byte("[GNUPG:] EXPKEYSIG 0123456789ABCDEF Someone\n"+valid(signerFpr, signerFpr)), []string{signerFpr}); err == nil {
+		t.Fatalf("A signature of an expired key is supposed to be rejected")
+	}
+	if err := checkSigners(nil, []string{signerFpr}); err == nil {
+		t.Fatalf("A missing signature is supposed to be rejected")
+	}
+}
+
+func TestGPGVerify(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg is not available")
+	}
+
+	homedir, err := ioutil.TempDir("", "mirrorbits-gpg")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(homedir)
+	defer exec.Command("gpgconf", "--homedir", homedir, "--kill", "gpg-agent").Run()
+
+	genKey := func(uid string) string {
+		cmd := exec.Command("gpg", "--batch", "--homedir", homedir, "--passphrase", "",
+			"--quick-gen-key", uid, "ed25519", "sign", "never")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("Unable to generate a key: %s: %s", err, out)
+		}
+		out, err := exec.Command("gpg", "--batch", "--homedir", homedir, "--with-colons", "--list-keys", uid).Output()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			if strings.HasPrefix(line, "fpr:") {
+				return strings.Split(line, ":")[9]
+			}
+		}
+		t.Fatalf("No fingerprint for %s", uid)
+		return ""
+	}
+	signer := genKey("signer@example.org")
+	other := genKey("other@example.org")
+
+	data := []byte("release")
+	signature, err := GPGSigner{Homedir: homedir, Key: other}.DetachSign(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if err := GPGVerify("", homedir, "", []string{signer}, data, signature); err == nil {
+		t.Fatalf("A signature of another key of the keyring is supposed to be rejected")
+	}
+	if err := GPGVerify("", homedir, "", []string{other}, data, signature); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := GPGVerify("", homedir, "", nil, data, signature); err != ErrNoSigner {
+		t.Fatalf("Expected ErrNoSigner, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2014-2019 Ludovic Fauvet
+// Licensed under the MIT license
+
+package utils
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strconv"
+)
+
+var (
+	// ErrInvalidTorrent is returned when a torrent file cannot be decoded
+	ErrInvalidTorrent = errors.New("invalid torrent file")
+)
+
+// TorrentInfoHash returns the hex encoded info hash of the given bencoded
+// torrent, as used in magnet links
+func TorrentInfoHash(data []byte) (string, error) {
+	if len(data) == 0 || data[0] != 'd' {
+		return "", ErrInvalidTorrent
+	}
+
+	pos := 1
+	for pos < len(data) && data[pos] != 'e' {
+		key, next, err := bencodeString(data, pos)
+		if err != nil {
+			return "", err
+		}
+		end, err := bencodeSkip(data, next)
+		if err != nil {
+			return "", err
+		}
+		if key == "info" {
+			sum := sha1.Sum(data[next:end])
+			return hex.EncodeToString(sum[:]), nil
+		}
+		pos = end
+	}
+	return "", ErrInvalidTorrent
+}
+
+// bencodeString decodes the string starting at pos and returns it along
+// with the position following it
+func bencodeString(data []byte, pos int) (string, int, error) {
+	colon := bytes.IndexByte(data[pos:], ':')
+	if colon <= 0 {
+		return "", 0, ErrInvalidTorrent
+	}
+	length, err := strconv.Atoi(string(data[pos : pos+colon]))
+	if err != nil || length < 0 {
+		return "", 0, ErrInvalidTorrent
+	}
+	start := pos + colon + 1
+	if start+length > len(data) {
+		return "", 0, ErrInvalidTorrent
+	}
+	return string(data[start : start+length]), start + length, nil
+}
+
+// bencodeSkip returns the position following the value starting at pos
+func bencodeSkip(data []byte, pos int) (int, error) {
+	if pos >= len(data) {
+		return 0, ErrInvalidTorrent
+	}
+	switch c := data[pos]; {
+	case c == 'i':
+		end := bytes.IndexByte(data[pos:], 'e')
+		if end < 0 {
+			return 0, ErrInvalidTorrent
+		}
+		return pos + end + 1, nil
+	case c == 'l' || c == 'd':
+		pos++
+		for pos < len(data) && data[pos] != 'e' {
+			next, err := bencodeSkip(data, pos)
+			if err != nil {
+				return 0, err
+			}
+			pos = next
+		}
+		if pos >= len(data) {
+			return 0, ErrInvalidTorrent
+		}
+		return pos + 1, nil
+	case c >= '0' && c <= '9':
+		_, next, err := bencodeString(data, pos)
+		return next, err
+	}
+	return 0, ErrInvalidTorrent
+}
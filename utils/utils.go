@@ -4,14 +4,20 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"math"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/etix/mirrorbits/core"
 	"github.com/etix/mirrorbits/network"
+	"golang.org/x/text/unicode/norm"
 )
 
 const (
@@ -149,6 +155,32 @@ func Plural(value interface{}) string {
 	return ""
 }
 
+// EscapePath percent-encodes the spaces, the reserved characters and the
+// non-ASCII characters of the path to use it in a URL
+func EscapePath(path string) string {
+	return (&url.URL{Path: path}).EscapedPath()
+}
+
+// AlternateNormalization returns the path in the other Unicode normalization
+// form (NFD for a path in NFC and vice versa) or an empty string if both are
+// identical
+func AlternateNormalization(path string) string {
+	alt := norm.NFC.String(path)
+	if alt == path {
+		alt = norm.NFD.String(path)
+	}
+	if alt == path {
+		return ""
+	}
+	return alt
+}
+
+// LookupKey returns the key of the path in the index of the tolerant lookups
+// (see TolerantLookups), i.e. the path in lower case without trailing slash
+func LookupKey(path string) string {
+	return strings.ToLower(strings.TrimSuffix(path, "/"))
+}
+
 // ConcatURL concatenate the url and path
 func ConcatURL(url, path string) string {
 	if strings.HasSuffix(url, "/") && strings.HasPrefix(path, "/") {
@@ -160,6 +192,20 @@ func ConcatURL(url, path string) string {
 	return url + path
 }
 
+// AppendURL appends the url and path to b like ConcatURL, sparing the
+// allocation of the intermediate string
+func AppendURL(b []byte, url, path string) []byte {
+	if strings.HasSuffix(url, "/") && strings.HasPrefix(path, "/") {
+		url = url[:len(url)-1]
+	} else if !strings.HasSuffix(url, "/") && !strings.HasPrefix(path, "/") {
+		b = append(b, url...)
+		b = append(b, '/')
+		return append(b, path...)
+	}
+	b = append(b, url...)
+	return append(b, path...)
+}
+
 // FormattedDateUTC returns the date formatted as RFC1123
 func FormattedDateUTC(t time.Time) string {
 	return t.UTC().Format(time.RFC1123)
@@ -247,3 +293,21 @@ func SanitizeLocationCodes(input string) string {
 	}
 	return strings.TrimRight(output, " ")
 }
+
+// SignPath returns the hex encoded HMAC-SHA256 signature of the given path
+// valid until the expiration timestamp (unix time)
+func SignPath(secret, path string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(path + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckPathSignature returns true if the signature of the given path is valid
+// and has not expired
+func CheckPathSignature(secret, path, expires, signature string, now time.Time) bool {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > exp {
+		return false
+	}
+	return hmac.Equal([]byte(SignPath(secret, path, exp)), []byte(signature))
+}
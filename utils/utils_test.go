@@ -214,6 +214,61 @@ func TestConcatURL(t *testing.T) {
 	}
 }
 
+func TestAppendURL(t *testing.T) {
+	parts := [][2]string{
+		{"http://test.example/somedir/", "/somefile.bin"},
+		{"http://test.example/somedir", "/somefile.bin"},
+		{"http://test.example/somedir", "somefile.bin"},
+		{"http://test.example/somedir/", "somefile.bin"},
+	}
+	for _, p := range parts {
+		result := "<" + ConcatURL(p[0], p[1])
+		if r := string(AppendURL([]byte("<"), p[0], p[1])); r != result {
+			t.Fatalf("Expected %s, got %s", result, r)
+		}
+	}
+}
+
+func TestEscapePath(t *testing.T) {
+	paths := map[string]string{
+		"/somedir/somefile.bin":    "/somedir/somefile.bin",
+		"/some dir/some file.bin":  "/some%20dir/some%20file.bin",
+		"/release #1/file?.iso":    "/release%20%231/file%3F.iso",
+		"/100%/file.iso":           "/100%25/file.iso",
+		"/café/naïve.txt":          "/caf%C3%A9/na%C3%AFve.txt",
+		"/日本語/ファイル.zip":            "/%E6%97%A5%E6%9C%AC%E8%AA%9E/%E3%83%95%E3%82%A1%E3%82%A4%E3%83%AB.zip",
+		"/a+b/c&d=e;f/g@h:i,j.tar": "/a+b/c&d=e;f/g@h:i,j.tar",
+	}
+	for path, expected := range paths {
+		if r := EscapePath(path); r != expected {
+			t.Fatalf("Expected %s, got %s", expected, r)
+		}
+	}
+}
+
+func TestAlternateNormalization(t *testing.T) {
+	nfc := "/caf\u00e9.txt"
+	nfd := "/cafe\u0301.txt"
+	if r := AlternateNormalization(nfc); r != nfd {
+		t.Fatalf("Expected %q, got %q", nfd, r)
+	}
+	if r := AlternateNormalization(nfd); r != nfc {
+		t.Fatalf("Expected %q, got %q", nfc, r)
+	}
+	if r := AlternateNormalization("/some file.bin"); r != "" {
+		t.Fatalf("Expected an empty string, got %q", r)
+	}
+}
+
+func TestLookupKey(t *testing.T) {
+	if r := LookupKey("/Releases/Project-1.0.ISO"); r != "/releases/project-1.0.iso" {
+		t.Fatalf("Unexpected key %s", r)
+	}
+	if r := LookupKey("/Releases/1.0/"); r != "/releases/1.0" {
+		t.Fatalf("Unexpected key %s", r)
+	}
+}
+
 func TestTimeKeyCoverage(t *testing.T) {
 	date1Start := time.Date(2015, 10, 30, 12, 42, 11, 0, time.UTC)
 	date1End := time.Date(2015, 12, 2, 13, 42, 11, 0, time.UTC)
@@ -285,3 +340,79 @@ func TestTimeKeyCoverage(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckPathSignature(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	secret := "0123456789abcdef"
+	path := "/beta/file.tar.gz"
+
+	sig := SignPath(secret, path, 1500000060)
+
+	if !CheckPathSignature(secret, path, "1500000060", sig, now) {
+		t.Fatalf("Expected the signature to be valid")
+	}
+	if CheckPathSignature(secret, path, "1500000060", sig, now.Add(2*time.Minute)) {
+		t.Fatalf("Expected the signature to be expired")
+	}
+	if CheckPathSignature(secret, path, "1500000120", sig, now) {
+		t.Fatalf("Expected the signature to be invalid for another expiration")
+	}
+	if CheckPathSignature(secret, "/beta/other.tar.gz", "1500000060", sig, now) {
+		t.Fatalf("Expected the signature to be invalid for another path")
+	}
+	if CheckPathSignature("fedcba9876543210", path, "1500000060", sig, now) {
+		t.Fatalf("Expected the signature to be invalid for another secret")
+	}
+	if CheckPathSignature(secret, path, "", sig, now) {
+		t.Fatalf("Expected the signature to be invalid without expiration")
+	}
+}
+
+func TestTorrentInfoHash(t *testing.T) {
+	torrent := "d8:announce23:http://tracker/announce4:infod6:lengthi42e4:name8:file.iso12:piece lengthi262144e6:pieces0:ee"
+
+	hash, err := TorrentInfoHash([]byte(torrent))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if hash != "482aa37897402d00f92384135d2fbdbdb89cd353" {
+		t.Fatalf("Unexpected info hash %s", hash)
+	}
+
+	if _, err := TorrentInfoHash([]byte("d8:announce23:http://tracker/announcee")); err != ErrInvalidTorrent {
+		t.Fatalf("Expected ErrInvalidTorrent without info dictionary, got %v", err)
+	}
+	if _, err := TorrentInfoHash([]byte("d4:infod6:lengthi42e")); err != ErrInvalidTorrent {
+		t.Fatalf("Expected ErrInvalidTorrent on truncated data, got %v", err)
+	}
+}
+
+func TestParseCountryCodes(t *testing.T) {
+	list, err := ParseCountryCodes("fr, BE  fr de")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(list) != 3 || list[0] != "FR" || list[1] != "BE" || list[2] != "DE" {
+		t.Fatalf("Unexpected list %#v", list)
+	}
+
+	list, err = ParseCountryCodes("FR UK XX")
+	if err == nil {
+		t.Fatalf("Error expected for the invalid codes")
+	}
+	if err.Error() != "invalid country codes: UK, XX" {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("Expected the invalid codes to be kept, got %#v", list)
+	}
+}
+
+func TestCountryContinent(t *testing.T) {
+	cases := map[string]string{"FR": "EU", "us": "NA", "BR": "SA", "CN": "AS", "AU": "OC", "ZA": "AF", "AQ": "AN", "XX": ""}
+	for country, continent := range cases {
+		if c := CountryContinent(country); c != continent {
+			t.Fatalf("Expected %q for %s, got %q", continent, country, c)
+		}
+	}
+}